@@ -0,0 +1,309 @@
+// Package admin handler is the representation layer for platform administration
+// only platform admins can reach these handlers, they manage users and workspaces across the whole platform
+package admin
+
+import (
+	"fmt"
+	"github.com/gofiber/fiber/v2"
+	"github.com/kotalco/core-api/core/loginattempt"
+	"github.com/kotalco/core-api/core/setting"
+	"github.com/kotalco/core-api/core/user"
+	"github.com/kotalco/core-api/core/verification"
+	"github.com/kotalco/core-api/core/workspace"
+	"github.com/kotalco/core-api/k8s/statefulset"
+	restErrors "github.com/kotalco/core-api/pkg/errors"
+	"github.com/kotalco/core-api/pkg/mailer"
+	"github.com/kotalco/core-api/pkg/pagination"
+	"github.com/kotalco/core-api/pkg/responder"
+	"github.com/kotalco/core-api/pkg/sqlclient"
+	"github.com/kotalco/core-api/pkg/token"
+	"net/http"
+	"strconv"
+)
+
+var (
+	userService         = user.NewService()
+	workspaceService    = workspace.NewService()
+	verificationService = verification.NewService()
+	mailService         = mailer.NewService()
+	statefulSetService  = statefulset.NewService()
+	loginAttemptService = loginattempt.NewService()
+)
+
+// ListUsers returns all platform users, filtered by the search qs if passed
+func ListUsers(c *fiber.Ctx) error {
+	page, _ := strconv.Atoi(c.Query("page"))
+	limit, _ := strconv.Atoi(c.Query("limit"))
+
+	list, err := userService.WithoutTransaction().Search(c.Query("search"))
+	if err != nil {
+		return c.Status(err.StatusCode()).JSON(err)
+	}
+
+	c.Set("Access-Control-Expose-Headers", "X-Total-Count")
+	c.Set("X-Total-Count", fmt.Sprintf("%d", len(list)))
+
+	start, end := pagination.Page(uint(len(list)), uint(page), uint(limit))
+	result := make([]user.UserResponseDto, 0)
+	for _, v := range list[start:end] {
+		result = append(result, new(user.UserResponseDto).Marshall(v))
+	}
+
+	return c.Status(http.StatusOK).JSON(responder.NewResponse(result))
+}
+
+// GetUser returns a single user by id
+func GetUser(c *fiber.Ctx) error {
+	model := c.Locals("targetUser").(user.User)
+	return c.Status(http.StatusOK).JSON(responder.NewResponse(new(user.UserResponseDto).Marshall(&model)))
+}
+
+// DisableUser disables the user, disabled users can't sign in and their tokens are rejected
+func DisableUser(c *fiber.Ctx) error {
+	model := c.Locals("targetUser").(user.User)
+	userId := c.Locals("user").(token.UserDetails).ID
+
+	if model.ID == userId {
+		badReq := restErrors.NewBadRequestError("you can't disable your own account")
+		return c.Status(badReq.StatusCode()).JSON(badReq)
+	}
+
+	err := userService.WithoutTransaction().Disable(&model)
+	if err != nil {
+		return c.Status(err.StatusCode()).JSON(err)
+	}
+
+	return c.Status(http.StatusOK).JSON(responder.NewResponse(new(user.UserResponseDto).Marshall(&model)))
+}
+
+// EnableUser re-enables a disabled user
+func EnableUser(c *fiber.Ctx) error {
+	model := c.Locals("targetUser").(user.User)
+
+	err := userService.WithoutTransaction().Enable(&model)
+	if err != nil {
+		return c.Status(err.StatusCode()).JSON(err)
+	}
+
+	return c.Status(http.StatusOK).JSON(responder.NewResponse(new(user.UserResponseDto).Marshall(&model)))
+}
+
+// ResetPassword invalidates the user current password and sends a reset password email to the user
+func ResetPassword(c *fiber.Ctx) error {
+	model := c.Locals("targetUser").(user.User)
+
+	txHandle := sqlclient.Begin()
+
+	err := userService.WithTransaction(txHandle).InvalidatePassword(&model)
+	if err != nil {
+		sqlclient.Rollback(txHandle)
+		return c.Status(err.StatusCode()).JSON(err)
+	}
+
+	verificationToken, err := verificationService.WithTransaction(txHandle).Resend(model.ID)
+	if err != nil {
+		sqlclient.Rollback(txHandle)
+		return c.Status(err.StatusCode()).JSON(err)
+	}
+
+	mailRequest := new(mailer.MailRequestDto)
+	mailRequest.Token = verificationToken
+	mailRequest.Email = model.Email
+
+	err = mailService.ForgetPassword(mailRequest)
+	if err != nil {
+		sqlclient.Rollback(txHandle)
+		return c.Status(err.StatusCode()).JSON(err)
+	}
+	sqlclient.Commit(txHandle)
+
+	return c.Status(http.StatusOK).JSON(responder.NewResponse(responder.SuccessMessage{Message: "reset password has been sent to the user email"}))
+}
+
+// ResetTwoFactorAuth disables the user 2fa so the user can sign in and register a new authenticator
+func ResetTwoFactorAuth(c *fiber.Ctx) error {
+	model := c.Locals("targetUser").(user.User)
+
+	if !model.TwoFactorEnabled {
+		badReq := restErrors.NewBadRequestError("2fa already disabled")
+		return c.Status(badReq.StatusCode()).JSON(badReq)
+	}
+
+	err := userService.WithoutTransaction().ResetTwoFactorAuth(&model)
+	if err != nil {
+		return c.Status(err.StatusCode()).JSON(err)
+	}
+
+	return c.Status(http.StatusOK).JSON(responder.NewResponse(responder.SuccessMessage{Message: "2FA reset successfully"}))
+}
+
+// UnlockUser clears the user failed sign in attempts, lifting the lockout and the progressive delay
+func UnlockUser(c *fiber.Ctx) error {
+	model := c.Locals("targetUser").(user.User)
+
+	err := loginAttemptService.WithoutTransaction().Reset(model.ID)
+	if err != nil {
+		return c.Status(err.StatusCode()).JSON(err)
+	}
+
+	return c.Status(http.StatusOK).JSON(responder.NewResponse(responder.SuccessMessage{Message: "user unlocked successfully"}))
+}
+
+// PromotePlatformAdmin grants the user platform admin privileges
+func PromotePlatformAdmin(c *fiber.Ctx) error {
+	model := c.Locals("targetUser").(user.User)
+
+	if model.PlatformAdmin {
+		conflictErr := restErrors.NewConflictError("user is already a platform admin")
+		return c.Status(conflictErr.StatusCode()).JSON(conflictErr)
+	}
+
+	err := userService.WithoutTransaction().SetAsPlatformAdmin(&model)
+	if err != nil {
+		return c.Status(err.StatusCode()).JSON(err)
+	}
+
+	return c.Status(http.StatusOK).JSON(responder.NewResponse(new(user.UserResponseDto).Marshall(&model)))
+}
+
+// DemotePlatformAdmin revokes the user platform admin privileges
+func DemotePlatformAdmin(c *fiber.Ctx) error {
+	model := c.Locals("targetUser").(user.User)
+	userId := c.Locals("user").(token.UserDetails).ID
+
+	if model.ID == userId {
+		badReq := restErrors.NewBadRequestError("you can't demote yourself")
+		return c.Status(badReq.StatusCode()).JSON(badReq)
+	}
+	if !model.PlatformAdmin {
+		badReq := restErrors.NewBadRequestError("user isn't a platform admin")
+		return c.Status(badReq.StatusCode()).JSON(badReq)
+	}
+
+	err := userService.WithoutTransaction().UnsetPlatformAdmin(&model)
+	if err != nil {
+		return c.Status(err.StatusCode()).JSON(err)
+	}
+
+	return c.Status(http.StatusOK).JSON(responder.NewResponse(new(user.UserResponseDto).Marshall(&model)))
+}
+
+// ListWorkspaces returns all platform workspaces, filtered by the search qs if passed
+func ListWorkspaces(c *fiber.Ctx) error {
+	page, _ := strconv.Atoi(c.Query("page"))
+	limit, _ := strconv.Atoi(c.Query("limit"))
+
+	list, err := workspaceService.WithoutTransaction().Search(c.Query("search"))
+	if err != nil {
+		return c.Status(err.StatusCode()).JSON(err)
+	}
+
+	c.Set("Access-Control-Expose-Headers", "X-Total-Count")
+	c.Set("X-Total-Count", fmt.Sprintf("%d", len(list)))
+
+	start, end := pagination.Page(uint(len(list)), uint(page), uint(limit))
+	result := make([]workspace.WorkspaceResponseDto, 0)
+	for _, v := range list[start:end] {
+		result = append(result, *new(workspace.WorkspaceResponseDto).Marshall(v))
+	}
+
+	return c.Status(http.StatusOK).JSON(responder.NewResponse(result))
+}
+
+// TransferWorkspace transfers the workspace ownership to another user
+func TransferWorkspace(c *fiber.Ctx) error {
+	model := c.Locals("workspace").(workspace.Workspace)
+
+	dto := new(workspace.TransferOwnershipRequestDto)
+	if err := c.BodyParser(dto); err != nil {
+		badReq := restErrors.NewBadRequestError("invalid request body")
+		return c.Status(badReq.StatusCode()).JSON(badReq)
+	}
+
+	err := workspace.Validate(dto)
+	if err != nil {
+		return c.Status(err.StatusCode()).JSON(err)
+	}
+
+	newOwner, err := userService.WithoutTransaction().GetById(dto.UserId)
+	if err != nil {
+		return c.Status(err.StatusCode()).JSON(err)
+	}
+	if newOwner.Disabled {
+		badReq := restErrors.NewBadRequestError("can't transfer workspace to a disabled user")
+		return c.Status(badReq.StatusCode()).JSON(badReq)
+	}
+
+	txHandle := sqlclient.Begin()
+	err = workspaceService.WithTransaction(txHandle).TransferOwnership(&model, newOwner.ID)
+	if err != nil {
+		sqlclient.Rollback(txHandle)
+		return c.Status(err.StatusCode()).JSON(err)
+	}
+	sqlclient.Commit(txHandle)
+
+	return c.Status(http.StatusOK).JSON(responder.NewResponse(new(workspace.WorkspaceResponseDto).Marshall(&model)))
+}
+
+// NodesCount returns the count of the nodes across all workspaces grouped by protocol
+func NodesCount(c *fiber.Ctx) error {
+	list, err := statefulSetService.List("")
+	if err != nil {
+		return c.Status(err.StatusCode()).JSON(err)
+	}
+
+	result := map[string]uint{}
+	for _, item := range list.Items {
+		protocol := item.Labels["kotal.io/protocol"]
+		result[protocol]++
+	}
+
+	return c.Status(http.StatusOK).JSON(responder.NewResponse(result))
+}
+
+// SendTestMail sends a test email with the configured mail transport and templates
+func SendTestMail(c *fiber.Ctx) error {
+	dto := new(setting.TestMailRequestDto)
+	if err := c.BodyParser(dto); err != nil {
+		badReq := restErrors.NewBadRequestError("invalid request body")
+		return c.Status(badReq.StatusCode()).JSON(badReq)
+	}
+
+	err := setting.Validate(dto)
+	if err != nil {
+		return c.Status(err.StatusCode()).JSON(err)
+	}
+
+	err = mailService.Test(&mailer.MailRequestDto{Email: dto.Email})
+	if err != nil {
+		return c.Status(err.StatusCode()).JSON(err)
+	}
+
+	return c.Status(http.StatusOK).JSON(responder.NewResponse(responder.SuccessMessage{Message: "test email sent successfully"}))
+}
+
+// ValidateUserExist checks if the user_id passed as path param exist, creates targetUser local
+func ValidateUserExist(c *fiber.Ctx) error {
+	model, err := userService.WithoutTransaction().GetById(c.Params("user_id"))
+	if err != nil {
+		return c.Status(err.StatusCode()).JSON(err)
+	}
+
+	c.Locals("targetUser", *model)
+	return c.Next()
+}
+
+// ValidateWorkspaceExist checks if the workspace id passed as path param exist, creates workspace local
+func ValidateWorkspaceExist(c *fiber.Ctx) error {
+	model, err := workspaceService.WithoutTransaction().GetById(c.Params("id"))
+	if err != nil {
+		if err.StatusCode() == http.StatusNotFound {
+			notFoundErr := restErrors.NewNotFoundError("no such record")
+			return c.Status(notFoundErr.StatusCode()).JSON(notFoundErr)
+		}
+		return c.Status(err.StatusCode()).JSON(err)
+	}
+
+	c.Locals("workspace", *model)
+	return c.Next()
+}
@@ -0,0 +1,713 @@
+package admin
+
+import (
+	"bytes"
+	"encoding/json"
+	"github.com/gofiber/fiber/v2"
+	"github.com/kotalco/core-api/core/loginattempt"
+	"github.com/kotalco/core-api/core/setting"
+	"github.com/kotalco/core-api/core/user"
+	"github.com/kotalco/core-api/core/verification"
+	"github.com/kotalco/core-api/core/workspace"
+	"github.com/kotalco/core-api/core/workspaceuser"
+	restErrors "github.com/kotalco/core-api/pkg/errors"
+	"github.com/kotalco/core-api/pkg/mailer"
+	"github.com/kotalco/core-api/pkg/sqlclient"
+	"github.com/kotalco/core-api/pkg/token"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+	"io/ioutil"
+	appsv1 "k8s.io/api/apps/v1"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+)
+
+/*
+User service Mocks
+*/
+var (
+	UserWithTransactionFunc      func(txHandle *gorm.DB) user.IService
+	SignUpFunc                   func(dto *user.SignUpRequestDto) (*user.User, restErrors.IRestErr)
+	SignInFunc                   func(dto *user.SignInRequestDto) (*user.UserSessionResponseDto, restErrors.IRestErr)
+	VerifyTOTPFunc               func(model *user.User, totp string) (*user.UserSessionResponseDto, restErrors.IRestErr)
+	GetByEmailFunc               func(email string) (*user.User, restErrors.IRestErr)
+	GetByIdFunc                  func(Id string) (*user.User, restErrors.IRestErr)
+	VerifyEmailFunc              func(model *user.User) restErrors.IRestErr
+	ResetPasswordFunc            func(model *user.User, password string) restErrors.IRestErr
+	InvalidatePasswordFunc       func(model *user.User) restErrors.IRestErr
+	ChangePasswordFunc           func(model *user.User, dto *user.ChangePasswordRequestDto) restErrors.IRestErr
+	ChangeEmailFunc              func(model *user.User, dto *user.ChangeEmailRequestDto) restErrors.IRestErr
+	CreateTOTPFunc               func(model *user.User, dto *user.CreateTOTPRequestDto) (bytes.Buffer, restErrors.IRestErr)
+	EnableTwoFactorAuthFunc      func(model *user.User, totp string) (*user.User, restErrors.IRestErr)
+	DisableTwoFactorAuthFunc     func(model *user.User, dto *user.DisableTOTPRequestDto) restErrors.IRestErr
+	FindWhereIdInSliceFunc       func(ids []string) ([]*user.User, restErrors.IRestErr)
+	usersCountFunc               func() (int64, restErrors.IRestErr)
+	usersSetAsPlatformAdminFunc  func(model *user.User) restErrors.IRestErr
+	usersUnsetPlatformAdminFunc  func(model *user.User) restErrors.IRestErr
+	usersSearchFunc              func(email string) ([]*user.User, restErrors.IRestErr)
+	usersDisableFunc             func(model *user.User) restErrors.IRestErr
+	usersEnableFunc              func(model *user.User) restErrors.IRestErr
+	usersResetTwoFactorAuthFunc  func(model *user.User) restErrors.IRestErr
+	usersCheckPasswordPolicyFunc func(model *user.User, password string) restErrors.IRestErr
+	usersReauthenticateFunc      func(model *user.User, dto *user.DeleteAccountRequestDto) restErrors.IRestErr
+	usersDeleteFunc              func(model *user.User) restErrors.IRestErr
+)
+
+type userServiceMock struct{}
+
+func (uService userServiceMock) WithoutTransaction() user.IService {
+	return uService
+}
+
+func (uService userServiceMock) WithTransaction(txHandle *gorm.DB) user.IService {
+	return uService
+}
+
+func (userServiceMock) SignUp(dto *user.SignUpRequestDto) (*user.User, restErrors.IRestErr) {
+	return SignUpFunc(dto)
+}
+
+func (userServiceMock) SignIn(dto *user.SignInRequestDto) (*user.UserSessionResponseDto, restErrors.IRestErr) {
+	return SignInFunc(dto)
+}
+
+func (userServiceMock) GetByEmail(email string) (*user.User, restErrors.IRestErr) {
+	return GetByEmailFunc(email)
+}
+
+func (userServiceMock) GetById(Id string) (*user.User, restErrors.IRestErr) {
+	return GetByIdFunc(Id)
+}
+
+func (userServiceMock) VerifyEmail(model *user.User) restErrors.IRestErr {
+	return VerifyEmailFunc(model)
+}
+
+func (userServiceMock) ResetPassword(model *user.User, password string) restErrors.IRestErr {
+	return ResetPasswordFunc(model, password)
+}
+
+func (userServiceMock) InvalidatePassword(model *user.User) restErrors.IRestErr {
+	return InvalidatePasswordFunc(model)
+}
+
+func (userServiceMock) ChangePassword(model *user.User, dto *user.ChangePasswordRequestDto) restErrors.IRestErr {
+	return ChangePasswordFunc(model, dto)
+}
+
+func (userServiceMock) ChangeEmail(model *user.User, dto *user.ChangeEmailRequestDto) restErrors.IRestErr {
+	return ChangeEmailFunc(model, dto)
+}
+
+func (userServiceMock) CreateTOTP(model *user.User, dto *user.CreateTOTPRequestDto) (bytes.Buffer, restErrors.IRestErr) {
+	return CreateTOTPFunc(model, dto)
+}
+
+func (userServiceMock) EnableTwoFactorAuth(model *user.User, totp string) (*user.User, restErrors.IRestErr) {
+	return EnableTwoFactorAuthFunc(model, totp)
+}
+
+func (userServiceMock) VerifyTOTP(model *user.User, totp string) (*user.UserSessionResponseDto, restErrors.IRestErr) {
+	return VerifyTOTPFunc(model, totp)
+}
+
+func (userServiceMock) DisableTwoFactorAuth(model *user.User, dto *user.DisableTOTPRequestDto) restErrors.IRestErr {
+	return DisableTwoFactorAuthFunc(model, dto)
+}
+func (userServiceMock) FindWhereIdInSlice(ids []string) ([]*user.User, restErrors.IRestErr) {
+	return FindWhereIdInSliceFunc(ids)
+}
+func (userServiceMock) Count() (int64, restErrors.IRestErr) {
+	return usersCountFunc()
+}
+func (userServiceMock) SetAsPlatformAdmin(model *user.User) restErrors.IRestErr {
+	return usersSetAsPlatformAdminFunc(model)
+}
+func (userServiceMock) UnsetPlatformAdmin(model *user.User) restErrors.IRestErr {
+	return usersUnsetPlatformAdminFunc(model)
+}
+func (userServiceMock) Search(email string) ([]*user.User, restErrors.IRestErr) {
+	return usersSearchFunc(email)
+}
+func (userServiceMock) Disable(model *user.User) restErrors.IRestErr {
+	return usersDisableFunc(model)
+}
+func (userServiceMock) Enable(model *user.User) restErrors.IRestErr {
+	return usersEnableFunc(model)
+}
+func (userServiceMock) ResetTwoFactorAuth(model *user.User) restErrors.IRestErr {
+	return usersResetTwoFactorAuthFunc(model)
+}
+func (userServiceMock) CheckPasswordPolicy(model *user.User, password string) restErrors.IRestErr {
+	return usersCheckPasswordPolicyFunc(model, password)
+}
+func (userServiceMock) Reauthenticate(model *user.User, dto *user.DeleteAccountRequestDto) restErrors.IRestErr {
+	return usersReauthenticateFunc(model, dto)
+}
+func (userServiceMock) Delete(model *user.User) restErrors.IRestErr {
+	return usersDeleteFunc(model)
+}
+
+/*
+Verification service Mocks
+*/
+var (
+	CreateFunc                      func(userId string) (string, restErrors.IRestErr)
+	GetByUserIdFunc                 func(userId string) (*verification.Verification, restErrors.IRestErr)
+	ResendFunc                      func(userId string) (string, restErrors.IRestErr)
+	VerifyFunc                      func(userId string, token string) restErrors.IRestErr
+	VerificationWithTransactionFunc func(txHandle *gorm.DB) verification.IService
+	VerificationDeleteByUserIdFunc  func(userId string) restErrors.IRestErr
+)
+
+type verificationServiceMock struct{}
+
+func (vService verificationServiceMock) WithoutTransaction() verification.IService {
+	return vService
+}
+
+func (vService verificationServiceMock) WithTransaction(txHandle *gorm.DB) verification.IService {
+	return vService
+}
+
+func (verificationServiceMock) Create(userId string) (string, restErrors.IRestErr) {
+	return CreateFunc(userId)
+}
+
+func (verificationServiceMock) GetByUserId(userId string) (*verification.Verification, restErrors.IRestErr) {
+	return GetByUserIdFunc(userId)
+}
+
+func (verificationServiceMock) Verify(userId string, token string) restErrors.IRestErr {
+	return VerifyFunc(userId, token)
+}
+
+func (vService verificationServiceMock) Resend(userId string) (string, restErrors.IRestErr) {
+	return ResendFunc(userId)
+}
+
+func (verificationServiceMock) DeleteByUserId(userId string) restErrors.IRestErr {
+	return VerificationDeleteByUserIdFunc(userId)
+}
+
+// Mail Service mocks
+var (
+	SignUpMailFunc               func(dto *mailer.MailRequestDto) restErrors.IRestErr
+	ResendEmailVerificationFunc  func(dto *mailer.MailRequestDto) restErrors.IRestErr
+	ForgetPasswordMailFunc       func(dto *mailer.MailRequestDto) restErrors.IRestErr
+	WorkspaceInvitationFunc      func(dto *mailer.WorkspaceInvitationMailRequestDto) restErrors.IRestErr
+	PingFunc                     func() restErrors.IRestErr
+	AccountLockedMailFunc        func(dto *mailer.AccountLockedMailRequestDto) restErrors.IRestErr
+	WorkspaceTransferredMailFunc func(dto *mailer.WorkspaceOwnershipMailRequestDto) restErrors.IRestErr
+	TestMailFunc                 func(dto *mailer.MailRequestDto) restErrors.IRestErr
+)
+
+type mailServiceMock struct{}
+
+func (mailServiceMock) SignUp(dto *mailer.MailRequestDto) restErrors.IRestErr {
+	return SignUpMailFunc(dto)
+}
+func (mailServiceMock) ResendEmailVerification(dto *mailer.MailRequestDto) restErrors.IRestErr {
+	return ResendEmailVerificationFunc(dto)
+}
+func (mailServiceMock) ForgetPassword(dto *mailer.MailRequestDto) restErrors.IRestErr {
+	return ForgetPasswordMailFunc(dto)
+}
+func (mailServiceMock) WorkspaceInvitation(dto *mailer.WorkspaceInvitationMailRequestDto) restErrors.IRestErr {
+	return WorkspaceInvitationFunc(dto)
+}
+func (m mailServiceMock) Ping() restErrors.IRestErr {
+	return PingFunc()
+}
+func (mailServiceMock) AccountLocked(dto *mailer.AccountLockedMailRequestDto) restErrors.IRestErr {
+	return AccountLockedMailFunc(dto)
+}
+func (mailServiceMock) WorkspaceTransferred(dto *mailer.WorkspaceOwnershipMailRequestDto) restErrors.IRestErr {
+	return WorkspaceTransferredMailFunc(dto)
+}
+
+func (mailServiceMock) Test(dto *mailer.MailRequestDto) restErrors.IRestErr {
+	return TestMailFunc(dto)
+}
+
+func (mailServiceMock) Verify(settings *setting.MailSettingsDto) restErrors.IRestErr {
+	return nil
+}
+
+/*
+Workspace service Mocks
+*/
+var (
+	WorkspaceWithTransaction          func(txHandle *gorm.DB) workspace.IService
+	CreateWorkspaceFunc               func(dto *workspace.CreateWorkspaceRequestDto, userId string, k8NamespaceName string) (*workspace.Workspace, restErrors.IRestErr)
+	UpdateWorkspaceFunc               func(dto *workspace.UpdateWorkspaceRequestDto, workspace *workspace.Workspace) restErrors.IRestErr
+	DeleteWorkspace                   func(workspace *workspace.Workspace) restErrors.IRestErr
+	GetWorkspaceByIdFunc              func(Id string) (*workspace.Workspace, restErrors.IRestErr)
+	GetWorkspacesByUserIdFunc         func(userId string) ([]*workspace.Workspace, restErrors.IRestErr)
+	addWorkspaceMemberFunc            func(workspace *workspace.Workspace, memberId string, role string) restErrors.IRestErr
+	DeleteWorkspaceMemberFunc         func(workspace *workspace.Workspace, memberId string) restErrors.IRestErr
+	CountByUserIdFunc                 func(userId string) (int64, restErrors.IRestErr)
+	UpdateWorkspaceUserFunc           func(workspaceUser *workspaceuser.WorkspaceUser, dto *workspace.UpdateWorkspaceUserRequestDto) restErrors.IRestErr
+	GetWorkspaceByNamespace           func(namespace string) (*workspace.Workspace, restErrors.IRestErr)
+	SearchWorkspaceFunc               func(name string) ([]*workspace.Workspace, restErrors.IRestErr)
+	UpdateWorkspaceSecretProviderFunc func(workspace *workspace.Workspace, provider string) restErrors.IRestErr
+	TransferWorkspaceOwnershipFunc    func(workspace *workspace.Workspace, newOwnerId string) restErrors.IRestErr
+)
+
+type workspaceServiceMock struct{}
+
+func (wService workspaceServiceMock) WithoutTransaction() workspace.IService {
+	return wService
+}
+
+func (wService workspaceServiceMock) WithTransaction(txHandle *gorm.DB) workspace.IService {
+	return wService
+}
+
+func (workspaceServiceMock) Create(dto *workspace.CreateWorkspaceRequestDto, userId string, k8NamespaceName string) (*workspace.Workspace, restErrors.IRestErr) {
+	return CreateWorkspaceFunc(dto, userId, k8NamespaceName)
+}
+
+func (workspaceServiceMock) Update(dto *workspace.UpdateWorkspaceRequestDto, workspace *workspace.Workspace) restErrors.IRestErr {
+	return UpdateWorkspaceFunc(dto, workspace)
+}
+
+func (workspaceServiceMock) Delete(workspace *workspace.Workspace) restErrors.IRestErr {
+	return DeleteWorkspace(workspace)
+}
+
+func (workspaceServiceMock) GetById(id string) (*workspace.Workspace, restErrors.IRestErr) {
+	return GetWorkspaceByIdFunc(id)
+}
+
+func (workspaceServiceMock) GetByUserId(userId string) ([]*workspace.Workspace, restErrors.IRestErr) {
+	return GetWorkspacesByUserIdFunc(userId)
+}
+
+func (workspaceServiceMock) AddWorkspaceMember(workspace *workspace.Workspace, memberId string, role string) restErrors.IRestErr {
+	return addWorkspaceMemberFunc(workspace, memberId, role)
+}
+
+func (workspaceServiceMock) DeleteWorkspaceMember(workspace *workspace.Workspace, memberId string) restErrors.IRestErr {
+	return DeleteWorkspaceMemberFunc(workspace, memberId)
+}
+func (workspaceServiceMock) CountByUserId(userId string) (int64, restErrors.IRestErr) {
+	return CountByUserIdFunc(userId)
+}
+func (workspaceServiceMock) UpdateWorkspaceUser(workspaceUser *workspaceuser.WorkspaceUser, dto *workspace.UpdateWorkspaceUserRequestDto) restErrors.IRestErr {
+	return UpdateWorkspaceUserFunc(workspaceUser, dto)
+}
+func (workspaceServiceMock) GetByNamespace(namespace string) (*workspace.Workspace, restErrors.IRestErr) {
+	return GetWorkspaceByNamespace(namespace)
+}
+func (workspaceServiceMock) Search(name string) ([]*workspace.Workspace, restErrors.IRestErr) {
+	return SearchWorkspaceFunc(name)
+}
+func (workspaceServiceMock) TransferOwnership(workspace *workspace.Workspace, newOwnerId string) restErrors.IRestErr {
+	return TransferWorkspaceOwnershipFunc(workspace, newOwnerId)
+}
+func (workspaceServiceMock) UpdateSecretProvider(workspace *workspace.Workspace, provider string) restErrors.IRestErr {
+	return UpdateWorkspaceSecretProviderFunc(workspace, provider)
+}
+
+/*
+StatefulSet service Mocks
+*/
+var (
+	stsCountFunc func() (uint, restErrors.IRestErr)
+	stsListFunc  func(namespace string) (appsv1.StatefulSetList, restErrors.IRestErr)
+)
+
+type statefulSetServiceMocks struct{}
+
+func (s statefulSetServiceMocks) Count() (uint, restErrors.IRestErr) {
+	return stsCountFunc()
+}
+
+func (s statefulSetServiceMocks) List(namespace string) (appsv1.StatefulSetList, restErrors.IRestErr) {
+	return stsListFunc(namespace)
+}
+
+/*
+Login attempt service Mocks
+*/
+var (
+	loginAttemptCheckFunc         func(userId string) restErrors.IRestErr
+	loginAttemptRecordFailureFunc func(userId string) (*loginattempt.LoginAttempt, restErrors.IRestErr)
+	loginAttemptResetFunc         func(userId string) restErrors.IRestErr
+	loginAttemptIsLockedFunc      func(userId string) (bool, restErrors.IRestErr)
+)
+
+type loginAttemptServiceMock struct{}
+
+func (s loginAttemptServiceMock) WithTransaction(txHandle *gorm.DB) loginattempt.IService {
+	return s
+}
+
+func (s loginAttemptServiceMock) WithoutTransaction() loginattempt.IService {
+	return s
+}
+
+// Attempt checks the account attempts, runs the verification and records its failure
+func (loginAttemptServiceMock) Attempt(userId string, verify func() bool) (*loginattempt.LoginAttempt, restErrors.IRestErr) {
+	if restErr := loginAttemptCheckFunc(userId); restErr != nil {
+		return nil, restErr
+	}
+	if verify() {
+		return nil, nil
+	}
+	return loginAttemptRecordFailureFunc(userId)
+}
+
+func (loginAttemptServiceMock) Reset(userId string) restErrors.IRestErr {
+	return loginAttemptResetFunc(userId)
+}
+
+func (loginAttemptServiceMock) IsLocked(userId string) (bool, restErrors.IRestErr) {
+	return loginAttemptIsLockedFunc(userId)
+}
+
+func TestMain(m *testing.M) {
+	userService = &userServiceMock{}
+	verificationService = &verificationServiceMock{}
+	mailService = &mailServiceMock{}
+	workspaceService = &workspaceServiceMock{}
+	statefulSetService = &statefulSetServiceMocks{}
+	loginAttemptService = &loginAttemptServiceMock{}
+
+	sqlclient.OpenDBConnection()
+
+	code := m.Run()
+	os.Exit(code)
+}
+
+func newFiberCtx(dto interface{}, method func(c *fiber.Ctx) error, locals map[string]interface{}) ([]byte, *http.Response) {
+	app := fiber.New()
+	app.Post("/test", func(c *fiber.Ctx) error {
+		for key, element := range locals {
+			c.Locals(key, element)
+		}
+		return method(c)
+	})
+
+	marshaledDto, err := json.Marshal(dto)
+	if err != nil {
+		panic(err.Error())
+	}
+
+	req := httptest.NewRequest("POST", "/test", bytes.NewBuffer(marshaledDto))
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := app.Test(req)
+	if err != nil {
+		panic(err.Error())
+	}
+
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		panic(err.Error())
+	}
+
+	return body, resp
+}
+
+func TestListUsers(t *testing.T) {
+	t.Run("list users should pass", func(t *testing.T) {
+		usersSearchFunc = func(email string) ([]*user.User, restErrors.IRestErr) {
+			return []*user.User{{ID: "1"}, {ID: "2"}}, nil
+		}
+		body, resp := newFiberCtx("", ListUsers, map[string]interface{}{})
+		var result map[string][]user.UserResponseDto
+		err := json.Unmarshal(body, &result)
+		if err != nil {
+			panic(err.Error())
+		}
+		assert.EqualValues(t, http.StatusOK, resp.StatusCode)
+		assert.Len(t, result["data"], 2)
+		assert.EqualValues(t, "2", resp.Header.Get("X-Total-Count"))
+	})
+	t.Run("list users should throw if service throws", func(t *testing.T) {
+		usersSearchFunc = func(email string) ([]*user.User, restErrors.IRestErr) {
+			return nil, restErrors.NewInternalServerError("something went wrong")
+		}
+		_, resp := newFiberCtx("", ListUsers, map[string]interface{}{})
+		assert.EqualValues(t, http.StatusInternalServerError, resp.StatusCode)
+	})
+}
+
+func TestDisableUser(t *testing.T) {
+	t.Run("disable user should pass", func(t *testing.T) {
+		usersDisableFunc = func(model *user.User) restErrors.IRestErr {
+			model.Disabled = true
+			return nil
+		}
+		locals := map[string]interface{}{"targetUser": user.User{ID: "2"}, "user": token.UserDetails{ID: "1"}}
+		body, resp := newFiberCtx("", DisableUser, locals)
+		var result map[string]user.UserResponseDto
+		err := json.Unmarshal(body, &result)
+		if err != nil {
+			panic(err.Error())
+		}
+		assert.EqualValues(t, http.StatusOK, resp.StatusCode)
+		assert.True(t, result["data"].Disabled)
+	})
+	t.Run("disable user should throw if user disables himself", func(t *testing.T) {
+		locals := map[string]interface{}{"targetUser": user.User{ID: "1"}, "user": token.UserDetails{ID: "1"}}
+		body, resp := newFiberCtx("", DisableUser, locals)
+		var result restErrors.RestErr
+		err := json.Unmarshal(body, &result)
+		if err != nil {
+			panic(err.Error())
+		}
+		assert.EqualValues(t, http.StatusBadRequest, resp.StatusCode)
+		assert.EqualValues(t, "you can't disable your own account", result.Message)
+	})
+	t.Run("disable user should throw if service throws", func(t *testing.T) {
+		usersDisableFunc = func(model *user.User) restErrors.IRestErr {
+			return restErrors.NewInternalServerError("something went wrong")
+		}
+		locals := map[string]interface{}{"targetUser": user.User{ID: "2"}, "user": token.UserDetails{ID: "1"}}
+		_, resp := newFiberCtx("", DisableUser, locals)
+		assert.EqualValues(t, http.StatusInternalServerError, resp.StatusCode)
+	})
+}
+
+func TestEnableUser(t *testing.T) {
+	t.Run("enable user should pass", func(t *testing.T) {
+		usersEnableFunc = func(model *user.User) restErrors.IRestErr {
+			model.Disabled = false
+			return nil
+		}
+		locals := map[string]interface{}{"targetUser": user.User{ID: "2", Disabled: true}}
+		_, resp := newFiberCtx("", EnableUser, locals)
+		assert.EqualValues(t, http.StatusOK, resp.StatusCode)
+	})
+	t.Run("enable user should throw if service throws", func(t *testing.T) {
+		usersEnableFunc = func(model *user.User) restErrors.IRestErr {
+			return restErrors.NewInternalServerError("something went wrong")
+		}
+		locals := map[string]interface{}{"targetUser": user.User{ID: "2", Disabled: true}}
+		_, resp := newFiberCtx("", EnableUser, locals)
+		assert.EqualValues(t, http.StatusInternalServerError, resp.StatusCode)
+	})
+}
+
+func TestResetPassword(t *testing.T) {
+	locals := map[string]interface{}{"targetUser": user.User{ID: "2", Email: "test@test.com"}}
+	t.Run("reset password should pass", func(t *testing.T) {
+		InvalidatePasswordFunc = func(model *user.User) restErrors.IRestErr {
+			return nil
+		}
+		ResendFunc = func(userId string) (string, restErrors.IRestErr) {
+			return "token", nil
+		}
+		ForgetPasswordMailFunc = func(dto *mailer.MailRequestDto) restErrors.IRestErr {
+			return nil
+		}
+		_, resp := newFiberCtx("", ResetPassword, locals)
+		assert.EqualValues(t, http.StatusOK, resp.StatusCode)
+	})
+	t.Run("reset password should throw if mail service throws", func(t *testing.T) {
+		InvalidatePasswordFunc = func(model *user.User) restErrors.IRestErr {
+			return nil
+		}
+		ResendFunc = func(userId string) (string, restErrors.IRestErr) {
+			return "token", nil
+		}
+		ForgetPasswordMailFunc = func(dto *mailer.MailRequestDto) restErrors.IRestErr {
+			return restErrors.NewInternalServerError("something went wrong")
+		}
+		_, resp := newFiberCtx("", ResetPassword, locals)
+		assert.EqualValues(t, http.StatusInternalServerError, resp.StatusCode)
+	})
+	t.Run("reset password should throw if the password can't be invalidated", func(t *testing.T) {
+		InvalidatePasswordFunc = func(model *user.User) restErrors.IRestErr {
+			return restErrors.NewInternalServerError("something went wrong")
+		}
+		_, resp := newFiberCtx("", ResetPassword, locals)
+		assert.EqualValues(t, http.StatusInternalServerError, resp.StatusCode)
+	})
+}
+
+func TestResetTwoFactorAuth(t *testing.T) {
+	t.Run("reset 2fa should pass", func(t *testing.T) {
+		usersResetTwoFactorAuthFunc = func(model *user.User) restErrors.IRestErr {
+			return nil
+		}
+		locals := map[string]interface{}{"targetUser": user.User{ID: "2", TwoFactorEnabled: true}}
+		_, resp := newFiberCtx("", ResetTwoFactorAuth, locals)
+		assert.EqualValues(t, http.StatusOK, resp.StatusCode)
+	})
+	t.Run("reset 2fa should throw if 2fa already disabled", func(t *testing.T) {
+		locals := map[string]interface{}{"targetUser": user.User{ID: "2"}}
+		_, resp := newFiberCtx("", ResetTwoFactorAuth, locals)
+		assert.EqualValues(t, http.StatusBadRequest, resp.StatusCode)
+	})
+}
+
+func TestUnlockUser(t *testing.T) {
+	locals := map[string]interface{}{"targetUser": user.User{ID: "2"}}
+	t.Run("unlock user should pass", func(t *testing.T) {
+		loginAttemptResetFunc = func(userId string) restErrors.IRestErr {
+			return nil
+		}
+		_, resp := newFiberCtx("", UnlockUser, locals)
+		assert.EqualValues(t, http.StatusOK, resp.StatusCode)
+	})
+	t.Run("unlock user should throw if service throws", func(t *testing.T) {
+		loginAttemptResetFunc = func(userId string) restErrors.IRestErr {
+			return restErrors.NewInternalServerError("something went wrong")
+		}
+		_, resp := newFiberCtx("", UnlockUser, locals)
+		assert.EqualValues(t, http.StatusInternalServerError, resp.StatusCode)
+	})
+}
+
+func TestPromotePlatformAdmin(t *testing.T) {
+	t.Run("promote platform admin should pass", func(t *testing.T) {
+		usersSetAsPlatformAdminFunc = func(model *user.User) restErrors.IRestErr {
+			model.PlatformAdmin = true
+			return nil
+		}
+		locals := map[string]interface{}{"targetUser": user.User{ID: "2"}}
+		body, resp := newFiberCtx("", PromotePlatformAdmin, locals)
+		var result map[string]user.UserResponseDto
+		err := json.Unmarshal(body, &result)
+		if err != nil {
+			panic(err.Error())
+		}
+		assert.EqualValues(t, http.StatusOK, resp.StatusCode)
+		assert.True(t, result["data"].PlatformAdmin)
+	})
+	t.Run("promote platform admin should throw if user is already a platform admin", func(t *testing.T) {
+		locals := map[string]interface{}{"targetUser": user.User{ID: "2", PlatformAdmin: true}}
+		_, resp := newFiberCtx("", PromotePlatformAdmin, locals)
+		assert.EqualValues(t, http.StatusConflict, resp.StatusCode)
+	})
+}
+
+func TestDemotePlatformAdmin(t *testing.T) {
+	t.Run("demote platform admin should pass", func(t *testing.T) {
+		usersUnsetPlatformAdminFunc = func(model *user.User) restErrors.IRestErr {
+			model.PlatformAdmin = false
+			return nil
+		}
+		locals := map[string]interface{}{"targetUser": user.User{ID: "2", PlatformAdmin: true}, "user": token.UserDetails{ID: "1"}}
+		_, resp := newFiberCtx("", DemotePlatformAdmin, locals)
+		assert.EqualValues(t, http.StatusOK, resp.StatusCode)
+	})
+	t.Run("demote platform admin should throw if user demotes himself", func(t *testing.T) {
+		locals := map[string]interface{}{"targetUser": user.User{ID: "1", PlatformAdmin: true}, "user": token.UserDetails{ID: "1"}}
+		_, resp := newFiberCtx("", DemotePlatformAdmin, locals)
+		assert.EqualValues(t, http.StatusBadRequest, resp.StatusCode)
+	})
+	t.Run("demote platform admin should throw if user isn't a platform admin", func(t *testing.T) {
+		locals := map[string]interface{}{"targetUser": user.User{ID: "2"}, "user": token.UserDetails{ID: "1"}}
+		_, resp := newFiberCtx("", DemotePlatformAdmin, locals)
+		assert.EqualValues(t, http.StatusBadRequest, resp.StatusCode)
+	})
+}
+
+func TestListWorkspaces(t *testing.T) {
+	t.Run("list workspaces should pass", func(t *testing.T) {
+		SearchWorkspaceFunc = func(name string) ([]*workspace.Workspace, restErrors.IRestErr) {
+			return []*workspace.Workspace{{ID: "1"}}, nil
+		}
+		_, resp := newFiberCtx("", ListWorkspaces, map[string]interface{}{})
+		assert.EqualValues(t, http.StatusOK, resp.StatusCode)
+		assert.EqualValues(t, "1", resp.Header.Get("X-Total-Count"))
+	})
+}
+
+func TestTransferWorkspace(t *testing.T) {
+	locals := map[string]interface{}{"workspace": workspace.Workspace{ID: "1", UserId: "1"}}
+	t.Run("transfer workspace should pass", func(t *testing.T) {
+		GetByIdFunc = func(Id string) (*user.User, restErrors.IRestErr) {
+			return &user.User{ID: Id}, nil
+		}
+		TransferWorkspaceOwnershipFunc = func(workspace *workspace.Workspace, newOwnerId string) restErrors.IRestErr {
+			workspace.UserId = newOwnerId
+			return nil
+		}
+		_, resp := newFiberCtx(map[string]string{"user_id": "2"}, TransferWorkspace, locals)
+		assert.EqualValues(t, http.StatusOK, resp.StatusCode)
+	})
+	t.Run("transfer workspace should throw validation error", func(t *testing.T) {
+		_, resp := newFiberCtx(map[string]string{}, TransferWorkspace, locals)
+		assert.EqualValues(t, http.StatusBadRequest, resp.StatusCode)
+	})
+	t.Run("transfer workspace should throw if new owner is disabled", func(t *testing.T) {
+		GetByIdFunc = func(Id string) (*user.User, restErrors.IRestErr) {
+			return &user.User{ID: Id, Disabled: true}, nil
+		}
+		_, resp := newFiberCtx(map[string]string{"user_id": "2"}, TransferWorkspace, locals)
+		assert.EqualValues(t, http.StatusBadRequest, resp.StatusCode)
+	})
+	t.Run("transfer workspace should throw if service throws", func(t *testing.T) {
+		GetByIdFunc = func(Id string) (*user.User, restErrors.IRestErr) {
+			return &user.User{ID: Id}, nil
+		}
+		TransferWorkspaceOwnershipFunc = func(workspace *workspace.Workspace, newOwnerId string) restErrors.IRestErr {
+			return restErrors.NewConflictError("user already has workspace with the same name")
+		}
+		_, resp := newFiberCtx(map[string]string{"user_id": "2"}, TransferWorkspace, locals)
+		assert.EqualValues(t, http.StatusConflict, resp.StatusCode)
+	})
+}
+
+func TestNodesCount(t *testing.T) {
+	t.Run("nodes count should pass", func(t *testing.T) {
+		stsListFunc = func(namespace string) (appsv1.StatefulSetList, restErrors.IRestErr) {
+			return appsv1.StatefulSetList{
+				Items: []appsv1.StatefulSet{
+					{ObjectMeta: v1.ObjectMeta{Labels: map[string]string{"kotal.io/protocol": "ethereum"}}},
+					{ObjectMeta: v1.ObjectMeta{Labels: map[string]string{"kotal.io/protocol": "ethereum"}}},
+				},
+			}, nil
+		}
+		body, resp := newFiberCtx("", NodesCount, map[string]interface{}{})
+		var result map[string]map[string]uint
+		err := json.Unmarshal(body, &result)
+		if err != nil {
+			panic(err.Error())
+		}
+		assert.EqualValues(t, http.StatusOK, resp.StatusCode)
+		assert.EqualValues(t, 2, result["data"]["ethereum"])
+	})
+	t.Run("nodes count should throw if sts service throws", func(t *testing.T) {
+		stsListFunc = func(namespace string) (appsv1.StatefulSetList, restErrors.IRestErr) {
+			return appsv1.StatefulSetList{}, restErrors.NewInternalServerError("something went wrong")
+		}
+		_, resp := newFiberCtx("", NodesCount, map[string]interface{}{})
+		assert.EqualValues(t, http.StatusInternalServerError, resp.StatusCode)
+	})
+}
+
+func TestSendTestMail(t *testing.T) {
+	t.Run("send test mail should pass", func(t *testing.T) {
+		TestMailFunc = func(dto *mailer.MailRequestDto) restErrors.IRestErr {
+			return nil
+		}
+		_, resp := newFiberCtx(setting.TestMailRequestDto{Email: "admin@kotal.co"}, SendTestMail, map[string]interface{}{})
+		assert.EqualValues(t, http.StatusOK, resp.StatusCode)
+	})
+	t.Run("send test mail should throw validation error", func(t *testing.T) {
+		_, resp := newFiberCtx(setting.TestMailRequestDto{Email: "invalid"}, SendTestMail, map[string]interface{}{})
+		assert.EqualValues(t, http.StatusBadRequest, resp.StatusCode)
+	})
+	t.Run("send test mail should throw if transport throws", func(t *testing.T) {
+		TestMailFunc = func(dto *mailer.MailRequestDto) restErrors.IRestErr {
+			return restErrors.NewBadRequestError("can't send test email: connection refused")
+		}
+		body, resp := newFiberCtx(setting.TestMailRequestDto{Email: "admin@kotal.co"}, SendTestMail, map[string]interface{}{})
+		var result restErrors.RestErr
+		err := json.Unmarshal(body, &result)
+		if err != nil {
+			panic(err.Error())
+		}
+		assert.EqualValues(t, http.StatusBadRequest, resp.StatusCode)
+		assert.EqualValues(t, "can't send test email: connection refused", result.Message)
+	})
+}
@@ -7,6 +7,7 @@ import (
 	"fmt"
 	"github.com/gofiber/fiber/v2"
 	"github.com/gofiber/websocket/v2"
+	"github.com/kotalco/core-api/api/handler/shared"
 	"github.com/kotalco/core-api/core/aptos"
 	"github.com/kotalco/core-api/k8s"
 	restErrors "github.com/kotalco/core-api/pkg/errors"
@@ -133,6 +134,14 @@ func Delete(c *fiber.Ctx) error {
 	return c.SendStatus(http.StatusNoContent)
 }
 
+// ExpandStorage expands the node volume to the given storage size
+// 1-get node from locals which checked and assigned by ValidateNodeExist
+// 2-call shared ExpandStorage to validate the storage size and expand the volume
+func ExpandStorage(c *fiber.Ctx) error {
+	node := c.Locals("node").(aptosv1alpha1.Node)
+	return shared.ExpandStorage(c, &node, &node.Spec.Resources)
+}
+
 // Stats returns a websocket that emits aptos stats
 func Stats(c *websocket.Conn) {
 	defer c.Close()
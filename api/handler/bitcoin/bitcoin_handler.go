@@ -7,8 +7,8 @@ import (
 	"fmt"
 	"github.com/gofiber/fiber/v2"
 	"github.com/gofiber/websocket/v2"
+	"github.com/kotalco/core-api/api/handler/shared"
 	"github.com/kotalco/core-api/core/bitcoin"
-	"github.com/kotalco/core-api/core/secret"
 	"github.com/kotalco/core-api/k8s"
 	restErrors "github.com/kotalco/core-api/pkg/errors"
 	"github.com/kotalco/core-api/pkg/pagination"
@@ -24,9 +24,9 @@ import (
 )
 
 type request struct {
-	endpoint string
-	method   string
-	name     string
+	client jsonrpc.RPCClient
+	method string
+	name   string
 }
 type result struct {
 	err  error
@@ -35,13 +35,14 @@ type result struct {
 }
 
 const (
-	nameKeyword = "name"
+	nameKeyword     = "name"
+	usernameKeyword = "username"
+	walletKeyword   = "wallet"
 )
 
 var (
-	service       = bitcoin.NewBitcoinService()
-	secretService = secret.NewSecretService()
-	k8sClient     = k8s.NewClientService()
+	service   = bitcoin.NewBitcoinService()
+	k8sClient = k8s.NewClientService()
 )
 
 // Get returns a single bitcoin node by name
@@ -81,30 +82,10 @@ func Create(c *fiber.Ctx) error {
 	}
 
 	dto.Namespace = c.Locals("namespace").(string)
-	if err := dto.MetaDataDto.Validate(); err != nil {
+	if err := dto.Validate(); err != nil {
 		return c.Status(err.StatusCode()).JSON(err)
 	}
 
-	//check for bitcoin json rpc default user secret
-	_, err := secretService.Get(types.NamespacedName{
-		Name:      bitcoin.BitcoinJsonRpcDefaultUserPasswordName,
-		Namespace: dto.Namespace,
-	})
-	if err != nil {
-		if err.StatusCode() != http.StatusNotFound {
-			return c.Status(err.StatusCode()).JSON(err)
-		}
-		//create bitcoin user default secret
-		_, err = secretService.Create(secret.SecretDto{
-			MetaDataDto: k8s.MetaDataDto{Name: bitcoin.BitcoinJsonRpcDefaultUserPasswordName, Namespace: dto.Namespace},
-			Type:        "password",
-			Data:        map[string]string{"password": bitcoin.BitcoinJsonRpcDefaultUserPasswordSecret},
-		})
-		if err != nil {
-			return c.Status(err.StatusCode()).JSON(err)
-		}
-	}
-
 	node, err := service.Create(*dto)
 	if err != nil {
 		return c.Status(err.StatusCode()).JSON(err)
@@ -155,6 +136,115 @@ func Delete(c *fiber.Ctx) error {
 	return c.SendStatus(http.StatusNoContent)
 }
 
+// ExpandStorage expands the node volume to the given storage size
+// 1-get node from locals which checked and assigned by ValidateNodeExist
+// 2-call shared ExpandStorage to validate the storage size and expand the volume
+func ExpandStorage(c *fiber.Ctx) error {
+	node := c.Locals("node").(bitcoinv1alpha1.Node)
+	return shared.ExpandStorage(c, &node, &node.Spec.Resources)
+}
+
+// AddRPCUser adds a rpc user to the node, the user password is generated and stored as a secret
+// 1-get node from locals which checked and assigned by ValidateNodeExist
+// 2-validate the username, the user password secret is named after the node and the username
+// 3-call service to generate the password secret and add the user to the node
+// 4-return the user with its password secret name, the password is revealed from the secret
+func AddRPCUser(c *fiber.Ctx) error {
+	dto := new(bitcoin.RPCUserDto)
+	if err := c.BodyParser(dto); err != nil {
+		badReq := restErrors.NewBadRequestError("invalid request body")
+		return c.Status(badReq.StatusCode()).JSON(badReq)
+	}
+
+	node := c.Locals("node").(bitcoinv1alpha1.Node)
+
+	err := dto.Validate(node.Name)
+	if err != nil {
+		return c.Status(err.StatusCode()).JSON(err)
+	}
+
+	user, err := service.AddRPCUser(&node, *dto)
+	if err != nil {
+		return c.Status(err.StatusCode()).JSON(err)
+	}
+
+	return c.Status(http.StatusCreated).JSON(responder.NewResponse(user))
+}
+
+// RemoveRPCUser removes a rpc user from the node by username with its generated password secret
+func RemoveRPCUser(c *fiber.Ctx) error {
+	node := c.Locals("node").(bitcoinv1alpha1.Node)
+
+	err := service.RemoveRPCUser(&node, c.Params(usernameKeyword))
+	if err != nil {
+		return c.Status(err.StatusCode()).JSON(err)
+	}
+
+	return c.SendStatus(http.StatusNoContent)
+}
+
+// RotateRPCPassword replaces the node default rpc user password with a generated one
+func RotateRPCPassword(c *fiber.Ctx) error {
+	node := c.Locals("node").(bitcoinv1alpha1.Node)
+
+	user, err := service.RotateRPCPassword(&node)
+	if err != nil {
+		return c.Status(err.StatusCode()).JSON(err)
+	}
+
+	return c.Status(http.StatusOK).JSON(responder.NewResponse(user))
+}
+
+// Wallets lists the node loaded wallets
+func Wallets(c *fiber.Ctx) error {
+	node := c.Locals("node").(bitcoinv1alpha1.Node)
+
+	wallets, err := service.Wallets(node)
+	if err != nil {
+		return c.Status(err.StatusCode()).JSON(err)
+	}
+
+	return c.Status(http.StatusOK).JSON(responder.NewResponse(wallets))
+}
+
+// Balances returns the node wallet balances by wallet name
+func Balances(c *fiber.Ctx) error {
+	node := c.Locals("node").(bitcoinv1alpha1.Node)
+
+	balances, err := service.Balances(node, c.Params(walletKeyword))
+	if err != nil {
+		return c.Status(err.StatusCode()).JSON(err)
+	}
+
+	return c.Status(http.StatusOK).JSON(responder.NewResponse(balances))
+}
+
+// NewAddress returns a new receive address of the node wallet
+// 1-validate the address type
+// 2-get node from locals which checked and assigned by ValidateNodeExist
+// 3-call service to get the new address from the node wallet json-rpc
+func NewAddress(c *fiber.Ctx) error {
+	dto := new(bitcoin.AddressDto)
+	if err := c.BodyParser(dto); err != nil {
+		badReq := restErrors.NewBadRequestError("invalid request body")
+		return c.Status(badReq.StatusCode()).JSON(badReq)
+	}
+
+	err := dto.Validate()
+	if err != nil {
+		return c.Status(err.StatusCode()).JSON(err)
+	}
+
+	node := c.Locals("node").(bitcoinv1alpha1.Node)
+
+	address, err := service.NewAddress(node, c.Params(walletKeyword), *dto)
+	if err != nil {
+		return c.Status(err.StatusCode()).JSON(err)
+	}
+
+	return c.Status(http.StatusCreated).JSON(responder.NewResponse(address))
+}
+
 func ValidateNodeExist(c *fiber.Ctx) error {
 	nameSpacedName := types.NamespacedName{
 		Name:      c.Params(nameKeyword),
@@ -193,9 +283,10 @@ func Stats(c *websocket.Conn) {
 		return
 	}
 
-	if !node.Spec.RPC {
+	rpcClient, restErr := service.RPCClient(*node, "")
+	if restErr != nil {
 		c.WriteJSON(fiber.Map{
-			"error": "JSON-RPC server is not enabled",
+			"error": restErr.Error(),
 		})
 		return
 	}
@@ -208,9 +299,8 @@ func Stats(c *websocket.Conn) {
 			go worker(jobs, results)
 		}
 
-		endpoint := fmt.Sprintf("http://%s:%s@%s.%s:%d/", bitcoin.BitcoinJsonRpcDefaultUserName, bitcoin.BitcoinJsonRpcDefaultUserPasswordSecret, nameSpacedName.Name, nameSpacedName.Namespace, node.Spec.RPCPort)
-		jobs <- request{name: "blockCount", endpoint: endpoint, method: "getblockcount"}
-		jobs <- request{name: "peerCount", endpoint: endpoint, method: "getconnectioncount"}
+		jobs <- request{name: "blockCount", client: rpcClient, method: "getblockcount"}
+		jobs <- request{name: "peerCount", client: rpcClient, method: "getconnectioncount"}
 
 		close(jobs)
 
@@ -268,9 +358,7 @@ func worker(jobs <-chan request, results chan<- result) {
 	for job := range jobs {
 		chanRes.name = job.name
 
-		client := jsonrpc.NewClient(job.endpoint)
-
-		res, err := client.Call(job.method)
+		res, err := job.client.Call(job.method)
 		if err != nil {
 			chanRes.err = err
 		} else {
@@ -0,0 +1,54 @@
+// Package bulk handler is the representation layer for the bulk actions on nodes across protocols
+// uses the bulk service to run the actions and to poll the async jobs
+package bulk
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/kotalco/core-api/core/bulk"
+	restErrors "github.com/kotalco/core-api/pkg/errors"
+	"github.com/kotalco/core-api/pkg/responder"
+	"net/http"
+)
+
+const idKeyword = "id"
+
+var service = bulk.NewService()
+
+// Create runs the action on the selected nodes
+// 1-validate the selector and the action
+// 2-call bulk service to run the action on the nodes the member has permission to act on
+// 3-return the per node results, or the job to be polled if the action is async
+func Create(c *fiber.Ctx) error {
+	dto := new(bulk.BulkDto)
+	if err := c.BodyParser(dto); err != nil {
+		badReq := restErrors.NewBadRequestError("invalid request body")
+		return c.Status(badReq.StatusCode()).JSON(badReq)
+	}
+
+	err := dto.Validate()
+	if err != nil {
+		return c.Status(err.StatusCode()).JSON(err)
+	}
+
+	permissions, _ := c.Locals("permissions").([]string)
+
+	job, err := service.Start(c.Locals("namespace").(string), *dto, permissions)
+	if err != nil {
+		return c.Status(err.StatusCode()).JSON(err)
+	}
+
+	if dto.Async {
+		return c.Status(http.StatusAccepted).JSON(responder.NewResponse(job))
+	}
+	return c.Status(http.StatusOK).JSON(responder.NewResponse(job))
+}
+
+// Get returns the bulk job progress and its per node results
+func Get(c *fiber.Ctx) error {
+	job, err := service.Job(c.Locals("namespace").(string), c.Params(idKeyword))
+	if err != nil {
+		return c.Status(err.StatusCode()).JSON(err)
+	}
+
+	return c.Status(http.StatusOK).JSON(responder.NewResponse(job))
+}
@@ -0,0 +1,178 @@
+package bulk
+
+import (
+	"bytes"
+	"encoding/json"
+	"github.com/gofiber/fiber/v2"
+	"github.com/kotalco/core-api/core/bulk"
+	restErrors "github.com/kotalco/core-api/pkg/errors"
+	"github.com/kotalco/core-api/pkg/roles"
+	"github.com/stretchr/testify/assert"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+)
+
+/*
+bulk service mocks
+*/
+var (
+	bulkStartFunc func(namespace string, dto bulk.BulkDto, permissions []string) (bulk.JobDto, restErrors.IRestErr)
+	bulkJobFunc   func(namespace string, id string) (bulk.JobDto, restErrors.IRestErr)
+)
+
+type bulkServiceMock struct{}
+
+func (bulkServiceMock) Start(namespace string, dto bulk.BulkDto, permissions []string) (bulk.JobDto, restErrors.IRestErr) {
+	return bulkStartFunc(namespace, dto, permissions)
+}
+func (bulkServiceMock) Job(namespace string, id string) (bulk.JobDto, restErrors.IRestErr) {
+	return bulkJobFunc(namespace, id)
+}
+
+func TestMain(m *testing.M) {
+	service = &bulkServiceMock{}
+
+	code := m.Run()
+	os.Exit(code)
+}
+
+var locals = map[string]interface{}{
+	"namespace":   "default",
+	"permissions": []string{roles.Permission(roles.EthereumNodes, roles.Update)},
+}
+
+func newFiberCtx(method string, path string, dto interface{}, handler fiber.Handler) ([]byte, *http.Response) {
+	app := fiber.New()
+	app.Add(method, "/test/:id?", func(c *fiber.Ctx) error {
+		for key, element := range locals {
+			c.Locals(key, element)
+		}
+		return c.Next()
+	}, handler)
+
+	marshaledDto, err := json.Marshal(dto)
+	if err != nil {
+		panic(err.Error())
+	}
+
+	req := httptest.NewRequest(method, "/test"+path, bytes.NewBuffer(marshaledDto))
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := app.Test(req)
+	if err != nil {
+		panic(err.Error())
+	}
+
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		panic(err.Error())
+	}
+
+	return body, resp
+}
+
+func TestCreate(t *testing.T) {
+	t.Run("create_should_return_the_per_node_results", func(t *testing.T) {
+		bulkStartFunc = func(namespace string, dto bulk.BulkDto, permissions []string) (bulk.JobDto, restErrors.IRestErr) {
+			assert.EqualValues(t, "default", namespace)
+			assert.EqualValues(t, locals["permissions"], permissions)
+			return bulk.JobDto{ID: "1", Action: dto.Action, Status: bulk.CompletedStatus, Total: 1, Completed: 1,
+				Results: []bulk.NodeResultDto{{Resource: roles.EthereumNodes, Name: "geth-1", Status: bulk.SucceededStatus}}}, nil
+		}
+
+		dto := bulk.BulkDto{Selector: bulk.SelectorDto{Protocol: "ethereum"}, Action: bulk.UpdateImageAction, Image: "geth:v1.13"}
+		body, resp := newFiberCtx(http.MethodPost, "/", dto, Create)
+		var result map[string]bulk.JobDto
+		err := json.Unmarshal(body, &result)
+		if err != nil {
+			panic(err.Error())
+		}
+
+		assert.EqualValues(t, http.StatusOK, resp.StatusCode)
+		assert.EqualValues(t, bulk.CompletedStatus, result["data"].Status)
+		assert.EqualValues(t, "geth-1", result["data"].Results[0].Name)
+	})
+
+	t.Run("create_should_accept_async_jobs", func(t *testing.T) {
+		bulkStartFunc = func(namespace string, dto bulk.BulkDto, permissions []string) (bulk.JobDto, restErrors.IRestErr) {
+			return bulk.JobDto{ID: "1", Action: dto.Action, Status: bulk.RunningStatus}, nil
+		}
+
+		dto := bulk.BulkDto{Selector: bulk.SelectorDto{Network: "goerli"}, Action: bulk.RestartAction, Async: true}
+		body, resp := newFiberCtx(http.MethodPost, "/", dto, Create)
+		var result map[string]bulk.JobDto
+		err := json.Unmarshal(body, &result)
+		if err != nil {
+			panic(err.Error())
+		}
+
+		assert.EqualValues(t, http.StatusAccepted, resp.StatusCode)
+		assert.EqualValues(t, "1", result["data"].ID)
+	})
+
+	t.Run("create_should_throw_validation_error", func(t *testing.T) {
+		body, resp := newFiberCtx(http.MethodPost, "/", bulk.BulkDto{Action: bulk.DeleteAction}, Create)
+		var result restErrors.RestErr
+		err := json.Unmarshal(body, &result)
+		if err != nil {
+			panic(err.Error())
+		}
+
+		assert.EqualValues(t, http.StatusBadRequest, resp.StatusCode)
+		assert.Contains(t, result.Validations, "selector")
+	})
+
+	t.Run("create_should_throw_if_service_throws", func(t *testing.T) {
+		bulkStartFunc = func(namespace string, dto bulk.BulkDto, permissions []string) (bulk.JobDto, restErrors.IRestErr) {
+			return bulk.JobDto{}, restErrors.NewInternalServerError("something went wrong")
+		}
+
+		dto := bulk.BulkDto{Selector: bulk.SelectorDto{Names: []string{"geth-1"}}, Action: bulk.DeleteAction}
+		body, resp := newFiberCtx(http.MethodPost, "/", dto, Create)
+		var result restErrors.RestErr
+		err := json.Unmarshal(body, &result)
+		if err != nil {
+			panic(err.Error())
+		}
+
+		assert.EqualValues(t, http.StatusInternalServerError, resp.StatusCode)
+		assert.EqualValues(t, "something went wrong", result.Message)
+	})
+}
+
+func TestGet(t *testing.T) {
+	t.Run("get_should_return_the_job_progress", func(t *testing.T) {
+		bulkJobFunc = func(namespace string, id string) (bulk.JobDto, restErrors.IRestErr) {
+			assert.EqualValues(t, "1", id)
+			return bulk.JobDto{ID: id, Status: bulk.RunningStatus, Total: 3, Completed: 1}, nil
+		}
+
+		body, resp := newFiberCtx(http.MethodGet, "/1", nil, Get)
+		var result map[string]bulk.JobDto
+		err := json.Unmarshal(body, &result)
+		if err != nil {
+			panic(err.Error())
+		}
+
+		assert.EqualValues(t, http.StatusOK, resp.StatusCode)
+		assert.EqualValues(t, 1, result["data"].Completed)
+	})
+
+	t.Run("get_should_throw_if_job_doesn't_exist", func(t *testing.T) {
+		bulkJobFunc = func(namespace string, id string) (bulk.JobDto, restErrors.IRestErr) {
+			return bulk.JobDto{}, restErrors.NewNotFoundError("bulk job by id 2 doesn't exist")
+		}
+
+		body, resp := newFiberCtx(http.MethodGet, "/2", nil, Get)
+		var result restErrors.RestErr
+		err := json.Unmarshal(body, &result)
+		if err != nil {
+			panic(err.Error())
+		}
+
+		assert.EqualValues(t, http.StatusNotFound, resp.StatusCode)
+		assert.EqualValues(t, "bulk job by id 2 doesn't exist", result.Message)
+	})
+}
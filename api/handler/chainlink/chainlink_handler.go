@@ -5,6 +5,8 @@ package chainlink
 import (
 	"fmt"
 	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/websocket/v2"
+	"github.com/kotalco/core-api/api/handler/shared"
 	"github.com/kotalco/core-api/core/chainlink"
 	restErrors "github.com/kotalco/core-api/pkg/errors"
 	"github.com/kotalco/core-api/pkg/pagination"
@@ -14,13 +16,18 @@ import (
 	"net/http"
 	"sort"
 	"strconv"
+	"time"
 )
 
 const (
-	nameKeyword = "name"
+	nameKeyword   = "name"
+	idKeyword     = "id"
+	bridgeKeyword = "bridge"
 )
 
-var service = chainlink.NewChainLinkService()
+var (
+	service = chainlink.NewChainLinkService()
+)
 
 // Get returns a single chainlink node by name
 // 1-get the node validated from ValidateNodeExist method
@@ -31,9 +38,9 @@ func Get(c *fiber.Ctx) error {
 }
 
 // Create creates chainlink node from the given spec
-// 1-Todo validate request body and return validation error
-// 2-call chain link service to create chainlink node
-// 2-marshall node to and format the response
+// 1-validate the node name, the ethereum endpoints or node reference and the database url or managed database
+// 2-call chain link service to create chainlink node, the referenced ethereum node must serve the chain id
+// 3-marshall node to and format the response
 func Create(c *fiber.Ctx) error {
 	dto := new(chainlink.ChainlinkDto)
 	if err := c.BodyParser(dto); err != nil {
@@ -43,7 +50,7 @@ func Create(c *fiber.Ctx) error {
 
 	dto.Namespace = c.Locals("namespace").(string)
 
-	err := dto.MetaDataDto.Validate()
+	err := dto.Validate()
 	if err != nil {
 		return c.Status(err.StatusCode()).JSON(err)
 	}
@@ -120,6 +127,14 @@ func Delete(c *fiber.Ctx) error {
 	return c.SendStatus(http.StatusNoContent)
 }
 
+// ExpandStorage expands the node volume to the given storage size
+// 1-get node from locals which checked and assigned by ValidateNodeExist
+// 2-call shared ExpandStorage to validate the storage size and expand the volume
+func ExpandStorage(c *fiber.Ctx) error {
+	node := c.Locals("node").(chainlinkv1alpha1.Node)
+	return shared.ExpandStorage(c, &node, &node.Spec.Resources)
+}
+
 // Count returns total number of nodes
 // 1-call chainlink service to get exiting node list
 // 2-create X-Total-Count header with the length
@@ -136,6 +151,180 @@ func Count(c *fiber.Ctx) error {
 	return c.SendStatus(http.StatusOK)
 }
 
+// Jobs lists the node jobs with the errors they hit
+// 1-get node from locals which checked and assigned by ValidateNodeExist
+// 2-call service to list the jobs through the node api using the node api credentials
+// 3-format the response
+func Jobs(c *fiber.Ctx) error {
+	node := c.Locals("node").(chainlinkv1alpha1.Node)
+
+	jobs, err := service.Jobs(node)
+	if err != nil {
+		return c.Status(err.StatusCode()).JSON(err)
+	}
+
+	return c.Status(http.StatusOK).JSON(responder.NewResponse(jobs))
+}
+
+// CreateJob creates a job on the node from its TOML spec
+// 1-validate the job spec
+// 2-get node from locals which checked and assigned by ValidateNodeExist
+// 3-call service to create the job, the node validates the spec
+// 4-format the response
+func CreateJob(c *fiber.Ctx) error {
+	dto := new(chainlink.JobRequestDto)
+	if err := c.BodyParser(dto); err != nil {
+		badReq := restErrors.NewBadRequestError("invalid request body")
+		return c.Status(badReq.StatusCode()).JSON(badReq)
+	}
+
+	err := dto.Validate()
+	if err != nil {
+		return c.Status(err.StatusCode()).JSON(err)
+	}
+
+	node := c.Locals("node").(chainlinkv1alpha1.Node)
+
+	job, err := service.CreateJob(node, *dto)
+	if err != nil {
+		return c.Status(err.StatusCode()).JSON(err)
+	}
+
+	return c.Status(http.StatusCreated).JSON(responder.NewResponse(job))
+}
+
+// DeleteJob deletes the node job by id
+// 1-get node from locals which checked and assigned by ValidateNodeExist
+// 2-call service to delete the job
+// 3-return no content if deleted with no errors
+func DeleteJob(c *fiber.Ctx) error {
+	node := c.Locals("node").(chainlinkv1alpha1.Node)
+
+	err := service.DeleteJob(node, c.Params(idKeyword))
+	if err != nil {
+		return c.Status(err.StatusCode()).JSON(err)
+	}
+
+	return c.SendStatus(http.StatusNoContent)
+}
+
+// RunJob triggers a run of the node webhook job by id
+// 1-get node from locals which checked and assigned by ValidateNodeExist
+// 2-call service to trigger the job run
+// 3-format the response
+func RunJob(c *fiber.Ctx) error {
+	node := c.Locals("node").(chainlinkv1alpha1.Node)
+
+	run, err := service.RunJob(node, c.Params(idKeyword))
+	if err != nil {
+		return c.Status(err.StatusCode()).JSON(err)
+	}
+
+	return c.Status(http.StatusCreated).JSON(responder.NewResponse(run))
+}
+
+// JobRuns lists the node job runs with their errors
+// 1-get node from locals which checked and assigned by ValidateNodeExist
+// 2-call service to list the job runs
+// 3-format the response
+func JobRuns(c *fiber.Ctx) error {
+	node := c.Locals("node").(chainlinkv1alpha1.Node)
+
+	runs, err := service.JobRuns(node, c.Params(idKeyword))
+	if err != nil {
+		return c.Status(err.StatusCode()).JSON(err)
+	}
+
+	return c.Status(http.StatusOK).JSON(responder.NewResponse(runs))
+}
+
+// Bridges lists the node bridges
+// 1-get node from locals which checked and assigned by ValidateNodeExist
+// 2-call service to list the bridges through the node api
+// 3-format the response
+func Bridges(c *fiber.Ctx) error {
+	node := c.Locals("node").(chainlinkv1alpha1.Node)
+
+	bridges, err := service.Bridges(node)
+	if err != nil {
+		return c.Status(err.StatusCode()).JSON(err)
+	}
+
+	return c.Status(http.StatusOK).JSON(responder.NewResponse(bridges))
+}
+
+// CreateBridge creates an external adapter bridge on the node
+// 1-validate the bridge name and url
+// 2-get node from locals which checked and assigned by ValidateNodeExist
+// 3-call service to create the bridge
+// 4-format the response holding the bridge tokens which are returned once
+func CreateBridge(c *fiber.Ctx) error {
+	dto := new(chainlink.BridgeDto)
+	if err := c.BodyParser(dto); err != nil {
+		badReq := restErrors.NewBadRequestError("invalid request body")
+		return c.Status(badReq.StatusCode()).JSON(badReq)
+	}
+
+	err := dto.Validate()
+	if err != nil {
+		return c.Status(err.StatusCode()).JSON(err)
+	}
+
+	node := c.Locals("node").(chainlinkv1alpha1.Node)
+
+	bridge, err := service.CreateBridge(node, *dto)
+	if err != nil {
+		return c.Status(err.StatusCode()).JSON(err)
+	}
+
+	return c.Status(http.StatusCreated).JSON(responder.NewResponse(bridge))
+}
+
+// DeleteBridge deletes the node bridge by name
+// 1-get node from locals which checked and assigned by ValidateNodeExist
+// 2-call service to delete the bridge
+// 3-return no content if deleted with no errors
+func DeleteBridge(c *fiber.Ctx) error {
+	node := c.Locals("node").(chainlinkv1alpha1.Node)
+
+	err := service.DeleteBridge(node, c.Params(bridgeKeyword))
+	if err != nil {
+		return c.Status(err.StatusCode()).JSON(err)
+	}
+
+	return c.SendStatus(http.StatusNoContent)
+}
+
+// Stats returns a websocket that emits the node jobs count, health checks and eth keys balances
+func Stats(c *websocket.Conn) {
+	defer c.Close()
+
+	nameSpacedName := types.NamespacedName{
+		Namespace: c.Locals("namespace").(string),
+		Name:      c.Params(nameKeyword),
+	}
+
+	for {
+		node, err := service.Get(nameSpacedName)
+		if err != nil {
+			c.WriteJSON(err)
+			return
+		}
+
+		stats, err := service.Stats(node)
+		if err != nil {
+			c.WriteJSON(err)
+			return
+		}
+
+		if intErr := c.WriteJSON(stats); intErr != nil {
+			return
+		}
+
+		time.Sleep(time.Second)
+	}
+}
+
 // ValidateNodeExist validate node by name exist acts as a validation for all handlers the needs to find chainlink node by name
 // 1-call chainlink service to check if node exits
 // 2-return 404 if it's not
@@ -78,15 +78,17 @@ func (s svcServiceMock) Create(obj *corev1.Service) restErrors.IRestErr {
 }
 
 var (
-	settingWithTransaction            func(txHandle *gorm.DB) setting.IService
-	settingSettingsFunc               func() ([]*setting.Setting, restErrors.IRestErr)
-	settingConfigureDomainFunc        func(dto *setting.ConfigureDomainRequestDto) restErrors.IRestErr
-	settingIsDomainConfiguredFunc     func() bool
-	settingConfigureRegistrationFunc  func(dto *setting.ConfigureRegistrationRequestDto) restErrors.IRestErr
-	settingGetDomainFunc              func() (string, restErrors.IRestErr)
-	settingIsRegistrationEnabledFunc  func() bool
-	settingConfigureActivationKeyFunc func(key string) restErrors.IRestErr
-	settingGetActivationKeyFunc       func() (string, restErrors.IRestErr)
+	settingWithTransaction             func(txHandle *gorm.DB) setting.IService
+	settingSettingsFunc                func() ([]*setting.Setting, restErrors.IRestErr)
+	settingConfigureDomainFunc         func(dto *setting.ConfigureDomainRequestDto) restErrors.IRestErr
+	settingIsDomainConfiguredFunc      func() bool
+	settingConfigureRegistrationFunc   func(dto *setting.ConfigureRegistrationRequestDto) restErrors.IRestErr
+	settingGetDomainFunc               func() (string, restErrors.IRestErr)
+	settingIsRegistrationEnabledFunc   func() bool
+	settingConfigurePasswordPolicyFunc func(dto *setting.PasswordPolicyDto) restErrors.IRestErr
+	settingGetPasswordPolicyFunc       func() (*setting.PasswordPolicyDto, restErrors.IRestErr)
+	settingConfigureActivationKeyFunc  func(key string) restErrors.IRestErr
+	settingGetActivationKeyFunc        func() (string, restErrors.IRestErr)
 )
 
 type settingServiceMock struct{}
@@ -111,6 +113,42 @@ func (s settingServiceMock) IsRegistrationEnabled() bool {
 	return settingIsRegistrationEnabledFunc()
 }
 
+func (s settingServiceMock) ConfigurePasswordPolicy(dto *setting.PasswordPolicyDto) restErrors.IRestErr {
+	return settingConfigurePasswordPolicyFunc(dto)
+}
+
+func (s settingServiceMock) GetPasswordPolicy() (*setting.PasswordPolicyDto, restErrors.IRestErr) {
+	return settingGetPasswordPolicyFunc()
+}
+
+func (s settingServiceMock) ConfigureMail(dto *setting.MailSettingsDto) restErrors.IRestErr {
+	return nil
+}
+
+func (s settingServiceMock) GetMailSettings() (*setting.MailSettingsDto, restErrors.IRestErr) {
+	return setting.DefaultMailSettings(), nil
+}
+
+func (s settingServiceMock) ConfigureMailTemplate(name string, dto *setting.MailTemplateDto) restErrors.IRestErr {
+	return nil
+}
+
+func (s settingServiceMock) GetMailTemplate(name string) (*setting.MailTemplateDto, restErrors.IRestErr) {
+	return nil, restErrors.NewNotFoundError("not found")
+}
+
+func (s settingServiceMock) DeleteMailTemplate(name string) restErrors.IRestErr {
+	return nil
+}
+
+func (s settingServiceMock) ConfigureCheckpointSyncProviders(dto *setting.CheckpointSyncProvidersDto) restErrors.IRestErr {
+	return nil
+}
+
+func (s settingServiceMock) GetCheckpointSyncProviders() (*setting.CheckpointSyncProvidersDto, restErrors.IRestErr) {
+	return setting.DefaultCheckpointSyncProviders(), nil
+}
+
 func (s settingServiceMock) WithoutTransaction() setting.IService {
 	return s
 }
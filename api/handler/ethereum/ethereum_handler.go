@@ -7,6 +7,7 @@ import (
 	"fmt"
 	"github.com/gofiber/fiber/v2"
 	"github.com/gofiber/websocket/v2"
+	"github.com/kotalco/core-api/api/handler/shared"
 	"github.com/kotalco/core-api/core/ethereum"
 	restErrors "github.com/kotalco/core-api/pkg/errors"
 	"github.com/kotalco/core-api/pkg/pagination"
@@ -27,7 +28,9 @@ const (
 	nameKeyword = "name"
 )
 
-var service = ethereum.NewEthereumService()
+var (
+	service = ethereum.NewEthereumService()
+)
 
 // Get returns a single ethereum node by name
 // 1-get the node validated from ValidateNodeExist method
@@ -127,6 +130,14 @@ func Delete(c *fiber.Ctx) error {
 	return c.SendStatus(http.StatusNoContent)
 }
 
+// ExpandStorage expands the node volume to the given storage size
+// 1-get node from locals which checked and assigned by ValidateNodeExist
+// 2-call shared ExpandStorage to validate the storage size and expand the volume
+func ExpandStorage(c *fiber.Ctx) error {
+	node := c.Locals("node").(ethereumv1alpha1.Node)
+	return shared.ExpandStorage(c, &node, &node.Spec.Resources)
+}
+
 // Count returns total number of nodes
 // 1-call ethereum service to get exiting node list
 // 2-create X-Total-Count header with the length
@@ -0,0 +1,125 @@
+// Package full_node handler is the representation layer for the ethereum full nodes
+// uses the full node service to manage the paired execution and beacon nodes as one unit
+package full_node
+
+import (
+	"fmt"
+	"github.com/gofiber/fiber/v2"
+	"github.com/kotalco/core-api/core/ethereum/full_node"
+	"github.com/kotalco/core-api/core/ethereum2/beacon_node"
+	restErrors "github.com/kotalco/core-api/pkg/errors"
+	"github.com/kotalco/core-api/pkg/pagination"
+	"github.com/kotalco/core-api/pkg/responder"
+	"k8s.io/apimachinery/pkg/types"
+	"net/http"
+	"strconv"
+)
+
+const (
+	nameKeyword = "name"
+)
+
+var service = full_node.NewService()
+
+// Create creates the execution node, the beacon node and their shared jwt secret
+// 1-validate the full node name, network and clients
+// 2-validate the checkpoint sync url serves the full node network
+// 3-call full node service to create the nodes, the created ones are rolled back on failure
+// 4-marshall full node to dto and format the response
+func Create(c *fiber.Ctx) error {
+	dto := new(full_node.FullNodeDto)
+	if err := c.BodyParser(dto); err != nil {
+		badReq := restErrors.NewBadRequestError("invalid request body")
+		return c.Status(badReq.StatusCode()).JSON(badReq)
+	}
+
+	dto.Namespace = c.Locals("namespace").(string)
+
+	err := dto.Validate()
+	if err != nil {
+		return c.Status(err.StatusCode()).JSON(err)
+	}
+
+	if dto.Consensus.CheckpointSyncURL != nil {
+		err = beacon_node.ValidateCheckpointSyncURL(*dto.Consensus.CheckpointSyncURL, dto.Network)
+		if err != nil {
+			return c.Status(err.StatusCode()).JSON(err)
+		}
+	}
+
+	fullNode, err := service.Create(*dto)
+	if err != nil {
+		return c.Status(err.StatusCode()).JSON(err)
+	}
+
+	return c.Status(http.StatusCreated).JSON(responder.NewResponse(full_node.FullNodeDto{}.FromFullNode(fullNode)))
+}
+
+// List returns the workspace full nodes with their combined status
+// 1-get the pagination qs default to 0
+// 2-call service to return the full nodes, newest first
+// 3-make the pagination and get the status of the page full nodes
+// 4-marshall full nodes to dto and format the response using NewResponse
+func List(c *fiber.Ctx) error {
+	page, _ := strconv.Atoi(c.Query("page"))
+	limit, _ := strconv.Atoi(c.Query("limit"))
+
+	fullNodes, err := service.List(c.Locals("namespace").(string))
+	if err != nil {
+		return c.Status(err.StatusCode()).JSON(err)
+	}
+
+	c.Set("Access-Control-Expose-Headers", "X-Total-Count")
+	c.Set("X-Total-Count", fmt.Sprintf("%d", len(fullNodes)))
+
+	start, end := pagination.Page(uint(len(fullNodes)), uint(page), uint(limit))
+	fullNodes = fullNodes[start:end]
+
+	result := full_node.FullNodeListDto{}.FromFullNode(fullNodes)
+	for i := range result {
+		status := service.Status(fullNodes[i])
+		result[i].Status = &status
+	}
+
+	return c.Status(http.StatusOK).JSON(responder.NewResponse(result))
+}
+
+// Get returns a single full node validated by ValidateFullNodeExist with its combined status
+func Get(c *fiber.Ctx) error {
+	fullNode := c.Locals("fullNode").(full_node.FullNode)
+
+	dto := full_node.FullNodeDto{}.FromFullNode(fullNode)
+	status := service.Status(fullNode)
+	dto.Status = &status
+
+	return c.Status(http.StatusOK).JSON(responder.NewResponse(dto))
+}
+
+// Delete deletes the full node beacon node, execution node and jwt secret together
+func Delete(c *fiber.Ctx) error {
+	fullNode := c.Locals("fullNode").(full_node.FullNode)
+
+	err := service.Delete(fullNode)
+	if err != nil {
+		return c.Status(err.StatusCode()).JSON(err)
+	}
+
+	return c.SendStatus(http.StatusNoContent)
+}
+
+// ValidateFullNodeExist validate full node by name exist acts as a validation for all handlers the needs to find full node by name
+// 1-call full node service to check if the full node exits
+// 2-return 404 if it's not
+// 3-save the full node to local with the key fullNode to be used by the other handlers
+func ValidateFullNodeExist(c *fiber.Ctx) error {
+	fullNode, err := service.Get(types.NamespacedName{
+		Namespace: c.Locals("namespace").(string),
+		Name:      c.Params(nameKeyword),
+	})
+	if err != nil {
+		return c.Status(err.StatusCode()).JSON(err)
+	}
+
+	c.Locals("fullNode", fullNode)
+	return c.Next()
+}
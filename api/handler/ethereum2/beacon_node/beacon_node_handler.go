@@ -1,20 +1,15 @@
 package beacon_node
 
 import (
-	"bytes"
-	"context"
-	"encoding/json"
 	"fmt"
 	"github.com/gofiber/fiber/v2"
 	"github.com/gofiber/websocket/v2"
+	"github.com/kotalco/core-api/api/handler/shared"
 	"github.com/kotalco/core-api/core/ethereum2/beacon_node"
-	"github.com/kotalco/core-api/k8s"
 	restErrors "github.com/kotalco/core-api/pkg/errors"
 	"github.com/kotalco/core-api/pkg/pagination"
 	"github.com/kotalco/core-api/pkg/responder"
 	ethereum2v1alpha1 "github.com/kotalco/kotal/apis/ethereum2/v1alpha1"
-	"io/ioutil"
-	"k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/types"
 	"net/http"
 	"sort"
@@ -22,23 +17,12 @@ import (
 	"time"
 )
 
-type request struct {
-	url  string
-	name string
-}
-type result struct {
-	err  error
-	name string
-	data []byte
-}
-
 const (
 	nameKeyword = "name"
 )
 
 var (
-	service   = beacon_node.NewBeaconNodeService()
-	k8sClient = k8s.NewClientService()
+	service = beacon_node.NewBeaconNodeService()
 )
 
 // Get gets a single ethereum 2.0 beacon node by name
@@ -77,8 +61,9 @@ func List(c *fiber.Ctx) error {
 
 // Create creates ethereum 2.0 beacon node from spec
 // 1-Todo validate request body and return validation error
-// 2-call beacon node service to create beacon node
-// 2-marshall node to dto and format the response
+// 2-validate the checkpoint sync url serves the node network
+// 3-call beacon node service to create beacon node
+// 4-marshall node to dto and format the response
 func Create(c *fiber.Ctx) error {
 	dto := new(beacon_node.BeaconNodeDto)
 	if err := c.BodyParser(dto); err != nil {
@@ -93,6 +78,13 @@ func Create(c *fiber.Ctx) error {
 		return c.Status(err.StatusCode()).JSON(err)
 	}
 
+	if dto.CheckpointSyncURL != nil {
+		err = beacon_node.ValidateCheckpointSyncURL(*dto.CheckpointSyncURL, dto.Network)
+		if err != nil {
+			return c.Status(err.StatusCode()).JSON(err)
+		}
+	}
+
 	node, err := service.Create(*dto)
 	if err != nil {
 		return c.Status(err.StatusCode()).JSON(err)
@@ -116,11 +108,20 @@ func Delete(c *fiber.Ctx) error {
 	return c.SendStatus(http.StatusNoContent)
 }
 
+// ExpandStorage expands the beacon node volume to the given storage size
+// 1-get beacon node from locals which checked and assigned by ValidateBeaconNodeExist
+// 2-call shared ExpandStorage to validate the storage size and expand the volume
+func ExpandStorage(c *fiber.Ctx) error {
+	node := c.Locals("node").(ethereum2v1alpha1.BeaconNode)
+	return shared.ExpandStorage(c, &node, &node.Spec.Resources)
+}
+
 // Update updates ethereum 2.0 beacon node by name from spec
 // 1-todo validate request body and return validation errors if exits
 // 2-get node from locals which checked and assigned by ValidateNodeExist
-// 3-call beacon node  service to update node which returns *ethereum2v1alpha1.BeaconNode
-// 4-marshall node to node dto and format the response
+// 3-validate the checkpoint sync url serves the node network if changed
+// 4-call beacon node  service to update node which returns *ethereum2v1alpha1.BeaconNode
+// 5-marshall node to node dto and format the response
 func Update(c *fiber.Ctx) error {
 	dto := new(beacon_node.BeaconNodeDto)
 
@@ -131,6 +132,13 @@ func Update(c *fiber.Ctx) error {
 
 	beaconnode := c.Locals("node").(ethereum2v1alpha1.BeaconNode)
 
+	if dto.CheckpointSyncURL != nil && *dto.CheckpointSyncURL != beaconnode.Spec.CheckpointSyncURL {
+		err := beacon_node.ValidateCheckpointSyncURL(*dto.CheckpointSyncURL, beaconnode.Spec.Network)
+		if err != nil {
+			return c.Status(err.StatusCode()).JSON(err)
+		}
+	}
+
 	err := service.Update(*dto, &beaconnode)
 	if err != nil {
 		return c.Status(err.StatusCode()).JSON(err)
@@ -178,150 +186,33 @@ func ValidateBeaconNodeExist(c *fiber.Ctx) error {
 func Stats(c *websocket.Conn) {
 	defer c.Close()
 
-	name := c.Params("name")
-	beaconnode := &ethereum2v1alpha1.BeaconNode{}
 	nameSpacedName := types.NamespacedName{
 		Namespace: c.Locals("namespace").(string),
-		Name:      name,
-	}
-	err := k8sClient.Get(context.Background(), nameSpacedName, beaconnode)
-	if err != nil {
-		if errors.IsNotFound(err) {
-			c.WriteJSON(fiber.Map{
-				"error": fmt.Sprintf("beacon node by name %s doesn't exist", name),
-			})
-			return
-		}
-		c.WriteJSON(fiber.Map{
-			"error": err.Error(),
-		})
-		return
+		Name:      c.Params(nameKeyword),
 	}
 
-	var baseUrl string
-	//Prysm client implements its API by using gRPC
-	if beaconnode.Spec.Client == ethereum2v1alpha1.PrysmClient {
-		if !beaconnode.Spec.GRPC {
+	for {
+		beaconnode, err := service.Get(nameSpacedName)
+		if err != nil {
 			c.WriteJSON(fiber.Map{
-				"error": "gRPC sever is not enabled",
+				"error": err.Error(),
 			})
 			return
 		}
-		baseUrl = fmt.Sprintf("http://%s.%s:%d/eth/v1/node/", nameSpacedName.Name, nameSpacedName.Namespace, beaconnode.Spec.GRPCPort)
-		//The remaining clients uses RestApi
-	} else {
-		if !beaconnode.Spec.REST {
+
+		stats, err := service.Stats(beaconnode)
+		if err != nil {
 			c.WriteJSON(fiber.Map{
-				"error": "REST API sever is not enabled",
+				"error": err.Error(),
 			})
-			return
-		}
-		baseUrl = fmt.Sprintf("http://%s.%s:%d/eth/v1/node/", nameSpacedName.Name, nameSpacedName.Namespace, beaconnode.Spec.RESTPort)
-	}
-
-	for {
-		jobs := make(chan request, 2)
-		results := make(chan result, 2)
-
-		for i := 0; i < 2; i++ {
-			go worker(jobs, results)
-		}
-
-		jobs <- request{name: "peers", url: fmt.Sprintf("%speer_count", baseUrl)}
-		jobs <- request{name: "isSyncing", url: fmt.Sprintf("%ssyncing", baseUrl)}
-
-		close(jobs)
-
-		var nodeStatResponseDto struct {
-			CurrentSlot int  `json:"currentSlot"`
-			TargetSlot  int  `json:"targetSlot"`
-			PeersCount  int  `json:"peersCount"`
-			Syncing     bool `json:"syncing"`
-		}
-
-		for i := 0; i < 2; i++ {
-			resp := <-results
-			if resp.err != nil {
-				c.WriteJSON(fiber.Map{
-					"error": err.Error(),
-				})
-				continue
+			// the api server isn't enabled, no stats until the node is updated
+			if err.StatusCode() == http.StatusBadRequest {
+				return
 			}
-			switch resp.name {
-			case "peers":
-				var responseBody struct {
-					Data struct {
-						Connected string `json:"connected"`
-					} `json:"data"`
-				}
-				err = json.Unmarshal(resp.data, &responseBody)
-				if err != nil {
-					c.WriteJSON(fiber.Map{
-						"error": err.Error(),
-					})
-					break
-				}
-				nodeStatResponseDto.PeersCount, _ = strconv.Atoi(responseBody.Data.Connected)
-				break
-			case "isSyncing":
-				var responseBody struct {
-					Data struct {
-						HeadSlot     string `json:"head_slot"`
-						SyncDistance string `json:"sync_distance"`
-						IsSyncing    bool   `json:"is_syncing"`
-					} `json:"data"`
-				}
-				err = json.Unmarshal(resp.data, &responseBody)
-				if err != nil {
-					c.WriteJSON(fiber.Map{
-						"error": err.Error(),
-					})
-					break
-				}
-				nodeStatResponseDto.CurrentSlot, _ = strconv.Atoi(responseBody.Data.HeadSlot)
-				nodeStatResponseDto.Syncing = responseBody.Data.IsSyncing
-				syncingDistance, _ := strconv.Atoi(responseBody.Data.SyncDistance)
-				nodeStatResponseDto.TargetSlot = syncingDistance + nodeStatResponseDto.CurrentSlot
-				break
-			}
-		}
-		close(results)
-
-		err := c.WriteJSON(nodeStatResponseDto)
-		if err != nil {
+		} else if c.WriteJSON(stats) != nil {
 			return
 		}
 
 		time.Sleep(time.Second * 3)
 	}
 }
-
-// worker is a  collection of threads for the beacon node stats
-func worker(jobs <-chan request, results chan<- result) {
-	chanRes := result{}
-	for job := range jobs {
-		chanRes.name = job.name
-
-		client := http.Client{
-			Timeout: 4 * time.Second,
-		}
-		req, err := http.NewRequest(http.MethodGet, job.url, bytes.NewReader([]byte(nil)))
-		if err != nil {
-			chanRes.err = err
-			return
-		}
-		resp, err := client.Do(req)
-		if err != nil {
-			chanRes.err = err
-			return
-		}
-
-		responseData, err := ioutil.ReadAll(resp.Body)
-		if err != nil {
-			chanRes.err = err
-			return
-		}
-		chanRes.data = responseData
-		results <- chanRes
-	}
-}
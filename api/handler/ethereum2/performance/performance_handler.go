@@ -0,0 +1,103 @@
+// Package performance handler is the representation layer for the ethereum 2.0 validators performance
+// uses the performance service to query the validators beacon node and their recorded balances
+package performance
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/websocket/v2"
+	"github.com/kotalco/core-api/core/ethereum2/performance"
+	"github.com/kotalco/core-api/core/ethereum2/validator"
+	restErrors "github.com/kotalco/core-api/pkg/errors"
+	"github.com/kotalco/core-api/pkg/responder"
+	ethereum2v1alpha1 "github.com/kotalco/kotal/apis/ethereum2/v1alpha1"
+	"k8s.io/apimachinery/pkg/types"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+const (
+	nameKeyword = "name"
+	// defaultEpochs is the balance history length if the epochs query string isn't given, about a week of mainnet epochs
+	defaultEpochs = 1575
+)
+
+var (
+	service          = performance.NewService()
+	validatorService = validator.NewValidatorService()
+)
+
+// Get returns the validator keys status, balances and duties
+// 1-get the validator validated from ValidateValidatorExist method
+// 2-call performance service to query the validator beacon node
+// 3-format the response
+func Get(c *fiber.Ctx) error {
+	model := c.Locals("validator").(ethereum2v1alpha1.Validator)
+
+	dto, err := service.Performance(model)
+	if err != nil {
+		return c.Status(err.StatusCode()).JSON(err)
+	}
+
+	return c.Status(http.StatusOK).JSON(responder.NewResponse(dto))
+}
+
+// History returns the validator balances recorded per epoch for the rewards charts
+// 1-get the epochs qs default to defaultEpochs
+// 2-call performance service to return the recorded balances grouped by epoch
+// 3-format the response
+func History(c *fiber.Ctx) error {
+	epochs := uint64(defaultEpochs)
+	if qs := c.Query("epochs"); qs != "" {
+		value, err := strconv.ParseUint(qs, 10, 64)
+		if err != nil || value == 0 {
+			badReq := restErrors.NewBadRequestError("epochs must be a positive number")
+			return c.Status(badReq.StatusCode()).JSON(badReq)
+		}
+		epochs = value
+	}
+
+	model := c.Locals("validator").(ethereum2v1alpha1.Validator)
+
+	history, err := service.History(model, epochs)
+	if err != nil {
+		return c.Status(err.StatusCode()).JSON(err)
+	}
+
+	return c.Status(http.StatusOK).JSON(responder.NewResponse(history))
+}
+
+// Stats returns a websocket that emits the validator keys status, balances and duties
+func Stats(c *websocket.Conn) {
+	defer c.Close()
+
+	nameSpacedName := types.NamespacedName{
+		Namespace: c.Locals("namespace").(string),
+		Name:      c.Params(nameKeyword),
+	}
+
+	for {
+		model, err := validatorService.Get(nameSpacedName)
+		if err != nil {
+			c.WriteJSON(fiber.Map{
+				"error": err.Error(),
+			})
+			return
+		}
+
+		dto, err := service.Performance(model)
+		if err != nil {
+			c.WriteJSON(fiber.Map{
+				"error": err.Error(),
+			})
+			// the validator has no beacon node api to query until it's updated
+			if err.StatusCode() == http.StatusBadRequest {
+				return
+			}
+		} else if c.WriteJSON(dto) != nil {
+			return
+		}
+
+		time.Sleep(time.Second * 12)
+	}
+}
@@ -3,22 +3,31 @@ package validator
 import (
 	"fmt"
 	"github.com/gofiber/fiber/v2"
+	"github.com/kotalco/core-api/api/handler/shared"
+	"github.com/kotalco/core-api/core/audit"
 	"github.com/kotalco/core-api/core/ethereum2/validator"
+	"github.com/kotalco/core-api/core/workspaceuser"
 	restErrors "github.com/kotalco/core-api/pkg/errors"
+	"github.com/kotalco/core-api/pkg/logger"
 	"github.com/kotalco/core-api/pkg/pagination"
 	"github.com/kotalco/core-api/pkg/responder"
+	"github.com/kotalco/core-api/pkg/token"
 	ethereum2v1alpha1 "github.com/kotalco/kotal/apis/ethereum2/v1alpha1"
 	"k8s.io/apimachinery/pkg/types"
 	"net/http"
 	"sort"
 	"strconv"
+	"strings"
 )
 
 const (
 	nameKeyword = "name"
 )
 
-var service = validator.NewValidatorService()
+var (
+	service      = validator.NewValidatorService()
+	auditService = audit.NewService()
+)
 
 // Get gets a single Ethereum 2.0 validator client by name
 // 1-get the node validated from ValidateNodeExist method
@@ -96,6 +105,14 @@ func Delete(c *fiber.Ctx) error {
 	return c.SendStatus(http.StatusNoContent)
 }
 
+// ExpandStorage expands the validator volume to the given storage size
+// 1-get validator from locals which checked and assigned by ValidateValidatorExist
+// 2-call shared ExpandStorage to validate the storage size and expand the volume
+func ExpandStorage(c *fiber.Ctx) error {
+	validator := c.Locals("validator").(ethereum2v1alpha1.Validator)
+	return shared.ExpandStorage(c, &validator, &validator.Spec.Resources)
+}
+
 // Update updates Ethereum 2.0 validator client by name from spec
 // 1-todo validate request body and return validation errors if exits
 // 2-get node from locals which checked and assigned by ValidateNodeExist
@@ -135,6 +152,124 @@ func Count(c *fiber.Ctx) error {
 	return c.SendStatus(http.StatusOK)
 }
 
+// UpdateKeymanager saves the validator client keymanager api port and bearer token secret used by the keys handlers
+// 1-validate the token secret is given
+// 2-get validator from locals which checked and assigned by ValidateValidatorExist
+// 3-call validator service to check the token secret and update the validator
+func UpdateKeymanager(c *fiber.Ctx) error {
+	dto := new(validator.KeymanagerDto)
+	if err := c.BodyParser(dto); err != nil {
+		badReq := restErrors.NewBadRequestError("invalid request body")
+		return c.Status(badReq.StatusCode()).JSON(badReq)
+	}
+
+	err := dto.Validate()
+	if err != nil {
+		return c.Status(err.StatusCode()).JSON(err)
+	}
+
+	validatorNode := c.Locals("validator").(ethereum2v1alpha1.Validator)
+
+	err = service.UpdateKeymanager(*dto, &validatorNode)
+	if err != nil {
+		return c.Status(err.StatusCode()).JSON(err)
+	}
+
+	return c.Status(http.StatusOK).JSON(responder.NewResponse(new(validator.ValidatorDto).FromEthereum2Validator(validatorNode)))
+}
+
+// Keys lists the public keys loaded by the validator client using its keymanager api
+func Keys(c *fiber.Ctx) error {
+	validatorNode := c.Locals("validator").(ethereum2v1alpha1.Validator)
+
+	keys, err := service.Keys(validatorNode)
+	if err != nil {
+		return c.Status(err.StatusCode()).JSON(err)
+	}
+
+	return c.Status(http.StatusOK).JSON(responder.NewResponse(keys))
+}
+
+// ImportKeys imports keystores into the validator client
+// 1-validate the keystores secrets are given
+// 2-call validator service to import the keystores and add the imported ones to the validator spec
+// 3-record the key operation to the audit table and return the import results
+func ImportKeys(c *fiber.Ctx) error {
+	dto := new(validator.ImportKeysDto)
+	if err := c.BodyParser(dto); err != nil {
+		badReq := restErrors.NewBadRequestError("invalid request body")
+		return c.Status(badReq.StatusCode()).JSON(badReq)
+	}
+
+	err := dto.Validate()
+	if err != nil {
+		return c.Status(err.StatusCode()).JSON(err)
+	}
+
+	validatorNode := c.Locals("validator").(ethereum2v1alpha1.Validator)
+
+	results, err := service.ImportKeys(*dto, &validatorNode)
+	if results != nil {
+		if auditErr := auditKeys(c, validatorNode, "import", results); auditErr != nil {
+			return c.Status(auditErr.StatusCode()).JSON(auditErr)
+		}
+	}
+	if err != nil {
+		return c.Status(err.StatusCode()).JSON(err)
+	}
+
+	return c.Status(http.StatusOK).JSON(responder.NewResponse(results))
+}
+
+// RemoveKeys removes keys from the validator client
+// 1-validate the public keys
+// 2-call validator service to remove the keys, export their slashing protection and remove them from the validator spec
+// 3-record the key operation to the audit table, its failure is returned as a warning since the keys are already removed
+// 4-return the removal results with the slashing protection interchange
+func RemoveKeys(c *fiber.Ctx) error {
+	dto := new(validator.RemoveKeysDto)
+	if err := c.BodyParser(dto); err != nil {
+		badReq := restErrors.NewBadRequestError("invalid request body")
+		return c.Status(badReq.StatusCode()).JSON(badReq)
+	}
+
+	err := dto.Validate()
+	if err != nil {
+		return c.Status(err.StatusCode()).JSON(err)
+	}
+
+	validatorNode := c.Locals("validator").(ethereum2v1alpha1.Validator)
+
+	removed, err := service.RemoveKeys(*dto, &validatorNode)
+	if err != nil {
+		return c.Status(err.StatusCode()).JSON(err)
+	}
+
+	// the keys are already removed, the audit failure is reported without dropping the slashing protection interchange
+	if auditErr := auditKeys(c, validatorNode, "remove", removed.Results); auditErr != nil {
+		go logger.Error("REMOVE_VALIDATOR_KEYS", auditErr)
+		removed.Warnings = append(removed.Warnings, "key removal can't be recorded to the audit table")
+	}
+
+	return c.Status(http.StatusOK).JSON(responder.NewResponse(removed))
+}
+
+// auditKeys records the validator key operation results before the response is returned
+func auditKeys(c *fiber.Ctx, validatorNode ethereum2v1alpha1.Validator, operation string, results []validator.KeyResultDto) restErrors.IRestErr {
+	keys := make([]string, len(results))
+	for i, result := range results {
+		keys[i] = fmt.Sprintf("%s:%s", result.PublicKey, result.Status)
+	}
+
+	return auditService.WithoutTransaction().Create(audit.CreateRecordDto{
+		Actor:       c.Locals("user").(token.UserDetails).ID,
+		WorkspaceId: c.Locals("workspaceUser").(workspaceuser.WorkspaceUser).WorkspaceID,
+		Target:      fmt.Sprintf("%s/%s", validatorNode.Namespace, validatorNode.Name),
+		Operation:   fmt.Sprintf("validator.keys:%s", operation),
+		Details:     strings.Join(keys, ","),
+	})
+}
+
 // ValidateValidatorExist  validate node by name exist acts as a validation for all handlers the needs to find validator by name
 // 1-call validator service to check if node exits
 // 2-return 404 if it's not
@@ -3,6 +3,7 @@ package filecoin
 import (
 	"fmt"
 	"github.com/gofiber/fiber/v2"
+	"github.com/kotalco/core-api/api/handler/shared"
 	"github.com/kotalco/core-api/core/filecoin"
 	restErrors "github.com/kotalco/core-api/pkg/errors"
 	"github.com/kotalco/core-api/pkg/pagination"
@@ -18,7 +19,9 @@ const (
 	nameKeyword = "name"
 )
 
-var service = filecoin.NewFilecoinService()
+var (
+	service = filecoin.NewFilecoinService()
+)
 
 // Get gets a single Filecoin node by name
 // 1-get the node validated from ValidateNodeExist method
@@ -96,6 +99,14 @@ func Delete(c *fiber.Ctx) error {
 	return c.SendStatus(http.StatusNoContent)
 }
 
+// ExpandStorage expands the node volume to the given storage size
+// 1-get node from locals which checked and assigned by ValidateNodeExist
+// 2-call shared ExpandStorage to validate the storage size and expand the volume
+func ExpandStorage(c *fiber.Ctx) error {
+	node := c.Locals("node").(filecoinv1alpha1.Node)
+	return shared.ExpandStorage(c, &node, &node.Spec.Resources)
+}
+
 // Update updates Filecoin node by name from spec
 // 1-todo validate request body and return validation errors if exits
 // 2-get node from locals which checked and assigned by ValidateNodeExist
@@ -0,0 +1,138 @@
+// Package ipfs_cluster handler is the representation layer for the ipfs clusters
+// uses the ipfs cluster service to manage the cluster members and their identities as one unit
+package ipfs_cluster
+
+import (
+	"fmt"
+	"github.com/gofiber/fiber/v2"
+	"github.com/kotalco/core-api/core/ipfs/ipfs_cluster"
+	restErrors "github.com/kotalco/core-api/pkg/errors"
+	"github.com/kotalco/core-api/pkg/pagination"
+	"github.com/kotalco/core-api/pkg/responder"
+	"k8s.io/apimachinery/pkg/types"
+	"net/http"
+	"strconv"
+)
+
+const (
+	nameKeyword = "name"
+)
+
+var service = ipfs_cluster.NewService()
+
+// Create creates the cluster secret and the members ipfs peers and cluster peers with generated identities
+// 1-validate the cluster name, size and consensus
+// 2-call ipfs cluster service to create the members, the created ones are rolled back on failure
+// 3-marshall cluster to dto and format the response
+func Create(c *fiber.Ctx) error {
+	dto := new(ipfs_cluster.ClusterDto)
+	if err := c.BodyParser(dto); err != nil {
+		badReq := restErrors.NewBadRequestError("invalid request body")
+		return c.Status(badReq.StatusCode()).JSON(badReq)
+	}
+
+	dto.Namespace = c.Locals("namespace").(string)
+
+	err := dto.Validate()
+	if err != nil {
+		return c.Status(err.StatusCode()).JSON(err)
+	}
+
+	cluster, err := service.Create(*dto)
+	if err != nil {
+		return c.Status(err.StatusCode()).JSON(err)
+	}
+
+	return c.Status(http.StatusCreated).JSON(responder.NewResponse(ipfs_cluster.ClusterDto{}.FromCluster(cluster)))
+}
+
+// List returns the workspace clusters
+// 1-get the pagination qs default to 0
+// 2-call service to return the clusters, newest first
+// 3-make the pagination
+// 4-marshall clusters to dto and format the response using NewResponse
+func List(c *fiber.Ctx) error {
+	page, _ := strconv.Atoi(c.Query("page"))
+	limit, _ := strconv.Atoi(c.Query("limit"))
+
+	clusters, err := service.List(c.Locals("namespace").(string))
+	if err != nil {
+		return c.Status(err.StatusCode()).JSON(err)
+	}
+
+	c.Set("Access-Control-Expose-Headers", "X-Total-Count")
+	c.Set("X-Total-Count", fmt.Sprintf("%d", len(clusters)))
+
+	start, end := pagination.Page(uint(len(clusters)), uint(page), uint(limit))
+
+	return c.Status(http.StatusOK).JSON(responder.NewResponse(ipfs_cluster.ClusterListDto{}.FromCluster(clusters[start:end])))
+}
+
+// Get returns a single cluster validated by ValidateClusterExist
+func Get(c *fiber.Ctx) error {
+	cluster := c.Locals("cluster").(ipfs_cluster.Cluster)
+
+	return c.Status(http.StatusOK).JSON(responder.NewResponse(ipfs_cluster.ClusterDto{}.FromCluster(cluster)))
+}
+
+// Scale adds or removes cluster members
+// 1-validate the requested size
+// 2-call ipfs cluster service to add members bootstrapping to the existing ones or remove the last members
+// 3-marshall the scaled cluster to dto and format the response
+func Scale(c *fiber.Ctx) error {
+	cluster := c.Locals("cluster").(ipfs_cluster.Cluster)
+
+	dto := new(ipfs_cluster.ScaleDto)
+	if err := c.BodyParser(dto); err != nil {
+		badReq := restErrors.NewBadRequestError("invalid request body")
+		return c.Status(badReq.StatusCode()).JSON(badReq)
+	}
+
+	err := dto.Validate()
+	if err != nil {
+		return c.Status(err.StatusCode()).JSON(err)
+	}
+
+	cluster, err = service.Scale(cluster, dto.Size)
+	if err != nil {
+		return c.Status(err.StatusCode()).JSON(err)
+	}
+
+	return c.Status(http.StatusOK).JSON(responder.NewResponse(ipfs_cluster.ClusterDto{}.FromCluster(cluster)))
+}
+
+// Topology returns every cluster member identity, connectivity and pins count
+func Topology(c *fiber.Ctx) error {
+	cluster := c.Locals("cluster").(ipfs_cluster.Cluster)
+
+	return c.Status(http.StatusOK).JSON(responder.NewResponse(service.Topology(cluster)))
+}
+
+// Delete deletes the cluster members and their secrets together
+func Delete(c *fiber.Ctx) error {
+	cluster := c.Locals("cluster").(ipfs_cluster.Cluster)
+
+	err := service.Delete(cluster)
+	if err != nil {
+		return c.Status(err.StatusCode()).JSON(err)
+	}
+
+	return c.SendStatus(http.StatusNoContent)
+}
+
+// ValidateClusterExist validate cluster by name exist acts as a validation for all handlers the needs to find cluster by name
+// 1-call ipfs cluster service to check if the cluster exits
+// 2-return 404 if it's not
+// 3-save the cluster to local with the key cluster to be used by the other handlers
+func ValidateClusterExist(c *fiber.Ctx) error {
+	cluster, err := service.Get(types.NamespacedName{
+		Namespace: c.Locals("namespace").(string),
+		Name:      c.Params(nameKeyword),
+	})
+	if err != nil {
+		return c.Status(err.StatusCode()).JSON(err)
+	}
+
+	c.Locals("cluster", cluster)
+	return c.Next()
+}
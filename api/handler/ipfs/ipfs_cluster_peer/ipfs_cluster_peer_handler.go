@@ -5,6 +5,7 @@ package ipfs_cluster_peer
 import (
 	"fmt"
 	"github.com/gofiber/fiber/v2"
+	"github.com/kotalco/core-api/api/handler/shared"
 	"github.com/kotalco/core-api/core/ipfs/ipfs_cluster_peer"
 	restErrors "github.com/kotalco/core-api/pkg/errors"
 	"github.com/kotalco/core-api/pkg/pagination"
@@ -20,7 +21,9 @@ const (
 	nameKeyword = "name"
 )
 
-var service = ipfs_cluster_peer.NewIpfsClusterPeerService()
+var (
+	service = ipfs_cluster_peer.NewIpfsClusterPeerService()
+)
 
 // Get gets a single IPFS cluster peer by name
 // 1-get the node validated from ValidateClusterPeerExist method
@@ -97,6 +100,14 @@ func Delete(c *fiber.Ctx) error {
 	return c.SendStatus(http.StatusNoContent)
 }
 
+// ExpandStorage expands the cluster peer volume to the given storage size
+// 1-get cluster peer from locals which checked and assigned by ValidateClusterPeerExist
+// 2-call shared ExpandStorage to validate the storage size and expand the volume
+func ExpandStorage(c *fiber.Ctx) error {
+	peer := c.Locals("peer").(ipfsv1alpha1.ClusterPeer)
+	return shared.ExpandStorage(c, &peer, &peer.Spec.Resources)
+}
+
 // Update updates IPFS cluster peer by name from spec
 // 1-todo validate request body and return validation errors if exits
 // 2-get node from locals which checked and assigned by ValidateClusterPeerExist
@@ -136,6 +147,118 @@ func Count(c *fiber.Ctx) error {
 	return c.SendStatus(http.StatusOK)
 }
 
+// Pins lists the cluster pinset with the pins status on every cluster peer
+// 1-get cluster peer from locals which checked and assigned by ValidateClusterPeerExist
+// 2-call service to list the pins through the cluster peer api inside the cluster
+// 3-format the response
+func Pins(c *fiber.Ctx) error {
+	peer := c.Locals("peer").(ipfsv1alpha1.ClusterPeer)
+
+	pins, err := service.Pins(peer)
+	if err != nil {
+		return c.Status(err.StatusCode()).JSON(err)
+	}
+
+	return c.Status(http.StatusOK).JSON(responder.NewResponse(pins))
+}
+
+// Pin pins a CID on the cluster with the replication factor
+// 1-validate the CID and the replication factor
+// 2-get cluster peer from locals which checked and assigned by ValidateClusterPeerExist
+// 3-call service to pin the CID, the cluster allocates it to the cluster peers
+// 4-format the response
+func Pin(c *fiber.Ctx) error {
+	dto := new(ipfs_cluster_peer.PinRequestDto)
+	if err := c.BodyParser(dto); err != nil {
+		badReq := restErrors.NewBadRequestError("invalid request body")
+		return c.Status(badReq.StatusCode()).JSON(badReq)
+	}
+
+	err := dto.Validate()
+	if err != nil {
+		return c.Status(err.StatusCode()).JSON(err)
+	}
+
+	peer := c.Locals("peer").(ipfsv1alpha1.ClusterPeer)
+
+	pin, err := service.Pin(peer, *dto)
+	if err != nil {
+		return c.Status(err.StatusCode()).JSON(err)
+	}
+
+	return c.Status(http.StatusCreated).JSON(responder.NewResponse(pin))
+}
+
+// PinStatus returns the CID status on every cluster peer
+// 1-get cluster peer from locals which checked and assigned by ValidateClusterPeerExist
+// 2-call service to get the CID status
+// 3-format the response
+func PinStatus(c *fiber.Ctx) error {
+	peer := c.Locals("peer").(ipfsv1alpha1.ClusterPeer)
+
+	pin, err := service.PinStatus(peer, c.Params("cid"))
+	if err != nil {
+		return c.Status(err.StatusCode()).JSON(err)
+	}
+
+	return c.Status(http.StatusOK).JSON(responder.NewResponse(pin))
+}
+
+// Unpin removes the CID from the cluster pinset
+// 1-get cluster peer from locals which checked and assigned by ValidateClusterPeerExist
+// 2-call service to unpin the CID
+// 3-return no content if unpinned with no errors
+func Unpin(c *fiber.Ctx) error {
+	peer := c.Locals("peer").(ipfsv1alpha1.ClusterPeer)
+
+	err := service.Unpin(peer, c.Params("cid"))
+	if err != nil {
+		return c.Status(err.StatusCode()).JSON(err)
+	}
+
+	return c.SendStatus(http.StatusNoContent)
+}
+
+// Add adds the uploaded files to the cluster and pins them
+// 1-validate the name and replicationFactor query params
+// 2-get cluster peer from locals which checked and assigned by ValidateClusterPeerExist
+// 3-cap the request body by the upload body limit, larger uploads are refused
+// 4-call service to stream the multipart body to the cluster peer api
+// 5-return the added files CIDs
+func Add(c *fiber.Ctx) error {
+	dto := ipfs_cluster_peer.AddRequestDto{Name: c.Query("name")}
+	if replicationFactor := c.Query("replicationFactor"); replicationFactor != "" {
+		value, err := strconv.Atoi(replicationFactor)
+		if err != nil {
+			validationErr := restErrors.NewValidationError(map[string]string{"replicationFactor": "replication factor must be a number"})
+			return c.Status(validationErr.StatusCode()).JSON(validationErr)
+		}
+		dto.ReplicationFactor = value
+	}
+
+	err := dto.Validate()
+	if err != nil {
+		return c.Status(err.StatusCode()).JSON(err)
+	}
+
+	peer := c.Locals("peer").(ipfsv1alpha1.ClusterPeer)
+
+	upload, err := shared.NewUpload(c)
+	if err != nil {
+		return c.Status(err.StatusCode()).JSON(err)
+	}
+
+	added, err := service.Add(peer, dto, upload, c.Get(fiber.HeaderContentType))
+	if upload.Exceeded() {
+		err = shared.TooLargeUploadError()
+	}
+	if err != nil {
+		return c.Status(err.StatusCode()).JSON(err)
+	}
+
+	return c.Status(http.StatusCreated).JSON(responder.NewResponse(added))
+}
+
 // ValidateClusterPeerExist validate cluster peer by name exist
 // 1-call service to check if node exits
 // 2-return 404 if it's not
@@ -9,6 +9,7 @@ import (
 	"fmt"
 	"github.com/gofiber/fiber/v2"
 	"github.com/gofiber/websocket/v2"
+	"github.com/kotalco/core-api/api/handler/shared"
 	"github.com/kotalco/core-api/core/ipfs/ipfs_peer"
 	"github.com/kotalco/core-api/k8s"
 	restErrors "github.com/kotalco/core-api/pkg/errors"
@@ -119,6 +120,14 @@ func Delete(c *fiber.Ctx) error {
 	return c.SendStatus(http.StatusNoContent)
 }
 
+// ExpandStorage expands the peer volume to the given storage size
+// 1-get peer from locals which checked and assigned by ValidatePeerExist
+// 2-call shared ExpandStorage to validate the storage size and expand the volume
+func ExpandStorage(c *fiber.Ctx) error {
+	peer := c.Locals("peer").(ipfsv1alpha1.Peer)
+	return shared.ExpandStorage(c, &peer, &peer.Spec.Resources)
+}
+
 // Update updates IPFS peer by name from spec
 // 1-todo validate request body and return validation errors if exits
 // 2-get node from locals which checked and assigned by ValidatePeerExist
@@ -157,6 +166,102 @@ func Count(c *fiber.Ctx) error {
 	return c.SendStatus(http.StatusOK)
 }
 
+// Pins lists the peer recursively pinned CIDs
+// 1-get peer from locals which checked and assigned by ValidatePeerExist
+// 2-call service to list the pins through the peer api inside the cluster
+// 3-format the response
+func Pins(c *fiber.Ctx) error {
+	peer := c.Locals("peer").(ipfsv1alpha1.Peer)
+
+	pins, err := service.Pins(peer)
+	if err != nil {
+		return c.Status(err.StatusCode()).JSON(err)
+	}
+
+	return c.Status(http.StatusOK).JSON(responder.NewResponse(pins))
+}
+
+// Pin pins a CID on the peer
+// 1-validate the CID
+// 2-get peer from locals which checked and assigned by ValidatePeerExist
+// 3-call service to pin the CID, the peer fetches the content from the network if it doesn't have it
+// 4-format the response
+func Pin(c *fiber.Ctx) error {
+	dto := new(ipfs_peer.PinRequestDto)
+	if err := c.BodyParser(dto); err != nil {
+		badReq := restErrors.NewBadRequestError("invalid request body")
+		return c.Status(badReq.StatusCode()).JSON(badReq)
+	}
+
+	err := dto.Validate()
+	if err != nil {
+		return c.Status(err.StatusCode()).JSON(err)
+	}
+
+	peer := c.Locals("peer").(ipfsv1alpha1.Peer)
+
+	pin, err := service.Pin(peer, *dto)
+	if err != nil {
+		return c.Status(err.StatusCode()).JSON(err)
+	}
+
+	return c.Status(http.StatusCreated).JSON(responder.NewResponse(pin))
+}
+
+// PinStatus returns the CID pin
+// 1-get peer from locals which checked and assigned by ValidatePeerExist
+// 2-call service to get the CID pin, 404 if the CID isn't pinned
+// 3-format the response
+func PinStatus(c *fiber.Ctx) error {
+	peer := c.Locals("peer").(ipfsv1alpha1.Peer)
+
+	pin, err := service.PinStatus(peer, c.Params("cid"))
+	if err != nil {
+		return c.Status(err.StatusCode()).JSON(err)
+	}
+
+	return c.Status(http.StatusOK).JSON(responder.NewResponse(pin))
+}
+
+// Unpin removes the CID pin from the peer
+// 1-get peer from locals which checked and assigned by ValidatePeerExist
+// 2-call service to unpin the CID
+// 3-return no content if unpinned with no errors
+func Unpin(c *fiber.Ctx) error {
+	peer := c.Locals("peer").(ipfsv1alpha1.Peer)
+
+	err := service.Unpin(peer, c.Params("cid"))
+	if err != nil {
+		return c.Status(err.StatusCode()).JSON(err)
+	}
+
+	return c.SendStatus(http.StatusNoContent)
+}
+
+// Add adds the uploaded files to the peer and pins them
+// 1-get peer from locals which checked and assigned by ValidatePeerExist
+// 2-cap the request body by the upload body limit, larger uploads are refused
+// 3-call service to stream the multipart body to the peer api
+// 4-return the added files CIDs
+func Add(c *fiber.Ctx) error {
+	peer := c.Locals("peer").(ipfsv1alpha1.Peer)
+
+	upload, err := shared.NewUpload(c)
+	if err != nil {
+		return c.Status(err.StatusCode()).JSON(err)
+	}
+
+	added, err := service.Add(peer, upload, c.Get(fiber.HeaderContentType))
+	if upload.Exceeded() {
+		err = shared.TooLargeUploadError()
+	}
+	if err != nil {
+		return c.Status(err.StatusCode()).JSON(err)
+	}
+
+	return c.Status(http.StatusCreated).JSON(responder.NewResponse(added))
+}
+
 // ValidatePeerExist validate peer by name exist acts as a validation for all handlers the needs to find ipfs peer by name
 // 1-call service to check if node exits
 // 2-return 404 if it's not
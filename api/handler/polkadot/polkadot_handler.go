@@ -4,6 +4,7 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"github.com/kotalco/core-api/api/handler/shared"
 	"github.com/kotalco/core-api/pkg/responder"
 	"net/http"
 	"os"
@@ -98,6 +99,14 @@ func Delete(c *fiber.Ctx) error {
 	return c.SendStatus(http.StatusNoContent)
 }
 
+// ExpandStorage expands the node volume to the given storage size
+// 1-get node from locals which checked and assigned by ValidateNodeExist
+// 2-call shared ExpandStorage to validate the storage size and expand the volume
+func ExpandStorage(c *fiber.Ctx) error {
+	node := c.Locals("node").(polkadotv1alpha1.Node)
+	return shared.ExpandStorage(c, &node, &node.Spec.Resources)
+}
+
 // Update updates Polkadot node by name from spec
 func Update(c *fiber.Ctx) error {
 	dto := new(polkadot.PolkadotDto)
@@ -5,10 +5,14 @@ package secret
 import (
 	"fmt"
 	"github.com/gofiber/fiber/v2"
+	"github.com/kotalco/core-api/core/audit"
 	"github.com/kotalco/core-api/core/secret"
+	"github.com/kotalco/core-api/core/workspaceuser"
 	restErrors "github.com/kotalco/core-api/pkg/errors"
+	"github.com/kotalco/core-api/pkg/logger"
 	"github.com/kotalco/core-api/pkg/pagination"
 	"github.com/kotalco/core-api/pkg/responder"
+	"github.com/kotalco/core-api/pkg/token"
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/types"
 	"net/http"
@@ -17,25 +21,35 @@ import (
 )
 
 const (
-	nameKeyword = "name"
+	nameKeyword     = "name"
+	revealOperation = "secret:reveal"
 )
 
-var service = secret.NewSecretService()
+var (
+	service      = secret.NewSecretService()
+	auditService = audit.NewService()
+)
 
 // Get gets a single  secret by name
 // 1-get the node validated from ValidateSecretExist method
-// 2-marshall secretModel and format the reponse
+// 2-call service to get the nodes referencing the secret
+// 3-marshall secretModel and format the reponse
 func Get(c *fiber.Ctx) error {
 	secretModel := c.Locals("secret").(corev1.Secret)
 
-	return c.Status(http.StatusOK).JSON(new(secret.SecretDto).FromCoreSecret(secretModel))
+	references, err := service.References(secretModel.Namespace)
+	if err != nil {
+		return c.Status(err.StatusCode()).JSON(err)
+	}
+
+	return c.Status(http.StatusOK).JSON(secret.SecretDto{Nodes: references.Of(secretModel.Name)}.FromCoreSecret(secretModel))
 }
 
 // List returns all k8s secrets
 // 1-get the pagination and type qs
 // 2-call service to return secret models
 // 3-paginate the list
-// 3-marshall secrets model to secrets dto and format the response using NewResponse
+// 4-marshall secrets model to secrets dto and format the response using NewResponse
 func List(c *fiber.Ctx) error {
 	secretType := c.Query("type")
 	page, _ := strconv.Atoi(c.Query("page")) // default page to 0
@@ -46,6 +60,11 @@ func List(c *fiber.Ctx) error {
 		return c.Status(err.StatusCode()).JSON(err)
 	}
 
+	references, err := service.References(c.Locals("namespace").(string))
+	if err != nil {
+		return c.Status(err.StatusCode()).JSON(err)
+	}
+
 	start, end := pagination.Page(uint(len(secrets.Items)), uint(page), uint(limit))
 	sort.Slice(secrets.Items[:], func(i, j int) bool {
 		return secrets.Items[j].CreationTimestamp.Before(&secrets.Items[i].CreationTimestamp)
@@ -57,7 +76,7 @@ func List(c *fiber.Ctx) error {
 		if keyType == "" || secretType != "" && keyType != secretType {
 			continue
 		}
-		secretListDto = append(secretListDto, secret.SecretDto{}.FromCoreSecret(sec))
+		secretListDto = append(secretListDto, secret.SecretDto{Nodes: references.Of(sec.Name)}.FromCoreSecret(sec))
 	}
 
 	c.Set("Access-Control-Expose-Headers", "X-Total-Count")
@@ -67,9 +86,10 @@ func List(c *fiber.Ctx) error {
 }
 
 // Create creates k8s secret from spec
-// 1-creates dto from request
-// 2-call service to create and save the secret model
-// 3-marshall the model to the dto and format the response
+// 1-creates dto from request, the secret is kept by the workspace secret provider if no provider is given
+// 2-validate the name and the data against the secret key type
+// 3-call service to create and save the secret model
+// 4-marshall the model to the dto and format the response
 func Create(c *fiber.Ctx) error {
 
 	dto := new(secret.SecretDto)
@@ -79,12 +99,20 @@ func Create(c *fiber.Ctx) error {
 	}
 
 	dto.Namespace = c.Locals("namespace").(string)
+	if dto.Provider == "" {
+		dto.Provider, _ = c.Locals("secretProvider").(string)
+	}
 
 	err := dto.MetaDataDto.Validate()
 	if err != nil {
 		return c.Status(err.StatusCode()).JSON(err)
 	}
 
+	err = dto.ValidateData()
+	if err != nil {
+		return c.Status(err.StatusCode()).JSON(err)
+	}
+
 	secretModel, err := service.Create(*dto)
 	if err != nil {
 		return c.Status(err.StatusCode()).JSON(err)
@@ -93,13 +121,56 @@ func Create(c *fiber.Ctx) error {
 	return c.Status(http.StatusCreated).JSON(responder.NewResponse(new(secret.SecretDto).FromCoreSecret(secretModel)))
 }
 
+// Generate generates the secret private material server side and saves it as a secret
+// 1-creates dto from request, the secret is kept by the workspace secret provider if no provider is given
+// 2-validate the name, the secret type and the key format
+// 3-call service to generate and save the secret
+// 4-format the response with the public material only, the private material is never returned
+func Generate(c *fiber.Ctx) error {
+	dto := new(secret.GenerateSecretDto)
+	if err := c.BodyParser(dto); err != nil {
+		badReq := restErrors.NewBadRequestError("invalid request body")
+		return c.Status(badReq.StatusCode()).JSON(badReq)
+	}
+
+	dto.Namespace = c.Locals("namespace").(string)
+	if dto.Provider == "" {
+		dto.Provider, _ = c.Locals("secretProvider").(string)
+	}
+
+	if err := dto.Validate(); err != nil {
+		return c.Status(err.StatusCode()).JSON(err)
+	}
+
+	generated, err := service.Generate(*dto)
+	if err != nil {
+		return c.Status(err.StatusCode()).JSON(err)
+	}
+
+	return c.Status(http.StatusCreated).JSON(responder.NewResponse(generated))
+}
+
 // Delete deletes k8s secret by name
 // 1-check if secrets with this name exits
-// 2-call service to make the delete action
-// 3-return the respective response
+// 2-refuse deleting the secret if nodes still reference it or their references can't be listed, unless the force qs is true
+// 3-call service to make the delete action
+// 4-return the respective response
 func Delete(c *fiber.Ctx) error {
 	secretModel := c.Locals("secret").(corev1.Secret)
 
+	if c.Query("force") != "true" {
+		// the strict lookup throws if a node kind can't be listed, a referenced secret must not be deleted unless forced
+		dependents, err := service.ReferencesOf(types.NamespacedName{Namespace: secretModel.Namespace, Name: secretModel.Name})
+		if err != nil {
+			return c.Status(err.StatusCode()).JSON(err)
+		}
+
+		if len(dependents) > 0 {
+			conflict := restErrors.NewConflictErrorWithDetails(fmt.Sprintf("secret has %d node references, delete with force=true to delete it anyway", len(dependents)), dependents)
+			return c.Status(conflict.StatusCode()).JSON(conflict)
+		}
+	}
+
 	err := service.Delete(&secretModel)
 	if err != nil {
 		return c.Status(err.StatusCode()).JSON(err)
@@ -108,9 +179,57 @@ func Delete(c *fiber.Ctx) error {
 	return c.SendStatus(http.StatusNoContent)
 }
 
-// Update updates k8s secret by name from spec
+// References returns the nodes referencing the secret
+func References(c *fiber.Ctx) error {
+	secretModel := c.Locals("secret").(corev1.Secret)
+
+	references, err := service.References(secretModel.Namespace)
+	if err != nil {
+		return c.Status(err.StatusCode()).JSON(err)
+	}
+
+	return c.Status(http.StatusOK).JSON(responder.NewResponse(references.Of(secretModel.Name)))
+}
+
+// Update rotates the secret, secrets are immutable so the new data is saved to the next secret version
+// 1-get the secret validated from ValidateSecretExist method
+// 2-call service to create the new version and re-point the nodes referencing the secret to it
+// 3-marshall the new version with the re-pointed nodes and format the response
 func Update(c *fiber.Ctx) error {
-	return c.SendStatus(http.StatusMethodNotAllowed)
+	secretModel := c.Locals("secret").(corev1.Secret)
+
+	dto := new(secret.RotateSecretDto)
+	if err := c.BodyParser(dto); err != nil {
+		badReq := restErrors.NewBadRequestError("invalid request body")
+		return c.Status(badReq.StatusCode()).JSON(badReq)
+	}
+
+	rotated, references, err := service.Rotate(secretModel, dto.Data)
+	if err != nil {
+		return c.Status(err.StatusCode()).JSON(err)
+	}
+
+	return c.Status(http.StatusOK).JSON(responder.NewResponse(secret.SecretDto{Nodes: references}.FromCoreSecret(rotated)))
+}
+
+// Reveal returns the secret data to the workspace admins
+// 1-get the secret validated from ValidateSecretExist method
+// 2-call audit service to record the reveal, the data isn't returned if the reveal can't be recorded
+// 3-marshall the secret with its data and format the response
+func Reveal(c *fiber.Ctx) error {
+	secretModel := c.Locals("secret").(corev1.Secret)
+
+	err := auditService.WithoutTransaction().Create(audit.CreateRecordDto{
+		Actor:       c.Locals("user").(token.UserDetails).ID,
+		WorkspaceId: c.Locals("workspaceUser").(workspaceuser.WorkspaceUser).WorkspaceID,
+		Target:      fmt.Sprintf("%s/%s", secretModel.Namespace, secretModel.Name),
+		Operation:   revealOperation,
+	})
+	if err != nil {
+		return c.Status(err.StatusCode()).JSON(err)
+	}
+
+	return c.Status(http.StatusOK).JSON(responder.NewResponse(secret.SecretDto{}.FromCoreSecret(secretModel).RevealData(secretModel)))
 }
 
 // Count returns total number of secrets
@@ -128,6 +247,21 @@ func Count(c *fiber.Ctx) error {
 	return c.SendStatus(http.StatusOK)
 }
 
+// Materialize creates the secrets referenced by the node being created from the workspace secret provider
+// it runs before the node Create handler, the secrets are materialized once the node is created
+func Materialize(c *fiber.Ctx) error {
+	if err := c.Next(); err != nil || c.Response().StatusCode() != http.StatusCreated {
+		return err
+	}
+
+	provider, _ := c.Locals("secretProvider").(string)
+	if err := service.Materialize(c.Locals("namespace").(string), provider); err != nil {
+		go logger.Warn("SECRET_MATERIALIZE", err)
+	}
+
+	return nil
+}
+
 // ValidateSecretExist validate secret by name exist acts as a validation for all handlers the needs to find secret by name
 // 1-call secrets service to check if secret exits
 // 2-return 404 if it's not
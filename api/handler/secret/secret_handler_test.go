@@ -1 +1,319 @@
 package secret
+
+import (
+	"bytes"
+	"encoding/json"
+	"github.com/gofiber/fiber/v2"
+	"github.com/kotalco/core-api/core/audit"
+	"github.com/kotalco/core-api/core/secret"
+	"github.com/kotalco/core-api/core/workspaceuser"
+	restErrors "github.com/kotalco/core-api/pkg/errors"
+	"github.com/kotalco/core-api/pkg/roles"
+	"github.com/kotalco/core-api/pkg/token"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+	"io/ioutil"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+)
+
+/*
+secret service mocks
+*/
+var (
+	secretDeleteFunc       func(secret *corev1.Secret) restErrors.IRestErr
+	secretRotateFunc       func(secret corev1.Secret, data map[string]string) (corev1.Secret, []secret.ReferenceDto, restErrors.IRestErr)
+	secretReferencesFunc   func(namespace string) (secret.ReferenceIndex, restErrors.IRestErr)
+	secretReferencesOfFunc func(key types.NamespacedName) ([]secret.ReferenceDto, restErrors.IRestErr)
+	secretMaterializeFunc  func(namespace string, provider string) restErrors.IRestErr
+	secretGenerateFunc     func(dto secret.GenerateSecretDto) (secret.GeneratedSecretDto, restErrors.IRestErr)
+)
+
+type secretServiceMock struct{}
+
+func (secretServiceMock) Get(name types.NamespacedName) (corev1.Secret, restErrors.IRestErr) {
+	return corev1.Secret{}, nil
+}
+func (secretServiceMock) Create(dto secret.SecretDto) (corev1.Secret, restErrors.IRestErr) {
+	return corev1.Secret{}, nil
+}
+func (secretServiceMock) Generate(dto secret.GenerateSecretDto) (secret.GeneratedSecretDto, restErrors.IRestErr) {
+	return secretGenerateFunc(dto)
+}
+func (secretServiceMock) List(namespace string) (corev1.SecretList, restErrors.IRestErr) {
+	return corev1.SecretList{}, nil
+}
+func (secretServiceMock) Delete(secret *corev1.Secret) restErrors.IRestErr {
+	return secretDeleteFunc(secret)
+}
+func (secretServiceMock) Count(namespace string) (int, restErrors.IRestErr) {
+	return 0, nil
+}
+func (secretServiceMock) Rotate(secret corev1.Secret, data map[string]string) (corev1.Secret, []secret.ReferenceDto, restErrors.IRestErr) {
+	return secretRotateFunc(secret, data)
+}
+func (secretServiceMock) References(namespace string) (secret.ReferenceIndex, restErrors.IRestErr) {
+	return secretReferencesFunc(namespace)
+}
+
+func (secretServiceMock) ReferencesOf(key types.NamespacedName) ([]secret.ReferenceDto, restErrors.IRestErr) {
+	return secretReferencesOfFunc(key)
+}
+
+func (secretServiceMock) Materialize(namespace string, provider string) restErrors.IRestErr {
+	return secretMaterializeFunc(namespace, provider)
+}
+func (secretServiceMock) Sync() restErrors.IRestErr {
+	return nil
+}
+
+/*
+audit service mocks
+*/
+var auditCreateFunc func(dto audit.CreateRecordDto) restErrors.IRestErr
+
+type auditServiceMock struct{}
+
+func (s auditServiceMock) WithTransaction(txHandle *gorm.DB) audit.IService {
+	return s
+}
+func (s auditServiceMock) WithoutTransaction() audit.IService {
+	return s
+}
+func (auditServiceMock) Create(dto audit.CreateRecordDto) restErrors.IRestErr {
+	return auditCreateFunc(dto)
+}
+
+func TestMain(m *testing.M) {
+	service = &secretServiceMock{}
+	auditService = &auditServiceMock{}
+
+	code := m.Run()
+	os.Exit(code)
+}
+
+func newFiberCtx(path string, dto interface{}, method func(c *fiber.Ctx) error, locals map[string]interface{}) ([]byte, *http.Response) {
+	app := fiber.New()
+	app.Post("/test/", func(c *fiber.Ctx) error {
+		for key, element := range locals {
+			c.Locals(key, element)
+		}
+		return method(c)
+	})
+
+	marshaledDto, err := json.Marshal(dto)
+	if err != nil {
+		panic(err.Error())
+	}
+
+	req := httptest.NewRequest("POST", "/test"+path, bytes.NewBuffer(marshaledDto))
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := app.Test(req)
+	if err != nil {
+		panic(err.Error())
+	}
+
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		panic(err.Error())
+	}
+
+	return body, resp
+}
+
+var (
+	secretModel = corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "my-jwt", Namespace: "default", Labels: map[string]string{secret.KeyTypeLabel: secret.JWTSecretType}}}
+	locals      = map[string]interface{}{"namespace": "default", "secret": secretModel}
+	dependents  = secret.ReferenceIndex{"my-jwt": {{Resource: roles.EthereumNodes, Name: "my-node", Field: "jwtSecretName"}}}
+)
+
+func TestDelete(t *testing.T) {
+	secretDeleteFunc = func(secret *corev1.Secret) restErrors.IRestErr {
+		return nil
+	}
+
+	t.Run("delete should pass if no node references the secret", func(t *testing.T) {
+		secretReferencesOfFunc = func(key types.NamespacedName) ([]secret.ReferenceDto, restErrors.IRestErr) {
+			return []secret.ReferenceDto{}, nil
+		}
+		_, resp := newFiberCtx("", nil, Delete, locals)
+		assert.EqualValues(t, http.StatusNoContent, resp.StatusCode)
+	})
+
+	t.Run("delete should throw conflict listing the nodes referencing the secret", func(t *testing.T) {
+		secretReferencesOfFunc = func(key types.NamespacedName) ([]secret.ReferenceDto, restErrors.IRestErr) {
+			return dependents[key.Name], nil
+		}
+		body, resp := newFiberCtx("", nil, Delete, locals)
+		var result struct {
+			Message string                `json:"message"`
+			Details []secret.ReferenceDto `json:"details"`
+		}
+		err := json.Unmarshal(body, &result)
+		assert.Nil(t, err)
+		assert.EqualValues(t, http.StatusConflict, resp.StatusCode)
+		assert.EqualValues(t, dependents["my-jwt"], result.Details)
+	})
+
+	t.Run("delete should throw if the node references can't be listed", func(t *testing.T) {
+		deleted := false
+		secretDeleteFunc = func(secret *corev1.Secret) restErrors.IRestErr {
+			deleted = true
+			return nil
+		}
+		secretReferencesOfFunc = func(key types.NamespacedName) ([]secret.ReferenceDto, restErrors.IRestErr) {
+			return nil, restErrors.NewInternalServerError("can't list ethereum2.validators referencing secrets")
+		}
+		_, resp := newFiberCtx("", nil, Delete, locals)
+		assert.EqualValues(t, http.StatusInternalServerError, resp.StatusCode)
+		assert.False(t, deleted)
+		secretDeleteFunc = func(secret *corev1.Secret) restErrors.IRestErr {
+			return nil
+		}
+	})
+
+	t.Run("delete should pass if forced", func(t *testing.T) {
+		secretReferencesOfFunc = func(key types.NamespacedName) ([]secret.ReferenceDto, restErrors.IRestErr) {
+			return dependents[key.Name], nil
+		}
+		_, resp := newFiberCtx("?force=true", nil, Delete, locals)
+		assert.EqualValues(t, http.StatusNoContent, resp.StatusCode)
+	})
+}
+
+func TestReferences(t *testing.T) {
+	t.Run("references should return the nodes referencing the secret", func(t *testing.T) {
+		secretReferencesFunc = func(namespace string) (secret.ReferenceIndex, restErrors.IRestErr) {
+			return dependents, nil
+		}
+		body, resp := newFiberCtx("", nil, References, locals)
+		var result map[string][]secret.ReferenceDto
+		err := json.Unmarshal(body, &result)
+		assert.Nil(t, err)
+		assert.EqualValues(t, http.StatusOK, resp.StatusCode)
+		assert.EqualValues(t, dependents["my-jwt"], result["data"])
+	})
+}
+
+func TestUpdate(t *testing.T) {
+	t.Run("update should return the new secret version with the re-pointed nodes", func(t *testing.T) {
+		secretRotateFunc = func(model corev1.Secret, data map[string]string) (corev1.Secret, []secret.ReferenceDto, restErrors.IRestErr) {
+			rotated := model
+			rotated.Name = "my-jwt-v2"
+			rotated.Labels = map[string]string{secret.VersionLabel: "2"}
+			return rotated, dependents["my-jwt"], nil
+		}
+		body, resp := newFiberCtx("", secret.RotateSecretDto{Data: map[string]string{"secret": "new"}}, Update, locals)
+		var result map[string]secret.SecretDto
+		err := json.Unmarshal(body, &result)
+		assert.Nil(t, err)
+		assert.EqualValues(t, http.StatusOK, resp.StatusCode)
+		assert.EqualValues(t, "my-jwt-v2", result["data"].Name)
+		assert.EqualValues(t, 2, result["data"].Version)
+		assert.EqualValues(t, dependents["my-jwt"], result["data"].Nodes)
+	})
+}
+
+func TestReveal(t *testing.T) {
+	revealLocals := map[string]interface{}{
+		"secret":        secretModel,
+		"user":          token.UserDetails{ID: "1"},
+		"workspaceUser": workspaceuser.WorkspaceUser{UserId: "1", WorkspaceID: "2", Role: roles.Admin},
+	}
+
+	t.Run("reveal should record the reveal before returning the data", func(t *testing.T) {
+		var recorded audit.CreateRecordDto
+		auditCreateFunc = func(dto audit.CreateRecordDto) restErrors.IRestErr {
+			recorded = dto
+			return nil
+		}
+		_, resp := newFiberCtx("", nil, Reveal, revealLocals)
+		assert.EqualValues(t, http.StatusOK, resp.StatusCode)
+		assert.EqualValues(t, audit.CreateRecordDto{Actor: "1", WorkspaceId: "2", Target: "default/my-jwt", Operation: revealOperation}, recorded)
+	})
+
+	t.Run("reveal should throw if the reveal can't be recorded", func(t *testing.T) {
+		auditCreateFunc = func(dto audit.CreateRecordDto) restErrors.IRestErr {
+			return restErrors.NewInternalServerError("something went wrong")
+		}
+		_, resp := newFiberCtx("", nil, Reveal, revealLocals)
+		assert.EqualValues(t, http.StatusInternalServerError, resp.StatusCode)
+	})
+}
+
+func TestMaterialize(t *testing.T) {
+	t.Run("materialize should materialize the workspace provider secrets once the node is created", func(t *testing.T) {
+		materialized := ""
+		secretMaterializeFunc = func(namespace string, provider string) restErrors.IRestErr {
+			materialized = provider
+			return nil
+		}
+		app := fiber.New()
+		app.Post("/test/", func(c *fiber.Ctx) error {
+			c.Locals("namespace", "default")
+			c.Locals("secretProvider", secret.VaultProvider)
+			return c.Next()
+		}, Materialize, func(c *fiber.Ctx) error {
+			return c.SendStatus(http.StatusCreated)
+		})
+
+		resp, err := app.Test(httptest.NewRequest("POST", "/test", nil))
+		assert.Nil(t, err)
+		assert.EqualValues(t, http.StatusCreated, resp.StatusCode)
+		assert.EqualValues(t, secret.VaultProvider, materialized)
+	})
+
+	t.Run("materialize should skip nodes that aren't created", func(t *testing.T) {
+		called := false
+		secretMaterializeFunc = func(namespace string, provider string) restErrors.IRestErr {
+			called = true
+			return nil
+		}
+		app := fiber.New()
+		app.Post("/test/", Materialize, func(c *fiber.Ctx) error {
+			return c.SendStatus(http.StatusBadRequest)
+		})
+
+		resp, err := app.Test(httptest.NewRequest("POST", "/test", nil))
+		assert.Nil(t, err)
+		assert.EqualValues(t, http.StatusBadRequest, resp.StatusCode)
+		assert.False(t, called)
+	})
+}
+
+func TestGenerate(t *testing.T) {
+	generateLocals := map[string]interface{}{"namespace": "default", "secretProvider": secret.KubernetesProvider}
+
+	t.Run("generate should return the public material only", func(t *testing.T) {
+		secretGenerateFunc = func(dto secret.GenerateSecretDto) (secret.GeneratedSecretDto, restErrors.IRestErr) {
+			assert.EqualValues(t, "default", dto.Namespace)
+			assert.EqualValues(t, secret.KubernetesProvider, dto.Provider)
+			generated := secret.GeneratedSecretDto{Public: map[string]string{"address": "0xabc"}}
+			generated.Name = dto.Name
+			generated.Type = secret.PrivateKeyType
+			return generated, nil
+		}
+		body, resp := newFiberCtx("", map[string]string{"name": "node-key", "type": secret.Secp256k1Generator}, Generate, generateLocals)
+		var result map[string]map[string]interface{}
+		err := json.Unmarshal(body, &result)
+		assert.Nil(t, err)
+		assert.EqualValues(t, http.StatusCreated, resp.StatusCode)
+		assert.EqualValues(t, "node-key", result["data"]["name"])
+		assert.EqualValues(t, map[string]interface{}{"address": "0xabc"}, result["data"]["public"])
+		assert.NotContains(t, result["data"], "data")
+	})
+
+	t.Run("generate should throw validation error if type is unknown", func(t *testing.T) {
+		body, resp := newFiberCtx("", map[string]string{"name": "node-key", "type": "rsa"}, Generate, generateLocals)
+		var result restErrors.RestErr
+		err := json.Unmarshal(body, &result)
+		assert.Nil(t, err)
+		assert.EqualValues(t, http.StatusBadRequest, resp.StatusCode)
+		assert.Contains(t, result.Validations, "type")
+	})
+}
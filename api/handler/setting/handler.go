@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"github.com/gofiber/fiber/v2"
 	"github.com/kotalco/core-api/config"
+	"github.com/kotalco/core-api/core/ethereum2/beacon_node"
 	"github.com/kotalco/core-api/core/setting"
 	"github.com/kotalco/core-api/core/user"
 	"github.com/kotalco/core-api/k8s"
@@ -13,9 +14,9 @@ import (
 	"github.com/kotalco/core-api/k8s/tlscertificate"
 	restErrors "github.com/kotalco/core-api/pkg/errors"
 	"github.com/kotalco/core-api/pkg/logger"
+	"github.com/kotalco/core-api/pkg/mailer"
 	"github.com/kotalco/core-api/pkg/responder"
 	"github.com/kotalco/core-api/pkg/security"
-	"github.com/kotalco/core-api/pkg/sendgrid"
 	"github.com/kotalco/core-api/pkg/sqlclient"
 	"github.com/kotalco/core-api/pkg/token"
 	corev1 "k8s.io/api/core/v1"
@@ -31,7 +32,7 @@ var (
 	secretService         = secret.NewService()
 	tlsCertificateService = tlscertificate.NewService()
 	userService           = user.NewService()
-	sendGridService       = sendgrid.NewService()
+	mailService           = mailer.NewService()
 	k8sClient             = k8s.NewClientService()
 )
 
@@ -128,7 +129,7 @@ func ConfigureRegistration(c *fiber.Ctx) error {
 		return c.Status(restErr.StatusCode()).JSON(restErr)
 	}
 
-	err := sendGridService.Ping()
+	err := mailService.Ping()
 	if err != nil {
 		return c.Status(err.StatusCode()).JSON(err)
 	}
@@ -140,6 +141,187 @@ func ConfigureRegistration(c *fiber.Ctx) error {
 	return c.Status(http.StatusOK).JSON(responder.NewResponse(responder.SuccessMessage{Message: "registration configured successfully!"}))
 }
 
+// ConfigurePasswordPolicy sets the platform-wide password policy applied on sign up, reset and change password
+func ConfigurePasswordPolicy(c *fiber.Ctx) error {
+	dto := new(setting.PasswordPolicyDto)
+	if err := c.BodyParser(dto); err != nil {
+		badReq := restErrors.NewBadRequestError("invalid request body")
+		return c.Status(badReq.StatusCode()).JSON(badReq)
+	}
+	restErr := setting.Validate(dto)
+	if restErr != nil {
+		return c.Status(restErr.StatusCode()).JSON(restErr)
+	}
+
+	err := settingService.WithoutTransaction().ConfigurePasswordPolicy(dto)
+	if err != nil {
+		return c.Status(err.StatusCode()).JSON(err)
+	}
+	return c.Status(http.StatusOK).JSON(responder.NewResponse(responder.SuccessMessage{Message: "password policy configured successfully!"}))
+}
+
+// GetPasswordPolicy returns the platform password policy, public so clients can validate passwords before sign up
+func GetPasswordPolicy(c *fiber.Ctx) error {
+	policy, err := settingService.WithoutTransaction().GetPasswordPolicy()
+	if err != nil {
+		return c.Status(err.StatusCode()).JSON(err)
+	}
+	return c.Status(http.StatusOK).JSON(responder.NewResponse(policy))
+}
+
+// GetMailSettings returns the mail transport and branding settings without the smtp password
+func GetMailSettings(c *fiber.Ctx) error {
+	settings, err := settingService.WithoutTransaction().GetMailSettings()
+	if err != nil {
+		return c.Status(err.StatusCode()).JSON(err)
+	}
+	if settings.SMTP != nil {
+		settings.SMTP.Password = ""
+	}
+	return c.Status(http.StatusOK).JSON(responder.NewResponse(settings))
+}
+
+// ConfigureMail sets the mail transport and branding after verifying the transport can deliver emails
+// the current smtp password is kept if the password wasn't sent
+func ConfigureMail(c *fiber.Ctx) error {
+	dto := new(setting.MailSettingsDto)
+	if err := c.BodyParser(dto); err != nil {
+		badReq := restErrors.NewBadRequestError("invalid request body")
+		return c.Status(badReq.StatusCode()).JSON(badReq)
+	}
+	restErr := setting.Validate(dto)
+	if restErr != nil {
+		return c.Status(restErr.StatusCode()).JSON(restErr)
+	}
+
+	current, err := settingService.WithoutTransaction().GetMailSettings()
+	if err != nil {
+		return c.Status(err.StatusCode()).JSON(err)
+	}
+	if dto.SMTP != nil && dto.SMTP.Password == "" && current.SMTP != nil {
+		dto.SMTP.Password = current.SMTP.Password
+	}
+
+	err = mailService.Verify(dto)
+	if err != nil {
+		return c.Status(err.StatusCode()).JSON(err)
+	}
+
+	err = settingService.WithoutTransaction().ConfigureMail(dto)
+	if err != nil {
+		return c.Status(err.StatusCode()).JSON(err)
+	}
+	return c.Status(http.StatusOK).JSON(responder.NewResponse(responder.SuccessMessage{Message: "mail configured successfully!"}))
+}
+
+// MailTemplates lists the mail templates, overridden templates are returned instead of the built-in ones
+func MailTemplates(c *fiber.Ctx) error {
+	list := make([]setting.MailTemplateResponseDto, 0)
+	for _, name := range mailer.TemplateNames() {
+		overridden := true
+		template, err := settingService.WithoutTransaction().GetMailTemplate(name)
+		if err != nil {
+			if err.StatusCode() != http.StatusNotFound {
+				return c.Status(err.StatusCode()).JSON(err)
+			}
+			overridden = false
+			template, err = mailer.DefaultTemplate(name)
+			if err != nil {
+				return c.Status(err.StatusCode()).JSON(err)
+			}
+		}
+		list = append(list, setting.MailTemplateResponseDto{Name: name, Subject: template.Subject, HTML: template.HTML, Overridden: overridden})
+	}
+	return c.Status(http.StatusOK).JSON(responder.NewResponse(list))
+}
+
+// ConfigureMailTemplate overrides the subject and html body of a built-in mail template
+func ConfigureMailTemplate(c *fiber.Ctx) error {
+	name := c.Params("name")
+	_, err := mailer.DefaultTemplate(name)
+	if err != nil {
+		return c.Status(err.StatusCode()).JSON(err)
+	}
+
+	dto := new(setting.MailTemplateDto)
+	if err := c.BodyParser(dto); err != nil {
+		badReq := restErrors.NewBadRequestError("invalid request body")
+		return c.Status(badReq.StatusCode()).JSON(badReq)
+	}
+	err = setting.Validate(dto)
+	if err != nil {
+		return c.Status(err.StatusCode()).JSON(err)
+	}
+	err = mailer.ValidateTemplate(dto)
+	if err != nil {
+		return c.Status(err.StatusCode()).JSON(err)
+	}
+
+	err = settingService.WithoutTransaction().ConfigureMailTemplate(name, dto)
+	if err != nil {
+		return c.Status(err.StatusCode()).JSON(err)
+	}
+	return c.Status(http.StatusOK).JSON(responder.NewResponse(setting.MailTemplateResponseDto{Name: name, Subject: dto.Subject, HTML: dto.HTML, Overridden: true}))
+}
+
+// ResetMailTemplate removes the mail template override so the built-in template is used again
+func ResetMailTemplate(c *fiber.Ctx) error {
+	name := c.Params("name")
+	_, err := mailer.DefaultTemplate(name)
+	if err != nil {
+		return c.Status(err.StatusCode()).JSON(err)
+	}
+
+	err = settingService.WithoutTransaction().DeleteMailTemplate(name)
+	if err != nil {
+		return c.Status(err.StatusCode()).JSON(err)
+	}
+	return c.SendStatus(http.StatusNoContent)
+}
+
+// GetCheckpointSyncProviders lists the checkpoint sync providers, filtered by the network query param if sent
+func GetCheckpointSyncProviders(c *fiber.Ctx) error {
+	providers, err := settingService.WithoutTransaction().GetCheckpointSyncProviders()
+	if err != nil {
+		return c.Status(err.StatusCode()).JSON(err)
+	}
+	if network := c.Query("network"); network != "" {
+		filtered := providers.Network(network)
+		providers = &filtered
+	}
+	return c.Status(http.StatusOK).JSON(responder.NewResponse(providers))
+}
+
+// ConfigureCheckpointSyncProviders replaces the checkpoint sync providers after verifying every provider serves its network
+func ConfigureCheckpointSyncProviders(c *fiber.Ctx) error {
+	dto := new(setting.CheckpointSyncProvidersDto)
+	if err := c.BodyParser(dto); err != nil {
+		badReq := restErrors.NewBadRequestError("invalid request body")
+		return c.Status(badReq.StatusCode()).JSON(badReq)
+	}
+	restErr := setting.Validate(dto)
+	if restErr != nil {
+		return c.Status(restErr.StatusCode()).JSON(restErr)
+	}
+
+	fields := map[string]string{}
+	for i, provider := range dto.Providers {
+		if err := beacon_node.VerifyCheckpointSyncURL(provider.URL, provider.Network); err != nil {
+			fields[fmt.Sprintf("providers[%d].url", i)] = err.Error()
+		}
+	}
+	if len(fields) > 0 {
+		validationErr := restErrors.NewValidationError(fields)
+		return c.Status(validationErr.StatusCode()).JSON(validationErr)
+	}
+
+	restErr = settingService.WithoutTransaction().ConfigureCheckpointSyncProviders(dto)
+	if restErr != nil {
+		return c.Status(restErr.StatusCode()).JSON(restErr)
+	}
+	return c.Status(http.StatusOK).JSON(responder.NewResponse(responder.SuccessMessage{Message: "checkpoint sync providers configured successfully!"}))
+}
+
 func ConfigureTLS(c *fiber.Ctx) error {
 	switch c.FormValue("tls_provider") {
 	case "letsencrypt":
@@ -10,6 +10,7 @@ import (
 	"github.com/kotalco/core-api/k8s/ingressroute"
 	"github.com/kotalco/core-api/k8s/middleware"
 	restErrors "github.com/kotalco/core-api/pkg/errors"
+	"github.com/kotalco/core-api/pkg/mailer"
 	"github.com/kotalco/core-api/pkg/responder"
 	"github.com/kotalco/core-api/pkg/token"
 	"github.com/stretchr/testify/assert"
@@ -29,14 +30,23 @@ import (
 setting service  mocks
 */
 var (
-	settingSettingsFunc               func() ([]*setting.Setting, restErrors.IRestErr)
-	settingConfigureDomainFunc        func(dto *setting.ConfigureDomainRequestDto) restErrors.IRestErr
-	settingIsDomainConfiguredFunc     func() bool
-	settingConfigureRegistrationFunc  func(dto *setting.ConfigureRegistrationRequestDto) restErrors.IRestErr
-	settingGetDomainFunc              func() (string, restErrors.IRestErr)
-	settingIsRegistrationEnabledFunc  func() bool
-	settingConfigureActivationKeyFunc func(key string) restErrors.IRestErr
-	settingGetActivationKey           func() (string, restErrors.IRestErr)
+	settingSettingsFunc                         func() ([]*setting.Setting, restErrors.IRestErr)
+	settingConfigureDomainFunc                  func(dto *setting.ConfigureDomainRequestDto) restErrors.IRestErr
+	settingIsDomainConfiguredFunc               func() bool
+	settingConfigureRegistrationFunc            func(dto *setting.ConfigureRegistrationRequestDto) restErrors.IRestErr
+	settingGetDomainFunc                        func() (string, restErrors.IRestErr)
+	settingIsRegistrationEnabledFunc            func() bool
+	settingConfigurePasswordPolicyFunc          func(dto *setting.PasswordPolicyDto) restErrors.IRestErr
+	settingGetPasswordPolicyFunc                func() (*setting.PasswordPolicyDto, restErrors.IRestErr)
+	settingConfigureActivationKeyFunc           func(key string) restErrors.IRestErr
+	settingGetActivationKey                     func() (string, restErrors.IRestErr)
+	settingConfigureMailFunc                    func(dto *setting.MailSettingsDto) restErrors.IRestErr
+	settingGetMailSettingsFunc                  func() (*setting.MailSettingsDto, restErrors.IRestErr)
+	settingConfigureMailTemplateFunc            func(name string, dto *setting.MailTemplateDto) restErrors.IRestErr
+	settingGetMailTemplateFunc                  func(name string) (*setting.MailTemplateDto, restErrors.IRestErr)
+	settingDeleteMailTemplateFunc               func(name string) restErrors.IRestErr
+	settingConfigureCheckpointSyncProvidersFunc func(dto *setting.CheckpointSyncProvidersDto) restErrors.IRestErr
+	settingGetCheckpointSyncProvidersFunc       func() (*setting.CheckpointSyncProvidersDto, restErrors.IRestErr)
 )
 
 type settingServiceMocks struct{}
@@ -60,6 +70,42 @@ func (s settingServiceMocks) IsRegistrationEnabled() bool {
 	return settingIsRegistrationEnabledFunc()
 }
 
+func (s settingServiceMocks) ConfigurePasswordPolicy(dto *setting.PasswordPolicyDto) restErrors.IRestErr {
+	return settingConfigurePasswordPolicyFunc(dto)
+}
+
+func (s settingServiceMocks) GetPasswordPolicy() (*setting.PasswordPolicyDto, restErrors.IRestErr) {
+	return settingGetPasswordPolicyFunc()
+}
+
+func (s settingServiceMocks) ConfigureMail(dto *setting.MailSettingsDto) restErrors.IRestErr {
+	return settingConfigureMailFunc(dto)
+}
+
+func (s settingServiceMocks) GetMailSettings() (*setting.MailSettingsDto, restErrors.IRestErr) {
+	return settingGetMailSettingsFunc()
+}
+
+func (s settingServiceMocks) ConfigureMailTemplate(name string, dto *setting.MailTemplateDto) restErrors.IRestErr {
+	return settingConfigureMailTemplateFunc(name, dto)
+}
+
+func (s settingServiceMocks) GetMailTemplate(name string) (*setting.MailTemplateDto, restErrors.IRestErr) {
+	return settingGetMailTemplateFunc(name)
+}
+
+func (s settingServiceMocks) DeleteMailTemplate(name string) restErrors.IRestErr {
+	return settingDeleteMailTemplateFunc(name)
+}
+
+func (s settingServiceMocks) ConfigureCheckpointSyncProviders(dto *setting.CheckpointSyncProvidersDto) restErrors.IRestErr {
+	return settingConfigureCheckpointSyncProvidersFunc(dto)
+}
+
+func (s settingServiceMocks) GetCheckpointSyncProviders() (*setting.CheckpointSyncProvidersDto, restErrors.IRestErr) {
+	return settingGetCheckpointSyncProvidersFunc()
+}
+
 func (s settingServiceMocks) WithoutTransaction() setting.IService {
 	return s
 }
@@ -157,22 +203,31 @@ func (tls tlsCertificateServiceMock) ConfigureCustomCertificate(secretName strin
 }
 
 var (
-	UserWithTransactionFunc     func(txHandle *gorm.DB) user.IService
-	SignUpFunc                  func(dto *user.SignUpRequestDto) (*user.User, restErrors.IRestErr)
-	SignInFunc                  func(dto *user.SignInRequestDto) (*user.UserSessionResponseDto, restErrors.IRestErr)
-	VerifyTOTPFunc              func(model *user.User, totp string) (*user.UserSessionResponseDto, restErrors.IRestErr)
-	GetByEmailFunc              func(email string) (*user.User, restErrors.IRestErr)
-	GetByIdFunc                 func(Id string) (*user.User, restErrors.IRestErr)
-	VerifyEmailFunc             func(model *user.User) restErrors.IRestErr
-	ResetPasswordFunc           func(model *user.User, password string) restErrors.IRestErr
-	ChangePasswordFunc          func(model *user.User, dto *user.ChangePasswordRequestDto) restErrors.IRestErr
-	ChangeEmailFunc             func(model *user.User, dto *user.ChangeEmailRequestDto) restErrors.IRestErr
-	CreateTOTPFunc              func(model *user.User, dto *user.CreateTOTPRequestDto) (bytes.Buffer, restErrors.IRestErr)
-	EnableTwoFactorAuthFunc     func(model *user.User, totp string) (*user.User, restErrors.IRestErr)
-	DisableTwoFactorAuthFunc    func(model *user.User, dto *user.DisableTOTPRequestDto) restErrors.IRestErr
-	FindWhereIdInSliceFunc      func(ids []string) ([]*user.User, restErrors.IRestErr)
-	usersCountFunc              func() (int64, restErrors.IRestErr)
-	usersSetAsPlatformAdminFunc func(model *user.User) restErrors.IRestErr
+	UserWithTransactionFunc      func(txHandle *gorm.DB) user.IService
+	SignUpFunc                   func(dto *user.SignUpRequestDto) (*user.User, restErrors.IRestErr)
+	SignInFunc                   func(dto *user.SignInRequestDto) (*user.UserSessionResponseDto, restErrors.IRestErr)
+	VerifyTOTPFunc               func(model *user.User, totp string) (*user.UserSessionResponseDto, restErrors.IRestErr)
+	GetByEmailFunc               func(email string) (*user.User, restErrors.IRestErr)
+	GetByIdFunc                  func(Id string) (*user.User, restErrors.IRestErr)
+	VerifyEmailFunc              func(model *user.User) restErrors.IRestErr
+	ResetPasswordFunc            func(model *user.User, password string) restErrors.IRestErr
+	InvalidatePasswordFunc       func(model *user.User) restErrors.IRestErr
+	ChangePasswordFunc           func(model *user.User, dto *user.ChangePasswordRequestDto) restErrors.IRestErr
+	ChangeEmailFunc              func(model *user.User, dto *user.ChangeEmailRequestDto) restErrors.IRestErr
+	CreateTOTPFunc               func(model *user.User, dto *user.CreateTOTPRequestDto) (bytes.Buffer, restErrors.IRestErr)
+	EnableTwoFactorAuthFunc      func(model *user.User, totp string) (*user.User, restErrors.IRestErr)
+	DisableTwoFactorAuthFunc     func(model *user.User, dto *user.DisableTOTPRequestDto) restErrors.IRestErr
+	FindWhereIdInSliceFunc       func(ids []string) ([]*user.User, restErrors.IRestErr)
+	usersCountFunc               func() (int64, restErrors.IRestErr)
+	usersSetAsPlatformAdminFunc  func(model *user.User) restErrors.IRestErr
+	usersUnsetPlatformAdminFunc  func(model *user.User) restErrors.IRestErr
+	usersSearchFunc              func(email string) ([]*user.User, restErrors.IRestErr)
+	usersDisableFunc             func(model *user.User) restErrors.IRestErr
+	usersEnableFunc              func(model *user.User) restErrors.IRestErr
+	usersResetTwoFactorAuthFunc  func(model *user.User) restErrors.IRestErr
+	usersCheckPasswordPolicyFunc func(model *user.User, password string) restErrors.IRestErr
+	usersReauthenticateFunc      func(model *user.User, dto *user.DeleteAccountRequestDto) restErrors.IRestErr
+	usersDeleteFunc              func(model *user.User) restErrors.IRestErr
 )
 
 type userServiceMock struct{}
@@ -209,6 +264,10 @@ func (userServiceMock) ResetPassword(model *user.User, password string) restErro
 	return ResetPasswordFunc(model, password)
 }
 
+func (userServiceMock) InvalidatePassword(model *user.User) restErrors.IRestErr {
+	return InvalidatePasswordFunc(model)
+}
+
 func (userServiceMock) ChangePassword(model *user.User, dto *user.ChangePasswordRequestDto) restErrors.IRestErr {
 	return ChangePasswordFunc(model, dto)
 }
@@ -241,6 +300,30 @@ func (userServiceMock) Count() (int64, restErrors.IRestErr) {
 func (userServiceMock) SetAsPlatformAdmin(model *user.User) restErrors.IRestErr {
 	return usersSetAsPlatformAdminFunc(model)
 }
+func (userServiceMock) UnsetPlatformAdmin(model *user.User) restErrors.IRestErr {
+	return usersUnsetPlatformAdminFunc(model)
+}
+func (userServiceMock) Search(email string) ([]*user.User, restErrors.IRestErr) {
+	return usersSearchFunc(email)
+}
+func (userServiceMock) Disable(model *user.User) restErrors.IRestErr {
+	return usersDisableFunc(model)
+}
+func (userServiceMock) Enable(model *user.User) restErrors.IRestErr {
+	return usersEnableFunc(model)
+}
+func (userServiceMock) ResetTwoFactorAuth(model *user.User) restErrors.IRestErr {
+	return usersResetTwoFactorAuthFunc(model)
+}
+func (userServiceMock) CheckPasswordPolicy(model *user.User, password string) restErrors.IRestErr {
+	return usersCheckPasswordPolicyFunc(model, password)
+}
+func (userServiceMock) Reauthenticate(model *user.User, dto *user.DeleteAccountRequestDto) restErrors.IRestErr {
+	return usersReauthenticateFunc(model, dto)
+}
+func (userServiceMock) Delete(model *user.User) restErrors.IRestErr {
+	return usersDeleteFunc(model)
+}
 
 func newFiberCtx(dto interface{}, method func(c *fiber.Ctx) error, locals map[string]interface{}) ([]byte, *http.Response) {
 	app := fiber.New()
@@ -271,12 +354,54 @@ func newFiberCtx(dto interface{}, method func(c *fiber.Ctx) error, locals map[st
 	return body, resp
 }
 
+/*
+mail service mocks
+*/
+var (
+	mailPingFunc   func() restErrors.IRestErr
+	mailVerifyFunc func(settings *setting.MailSettingsDto) restErrors.IRestErr
+)
+
+type mailServiceMock struct{}
+
+func (mailServiceMock) SignUp(dto *mailer.MailRequestDto) restErrors.IRestErr {
+	return nil
+}
+func (mailServiceMock) ResendEmailVerification(dto *mailer.MailRequestDto) restErrors.IRestErr {
+	return nil
+}
+func (mailServiceMock) ForgetPassword(dto *mailer.MailRequestDto) restErrors.IRestErr {
+	return nil
+}
+func (mailServiceMock) WorkspaceInvitation(dto *mailer.WorkspaceInvitationMailRequestDto) restErrors.IRestErr {
+	return nil
+}
+func (mailServiceMock) AccountLocked(dto *mailer.AccountLockedMailRequestDto) restErrors.IRestErr {
+	return nil
+}
+func (mailServiceMock) WorkspaceTransferred(dto *mailer.WorkspaceOwnershipMailRequestDto) restErrors.IRestErr {
+	return nil
+}
+func (mailServiceMock) Test(dto *mailer.MailRequestDto) restErrors.IRestErr {
+	return nil
+}
+func (mailServiceMock) Verify(settings *setting.MailSettingsDto) restErrors.IRestErr {
+	return mailVerifyFunc(settings)
+}
+func (mailServiceMock) Ping() restErrors.IRestErr {
+	return mailPingFunc()
+}
+
 func TestMain(m *testing.M) {
 	settingService = &settingServiceMocks{}
 	k8service = &k8sServiceMock{}
 	ingressRouteService = &ingressRouteServiceMock{}
 	userService = &userServiceMock{}
 	tlsCertificateService = &tlsCertificateServiceMock{}
+	mailService = &mailServiceMock{}
+	mailPingFunc = func() restErrors.IRestErr {
+		return nil
+	}
 
 	code := m.Run()
 	os.Exit(code)
@@ -635,6 +760,70 @@ func TestConfigureRegistration(t *testing.T) {
 		assert.EqualValues(t, http.StatusInternalServerError, resp.StatusCode)
 	})
 }
+func TestConfigurePasswordPolicy(t *testing.T) {
+	var validDto = map[string]interface{}{
+		"min_length":     10,
+		"require_digit":  true,
+		"history_size":   5,
+		"check_breached": true,
+	}
+	var invalidDto = map[string]interface{}{
+		"min_length":   2,
+		"history_size": 50,
+	}
+
+	t.Run("configure password policy should pass", func(t *testing.T) {
+		settingConfigurePasswordPolicyFunc = func(dto *setting.PasswordPolicyDto) restErrors.IRestErr {
+			assert.EqualValues(t, 10, dto.MinLength)
+			return nil
+		}
+		body, resp := newFiberCtx(validDto, ConfigurePasswordPolicy, map[string]interface{}{})
+
+		var result map[string]responder.SuccessMessage
+		err := json.Unmarshal(body, &result)
+		if err != nil {
+			panic(err.Error())
+		}
+		assert.EqualValues(t, http.StatusOK, resp.StatusCode)
+		assert.EqualValues(t, "password policy configured successfully!", result["data"].Message)
+	})
+
+	t.Run("configure password policy should throw validation err", func(t *testing.T) {
+		body, resp := newFiberCtx(invalidDto, ConfigurePasswordPolicy, map[string]interface{}{})
+		var result restErrors.RestErr
+		err := json.Unmarshal(body, &result)
+		assert.Nil(t, err)
+		assert.EqualValues(t, http.StatusBadRequest, resp.StatusCode)
+		assert.EqualValues(t, "min_length should be between 6 and 100", result.Validations["min_length"])
+		assert.EqualValues(t, "history_size can't exceed 10", result.Validations["history_size"])
+	})
+
+	t.Run("configure password policy should throw if service throws", func(t *testing.T) {
+		settingConfigurePasswordPolicyFunc = func(dto *setting.PasswordPolicyDto) restErrors.IRestErr {
+			return restErrors.NewInternalServerError("something went wrong")
+		}
+		_, resp := newFiberCtx(validDto, ConfigurePasswordPolicy, map[string]interface{}{})
+		assert.EqualValues(t, http.StatusInternalServerError, resp.StatusCode)
+	})
+}
+
+func TestGetPasswordPolicy(t *testing.T) {
+	t.Run("get password policy should pass", func(t *testing.T) {
+		settingGetPasswordPolicyFunc = func() (*setting.PasswordPolicyDto, restErrors.IRestErr) {
+			return setting.DefaultPasswordPolicy(), nil
+		}
+		body, resp := newFiberCtx("", GetPasswordPolicy, map[string]interface{}{})
+
+		var result map[string]setting.PasswordPolicyDto
+		err := json.Unmarshal(body, &result)
+		if err != nil {
+			panic(err.Error())
+		}
+		assert.EqualValues(t, http.StatusOK, resp.StatusCode)
+		assert.EqualValues(t, 6, result["data"].MinLength)
+	})
+}
+
 func TestSettings(t *testing.T) {
 	userDetails := new(token.UserDetails)
 	userDetails.ID = "test@test.com"
@@ -756,3 +945,208 @@ func TestVerifyDomainHots(t *testing.T) {
 		assert.NotNil(t, err, "Expected an error")
 	})
 }
+
+func TestGetMailSettings(t *testing.T) {
+	t.Run("get_mail_settings_should_pass_without_smtp_password", func(t *testing.T) {
+		settingGetMailSettingsFunc = func() (*setting.MailSettingsDto, restErrors.IRestErr) {
+			settings := setting.DefaultMailSettings()
+			settings.Transport = setting.SMTPMailTransport
+			settings.SMTP = &setting.SMTPSettingsDto{Host: "smtp.internal", Port: 587, Username: "kotal", Password: "secret"}
+			return settings, nil
+		}
+
+		body, resp := newFiberCtx("", GetMailSettings, map[string]interface{}{})
+		var result map[string]setting.MailSettingsDto
+		err := json.Unmarshal(body, &result)
+		assert.Nil(t, err)
+
+		assert.EqualValues(t, http.StatusOK, resp.StatusCode)
+		assert.EqualValues(t, "smtp.internal", result["data"].SMTP.Host)
+		assert.EqualValues(t, "", result["data"].SMTP.Password)
+	})
+
+	t.Run("get_mail_settings_should_throw_if_service_throws", func(t *testing.T) {
+		settingGetMailSettingsFunc = func() (*setting.MailSettingsDto, restErrors.IRestErr) {
+			return nil, restErrors.NewInternalServerError("something went wrong")
+		}
+
+		body, resp := newFiberCtx("", GetMailSettings, map[string]interface{}{})
+		var result restErrors.RestErr
+		err := json.Unmarshal(body, &result)
+		assert.Nil(t, err)
+
+		assert.EqualValues(t, http.StatusInternalServerError, resp.StatusCode)
+		assert.EqualValues(t, "something went wrong", result.Message)
+	})
+}
+
+func TestConfigureMail(t *testing.T) {
+	var validDto = map[string]interface{}{
+		"transport":    "smtp",
+		"sender_name":  "Kotal Notifications",
+		"sender_email": "notifications@kotal.co",
+		"product_name": "Kotal",
+		"smtp": map[string]interface{}{
+			"host":     "smtp.internal",
+			"port":     587,
+			"username": "kotal",
+			"starttls": true,
+		},
+	}
+	settingGetMailSettingsFunc = func() (*setting.MailSettingsDto, restErrors.IRestErr) {
+		settings := setting.DefaultMailSettings()
+		settings.SMTP = &setting.SMTPSettingsDto{Password: "secret"}
+		return settings, nil
+	}
+
+	t.Run("configure_mail_should_pass_and_keep_the_smtp_password", func(t *testing.T) {
+		mailVerifyFunc = func(settings *setting.MailSettingsDto) restErrors.IRestErr {
+			return nil
+		}
+		password := ""
+		settingConfigureMailFunc = func(dto *setting.MailSettingsDto) restErrors.IRestErr {
+			password = dto.SMTP.Password
+			return nil
+		}
+
+		body, resp := newFiberCtx(validDto, ConfigureMail, map[string]interface{}{})
+		var result map[string]responder.SuccessMessage
+		err := json.Unmarshal(body, &result)
+		assert.Nil(t, err)
+
+		assert.EqualValues(t, http.StatusOK, resp.StatusCode)
+		assert.EqualValues(t, "mail configured successfully!", result["data"].Message)
+		assert.EqualValues(t, "secret", password)
+	})
+
+	t.Run("configure_mail_should_throw_validation_error", func(t *testing.T) {
+		invalidDto := map[string]interface{}{"transport": "pigeon", "sender_name": "Kotal", "sender_email": "invalid", "product_name": "Kotal"}
+
+		body, resp := newFiberCtx(invalidDto, ConfigureMail, map[string]interface{}{})
+		var result restErrors.RestErr
+		err := json.Unmarshal(body, &result)
+		assert.Nil(t, err)
+
+		fields := map[string]string{
+			"transport":    "transport can only be sendgrid or smtp",
+			"sender_email": "invalid sender email address",
+		}
+		assert.EqualValues(t, http.StatusBadRequest, resp.StatusCode)
+		assert.EqualValues(t, fields, result.Validations)
+	})
+
+	t.Run("configure_mail_should_throw_if_smtp_settings_are_missing", func(t *testing.T) {
+		invalidDto := map[string]interface{}{"transport": "smtp", "sender_name": "Kotal", "sender_email": "notifications@kotal.co", "product_name": "Kotal"}
+
+		body, resp := newFiberCtx(invalidDto, ConfigureMail, map[string]interface{}{})
+		var result restErrors.RestErr
+		err := json.Unmarshal(body, &result)
+		assert.Nil(t, err)
+
+		assert.EqualValues(t, http.StatusBadRequest, resp.StatusCode)
+		assert.EqualValues(t, map[string]string{"smtp": "smtp settings are required for smtp transport"}, result.Validations)
+	})
+
+	t.Run("configure_mail_should_throw_if_transport_can't_be_verified", func(t *testing.T) {
+		mailVerifyFunc = func(settings *setting.MailSettingsDto) restErrors.IRestErr {
+			return restErrors.NewForbiddenError("can't connect to smtp server: connection refused")
+		}
+
+		body, resp := newFiberCtx(validDto, ConfigureMail, map[string]interface{}{})
+		var result restErrors.RestErr
+		err := json.Unmarshal(body, &result)
+		assert.Nil(t, err)
+
+		assert.EqualValues(t, http.StatusForbidden, resp.StatusCode)
+		assert.EqualValues(t, "can't connect to smtp server: connection refused", result.Message)
+	})
+}
+
+func TestGetCheckpointSyncProviders(t *testing.T) {
+	t.Run("get_checkpoint_sync_providers_should_pass", func(t *testing.T) {
+		settingGetCheckpointSyncProvidersFunc = func() (*setting.CheckpointSyncProvidersDto, restErrors.IRestErr) {
+			return setting.DefaultCheckpointSyncProviders(), nil
+		}
+
+		body, resp := newFiberCtx("", GetCheckpointSyncProviders, map[string]interface{}{})
+		var result map[string]setting.CheckpointSyncProvidersDto
+		err := json.Unmarshal(body, &result)
+		assert.Nil(t, err)
+
+		assert.EqualValues(t, http.StatusOK, resp.StatusCode)
+		assert.EqualValues(t, setting.DefaultCheckpointSyncProviders().Providers, result["data"].Providers)
+	})
+
+	t.Run("get_checkpoint_sync_providers_should_throw_if_service_throws", func(t *testing.T) {
+		settingGetCheckpointSyncProvidersFunc = func() (*setting.CheckpointSyncProvidersDto, restErrors.IRestErr) {
+			return nil, restErrors.NewInternalServerError("something went wrong")
+		}
+
+		body, resp := newFiberCtx("", GetCheckpointSyncProviders, map[string]interface{}{})
+		var result restErrors.RestErr
+		err := json.Unmarshal(body, &result)
+		assert.Nil(t, err)
+
+		assert.EqualValues(t, http.StatusInternalServerError, resp.StatusCode)
+		assert.EqualValues(t, "something went wrong", result.Message)
+	})
+}
+
+func TestConfigureCheckpointSyncProviders(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/eth/v1/beacon/headers/finalized":
+			w.Write([]byte(`{"data":{"root":"0xabc"}}`))
+		case "/eth/v1/beacon/genesis":
+			w.Write([]byte(`{"data":{"genesis_validators_root":"0xd8ea171f3c94aea21ebc42a1ed61052acf3f9209c00e4efbaaddac09ed9b8078"}}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	t.Run("configure_checkpoint_sync_providers_should_pass", func(t *testing.T) {
+		var configured *setting.CheckpointSyncProvidersDto
+		settingConfigureCheckpointSyncProvidersFunc = func(dto *setting.CheckpointSyncProvidersDto) restErrors.IRestErr {
+			configured = dto
+			return nil
+		}
+		dto := map[string]interface{}{"providers": []map[string]string{{"name": "provider", "network": "sepolia", "url": server.URL}}}
+
+		body, resp := newFiberCtx(dto, ConfigureCheckpointSyncProviders, map[string]interface{}{})
+		var result map[string]responder.SuccessMessage
+		err := json.Unmarshal(body, &result)
+		assert.Nil(t, err)
+
+		assert.EqualValues(t, http.StatusOK, resp.StatusCode)
+		assert.EqualValues(t, "checkpoint sync providers configured successfully!", result["data"].Message)
+		assert.EqualValues(t, server.URL, configured.Providers[0].URL)
+	})
+
+	t.Run("configure_checkpoint_sync_providers_should_throw_validation_error", func(t *testing.T) {
+		dto := map[string]interface{}{"providers": []map[string]string{{"name": "provider", "network": "sepolia", "url": "invalid"}}}
+
+		body, resp := newFiberCtx(dto, ConfigureCheckpointSyncProviders, map[string]interface{}{})
+		var result restErrors.RestErr
+		err := json.Unmarshal(body, &result)
+		assert.Nil(t, err)
+
+		assert.EqualValues(t, http.StatusBadRequest, resp.StatusCode)
+		assert.EqualValues(t, map[string]string{"providers[0].url": "invalid provider url"}, result.Validations)
+	})
+
+	t.Run("configure_checkpoint_sync_providers_should_throw_if_provider_serves_another_network", func(t *testing.T) {
+		dto := map[string]interface{}{"providers": []map[string]string{
+			{"name": "provider", "network": "sepolia", "url": server.URL},
+			{"name": "provider", "network": "mainnet", "url": server.URL},
+		}}
+
+		body, resp := newFiberCtx(dto, ConfigureCheckpointSyncProviders, map[string]interface{}{})
+		var result restErrors.RestErr
+		err := json.Unmarshal(body, &result)
+		assert.Nil(t, err)
+
+		assert.EqualValues(t, http.StatusBadRequest, resp.StatusCode)
+		assert.EqualValues(t, map[string]string{"providers[1].url": "checkpoint sync url doesn't serve mainnet network"}, result.Validations)
+	})
+}
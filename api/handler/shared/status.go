@@ -8,11 +8,13 @@ import (
 	"time"
 
 	"github.com/gofiber/websocket/v2"
+	"github.com/kotalco/core-api/core/volume"
 	"github.com/kotalco/core-api/k8s"
 	"github.com/kotalco/core-api/pkg/logger"
 	corev1 "k8s.io/api/core/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
 )
 
 var (
@@ -20,8 +22,11 @@ var (
 	k8sClientset, _ = k8s.NewClientset()
 )
 
+// storageAlmostFull is emitted instead of Running if the node volume is above the storage warning threshold
+const storageAlmostFull = "StorageAlmostFull"
+
 // Status returns a websocket that emits logs from pod
-// Possible values are: NotFound, Pending, PodInitializing, ContainerCreating, Running, Error, Terminating
+// Possible values are: NotFound, Pending, PodInitializing, ContainerCreating, Running, StorageAlmostFull, Error, Terminating
 func Status(c *websocket.Conn) {
 	defer c.Close()
 
@@ -32,6 +37,7 @@ func Status(c *websocket.Conn) {
 			"PodInitializing",
 			"ContainerCreating",
 			"Running",
+			storageAlmostFull,
 			"Error",
 			"Terminating",
 		}
@@ -55,35 +61,60 @@ func Status(c *websocket.Conn) {
 		return
 	}
 
-	for event := range watch.ResultChan() {
+	key := types.NamespacedName{Namespace: ns, Name: name}
+	// the volume usage is checked periodically, pod events don't fire as the volume fills up
+	ticker := time.NewTicker(volume.MonitorInterval())
+	defer ticker.Stop()
+
+	var phase, lastStatus string
+	for {
+		select {
+		case event, ok := <-watch.ResultChan():
+			if !ok {
+				return
+			}
 
-		pod, ok := event.Object.(*corev1.Pod)
-		if !ok {
-			return
-		}
+			pod, ok := event.Object.(*corev1.Pod)
+			if !ok {
+				return
+			}
 
-		phase := string(pod.Status.Phase)
+			phase = string(pod.Status.Phase)
 
-		if pod.DeletionTimestamp != nil {
-			phase = "Terminating"
+			if pod.DeletionTimestamp != nil {
+				phase = "Terminating"
 
-			// if pod is being terminated, check owner sts is found or not
-			go func() {
-				time.Sleep(3 * time.Second)
-				_, err := k8sClientset.AppsV1().StatefulSets(ns).Get(context.Background(), name, metav1.GetOptions{})
-				if err != nil && apierrors.IsNotFound(err) {
-					watch.Stop()
+				// if pod is being terminated, check owner sts is found or not
+				go func() {
+					time.Sleep(3 * time.Second)
+					_, err := k8sClientset.AppsV1().StatefulSets(ns).Get(context.Background(), name, metav1.GetOptions{})
+					if err != nil && apierrors.IsNotFound(err) {
+						watch.Stop()
+					}
+				}()
+			}
+
+			if len(pod.Status.ContainerStatuses) != 0 {
+				if pod.Status.ContainerStatuses[0].State.Waiting != nil {
+					phase = pod.Status.ContainerStatuses[0].State.Waiting.Reason
 				}
-			}()
+			}
+		case <-ticker.C:
+			if phase != string(corev1.PodRunning) {
+				continue
+			}
 		}
 
-		if len(pod.Status.ContainerStatuses) != 0 {
-			if pod.Status.ContainerStatuses[0].State.Waiting != nil {
-				phase = pod.Status.ContainerStatuses[0].State.Waiting.Reason
-			}
+		status := phase
+		if phase == string(corev1.PodRunning) && volume.CapacityWarning(key) {
+			status = storageAlmostFull
+		}
+		if status == lastStatus && phase == string(corev1.PodRunning) {
+			continue
 		}
+		lastStatus = status
 
-		if err := c.WriteMessage(websocket.TextMessage, []byte(phase)); err != nil {
+		if err := c.WriteMessage(websocket.TextMessage, []byte(status)); err != nil {
 			return
 		}
 	}
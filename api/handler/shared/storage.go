@@ -0,0 +1,38 @@
+package shared
+
+import (
+	"net/http"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/kotalco/core-api/core/volume"
+	restErrors "github.com/kotalco/core-api/pkg/errors"
+	"github.com/kotalco/core-api/pkg/responder"
+	sharedAPI "github.com/kotalco/kotal/apis/shared"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+var volumeService = volume.NewService()
+
+// ExpandStorage expands the node volume to the given storage size, used by the nodes handlers
+// 1-validate the new storage size
+// 2-call volume service to check the storage class allows expansion and update the node storage
+// 3-return the volume status holding the resize progress
+func ExpandStorage(c *fiber.Ctx, node client.Object, resources *sharedAPI.Resources) error {
+	dto := new(volume.ExpandVolumeDto)
+	if err := c.BodyParser(dto); err != nil {
+		badReq := restErrors.NewBadRequestError("invalid request body")
+		return c.Status(badReq.StatusCode()).JSON(badReq)
+	}
+
+	err := dto.Validate()
+	if err != nil {
+		return c.Status(err.StatusCode()).JSON(err)
+	}
+
+	status, err := volumeService.Expand(node, resources, *dto)
+	if err != nil {
+		return c.Status(err.StatusCode()).JSON(err)
+	}
+
+	return c.Status(http.StatusOK).JSON(responder.NewResponse(status))
+}
@@ -0,0 +1,65 @@
+package shared
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/kotalco/core-api/config"
+	restErrors "github.com/kotalco/core-api/pkg/errors"
+)
+
+var errUploadTooLarge = errors.New("upload exceeds the upload body limit")
+
+// Upload is the uploaded request body, bodies larger than the upload server body limit are streamed instead of being read into memory
+type Upload struct {
+	reader   io.Reader
+	limit    int64
+	read     int64
+	exceeded bool
+}
+
+// NewUpload returns the request body capped by the upload body limit
+// uploads sending a larger content length are refused before being read, chunked uploads fail once they exceed the limit
+func NewUpload(c *fiber.Ctx) (*Upload, restErrors.IRestErr) {
+	limit := int64(config.Environment.UploadBodyLimit)
+	if int64(c.Request().Header.ContentLength()) > limit {
+		return nil, TooLargeUploadError()
+	}
+
+	var reader io.Reader = bytes.NewReader(c.Body())
+	if stream := c.Context().RequestBodyStream(); stream != nil {
+		reader = stream
+	}
+	return &Upload{reader: reader, limit: limit}, nil
+}
+
+func (upload *Upload) Read(p []byte) (int, error) {
+	if upload.read >= upload.limit {
+		// read one more byte to tell bodies of exactly the limit size from larger ones
+		n, err := upload.reader.Read(make([]byte, 1))
+		if n > 0 {
+			upload.exceeded = true
+			return 0, errUploadTooLarge
+		}
+		return 0, err
+	}
+	if remaining := upload.limit - upload.read; int64(len(p)) > remaining {
+		p = p[:remaining]
+	}
+	n, err := upload.reader.Read(p)
+	upload.read += int64(n)
+	return n, err
+}
+
+// Exceeded reports whether the upload was stopped for exceeding the upload body limit
+func (upload *Upload) Exceeded() bool {
+	return upload.exceeded
+}
+
+// TooLargeUploadError is returned for uploads exceeding the upload body limit
+func TooLargeUploadError() restErrors.IRestErr {
+	return restErrors.NewRequestEntityTooLargeError(fmt.Sprintf("upload can't exceed %d bytes", config.Environment.UploadBodyLimit))
+}
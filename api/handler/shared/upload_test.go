@@ -0,0 +1,29 @@
+package shared
+
+import (
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUpload_Read(t *testing.T) {
+	t.Run("read_should_read_uploads_up_to_the_limit", func(t *testing.T) {
+		upload := &Upload{reader: strings.NewReader("hello"), limit: 5}
+
+		body, err := io.ReadAll(upload)
+		assert.Nil(t, err)
+		assert.EqualValues(t, "hello", string(body))
+		assert.False(t, upload.Exceeded())
+	})
+
+	t.Run("read_should_throw_if_upload_exceeds_the_limit", func(t *testing.T) {
+		upload := &Upload{reader: strings.NewReader("hello world"), limit: 5}
+
+		body, err := io.ReadAll(upload)
+		assert.EqualValues(t, errUploadTooLarge, err)
+		assert.EqualValues(t, "hello", string(body))
+		assert.True(t, upload.Exceeded())
+	})
+}
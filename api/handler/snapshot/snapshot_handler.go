@@ -0,0 +1,233 @@
+// Package snapshot handler is the representation layer for the nodes volume snapshots
+// uses the snapshot service to snapshot the nodes volumes and to restore new nodes from them
+package snapshot
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/gofiber/fiber/v2"
+	"github.com/kotalco/core-api/core/snapshot"
+	"github.com/kotalco/core-api/k8s"
+	"github.com/kotalco/core-api/k8s/volumesnapshot"
+	restErrors "github.com/kotalco/core-api/pkg/errors"
+	"github.com/kotalco/core-api/pkg/pagination"
+	"github.com/kotalco/core-api/pkg/responder"
+	"github.com/kotalco/core-api/pkg/roles"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"net/http"
+	"sort"
+	"strconv"
+)
+
+const (
+	nameKeyword = "name"
+	// fromSnapshotKeyword is the query of the node create request naming the snapshot to restore the node volume from
+	fromSnapshotKeyword = "fromSnapshot"
+)
+
+var service = snapshot.NewService()
+
+// Create snapshots the node volume
+// 1-validate the snapshot name if given
+// 2-call snapshot service to create a volume snapshot of the node persistent volume claim
+// 3-marshall snapshot to dto and format the response
+func Create(c *fiber.Ctx) error {
+	dto := new(snapshot.CreateSnapshotDto)
+	if len(c.Body()) != 0 {
+		if err := c.BodyParser(dto); err != nil {
+			badReq := restErrors.NewBadRequestError("invalid request body")
+			return c.Status(badReq.StatusCode()).JSON(badReq)
+		}
+	}
+
+	err := dto.Validate()
+	if err != nil {
+		return c.Status(err.StatusCode()).JSON(err)
+	}
+
+	volumeSnapshot, err := service.Create(nodeKey(c), *dto, snapshot.ManualTrigger)
+	if err != nil {
+		return c.Status(err.StatusCode()).JSON(err)
+	}
+
+	return c.Status(http.StatusCreated).JSON(responder.NewResponse(snapshot.SnapshotDto{}.FromVolumeSnapshot(volumeSnapshot)))
+}
+
+// ListByNode returns the node volume snapshots, newest first
+func ListByNode(c *fiber.Ctx) error {
+	snapshots, err := service.ListByNode(nodeKey(c))
+	if err != nil {
+		return c.Status(err.StatusCode()).JSON(err)
+	}
+
+	sortByNewest(snapshots.Items)
+
+	return c.Status(http.StatusOK).JSON(responder.NewResponse(snapshot.SnapshotListDto{}.FromVolumeSnapshot(snapshots.Items)))
+}
+
+// List returns all the workspace nodes snapshots
+// 1-get the pagination qs default to 0
+// 2-call service to return the snapshots
+// 3-make the pagination
+// 4-marshall snapshots to snapshot dto and format the response using NewResponse
+func List(c *fiber.Ctx) error {
+	page, _ := strconv.Atoi(c.Query("page"))
+	limit, _ := strconv.Atoi(c.Query("limit"))
+
+	snapshots, err := service.List(c.Locals("namespace").(string))
+	if err != nil {
+		return c.Status(err.StatusCode()).JSON(err)
+	}
+
+	c.Set("Access-Control-Expose-Headers", "X-Total-Count")
+	c.Set("X-Total-Count", fmt.Sprintf("%d", len(snapshots.Items)))
+
+	start, end := pagination.Page(uint(len(snapshots.Items)), uint(page), uint(limit))
+	sortByNewest(snapshots.Items)
+
+	return c.Status(http.StatusOK).JSON(responder.NewResponse(snapshot.SnapshotListDto{}.FromVolumeSnapshot(snapshots.Items[start:end])))
+}
+
+// Get returns a single snapshot validated by ValidateSnapshotExist
+func Get(c *fiber.Ctx) error {
+	volumeSnapshot := c.Locals("snapshot").(volumesnapshot.VolumeSnapshot)
+
+	return c.Status(http.StatusOK).JSON(responder.NewResponse(snapshot.SnapshotDto{}.FromVolumeSnapshot(volumeSnapshot)))
+}
+
+// Delete deletes a single snapshot validated by ValidateSnapshotExist
+func Delete(c *fiber.Ctx) error {
+	volumeSnapshot := c.Locals("snapshot").(volumesnapshot.VolumeSnapshot)
+
+	err := service.Delete(&volumeSnapshot)
+	if err != nil {
+		return c.Status(err.StatusCode()).JSON(err)
+	}
+
+	return c.SendStatus(http.StatusNoContent)
+}
+
+// GetSchedule returns the node snapshot schedule
+func GetSchedule(c *fiber.Ctx) error {
+	schedule, err := service.GetSchedule(nodeKey(c))
+	if err != nil {
+		return c.Status(err.StatusCode()).JSON(err)
+	}
+
+	return c.Status(http.StatusOK).JSON(responder.NewResponse(schedule))
+}
+
+// UpdateSchedule sets the node snapshot schedule, an empty schedule disables the scheduled snapshots
+// 1-validate the schedule
+// 2-call snapshot service to save the schedule to the node volume
+func UpdateSchedule(c *fiber.Ctx) error {
+	dto := new(snapshot.ScheduleDto)
+	if err := c.BodyParser(dto); err != nil {
+		badReq := restErrors.NewBadRequestError("invalid request body")
+		return c.Status(badReq.StatusCode()).JSON(badReq)
+	}
+
+	err := dto.Validate()
+	if err != nil {
+		return c.Status(err.StatusCode()).JSON(err)
+	}
+
+	err = service.UpdateSchedule(nodeKey(c), *dto)
+	if err != nil {
+		return c.Status(err.StatusCode()).JSON(err)
+	}
+
+	return c.Status(http.StatusOK).JSON(responder.NewResponse(dto))
+}
+
+// Restore provisions the volume of the node being created from the snapshot named by the fromSnapshot query
+// it runs before the protocol Create handler, the node requests the restored volume size and storage class
+// and the restored volume is discarded if the node isn't created
+func Restore(c *fiber.Ctx) error {
+	name := c.Query(fromSnapshotKeyword)
+	if name == "" {
+		return c.Next()
+	}
+
+	permissions, _ := c.Locals("permissions").([]string)
+	if !roles.Allowed(permissions, roles.Permission(roles.Snapshots, roles.Read)) {
+		forbidden := restErrors.NewForbiddenError("unAuthorized action")
+		return c.Status(forbidden.StatusCode()).JSON(forbidden)
+	}
+
+	body := map[string]interface{}{}
+	if err := json.Unmarshal(c.Body(), &body); err != nil {
+		badReq := restErrors.NewBadRequestError("invalid request body")
+		return c.Status(badReq.StatusCode()).JSON(badReq)
+	}
+
+	dto := snapshot.RestoreDto{}
+	dto.Name, _ = body["name"].(string)
+	dto.Storage, _ = body["storage"].(string)
+	if storageClass, ok := body["storageClass"].(string); ok && storageClass != "" {
+		dto.StorageClass = &storageClass
+	}
+
+	meta := k8s.MetaDataDto{Name: dto.Name}
+	err := meta.Validate()
+	if err != nil {
+		return c.Status(err.StatusCode()).JSON(err)
+	}
+
+	volumeSnapshot, err := service.Get(types.NamespacedName{Namespace: c.Locals("namespace").(string), Name: name})
+	if err != nil {
+		return c.Status(err.StatusCode()).JSON(err)
+	}
+
+	claim, err := service.Restore(volumeSnapshot, dto)
+	if err != nil {
+		return c.Status(err.StatusCode()).JSON(err)
+	}
+
+	storage := claim.Spec.Resources.Requests[corev1.ResourceStorage]
+	body["storage"] = storage.String()
+	if claim.Spec.StorageClassName != nil {
+		body["storageClass"] = *claim.Spec.StorageClassName
+	}
+	raw, _ := json.Marshal(body)
+	c.Request().SetBody(raw)
+
+	if nextErr := c.Next(); nextErr != nil || c.Response().StatusCode() != http.StatusCreated {
+		service.Discard(&claim)
+		return nextErr
+	}
+
+	return nil
+}
+
+// ValidateSnapshotExist validate snapshot by name exist acts as a validation for all handlers the needs to find snapshot by name
+// 1-call snapshot service to check if snapshot exits
+// 2-return 404 if it's not
+// 3-save the snapshot to local with the key snapshot to be used by the other handlers
+func ValidateSnapshotExist(c *fiber.Ctx) error {
+	volumeSnapshot, err := service.Get(types.NamespacedName{
+		Namespace: c.Locals("namespace").(string),
+		Name:      c.Params(nameKeyword),
+	})
+	if err != nil {
+		return c.Status(err.StatusCode()).JSON(err)
+	}
+
+	c.Locals("snapshot", volumeSnapshot)
+	return c.Next()
+}
+
+// nodeKey returns the node name from the path and the workspace namespace
+func nodeKey(c *fiber.Ctx) types.NamespacedName {
+	return types.NamespacedName{
+		Namespace: c.Locals("namespace").(string),
+		Name:      c.Params(nameKeyword),
+	}
+}
+
+func sortByNewest(snapshots []volumesnapshot.VolumeSnapshot) {
+	sort.Slice(snapshots[:], func(i, j int) bool {
+		return snapshots[j].CreationTimestamp.Before(&snapshots[i].CreationTimestamp)
+	})
+}
@@ -0,0 +1,196 @@
+package snapshot
+
+import (
+	"bytes"
+	"encoding/json"
+	"github.com/gofiber/fiber/v2"
+	"github.com/kotalco/core-api/core/snapshot"
+	"github.com/kotalco/core-api/k8s/volumesnapshot"
+	restErrors "github.com/kotalco/core-api/pkg/errors"
+	"github.com/kotalco/core-api/pkg/roles"
+	"github.com/stretchr/testify/assert"
+	"io/ioutil"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+)
+
+/*
+snapshot service mocks
+*/
+var (
+	snapshotGetFunc     func(key types.NamespacedName) (volumesnapshot.VolumeSnapshot, restErrors.IRestErr)
+	snapshotCreateFunc  func(node types.NamespacedName, dto snapshot.CreateSnapshotDto, trigger string) (volumesnapshot.VolumeSnapshot, restErrors.IRestErr)
+	snapshotRestoreFunc func(volumeSnapshot volumesnapshot.VolumeSnapshot, dto snapshot.RestoreDto) (corev1.PersistentVolumeClaim, restErrors.IRestErr)
+	snapshotDiscardFunc func(claim *corev1.PersistentVolumeClaim) restErrors.IRestErr
+)
+
+type snapshotServiceMock struct{}
+
+func (snapshotServiceMock) Get(key types.NamespacedName) (volumesnapshot.VolumeSnapshot, restErrors.IRestErr) {
+	return snapshotGetFunc(key)
+}
+func (snapshotServiceMock) Create(node types.NamespacedName, dto snapshot.CreateSnapshotDto, trigger string) (volumesnapshot.VolumeSnapshot, restErrors.IRestErr) {
+	return snapshotCreateFunc(node, dto, trigger)
+}
+func (snapshotServiceMock) List(namespace string) (volumesnapshot.VolumeSnapshotList, restErrors.IRestErr) {
+	return volumesnapshot.VolumeSnapshotList{}, nil
+}
+func (snapshotServiceMock) ListByNode(node types.NamespacedName) (volumesnapshot.VolumeSnapshotList, restErrors.IRestErr) {
+	return volumesnapshot.VolumeSnapshotList{}, nil
+}
+func (snapshotServiceMock) Delete(*volumesnapshot.VolumeSnapshot) restErrors.IRestErr {
+	return nil
+}
+func (snapshotServiceMock) GetSchedule(node types.NamespacedName) (snapshot.ScheduleDto, restErrors.IRestErr) {
+	return snapshot.ScheduleDto{}, nil
+}
+func (snapshotServiceMock) UpdateSchedule(node types.NamespacedName, dto snapshot.ScheduleDto) restErrors.IRestErr {
+	return nil
+}
+func (snapshotServiceMock) Restore(volumeSnapshot volumesnapshot.VolumeSnapshot, dto snapshot.RestoreDto) (corev1.PersistentVolumeClaim, restErrors.IRestErr) {
+	return snapshotRestoreFunc(volumeSnapshot, dto)
+}
+func (snapshotServiceMock) Discard(claim *corev1.PersistentVolumeClaim) restErrors.IRestErr {
+	return snapshotDiscardFunc(claim)
+}
+
+func TestMain(m *testing.M) {
+	service = &snapshotServiceMock{}
+
+	code := m.Run()
+	os.Exit(code)
+}
+
+var locals = map[string]interface{}{
+	"namespace":   "default",
+	"permissions": []string{roles.Permission(roles.Snapshots, roles.Read)},
+}
+
+func newFiberCtx(path string, dto interface{}, handlers []fiber.Handler, locals map[string]interface{}) ([]byte, *http.Response) {
+	app := fiber.New()
+	app.Post("/test/", append([]fiber.Handler{func(c *fiber.Ctx) error {
+		for key, element := range locals {
+			c.Locals(key, element)
+		}
+		return c.Next()
+	}}, handlers...)...)
+
+	marshaledDto, err := json.Marshal(dto)
+	if err != nil {
+		panic(err.Error())
+	}
+
+	req := httptest.NewRequest("POST", "/test"+path, bytes.NewBuffer(marshaledDto))
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := app.Test(req)
+	if err != nil {
+		panic(err.Error())
+	}
+
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		panic(err.Error())
+	}
+
+	return body, resp
+}
+
+func restoredClaim() corev1.PersistentVolumeClaim {
+	standard := "standard"
+	return corev1.PersistentVolumeClaim{
+		ObjectMeta: metav1.ObjectMeta{Name: "new-node", Namespace: "default"},
+		Spec: corev1.PersistentVolumeClaimSpec{
+			StorageClassName: &standard,
+			Resources: corev1.VolumeResourceRequirements{
+				Requests: corev1.ResourceList{corev1.ResourceStorage: resource.MustParse("100Gi")},
+			},
+		},
+	}
+}
+
+func TestCreate(t *testing.T) {
+	t.Run("create should snapshot the node volume", func(t *testing.T) {
+		snapshotCreateFunc = func(node types.NamespacedName, dto snapshot.CreateSnapshotDto, trigger string) (volumesnapshot.VolumeSnapshot, restErrors.IRestErr) {
+			assert.EqualValues(t, snapshot.ManualTrigger, trigger)
+			return volumesnapshot.VolumeSnapshot{ObjectMeta: metav1.ObjectMeta{Name: "my-snapshot"}}, nil
+		}
+		body, resp := newFiberCtx("", map[string]string{"snapshotClass": "csi-snapclass"}, []fiber.Handler{Create}, locals)
+		var result map[string]snapshot.SnapshotDto
+		err := json.Unmarshal(body, &result)
+		assert.Nil(t, err)
+		assert.EqualValues(t, http.StatusCreated, resp.StatusCode)
+		assert.EqualValues(t, "my-snapshot", result["data"].Name)
+	})
+	t.Run("create should throw validation error for invalid name", func(t *testing.T) {
+		_, resp := newFiberCtx("", map[string]string{"name": "Invalid Name"}, []fiber.Handler{Create}, locals)
+		assert.EqualValues(t, http.StatusBadRequest, resp.StatusCode)
+	})
+}
+
+func TestRestore(t *testing.T) {
+	snapshotGetFunc = func(key types.NamespacedName) (volumesnapshot.VolumeSnapshot, restErrors.IRestErr) {
+		return volumesnapshot.VolumeSnapshot{ObjectMeta: metav1.ObjectMeta{Name: key.Name, Namespace: key.Namespace}}, nil
+	}
+	snapshotRestoreFunc = func(volumeSnapshot volumesnapshot.VolumeSnapshot, dto snapshot.RestoreDto) (corev1.PersistentVolumeClaim, restErrors.IRestErr) {
+		return restoredClaim(), nil
+	}
+
+	t.Run("restore should pass through if no snapshot is given", func(t *testing.T) {
+		_, resp := newFiberCtx("", map[string]string{"name": "new-node"}, []fiber.Handler{Restore, func(c *fiber.Ctx) error {
+			return c.SendStatus(http.StatusCreated)
+		}}, locals)
+		assert.EqualValues(t, http.StatusCreated, resp.StatusCode)
+	})
+
+	t.Run("restore should request the restored volume for the node", func(t *testing.T) {
+		discarded := false
+		snapshotDiscardFunc = func(claim *corev1.PersistentVolumeClaim) restErrors.IRestErr {
+			discarded = true
+			return nil
+		}
+		var requested map[string]interface{}
+		_, resp := newFiberCtx("?fromSnapshot=my-snapshot", map[string]string{"name": "new-node"}, []fiber.Handler{Restore, func(c *fiber.Ctx) error {
+			_ = json.Unmarshal(c.Body(), &requested)
+			return c.SendStatus(http.StatusCreated)
+		}}, locals)
+		assert.EqualValues(t, http.StatusCreated, resp.StatusCode)
+		assert.EqualValues(t, "100Gi", requested["storage"])
+		assert.EqualValues(t, "standard", requested["storageClass"])
+		assert.False(t, discarded)
+	})
+
+	t.Run("restore should discard the restored volume if the node isn't created", func(t *testing.T) {
+		discarded := false
+		snapshotDiscardFunc = func(claim *corev1.PersistentVolumeClaim) restErrors.IRestErr {
+			discarded = true
+			return nil
+		}
+		_, resp := newFiberCtx("?fromSnapshot=my-snapshot", map[string]string{"name": "new-node"}, []fiber.Handler{Restore, func(c *fiber.Ctx) error {
+			return c.SendStatus(http.StatusBadRequest)
+		}}, locals)
+		assert.EqualValues(t, http.StatusBadRequest, resp.StatusCode)
+		assert.True(t, discarded)
+	})
+
+	t.Run("restore should throw forbidden without snapshots read permission", func(t *testing.T) {
+		_, resp := newFiberCtx("?fromSnapshot=my-snapshot", map[string]string{"name": "new-node"}, []fiber.Handler{Restore}, map[string]interface{}{
+			"namespace":   "default",
+			"permissions": []string{},
+		})
+		assert.EqualValues(t, http.StatusForbidden, resp.StatusCode)
+	})
+
+	t.Run("restore should throw if the snapshot doesn't exist", func(t *testing.T) {
+		snapshotGetFunc = func(key types.NamespacedName) (volumesnapshot.VolumeSnapshot, restErrors.IRestErr) {
+			return volumesnapshot.VolumeSnapshot{}, restErrors.NewNotFoundError("not found")
+		}
+		_, resp := newFiberCtx("?fromSnapshot=my-snapshot", map[string]string{"name": "new-node"}, []fiber.Handler{Restore}, locals)
+		assert.EqualValues(t, http.StatusNotFound, resp.StatusCode)
+	})
+}
@@ -3,10 +3,16 @@ package storage_class
 import (
 	"fmt"
 	"github.com/gofiber/fiber/v2"
+	"github.com/kotalco/core-api/config"
 	"github.com/kotalco/core-api/core/storage_class"
+	"github.com/kotalco/core-api/core/volume"
+	"github.com/kotalco/core-api/k8s/pvc"
+	restErrors "github.com/kotalco/core-api/pkg/errors"
 	"github.com/kotalco/core-api/pkg/pagination"
 	"github.com/kotalco/core-api/pkg/responder"
+	corev1 "k8s.io/api/core/v1"
 	storagev1 "k8s.io/api/storage/v1"
+	"k8s.io/apimachinery/pkg/types"
 	"net/http"
 	"sort"
 	"strconv"
@@ -16,7 +22,11 @@ const (
 	nameKeyword = "name"
 )
 
-var service = storage_class.NewStorageClassService()
+var (
+	service       = storage_class.NewStorageClassService()
+	pvcService    = pvc.NewService()
+	volumeService = volume.NewService()
+)
 
 // Get gets a single k8s storage class
 // 1-get the node validated from ValidateStorageClassExist method
@@ -53,21 +63,147 @@ func List(c *fiber.Ctx) error {
 }
 
 // Create creates k8s storage class from spec
-// todo
+// 1-creates dto from request
+// 2-call service to create the storage class, unsetting the other default storage classes if it's the default one
+// 3-marshall the model to the dto and format the response
 func Create(c *fiber.Ctx) error {
-	return c.SendStatus(http.StatusMethodNotAllowed)
+	dto := new(storage_class.StorageClassDto)
+	if err := c.BodyParser(dto); err != nil {
+		badReq := restErrors.NewBadRequestError("invalid request body")
+		return c.Status(badReq.StatusCode()).JSON(badReq)
+	}
+
+	err := dto.Validate()
+	if err != nil {
+		return c.Status(err.StatusCode()).JSON(err)
+	}
+
+	storageClass, err := service.Create(*dto)
+	if err != nil {
+		return c.Status(err.StatusCode()).JSON(err)
+	}
+
+	return c.Status(http.StatusCreated).JSON(responder.NewResponse(new(storage_class.StorageClassDto).FromCoreStorageClass(storageClass)))
 }
 
 // Delete deletes k8s storage class by name
-// todo
+// 1-check that no persistent volume claim in the cluster references the storage class
+// 2-call service to make the delete action
+// 3-return the respective response
 func Delete(c *fiber.Ctx) error {
-	return c.SendStatus(http.StatusMethodNotAllowed)
+	storageClass := c.Locals("storage_class").(storagev1.StorageClass)
+
+	classes, err := service.List()
+	if err != nil {
+		return c.Status(err.StatusCode()).JSON(err)
+	}
+	claims, err := pvcService.ListAll()
+	if err != nil {
+		return c.Status(err.StatusCode()).JSON(err)
+	}
+
+	references := storage_class.References(storageClass.Name, classes.Items, claims.Items)
+	if len(references) > 0 {
+		conflict := restErrors.NewConflictError(fmt.Sprintf("storage class is used by %d persistent volume claims", len(references)))
+		return c.Status(conflict.StatusCode()).JSON(conflict)
+	}
+
+	err = service.Delete(&storageClass)
+	if err != nil {
+		return c.Status(err.StatusCode()).JSON(err)
+	}
+
+	return c.SendStatus(http.StatusNoContent)
 }
 
 // Update updates k8s storage class by name from spec
-// todo
+// only volume expansion, mount options and the default annotation can be updated after creation
 func Update(c *fiber.Ctx) error {
-	return c.SendStatus(http.StatusMethodNotAllowed)
+	storageClass := c.Locals("storage_class").(storagev1.StorageClass)
+
+	dto := new(storage_class.UpdateStorageClassDto)
+	if err := c.BodyParser(dto); err != nil {
+		badReq := restErrors.NewBadRequestError("invalid request body")
+		return c.Status(badReq.StatusCode()).JSON(badReq)
+	}
+
+	err := service.Update(*dto, &storageClass)
+	if err != nil {
+		return c.Status(err.StatusCode()).JSON(err)
+	}
+
+	return c.Status(http.StatusOK).JSON(responder.NewResponse(new(storage_class.StorageClassDto).FromCoreStorageClass(storageClass)))
+}
+
+// Volumes returns the workspace nodes persistent volume claims with their usage
+// and the storage classes safe to delete because no claim in the cluster references them
+// 1-get the workspace claims and their usage from the kubelets volume stats
+// 2-resolve the claims storage class, the default storage class is used by claims without one
+// 3-get the unreferenced storage classes across all namespaces
+func Volumes(c *fiber.Ctx) error {
+	namespace := c.Locals("namespace").(string)
+
+	claims, err := pvcService.List(namespace)
+	if err != nil {
+		return c.Status(err.StatusCode()).JSON(err)
+	}
+	stats, err := pvcService.Stats(namespace)
+	if err != nil {
+		return c.Status(err.StatusCode()).JSON(err)
+	}
+	classes, err := service.List()
+	if err != nil {
+		return c.Status(err.StatusCode()).JSON(err)
+	}
+	allClaims, err := pvcService.ListAll()
+	if err != nil {
+		return c.Status(err.StatusCode()).JSON(err)
+	}
+
+	sort.Slice(claims.Items[:], func(i, j int) bool {
+		return claims.Items[j].CreationTimestamp.Before(&claims.Items[i].CreationTimestamp)
+	})
+
+	volumes := make([]storage_class.VolumeDto, 0)
+	for _, claim := range claims.Items {
+		requested := claim.Spec.Resources.Requests[corev1.ResourceStorage]
+		dto := storage_class.VolumeDto{
+			Name:           claim.Name,
+			Node:           claim.Labels["app.kubernetes.io/instance"],
+			Protocol:       claim.Labels["kotal.io/protocol"],
+			StorageClass:   storage_class.ClaimStorageClass(claim, classes.Items),
+			Phase:          string(claim.Status.Phase),
+			RequestedBytes: requested.Value(),
+		}
+		if usage, ok := stats[claim.Name]; ok {
+			capacity, used, available := int64(usage.CapacityBytes), int64(usage.UsedBytes), int64(usage.AvailableBytes)
+			dto.CapacityBytes, dto.UsedBytes, dto.AvailableBytes = &capacity, &used, &available
+			dto.CapacityWarning = volume.AboveThreshold(usage, config.Environment.StorageWarningThreshold)
+		}
+		volumes = append(volumes, dto)
+	}
+
+	return c.Status(http.StatusOK).JSON(responder.NewResponse(storage_class.VolumesDto{
+		Volumes:                 volumes,
+		DeletableStorageClasses: storage_class.Unreferenced(classes.Items, allClaims.Items),
+	}))
+}
+
+// NodeVolume returns the volume of the node by name with its resize progress and usage
+// 1-call volume service to get the node persistent volume claim status
+// 2-return not found if the node doesn't have a volume
+func NodeVolume(c *fiber.Ctx) error {
+	key := types.NamespacedName{
+		Namespace: c.Locals("namespace").(string),
+		Name:      c.Params(nameKeyword),
+	}
+
+	status, err := volumeService.Status(key)
+	if err != nil {
+		return c.Status(err.StatusCode()).JSON(err)
+	}
+
+	return c.Status(http.StatusOK).JSON(responder.NewResponse(status))
 }
 
 // ValidateStorageClassExist validate storage class by name exist acts as a validation for all handlers the needs to find storage class by name
@@ -1 +1,289 @@
 package storage_class
+
+import (
+	"bytes"
+	"encoding/json"
+	"github.com/gofiber/fiber/v2"
+	"github.com/kotalco/core-api/core/storage_class"
+	"github.com/kotalco/core-api/core/volume"
+	"github.com/kotalco/core-api/k8s/pvc"
+	restErrors "github.com/kotalco/core-api/pkg/errors"
+	sharedAPI "github.com/kotalco/kotal/apis/shared"
+	"github.com/stretchr/testify/assert"
+	"io/ioutil"
+	corev1 "k8s.io/api/core/v1"
+	storagev1 "k8s.io/api/storage/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"testing"
+)
+
+/*
+storage class service mocks
+*/
+var (
+	storageClassCreateFunc func(dto storage_class.StorageClassDto) (storagev1.StorageClass, restErrors.IRestErr)
+	storageClassUpdateFunc func(dto storage_class.UpdateStorageClassDto, sc *storagev1.StorageClass) restErrors.IRestErr
+	storageClassListFunc   func() (storagev1.StorageClassList, restErrors.IRestErr)
+	storageClassDeleteFunc func(sc *storagev1.StorageClass) restErrors.IRestErr
+)
+
+type storageClassServiceMock struct{}
+
+func (storageClassServiceMock) Get(name string) (storagev1.StorageClass, restErrors.IRestErr) {
+	return storagev1.StorageClass{}, nil
+}
+func (storageClassServiceMock) Create(dto storage_class.StorageClassDto) (storagev1.StorageClass, restErrors.IRestErr) {
+	return storageClassCreateFunc(dto)
+}
+func (storageClassServiceMock) Update(dto storage_class.UpdateStorageClassDto, sc *storagev1.StorageClass) restErrors.IRestErr {
+	return storageClassUpdateFunc(dto, sc)
+}
+func (storageClassServiceMock) List() (storagev1.StorageClassList, restErrors.IRestErr) {
+	return storageClassListFunc()
+}
+func (storageClassServiceMock) Delete(sc *storagev1.StorageClass) restErrors.IRestErr {
+	return storageClassDeleteFunc(sc)
+}
+func (storageClassServiceMock) Count() (int, restErrors.IRestErr) {
+	return 0, nil
+}
+
+/*
+persistent volume claim service mocks
+*/
+var (
+	pvcListFunc    func(namespace string) (corev1.PersistentVolumeClaimList, restErrors.IRestErr)
+	pvcListAllFunc func() (corev1.PersistentVolumeClaimList, restErrors.IRestErr)
+	pvcStatsFunc   func(namespace string) (map[string]pvc.VolumeStats, restErrors.IRestErr)
+)
+
+type pvcServiceMock struct{}
+
+func (pvcServiceMock) Get(key types.NamespacedName) (corev1.PersistentVolumeClaim, restErrors.IRestErr) {
+	return corev1.PersistentVolumeClaim{}, nil
+}
+func (pvcServiceMock) Create(claim *corev1.PersistentVolumeClaim) restErrors.IRestErr {
+	return nil
+}
+func (pvcServiceMock) Update(claim *corev1.PersistentVolumeClaim) restErrors.IRestErr {
+	return nil
+}
+func (pvcServiceMock) Delete(claim *corev1.PersistentVolumeClaim) restErrors.IRestErr {
+	return nil
+}
+func (pvcServiceMock) List(namespace string) (corev1.PersistentVolumeClaimList, restErrors.IRestErr) {
+	return pvcListFunc(namespace)
+}
+func (pvcServiceMock) ListAll() (corev1.PersistentVolumeClaimList, restErrors.IRestErr) {
+	return pvcListAllFunc()
+}
+func (pvcServiceMock) Stats(namespace string) (map[string]pvc.VolumeStats, restErrors.IRestErr) {
+	return pvcStatsFunc(namespace)
+}
+
+/*
+volume service mocks
+*/
+var volumeStatusFunc func(key types.NamespacedName) (volume.VolumeStatusDto, restErrors.IRestErr)
+
+type volumeServiceMock struct{}
+
+func (volumeServiceMock) Status(key types.NamespacedName) (volume.VolumeStatusDto, restErrors.IRestErr) {
+	return volumeStatusFunc(key)
+}
+func (volumeServiceMock) Expand(node client.Object, resources *sharedAPI.Resources, dto volume.ExpandVolumeDto) (volume.VolumeStatusDto, restErrors.IRestErr) {
+	return volume.VolumeStatusDto{}, nil
+}
+
+func newFiberCtx(dto interface{}, method func(c *fiber.Ctx) error, locals map[string]interface{}) ([]byte, *http.Response) {
+	app := fiber.New()
+	app.Post("/test/", func(c *fiber.Ctx) error {
+		for key, element := range locals {
+			c.Locals(key, element)
+		}
+		return method(c)
+	})
+
+	marshaledDto, err := json.Marshal(dto)
+	if err != nil {
+		panic(err.Error())
+	}
+
+	req := httptest.NewRequest("POST", "/test", bytes.NewBuffer(marshaledDto))
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := app.Test(req)
+	if err != nil {
+		panic(err.Error())
+	}
+
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		panic(err.Error())
+	}
+
+	return body, resp
+}
+
+func TestMain(m *testing.M) {
+	service = &storageClassServiceMock{}
+	pvcService = &pvcServiceMock{}
+	volumeService = &volumeServiceMock{}
+
+	code := m.Run()
+	os.Exit(code)
+}
+
+func TestCreate(t *testing.T) {
+	t.Run("create storage class should pass", func(t *testing.T) {
+		storageClassCreateFunc = func(dto storage_class.StorageClassDto) (storagev1.StorageClass, restErrors.IRestErr) {
+			return storagev1.StorageClass{ObjectMeta: metav1.ObjectMeta{Name: dto.Name}, Provisioner: dto.Provisioner, Parameters: dto.Parameters}, nil
+		}
+		dto := storage_class.StorageClassDto{Name: "fast", Provisioner: "ebs.csi.aws.com", Parameters: map[string]string{"type": "gp3"}}
+
+		body, resp := newFiberCtx(dto, Create, map[string]interface{}{})
+		var result map[string]storage_class.StorageClassDto
+		err := json.Unmarshal(body, &result)
+		assert.Nil(t, err)
+		assert.EqualValues(t, http.StatusCreated, resp.StatusCode)
+		assert.EqualValues(t, "gp3", result["data"].Parameters["type"])
+	})
+	t.Run("create storage class should throw validation error", func(t *testing.T) {
+		_, resp := newFiberCtx(storage_class.StorageClassDto{Name: "fast"}, Create, map[string]interface{}{})
+		assert.EqualValues(t, http.StatusBadRequest, resp.StatusCode)
+	})
+}
+
+func TestUpdate(t *testing.T) {
+	locals := map[string]interface{}{"storage_class": storagev1.StorageClass{ObjectMeta: metav1.ObjectMeta{Name: "fast"}}}
+
+	t.Run("update storage class should pass", func(t *testing.T) {
+		storageClassUpdateFunc = func(dto storage_class.UpdateStorageClassDto, sc *storagev1.StorageClass) restErrors.IRestErr {
+			sc.AllowVolumeExpansion = dto.AllowVolumeExpansion
+			return nil
+		}
+
+		body, resp := newFiberCtx(map[string]interface{}{"allowVolumeExpansion": true}, Update, locals)
+		var result map[string]storage_class.StorageClassDto
+		err := json.Unmarshal(body, &result)
+		assert.Nil(t, err)
+		assert.EqualValues(t, http.StatusOK, resp.StatusCode)
+		assert.True(t, result["data"].AllowVolumeExpansion)
+	})
+}
+
+func TestDelete(t *testing.T) {
+	locals := map[string]interface{}{"storage_class": storagev1.StorageClass{ObjectMeta: metav1.ObjectMeta{Name: "fast"}}}
+	storageClassListFunc = func() (storagev1.StorageClassList, restErrors.IRestErr) {
+		return storagev1.StorageClassList{Items: []storagev1.StorageClass{{ObjectMeta: metav1.ObjectMeta{Name: "fast"}}}}, nil
+	}
+	storageClassDeleteFunc = func(sc *storagev1.StorageClass) restErrors.IRestErr {
+		return nil
+	}
+
+	t.Run("delete storage class should pass if no claim references it", func(t *testing.T) {
+		pvcListAllFunc = func() (corev1.PersistentVolumeClaimList, restErrors.IRestErr) {
+			return corev1.PersistentVolumeClaimList{}, nil
+		}
+		_, resp := newFiberCtx("", Delete, locals)
+		assert.EqualValues(t, http.StatusNoContent, resp.StatusCode)
+	})
+	t.Run("delete storage class should throw conflict if claims reference it", func(t *testing.T) {
+		fast := "fast"
+		pvcListAllFunc = func() (corev1.PersistentVolumeClaimList, restErrors.IRestErr) {
+			return corev1.PersistentVolumeClaimList{Items: []corev1.PersistentVolumeClaim{{Spec: corev1.PersistentVolumeClaimSpec{StorageClassName: &fast}}}}, nil
+		}
+		body, resp := newFiberCtx("", Delete, locals)
+		var result restErrors.RestErr
+		err := json.Unmarshal(body, &result)
+		assert.Nil(t, err)
+		assert.EqualValues(t, http.StatusConflict, resp.StatusCode)
+		assert.EqualValues(t, "storage class is used by 1 persistent volume claims", result.Message)
+	})
+}
+
+func TestVolumes(t *testing.T) {
+	locals := map[string]interface{}{"namespace": "default"}
+	standard := "standard"
+	claim := corev1.PersistentVolumeClaim{
+		ObjectMeta: metav1.ObjectMeta{Name: "my-node", Labels: map[string]string{"app.kubernetes.io/instance": "my-node", "kotal.io/protocol": "ethereum"}},
+		Spec: corev1.PersistentVolumeClaimSpec{
+			StorageClassName: &standard,
+			Resources:        corev1.VolumeResourceRequirements{Requests: corev1.ResourceList{corev1.ResourceStorage: resource.MustParse("1Gi")}},
+		},
+		Status: corev1.PersistentVolumeClaimStatus{Phase: corev1.ClaimBound},
+	}
+	pvcListFunc = func(namespace string) (corev1.PersistentVolumeClaimList, restErrors.IRestErr) {
+		return corev1.PersistentVolumeClaimList{Items: []corev1.PersistentVolumeClaim{claim}}, nil
+	}
+	pvcListAllFunc = func() (corev1.PersistentVolumeClaimList, restErrors.IRestErr) {
+		return corev1.PersistentVolumeClaimList{Items: []corev1.PersistentVolumeClaim{claim}}, nil
+	}
+	storageClassListFunc = func() (storagev1.StorageClassList, restErrors.IRestErr) {
+		return storagev1.StorageClassList{Items: []storagev1.StorageClass{{ObjectMeta: metav1.ObjectMeta{Name: "standard"}}, {ObjectMeta: metav1.ObjectMeta{Name: "fast"}}}}, nil
+	}
+
+	t.Run("volumes should return the claims usage and deletable storage classes", func(t *testing.T) {
+		pvcStatsFunc = func(namespace string) (map[string]pvc.VolumeStats, restErrors.IRestErr) {
+			return map[string]pvc.VolumeStats{"my-node": {CapacityBytes: 1073741824, UsedBytes: 536870912, AvailableBytes: 536870912}}, nil
+		}
+
+		body, resp := newFiberCtx("", Volumes, locals)
+		var result map[string]storage_class.VolumesDto
+		err := json.Unmarshal(body, &result)
+		assert.Nil(t, err)
+		assert.EqualValues(t, http.StatusOK, resp.StatusCode)
+
+		nodeVolume := result["data"].Volumes[0]
+		assert.EqualValues(t, "my-node", nodeVolume.Node)
+		assert.EqualValues(t, "ethereum", nodeVolume.Protocol)
+		assert.EqualValues(t, "standard", nodeVolume.StorageClass)
+		assert.EqualValues(t, 1073741824, nodeVolume.RequestedBytes)
+		assert.EqualValues(t, 536870912, *nodeVolume.UsedBytes)
+		assert.False(t, nodeVolume.CapacityWarning)
+		assert.EqualValues(t, []string{"fast"}, result["data"].DeletableStorageClasses)
+	})
+	t.Run("volumes should return the claims without usage if the kubelet didn't report it", func(t *testing.T) {
+		pvcStatsFunc = func(namespace string) (map[string]pvc.VolumeStats, restErrors.IRestErr) {
+			return map[string]pvc.VolumeStats{}, nil
+		}
+
+		body, resp := newFiberCtx("", Volumes, locals)
+		var result map[string]storage_class.VolumesDto
+		err := json.Unmarshal(body, &result)
+		assert.Nil(t, err)
+		assert.EqualValues(t, http.StatusOK, resp.StatusCode)
+		assert.Nil(t, result["data"].Volumes[0].UsedBytes)
+	})
+}
+
+func TestNodeVolume(t *testing.T) {
+	locals := map[string]interface{}{"namespace": "default"}
+
+	t.Run("node volume should return the volume status", func(t *testing.T) {
+		volumeStatusFunc = func(key types.NamespacedName) (volume.VolumeStatusDto, restErrors.IRestErr) {
+			return volume.VolumeStatusDto{Name: key.Name, Requested: "200Gi", Capacity: "100Gi", Resizing: true}, nil
+		}
+
+		body, resp := newFiberCtx("", NodeVolume, locals)
+		var result map[string]volume.VolumeStatusDto
+		err := json.Unmarshal(body, &result)
+		assert.Nil(t, err)
+		assert.EqualValues(t, http.StatusOK, resp.StatusCode)
+		assert.True(t, result["data"].Resizing)
+		assert.EqualValues(t, "200Gi", result["data"].Requested)
+	})
+	t.Run("node volume should throw if the node doesn't have a volume", func(t *testing.T) {
+		volumeStatusFunc = func(key types.NamespacedName) (volume.VolumeStatusDto, restErrors.IRestErr) {
+			return volume.VolumeStatusDto{}, restErrors.NewNotFoundError("persistent volume claim by name my-node doesn't exist")
+		}
+
+		_, resp := newFiberCtx("", NodeVolume, locals)
+		assert.EqualValues(t, http.StatusNotFound, resp.StatusCode)
+	})
+}
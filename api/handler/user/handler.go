@@ -8,11 +8,13 @@ import (
 	"github.com/kotalco/core-api/core/user"
 	"github.com/kotalco/core-api/core/verification"
 	"github.com/kotalco/core-api/core/workspace"
+	"github.com/kotalco/core-api/core/workspacerole"
 	"github.com/kotalco/core-api/k8s"
 	restErrors "github.com/kotalco/core-api/pkg/errors"
 	"github.com/kotalco/core-api/pkg/logger"
+	"github.com/kotalco/core-api/pkg/mailer"
 	"github.com/kotalco/core-api/pkg/responder"
-	"github.com/kotalco/core-api/pkg/sendgrid"
+	"github.com/kotalco/core-api/pkg/roles"
 	"github.com/kotalco/core-api/pkg/sqlclient"
 	"github.com/kotalco/core-api/pkg/token"
 	"net/http"
@@ -21,12 +23,13 @@ import (
 )
 
 var (
-	userService         = user.NewService()
-	mailService         = sendgrid.NewService()
-	verificationService = verification.NewService()
-	workspaceService    = workspace.NewService()
-	settingService      = setting.NewService()
-	namespaceService    = k8s.NewNamespaceService()
+	userService          = user.NewService()
+	mailService          = mailer.NewService()
+	verificationService  = verification.NewService()
+	workspaceService     = workspace.NewService()
+	settingService       = setting.NewService()
+	namespaceService     = k8s.NewNamespaceService()
+	workspaceRoleService = workspacerole.NewService()
 )
 
 // SignUp validate dto , create user , send verification token, create the default namespace and create the default workspace
@@ -53,6 +56,11 @@ func SignUp(c *fiber.Ctx) error {
 		return c.Status(err.StatusCode()).JSON(err)
 	}
 
+	restErr = userService.WithoutTransaction().CheckPasswordPolicy(nil, dto.Password)
+	if restErr != nil {
+		return c.Status(restErr.StatusCode()).JSON(restErr)
+	}
+
 	txRead := sqlclient.Begin(&sql.TxOptions{
 		Isolation: sql.LevelReadUncommitted,
 	})
@@ -128,7 +136,7 @@ func SignUp(c *fiber.Ctx) error {
 	//section that user don't need to wait for
 	if usersCount > 1 { // if this user isn't the first user in the cluster send verification email
 		//send email verification
-		mailRequest := new(sendgrid.MailRequestDto)
+		mailRequest := new(mailer.MailRequestDto)
 		mailRequest.Token = token
 		mailRequest.Email = model.Email
 		restErr = mailService.SignUp(mailRequest)
@@ -158,6 +166,9 @@ func SignIn(c *fiber.Ctx) error {
 
 	session, restErr := userService.WithoutTransaction().SignIn(dto)
 	if restErr != nil {
+		if retryAfter := restErrors.RetryAfter(restErr); retryAfter > 0 {
+			c.Set(fiber.HeaderRetryAfter, strconv.FormatInt(retryAfter, 10))
+		}
 		return c.Status(restErr.StatusCode()).JSON(restErr)
 	}
 
@@ -196,7 +207,7 @@ func SendEmailVerification(c *fiber.Ctx) error {
 		return c.Status(err.StatusCode()).JSON(err)
 	}
 
-	mailRequest := new(sendgrid.MailRequestDto)
+	mailRequest := new(mailer.MailRequestDto)
 	mailRequest.Token = token
 	mailRequest.Email = userModel.Email
 
@@ -276,7 +287,7 @@ func ForgetPassword(c *fiber.Ctx) error {
 		return c.Status(err.StatusCode()).JSON(err)
 	}
 
-	mailRequest := new(sendgrid.MailRequestDto)
+	mailRequest := new(mailer.MailRequestDto)
 	mailRequest.Token = token
 	mailRequest.Email = userModel.Email
 
@@ -320,6 +331,11 @@ func ResetPassword(c *fiber.Ctx) error {
 		sqlclient.Rollback(txHandle)
 		return c.Status(err.StatusCode()).JSON(err)
 	}
+	err = userService.WithTransaction(txHandle).CheckPasswordPolicy(userModel, dto.Password)
+	if err != nil {
+		sqlclient.Rollback(txHandle)
+		return c.Status(err.StatusCode()).JSON(err)
+	}
 	err = userService.WithTransaction(txHandle).ResetPassword(userModel, dto.Password)
 	if err != nil {
 		sqlclient.Rollback(txHandle)
@@ -392,7 +408,7 @@ func ChangeEmail(c *fiber.Ctx) error {
 		return c.Status(err.StatusCode()).JSON(err)
 	}
 
-	mailRequest := new(sendgrid.MailRequestDto)
+	mailRequest := new(mailer.MailRequestDto)
 	mailRequest.Token = token
 	mailRequest.Email = userDetails.Email
 
@@ -494,6 +510,9 @@ func VerifyTOTP(c *fiber.Ctx) error {
 
 	session, err := userService.WithoutTransaction().VerifyTOTP(userDetails, dto.TOTP)
 	if err != nil {
+		if retryAfter := restErrors.RetryAfter(err); retryAfter > 0 {
+			c.Set(fiber.HeaderRetryAfter, strconv.FormatInt(retryAfter, 10))
+		}
 		return c.Status(err.StatusCode()).JSON(err)
 	}
 
@@ -526,3 +545,173 @@ func DisableTwoFactorAuth(c *fiber.Ctx) error {
 
 	return c.Status(http.StatusOK).JSON(responder.NewResponse(responder.SuccessMessage{Message: "2FA disabled"}))
 }
+
+// DeleteAccount re-authenticates the user then deletes the account
+// owned workspaces are handed off to another member, admins first, workspaces without other members are deleted with their namespaces
+// the user memberships and verification records are removed, and the members of the owned workspaces are notified by email
+func DeleteAccount(c *fiber.Ctx) error {
+	userId := c.Locals("user").(token.UserDetails).ID
+	model, err := userService.WithoutTransaction().GetById(userId)
+	if err != nil {
+		return c.Status(err.StatusCode()).JSON(err)
+	}
+
+	dto := new(user.DeleteAccountRequestDto)
+	if err := c.BodyParser(dto); err != nil {
+		badReq := restErrors.NewBadRequestError("invalid request body")
+		return c.Status(badReq.StatusCode()).JSON(badReq)
+	}
+
+	err = user.Validate(dto)
+	if err != nil {
+		return c.Status(err.StatusCode()).JSON(err)
+	}
+
+	err = userService.WithoutTransaction().Reauthenticate(model, dto)
+	if err != nil {
+		if retryAfter := restErrors.RetryAfter(err); retryAfter > 0 {
+			c.Set(fiber.HeaderRetryAfter, strconv.FormatInt(retryAfter, 10))
+		}
+		return c.Status(err.StatusCode()).JSON(err)
+	}
+
+	list, err := workspaceService.WithoutTransaction().GetByUserId(model.ID)
+	if err != nil {
+		return c.Status(err.StatusCode()).JSON(err)
+	}
+
+	transferred := make([]*workspace.Workspace, 0)
+	deleted := make([]*workspace.Workspace, 0)
+
+	txHandle := sqlclient.Begin()
+	for _, v := range list {
+		if v.UserId != model.ID {
+			err = workspaceService.WithTransaction(txHandle).DeleteWorkspaceMember(v, model.ID)
+			if err != nil {
+				sqlclient.Rollback(txHandle)
+				return c.Status(err.StatusCode()).JSON(err)
+			}
+			continue
+		}
+
+		owned, err := workspaceService.WithTransaction(txHandle).GetById(v.ID)
+		if err != nil {
+			sqlclient.Rollback(txHandle)
+			return c.Status(err.StatusCode()).JSON(err)
+		}
+
+		if newOwnerId := nextOwner(owned, model.ID); newOwnerId != "" {
+			err = workspaceService.WithTransaction(txHandle).TransferOwnership(owned, newOwnerId)
+			if err != nil {
+				sqlclient.Rollback(txHandle)
+				return c.Status(err.StatusCode()).JSON(err)
+			}
+			err = workspaceService.WithTransaction(txHandle).DeleteWorkspaceMember(owned, model.ID)
+			if err != nil {
+				sqlclient.Rollback(txHandle)
+				return c.Status(err.StatusCode()).JSON(err)
+			}
+			transferred = append(transferred, owned)
+			continue
+		}
+
+		if owned.K8sNamespace == "default" {
+			sqlclient.Rollback(txHandle)
+			badReq := restErrors.NewBadRequestError("the default workspace can't be deleted, add another member to it before deleting your account")
+			return c.Status(badReq.StatusCode()).JSON(badReq)
+		}
+
+		err = workspaceService.WithTransaction(txHandle).Delete(owned)
+		if err != nil {
+			sqlclient.Rollback(txHandle)
+			return c.Status(err.StatusCode()).JSON(err)
+		}
+		err = workspaceRoleService.WithTransaction(txHandle).DeleteByWorkspaceId(owned.ID)
+		if err != nil {
+			sqlclient.Rollback(txHandle)
+			return c.Status(err.StatusCode()).JSON(err)
+		}
+		deleted = append(deleted, owned)
+	}
+
+	err = verificationService.WithTransaction(txHandle).DeleteByUserId(model.ID)
+	if err != nil {
+		sqlclient.Rollback(txHandle)
+		return c.Status(err.StatusCode()).JSON(err)
+	}
+
+	err = userService.WithTransaction(txHandle).Delete(model)
+	if err != nil {
+		sqlclient.Rollback(txHandle)
+		return c.Status(err.StatusCode()).JSON(err)
+	}
+
+	if commitErr := sqlclient.Commit(txHandle); commitErr != nil {
+		go logger.Error("DELETE_ACCOUNT", commitErr)
+		internalErr := restErrors.NewInternalServerError("some thing went wrong")
+		return c.Status(internalErr.StatusCode()).JSON(internalErr)
+	}
+
+	// the namespaces are deleted only after the account removal is committed, so a failed commit never leaves workspaces without their resources
+	for _, v := range deleted {
+		err = namespaceService.Delete(v.K8sNamespace)
+		if err != nil && err.StatusCode() != http.StatusNotFound {
+			go logger.Error("DELETE_ACCOUNT", err)
+		}
+	}
+
+	go notifyWorkspacesMembers(transferred, model.ID)
+
+	return c.SendStatus(http.StatusNoContent)
+}
+
+// nextOwner picks the member who takes over the workspace ownership from the leaving user, admins first
+// returns empty string if the leaving user is the only member
+func nextOwner(model *workspace.Workspace, leavingUserId string) string {
+	nextOwnerId := ""
+	for _, v := range model.WorkspaceUsers {
+		if v.UserId == leavingUserId {
+			continue
+		}
+		if v.Role == roles.Admin {
+			return v.UserId
+		}
+		if nextOwnerId == "" {
+			nextOwnerId = v.UserId
+		}
+	}
+	return nextOwnerId
+}
+
+// notifyWorkspacesMembers emails the members of the transferred workspaces with their new owner
+func notifyWorkspacesMembers(transferred []*workspace.Workspace, leavingUserId string) {
+	for _, v := range transferred {
+		userIds := make([]string, 0)
+		for _, member := range v.WorkspaceUsers {
+			if member.UserId != leavingUserId {
+				userIds = append(userIds, member.UserId)
+			}
+		}
+
+		members, err := userService.WithoutTransaction().FindWhereIdInSlice(userIds)
+		if err != nil {
+			go logger.Error("NOTIFY_WORKSPACES_MEMBERS", err)
+			continue
+		}
+
+		newOwnerEmail := ""
+		for _, member := range members {
+			if member.ID == v.UserId {
+				newOwnerEmail = member.Email
+			}
+		}
+
+		for _, member := range members {
+			mailRequest := new(mailer.WorkspaceOwnershipMailRequestDto)
+			mailRequest.Email = member.Email
+			mailRequest.WorkspaceName = v.Name
+			mailRequest.NewOwnerEmail = newOwnerEmail
+			mailService.WorkspaceTransferred(mailRequest)
+		}
+	}
+}
@@ -6,7 +6,9 @@ import (
 	"fmt"
 	"github.com/kotalco/core-api/core/setting"
 	"github.com/kotalco/core-api/core/workspace"
+	"github.com/kotalco/core-api/core/workspacerole"
 	"github.com/kotalco/core-api/core/workspaceuser"
+	"github.com/kotalco/core-api/pkg/roles"
 	"github.com/kotalco/core-api/pkg/sqlclient"
 	"github.com/kotalco/core-api/pkg/token"
 	"gorm.io/gorm"
@@ -16,10 +18,11 @@ import (
 	"net/http/httptest"
 	"os"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 
-	"github.com/kotalco/core-api/pkg/sendgrid"
+	"github.com/kotalco/core-api/pkg/mailer"
 
 	"github.com/gofiber/fiber/v2"
 
@@ -32,22 +35,31 @@ import (
 User service Mocks
 */
 var (
-	UserWithTransactionFunc     func(txHandle *gorm.DB) user.IService
-	SignUpFunc                  func(dto *user.SignUpRequestDto) (*user.User, restErrors.IRestErr)
-	SignInFunc                  func(dto *user.SignInRequestDto) (*user.UserSessionResponseDto, restErrors.IRestErr)
-	VerifyTOTPFunc              func(model *user.User, totp string) (*user.UserSessionResponseDto, restErrors.IRestErr)
-	GetByEmailFunc              func(email string) (*user.User, restErrors.IRestErr)
-	GetByIdFunc                 func(Id string) (*user.User, restErrors.IRestErr)
-	VerifyEmailFunc             func(model *user.User) restErrors.IRestErr
-	ResetPasswordFunc           func(model *user.User, password string) restErrors.IRestErr
-	ChangePasswordFunc          func(model *user.User, dto *user.ChangePasswordRequestDto) restErrors.IRestErr
-	ChangeEmailFunc             func(model *user.User, dto *user.ChangeEmailRequestDto) restErrors.IRestErr
-	CreateTOTPFunc              func(model *user.User, dto *user.CreateTOTPRequestDto) (bytes.Buffer, restErrors.IRestErr)
-	EnableTwoFactorAuthFunc     func(model *user.User, totp string) (*user.User, restErrors.IRestErr)
-	DisableTwoFactorAuthFunc    func(model *user.User, dto *user.DisableTOTPRequestDto) restErrors.IRestErr
-	FindWhereIdInSliceFunc      func(ids []string) ([]*user.User, restErrors.IRestErr)
-	usersCountFunc              func() (int64, restErrors.IRestErr)
-	usersSetAsPlatformAdminFunc func(model *user.User) restErrors.IRestErr
+	UserWithTransactionFunc      func(txHandle *gorm.DB) user.IService
+	SignUpFunc                   func(dto *user.SignUpRequestDto) (*user.User, restErrors.IRestErr)
+	SignInFunc                   func(dto *user.SignInRequestDto) (*user.UserSessionResponseDto, restErrors.IRestErr)
+	VerifyTOTPFunc               func(model *user.User, totp string) (*user.UserSessionResponseDto, restErrors.IRestErr)
+	GetByEmailFunc               func(email string) (*user.User, restErrors.IRestErr)
+	GetByIdFunc                  func(Id string) (*user.User, restErrors.IRestErr)
+	VerifyEmailFunc              func(model *user.User) restErrors.IRestErr
+	ResetPasswordFunc            func(model *user.User, password string) restErrors.IRestErr
+	InvalidatePasswordFunc       func(model *user.User) restErrors.IRestErr
+	ChangePasswordFunc           func(model *user.User, dto *user.ChangePasswordRequestDto) restErrors.IRestErr
+	ChangeEmailFunc              func(model *user.User, dto *user.ChangeEmailRequestDto) restErrors.IRestErr
+	CreateTOTPFunc               func(model *user.User, dto *user.CreateTOTPRequestDto) (bytes.Buffer, restErrors.IRestErr)
+	EnableTwoFactorAuthFunc      func(model *user.User, totp string) (*user.User, restErrors.IRestErr)
+	DisableTwoFactorAuthFunc     func(model *user.User, dto *user.DisableTOTPRequestDto) restErrors.IRestErr
+	FindWhereIdInSliceFunc       func(ids []string) ([]*user.User, restErrors.IRestErr)
+	usersCountFunc               func() (int64, restErrors.IRestErr)
+	usersSetAsPlatformAdminFunc  func(model *user.User) restErrors.IRestErr
+	usersUnsetPlatformAdminFunc  func(model *user.User) restErrors.IRestErr
+	usersSearchFunc              func(email string) ([]*user.User, restErrors.IRestErr)
+	usersDisableFunc             func(model *user.User) restErrors.IRestErr
+	usersEnableFunc              func(model *user.User) restErrors.IRestErr
+	usersResetTwoFactorAuthFunc  func(model *user.User) restErrors.IRestErr
+	usersCheckPasswordPolicyFunc func(model *user.User, password string) restErrors.IRestErr
+	usersReauthenticateFunc      func(model *user.User, dto *user.DeleteAccountRequestDto) restErrors.IRestErr
+	usersDeleteFunc              func(model *user.User) restErrors.IRestErr
 )
 
 type userServiceMock struct{}
@@ -84,6 +96,10 @@ func (userServiceMock) ResetPassword(model *user.User, password string) restErro
 	return ResetPasswordFunc(model, password)
 }
 
+func (userServiceMock) InvalidatePassword(model *user.User) restErrors.IRestErr {
+	return InvalidatePasswordFunc(model)
+}
+
 func (userServiceMock) ChangePassword(model *user.User, dto *user.ChangePasswordRequestDto) restErrors.IRestErr {
 	return ChangePasswordFunc(model, dto)
 }
@@ -116,6 +132,30 @@ func (userServiceMock) Count() (int64, restErrors.IRestErr) {
 func (userServiceMock) SetAsPlatformAdmin(model *user.User) restErrors.IRestErr {
 	return usersSetAsPlatformAdminFunc(model)
 }
+func (userServiceMock) UnsetPlatformAdmin(model *user.User) restErrors.IRestErr {
+	return usersUnsetPlatformAdminFunc(model)
+}
+func (userServiceMock) Search(email string) ([]*user.User, restErrors.IRestErr) {
+	return usersSearchFunc(email)
+}
+func (userServiceMock) Disable(model *user.User) restErrors.IRestErr {
+	return usersDisableFunc(model)
+}
+func (userServiceMock) Enable(model *user.User) restErrors.IRestErr {
+	return usersEnableFunc(model)
+}
+func (userServiceMock) ResetTwoFactorAuth(model *user.User) restErrors.IRestErr {
+	return usersResetTwoFactorAuthFunc(model)
+}
+func (userServiceMock) CheckPasswordPolicy(model *user.User, password string) restErrors.IRestErr {
+	return usersCheckPasswordPolicyFunc(model, password)
+}
+func (userServiceMock) Reauthenticate(model *user.User, dto *user.DeleteAccountRequestDto) restErrors.IRestErr {
+	return usersReauthenticateFunc(model, dto)
+}
+func (userServiceMock) Delete(model *user.User) restErrors.IRestErr {
+	return usersDeleteFunc(model)
+}
 
 /*
 Verification service Mocks
@@ -126,6 +166,7 @@ var (
 	ResendFunc                      func(userId string) (string, restErrors.IRestErr)
 	VerifyFunc                      func(userId string, token string) restErrors.IRestErr
 	VerificationWithTransactionFunc func(txHandle *gorm.DB) verification.IService
+	VerificationDeleteByUserIdFunc  func(userId string) restErrors.IRestErr
 )
 
 type verificationServiceMock struct{}
@@ -154,48 +195,71 @@ func (vService verificationServiceMock) Resend(userId string) (string, restError
 	return ResendFunc(userId)
 }
 
+func (verificationServiceMock) DeleteByUserId(userId string) restErrors.IRestErr {
+	return VerificationDeleteByUserIdFunc(userId)
+}
+
 // Mail Service mocks
 var (
-	SignUpMailFunc              func(dto *sendgrid.MailRequestDto) restErrors.IRestErr
-	ResendEmailVerificationFunc func(dto *sendgrid.MailRequestDto) restErrors.IRestErr
-	ForgetPasswordMailFunc      func(dto *sendgrid.MailRequestDto) restErrors.IRestErr
-	WorkspaceInvitationFunc     func(dto *sendgrid.WorkspaceInvitationMailRequestDto) restErrors.IRestErr
-	PingFunc                    func() restErrors.IRestErr
+	SignUpMailFunc               func(dto *mailer.MailRequestDto) restErrors.IRestErr
+	ResendEmailVerificationFunc  func(dto *mailer.MailRequestDto) restErrors.IRestErr
+	ForgetPasswordMailFunc       func(dto *mailer.MailRequestDto) restErrors.IRestErr
+	WorkspaceInvitationFunc      func(dto *mailer.WorkspaceInvitationMailRequestDto) restErrors.IRestErr
+	PingFunc                     func() restErrors.IRestErr
+	AccountLockedMailFunc        func(dto *mailer.AccountLockedMailRequestDto) restErrors.IRestErr
+	WorkspaceTransferredMailFunc func(dto *mailer.WorkspaceOwnershipMailRequestDto) restErrors.IRestErr
 )
 
 type mailServiceMock struct{}
 
-func (mailServiceMock) SignUp(dto *sendgrid.MailRequestDto) restErrors.IRestErr {
+func (mailServiceMock) SignUp(dto *mailer.MailRequestDto) restErrors.IRestErr {
 	return SignUpMailFunc(dto)
 }
-func (mailServiceMock) ResendEmailVerification(dto *sendgrid.MailRequestDto) restErrors.IRestErr {
+func (mailServiceMock) ResendEmailVerification(dto *mailer.MailRequestDto) restErrors.IRestErr {
 	return ResendEmailVerificationFunc(dto)
 }
-func (mailServiceMock) ForgetPassword(dto *sendgrid.MailRequestDto) restErrors.IRestErr {
+func (mailServiceMock) ForgetPassword(dto *mailer.MailRequestDto) restErrors.IRestErr {
 	return ForgetPasswordMailFunc(dto)
 }
-func (mailServiceMock) WorkspaceInvitation(dto *sendgrid.WorkspaceInvitationMailRequestDto) restErrors.IRestErr {
+func (mailServiceMock) WorkspaceInvitation(dto *mailer.WorkspaceInvitationMailRequestDto) restErrors.IRestErr {
 	return WorkspaceInvitationFunc(dto)
 }
 func (m mailServiceMock) Ping() restErrors.IRestErr {
 	return PingFunc()
 }
+func (mailServiceMock) AccountLocked(dto *mailer.AccountLockedMailRequestDto) restErrors.IRestErr {
+	return AccountLockedMailFunc(dto)
+}
+func (mailServiceMock) WorkspaceTransferred(dto *mailer.WorkspaceOwnershipMailRequestDto) restErrors.IRestErr {
+	return WorkspaceTransferredMailFunc(dto)
+}
+
+func (mailServiceMock) Test(dto *mailer.MailRequestDto) restErrors.IRestErr {
+	return nil
+}
+
+func (mailServiceMock) Verify(settings *setting.MailSettingsDto) restErrors.IRestErr {
+	return nil
+}
 
 /*
 Workspace service Mocks
 */
 var (
-	WorkspaceWithTransaction  func(txHandle *gorm.DB) workspace.IService
-	CreateWorkspaceFunc       func(dto *workspace.CreateWorkspaceRequestDto, userId string, k8NamespaceName string) (*workspace.Workspace, restErrors.IRestErr)
-	UpdateWorkspaceFunc       func(dto *workspace.UpdateWorkspaceRequestDto, workspace *workspace.Workspace) restErrors.IRestErr
-	DeleteWorkspace           func(workspace *workspace.Workspace) restErrors.IRestErr
-	GetWorkspaceByIdFunc      func(Id string) (*workspace.Workspace, restErrors.IRestErr)
-	GetWorkspacesByUserIdFunc func(userId string) ([]*workspace.Workspace, restErrors.IRestErr)
-	addWorkspaceMemberFunc    func(workspace *workspace.Workspace, memberId string, role string) restErrors.IRestErr
-	DeleteWorkspaceMemberFunc func(workspace *workspace.Workspace, memberId string) restErrors.IRestErr
-	CountByUserIdFunc         func(userId string) (int64, restErrors.IRestErr)
-	UpdateWorkspaceUserFunc   func(workspaceUser *workspaceuser.WorkspaceUser, dto *workspace.UpdateWorkspaceUserRequestDto) restErrors.IRestErr
-	GetWorkspaceByNamespace   func(namespace string) (*workspace.Workspace, restErrors.IRestErr)
+	WorkspaceWithTransaction          func(txHandle *gorm.DB) workspace.IService
+	CreateWorkspaceFunc               func(dto *workspace.CreateWorkspaceRequestDto, userId string, k8NamespaceName string) (*workspace.Workspace, restErrors.IRestErr)
+	UpdateWorkspaceFunc               func(dto *workspace.UpdateWorkspaceRequestDto, workspace *workspace.Workspace) restErrors.IRestErr
+	DeleteWorkspace                   func(workspace *workspace.Workspace) restErrors.IRestErr
+	GetWorkspaceByIdFunc              func(Id string) (*workspace.Workspace, restErrors.IRestErr)
+	GetWorkspacesByUserIdFunc         func(userId string) ([]*workspace.Workspace, restErrors.IRestErr)
+	addWorkspaceMemberFunc            func(workspace *workspace.Workspace, memberId string, role string) restErrors.IRestErr
+	DeleteWorkspaceMemberFunc         func(workspace *workspace.Workspace, memberId string) restErrors.IRestErr
+	CountByUserIdFunc                 func(userId string) (int64, restErrors.IRestErr)
+	UpdateWorkspaceUserFunc           func(workspaceUser *workspaceuser.WorkspaceUser, dto *workspace.UpdateWorkspaceUserRequestDto) restErrors.IRestErr
+	GetWorkspaceByNamespace           func(namespace string) (*workspace.Workspace, restErrors.IRestErr)
+	SearchWorkspaceFunc               func(name string) ([]*workspace.Workspace, restErrors.IRestErr)
+	UpdateWorkspaceSecretProviderFunc func(workspace *workspace.Workspace, provider string) restErrors.IRestErr
+	TransferWorkspaceOwnershipFunc    func(workspace *workspace.Workspace, newOwnerId string) restErrors.IRestErr
 )
 
 type workspaceServiceMock struct{}
@@ -244,19 +308,30 @@ func (workspaceServiceMock) UpdateWorkspaceUser(workspaceUser *workspaceuser.Wor
 func (workspaceServiceMock) GetByNamespace(namespace string) (*workspace.Workspace, restErrors.IRestErr) {
 	return GetWorkspaceByNamespace(namespace)
 }
+func (workspaceServiceMock) Search(name string) ([]*workspace.Workspace, restErrors.IRestErr) {
+	return SearchWorkspaceFunc(name)
+}
+func (workspaceServiceMock) TransferOwnership(workspace *workspace.Workspace, newOwnerId string) restErrors.IRestErr {
+	return TransferWorkspaceOwnershipFunc(workspace, newOwnerId)
+}
+func (workspaceServiceMock) UpdateSecretProvider(workspace *workspace.Workspace, provider string) restErrors.IRestErr {
+	return UpdateWorkspaceSecretProviderFunc(workspace, provider)
+}
 
 /*
 setting service  mocks
 */
 var (
-	settingSettingsFunc               func() ([]*setting.Setting, restErrors.IRestErr)
-	settingConfigureDomainFunc        func(dto *setting.ConfigureDomainRequestDto) restErrors.IRestErr
-	settingIsDomainConfiguredFunc     func() bool
-	settingConfigureRegistrationFunc  func(dto *setting.ConfigureRegistrationRequestDto) restErrors.IRestErr
-	settingGetDomainFunc              func() (string, restErrors.IRestErr)
-	settingIsRegistrationEnabledFunc  func() bool
-	settingConfigureActivationKeyFunc func(key string) restErrors.IRestErr
-	settingGetActivationKey           func() (string, restErrors.IRestErr)
+	settingSettingsFunc                func() ([]*setting.Setting, restErrors.IRestErr)
+	settingConfigureDomainFunc         func(dto *setting.ConfigureDomainRequestDto) restErrors.IRestErr
+	settingIsDomainConfiguredFunc      func() bool
+	settingConfigureRegistrationFunc   func(dto *setting.ConfigureRegistrationRequestDto) restErrors.IRestErr
+	settingGetDomainFunc               func() (string, restErrors.IRestErr)
+	settingIsRegistrationEnabledFunc   func() bool
+	settingConfigurePasswordPolicyFunc func(dto *setting.PasswordPolicyDto) restErrors.IRestErr
+	settingGetPasswordPolicyFunc       func() (*setting.PasswordPolicyDto, restErrors.IRestErr)
+	settingConfigureActivationKeyFunc  func(key string) restErrors.IRestErr
+	settingGetActivationKey            func() (string, restErrors.IRestErr)
 )
 
 type settingServiceMocks struct{}
@@ -281,6 +356,42 @@ func (s settingServiceMocks) IsRegistrationEnabled() bool {
 	return settingIsRegistrationEnabledFunc()
 }
 
+func (s settingServiceMocks) ConfigurePasswordPolicy(dto *setting.PasswordPolicyDto) restErrors.IRestErr {
+	return settingConfigurePasswordPolicyFunc(dto)
+}
+
+func (s settingServiceMocks) GetPasswordPolicy() (*setting.PasswordPolicyDto, restErrors.IRestErr) {
+	return settingGetPasswordPolicyFunc()
+}
+
+func (s settingServiceMocks) ConfigureMail(dto *setting.MailSettingsDto) restErrors.IRestErr {
+	return nil
+}
+
+func (s settingServiceMocks) GetMailSettings() (*setting.MailSettingsDto, restErrors.IRestErr) {
+	return setting.DefaultMailSettings(), nil
+}
+
+func (s settingServiceMocks) ConfigureMailTemplate(name string, dto *setting.MailTemplateDto) restErrors.IRestErr {
+	return nil
+}
+
+func (s settingServiceMocks) GetMailTemplate(name string) (*setting.MailTemplateDto, restErrors.IRestErr) {
+	return nil, restErrors.NewNotFoundError("not found")
+}
+
+func (s settingServiceMocks) DeleteMailTemplate(name string) restErrors.IRestErr {
+	return nil
+}
+
+func (s settingServiceMocks) ConfigureCheckpointSyncProviders(dto *setting.CheckpointSyncProvidersDto) restErrors.IRestErr {
+	return nil
+}
+
+func (s settingServiceMocks) GetCheckpointSyncProviders() (*setting.CheckpointSyncProvidersDto, restErrors.IRestErr) {
+	return setting.DefaultCheckpointSyncProviders(), nil
+}
+
 func (s settingServiceMocks) WithoutTransaction() setting.IService {
 	return s
 }
@@ -325,6 +436,43 @@ func (namespaceServiceMock) Delete(name string) restErrors.IRestErr {
 	return namespaceDeleteNamespaceFunc(name)
 }
 
+/*
+Workspace role service Mocks
+*/
+var (
+	DeleteWorkspaceRolesByWorkspaceIdFunc func(workspaceId string) restErrors.IRestErr
+)
+
+type workspaceRoleServiceMock struct{}
+
+func (r workspaceRoleServiceMock) WithTransaction(txHandle *gorm.DB) workspacerole.IService {
+	return r
+}
+func (r workspaceRoleServiceMock) WithoutTransaction() workspacerole.IService {
+	return r
+}
+func (workspaceRoleServiceMock) Create(dto *workspacerole.CreateWorkspaceRoleRequestDto, workspaceId string) (*workspacerole.WorkspaceRole, restErrors.IRestErr) {
+	return nil, nil
+}
+func (workspaceRoleServiceMock) Update(dto *workspacerole.UpdateWorkspaceRoleRequestDto, role *workspacerole.WorkspaceRole) restErrors.IRestErr {
+	return nil
+}
+func (workspaceRoleServiceMock) Delete(role *workspacerole.WorkspaceRole) restErrors.IRestErr {
+	return nil
+}
+func (workspaceRoleServiceMock) GetById(id string, workspaceId string) (*workspacerole.WorkspaceRole, restErrors.IRestErr) {
+	return nil, nil
+}
+func (workspaceRoleServiceMock) GetByWorkspaceId(workspaceId string) ([]*workspacerole.WorkspaceRole, restErrors.IRestErr) {
+	return nil, nil
+}
+func (workspaceRoleServiceMock) DeleteByWorkspaceId(workspaceId string) restErrors.IRestErr {
+	return DeleteWorkspaceRolesByWorkspaceIdFunc(workspaceId)
+}
+func (workspaceRoleServiceMock) Permissions(workspaceId string, role string) ([]string, restErrors.IRestErr) {
+	return nil, nil
+}
+
 func TestMain(m *testing.M) {
 	userService = &userServiceMock{}
 	verificationService = &verificationServiceMock{}
@@ -332,6 +480,11 @@ func TestMain(m *testing.M) {
 	workspaceService = &workspaceServiceMock{}
 	settingService = &settingServiceMocks{}
 	namespaceService = &namespaceServiceMock{}
+	workspaceRoleService = &workspaceRoleServiceMock{}
+
+	usersCheckPasswordPolicyFunc = func(model *user.User, password string) restErrors.IRestErr {
+		return nil
+	}
 
 	sqlclient.OpenDBConnection()
 
@@ -400,7 +553,7 @@ func TestSignUp(t *testing.T) {
 			return 1, nil
 		}
 
-		SignUpMailFunc = func(dto *sendgrid.MailRequestDto) restErrors.IRestErr {
+		SignUpMailFunc = func(dto *mailer.MailRequestDto) restErrors.IRestErr {
 			return nil
 		}
 
@@ -685,6 +838,34 @@ func TestSignUp(t *testing.T) {
 		assert.EqualValues(t, "can't configure registration", result.Message)
 	})
 
+	t.Run("Sign_Up_Should_Throw_if_password_violates_password_policy", func(t *testing.T) {
+		GetByEmailFunc = func(email string) (*user.User, restErrors.IRestErr) {
+			return nil, nil
+		}
+		settingIsRegistrationEnabledFunc = func() bool {
+			return true
+		}
+		usersCheckPasswordPolicyFunc = func(model *user.User, password string) restErrors.IRestErr {
+			return restErrors.NewValidationError(map[string]string{"password.breached": "password has appeared in a data breach, please choose a different password"})
+		}
+		defer func() {
+			usersCheckPasswordPolicyFunc = func(model *user.User, password string) restErrors.IRestErr {
+				return nil
+			}
+		}()
+
+		body, resp := newFiberCtx(validDto, SignUp, map[string]interface{}{})
+
+		var result restErrors.RestErr
+		err := json.Unmarshal(body, &result)
+		if err != nil {
+			panic(err.Error())
+		}
+
+		assert.EqualValues(t, http.StatusBadRequest, resp.StatusCode)
+		assert.Contains(t, result.Validations["password.breached"], "data breach")
+	})
+
 	t.Run("Sign_Up_Should_Throw_if_create_user_Throws", func(t *testing.T) {
 		GetByEmailFunc = func(email string) (*user.User, restErrors.IRestErr) {
 			return nil, nil
@@ -769,7 +950,7 @@ func TestSignUp(t *testing.T) {
 			return 1, nil
 		}
 
-		SignUpMailFunc = func(dto *sendgrid.MailRequestDto) restErrors.IRestErr {
+		SignUpMailFunc = func(dto *mailer.MailRequestDto) restErrors.IRestErr {
 			return nil
 		}
 
@@ -1030,6 +1211,17 @@ func TestSignIn(t *testing.T) {
 		assert.EqualValues(t, http.StatusBadRequest, resp.StatusCode)
 		assert.Equal(t, "error from service", result.Message)
 	})
+
+	t.Run("Sing_In_Should_set_retry_after_if_account_is_throttled", func(t *testing.T) {
+		SignInFunc = func(dto *user.SignInRequestDto) (*user.UserSessionResponseDto, restErrors.IRestErr) {
+			return nil, restErrors.NewTooManyRequestsError("too many failed attempts, try again later", 30*time.Second)
+		}
+
+		_, resp := newFiberCtx(validDto, SignIn, map[string]interface{}{})
+
+		assert.EqualValues(t, http.StatusTooManyRequests, resp.StatusCode)
+		assert.EqualValues(t, "30", resp.Header.Get(fiber.HeaderRetryAfter))
+	})
 }
 
 func TestSendEmailVerification(t *testing.T) {
@@ -1045,7 +1237,7 @@ func TestSendEmailVerification(t *testing.T) {
 			return "token", nil
 		}
 
-		ResendEmailVerificationFunc = func(dto *sendgrid.MailRequestDto) restErrors.IRestErr {
+		ResendEmailVerificationFunc = func(dto *mailer.MailRequestDto) restErrors.IRestErr {
 			return nil
 		}
 
@@ -1173,7 +1365,7 @@ func TestForgetPassword(t *testing.T) {
 		ResendFunc = func(userId string) (string, restErrors.IRestErr) {
 			return "token", nil
 		}
-		ForgetPasswordMailFunc = func(dto *sendgrid.MailRequestDto) restErrors.IRestErr {
+		ForgetPasswordMailFunc = func(dto *mailer.MailRequestDto) restErrors.IRestErr {
 			return nil
 		}
 		body, resp := newFiberCtx(validDto, ForgetPassword, map[string]interface{}{})
@@ -1573,7 +1765,7 @@ func TestChangeEmail(t *testing.T) {
 		ResendFunc = func(userId string) (string, restErrors.IRestErr) {
 			return "token", nil
 		}
-		ResendEmailVerificationFunc = func(dto *sendgrid.MailRequestDto) restErrors.IRestErr {
+		ResendEmailVerificationFunc = func(dto *mailer.MailRequestDto) restErrors.IRestErr {
 			return nil
 		}
 		GetByIdFunc = func(Id string) (*user.User, restErrors.IRestErr) {
@@ -2097,3 +2289,128 @@ func TestDisableTwoFactorAuth(t *testing.T) {
 	})
 
 }
+
+func TestDeleteAccount(t *testing.T) {
+	userDetails := new(token.UserDetails)
+	userDetails.ID = "1"
+	var locals = map[string]interface{}{}
+	locals["user"] = *userDetails
+
+	dto := new(user.DeleteAccountRequestDto)
+	dto.Password = "123456"
+
+	GetByIdFunc = func(Id string) (*user.User, restErrors.IRestErr) {
+		model := new(user.User)
+		model.ID = Id
+		return model, nil
+	}
+	usersReauthenticateFunc = func(model *user.User, dto *user.DeleteAccountRequestDto) restErrors.IRestErr {
+		return nil
+	}
+	usersDeleteFunc = func(model *user.User) restErrors.IRestErr {
+		return nil
+	}
+	VerificationDeleteByUserIdFunc = func(userId string) restErrors.IRestErr {
+		return nil
+	}
+	DeleteWorkspaceMemberFunc = func(workspace *workspace.Workspace, memberId string) restErrors.IRestErr {
+		return nil
+	}
+	DeleteWorkspace = func(workspace *workspace.Workspace) restErrors.IRestErr {
+		return nil
+	}
+	DeleteWorkspaceRolesByWorkspaceIdFunc = func(workspaceId string) restErrors.IRestErr {
+		return nil
+	}
+	namespaceDeleteNamespaceFunc = func(name string) restErrors.IRestErr {
+		return nil
+	}
+	FindWhereIdInSliceFunc = func(ids []string) ([]*user.User, restErrors.IRestErr) {
+		return []*user.User{}, nil
+	}
+
+	t.Run("Delete_Account_Should_Transfer_Shared_Workspaces_And_Delete_Owned_Ones", func(t *testing.T) {
+		shared := &workspace.Workspace{ID: "1", UserId: "1", K8sNamespace: "shared", WorkspaceUsers: []workspaceuser.WorkspaceUser{
+			{UserId: "1", Role: roles.Admin},
+			{UserId: "2", Role: roles.Reader},
+			{UserId: "3", Role: roles.Admin},
+		}}
+		owned := &workspace.Workspace{ID: "2", UserId: "1", K8sNamespace: "owned", WorkspaceUsers: []workspaceuser.WorkspaceUser{{UserId: "1", Role: roles.Admin}}}
+		membership := &workspace.Workspace{ID: "3", UserId: "4"}
+
+		GetWorkspacesByUserIdFunc = func(userId string) ([]*workspace.Workspace, restErrors.IRestErr) {
+			return []*workspace.Workspace{shared, owned, membership}, nil
+		}
+		GetWorkspaceByIdFunc = func(Id string) (*workspace.Workspace, restErrors.IRestErr) {
+			if Id == shared.ID {
+				return shared, nil
+			}
+			return owned, nil
+		}
+		newOwnerId := ""
+		TransferWorkspaceOwnershipFunc = func(workspace *workspace.Workspace, newOwner string) restErrors.IRestErr {
+			newOwnerId = newOwner
+			return nil
+		}
+		deletedNamespaces := make([]string, 0)
+		namespaceDeleteNamespaceFunc = func(name string) restErrors.IRestErr {
+			deletedNamespaces = append(deletedNamespaces, name)
+			return nil
+		}
+
+		_, resp := newFiberCtx(dto, DeleteAccount, locals)
+
+		assert.EqualValues(t, http.StatusNoContent, resp.StatusCode)
+		assert.EqualValues(t, "3", newOwnerId)
+		assert.EqualValues(t, []string{"owned"}, deletedNamespaces)
+	})
+
+	t.Run("Delete_Account_Should_Throw_If_Default_Workspace_Has_No_Other_Members", func(t *testing.T) {
+		defaultWorkspace := &workspace.Workspace{ID: "1", UserId: "1", K8sNamespace: "default", WorkspaceUsers: []workspaceuser.WorkspaceUser{{UserId: "1", Role: roles.Admin}}}
+		GetWorkspacesByUserIdFunc = func(userId string) ([]*workspace.Workspace, restErrors.IRestErr) {
+			return []*workspace.Workspace{defaultWorkspace}, nil
+		}
+		GetWorkspaceByIdFunc = func(Id string) (*workspace.Workspace, restErrors.IRestErr) {
+			return defaultWorkspace, nil
+		}
+
+		body, resp := newFiberCtx(dto, DeleteAccount, locals)
+		var result restErrors.RestErr
+		err := json.Unmarshal(body, &result)
+		if err != nil {
+			panic(err.Error())
+		}
+
+		assert.EqualValues(t, http.StatusBadRequest, resp.StatusCode)
+		assert.EqualValues(t, "the default workspace can't be deleted, add another member to it before deleting your account", result.Message)
+	})
+
+	t.Run("Delete_Account_Should_Throw_If_Reauthentication_Fails", func(t *testing.T) {
+		usersReauthenticateFunc = func(model *user.User, dto *user.DeleteAccountRequestDto) restErrors.IRestErr {
+			return restErrors.NewBadRequestError("Invalid password")
+		}
+
+		body, resp := newFiberCtx(dto, DeleteAccount, locals)
+		var result restErrors.RestErr
+		err := json.Unmarshal(body, &result)
+		if err != nil {
+			panic(err.Error())
+		}
+
+		assert.EqualValues(t, http.StatusBadRequest, resp.StatusCode)
+		assert.EqualValues(t, "Invalid password", result.Message)
+	})
+
+	t.Run("Delete_Account_Should_Throw_Validation_Error", func(t *testing.T) {
+		body, resp := newFiberCtx(new(user.DeleteAccountRequestDto), DeleteAccount, locals)
+		var result restErrors.RestErr
+		err := json.Unmarshal(body, &result)
+		if err != nil {
+			panic(err.Error())
+		}
+
+		fields := map[string]string{"password": "password should be at least 6 chars"}
+		assert.EqualValues(t, http.StatusBadRequest, resp.StatusCode)
+		assert.Equal(t, restErrors.NewValidationError(fields), result)
+	})
+}
@@ -1,26 +1,31 @@
 package workspace
 
 import (
+	"fmt"
 	"github.com/gofiber/fiber/v2"
 	"github.com/google/uuid"
+	"github.com/kotalco/core-api/core/secret"
 	"github.com/kotalco/core-api/core/user"
 	"github.com/kotalco/core-api/core/workspace"
+	"github.com/kotalco/core-api/core/workspacerole"
 	"github.com/kotalco/core-api/core/workspaceuser"
 	"github.com/kotalco/core-api/k8s"
 	restErrors "github.com/kotalco/core-api/pkg/errors"
+	"github.com/kotalco/core-api/pkg/logger"
+	"github.com/kotalco/core-api/pkg/mailer"
 	"github.com/kotalco/core-api/pkg/responder"
 	"github.com/kotalco/core-api/pkg/roles"
-	"github.com/kotalco/core-api/pkg/sendgrid"
 	"github.com/kotalco/core-api/pkg/sqlclient"
 	"github.com/kotalco/core-api/pkg/token"
 	"net/http"
 )
 
 var (
-	workspaceService = workspace.NewService()
-	namespaceService = k8s.NewNamespaceService()
-	userService      = user.NewService()
-	mailService      = sendgrid.NewService()
+	workspaceService     = workspace.NewService()
+	namespaceService     = k8s.NewNamespaceService()
+	userService          = user.NewService()
+	mailService          = mailer.NewService()
+	workspaceRoleService = workspacerole.NewService()
 )
 
 // Create validate dto , create new workspace, creates new namespace in k8
@@ -108,6 +113,12 @@ func Delete(c *fiber.Ctx) error {
 		return c.Status(err.StatusCode()).JSON(err)
 	}
 
+	err = workspaceRoleService.WithTransaction(txHandle).DeleteByWorkspaceId(model.ID)
+	if err != nil {
+		sqlclient.Rollback(txHandle)
+		return c.Status(err.StatusCode()).JSON(err)
+	}
+
 	err = namespaceService.Delete(model.K8sNamespace)
 	if err != nil && err.StatusCode() != http.StatusNotFound {
 		sqlclient.Rollback(txHandle)
@@ -147,6 +158,36 @@ func GetById(c *fiber.Ctx) error {
 	return c.Status(http.StatusOK).JSON(responder.NewResponse(marshalled))
 }
 
+// UpdateSecretProvider sets the workspace default secret provider
+// 1-validate the provider is supported and configured
+// 2-call workspace service to save the provider
+func UpdateSecretProvider(c *fiber.Ctx) error {
+	model := c.Locals("workspace").(workspace.Workspace)
+
+	dto := new(workspace.UpdateSecretProviderRequestDto)
+	if err := c.BodyParser(dto); err != nil {
+		badReq := restErrors.NewBadRequestError("invalid request body")
+		return c.Status(badReq.StatusCode()).JSON(badReq)
+	}
+
+	err := workspace.Validate(dto)
+	if err != nil {
+		return c.Status(err.StatusCode()).JSON(err)
+	}
+
+	if !secret.ProviderAvailable(dto.SecretProvider) {
+		badReq := restErrors.NewBadRequestError(fmt.Sprintf("secret provider %s isn't configured", dto.SecretProvider))
+		return c.Status(badReq.StatusCode()).JSON(badReq)
+	}
+
+	err = workspaceService.WithoutTransaction().UpdateSecretProvider(&model, dto.SecretProvider)
+	if err != nil {
+		return c.Status(err.StatusCode()).JSON(err)
+	}
+
+	return c.Status(http.StatusOK).JSON(responder.NewResponse(new(workspace.WorkspaceResponseDto).Marshall(&model)))
+}
+
 // AddMember adds new member to workspace
 func AddMember(c *fiber.Ctx) error {
 	dto := new(workspace.AddWorkspaceMemberDto)
@@ -160,12 +201,17 @@ func AddMember(c *fiber.Ctx) error {
 		return c.Status(err.StatusCode()).JSON(err)
 	}
 
-	member, err := userService.WithoutTransaction().GetByEmail(dto.Email)
+	model := c.Locals("workspace").(workspace.Workspace)
+
+	err = validateRole(c, model.ID, dto.Role)
 	if err != nil {
 		return c.Status(err.StatusCode()).JSON(err)
 	}
 
-	model := c.Locals("workspace").(workspace.Workspace)
+	member, err := userService.WithoutTransaction().GetByEmail(dto.Email)
+	if err != nil {
+		return c.Status(err.StatusCode()).JSON(err)
+	}
 
 	for _, v := range model.WorkspaceUsers {
 		if v.UserId == member.ID {
@@ -183,7 +229,7 @@ func AddMember(c *fiber.Ctx) error {
 
 	sqlclient.Commit(txHandle)
 
-	mailRequestDto := new(sendgrid.WorkspaceInvitationMailRequestDto)
+	mailRequestDto := new(mailer.WorkspaceInvitationMailRequestDto)
 	mailRequestDto.Email = dto.Email
 	mailRequestDto.WorkspaceName = model.Name
 	mailRequestDto.WorkspaceId = model.ID
@@ -332,6 +378,10 @@ func UpdateWorkspaceUser(c *fiber.Ctx) error {
 			return c.Status(badReq.StatusCode()).JSON(badReq)
 		}
 
+		err = validateRole(c, model.ID, dto.Role)
+		if err != nil {
+			return c.Status(err.StatusCode()).JSON(err)
+		}
 	}
 
 	txHandle := sqlclient.Begin()
@@ -348,6 +398,104 @@ func UpdateWorkspaceUser(c *fiber.Ctx) error {
 	}))
 }
 
+// TransferOwnership transfers the workspace ownership to another admin member, only the workspace owner can transfer it
+func TransferOwnership(c *fiber.Ctx) error {
+	model := c.Locals("workspace").(workspace.Workspace)
+	userId := c.Locals("user").(token.UserDetails).ID
+
+	if model.UserId != userId {
+		forbidden := restErrors.NewForbiddenError("only the workspace owner can transfer its ownership")
+		return c.Status(forbidden.StatusCode()).JSON(forbidden)
+	}
+
+	dto := new(workspace.TransferOwnershipRequestDto)
+	if err := c.BodyParser(dto); err != nil {
+		badReq := restErrors.NewBadRequestError("invalid request body")
+		return c.Status(badReq.StatusCode()).JSON(badReq)
+	}
+
+	err := workspace.Validate(dto)
+	if err != nil {
+		return c.Status(err.StatusCode()).JSON(err)
+	}
+
+	var member *workspaceuser.WorkspaceUser
+	for _, v := range model.WorkspaceUsers {
+		if v.UserId == dto.UserId {
+			member = &v
+			break
+		}
+	}
+	if member == nil {
+		notFoundErr := restErrors.NewNotFoundError("user isn't a member of the workspace")
+		return c.Status(notFoundErr.StatusCode()).JSON(notFoundErr)
+	}
+	if member.Role != roles.Admin {
+		badReq := restErrors.NewBadRequestError("workspace ownership can only be transferred to an admin member")
+		return c.Status(badReq.StatusCode()).JSON(badReq)
+	}
+
+	newOwner, err := userService.WithoutTransaction().GetById(dto.UserId)
+	if err != nil {
+		return c.Status(err.StatusCode()).JSON(err)
+	}
+
+	txHandle := sqlclient.Begin()
+	err = workspaceService.WithTransaction(txHandle).TransferOwnership(&model, newOwner.ID)
+	if err != nil {
+		sqlclient.Rollback(txHandle)
+		return c.Status(err.StatusCode()).JSON(err)
+	}
+	sqlclient.Commit(txHandle)
+
+	go notifyMembers(&model, userId, newOwner.Email)
+
+	return c.Status(http.StatusOK).JSON(responder.NewResponse(new(workspace.WorkspaceResponseDto).Marshall(&model)))
+}
+
+// notifyMembers emails the workspace members, except the previous owner, with the workspace new owner
+func notifyMembers(model *workspace.Workspace, previousOwnerId string, newOwnerEmail string) {
+	userIds := make([]string, 0)
+	for _, v := range model.WorkspaceUsers {
+		if v.UserId != previousOwnerId {
+			userIds = append(userIds, v.UserId)
+		}
+	}
+
+	members, err := userService.WithoutTransaction().FindWhereIdInSlice(userIds)
+	if err != nil {
+		go logger.Error("NOTIFY_WORKSPACE_MEMBERS", err)
+		return
+	}
+
+	for _, v := range members {
+		mailRequest := new(mailer.WorkspaceOwnershipMailRequestDto)
+		mailRequest.Email = v.Email
+		mailRequest.WorkspaceName = model.Name
+		mailRequest.NewOwnerEmail = newOwnerEmail
+		mailService.WorkspaceTransferred(mailRequest)
+	}
+}
+
+// validateRole checks the role is built-in or defined by the workspace
+// and that the member assigning it already has all the role permissions, so members can't escalate their privileges
+func validateRole(c *fiber.Ctx, workspaceId string, role string) restErrors.IRestErr {
+	rolePermissions, err := workspaceRoleService.WithoutTransaction().Permissions(workspaceId, role)
+	if err != nil {
+		if err.StatusCode() == http.StatusNotFound {
+			return restErrors.NewValidationError(map[string]string{"role": "invalid role"})
+		}
+		return err
+	}
+
+	permissions, _ := c.Locals("permissions").([]string)
+	if !roles.Covers(permissions, rolePermissions) {
+		return restErrors.NewForbiddenError("you can't grant permissions you don't have")
+	}
+
+	return nil
+}
+
 // ValidateWorkspaceExist checks if the workspace_id (id) which passed as query param exist, creates workspace local
 func ValidateWorkspaceExist(c *fiber.Ctx) error {
 	workspaceId := c.Params("id")
@@ -5,13 +5,15 @@ import (
 	"encoding/json"
 	"github.com/gofiber/fiber/v2"
 	"github.com/google/uuid"
+	"github.com/kotalco/core-api/core/setting"
 	"github.com/kotalco/core-api/core/user"
 	"github.com/kotalco/core-api/core/workspace"
+	"github.com/kotalco/core-api/core/workspacerole"
 	"github.com/kotalco/core-api/core/workspaceuser"
 	restErrors "github.com/kotalco/core-api/pkg/errors"
+	"github.com/kotalco/core-api/pkg/mailer"
 	"github.com/kotalco/core-api/pkg/responder"
 	"github.com/kotalco/core-api/pkg/roles"
-	"github.com/kotalco/core-api/pkg/sendgrid"
 	"github.com/kotalco/core-api/pkg/sqlclient"
 	"github.com/kotalco/core-api/pkg/token"
 	"github.com/stretchr/testify/assert"
@@ -21,6 +23,7 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"os"
+	"strings"
 	"testing"
 )
 
@@ -28,22 +31,31 @@ import (
 User service Mocks
 */
 var (
-	UserWithTransactionFunc     func(txHandle *gorm.DB) user.IService
-	SignUpFunc                  func(dto *user.SignUpRequestDto) (*user.User, restErrors.IRestErr)
-	SignInFunc                  func(dto *user.SignInRequestDto) (*user.UserSessionResponseDto, restErrors.IRestErr)
-	VerifyTOTPFunc              func(model *user.User, totp string) (*user.UserSessionResponseDto, restErrors.IRestErr)
-	GetByEmailFunc              func(email string) (*user.User, restErrors.IRestErr)
-	GetByIdFunc                 func(Id string) (*user.User, restErrors.IRestErr)
-	VerifyEmailFunc             func(model *user.User) restErrors.IRestErr
-	ResetPasswordFunc           func(model *user.User, password string) restErrors.IRestErr
-	ChangePasswordFunc          func(model *user.User, dto *user.ChangePasswordRequestDto) restErrors.IRestErr
-	ChangeEmailFunc             func(model *user.User, dto *user.ChangeEmailRequestDto) restErrors.IRestErr
-	CreateTOTPFunc              func(model *user.User, dto *user.CreateTOTPRequestDto) (bytes.Buffer, restErrors.IRestErr)
-	EnableTwoFactorAuthFunc     func(model *user.User, totp string) (*user.User, restErrors.IRestErr)
-	DisableTwoFactorAuthFunc    func(model *user.User, dto *user.DisableTOTPRequestDto) restErrors.IRestErr
-	FindWhereIdInSliceFunc      func(ids []string) ([]*user.User, restErrors.IRestErr)
-	usersCountFunc              func() (int64, restErrors.IRestErr)
-	usersSetAsPlatformAdminFunc func(model *user.User) restErrors.IRestErr
+	UserWithTransactionFunc      func(txHandle *gorm.DB) user.IService
+	SignUpFunc                   func(dto *user.SignUpRequestDto) (*user.User, restErrors.IRestErr)
+	SignInFunc                   func(dto *user.SignInRequestDto) (*user.UserSessionResponseDto, restErrors.IRestErr)
+	VerifyTOTPFunc               func(model *user.User, totp string) (*user.UserSessionResponseDto, restErrors.IRestErr)
+	GetByEmailFunc               func(email string) (*user.User, restErrors.IRestErr)
+	GetByIdFunc                  func(Id string) (*user.User, restErrors.IRestErr)
+	VerifyEmailFunc              func(model *user.User) restErrors.IRestErr
+	ResetPasswordFunc            func(model *user.User, password string) restErrors.IRestErr
+	InvalidatePasswordFunc       func(model *user.User) restErrors.IRestErr
+	ChangePasswordFunc           func(model *user.User, dto *user.ChangePasswordRequestDto) restErrors.IRestErr
+	ChangeEmailFunc              func(model *user.User, dto *user.ChangeEmailRequestDto) restErrors.IRestErr
+	CreateTOTPFunc               func(model *user.User, dto *user.CreateTOTPRequestDto) (bytes.Buffer, restErrors.IRestErr)
+	EnableTwoFactorAuthFunc      func(model *user.User, totp string) (*user.User, restErrors.IRestErr)
+	DisableTwoFactorAuthFunc     func(model *user.User, dto *user.DisableTOTPRequestDto) restErrors.IRestErr
+	FindWhereIdInSliceFunc       func(ids []string) ([]*user.User, restErrors.IRestErr)
+	usersCountFunc               func() (int64, restErrors.IRestErr)
+	usersSetAsPlatformAdminFunc  func(model *user.User) restErrors.IRestErr
+	usersUnsetPlatformAdminFunc  func(model *user.User) restErrors.IRestErr
+	usersSearchFunc              func(email string) ([]*user.User, restErrors.IRestErr)
+	usersDisableFunc             func(model *user.User) restErrors.IRestErr
+	usersEnableFunc              func(model *user.User) restErrors.IRestErr
+	usersResetTwoFactorAuthFunc  func(model *user.User) restErrors.IRestErr
+	usersCheckPasswordPolicyFunc func(model *user.User, password string) restErrors.IRestErr
+	usersReauthenticateFunc      func(model *user.User, dto *user.DeleteAccountRequestDto) restErrors.IRestErr
+	usersDeleteFunc              func(model *user.User) restErrors.IRestErr
 )
 
 type userServiceMock struct{}
@@ -80,6 +92,10 @@ func (userServiceMock) ResetPassword(model *user.User, password string) restErro
 	return ResetPasswordFunc(model, password)
 }
 
+func (userServiceMock) InvalidatePassword(model *user.User) restErrors.IRestErr {
+	return InvalidatePasswordFunc(model)
+}
+
 func (userServiceMock) ChangePassword(model *user.User, dto *user.ChangePasswordRequestDto) restErrors.IRestErr {
 	return ChangePasswordFunc(model, dto)
 }
@@ -113,22 +129,49 @@ func (uService userServiceMock) Count() (int64, restErrors.IRestErr) {
 func (uService userServiceMock) SetAsPlatformAdmin(model *user.User) restErrors.IRestErr {
 	return usersSetAsPlatformAdminFunc(model)
 }
+func (userServiceMock) UnsetPlatformAdmin(model *user.User) restErrors.IRestErr {
+	return usersUnsetPlatformAdminFunc(model)
+}
+func (userServiceMock) Search(email string) ([]*user.User, restErrors.IRestErr) {
+	return usersSearchFunc(email)
+}
+func (userServiceMock) Disable(model *user.User) restErrors.IRestErr {
+	return usersDisableFunc(model)
+}
+func (userServiceMock) Enable(model *user.User) restErrors.IRestErr {
+	return usersEnableFunc(model)
+}
+func (userServiceMock) ResetTwoFactorAuth(model *user.User) restErrors.IRestErr {
+	return usersResetTwoFactorAuthFunc(model)
+}
+func (userServiceMock) CheckPasswordPolicy(model *user.User, password string) restErrors.IRestErr {
+	return usersCheckPasswordPolicyFunc(model, password)
+}
+func (userServiceMock) Reauthenticate(model *user.User, dto *user.DeleteAccountRequestDto) restErrors.IRestErr {
+	return usersReauthenticateFunc(model, dto)
+}
+func (userServiceMock) Delete(model *user.User) restErrors.IRestErr {
+	return usersDeleteFunc(model)
+}
 
 /*
 Workspace service Mocks
 */
 var (
-	WorkspaceWithTransaction   func(txHandle *gorm.DB) workspace.IService
-	CreateWorkspaceFunc        func(dto *workspace.CreateWorkspaceRequestDto, userId string, k8NamespaceName string) (*workspace.Workspace, restErrors.IRestErr)
-	UpdateWorkspaceFunc        func(dto *workspace.UpdateWorkspaceRequestDto, workspace *workspace.Workspace) restErrors.IRestErr
-	GetWorkspaceByIdFunc       func(Id string) (*workspace.Workspace, restErrors.IRestErr)
-	DeleteWorkspaceFunc        func(workspace *workspace.Workspace) restErrors.IRestErr
-	GetWorkspaceByUserIdFunc   func(userId string) ([]*workspace.Workspace, restErrors.IRestErr)
-	AddWorkspaceMemberFunc     func(workspace *workspace.Workspace, memberId string, role string) restErrors.IRestErr
-	DeleteWorkspaceMemberFunc  func(workspace *workspace.Workspace, memberId string) restErrors.IRestErr
-	CountWorkspaceByUserIdFunc func(userId string) (int64, restErrors.IRestErr)
-	UpdateWorkspaceUserFunc    func(workspaceUser *workspaceuser.WorkspaceUser, dto *workspace.UpdateWorkspaceUserRequestDto) restErrors.IRestErr
-	GetWorkspaceByNamespace    func(namespace string) (*workspace.Workspace, restErrors.IRestErr)
+	WorkspaceWithTransaction          func(txHandle *gorm.DB) workspace.IService
+	CreateWorkspaceFunc               func(dto *workspace.CreateWorkspaceRequestDto, userId string, k8NamespaceName string) (*workspace.Workspace, restErrors.IRestErr)
+	UpdateWorkspaceFunc               func(dto *workspace.UpdateWorkspaceRequestDto, workspace *workspace.Workspace) restErrors.IRestErr
+	GetWorkspaceByIdFunc              func(Id string) (*workspace.Workspace, restErrors.IRestErr)
+	DeleteWorkspaceFunc               func(workspace *workspace.Workspace) restErrors.IRestErr
+	GetWorkspaceByUserIdFunc          func(userId string) ([]*workspace.Workspace, restErrors.IRestErr)
+	AddWorkspaceMemberFunc            func(workspace *workspace.Workspace, memberId string, role string) restErrors.IRestErr
+	DeleteWorkspaceMemberFunc         func(workspace *workspace.Workspace, memberId string) restErrors.IRestErr
+	CountWorkspaceByUserIdFunc        func(userId string) (int64, restErrors.IRestErr)
+	UpdateWorkspaceUserFunc           func(workspaceUser *workspaceuser.WorkspaceUser, dto *workspace.UpdateWorkspaceUserRequestDto) restErrors.IRestErr
+	GetWorkspaceByNamespace           func(namespace string) (*workspace.Workspace, restErrors.IRestErr)
+	SearchWorkspaceFunc               func(name string) ([]*workspace.Workspace, restErrors.IRestErr)
+	UpdateWorkspaceSecretProviderFunc func(workspace *workspace.Workspace, provider string) restErrors.IRestErr
+	TransferWorkspaceOwnershipFunc    func(workspace *workspace.Workspace, newOwnerId string) restErrors.IRestErr
 )
 
 type workspaceServiceMock struct{}
@@ -177,6 +220,58 @@ func (workspaceServiceMock) UpdateWorkspaceUser(workspaceUser *workspaceuser.Wor
 func (workspaceServiceMock) GetByNamespace(namespace string) (*workspace.Workspace, restErrors.IRestErr) {
 	return GetWorkspaceByNamespace(namespace)
 }
+func (workspaceServiceMock) Search(name string) ([]*workspace.Workspace, restErrors.IRestErr) {
+	return SearchWorkspaceFunc(name)
+}
+func (workspaceServiceMock) TransferOwnership(workspace *workspace.Workspace, newOwnerId string) restErrors.IRestErr {
+	return TransferWorkspaceOwnershipFunc(workspace, newOwnerId)
+}
+func (workspaceServiceMock) UpdateSecretProvider(workspace *workspace.Workspace, provider string) restErrors.IRestErr {
+	return UpdateWorkspaceSecretProviderFunc(workspace, provider)
+}
+
+/*
+Workspace role service Mocks
+*/
+var (
+	CreateWorkspaceRoleFunc               func(dto *workspacerole.CreateWorkspaceRoleRequestDto, workspaceId string) (*workspacerole.WorkspaceRole, restErrors.IRestErr)
+	UpdateWorkspaceRoleFunc               func(dto *workspacerole.UpdateWorkspaceRoleRequestDto, role *workspacerole.WorkspaceRole) restErrors.IRestErr
+	DeleteWorkspaceRoleFunc               func(role *workspacerole.WorkspaceRole) restErrors.IRestErr
+	GetWorkspaceRoleByIdFunc              func(id string, workspaceId string) (*workspacerole.WorkspaceRole, restErrors.IRestErr)
+	GetWorkspaceRolesByWorkspaceIdFunc    func(workspaceId string) ([]*workspacerole.WorkspaceRole, restErrors.IRestErr)
+	DeleteWorkspaceRolesByWorkspaceIdFunc func(workspaceId string) restErrors.IRestErr
+	WorkspaceRolePermissionsFunc          func(workspaceId string, role string) ([]string, restErrors.IRestErr)
+)
+
+type workspaceRoleServiceMock struct{}
+
+func (r workspaceRoleServiceMock) WithTransaction(txHandle *gorm.DB) workspacerole.IService {
+	return r
+}
+func (r workspaceRoleServiceMock) WithoutTransaction() workspacerole.IService {
+	return r
+}
+func (workspaceRoleServiceMock) Create(dto *workspacerole.CreateWorkspaceRoleRequestDto, workspaceId string) (*workspacerole.WorkspaceRole, restErrors.IRestErr) {
+	return CreateWorkspaceRoleFunc(dto, workspaceId)
+}
+func (workspaceRoleServiceMock) Update(dto *workspacerole.UpdateWorkspaceRoleRequestDto, role *workspacerole.WorkspaceRole) restErrors.IRestErr {
+	return UpdateWorkspaceRoleFunc(dto, role)
+}
+func (workspaceRoleServiceMock) Delete(role *workspacerole.WorkspaceRole) restErrors.IRestErr {
+	return DeleteWorkspaceRoleFunc(role)
+}
+func (workspaceRoleServiceMock) GetById(id string, workspaceId string) (*workspacerole.WorkspaceRole, restErrors.IRestErr) {
+	return GetWorkspaceRoleByIdFunc(id, workspaceId)
+}
+func (workspaceRoleServiceMock) GetByWorkspaceId(workspaceId string) ([]*workspacerole.WorkspaceRole, restErrors.IRestErr) {
+	return GetWorkspaceRolesByWorkspaceIdFunc(workspaceId)
+}
+func (workspaceRoleServiceMock) DeleteByWorkspaceId(workspaceId string) restErrors.IRestErr {
+	return DeleteWorkspaceRolesByWorkspaceIdFunc(workspaceId)
+}
+func (workspaceRoleServiceMock) Permissions(workspaceId string, role string) ([]string, restErrors.IRestErr) {
+	return WorkspaceRolePermissionsFunc(workspaceId, role)
+}
 
 /*
 Namespace service Mocks
@@ -204,30 +299,46 @@ func (namespaceServiceMock) Delete(name string) restErrors.IRestErr {
 
 // Mail Service mocks
 var (
-	SignUpMailFunc              func(dto *sendgrid.MailRequestDto) restErrors.IRestErr
-	ResendEmailVerificationFunc func(dto *sendgrid.MailRequestDto) restErrors.IRestErr
-	ForgetPasswordMailFunc      func(dto *sendgrid.MailRequestDto) restErrors.IRestErr
-	WorkspaceInvitationFunc     func(dto *sendgrid.WorkspaceInvitationMailRequestDto) restErrors.IRestErr
-	PingFunc                    func() restErrors.IRestErr
+	SignUpMailFunc               func(dto *mailer.MailRequestDto) restErrors.IRestErr
+	ResendEmailVerificationFunc  func(dto *mailer.MailRequestDto) restErrors.IRestErr
+	ForgetPasswordMailFunc       func(dto *mailer.MailRequestDto) restErrors.IRestErr
+	WorkspaceInvitationFunc      func(dto *mailer.WorkspaceInvitationMailRequestDto) restErrors.IRestErr
+	PingFunc                     func() restErrors.IRestErr
+	AccountLockedMailFunc        func(dto *mailer.AccountLockedMailRequestDto) restErrors.IRestErr
+	WorkspaceTransferredMailFunc func(dto *mailer.WorkspaceOwnershipMailRequestDto) restErrors.IRestErr
 )
 
 type mailServiceMock struct{}
 
-func (mailServiceMock) SignUp(dto *sendgrid.MailRequestDto) restErrors.IRestErr {
+func (mailServiceMock) SignUp(dto *mailer.MailRequestDto) restErrors.IRestErr {
 	return SignUpMailFunc(dto)
 }
-func (mailServiceMock) ResendEmailVerification(dto *sendgrid.MailRequestDto) restErrors.IRestErr {
+func (mailServiceMock) ResendEmailVerification(dto *mailer.MailRequestDto) restErrors.IRestErr {
 	return ResendEmailVerificationFunc(dto)
 }
-func (mailServiceMock) ForgetPassword(dto *sendgrid.MailRequestDto) restErrors.IRestErr {
+func (mailServiceMock) ForgetPassword(dto *mailer.MailRequestDto) restErrors.IRestErr {
 	return ForgetPasswordMailFunc(dto)
 }
-func (mailServiceMock) WorkspaceInvitation(dto *sendgrid.WorkspaceInvitationMailRequestDto) restErrors.IRestErr {
+func (mailServiceMock) WorkspaceInvitation(dto *mailer.WorkspaceInvitationMailRequestDto) restErrors.IRestErr {
 	return WorkspaceInvitationFunc(dto)
 }
 func (m mailServiceMock) Ping() restErrors.IRestErr {
 	return PingFunc()
 }
+func (mailServiceMock) AccountLocked(dto *mailer.AccountLockedMailRequestDto) restErrors.IRestErr {
+	return AccountLockedMailFunc(dto)
+}
+func (mailServiceMock) WorkspaceTransferred(dto *mailer.WorkspaceOwnershipMailRequestDto) restErrors.IRestErr {
+	return WorkspaceTransferredMailFunc(dto)
+}
+
+func (mailServiceMock) Test(dto *mailer.MailRequestDto) restErrors.IRestErr {
+	return nil
+}
+
+func (mailServiceMock) Verify(settings *setting.MailSettingsDto) restErrors.IRestErr {
+	return nil
+}
 
 func newFiberCtx(dto interface{}, method func(c *fiber.Ctx) error, locals map[string]interface{}) ([]byte, *http.Response) {
 	app := fiber.New()
@@ -263,6 +374,7 @@ func TestMain(m *testing.M) {
 	namespaceService = &namespaceServiceMock{}
 	mailService = &mailServiceMock{}
 	userService = &userServiceMock{}
+	workspaceRoleService = &workspaceRoleServiceMock{}
 	sqlclient.OpenDBConnection()
 
 	code := m.Run()
@@ -493,6 +605,10 @@ func TestDeleteWorkspace(t *testing.T) {
 	locals["user"] = *userDetails
 	locals["workspace"] = *workspaceModelLocals
 
+	DeleteWorkspaceRolesByWorkspaceIdFunc = func(workspaceId string) restErrors.IRestErr {
+		return nil
+	}
+
 	t.Run("Delete_Workspace_should_pass", func(t *testing.T) {
 
 		CountWorkspaceByUserIdFunc = func(userId string) (int64, restErrors.IRestErr) {
@@ -556,6 +672,32 @@ func TestDeleteWorkspace(t *testing.T) {
 		assert.Error(t, restErr, "something went wrong")
 	})
 
+	t.Run("Delete_Workspace_should_throw_if_workspace_role_service_throws", func(t *testing.T) {
+		CountWorkspaceByUserIdFunc = func(userId string) (int64, restErrors.IRestErr) {
+			return 2, nil
+		}
+		DeleteWorkspaceFunc = func(workspace *workspace.Workspace) restErrors.IRestErr {
+			return nil
+		}
+		DeleteWorkspaceRolesByWorkspaceIdFunc = func(workspaceId string) restErrors.IRestErr {
+			return restErrors.NewInternalServerError("something went wrong")
+		}
+
+		body, resp := newFiberCtx("", Delete, locals)
+
+		var restErr restErrors.RestErr
+		err := json.Unmarshal(body, &restErr)
+		if err != nil {
+			panic(err)
+		}
+		assert.EqualValues(t, http.StatusInternalServerError, resp.StatusCode)
+		assert.EqualValues(t, "something went wrong", restErr.Message)
+
+		DeleteWorkspaceRolesByWorkspaceIdFunc = func(workspaceId string) restErrors.IRestErr {
+			return nil
+		}
+	})
+
 	t.Run("Delete_Workspace_should_throw_if_namespace_service_throws", func(t *testing.T) {
 		CountWorkspaceByUserIdFunc = func(userId string) (int64, restErrors.IRestErr) {
 			return 2, nil
@@ -634,13 +776,20 @@ func TestAddMemberToWorkspace(t *testing.T) {
 	locals["user"] = *userDetails
 	locals["workspace"] = *workspaceModelLocals
 
+	locals["permissions"] = []string{roles.Wildcard}
+
+	WorkspaceRolePermissionsFunc = func(workspaceId string, role string) ([]string, restErrors.IRestErr) {
+		permissions, _ := roles.New().Permissions(role)
+		return permissions, nil
+	}
+
 	var validDto = map[string]string{
 		"email": "test@test.com",
 		"role":  "admin",
 	}
 	var invalidDto = map[string]string{
 		"email": "invalid",
-		"role":  "invalid",
+		"role":  "",
 	}
 
 	t.Run("add_member_to_workspace_should_pass", func(t *testing.T) {
@@ -648,7 +797,7 @@ func TestAddMemberToWorkspace(t *testing.T) {
 			return new(user.User), nil
 		}
 
-		WorkspaceInvitationFunc = func(dto *sendgrid.WorkspaceInvitationMailRequestDto) restErrors.IRestErr {
+		WorkspaceInvitationFunc = func(dto *mailer.WorkspaceInvitationMailRequestDto) restErrors.IRestErr {
 			return nil
 		}
 
@@ -693,6 +842,46 @@ func TestAddMemberToWorkspace(t *testing.T) {
 		assert.EqualValues(t, "invalid request body", result.Message)
 	})
 
+	t.Run("add_member_to_workspace_should_throw_if_role_does't_exist", func(t *testing.T) {
+		WorkspaceRolePermissionsFunc = func(workspaceId string, role string) ([]string, restErrors.IRestErr) {
+			return nil, restErrors.NewNotFoundError("not found")
+		}
+
+		body, resp := newFiberCtx(validDto, AddMember, locals)
+		var result restErrors.RestErr
+		err := json.Unmarshal(body, &result)
+		if err != nil {
+			panic(err.Error())
+		}
+		badReqErr := restErrors.NewValidationError(map[string]string{"role": "invalid role"})
+
+		assert.EqualValues(t, badReqErr.StatusCode(), resp.StatusCode)
+		assert.Equal(t, badReqErr, result)
+
+		WorkspaceRolePermissionsFunc = func(workspaceId string, role string) ([]string, restErrors.IRestErr) {
+			permissions, _ := roles.New().Permissions(role)
+			return permissions, nil
+		}
+	})
+
+	t.Run("add_member_to_workspace_should_throw_if_role_has_permissions_the_member_doesn't_have", func(t *testing.T) {
+		writerLocals := map[string]interface{}{}
+		for k, v := range locals {
+			writerLocals[k] = v
+		}
+		writerLocals["permissions"], _ = roles.New().Permissions(roles.Writer)
+
+		body, resp := newFiberCtx(validDto, AddMember, writerLocals)
+		var result restErrors.RestErr
+		err := json.Unmarshal(body, &result)
+		if err != nil {
+			panic(err.Error())
+		}
+
+		assert.EqualValues(t, http.StatusForbidden, resp.StatusCode)
+		assert.EqualValues(t, "you can't grant permissions you don't have", result.Message)
+	})
+
 	t.Run("add_member_to_workspace_should_throw_if_user_already_a_member_of_the_workspace", func(t *testing.T) {
 		GetByEmailFunc = func(email string) (*user.User, restErrors.IRestErr) {
 			member := new(user.User)
@@ -700,7 +889,7 @@ func TestAddMemberToWorkspace(t *testing.T) {
 			return member, nil
 		}
 
-		WorkspaceInvitationFunc = func(dto *sendgrid.WorkspaceInvitationMailRequestDto) restErrors.IRestErr {
+		WorkspaceInvitationFunc = func(dto *mailer.WorkspaceInvitationMailRequestDto) restErrors.IRestErr {
 			return nil
 		}
 
@@ -738,7 +927,7 @@ func TestAddMemberToWorkspace(t *testing.T) {
 			return new(user.User), nil
 		}
 
-		WorkspaceInvitationFunc = func(dto *sendgrid.WorkspaceInvitationMailRequestDto) restErrors.IRestErr {
+		WorkspaceInvitationFunc = func(dto *mailer.WorkspaceInvitationMailRequestDto) restErrors.IRestErr {
 			return nil
 		}
 
@@ -997,11 +1186,18 @@ func TestUpdateWorkspaceUser(t *testing.T) {
 	locals["user"] = *userDetails
 	locals["workspace"] = *workspaceModelLocals
 
+	locals["permissions"] = []string{roles.Wildcard}
+
+	WorkspaceRolePermissionsFunc = func(workspaceId string, role string) ([]string, restErrors.IRestErr) {
+		permissions, _ := roles.New().Permissions(role)
+		return permissions, nil
+	}
+
 	var validDto = map[string]string{
 		"role": "admin",
 	}
 	var invalidDto = map[string]string{
-		"role": "invalid",
+		"role": strings.Repeat("r", 101),
 	}
 
 	t.Run("update_workspace_user_should_pass", func(t *testing.T) {
@@ -1056,6 +1252,29 @@ func TestUpdateWorkspaceUser(t *testing.T) {
 		assert.EqualValues(t, http.StatusBadRequest, resp.StatusCode)
 	})
 
+	t.Run("update_workspace_user_should_throw_if_role_does't_exist", func(t *testing.T) {
+		WorkspaceRolePermissionsFunc = func(workspaceId string, role string) ([]string, restErrors.IRestErr) {
+			return nil, restErrors.NewNotFoundError("not found")
+		}
+
+		body, resp := newFiberCtx(map[string]string{"role": "operator"}, UpdateWorkspaceUser, locals)
+
+		var result restErrors.RestErr
+		err := json.Unmarshal(body, &result)
+		if err != nil {
+			panic(err.Error())
+		}
+		badReqErr := restErrors.NewValidationError(map[string]string{"role": "invalid role"})
+
+		assert.EqualValues(t, badReqErr, result)
+		assert.EqualValues(t, http.StatusBadRequest, resp.StatusCode)
+
+		WorkspaceRolePermissionsFunc = func(workspaceId string, role string) ([]string, restErrors.IRestErr) {
+			permissions, _ := roles.New().Permissions(role)
+			return permissions, nil
+		}
+	})
+
 	t.Run("update_workspace_should_throw_if_user_to_be_changed_isn't_member_of_the_workspace", func(t *testing.T) {
 		workspaceUserModelLocals.UserId = "different_id"
 		workspaceModelLocals.WorkspaceUsers = []workspaceuser.WorkspaceUser{*workspaceUserModelLocals}
@@ -1122,3 +1341,145 @@ func TestUpdateWorkspaceUser(t *testing.T) {
 	})
 
 }
+
+func TestTransferOwnership(t *testing.T) {
+	userDetails := new(token.UserDetails)
+	userDetails.ID = "ownerId"
+
+	workspaceModelLocals := new(workspace.Workspace)
+	workspaceModelLocals.ID = "1"
+	workspaceModelLocals.UserId = userDetails.ID
+	workspaceModelLocals.WorkspaceUsers = []workspaceuser.WorkspaceUser{
+		{UserId: "ownerId", Role: roles.Admin},
+		{UserId: "adminId", Role: roles.Admin},
+		{UserId: "readerId", Role: roles.Reader},
+	}
+
+	var locals = map[string]interface{}{}
+	locals["user"] = *userDetails
+	locals["workspace"] = *workspaceModelLocals
+
+	GetByIdFunc = func(Id string) (*user.User, restErrors.IRestErr) {
+		model := new(user.User)
+		model.ID = Id
+		return model, nil
+	}
+	FindWhereIdInSliceFunc = func(ids []string) ([]*user.User, restErrors.IRestErr) {
+		return []*user.User{}, nil
+	}
+
+	t.Run("transfer_ownership_should_pass", func(t *testing.T) {
+		TransferWorkspaceOwnershipFunc = func(workspace *workspace.Workspace, newOwnerId string) restErrors.IRestErr {
+			workspace.UserId = newOwnerId
+			return nil
+		}
+
+		result, resp := newFiberCtx(workspace.TransferOwnershipRequestDto{UserId: "adminId"}, TransferOwnership, locals)
+		var responseMessage map[string]workspace.WorkspaceResponseDto
+		err := json.Unmarshal(result, &responseMessage)
+		if err != nil {
+			panic(err)
+		}
+		assert.EqualValues(t, http.StatusOK, resp.StatusCode)
+		assert.EqualValues(t, "1", responseMessage["data"].ID)
+	})
+
+	t.Run("transfer_ownership_should_throw_if_user_isn't_the_owner", func(t *testing.T) {
+		notOwner := new(token.UserDetails)
+		notOwner.ID = "adminId"
+		locals["user"] = *notOwner
+
+		result, resp := newFiberCtx(workspace.TransferOwnershipRequestDto{UserId: "adminId"}, TransferOwnership, locals)
+		var restErr restErrors.RestErr
+		err := json.Unmarshal(result, &restErr)
+		if err != nil {
+			panic(err)
+		}
+		assert.EqualValues(t, "only the workspace owner can transfer its ownership", restErr.Message)
+		assert.EqualValues(t, http.StatusForbidden, resp.StatusCode)
+		locals["user"] = *userDetails
+	})
+
+	t.Run("transfer_ownership_should_throw_if_user_isn't_a_member", func(t *testing.T) {
+		result, resp := newFiberCtx(workspace.TransferOwnershipRequestDto{UserId: "strangerId"}, TransferOwnership, locals)
+		var restErr restErrors.RestErr
+		err := json.Unmarshal(result, &restErr)
+		if err != nil {
+			panic(err)
+		}
+		assert.EqualValues(t, "user isn't a member of the workspace", restErr.Message)
+		assert.EqualValues(t, http.StatusNotFound, resp.StatusCode)
+	})
+
+	t.Run("transfer_ownership_should_throw_if_member_isn't_an_admin", func(t *testing.T) {
+		result, resp := newFiberCtx(workspace.TransferOwnershipRequestDto{UserId: "readerId"}, TransferOwnership, locals)
+		var restErr restErrors.RestErr
+		err := json.Unmarshal(result, &restErr)
+		if err != nil {
+			panic(err)
+		}
+		assert.EqualValues(t, "workspace ownership can only be transferred to an admin member", restErr.Message)
+		assert.EqualValues(t, http.StatusBadRequest, resp.StatusCode)
+	})
+
+	t.Run("transfer_ownership_should_throw_if_service_throw", func(t *testing.T) {
+		TransferWorkspaceOwnershipFunc = func(workspace *workspace.Workspace, newOwnerId string) restErrors.IRestErr {
+			return restErrors.NewInternalServerError("something went wrong")
+		}
+
+		result, resp := newFiberCtx(workspace.TransferOwnershipRequestDto{UserId: "adminId"}, TransferOwnership, locals)
+		var restErr restErrors.RestErr
+		err := json.Unmarshal(result, &restErr)
+		if err != nil {
+			panic(err)
+		}
+		assert.EqualValues(t, "something went wrong", restErr.Message)
+		assert.EqualValues(t, http.StatusInternalServerError, resp.StatusCode)
+	})
+}
+
+func TestUpdateSecretProvider(t *testing.T) {
+	workspaceModelLocals := new(workspace.Workspace)
+	workspaceModelLocals.ID = "1"
+
+	var locals = map[string]interface{}{}
+	locals["workspace"] = *workspaceModelLocals
+
+	t.Run("update_secret_provider_should_pass", func(t *testing.T) {
+		UpdateWorkspaceSecretProviderFunc = func(workspace *workspace.Workspace, provider string) restErrors.IRestErr {
+			workspace.SecretProvider = provider
+			return nil
+		}
+
+		result, resp := newFiberCtx(workspace.UpdateSecretProviderRequestDto{SecretProvider: "kubernetes"}, UpdateSecretProvider, locals)
+		var responseMessage map[string]workspace.WorkspaceResponseDto
+		err := json.Unmarshal(result, &responseMessage)
+		if err != nil {
+			panic(err)
+		}
+		assert.EqualValues(t, http.StatusOK, resp.StatusCode)
+		assert.EqualValues(t, "kubernetes", responseMessage["data"].SecretProvider)
+	})
+
+	t.Run("update_secret_provider_should_throw_validation_error", func(t *testing.T) {
+		result, resp := newFiberCtx(workspace.UpdateSecretProviderRequestDto{SecretProvider: "kms"}, UpdateSecretProvider, locals)
+		var restErr restErrors.RestErr
+		err := json.Unmarshal(result, &restErr)
+		if err != nil {
+			panic(err)
+		}
+		assert.EqualValues(t, http.StatusBadRequest, resp.StatusCode)
+		assert.EqualValues(t, "secret provider can only be kubernetes or vault", restErr.Validations["secret_provider"])
+	})
+
+	t.Run("update_secret_provider_should_throw_if_the_provider_isn't_configured", func(t *testing.T) {
+		result, resp := newFiberCtx(workspace.UpdateSecretProviderRequestDto{SecretProvider: "vault"}, UpdateSecretProvider, locals)
+		var restErr restErrors.RestErr
+		err := json.Unmarshal(result, &restErr)
+		if err != nil {
+			panic(err)
+		}
+		assert.EqualValues(t, http.StatusBadRequest, resp.StatusCode)
+		assert.EqualValues(t, "secret provider vault isn't configured", restErr.Message)
+	})
+}
@@ -0,0 +1,136 @@
+// Package workspacerole handler is the representation layer for the workspace custom roles
+// custom roles are permission sets defined by the workspace beside the built-in admin, writer and reader roles
+package workspacerole
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/kotalco/core-api/core/workspace"
+	"github.com/kotalco/core-api/core/workspacerole"
+	restErrors "github.com/kotalco/core-api/pkg/errors"
+	"github.com/kotalco/core-api/pkg/responder"
+	"github.com/kotalco/core-api/pkg/roles"
+	"net/http"
+)
+
+var workspaceRoleService = workspacerole.NewService()
+
+// Permissions returns the resources and actions permissions can be built from
+func Permissions(c *fiber.Ctx) error {
+	return c.Status(http.StatusOK).JSON(responder.NewResponse(workspacerole.PermissionsResponseDto{
+		Resources: roles.Resources,
+		Actions:   roles.Actions,
+	}))
+}
+
+// List returns the built-in roles followed by the workspace custom roles
+func List(c *fiber.Ctx) error {
+	model := c.Locals("workspace").(workspace.Workspace)
+
+	list, err := workspaceRoleService.WithoutTransaction().GetByWorkspaceId(model.ID)
+	if err != nil {
+		return c.Status(err.StatusCode()).JSON(err)
+	}
+
+	result := make([]workspacerole.WorkspaceRoleResponseDto, 0)
+	for _, name := range roles.New().BuiltIn() {
+		permissions, _ := roles.New().Permissions(name)
+		result = append(result, workspacerole.WorkspaceRoleResponseDto{Name: name, Permissions: permissions, BuiltIn: true})
+	}
+	for _, v := range list {
+		result = append(result, new(workspacerole.WorkspaceRoleResponseDto).Marshall(v))
+	}
+
+	return c.Status(http.StatusOK).JSON(responder.NewResponse(result))
+}
+
+// Create validates dto, creates a new workspace custom role
+// members can't create roles with permissions they don't have
+func Create(c *fiber.Ctx) error {
+	model := c.Locals("workspace").(workspace.Workspace)
+	permissions := c.Locals("permissions").([]string)
+
+	dto := new(workspacerole.CreateWorkspaceRoleRequestDto)
+	if err := c.BodyParser(dto); err != nil {
+		badReq := restErrors.NewBadRequestError("invalid request body")
+		return c.Status(badReq.StatusCode()).JSON(badReq)
+	}
+
+	err := workspacerole.Validate(dto)
+	if err != nil {
+		return c.Status(err.StatusCode()).JSON(err)
+	}
+
+	if !roles.Covers(permissions, dto.Permissions) {
+		forbidden := restErrors.NewForbiddenError("you can't grant permissions you don't have")
+		return c.Status(forbidden.StatusCode()).JSON(forbidden)
+	}
+
+	role, err := workspaceRoleService.WithoutTransaction().Create(dto, model.ID)
+	if err != nil {
+		return c.Status(err.StatusCode()).JSON(err)
+	}
+
+	return c.Status(http.StatusCreated).JSON(responder.NewResponse(new(workspacerole.WorkspaceRoleResponseDto).Marshall(role)))
+}
+
+// Update validates dto, replaces the workspace custom role permissions
+func Update(c *fiber.Ctx) error {
+	role := c.Locals("workspaceRole").(workspacerole.WorkspaceRole)
+	permissions := c.Locals("permissions").([]string)
+
+	dto := new(workspacerole.UpdateWorkspaceRoleRequestDto)
+	if err := c.BodyParser(dto); err != nil {
+		badReq := restErrors.NewBadRequestError("invalid request body")
+		return c.Status(badReq.StatusCode()).JSON(badReq)
+	}
+
+	err := workspacerole.Validate(dto)
+	if err != nil {
+		return c.Status(err.StatusCode()).JSON(err)
+	}
+
+	if !roles.Covers(permissions, dto.Permissions) {
+		forbidden := restErrors.NewForbiddenError("you can't grant permissions you don't have")
+		return c.Status(forbidden.StatusCode()).JSON(forbidden)
+	}
+
+	err = workspaceRoleService.WithoutTransaction().Update(dto, &role)
+	if err != nil {
+		return c.Status(err.StatusCode()).JSON(err)
+	}
+
+	return c.Status(http.StatusOK).JSON(responder.NewResponse(new(workspacerole.WorkspaceRoleResponseDto).Marshall(&role)))
+}
+
+// Delete deletes a workspace custom role, roles assigned to members can't be deleted
+func Delete(c *fiber.Ctx) error {
+	model := c.Locals("workspace").(workspace.Workspace)
+	role := c.Locals("workspaceRole").(workspacerole.WorkspaceRole)
+
+	for _, v := range model.WorkspaceUsers {
+		if v.Role == role.Name {
+			conflictErr := restErrors.NewConflictError("role is assigned to workspace members, change their role first")
+			return c.Status(conflictErr.StatusCode()).JSON(conflictErr)
+		}
+	}
+
+	err := workspaceRoleService.WithoutTransaction().Delete(&role)
+	if err != nil {
+		return c.Status(err.StatusCode()).JSON(err)
+	}
+
+	return c.SendStatus(http.StatusNoContent)
+}
+
+// ValidateRoleExist checks if the role_id passed as path param exist in the workspace, creates workspaceRole local
+func ValidateRoleExist(c *fiber.Ctx) error {
+	model := c.Locals("workspace").(workspace.Workspace)
+
+	role, err := workspaceRoleService.WithoutTransaction().GetById(c.Params("role_id"), model.ID)
+	if err != nil {
+		return c.Status(err.StatusCode()).JSON(err)
+	}
+
+	c.Locals("workspaceRole", *role)
+	return c.Next()
+}
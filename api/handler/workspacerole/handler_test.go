@@ -0,0 +1,248 @@
+package workspacerole
+
+import (
+	"bytes"
+	"encoding/json"
+	"github.com/gofiber/fiber/v2"
+	"github.com/kotalco/core-api/core/workspace"
+	"github.com/kotalco/core-api/core/workspacerole"
+	"github.com/kotalco/core-api/core/workspaceuser"
+	restErrors "github.com/kotalco/core-api/pkg/errors"
+	"github.com/kotalco/core-api/pkg/roles"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+)
+
+/*
+Workspace role service Mocks
+*/
+var (
+	CreateFunc              func(dto *workspacerole.CreateWorkspaceRoleRequestDto, workspaceId string) (*workspacerole.WorkspaceRole, restErrors.IRestErr)
+	UpdateFunc              func(dto *workspacerole.UpdateWorkspaceRoleRequestDto, role *workspacerole.WorkspaceRole) restErrors.IRestErr
+	DeleteFunc              func(role *workspacerole.WorkspaceRole) restErrors.IRestErr
+	GetByIdFunc             func(id string, workspaceId string) (*workspacerole.WorkspaceRole, restErrors.IRestErr)
+	GetByWorkspaceIdFunc    func(workspaceId string) ([]*workspacerole.WorkspaceRole, restErrors.IRestErr)
+	DeleteByWorkspaceIdFunc func(workspaceId string) restErrors.IRestErr
+	PermissionsFunc         func(workspaceId string, role string) ([]string, restErrors.IRestErr)
+)
+
+type workspaceRoleServiceMock struct{}
+
+func (r workspaceRoleServiceMock) WithTransaction(txHandle *gorm.DB) workspacerole.IService {
+	return r
+}
+func (r workspaceRoleServiceMock) WithoutTransaction() workspacerole.IService {
+	return r
+}
+func (workspaceRoleServiceMock) Create(dto *workspacerole.CreateWorkspaceRoleRequestDto, workspaceId string) (*workspacerole.WorkspaceRole, restErrors.IRestErr) {
+	return CreateFunc(dto, workspaceId)
+}
+func (workspaceRoleServiceMock) Update(dto *workspacerole.UpdateWorkspaceRoleRequestDto, role *workspacerole.WorkspaceRole) restErrors.IRestErr {
+	return UpdateFunc(dto, role)
+}
+func (workspaceRoleServiceMock) Delete(role *workspacerole.WorkspaceRole) restErrors.IRestErr {
+	return DeleteFunc(role)
+}
+func (workspaceRoleServiceMock) GetById(id string, workspaceId string) (*workspacerole.WorkspaceRole, restErrors.IRestErr) {
+	return GetByIdFunc(id, workspaceId)
+}
+func (workspaceRoleServiceMock) GetByWorkspaceId(workspaceId string) ([]*workspacerole.WorkspaceRole, restErrors.IRestErr) {
+	return GetByWorkspaceIdFunc(workspaceId)
+}
+func (workspaceRoleServiceMock) DeleteByWorkspaceId(workspaceId string) restErrors.IRestErr {
+	return DeleteByWorkspaceIdFunc(workspaceId)
+}
+func (workspaceRoleServiceMock) Permissions(workspaceId string, role string) ([]string, restErrors.IRestErr) {
+	return PermissionsFunc(workspaceId, role)
+}
+
+func newFiberCtx(dto interface{}, method func(c *fiber.Ctx) error, locals map[string]interface{}) ([]byte, *http.Response) {
+	app := fiber.New()
+	app.Post("/test/", func(c *fiber.Ctx) error {
+		for key, element := range locals {
+			c.Locals(key, element)
+		}
+		return method(c)
+	})
+
+	marshaledDto, err := json.Marshal(dto)
+	if err != nil {
+		panic(err.Error())
+	}
+
+	req := httptest.NewRequest("POST", "/test", bytes.NewBuffer(marshaledDto))
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := app.Test(req)
+	if err != nil {
+		panic(err.Error())
+	}
+
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		panic(err.Error())
+	}
+
+	return body, resp
+}
+
+func TestMain(m *testing.M) {
+	workspaceRoleService = &workspaceRoleServiceMock{}
+	code := m.Run()
+	os.Exit(code)
+}
+
+func TestList(t *testing.T) {
+	var locals = map[string]interface{}{}
+	locals["workspace"] = workspace.Workspace{ID: "1"}
+
+	t.Run("List_Should_Return_Built_In_And_Custom_Roles", func(t *testing.T) {
+		GetByWorkspaceIdFunc = func(workspaceId string) ([]*workspacerole.WorkspaceRole, restErrors.IRestErr) {
+			return []*workspacerole.WorkspaceRole{{ID: "1", Name: "operator", Permissions: []string{"endpoints:*"}}}, nil
+		}
+
+		body, resp := newFiberCtx("", List, locals)
+		var result map[string][]workspacerole.WorkspaceRoleResponseDto
+		err := json.Unmarshal(body, &result)
+		assert.Nil(t, err)
+		assert.EqualValues(t, http.StatusOK, resp.StatusCode)
+		assert.Len(t, result["data"], 4)
+		assert.EqualValues(t, roles.Admin, result["data"][0].Name)
+		assert.True(t, result["data"][0].BuiltIn)
+		assert.EqualValues(t, "operator", result["data"][3].Name)
+		assert.False(t, result["data"][3].BuiltIn)
+	})
+	t.Run("List_Should_Throw_If_Service_Throws", func(t *testing.T) {
+		GetByWorkspaceIdFunc = func(workspaceId string) ([]*workspacerole.WorkspaceRole, restErrors.IRestErr) {
+			return nil, restErrors.NewInternalServerError("something went wrong")
+		}
+
+		_, resp := newFiberCtx("", List, locals)
+		assert.EqualValues(t, http.StatusInternalServerError, resp.StatusCode)
+	})
+}
+
+func TestCreate(t *testing.T) {
+	var locals = map[string]interface{}{}
+	locals["workspace"] = workspace.Workspace{ID: "1"}
+	locals["permissions"] = []string{roles.Wildcard}
+
+	validDto := map[string]interface{}{
+		"name":        "operator",
+		"permissions": []string{"ethereum.nodes:*", "secrets:read"},
+	}
+
+	t.Run("Create_Should_Pass", func(t *testing.T) {
+		CreateFunc = func(dto *workspacerole.CreateWorkspaceRoleRequestDto, workspaceId string) (*workspacerole.WorkspaceRole, restErrors.IRestErr) {
+			return &workspacerole.WorkspaceRole{ID: "1", WorkspaceID: workspaceId, Name: dto.Name, Permissions: dto.Permissions}, nil
+		}
+
+		body, resp := newFiberCtx(validDto, Create, locals)
+		var result map[string]workspacerole.WorkspaceRoleResponseDto
+		err := json.Unmarshal(body, &result)
+		assert.Nil(t, err)
+		assert.EqualValues(t, http.StatusCreated, resp.StatusCode)
+		assert.EqualValues(t, "operator", result["data"].Name)
+		assert.EqualValues(t, []string{"ethereum.nodes:*", "secrets:read"}, result["data"].Permissions)
+	})
+	t.Run("Create_Should_Throw_Validation_Error", func(t *testing.T) {
+		body, resp := newFiberCtx(map[string]interface{}{"name": "", "permissions": []string{"ethereum.nodes:restart"}}, Create, locals)
+		var result restErrors.RestErr
+		err := json.Unmarshal(body, &result)
+		assert.Nil(t, err)
+
+		fields := map[string]string{}
+		fields["name"] = "name should be greater than 1 char and less than 100 char"
+		fields["permissions"] = "permissions should be a list of resource:action e.g. ethereum.nodes:update"
+		assert.EqualValues(t, http.StatusBadRequest, resp.StatusCode)
+		assert.Equal(t, restErrors.NewValidationError(fields), result)
+	})
+	t.Run("Create_Should_Throw_If_Granting_Permissions_Member_Doesn't_Have", func(t *testing.T) {
+		readerLocals := map[string]interface{}{}
+		readerLocals["workspace"] = workspace.Workspace{ID: "1"}
+		readerLocals["permissions"], _ = roles.New().Permissions(roles.Reader)
+
+		body, resp := newFiberCtx(validDto, Create, readerLocals)
+		var result restErrors.RestErr
+		err := json.Unmarshal(body, &result)
+		assert.Nil(t, err)
+		assert.EqualValues(t, http.StatusForbidden, resp.StatusCode)
+		assert.EqualValues(t, "you can't grant permissions you don't have", result.Message)
+	})
+	t.Run("Create_Should_Throw_If_Service_Throws", func(t *testing.T) {
+		CreateFunc = func(dto *workspacerole.CreateWorkspaceRoleRequestDto, workspaceId string) (*workspacerole.WorkspaceRole, restErrors.IRestErr) {
+			return nil, restErrors.NewConflictError("role already exist")
+		}
+
+		_, resp := newFiberCtx(validDto, Create, locals)
+		assert.EqualValues(t, http.StatusConflict, resp.StatusCode)
+	})
+}
+
+func TestUpdate(t *testing.T) {
+	var locals = map[string]interface{}{}
+	locals["workspace"] = workspace.Workspace{ID: "1"}
+	locals["workspaceRole"] = workspacerole.WorkspaceRole{ID: "1", Name: "operator", Permissions: []string{"secrets:read"}}
+	locals["permissions"] = []string{roles.Wildcard}
+
+	t.Run("Update_Should_Pass", func(t *testing.T) {
+		UpdateFunc = func(dto *workspacerole.UpdateWorkspaceRoleRequestDto, role *workspacerole.WorkspaceRole) restErrors.IRestErr {
+			role.Permissions = dto.Permissions
+			return nil
+		}
+
+		body, resp := newFiberCtx(map[string]interface{}{"permissions": []string{"endpoints:*"}}, Update, locals)
+		var result map[string]workspacerole.WorkspaceRoleResponseDto
+		err := json.Unmarshal(body, &result)
+		assert.Nil(t, err)
+		assert.EqualValues(t, http.StatusOK, resp.StatusCode)
+		assert.EqualValues(t, []string{"endpoints:*"}, result["data"].Permissions)
+	})
+	t.Run("Update_Should_Throw_Validation_Error", func(t *testing.T) {
+		_, resp := newFiberCtx(map[string]interface{}{"permissions": []string{}}, Update, locals)
+		assert.EqualValues(t, http.StatusBadRequest, resp.StatusCode)
+	})
+}
+
+func TestDelete(t *testing.T) {
+	var locals = map[string]interface{}{}
+	locals["workspaceRole"] = workspacerole.WorkspaceRole{ID: "1", Name: "operator"}
+
+	t.Run("Delete_Should_Pass", func(t *testing.T) {
+		locals["workspace"] = workspace.Workspace{ID: "1", WorkspaceUsers: []workspaceuser.WorkspaceUser{{Role: roles.Admin}}}
+		DeleteFunc = func(role *workspacerole.WorkspaceRole) restErrors.IRestErr {
+			return nil
+		}
+
+		_, resp := newFiberCtx("", Delete, locals)
+		assert.EqualValues(t, http.StatusNoContent, resp.StatusCode)
+	})
+	t.Run("Delete_Should_Throw_If_Role_Is_Assigned", func(t *testing.T) {
+		locals["workspace"] = workspace.Workspace{ID: "1", WorkspaceUsers: []workspaceuser.WorkspaceUser{{Role: "operator"}}}
+
+		body, resp := newFiberCtx("", Delete, locals)
+		var result restErrors.RestErr
+		err := json.Unmarshal(body, &result)
+		assert.Nil(t, err)
+		assert.EqualValues(t, http.StatusConflict, resp.StatusCode)
+		assert.EqualValues(t, "role is assigned to workspace members, change their role first", result.Message)
+	})
+}
+
+func TestValidateRoleExist(t *testing.T) {
+	var locals = map[string]interface{}{}
+	locals["workspace"] = workspace.Workspace{ID: "1"}
+
+	t.Run("Validate_Role_Exist_Should_Throw_If_Role_Doesn't_Exist", func(t *testing.T) {
+		GetByIdFunc = func(id string, workspaceId string) (*workspacerole.WorkspaceRole, restErrors.IRestErr) {
+			return nil, restErrors.NewNotFoundError("can't find role")
+		}
+
+		_, resp := newFiberCtx("", ValidateRoleExist, locals)
+		assert.EqualValues(t, http.StatusNotFound, resp.StatusCode)
+	})
+}
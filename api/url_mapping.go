@@ -3,14 +3,19 @@ package api
 import (
 	"github.com/gofiber/fiber/v2"
 	"github.com/gofiber/websocket/v2"
+	"github.com/kotalco/core-api/api/handler/admin"
 	"github.com/kotalco/core-api/api/handler/aptos"
 	"github.com/kotalco/core-api/api/handler/bitcoin"
+	"github.com/kotalco/core-api/api/handler/bulk"
 	"github.com/kotalco/core-api/api/handler/chainlink"
 	"github.com/kotalco/core-api/api/handler/endpoint"
 	"github.com/kotalco/core-api/api/handler/ethereum"
+	"github.com/kotalco/core-api/api/handler/ethereum/full_node"
 	"github.com/kotalco/core-api/api/handler/ethereum2/beacon_node"
+	"github.com/kotalco/core-api/api/handler/ethereum2/performance"
 	"github.com/kotalco/core-api/api/handler/ethereum2/validator"
 	"github.com/kotalco/core-api/api/handler/filecoin"
+	"github.com/kotalco/core-api/api/handler/ipfs/ipfs_cluster"
 	"github.com/kotalco/core-api/api/handler/ipfs/ipfs_cluster_peer"
 	"github.com/kotalco/core-api/api/handler/ipfs/ipfs_peer"
 	"github.com/kotalco/core-api/api/handler/near"
@@ -18,12 +23,14 @@ import (
 	"github.com/kotalco/core-api/api/handler/secret"
 	"github.com/kotalco/core-api/api/handler/setting"
 	"github.com/kotalco/core-api/api/handler/shared"
+	"github.com/kotalco/core-api/api/handler/snapshot"
 	"github.com/kotalco/core-api/api/handler/stacks"
 	"github.com/kotalco/core-api/api/handler/storage_class"
 	"github.com/kotalco/core-api/api/handler/sts"
 	"github.com/kotalco/core-api/api/handler/svc"
 	"github.com/kotalco/core-api/api/handler/user"
 	"github.com/kotalco/core-api/api/handler/workspace"
+	"github.com/kotalco/core-api/api/handler/workspacerole"
 	"github.com/kotalco/core-api/config"
 	"github.com/kotalco/core-api/pkg/middleware"
 )
@@ -40,6 +47,7 @@ func MapUrl(app *fiber.App) {
 	v1.Use(config.FiberLimiter())
 	//users group
 	v1.Post("sessions", user.SignIn)
+	v1.Get("password_policy", setting.GetPasswordPolicy)
 	users := v1.Group("users")
 	users.Post("/", user.SignUp)
 	users.Post("/resend_email_verification", user.SendEmailVerification)
@@ -50,6 +58,7 @@ func MapUrl(app *fiber.App) {
 	users.Post("/change_password", middleware.JWTProtected, middleware.TFAProtected, user.ChangePassword)
 	users.Post("/change_email", middleware.JWTProtected, middleware.TFAProtected, user.ChangeEmail)
 	users.Get("/whoami", middleware.JWTProtected, middleware.TFAProtected, user.Whoami)
+	users.Delete("/me", middleware.JWTProtected, middleware.TFAProtected, user.DeleteAccount)
 
 	users.Post("/totp", middleware.JWTProtected, user.CreateTOTP)
 	users.Post("/totp/enable", middleware.JWTProtected, user.EnableTwoFactorAuth)
@@ -60,15 +69,22 @@ func MapUrl(app *fiber.App) {
 	workspaces := v1.Group("workspaces")
 	workspaces.Use(middleware.JWTProtected, middleware.TFAProtected)
 	workspaces.Post("/", workspace.Create)
-	workspaces.Patch("/:id", workspace.ValidateWorkspaceExist, middleware.ValidateWorkspaceMembership, middleware.IsWriter, workspace.Update)
-	workspaces.Delete("/:id", workspace.ValidateWorkspaceExist, middleware.ValidateWorkspaceMembership, middleware.IsWriter, workspace.Delete)
+	workspaces.Patch("/:id", workspace.ValidateWorkspaceExist, middleware.ValidateWorkspaceMembership, middleware.HasPermission("workspaces:update"), workspace.Update)
+	workspaces.Delete("/:id", workspace.ValidateWorkspaceExist, middleware.ValidateWorkspaceMembership, middleware.HasPermission("workspaces:delete"), workspace.Delete)
 	workspaces.Get("/", workspace.GetByUserId)
-	workspaces.Get("/:id", workspace.ValidateWorkspaceExist, middleware.ValidateWorkspaceMembership, middleware.IsReader, workspace.GetById)
-	workspaces.Post("/:id/members", workspace.ValidateWorkspaceExist, middleware.ValidateWorkspaceMembership, workspace.AddMember)
+	workspaces.Get("/:id", workspace.ValidateWorkspaceExist, middleware.ValidateWorkspaceMembership, middleware.HasPermission("workspaces:read"), workspace.GetById)
+	workspaces.Post("/:id/members", workspace.ValidateWorkspaceExist, middleware.ValidateWorkspaceMembership, middleware.HasPermission("workspaces.members:create"), workspace.AddMember)
 	workspaces.Post("/:id/leave", workspace.ValidateWorkspaceExist, middleware.ValidateWorkspaceMembership, workspace.Leave)
-	workspaces.Delete("/:id/members/:user_id", workspace.ValidateWorkspaceExist, middleware.ValidateWorkspaceMembership, workspace.RemoveMember)
-	workspaces.Get("/:id/members", workspace.ValidateWorkspaceExist, middleware.ValidateWorkspaceMembership, workspace.Members)
-	workspaces.Patch("/:id/members/:user_id", workspace.ValidateWorkspaceExist, middleware.ValidateWorkspaceMembership, workspace.UpdateWorkspaceUser)
+	workspaces.Post("/:id/transfer", workspace.ValidateWorkspaceExist, middleware.ValidateWorkspaceMembership, workspace.TransferOwnership)
+	workspaces.Put("/:id/secret_provider", workspace.ValidateWorkspaceExist, middleware.ValidateWorkspaceMembership, middleware.HasPermission("workspaces:update"), workspace.UpdateSecretProvider)
+	workspaces.Delete("/:id/members/:user_id", workspace.ValidateWorkspaceExist, middleware.ValidateWorkspaceMembership, middleware.HasPermission("workspaces.members:delete"), workspace.RemoveMember)
+	workspaces.Get("/:id/members", workspace.ValidateWorkspaceExist, middleware.ValidateWorkspaceMembership, middleware.HasPermission("workspaces.members:read"), workspace.Members)
+	workspaces.Patch("/:id/members/:user_id", workspace.ValidateWorkspaceExist, middleware.ValidateWorkspaceMembership, middleware.HasPermission("workspaces.members:update"), workspace.UpdateWorkspaceUser)
+	workspaces.Get("/:id/permissions", workspace.ValidateWorkspaceExist, middleware.ValidateWorkspaceMembership, workspacerole.Permissions)
+	workspaces.Get("/:id/roles", workspace.ValidateWorkspaceExist, middleware.ValidateWorkspaceMembership, middleware.HasPermission("workspaces.roles:read"), workspacerole.List)
+	workspaces.Post("/:id/roles", workspace.ValidateWorkspaceExist, middleware.ValidateWorkspaceMembership, middleware.HasPermission("workspaces.roles:create"), workspacerole.Create)
+	workspaces.Put("/:id/roles/:role_id", workspace.ValidateWorkspaceExist, middleware.ValidateWorkspaceMembership, middleware.HasPermission("workspaces.roles:update"), workspacerole.ValidateRoleExist, workspacerole.Update)
+	workspaces.Delete("/:id/roles/:role_id", workspace.ValidateWorkspaceExist, middleware.ValidateWorkspaceMembership, middleware.HasPermission("workspaces.roles:delete"), workspacerole.ValidateRoleExist, workspacerole.Delete)
 
 	//svc group
 	svcGroup := v1.Group("/core/services")
@@ -79,22 +95,48 @@ func MapUrl(app *fiber.App) {
 
 	//endpoints group
 	endpoints := v1.Group("endpoints")
-	endpoints.Post("/", middleware.JWTProtected, middleware.TFAProtected, middleware.WorkspaceProtected, middleware.ValidateWorkspaceMembership, middleware.IsWriter, endpoint.Create)
-	endpoints.Head("/", middleware.JWTProtected, middleware.TFAProtected, middleware.WorkspaceProtected, middleware.ValidateWorkspaceMembership, middleware.IsReader, endpoint.Count)
-	endpoints.Get("/", middleware.JWTProtected, middleware.TFAProtected, middleware.WorkspaceProtected, middleware.ValidateWorkspaceMembership, middleware.IsReader, endpoint.List)
-	endpoints.Get("/:name", middleware.JWTProtected, middleware.TFAProtected, middleware.WorkspaceProtected, middleware.ValidateWorkspaceMembership, middleware.IsReader, endpoint.Get)
-	endpoints.Delete("/:name", middleware.JWTProtected, middleware.TFAProtected, middleware.WorkspaceProtected, middleware.ValidateWorkspaceMembership, middleware.IsAdmin, endpoint.Delete)
-	endpoints.Get("/:name/stats", middleware.JWTProtected, middleware.TFAProtected, middleware.WorkspaceProtected, middleware.ValidateWorkspaceMembership, middleware.IsReader, endpoint.ReadStats)
+	endpoints.Post("/", middleware.JWTProtected, middleware.TFAProtected, middleware.WorkspaceProtected, middleware.ValidateWorkspaceMembership, middleware.HasPermission("endpoints:create"), endpoint.Create)
+	endpoints.Head("/", middleware.JWTProtected, middleware.TFAProtected, middleware.WorkspaceProtected, middleware.ValidateWorkspaceMembership, middleware.HasPermission("endpoints:read"), endpoint.Count)
+	endpoints.Get("/", middleware.JWTProtected, middleware.TFAProtected, middleware.WorkspaceProtected, middleware.ValidateWorkspaceMembership, middleware.HasPermission("endpoints:read"), endpoint.List)
+	endpoints.Get("/:name", middleware.JWTProtected, middleware.TFAProtected, middleware.WorkspaceProtected, middleware.ValidateWorkspaceMembership, middleware.HasPermission("endpoints:read"), endpoint.Get)
+	endpoints.Delete("/:name", middleware.JWTProtected, middleware.TFAProtected, middleware.WorkspaceProtected, middleware.ValidateWorkspaceMembership, middleware.HasPermission("endpoints:delete"), endpoint.Delete)
+	endpoints.Get("/:name/stats", middleware.JWTProtected, middleware.TFAProtected, middleware.WorkspaceProtected, middleware.ValidateWorkspaceMembership, middleware.HasPermission("endpoints:read"), endpoint.ReadStats)
 
 	//settings group
 	settingGroup := v1.Group("settings", middleware.JWTProtected, middleware.TFAProtected)
 	settingGroup.Get("/", setting.Settings)
-	settingGroup.Post("/domain", setting.ConfigureDomain)
-	settingGroup.Post("/tls", setting.ConfigureTLS)
-	settingGroup.Post("/registration", setting.ConfigureRegistration)
+	settingGroup.Post("/domain", middleware.IsPlatformAdmin, setting.ConfigureDomain)
+	settingGroup.Post("/tls", middleware.IsPlatformAdmin, setting.ConfigureTLS)
+	settingGroup.Post("/registration", middleware.IsPlatformAdmin, setting.ConfigureRegistration)
+	settingGroup.Post("/password_policy", middleware.IsPlatformAdmin, setting.ConfigurePasswordPolicy)
+	settingGroup.Get("/mail", middleware.IsPlatformAdmin, setting.GetMailSettings)
+	settingGroup.Post("/mail", middleware.IsPlatformAdmin, setting.ConfigureMail)
+	settingGroup.Get("/mail/templates", middleware.IsPlatformAdmin, setting.MailTemplates)
+	settingGroup.Put("/mail/templates/:name", middleware.IsPlatformAdmin, setting.ConfigureMailTemplate)
+	settingGroup.Delete("/mail/templates/:name", middleware.IsPlatformAdmin, setting.ResetMailTemplate)
+	settingGroup.Get("/checkpoint_sync_providers", setting.GetCheckpointSyncProviders)
+	settingGroup.Post("/checkpoint_sync_providers", middleware.IsPlatformAdmin, setting.ConfigureCheckpointSyncProviders)
 	//todo change the route /ip-address to /network-identifiers
 	settingGroup.Get("/ip-address", setting.NetworkIdentifiers)
 
+	//admin group
+	adminGroup := v1.Group("admin", middleware.JWTProtected, middleware.TFAProtected, middleware.IsPlatformAdmin)
+	adminUsers := adminGroup.Group("users")
+	adminUsers.Get("/", admin.ListUsers)
+	adminUsers.Get("/:user_id", admin.ValidateUserExist, admin.GetUser)
+	adminUsers.Post("/:user_id/disable", admin.ValidateUserExist, admin.DisableUser)
+	adminUsers.Post("/:user_id/enable", admin.ValidateUserExist, admin.EnableUser)
+	adminUsers.Post("/:user_id/reset_password", admin.ValidateUserExist, admin.ResetPassword)
+	adminUsers.Post("/:user_id/reset_totp", admin.ValidateUserExist, admin.ResetTwoFactorAuth)
+	adminUsers.Post("/:user_id/unlock", admin.ValidateUserExist, admin.UnlockUser)
+	adminUsers.Post("/:user_id/platform_admin", admin.ValidateUserExist, admin.PromotePlatformAdmin)
+	adminUsers.Delete("/:user_id/platform_admin", admin.ValidateUserExist, admin.DemotePlatformAdmin)
+	adminWorkspaces := adminGroup.Group("workspaces")
+	adminWorkspaces.Get("/", admin.ListWorkspaces)
+	adminWorkspaces.Post("/:id/transfer", admin.ValidateWorkspaceExist, admin.TransferWorkspace)
+	adminGroup.Get("/nodes/count", admin.NodesCount)
+	adminGroup.Post("/mail/test", admin.SendTestMail)
+
 	/*
 	*** deployments
 	 */
@@ -103,175 +145,318 @@ func MapUrl(app *fiber.App) {
 	chainlinkGroup := v1.Group("chainlink", middleware.JWTProtected, middleware.TFAProtected, middleware.WorkspaceProtected, middleware.ValidateWorkspaceMembership)
 	chainlinkNodes := chainlinkGroup.Group("nodes")
 
-	chainlinkNodes.Post("/", middleware.IsWriter, chainlink.Create)
-	chainlinkNodes.Head("/", middleware.IsReader, chainlink.Count)
-	chainlinkNodes.Get("/", middleware.IsReader, chainlink.List)
-	chainlinkNodes.Get("/:name", middleware.IsReader, chainlink.ValidateNodeExist, chainlink.Get)
-	chainlinkNodes.Get("/:name/logs", middleware.IsReader, websocket.New(shared.Logger))
-	chainlinkNodes.Get("/:name/status", middleware.IsReader, websocket.New(shared.Status))
-	chainlinkNodes.Get("/:name/metrics", middleware.IsReader, websocket.New(shared.Metrics))
-	chainlinkNodes.Put("/:name", middleware.IsWriter, chainlink.ValidateNodeExist, chainlink.Update)
-	chainlinkNodes.Delete("/:name", middleware.IsAdmin, chainlink.ValidateNodeExist, chainlink.Delete)
+	chainlinkNodes.Post("/", middleware.HasPermission("chainlink.nodes:create"), secret.Materialize, snapshot.Restore, chainlink.Create)
+	chainlinkNodes.Head("/", middleware.HasPermission("chainlink.nodes:read"), chainlink.Count)
+	chainlinkNodes.Get("/", middleware.HasPermission("chainlink.nodes:read"), chainlink.List)
+	chainlinkNodes.Get("/:name", middleware.HasPermission("chainlink.nodes:read"), chainlink.ValidateNodeExist, chainlink.Get)
+	chainlinkNodes.Get("/:name/logs", middleware.HasPermission("chainlink.nodes:read"), websocket.New(shared.Logger))
+	chainlinkNodes.Get("/:name/status", middleware.HasPermission("chainlink.nodes:read"), websocket.New(shared.Status))
+	chainlinkNodes.Get("/:name/metrics", middleware.HasPermission("chainlink.nodes:read"), websocket.New(shared.Metrics))
+	chainlinkNodes.Get("/:name/storage", middleware.HasPermission("chainlink.nodes:read"), chainlink.ValidateNodeExist, storage_class.NodeVolume)
+	chainlinkNodes.Post("/:name/storage/expand", middleware.HasPermission("chainlink.nodes:update"), chainlink.ValidateNodeExist, chainlink.ExpandStorage)
+	chainlinkNodes.Post("/:name/snapshots", middleware.HasPermission("snapshots:create"), chainlink.ValidateNodeExist, snapshot.Create)
+	chainlinkNodes.Get("/:name/snapshots", middleware.HasPermission("snapshots:read"), chainlink.ValidateNodeExist, snapshot.ListByNode)
+	chainlinkNodes.Get("/:name/snapshots/schedule", middleware.HasPermission("snapshots:read"), chainlink.ValidateNodeExist, snapshot.GetSchedule)
+	chainlinkNodes.Put("/:name/snapshots/schedule", middleware.HasPermission("snapshots:update"), chainlink.ValidateNodeExist, snapshot.UpdateSchedule)
+	chainlinkNodes.Get("/:name/stats", middleware.HasPermission("chainlink.nodes:read"), websocket.New(chainlink.Stats))
+	chainlinkNodes.Get("/:name/jobs", middleware.HasPermission("chainlink.nodes:read"), chainlink.ValidateNodeExist, chainlink.Jobs)
+	chainlinkNodes.Post("/:name/jobs", middleware.HasPermission("chainlink.nodes:update"), chainlink.ValidateNodeExist, chainlink.CreateJob)
+	chainlinkNodes.Delete("/:name/jobs/:id", middleware.HasPermission("chainlink.nodes:update"), chainlink.ValidateNodeExist, chainlink.DeleteJob)
+	chainlinkNodes.Get("/:name/jobs/:id/runs", middleware.HasPermission("chainlink.nodes:read"), chainlink.ValidateNodeExist, chainlink.JobRuns)
+	chainlinkNodes.Post("/:name/jobs/:id/runs", middleware.HasPermission("chainlink.nodes:update"), chainlink.ValidateNodeExist, chainlink.RunJob)
+	chainlinkNodes.Get("/:name/bridges", middleware.HasPermission("chainlink.nodes:read"), chainlink.ValidateNodeExist, chainlink.Bridges)
+	chainlinkNodes.Post("/:name/bridges", middleware.HasPermission("chainlink.nodes:update"), chainlink.ValidateNodeExist, chainlink.CreateBridge)
+	chainlinkNodes.Delete("/:name/bridges/:bridge", middleware.HasPermission("chainlink.nodes:update"), chainlink.ValidateNodeExist, chainlink.DeleteBridge)
+	chainlinkNodes.Put("/:name", middleware.HasPermission("chainlink.nodes:update"), chainlink.ValidateNodeExist, chainlink.Update)
+	chainlinkNodes.Delete("/:name", middleware.HasPermission("chainlink.nodes:delete"), chainlink.ValidateNodeExist, chainlink.Delete)
 
 	//ethereum group
 	ethereumGroup := v1.Group("ethereum", middleware.JWTProtected, middleware.TFAProtected, middleware.WorkspaceProtected, middleware.ValidateWorkspaceMembership)
 	ethereumNodes := ethereumGroup.Group("nodes")
-	ethereumNodes.Post("/", middleware.IsWriter, ethereum.Create)
-	ethereumNodes.Head("/", middleware.IsReader, ethereum.Count)
-	ethereumNodes.Get("/", middleware.IsReader, ethereum.List)
-	ethereumNodes.Get("/:name", middleware.IsReader, ethereum.ValidateNodeExist, ethereum.Get)
-	ethereumNodes.Get("/:name/logs", middleware.IsReader, websocket.New(shared.Logger))
-	ethereumNodes.Get("/:name/status", middleware.IsReader, websocket.New(shared.Status))
-	ethereumNodes.Get("/:name/metrics", middleware.IsReader, websocket.New(shared.Metrics))
-	ethereumNodes.Get("/:name/stats", middleware.IsReader, websocket.New(ethereum.Stats))
-	ethereumNodes.Put("/:name", middleware.IsWriter, ethereum.ValidateNodeExist, ethereum.Update)
-	ethereumNodes.Delete("/:name", middleware.IsAdmin, ethereum.ValidateNodeExist, ethereum.Delete)
+	ethereumNodes.Post("/", middleware.HasPermission("ethereum.nodes:create"), secret.Materialize, snapshot.Restore, ethereum.Create)
+	ethereumNodes.Head("/", middleware.HasPermission("ethereum.nodes:read"), ethereum.Count)
+	ethereumNodes.Get("/", middleware.HasPermission("ethereum.nodes:read"), ethereum.List)
+	ethereumNodes.Get("/:name", middleware.HasPermission("ethereum.nodes:read"), ethereum.ValidateNodeExist, ethereum.Get)
+	ethereumNodes.Get("/:name/logs", middleware.HasPermission("ethereum.nodes:read"), websocket.New(shared.Logger))
+	ethereumNodes.Get("/:name/status", middleware.HasPermission("ethereum.nodes:read"), websocket.New(shared.Status))
+	ethereumNodes.Get("/:name/metrics", middleware.HasPermission("ethereum.nodes:read"), websocket.New(shared.Metrics))
+	ethereumNodes.Get("/:name/stats", middleware.HasPermission("ethereum.nodes:read"), websocket.New(ethereum.Stats))
+	ethereumNodes.Get("/:name/storage", middleware.HasPermission("ethereum.nodes:read"), ethereum.ValidateNodeExist, storage_class.NodeVolume)
+	ethereumNodes.Post("/:name/storage/expand", middleware.HasPermission("ethereum.nodes:update"), ethereum.ValidateNodeExist, ethereum.ExpandStorage)
+	ethereumNodes.Post("/:name/snapshots", middleware.HasPermission("snapshots:create"), ethereum.ValidateNodeExist, snapshot.Create)
+	ethereumNodes.Get("/:name/snapshots", middleware.HasPermission("snapshots:read"), ethereum.ValidateNodeExist, snapshot.ListByNode)
+	ethereumNodes.Get("/:name/snapshots/schedule", middleware.HasPermission("snapshots:read"), ethereum.ValidateNodeExist, snapshot.GetSchedule)
+	ethereumNodes.Put("/:name/snapshots/schedule", middleware.HasPermission("snapshots:update"), ethereum.ValidateNodeExist, snapshot.UpdateSchedule)
+	ethereumNodes.Put("/:name", middleware.HasPermission("ethereum.nodes:update"), ethereum.ValidateNodeExist, ethereum.Update)
+	ethereumNodes.Delete("/:name", middleware.HasPermission("ethereum.nodes:delete"), ethereum.ValidateNodeExist, ethereum.Delete)
+	ethereumFullNodes := ethereumGroup.Group("full-nodes")
+	ethereumFullNodes.Post("/", middleware.HasPermission("ethereum.nodes:create"), middleware.HasPermission("ethereum2.beaconnodes:create"), middleware.HasPermission("secrets:create"), full_node.Create)
+	ethereumFullNodes.Get("/", middleware.HasPermission("ethereum.nodes:read"), middleware.HasPermission("ethereum2.beaconnodes:read"), full_node.List)
+	ethereumFullNodes.Get("/:name", middleware.HasPermission("ethereum.nodes:read"), middleware.HasPermission("ethereum2.beaconnodes:read"), full_node.ValidateFullNodeExist, full_node.Get)
+	ethereumFullNodes.Delete("/:name", middleware.HasPermission("ethereum.nodes:delete"), middleware.HasPermission("ethereum2.beaconnodes:delete"), middleware.HasPermission("secrets:delete"), full_node.ValidateFullNodeExist, full_node.Delete)
 
 	//core group
 	coreGroup := v1.Group("core", middleware.JWTProtected, middleware.TFAProtected, middleware.WorkspaceProtected, middleware.ValidateWorkspaceMembership)
 	//secret group
 	secrets := coreGroup.Group("secrets")
-	secrets.Post("/", middleware.IsWriter, secret.Create)
-	secrets.Head("/", middleware.IsReader, secret.Count)
-	secrets.Get("/", middleware.IsReader, secret.List)
-	secrets.Get("/:name", middleware.IsReader, secret.ValidateSecretExist, secret.Get)
-	secrets.Put("/:name", middleware.IsWriter, secret.ValidateSecretExist, secret.Update)
-	secrets.Delete("/:name", middleware.IsAdmin, secret.ValidateSecretExist, secret.Delete)
+	secrets.Post("/", middleware.HasPermission("secrets:create"), secret.Create)
+	secrets.Post("/generate", middleware.HasPermission("secrets:create"), secret.Generate)
+	secrets.Head("/", middleware.HasPermission("secrets:read"), secret.Count)
+	secrets.Get("/", middleware.HasPermission("secrets:read"), secret.List)
+	secrets.Get("/:name", middleware.HasPermission("secrets:read"), secret.ValidateSecretExist, secret.Get)
+	secrets.Get("/:name/references", middleware.HasPermission("secrets:read"), secret.ValidateSecretExist, secret.References)
+	secrets.Get("/:name/reveal", middleware.IsWorkspaceAdmin, secret.ValidateSecretExist, secret.Reveal)
+	secrets.Put("/:name", middleware.HasPermission("secrets:update"), secret.ValidateSecretExist, secret.Update)
+	secrets.Delete("/:name", middleware.HasPermission("secrets:delete"), secret.ValidateSecretExist, secret.Delete)
 	//storage class group
 	storageClasses := coreGroup.Group("storageclasses", middleware.JWTProtected, middleware.TFAProtected, middleware.WorkspaceProtected, middleware.ValidateWorkspaceMembership)
-	storageClasses.Post("/", middleware.IsWriter, storage_class.Create)
-	storageClasses.Get("/", middleware.IsReader, storage_class.List)
-	storageClasses.Get("/:name", middleware.IsReader, storage_class.ValidateStorageClassExist, storage_class.Get)
-	storageClasses.Put("/:name", middleware.IsWriter, storage_class.ValidateStorageClassExist, storage_class.Update)
-	storageClasses.Delete("/:name", middleware.IsAdmin, storage_class.ValidateStorageClassExist, storage_class.Delete)
+	storageClasses.Post("/", middleware.IsPlatformAdmin, storage_class.Create)
+	storageClasses.Get("/", middleware.HasPermission("storageclasses:read"), storage_class.List)
+	storageClasses.Get("/:name", middleware.HasPermission("storageclasses:read"), storage_class.ValidateStorageClassExist, storage_class.Get)
+	storageClasses.Put("/:name", middleware.IsPlatformAdmin, storage_class.ValidateStorageClassExist, storage_class.Update)
+	storageClasses.Delete("/:name", middleware.IsPlatformAdmin, storage_class.ValidateStorageClassExist, storage_class.Delete)
+
+	//volumes group
+	volumes := coreGroup.Group("volumes", middleware.JWTProtected, middleware.TFAProtected, middleware.WorkspaceProtected, middleware.ValidateWorkspaceMembership)
+	volumes.Get("/", middleware.HasPermission("storageclasses:read"), storage_class.Volumes)
+
+	//bulk group
+	bulkGroup := coreGroup.Group("bulk")
+	bulkGroup.Post("/", middleware.LoadPermissions, bulk.Create)
+	bulkGroup.Get("/:id", bulk.Get)
+
+	//snapshots group
+	snapshots := coreGroup.Group("snapshots")
+	snapshots.Get("/", middleware.HasPermission("snapshots:read"), snapshot.List)
+	snapshots.Get("/:name", middleware.HasPermission("snapshots:read"), snapshot.ValidateSnapshotExist, snapshot.Get)
+	snapshots.Delete("/:name", middleware.HasPermission("snapshots:delete"), snapshot.ValidateSnapshotExist, snapshot.Delete)
 
 	//ethereum2 group
 	ethereum2 := v1.Group("ethereum2", middleware.JWTProtected, middleware.TFAProtected, middleware.WorkspaceProtected, middleware.ValidateWorkspaceMembership)
 	//beaconnodes group
 	beaconnodesGroup := ethereum2.Group("beaconnodes")
-	beaconnodesGroup.Post("/", middleware.IsWriter, beacon_node.Create)
-	beaconnodesGroup.Head("/", middleware.IsReader, beacon_node.Count)
-	beaconnodesGroup.Get("/", middleware.IsReader, beacon_node.List)
-	beaconnodesGroup.Get("/:name", middleware.IsReader, beacon_node.ValidateBeaconNodeExist, beacon_node.Get)
-	beaconnodesGroup.Get("/:name/logs", middleware.IsReader, websocket.New(shared.Logger))
-	beaconnodesGroup.Get("/:name/status", middleware.IsReader, websocket.New(shared.Status))
-	beaconnodesGroup.Get("/:name/metrics", middleware.IsReader, websocket.New(shared.Metrics))
-	beaconnodesGroup.Get("/:name/stats", middleware.IsReader, websocket.New(beacon_node.Stats))
-	beaconnodesGroup.Put("/:name", middleware.IsWriter, beacon_node.ValidateBeaconNodeExist, beacon_node.Update)
-	beaconnodesGroup.Delete("/:name", middleware.IsAdmin, beacon_node.ValidateBeaconNodeExist, beacon_node.Delete)
+	beaconnodesGroup.Post("/", middleware.HasPermission("ethereum2.beaconnodes:create"), secret.Materialize, snapshot.Restore, beacon_node.Create)
+	beaconnodesGroup.Head("/", middleware.HasPermission("ethereum2.beaconnodes:read"), beacon_node.Count)
+	beaconnodesGroup.Get("/", middleware.HasPermission("ethereum2.beaconnodes:read"), beacon_node.List)
+	beaconnodesGroup.Get("/:name", middleware.HasPermission("ethereum2.beaconnodes:read"), beacon_node.ValidateBeaconNodeExist, beacon_node.Get)
+	beaconnodesGroup.Get("/:name/logs", middleware.HasPermission("ethereum2.beaconnodes:read"), websocket.New(shared.Logger))
+	beaconnodesGroup.Get("/:name/status", middleware.HasPermission("ethereum2.beaconnodes:read"), websocket.New(shared.Status))
+	beaconnodesGroup.Get("/:name/metrics", middleware.HasPermission("ethereum2.beaconnodes:read"), websocket.New(shared.Metrics))
+	beaconnodesGroup.Get("/:name/stats", middleware.HasPermission("ethereum2.beaconnodes:read"), websocket.New(beacon_node.Stats))
+	beaconnodesGroup.Get("/:name/storage", middleware.HasPermission("ethereum2.beaconnodes:read"), beacon_node.ValidateBeaconNodeExist, storage_class.NodeVolume)
+	beaconnodesGroup.Post("/:name/storage/expand", middleware.HasPermission("ethereum2.beaconnodes:update"), beacon_node.ValidateBeaconNodeExist, beacon_node.ExpandStorage)
+	beaconnodesGroup.Post("/:name/snapshots", middleware.HasPermission("snapshots:create"), beacon_node.ValidateBeaconNodeExist, snapshot.Create)
+	beaconnodesGroup.Get("/:name/snapshots", middleware.HasPermission("snapshots:read"), beacon_node.ValidateBeaconNodeExist, snapshot.ListByNode)
+	beaconnodesGroup.Get("/:name/snapshots/schedule", middleware.HasPermission("snapshots:read"), beacon_node.ValidateBeaconNodeExist, snapshot.GetSchedule)
+	beaconnodesGroup.Put("/:name/snapshots/schedule", middleware.HasPermission("snapshots:update"), beacon_node.ValidateBeaconNodeExist, snapshot.UpdateSchedule)
+	beaconnodesGroup.Put("/:name", middleware.HasPermission("ethereum2.beaconnodes:update"), beacon_node.ValidateBeaconNodeExist, beacon_node.Update)
+	beaconnodesGroup.Delete("/:name", middleware.HasPermission("ethereum2.beaconnodes:delete"), beacon_node.ValidateBeaconNodeExist, beacon_node.Delete)
 	//validators group
 	validatorsGroup := ethereum2.Group("validators", middleware.JWTProtected, middleware.TFAProtected, middleware.WorkspaceProtected, middleware.ValidateWorkspaceMembership)
-	validatorsGroup.Post("/", middleware.IsWriter, validator.Create)
-	validatorsGroup.Head("/", middleware.IsReader, validator.Count)
-	validatorsGroup.Get("/", middleware.IsReader, validator.List)
-	validatorsGroup.Get("/:name", middleware.IsReader, validator.ValidateValidatorExist, validator.Get)
-	validatorsGroup.Get("/:name/logs", middleware.IsReader, websocket.New(shared.Logger))
-	validatorsGroup.Get("/:name/status", middleware.IsReader, websocket.New(shared.Status))
-	validatorsGroup.Get("/:name/metrics", middleware.IsReader, websocket.New(shared.Metrics))
-	validatorsGroup.Put("/:name", middleware.IsWriter, validator.ValidateValidatorExist, validator.Update)
-	validatorsGroup.Delete("/:name", middleware.IsAdmin, validator.ValidateValidatorExist, validator.Delete)
+	validatorsGroup.Post("/", middleware.HasPermission("ethereum2.validators:create"), secret.Materialize, snapshot.Restore, validator.Create)
+	validatorsGroup.Head("/", middleware.HasPermission("ethereum2.validators:read"), validator.Count)
+	validatorsGroup.Get("/", middleware.HasPermission("ethereum2.validators:read"), validator.List)
+	validatorsGroup.Get("/:name", middleware.HasPermission("ethereum2.validators:read"), validator.ValidateValidatorExist, validator.Get)
+	validatorsGroup.Get("/:name/logs", middleware.HasPermission("ethereum2.validators:read"), websocket.New(shared.Logger))
+	validatorsGroup.Get("/:name/status", middleware.HasPermission("ethereum2.validators:read"), websocket.New(shared.Status))
+	validatorsGroup.Get("/:name/metrics", middleware.HasPermission("ethereum2.validators:read"), websocket.New(shared.Metrics))
+	validatorsGroup.Get("/:name/storage", middleware.HasPermission("ethereum2.validators:read"), validator.ValidateValidatorExist, storage_class.NodeVolume)
+	validatorsGroup.Post("/:name/storage/expand", middleware.HasPermission("ethereum2.validators:update"), validator.ValidateValidatorExist, validator.ExpandStorage)
+	validatorsGroup.Post("/:name/snapshots", middleware.HasPermission("snapshots:create"), validator.ValidateValidatorExist, snapshot.Create)
+	validatorsGroup.Get("/:name/snapshots", middleware.HasPermission("snapshots:read"), validator.ValidateValidatorExist, snapshot.ListByNode)
+	validatorsGroup.Get("/:name/snapshots/schedule", middleware.HasPermission("snapshots:read"), validator.ValidateValidatorExist, snapshot.GetSchedule)
+	validatorsGroup.Put("/:name/snapshots/schedule", middleware.HasPermission("snapshots:update"), validator.ValidateValidatorExist, snapshot.UpdateSchedule)
+	validatorsGroup.Get("/:name/performance", middleware.HasPermission("ethereum2.validators:read"), validator.ValidateValidatorExist, performance.Get)
+	validatorsGroup.Get("/:name/performance/history", middleware.HasPermission("ethereum2.validators:read"), validator.ValidateValidatorExist, performance.History)
+	validatorsGroup.Get("/:name/performance/stats", middleware.HasPermission("ethereum2.validators:read"), websocket.New(performance.Stats))
+	validatorsGroup.Put("/:name/keymanager", middleware.HasPermission("ethereum2.validators:update"), validator.ValidateValidatorExist, validator.UpdateKeymanager)
+	validatorsGroup.Get("/:name/keys", middleware.HasPermission("ethereum2.validators:read"), validator.ValidateValidatorExist, validator.Keys)
+	validatorsGroup.Post("/:name/keys", middleware.HasPermission("ethereum2.validators:update"), validator.ValidateValidatorExist, validator.ImportKeys)
+	validatorsGroup.Delete("/:name/keys", middleware.HasPermission("ethereum2.validators:update"), validator.ValidateValidatorExist, validator.RemoveKeys)
+	validatorsGroup.Put("/:name", middleware.HasPermission("ethereum2.validators:update"), validator.ValidateValidatorExist, validator.Update)
+	validatorsGroup.Delete("/:name", middleware.HasPermission("ethereum2.validators:delete"), validator.ValidateValidatorExist, validator.Delete)
 
 	//filecoin group
 	filecoinGroup := v1.Group("filecoin", middleware.JWTProtected, middleware.TFAProtected, middleware.WorkspaceProtected, middleware.ValidateWorkspaceMembership)
 	filecoinNodes := filecoinGroup.Group("nodes")
-	filecoinNodes.Post("/", middleware.IsWriter, filecoin.Create)
-	filecoinNodes.Head("/", middleware.IsReader, filecoin.Count)
-	filecoinNodes.Get("/", middleware.IsReader, filecoin.List)
-	filecoinNodes.Get("/:name", middleware.IsReader, filecoin.ValidateNodeExist, filecoin.Get)
-	filecoinNodes.Get("/:name/logs", middleware.IsReader, websocket.New(shared.Logger))
-	filecoinNodes.Get("/:name/status", middleware.IsReader, websocket.New(shared.Status))
-	filecoinNodes.Get("/:name/metrics", middleware.IsReader, websocket.New(shared.Metrics))
-	filecoinNodes.Put("/:name", middleware.IsWriter, filecoin.ValidateNodeExist, filecoin.Update)
-	filecoinNodes.Delete("/:name", middleware.IsAdmin, filecoin.ValidateNodeExist, filecoin.Delete)
+	filecoinNodes.Post("/", middleware.HasPermission("filecoin.nodes:create"), secret.Materialize, snapshot.Restore, filecoin.Create)
+	filecoinNodes.Head("/", middleware.HasPermission("filecoin.nodes:read"), filecoin.Count)
+	filecoinNodes.Get("/", middleware.HasPermission("filecoin.nodes:read"), filecoin.List)
+	filecoinNodes.Get("/:name", middleware.HasPermission("filecoin.nodes:read"), filecoin.ValidateNodeExist, filecoin.Get)
+	filecoinNodes.Get("/:name/logs", middleware.HasPermission("filecoin.nodes:read"), websocket.New(shared.Logger))
+	filecoinNodes.Get("/:name/status", middleware.HasPermission("filecoin.nodes:read"), websocket.New(shared.Status))
+	filecoinNodes.Get("/:name/metrics", middleware.HasPermission("filecoin.nodes:read"), websocket.New(shared.Metrics))
+	filecoinNodes.Get("/:name/storage", middleware.HasPermission("filecoin.nodes:read"), filecoin.ValidateNodeExist, storage_class.NodeVolume)
+	filecoinNodes.Post("/:name/storage/expand", middleware.HasPermission("filecoin.nodes:update"), filecoin.ValidateNodeExist, filecoin.ExpandStorage)
+	filecoinNodes.Post("/:name/snapshots", middleware.HasPermission("snapshots:create"), filecoin.ValidateNodeExist, snapshot.Create)
+	filecoinNodes.Get("/:name/snapshots", middleware.HasPermission("snapshots:read"), filecoin.ValidateNodeExist, snapshot.ListByNode)
+	filecoinNodes.Get("/:name/snapshots/schedule", middleware.HasPermission("snapshots:read"), filecoin.ValidateNodeExist, snapshot.GetSchedule)
+	filecoinNodes.Put("/:name/snapshots/schedule", middleware.HasPermission("snapshots:update"), filecoin.ValidateNodeExist, snapshot.UpdateSchedule)
+	filecoinNodes.Put("/:name", middleware.HasPermission("filecoin.nodes:update"), filecoin.ValidateNodeExist, filecoin.Update)
+	filecoinNodes.Delete("/:name", middleware.HasPermission("filecoin.nodes:delete"), filecoin.ValidateNodeExist, filecoin.Delete)
 
 	//ipfs group
 	ipfsGroup := v1.Group("ipfs", middleware.JWTProtected, middleware.TFAProtected, middleware.WorkspaceProtected, middleware.ValidateWorkspaceMembership)
 	//ipfs peer group
 	ipfsPeersGroup := ipfsGroup.Group("peers")
-	ipfsPeersGroup.Post("/", middleware.IsWriter, ipfs_peer.Create)
-	ipfsPeersGroup.Head("/", middleware.IsReader, ipfs_peer.Count)
-	ipfsPeersGroup.Get("/", middleware.IsReader, ipfs_peer.List)
-	ipfsPeersGroup.Get("/:name", middleware.IsReader, ipfs_peer.ValidatePeerExist, ipfs_peer.Get)
-	ipfsPeersGroup.Get("/:name/logs", middleware.IsReader, websocket.New(shared.Logger))
-	ipfsPeersGroup.Get("/:name/status", middleware.IsReader, websocket.New(shared.Status))
-	ipfsPeersGroup.Get("/:name/metrics", middleware.IsReader, websocket.New(shared.Metrics))
-	ipfsPeersGroup.Get("/:name/stats", middleware.IsReader, websocket.New(ipfs_peer.Stats))
-	ipfsPeersGroup.Put("/:name", middleware.IsWriter, ipfs_peer.ValidatePeerExist, ipfs_peer.Update)
-	ipfsPeersGroup.Delete("/:name", middleware.IsAdmin, ipfs_peer.ValidatePeerExist, ipfs_peer.Delete)
+	ipfsPeersGroup.Post("/", middleware.HasPermission("ipfs.peers:create"), secret.Materialize, snapshot.Restore, ipfs_peer.Create)
+	ipfsPeersGroup.Head("/", middleware.HasPermission("ipfs.peers:read"), ipfs_peer.Count)
+	ipfsPeersGroup.Get("/", middleware.HasPermission("ipfs.peers:read"), ipfs_peer.List)
+	ipfsPeersGroup.Get("/:name", middleware.HasPermission("ipfs.peers:read"), ipfs_peer.ValidatePeerExist, ipfs_peer.Get)
+	ipfsPeersGroup.Get("/:name/logs", middleware.HasPermission("ipfs.peers:read"), websocket.New(shared.Logger))
+	ipfsPeersGroup.Get("/:name/status", middleware.HasPermission("ipfs.peers:read"), websocket.New(shared.Status))
+	ipfsPeersGroup.Get("/:name/metrics", middleware.HasPermission("ipfs.peers:read"), websocket.New(shared.Metrics))
+	ipfsPeersGroup.Get("/:name/stats", middleware.HasPermission("ipfs.peers:read"), websocket.New(ipfs_peer.Stats))
+	ipfsPeersGroup.Get("/:name/storage", middleware.HasPermission("ipfs.peers:read"), ipfs_peer.ValidatePeerExist, storage_class.NodeVolume)
+	ipfsPeersGroup.Post("/:name/storage/expand", middleware.HasPermission("ipfs.peers:update"), ipfs_peer.ValidatePeerExist, ipfs_peer.ExpandStorage)
+	ipfsPeersGroup.Post("/:name/snapshots", middleware.HasPermission("snapshots:create"), ipfs_peer.ValidatePeerExist, snapshot.Create)
+	ipfsPeersGroup.Get("/:name/snapshots", middleware.HasPermission("snapshots:read"), ipfs_peer.ValidatePeerExist, snapshot.ListByNode)
+	ipfsPeersGroup.Get("/:name/snapshots/schedule", middleware.HasPermission("snapshots:read"), ipfs_peer.ValidatePeerExist, snapshot.GetSchedule)
+	ipfsPeersGroup.Put("/:name/snapshots/schedule", middleware.HasPermission("snapshots:update"), ipfs_peer.ValidatePeerExist, snapshot.UpdateSchedule)
+	ipfsPeersGroup.Get("/:name/pins", middleware.HasPermission("ipfs.peers:read"), ipfs_peer.ValidatePeerExist, ipfs_peer.Pins)
+	ipfsPeersGroup.Post("/:name/pins", middleware.HasPermission("ipfs.peers:update"), ipfs_peer.ValidatePeerExist, ipfs_peer.Pin)
+	ipfsPeersGroup.Get("/:name/pins/:cid", middleware.HasPermission("ipfs.peers:read"), ipfs_peer.ValidatePeerExist, ipfs_peer.PinStatus)
+	ipfsPeersGroup.Delete("/:name/pins/:cid", middleware.HasPermission("ipfs.peers:update"), ipfs_peer.ValidatePeerExist, ipfs_peer.Unpin)
+	ipfsPeersGroup.Put("/:name", middleware.HasPermission("ipfs.peers:update"), ipfs_peer.ValidatePeerExist, ipfs_peer.Update)
+	ipfsPeersGroup.Delete("/:name", middleware.HasPermission("ipfs.peers:delete"), ipfs_peer.ValidatePeerExist, ipfs_peer.Delete)
 	//ipfs peer group
 	clusterpeersGroup := ipfsGroup.Group("clusterpeers", middleware.JWTProtected, middleware.TFAProtected, middleware.WorkspaceProtected, middleware.ValidateWorkspaceMembership)
-	clusterpeersGroup.Post("/", middleware.IsWriter, ipfs_cluster_peer.Create)
-	clusterpeersGroup.Head("/", middleware.IsReader, ipfs_cluster_peer.Count)
-	clusterpeersGroup.Get("/", middleware.IsReader, ipfs_cluster_peer.List)
-	clusterpeersGroup.Get("/:name", middleware.IsReader, ipfs_cluster_peer.ValidateClusterPeerExist, ipfs_cluster_peer.Get)
-	clusterpeersGroup.Get("/:name/logs", middleware.IsReader, websocket.New(shared.Logger))
-	clusterpeersGroup.Get("/:name/status", middleware.IsReader, websocket.New(shared.Status))
-	clusterpeersGroup.Get("/:name/metrics", middleware.IsReader, websocket.New(shared.Metrics))
-	clusterpeersGroup.Put("/:name", middleware.IsWriter, ipfs_cluster_peer.ValidateClusterPeerExist, ipfs_cluster_peer.Update)
-	clusterpeersGroup.Delete("/:name", middleware.IsAdmin, ipfs_cluster_peer.ValidateClusterPeerExist, ipfs_cluster_peer.Delete)
+	clusterpeersGroup.Post("/", middleware.HasPermission("ipfs.clusterpeers:create"), secret.Materialize, snapshot.Restore, ipfs_cluster_peer.Create)
+	clusterpeersGroup.Head("/", middleware.HasPermission("ipfs.clusterpeers:read"), ipfs_cluster_peer.Count)
+	clusterpeersGroup.Get("/", middleware.HasPermission("ipfs.clusterpeers:read"), ipfs_cluster_peer.List)
+	clusterpeersGroup.Get("/:name", middleware.HasPermission("ipfs.clusterpeers:read"), ipfs_cluster_peer.ValidateClusterPeerExist, ipfs_cluster_peer.Get)
+	clusterpeersGroup.Get("/:name/logs", middleware.HasPermission("ipfs.clusterpeers:read"), websocket.New(shared.Logger))
+	clusterpeersGroup.Get("/:name/status", middleware.HasPermission("ipfs.clusterpeers:read"), websocket.New(shared.Status))
+	clusterpeersGroup.Get("/:name/metrics", middleware.HasPermission("ipfs.clusterpeers:read"), websocket.New(shared.Metrics))
+	clusterpeersGroup.Get("/:name/storage", middleware.HasPermission("ipfs.clusterpeers:read"), ipfs_cluster_peer.ValidateClusterPeerExist, storage_class.NodeVolume)
+	clusterpeersGroup.Post("/:name/storage/expand", middleware.HasPermission("ipfs.clusterpeers:update"), ipfs_cluster_peer.ValidateClusterPeerExist, ipfs_cluster_peer.ExpandStorage)
+	clusterpeersGroup.Post("/:name/snapshots", middleware.HasPermission("snapshots:create"), ipfs_cluster_peer.ValidateClusterPeerExist, snapshot.Create)
+	clusterpeersGroup.Get("/:name/snapshots", middleware.HasPermission("snapshots:read"), ipfs_cluster_peer.ValidateClusterPeerExist, snapshot.ListByNode)
+	clusterpeersGroup.Get("/:name/snapshots/schedule", middleware.HasPermission("snapshots:read"), ipfs_cluster_peer.ValidateClusterPeerExist, snapshot.GetSchedule)
+	clusterpeersGroup.Put("/:name/snapshots/schedule", middleware.HasPermission("snapshots:update"), ipfs_cluster_peer.ValidateClusterPeerExist, snapshot.UpdateSchedule)
+	clusterpeersGroup.Get("/:name/pins", middleware.HasPermission("ipfs.clusterpeers:read"), ipfs_cluster_peer.ValidateClusterPeerExist, ipfs_cluster_peer.Pins)
+	clusterpeersGroup.Post("/:name/pins", middleware.HasPermission("ipfs.clusterpeers:update"), ipfs_cluster_peer.ValidateClusterPeerExist, ipfs_cluster_peer.Pin)
+	clusterpeersGroup.Get("/:name/pins/:cid", middleware.HasPermission("ipfs.clusterpeers:read"), ipfs_cluster_peer.ValidateClusterPeerExist, ipfs_cluster_peer.PinStatus)
+	clusterpeersGroup.Delete("/:name/pins/:cid", middleware.HasPermission("ipfs.clusterpeers:update"), ipfs_cluster_peer.ValidateClusterPeerExist, ipfs_cluster_peer.Unpin)
+	clusterpeersGroup.Put("/:name", middleware.HasPermission("ipfs.clusterpeers:update"), ipfs_cluster_peer.ValidateClusterPeerExist, ipfs_cluster_peer.Update)
+	clusterpeersGroup.Delete("/:name", middleware.HasPermission("ipfs.clusterpeers:delete"), ipfs_cluster_peer.ValidateClusterPeerExist, ipfs_cluster_peer.Delete)
+	//ipfs cluster group
+	ipfsClusters := ipfsGroup.Group("clusters")
+	ipfsClusters.Post("/", middleware.HasPermission("ipfs.peers:create"), middleware.HasPermission("ipfs.clusterpeers:create"), middleware.HasPermission("secrets:create"), ipfs_cluster.Create)
+	ipfsClusters.Get("/", middleware.HasPermission("ipfs.peers:read"), middleware.HasPermission("ipfs.clusterpeers:read"), ipfs_cluster.List)
+	ipfsClusters.Get("/:name", middleware.HasPermission("ipfs.peers:read"), middleware.HasPermission("ipfs.clusterpeers:read"), ipfs_cluster.ValidateClusterExist, ipfs_cluster.Get)
+	ipfsClusters.Get("/:name/topology", middleware.HasPermission("ipfs.peers:read"), middleware.HasPermission("ipfs.clusterpeers:read"), ipfs_cluster.ValidateClusterExist, ipfs_cluster.Topology)
+	ipfsClusters.Put("/:name/scale", middleware.HasPermission("ipfs.peers:create"), middleware.HasPermission("ipfs.peers:delete"), middleware.HasPermission("ipfs.clusterpeers:create"), middleware.HasPermission("ipfs.clusterpeers:update"), middleware.HasPermission("ipfs.clusterpeers:delete"), middleware.HasPermission("secrets:create"), middleware.HasPermission("secrets:delete"), ipfs_cluster.ValidateClusterExist, ipfs_cluster.Scale)
+	ipfsClusters.Delete("/:name", middleware.HasPermission("ipfs.peers:delete"), middleware.HasPermission("ipfs.clusterpeers:delete"), middleware.HasPermission("secrets:delete"), ipfs_cluster.ValidateClusterExist, ipfs_cluster.Delete)
 
 	//near group
 	nearGroup := v1.Group("near", middleware.JWTProtected, middleware.TFAProtected, middleware.WorkspaceProtected, middleware.ValidateWorkspaceMembership)
 	nearNodesGroup := nearGroup.Group("nodes")
-	nearNodesGroup.Post("/", middleware.IsWriter, near.Create)
-	nearNodesGroup.Head("/", middleware.IsReader, near.Count)
-	nearNodesGroup.Get("/", middleware.IsReader, near.List)
-	nearNodesGroup.Get("/:name", middleware.IsReader, near.ValidateNodeExist, near.Get)
-	nearNodesGroup.Get("/:name/logs", middleware.IsReader, websocket.New(shared.Logger))
-	nearNodesGroup.Get("/:name/status", middleware.IsReader, websocket.New(shared.Status))
-	nearNodesGroup.Get("/:name/metrics", middleware.IsReader, websocket.New(shared.Metrics))
-	nearNodesGroup.Get("/:name/stats", middleware.IsReader, websocket.New(near.Stats))
-	nearNodesGroup.Put("/:name", middleware.IsWriter, near.ValidateNodeExist, near.Update)
-	nearNodesGroup.Delete("/:name", middleware.IsAdmin, near.ValidateNodeExist, near.Delete)
+	nearNodesGroup.Post("/", middleware.HasPermission("near.nodes:create"), secret.Materialize, snapshot.Restore, near.Create)
+	nearNodesGroup.Head("/", middleware.HasPermission("near.nodes:read"), near.Count)
+	nearNodesGroup.Get("/", middleware.HasPermission("near.nodes:read"), near.List)
+	nearNodesGroup.Get("/:name", middleware.HasPermission("near.nodes:read"), near.ValidateNodeExist, near.Get)
+	nearNodesGroup.Get("/:name/logs", middleware.HasPermission("near.nodes:read"), websocket.New(shared.Logger))
+	nearNodesGroup.Get("/:name/status", middleware.HasPermission("near.nodes:read"), websocket.New(shared.Status))
+	nearNodesGroup.Get("/:name/metrics", middleware.HasPermission("near.nodes:read"), websocket.New(shared.Metrics))
+	nearNodesGroup.Get("/:name/stats", middleware.HasPermission("near.nodes:read"), websocket.New(near.Stats))
+	nearNodesGroup.Get("/:name/storage", middleware.HasPermission("near.nodes:read"), near.ValidateNodeExist, storage_class.NodeVolume)
+	nearNodesGroup.Post("/:name/storage/expand", middleware.HasPermission("near.nodes:update"), near.ValidateNodeExist, near.ExpandStorage)
+	nearNodesGroup.Post("/:name/snapshots", middleware.HasPermission("snapshots:create"), near.ValidateNodeExist, snapshot.Create)
+	nearNodesGroup.Get("/:name/snapshots", middleware.HasPermission("snapshots:read"), near.ValidateNodeExist, snapshot.ListByNode)
+	nearNodesGroup.Get("/:name/snapshots/schedule", middleware.HasPermission("snapshots:read"), near.ValidateNodeExist, snapshot.GetSchedule)
+	nearNodesGroup.Put("/:name/snapshots/schedule", middleware.HasPermission("snapshots:update"), near.ValidateNodeExist, snapshot.UpdateSchedule)
+	nearNodesGroup.Put("/:name", middleware.HasPermission("near.nodes:update"), near.ValidateNodeExist, near.Update)
+	nearNodesGroup.Delete("/:name", middleware.HasPermission("near.nodes:delete"), near.ValidateNodeExist, near.Delete)
 
 	polkadotGroup := v1.Group("polkadot", middleware.JWTProtected, middleware.TFAProtected, middleware.WorkspaceProtected, middleware.ValidateWorkspaceMembership)
 	polkadotNodesGroup := polkadotGroup.Group("nodes")
-	polkadotNodesGroup.Post("/", middleware.IsWriter, polkadot.Create)
-	polkadotNodesGroup.Head("/", middleware.IsReader, polkadot.Count)
-	polkadotNodesGroup.Get("/", middleware.IsReader, polkadot.List)
-	polkadotNodesGroup.Get("/:name", middleware.IsReader, polkadot.ValidateNodeExist, polkadot.Get)
-	polkadotNodesGroup.Get("/:name/logs", middleware.IsReader, websocket.New(shared.Logger))
-	polkadotNodesGroup.Get("/:name/status", middleware.IsReader, websocket.New(shared.Status))
-	polkadotNodesGroup.Get("/:name/metrics", middleware.IsReader, websocket.New(shared.Metrics))
-	polkadotNodesGroup.Get("/:name/stats", middleware.IsReader, websocket.New(polkadot.Stats))
-	polkadotNodesGroup.Put("/:name", middleware.IsWriter, polkadot.ValidateNodeExist, polkadot.Update)
-	polkadotNodesGroup.Delete("/:name", middleware.IsAdmin, polkadot.ValidateNodeExist, polkadot.Delete)
+	polkadotNodesGroup.Post("/", middleware.HasPermission("polkadot.nodes:create"), secret.Materialize, snapshot.Restore, polkadot.Create)
+	polkadotNodesGroup.Head("/", middleware.HasPermission("polkadot.nodes:read"), polkadot.Count)
+	polkadotNodesGroup.Get("/", middleware.HasPermission("polkadot.nodes:read"), polkadot.List)
+	polkadotNodesGroup.Get("/:name", middleware.HasPermission("polkadot.nodes:read"), polkadot.ValidateNodeExist, polkadot.Get)
+	polkadotNodesGroup.Get("/:name/logs", middleware.HasPermission("polkadot.nodes:read"), websocket.New(shared.Logger))
+	polkadotNodesGroup.Get("/:name/status", middleware.HasPermission("polkadot.nodes:read"), websocket.New(shared.Status))
+	polkadotNodesGroup.Get("/:name/metrics", middleware.HasPermission("polkadot.nodes:read"), websocket.New(shared.Metrics))
+	polkadotNodesGroup.Get("/:name/stats", middleware.HasPermission("polkadot.nodes:read"), websocket.New(polkadot.Stats))
+	polkadotNodesGroup.Get("/:name/storage", middleware.HasPermission("polkadot.nodes:read"), polkadot.ValidateNodeExist, storage_class.NodeVolume)
+	polkadotNodesGroup.Post("/:name/storage/expand", middleware.HasPermission("polkadot.nodes:update"), polkadot.ValidateNodeExist, polkadot.ExpandStorage)
+	polkadotNodesGroup.Post("/:name/snapshots", middleware.HasPermission("snapshots:create"), polkadot.ValidateNodeExist, snapshot.Create)
+	polkadotNodesGroup.Get("/:name/snapshots", middleware.HasPermission("snapshots:read"), polkadot.ValidateNodeExist, snapshot.ListByNode)
+	polkadotNodesGroup.Get("/:name/snapshots/schedule", middleware.HasPermission("snapshots:read"), polkadot.ValidateNodeExist, snapshot.GetSchedule)
+	polkadotNodesGroup.Put("/:name/snapshots/schedule", middleware.HasPermission("snapshots:update"), polkadot.ValidateNodeExist, snapshot.UpdateSchedule)
+	polkadotNodesGroup.Put("/:name", middleware.HasPermission("polkadot.nodes:update"), polkadot.ValidateNodeExist, polkadot.Update)
+	polkadotNodesGroup.Delete("/:name", middleware.HasPermission("polkadot.nodes:delete"), polkadot.ValidateNodeExist, polkadot.Delete)
 
 	bitcoinGroup := v1.Group("bitcoin", middleware.JWTProtected, middleware.TFAProtected, middleware.WorkspaceProtected, middleware.ValidateWorkspaceMembership)
 	bitcoinNodesGroup := bitcoinGroup.Group("nodes")
-	bitcoinNodesGroup.Post("/", middleware.IsWriter, bitcoin.Create)
-	bitcoinNodesGroup.Head("/", middleware.IsReader, bitcoin.Count)
-	bitcoinNodesGroup.Get("/", middleware.IsReader, bitcoin.List)
-	bitcoinNodesGroup.Get("/:name", middleware.IsReader, bitcoin.ValidateNodeExist, bitcoin.Get)
-	bitcoinNodesGroup.Get("/:name/logs", middleware.IsReader, websocket.New(shared.Logger))
-	bitcoinNodesGroup.Get("/:name/status", middleware.IsReader, websocket.New(shared.Status))
-	bitcoinNodesGroup.Get("/:name/metrics", middleware.IsReader, websocket.New(shared.Metrics))
-	bitcoinNodesGroup.Get("/:name/stats", middleware.IsReader, websocket.New(bitcoin.Stats))
-	bitcoinNodesGroup.Put("/:name", middleware.IsWriter, bitcoin.ValidateNodeExist, bitcoin.Update)
-	bitcoinNodesGroup.Delete("/:name", middleware.IsAdmin, bitcoin.ValidateNodeExist, bitcoin.Delete)
+	bitcoinNodesGroup.Post("/", middleware.HasPermission("bitcoin.nodes:create"), secret.Materialize, snapshot.Restore, bitcoin.Create)
+	bitcoinNodesGroup.Head("/", middleware.HasPermission("bitcoin.nodes:read"), bitcoin.Count)
+	bitcoinNodesGroup.Get("/", middleware.HasPermission("bitcoin.nodes:read"), bitcoin.List)
+	bitcoinNodesGroup.Get("/:name", middleware.HasPermission("bitcoin.nodes:read"), bitcoin.ValidateNodeExist, bitcoin.Get)
+	bitcoinNodesGroup.Get("/:name/logs", middleware.HasPermission("bitcoin.nodes:read"), websocket.New(shared.Logger))
+	bitcoinNodesGroup.Get("/:name/status", middleware.HasPermission("bitcoin.nodes:read"), websocket.New(shared.Status))
+	bitcoinNodesGroup.Get("/:name/metrics", middleware.HasPermission("bitcoin.nodes:read"), websocket.New(shared.Metrics))
+	bitcoinNodesGroup.Get("/:name/stats", middleware.HasPermission("bitcoin.nodes:read"), websocket.New(bitcoin.Stats))
+	bitcoinNodesGroup.Get("/:name/storage", middleware.HasPermission("bitcoin.nodes:read"), bitcoin.ValidateNodeExist, storage_class.NodeVolume)
+	bitcoinNodesGroup.Post("/:name/storage/expand", middleware.HasPermission("bitcoin.nodes:update"), bitcoin.ValidateNodeExist, bitcoin.ExpandStorage)
+	bitcoinNodesGroup.Post("/:name/snapshots", middleware.HasPermission("snapshots:create"), bitcoin.ValidateNodeExist, snapshot.Create)
+	bitcoinNodesGroup.Get("/:name/snapshots", middleware.HasPermission("snapshots:read"), bitcoin.ValidateNodeExist, snapshot.ListByNode)
+	bitcoinNodesGroup.Get("/:name/snapshots/schedule", middleware.HasPermission("snapshots:read"), bitcoin.ValidateNodeExist, snapshot.GetSchedule)
+	bitcoinNodesGroup.Put("/:name/snapshots/schedule", middleware.HasPermission("snapshots:update"), bitcoin.ValidateNodeExist, snapshot.UpdateSchedule)
+	bitcoinNodesGroup.Post("/:name/rpc-users", middleware.HasPermission("bitcoin.nodes:update"), middleware.HasPermission("secrets:create"), bitcoin.ValidateNodeExist, bitcoin.AddRPCUser)
+	bitcoinNodesGroup.Post("/:name/rpc-users/rotate", middleware.HasPermission("bitcoin.nodes:update"), middleware.HasPermission("secrets:create"), bitcoin.ValidateNodeExist, bitcoin.RotateRPCPassword)
+	bitcoinNodesGroup.Delete("/:name/rpc-users/:username", middleware.HasPermission("bitcoin.nodes:update"), middleware.HasPermission("secrets:delete"), bitcoin.ValidateNodeExist, bitcoin.RemoveRPCUser)
+	bitcoinNodesGroup.Get("/:name/wallets", middleware.HasPermission("bitcoin.nodes:read"), bitcoin.ValidateNodeExist, bitcoin.Wallets)
+	bitcoinNodesGroup.Get("/:name/wallets/:wallet/balances", middleware.HasPermission("bitcoin.nodes:read"), bitcoin.ValidateNodeExist, bitcoin.Balances)
+	bitcoinNodesGroup.Post("/:name/wallets/:wallet/addresses", middleware.HasPermission("bitcoin.nodes:update"), bitcoin.ValidateNodeExist, bitcoin.NewAddress)
+	bitcoinNodesGroup.Put("/:name", middleware.HasPermission("bitcoin.nodes:update"), bitcoin.ValidateNodeExist, bitcoin.Update)
+	bitcoinNodesGroup.Delete("/:name", middleware.HasPermission("bitcoin.nodes:delete"), bitcoin.ValidateNodeExist, bitcoin.Delete)
 
 	stacksGroup := v1.Group("stacks", middleware.JWTProtected, middleware.TFAProtected, middleware.WorkspaceProtected, middleware.ValidateWorkspaceMembership)
 	stacksNodesGroup := stacksGroup.Group("nodes")
-	stacksNodesGroup.Post("/", middleware.IsWriter, stacks.Create)
-	stacksNodesGroup.Head("/", middleware.IsReader, stacks.Count)
-	stacksNodesGroup.Get("/", middleware.IsReader, stacks.List)
-	stacksNodesGroup.Get("/:name", middleware.IsReader, stacks.ValidateNodeExist, stacks.Get)
-	stacksNodesGroup.Get("/:name/logs", middleware.IsReader, websocket.New(shared.Logger))
-	stacksNodesGroup.Get("/:name/status", middleware.IsReader, websocket.New(shared.Status))
-	stacksNodesGroup.Get("/:name/metrics", middleware.IsReader, websocket.New(shared.Metrics))
-	stacksNodesGroup.Put("/:name", middleware.IsWriter, stacks.ValidateNodeExist, stacks.Update)
-	stacksNodesGroup.Delete("/:name", middleware.IsAdmin, stacks.ValidateNodeExist, stacks.Delete)
+	stacksNodesGroup.Post("/", middleware.HasPermission("stacks.nodes:create"), secret.Materialize, snapshot.Restore, stacks.Create)
+	stacksNodesGroup.Head("/", middleware.HasPermission("stacks.nodes:read"), stacks.Count)
+	stacksNodesGroup.Get("/", middleware.HasPermission("stacks.nodes:read"), stacks.List)
+	stacksNodesGroup.Get("/:name", middleware.HasPermission("stacks.nodes:read"), stacks.ValidateNodeExist, stacks.Get)
+	stacksNodesGroup.Get("/:name/logs", middleware.HasPermission("stacks.nodes:read"), websocket.New(shared.Logger))
+	stacksNodesGroup.Get("/:name/status", middleware.HasPermission("stacks.nodes:read"), websocket.New(shared.Status))
+	stacksNodesGroup.Get("/:name/metrics", middleware.HasPermission("stacks.nodes:read"), websocket.New(shared.Metrics))
+	stacksNodesGroup.Get("/:name/storage", middleware.HasPermission("stacks.nodes:read"), stacks.ValidateNodeExist, storage_class.NodeVolume)
+	stacksNodesGroup.Post("/:name/storage/expand", middleware.HasPermission("stacks.nodes:update"), stacks.ValidateNodeExist, stacks.ExpandStorage)
+	stacksNodesGroup.Post("/:name/snapshots", middleware.HasPermission("snapshots:create"), stacks.ValidateNodeExist, snapshot.Create)
+	stacksNodesGroup.Get("/:name/snapshots", middleware.HasPermission("snapshots:read"), stacks.ValidateNodeExist, snapshot.ListByNode)
+	stacksNodesGroup.Get("/:name/snapshots/schedule", middleware.HasPermission("snapshots:read"), stacks.ValidateNodeExist, snapshot.GetSchedule)
+	stacksNodesGroup.Put("/:name/snapshots/schedule", middleware.HasPermission("snapshots:update"), stacks.ValidateNodeExist, snapshot.UpdateSchedule)
+	stacksNodesGroup.Put("/:name", middleware.HasPermission("stacks.nodes:update"), stacks.ValidateNodeExist, stacks.Update)
+	stacksNodesGroup.Delete("/:name", middleware.HasPermission("stacks.nodes:delete"), stacks.ValidateNodeExist, stacks.Delete)
 
 	aptosGroup := v1.Group("aptos", middleware.JWTProtected, middleware.TFAProtected, middleware.WorkspaceProtected, middleware.ValidateWorkspaceMembership)
 	aptosNodesGroup := aptosGroup.Group("nodes")
-	aptosNodesGroup.Post("/", middleware.IsWriter, aptos.Create)
-	aptosNodesGroup.Head("/", middleware.IsReader, aptos.Count)
-	aptosNodesGroup.Get("/", middleware.IsReader, aptos.List)
-	aptosNodesGroup.Get("/:name", middleware.IsReader, aptos.ValidateNodeExist, aptos.Get)
-	aptosNodesGroup.Get("/:name/logs", middleware.IsReader, websocket.New(shared.Logger))
-	aptosNodesGroup.Get("/:name/status", middleware.IsReader, websocket.New(shared.Status))
-	aptosNodesGroup.Get("/:name/metrics", middleware.IsReader, websocket.New(shared.Metrics))
-	aptosNodesGroup.Get("/:name/stats", middleware.IsReader, websocket.New(aptos.Stats))
-	aptosNodesGroup.Put("/:name", middleware.IsWriter, aptos.ValidateNodeExist, aptos.Update)
-	aptosNodesGroup.Delete("/:name", middleware.IsAdmin, aptos.ValidateNodeExist, aptos.Delete)
+	aptosNodesGroup.Post("/", middleware.HasPermission("aptos.nodes:create"), secret.Materialize, snapshot.Restore, aptos.Create)
+	aptosNodesGroup.Head("/", middleware.HasPermission("aptos.nodes:read"), aptos.Count)
+	aptosNodesGroup.Get("/", middleware.HasPermission("aptos.nodes:read"), aptos.List)
+	aptosNodesGroup.Get("/:name", middleware.HasPermission("aptos.nodes:read"), aptos.ValidateNodeExist, aptos.Get)
+	aptosNodesGroup.Get("/:name/logs", middleware.HasPermission("aptos.nodes:read"), websocket.New(shared.Logger))
+	aptosNodesGroup.Get("/:name/status", middleware.HasPermission("aptos.nodes:read"), websocket.New(shared.Status))
+	aptosNodesGroup.Get("/:name/metrics", middleware.HasPermission("aptos.nodes:read"), websocket.New(shared.Metrics))
+	aptosNodesGroup.Get("/:name/stats", middleware.HasPermission("aptos.nodes:read"), websocket.New(aptos.Stats))
+	aptosNodesGroup.Get("/:name/storage", middleware.HasPermission("aptos.nodes:read"), aptos.ValidateNodeExist, storage_class.NodeVolume)
+	aptosNodesGroup.Post("/:name/storage/expand", middleware.HasPermission("aptos.nodes:update"), aptos.ValidateNodeExist, aptos.ExpandStorage)
+	aptosNodesGroup.Post("/:name/snapshots", middleware.HasPermission("snapshots:create"), aptos.ValidateNodeExist, snapshot.Create)
+	aptosNodesGroup.Get("/:name/snapshots", middleware.HasPermission("snapshots:read"), aptos.ValidateNodeExist, snapshot.ListByNode)
+	aptosNodesGroup.Get("/:name/snapshots/schedule", middleware.HasPermission("snapshots:read"), aptos.ValidateNodeExist, snapshot.GetSchedule)
+	aptosNodesGroup.Put("/:name/snapshots/schedule", middleware.HasPermission("snapshots:update"), aptos.ValidateNodeExist, snapshot.UpdateSchedule)
+	aptosNodesGroup.Put("/:name", middleware.HasPermission("aptos.nodes:update"), aptos.ValidateNodeExist, aptos.Update)
+	aptosNodesGroup.Delete("/:name", middleware.HasPermission("aptos.nodes:delete"), aptos.ValidateNodeExist, aptos.Delete)
+}
+
+// MapUploadUrl maps the files uploads routes served by the upload server, the upload server streams the request bodies
+func MapUploadUrl(app *fiber.App) {
+	v1 := app.Group("api").Group("v1")
+	v1.Use(config.FiberLimiter())
+
+	ipfsGroup := v1.Group("ipfs", middleware.JWTProtected, middleware.TFAProtected, middleware.WorkspaceProtected, middleware.ValidateWorkspaceMembership)
+	ipfsGroup.Post("/peers/:name/add", middleware.HasPermission("ipfs.peers:update"), ipfs_peer.ValidatePeerExist, ipfs_peer.Add)
+	ipfsGroup.Post("/clusterpeers/:name/add", middleware.HasPermission("ipfs.clusterpeers:update"), ipfs_cluster_peer.ValidateClusterPeerExist, ipfs_cluster_peer.Add)
 }
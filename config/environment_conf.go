@@ -43,6 +43,9 @@ var (
 		LogOutput                              string
 		LogLevel                               string
 		ServerReadTimeout                      string
+		UploadServerPort                       string
+		UploadReadTimeout                      int
+		UploadBodyLimit                        int
 		AccessSecret                           string
 		JwtSecretKeyExpireHoursCount           string
 		JwtSecretKeyExpireHoursCountRememberMe string
@@ -71,12 +74,27 @@ var (
 		TraefikNamespace                       string
 		KotalNamespace                         string
 		KotalIngressRouteName                  string
+		StorageWarningThreshold                int
+		StorageMonitorInterval                 int
+		SnapshotSchedulerInterval              int
+		VaultAddress                           string
+		VaultToken                             string
+		VaultKVMount                           string
+		VaultPathPrefix                        string
+		SecretSyncInterval                     int
+		BulkConcurrency                        int
+		BulkJobRetention                       int
+		ValidatorMonitorInterval               int
+		ValidatorBalanceRetention              int
 	}{
 		ServerPort:                             getenv("CORE_API_SERVER_PORT", "6000"),
 		Environment:                            getenv("ENVIRONMENT", "development"),
 		LogOutput:                              getenv("LOG_OUTPUT", "stdout"),
 		LogLevel:                               getenv("LOG_LEVEL", "info"),
 		ServerReadTimeout:                      getenv("SERVER_READ_TIMEOUT", "60"),
+		UploadServerPort:                       getenv("CORE_API_UPLOAD_SERVER_PORT", "6001"),
+		UploadReadTimeout:                      getenv("UPLOAD_READ_TIMEOUT", 1800),
+		UploadBodyLimit:                        getenv("UPLOAD_BODY_LIMIT", 1073741824),
 		AccessSecret:                           getenv("ACCESS_SECRET", "secret"), // TODO: change access secret default value
 		JwtSecretKeyExpireHoursCount:           getenv("JWT_SECRET_KEY_EXPIRE_HOURS_COUNT", "24"),
 		JwtSecretKeyExpireHoursCountRememberMe: getenv("JWT_SECRET_KEY_EXPIRE_HOURS_COUNT_REMEMBER_ME", "168"),
@@ -104,5 +122,17 @@ var (
 		TraefikNamespace:                       getenv("TRAEFIK_NAMESPACE", "traefik"),
 		KotalNamespace:                         getenv("KOTAL_NAMESPACE", "kotal"),
 		KotalIngressRouteName:                  getenv("KOTAL_INGRESS_ROUTE_NAME", "kotal-stack"),
+		StorageWarningThreshold:                getenv("STORAGE_WARNING_THRESHOLD", 85),
+		StorageMonitorInterval:                 getenv("STORAGE_MONITOR_INTERVAL", 60),
+		SnapshotSchedulerInterval:              getenv("SNAPSHOT_SCHEDULER_INTERVAL", 300),
+		VaultAddress:                           os.Getenv("VAULT_ADDR"),
+		VaultToken:                             os.Getenv("VAULT_TOKEN"),
+		VaultKVMount:                           getenv("VAULT_KV_MOUNT", "secret"),
+		VaultPathPrefix:                        getenv("VAULT_PATH_PREFIX", "kotal"),
+		SecretSyncInterval:                     getenv("SECRET_SYNC_INTERVAL", 60),
+		BulkConcurrency:                        getenv("BULK_CONCURRENCY", 5),
+		BulkJobRetention:                       getenv("BULK_JOB_RETENTION", 3600),
+		ValidatorMonitorInterval:               getenv("VALIDATOR_MONITOR_INTERVAL", 384),
+		ValidatorBalanceRetention:              getenv("VALIDATOR_BALANCE_RETENTION", 2592000),
 	}
 )
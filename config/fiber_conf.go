@@ -19,6 +19,16 @@ func FiberConfig() fiber.Config {
 	}
 }
 
+// UploadFiberConfig is the config of the upload server serving the files uploads to ipfs peers
+// bodies larger than the body limit are streamed instead of being read into memory, the streamed bodies are capped by UploadBodyLimit
+func UploadFiberConfig() fiber.Config {
+	return fiber.Config{
+		ReadTimeout:       time.Second * time.Duration(Environment.UploadReadTimeout),
+		ErrorHandler:      defaultErrorHandler,
+		StreamRequestBody: true,
+	}
+}
+
 // defaultErrorHandler used to catch all unhandled  run time resterror mainly panics
 // logs resterror using logger pkg
 // return custom error struct using restError pkg
@@ -42,7 +52,9 @@ func FiberLimiter() fiber.Handler {
 			return c.IP()
 		},
 		LimitReached: func(c *fiber.Ctx) error {
-			err := restErrors.NewTooManyRequestsError("too many requests")
+			// the limiter sets Retry-After before calling LimitReached
+			retryAfter, _ := strconv.Atoi(c.GetRespHeader(fiber.HeaderRetryAfter))
+			err := restErrors.NewTooManyRequestsError("too many requests", time.Duration(retryAfter)*time.Second)
 			return c.Status(fiber.StatusTooManyRequests).JSON(responder.NewResponse(err))
 		},
 		SkipFailedRequests:     false,
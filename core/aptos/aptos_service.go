@@ -76,6 +76,7 @@ func (service aptosService) Count(namespace string) (count int, restErr restErro
 func (service aptosService) Create(dto AptosDto) (node aptosv1alpha1.Node, restErr restErrors.IRestErr) {
 	node.ObjectMeta = dto.ObjectMetaFromMetadataDto()
 	k8s.DefaultResources(&node.Spec.Resources)
+	k8s.RequestedStorage(&node.Spec.Resources, dto.Resources)
 	node.Spec.Network = dto.Network
 	node.Spec.Image = dto.Image
 	node.Spec.API = true
@@ -0,0 +1,19 @@
+package audit
+
+import "time"
+
+// Record is an audited operation on a sensitive workspace resource, written before the operation response is returned
+type Record struct {
+	ID          string `gorm:"uniqueIndex"`
+	Actor       string `gorm:"index"`
+	WorkspaceId string `gorm:"index"`
+	Target      string
+	Operation   string
+	Details     string
+	CreatedAt   time.Time
+}
+
+// TableName names the table after the audit log, records alone is ambiguous
+func (Record) TableName() string {
+	return "audit_records"
+}
@@ -0,0 +1,10 @@
+package audit
+
+// CreateRecordDto is the audited operation, the actor is the user id and the target is the resource the operation was done on
+type CreateRecordDto struct {
+	Actor       string
+	WorkspaceId string
+	Target      string
+	Operation   string
+	Details     string
+}
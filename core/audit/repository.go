@@ -0,0 +1,42 @@
+package audit
+
+import (
+	restErrors "github.com/kotalco/core-api/pkg/errors"
+	"github.com/kotalco/core-api/pkg/logger"
+	"github.com/kotalco/core-api/pkg/sqlclient"
+	"gorm.io/gorm"
+)
+
+type repository struct {
+	db *gorm.DB
+}
+
+type IRepository interface {
+	WithTransaction(txHandle *gorm.DB) IRepository
+	WithoutTransaction() IRepository
+	Create(record *Record) restErrors.IRestErr
+}
+
+func NewRepository() IRepository {
+	newRepository := repository{}
+	return newRepository
+}
+
+func (r repository) WithTransaction(txHandle *gorm.DB) IRepository {
+	r.db = txHandle
+	return r
+}
+func (r repository) WithoutTransaction() IRepository {
+	r.db = sqlclient.OpenDBConnection()
+	return r
+}
+
+func (r repository) Create(record *Record) restErrors.IRestErr {
+	res := r.db.Create(record)
+	if res.Error != nil {
+		go logger.Error(repository.Create, res.Error)
+		return restErrors.NewInternalServerError("something went wrong")
+	}
+
+	return nil
+}
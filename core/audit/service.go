@@ -0,0 +1,46 @@
+package audit
+
+import (
+	"github.com/google/uuid"
+	restErrors "github.com/kotalco/core-api/pkg/errors"
+	"gorm.io/gorm"
+	"time"
+)
+
+type service struct{}
+
+type IService interface {
+	WithTransaction(txHandle *gorm.DB) IService
+	WithoutTransaction() IService
+	Create(dto CreateRecordDto) restErrors.IRestErr
+}
+
+var auditRepository = NewRepository()
+
+func NewService() IService {
+	newService := &service{}
+	return newService
+}
+
+func (s service) WithTransaction(txHandle *gorm.DB) IService {
+	auditRepository = auditRepository.WithTransaction(txHandle)
+	return s
+}
+func (s service) WithoutTransaction() IService {
+	auditRepository = auditRepository.WithoutTransaction()
+	return s
+}
+
+// Create writes the audit record of the operation, callers return the error instead of the operation response if the record can't be written
+func (service) Create(dto CreateRecordDto) restErrors.IRestErr {
+	record := new(Record)
+	record.ID = uuid.NewString()
+	record.Actor = dto.Actor
+	record.WorkspaceId = dto.WorkspaceId
+	record.Target = dto.Target
+	record.Operation = dto.Operation
+	record.Details = dto.Details
+	record.CreatedAt = time.Now().UTC()
+
+	return auditRepository.Create(record)
+}
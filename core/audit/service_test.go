@@ -0,0 +1,64 @@
+package audit
+
+import (
+	restErrors "github.com/kotalco/core-api/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+	"net/http"
+	"os"
+	"testing"
+)
+
+var (
+	auditService IService
+	CreateFunc   func(record *Record) restErrors.IRestErr
+)
+
+type auditRepositoryMock struct{}
+
+func (r auditRepositoryMock) WithTransaction(txHandle *gorm.DB) IRepository {
+	return r
+}
+func (r auditRepositoryMock) WithoutTransaction() IRepository {
+	return r
+}
+func (auditRepositoryMock) Create(record *Record) restErrors.IRestErr {
+	return CreateFunc(record)
+}
+
+func TestMain(m *testing.M) {
+	auditRepository = &auditRepositoryMock{}
+	auditService = NewService()
+	code := m.Run()
+	os.Exit(code)
+}
+
+func TestService_Create(t *testing.T) {
+	dto := CreateRecordDto{Actor: "1", WorkspaceId: "2", Target: "default/my-secret", Operation: "secret:reveal"}
+
+	t.Run("create_should_write_the_record", func(t *testing.T) {
+		var created *Record
+		CreateFunc = func(record *Record) restErrors.IRestErr {
+			created = record
+			return nil
+		}
+
+		err := auditService.Create(dto)
+		assert.Nil(t, err)
+		assert.NotEmpty(t, created.ID)
+		assert.EqualValues(t, "1", created.Actor)
+		assert.EqualValues(t, "2", created.WorkspaceId)
+		assert.EqualValues(t, "default/my-secret", created.Target)
+		assert.EqualValues(t, "secret:reveal", created.Operation)
+		assert.False(t, created.CreatedAt.IsZero())
+	})
+
+	t.Run("create_should_throw_if_repo_throws", func(t *testing.T) {
+		CreateFunc = func(record *Record) restErrors.IRestErr {
+			return restErrors.NewInternalServerError("something went wrong")
+		}
+
+		err := auditService.Create(dto)
+		assert.EqualValues(t, http.StatusInternalServerError, err.StatusCode())
+	})
+}
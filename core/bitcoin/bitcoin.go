@@ -1,16 +1,17 @@
 package bitcoin
 
 import (
+	"fmt"
 	"github.com/kotalco/core-api/k8s"
+	restErrors "github.com/kotalco/core-api/pkg/errors"
 	"github.com/kotalco/core-api/pkg/time"
 	bitcoinv1alpha1 "github.com/kotalco/kotal/apis/bitcoin/v1alpha1"
 	sharedAPI "github.com/kotalco/kotal/apis/shared"
 )
 
 const (
-	BitcoinJsonRpcDefaultUserName           = "kotal"
-	BitcoinJsonRpcDefaultUserPasswordName   = "kotal-rpc-user-password"
-	BitcoinJsonRpcDefaultUserPasswordSecret = "2bbf1fdeff5f5c2dbae910a7a43776ab1d829446d7bd911c4812f7eb47f224aa"
+	// BitcoinJsonRpcDefaultUserName is the rpc user every node is created with, the api calls the node json-rpc as this user
+	BitcoinJsonRpcDefaultUserName = "kotal"
 )
 
 type RPCUser struct {
@@ -34,6 +35,19 @@ type BitcoinDto struct {
 
 type BitcoinListDto []BitcoinDto
 
+// Validate validates the node name, it names the default rpc user password secret
+func (dto BitcoinDto) Validate() restErrors.IRestErr {
+	if err := dto.MetaDataDto.Validate(); err != nil {
+		return err
+	}
+	if len(rpcUserSecretName(dto.Name, BitcoinJsonRpcDefaultUserName)) > maxSecretNameLength {
+		return restErrors.NewValidationError(map[string]string{
+			"name": fmt.Sprintf("name can't exceed %d characters", maxSecretNameLength-len(rpcUserSecretName("", BitcoinJsonRpcDefaultUserName))),
+		})
+	}
+	return nil
+}
+
 func (dto BitcoinDto) FromBitcoinNode(n bitcoinv1alpha1.Node) BitcoinDto {
 	dto.Name = n.Name
 	dto.Time = time.Time{CreatedAt: n.CreationTimestamp.UTC().Format(time.JavascriptISOString)}
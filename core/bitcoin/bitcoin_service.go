@@ -3,10 +3,12 @@ package bitcoin
 import (
 	"context"
 	"fmt"
+	"github.com/kotalco/core-api/core/secret"
 	"github.com/kotalco/core-api/k8s"
 	restErrors "github.com/kotalco/core-api/pkg/errors"
 	"github.com/kotalco/core-api/pkg/logger"
 	bitcoinv1alpha1 "github.com/kotalco/kotal/apis/bitcoin/v1alpha1"
+	"github.com/ybbus/jsonrpc/v2"
 	corev1 "k8s.io/api/core/v1"
 	apiErrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/types"
@@ -22,10 +24,25 @@ type IService interface {
 	Create(BitcoinDto) (bitcoinv1alpha1.Node, restErrors.IRestErr)
 	Delete(*bitcoinv1alpha1.Node) restErrors.IRestErr
 	Update(BitcoinDto, *bitcoinv1alpha1.Node) restErrors.IRestErr
+	// AddRPCUser adds the rpc user to the node with a generated password secret
+	AddRPCUser(*bitcoinv1alpha1.Node, RPCUserDto) (RPCUser, restErrors.IRestErr)
+	// RemoveRPCUser removes the rpc user from the node and deletes its generated password secret
+	RemoveRPCUser(node *bitcoinv1alpha1.Node, username string) restErrors.IRestErr
+	// RotateRPCPassword replaces the node default rpc user password with a generated one
+	RotateRPCPassword(*bitcoinv1alpha1.Node) (RPCUser, restErrors.IRestErr)
+	// RPCClient returns the node json-rpc client authenticated as the default rpc user
+	RPCClient(node bitcoinv1alpha1.Node, wallet string) (jsonrpc.RPCClient, restErrors.IRestErr)
+	// Wallets lists the node loaded wallets
+	Wallets(bitcoinv1alpha1.Node) ([]string, restErrors.IRestErr)
+	// Balances returns the node wallet balances by wallet name
+	Balances(node bitcoinv1alpha1.Node, wallet string) (BalancesDto, restErrors.IRestErr)
+	// NewAddress returns a new receive address of the node wallet by wallet name
+	NewAddress(node bitcoinv1alpha1.Node, wallet string, dto AddressDto) (AddressDto, restErrors.IRestErr)
 }
 
 var (
-	k8sClient = k8s.NewClientService()
+	k8sClient     = k8s.NewClientService()
+	secretService = secret.NewSecretService()
 )
 
 func NewBitcoinService() IService {
@@ -71,7 +88,13 @@ func (service bitcoinService) Count(namespace string) (count int, restErr restEr
 }
 
 // Create creates bitcoin node from the given specs
+// the node is created with the default rpc user, its password secret is generated for the node
 func (service bitcoinService) Create(dto BitcoinDto) (node bitcoinv1alpha1.Node, restErr restErrors.IRestErr) {
+	generated, restErr := service.generateRPCPassword(dto.Name, dto.Namespace, BitcoinJsonRpcDefaultUserName)
+	if restErr != nil {
+		return
+	}
+
 	node.ObjectMeta = dto.ObjectMetaFromMetadataDto()
 	node.Spec = bitcoinv1alpha1.NodeSpec{
 		Network: dto.Network,
@@ -79,14 +102,16 @@ func (service bitcoinService) Create(dto BitcoinDto) (node bitcoinv1alpha1.Node,
 		RPCUsers: []bitcoinv1alpha1.RPCUser{
 			{
 				Username:           BitcoinJsonRpcDefaultUserName,
-				PasswordSecretName: BitcoinJsonRpcDefaultUserPasswordName,
+				PasswordSecretName: generated.Name,
 			},
 		},
 	}
 
 	k8s.DefaultResources(&node.Spec.Resources)
+	k8s.RequestedStorage(&node.Spec.Resources, dto.Resources)
 
 	if err := k8sClient.Create(context.Background(), &node); err != nil {
+		_ = service.deleteRPCUserSecrets(dto.Namespace, generated.Name)
 		if apiErrors.IsAlreadyExists(err) {
 			restErr = restErrors.NewBadRequestError(fmt.Sprintf("node by name %s already exist", node.Name))
 			return
@@ -176,12 +201,18 @@ func (service bitcoinService) Update(dto BitcoinDto, node *bitcoinv1alpha1.Node)
 	return
 }
 
-// Delete deletes bitcoin node by name
+// Delete deletes bitcoin node by name with the generated passwords secrets of its rpc users
 func (service bitcoinService) Delete(node *bitcoinv1alpha1.Node) (restErr restErrors.IRestErr) {
 	if err := k8sClient.Delete(context.Background(), node); err != nil {
 		go logger.Error(service.Delete, err)
 		restErr = restErrors.NewInternalServerError(fmt.Sprintf("can't delete node by name %s", node.Name))
 		return
 	}
+
+	for _, user := range node.Spec.RPCUsers {
+		if restErr := service.deleteRPCUserSecrets(node.Namespace, rpcUserSecretName(node.Name, user.Username)); restErr != nil {
+			go logger.Warn(service.Delete, restErr)
+		}
+	}
 	return
 }
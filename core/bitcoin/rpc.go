@@ -0,0 +1,340 @@
+package bitcoin
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/kotalco/core-api/core/secret"
+	"github.com/kotalco/core-api/k8s"
+	restErrors "github.com/kotalco/core-api/pkg/errors"
+	"github.com/kotalco/core-api/pkg/logger"
+	"github.com/kotalco/core-api/pkg/security"
+	bitcoinv1alpha1 "github.com/kotalco/kotal/apis/bitcoin/v1alpha1"
+	"github.com/ybbus/jsonrpc/v2"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"net/http"
+	"net/url"
+	"regexp"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"time"
+)
+
+const (
+	// rpcPasswordLength is the length of the passwords generated when the default rpc user password is rotated
+	rpcPasswordLength = 32
+	// rpcWalletNotFound is the bitcoin json-rpc error code of the wallet not found or not loaded
+	rpcWalletNotFound = -18
+	// maxSecretNameLength is the max length of the generated password secret name, it's copied to the secret name label
+	maxSecretNameLength = 63
+)
+
+var rpcUsernameRegex = regexp.MustCompile("^[a-z0-9]([a-z0-9-]{0,30}[a-z0-9])?$")
+
+// rpcClient calls the node json-rpc server
+var rpcClient = &http.Client{Timeout: 10 * time.Second}
+
+// RPCUserDto is the request to add a rpc user, the user password is generated
+type RPCUserDto struct {
+	Username string `json:"username"`
+}
+
+// BalanceDto is the wallet balance in btc
+type BalanceDto struct {
+	Trusted          json.Number `json:"trusted"`
+	UntrustedPending json.Number `json:"untrustedPending"`
+	Immature         json.Number `json:"immature"`
+}
+
+// BalancesDto is the wallet balances of the wallet keys and the watch-only addresses
+type BalancesDto struct {
+	Wallet    string      `json:"wallet"`
+	Mine      BalanceDto  `json:"mine"`
+	WatchOnly *BalanceDto `json:"watchOnly,omitempty"`
+}
+
+// AddressDto is the request and the response of a new wallet receive address
+type AddressDto struct {
+	Label       string `json:"label"`
+	AddressType string `json:"addressType"`
+	Address     string `json:"address,omitempty"`
+}
+
+// balance is the getbalances json-rpc balance
+type balance struct {
+	Trusted          json.Number `json:"trusted"`
+	UntrustedPending json.Number `json:"untrusted_pending"`
+	Immature         json.Number `json:"immature"`
+}
+
+func (b balance) toDto() BalanceDto {
+	return BalanceDto{Trusted: b.Trusted, UntrustedPending: b.UntrustedPending, Immature: b.Immature}
+}
+
+// Validate validates the username, it names the password secret of the user of the node by name
+func (dto RPCUserDto) Validate(nodeName string) restErrors.IRestErr {
+	if !rpcUsernameRegex.MatchString(dto.Username) {
+		return restErrors.NewValidationError(map[string]string{
+			"username": "username must start and end with an alphanumeric, and contains no more than 32 lowercase alphanumeric characters and - in total.",
+		})
+	}
+	if len(rpcUserSecretName(nodeName, dto.Username)) > maxSecretNameLength {
+		return restErrors.NewValidationError(map[string]string{
+			"username": fmt.Sprintf("username can't exceed %d characters for node by name %s", maxSecretNameLength-len(rpcUserSecretName(nodeName, "")), nodeName),
+		})
+	}
+	return nil
+}
+
+// Validate validates the address type, the node default address type is used if it's empty
+func (dto AddressDto) Validate() restErrors.IRestErr {
+	switch dto.AddressType {
+	case "", "legacy", "p2sh-segwit", "bech32", "bech32m":
+		return nil
+	}
+	return restErrors.NewValidationError(map[string]string{"addressType": "address type must be one of legacy, p2sh-segwit, bech32 or bech32m"})
+}
+
+// rpcUserSecretName is the name of the generated password secret of the node rpc user
+func rpcUserSecretName(name string, username string) string {
+	return fmt.Sprintf("%s-%s-rpc-password", name, username)
+}
+
+// AddRPCUser generates the user password secret and adds the user to the node rpc users
+func (service bitcoinService) AddRPCUser(node *bitcoinv1alpha1.Node, dto RPCUserDto) (user RPCUser, restErr restErrors.IRestErr) {
+	for _, v := range node.Spec.RPCUsers {
+		if v.Username == dto.Username {
+			restErr = restErrors.NewValidationError(map[string]string{"username": fmt.Sprintf("rpc user %s already exist", dto.Username)})
+			return
+		}
+	}
+
+	generated, restErr := service.generateRPCPassword(node.Name, node.Namespace, dto.Username)
+	if restErr != nil {
+		return
+	}
+
+	node.Spec.RPCUsers = append(node.Spec.RPCUsers, bitcoinv1alpha1.RPCUser{Username: dto.Username, PasswordSecretName: generated.Name})
+	if err := k8sClient.Update(context.Background(), node); err != nil {
+		go logger.Error(service.AddRPCUser, err)
+		node.Spec.RPCUsers = node.Spec.RPCUsers[:len(node.Spec.RPCUsers)-1]
+		_ = service.deleteRPCUserSecrets(node.Namespace, rpcUserSecretName(node.Name, dto.Username))
+		restErr = restErrors.NewInternalServerError(fmt.Sprintf("can't update node by name %s", node.Name))
+		return
+	}
+
+	return RPCUser{Username: dto.Username, PasswordSecretName: generated.Name}, nil
+}
+
+// RemoveRPCUser removes the user from the node rpc users and deletes the user generated password secret
+// the default rpc user can't be removed, the api calls the node json-rpc as this user
+func (service bitcoinService) RemoveRPCUser(node *bitcoinv1alpha1.Node, username string) restErrors.IRestErr {
+	if username == BitcoinJsonRpcDefaultUserName {
+		return restErrors.NewBadRequestError(fmt.Sprintf("default rpc user %s can't be removed", username))
+	}
+
+	users := make([]bitcoinv1alpha1.RPCUser, 0, len(node.Spec.RPCUsers))
+	for _, v := range node.Spec.RPCUsers {
+		if v.Username != username {
+			users = append(users, v)
+		}
+	}
+	if len(users) == len(node.Spec.RPCUsers) {
+		return restErrors.NewNotFoundError(fmt.Sprintf("rpc user by name %s doesn't exist", username))
+	}
+
+	previous := node.Spec.RPCUsers
+	node.Spec.RPCUsers = users
+	if err := k8sClient.Update(context.Background(), node); err != nil {
+		go logger.Error(service.RemoveRPCUser, err)
+		node.Spec.RPCUsers = previous
+		return restErrors.NewInternalServerError(fmt.Sprintf("can't update node by name %s", node.Name))
+	}
+
+	// secrets provided by the user are kept, only the generated ones are deleted
+	return service.deleteRPCUserSecrets(node.Namespace, rpcUserSecretName(node.Name, username))
+}
+
+// RotateRPCPassword replaces the default rpc user password with a generated one
+// the node password secret is rotated to a new version, nodes still using the secret shared by the namespace nodes are moved to a secret of their own
+func (service bitcoinService) RotateRPCPassword(node *bitcoinv1alpha1.Node) (user RPCUser, restErr restErrors.IRestErr) {
+	index := defaultRPCUser(*node)
+	if index == -1 {
+		restErr = restErrors.NewNotFoundError(fmt.Sprintf("default rpc user %s doesn't exist", BitcoinJsonRpcDefaultUserName))
+		return
+	}
+	user.Username = BitcoinJsonRpcDefaultUserName
+
+	base := rpcUserSecretName(node.Name, BitcoinJsonRpcDefaultUserName)
+	current, restErr := secretService.Get(types.NamespacedName{Namespace: node.Namespace, Name: node.Spec.RPCUsers[index].PasswordSecretName})
+	if restErr != nil && restErr.StatusCode() != http.StatusNotFound {
+		return
+	}
+
+	if restErr == nil && current.Labels[secret.NameLabel] == base {
+		password, err := security.GenerateSecureString(rpcPasswordLength)
+		if err != nil {
+			go logger.Error(service.RotateRPCPassword, err)
+			restErr = restErrors.NewInternalServerError("can't generate rpc password")
+			return
+		}
+		// the secret service re-points the node to the new version
+		var rotated corev1.Secret
+		if rotated, _, restErr = secretService.Rotate(current, map[string]string{"password": password}); restErr != nil {
+			return
+		}
+		node.Spec.RPCUsers[index].PasswordSecretName = rotated.Name
+		user.PasswordSecretName = rotated.Name
+		return
+	}
+
+	generated, restErr := service.generateRPCPassword(node.Name, node.Namespace, BitcoinJsonRpcDefaultUserName)
+	if restErr != nil {
+		return
+	}
+
+	previous := node.Spec.RPCUsers[index].PasswordSecretName
+	node.Spec.RPCUsers[index].PasswordSecretName = generated.Name
+	if err := k8sClient.Update(context.Background(), node); err != nil {
+		go logger.Error(service.RotateRPCPassword, err)
+		node.Spec.RPCUsers[index].PasswordSecretName = previous
+		_ = service.deleteRPCUserSecrets(node.Namespace, base)
+		restErr = restErrors.NewInternalServerError(fmt.Sprintf("can't update node by name %s", node.Name))
+		return
+	}
+
+	user.PasswordSecretName = generated.Name
+	return
+}
+
+// RPCClient returns the json-rpc client of the running node pod authenticated as the default rpc user
+// the wallet endpoint is called if the wallet isn't empty, the password is sent in the authorization header to be kept out of the client errors
+func (service bitcoinService) RPCClient(node bitcoinv1alpha1.Node, wallet string) (jsonrpc.RPCClient, restErrors.IRestErr) {
+	if !node.Spec.RPC {
+		return nil, restErrors.NewBadRequestError("JSON-RPC server is not enabled")
+	}
+	index := defaultRPCUser(node)
+	if index == -1 {
+		return nil, restErrors.NewBadRequestError(fmt.Sprintf("default rpc user %s doesn't exist", BitcoinJsonRpcDefaultUserName))
+	}
+
+	passwordSecret, restErr := secretService.Get(types.NamespacedName{Namespace: node.Namespace, Name: node.Spec.RPCUsers[index].PasswordSecretName})
+	if restErr != nil {
+		return nil, restErr
+	}
+
+	pod := &corev1.Pod{}
+	if err := k8sClient.Get(context.Background(), types.NamespacedName{Namespace: node.Namespace, Name: fmt.Sprintf("%s-0", node.Name)}, pod); err != nil || pod.Status.PodIP == "" {
+		return nil, restErrors.NewBadRequestError(fmt.Sprintf("node by name %s isn't running", node.Name))
+	}
+
+	endpoint := fmt.Sprintf("http://%s:%d/", pod.Status.PodIP, node.Spec.RPCPort)
+	if wallet != "" {
+		endpoint += "wallet/" + url.PathEscape(wallet)
+	}
+	credentials := base64.StdEncoding.EncodeToString([]byte(BitcoinJsonRpcDefaultUserName + ":" + string(passwordSecret.Data["password"])))
+	return jsonrpc.NewClientWithOpts(endpoint, &jsonrpc.RPCClientOpts{
+		HTTPClient:    rpcClient,
+		CustomHeaders: map[string]string{"Authorization": "Basic " + credentials},
+	}), nil
+}
+
+// Wallets lists the names of the node loaded wallets
+func (service bitcoinService) Wallets(node bitcoinv1alpha1.Node) ([]string, restErrors.IRestErr) {
+	wallets := make([]string, 0)
+	if restErr := service.walletCall(node, "", &wallets, "listwallets"); restErr != nil {
+		return nil, restErr
+	}
+	return wallets, nil
+}
+
+// Balances returns the node wallet balances by wallet name
+func (service bitcoinService) Balances(node bitcoinv1alpha1.Node, wallet string) (BalancesDto, restErrors.IRestErr) {
+	var balances struct {
+		Mine      balance  `json:"mine"`
+		WatchOnly *balance `json:"watchonly"`
+	}
+	if restErr := service.walletCall(node, wallet, &balances, "getbalances"); restErr != nil {
+		return BalancesDto{}, restErr
+	}
+
+	result := BalancesDto{Wallet: wallet, Mine: balances.Mine.toDto()}
+	if balances.WatchOnly != nil {
+		watchOnly := balances.WatchOnly.toDto()
+		result.WatchOnly = &watchOnly
+	}
+	return result, nil
+}
+
+// NewAddress returns a new receive address of the node wallet by wallet name
+func (service bitcoinService) NewAddress(node bitcoinv1alpha1.Node, wallet string, dto AddressDto) (AddressDto, restErrors.IRestErr) {
+	params := []interface{}{dto.Label}
+	if dto.AddressType != "" {
+		params = append(params, dto.AddressType)
+	}
+	if restErr := service.walletCall(node, wallet, &dto.Address, "getnewaddress", params...); restErr != nil {
+		return AddressDto{}, restErr
+	}
+	return dto, nil
+}
+
+// walletCall calls the wallet json-rpc method of the node, the node wide endpoint is called if the wallet is empty
+func (service bitcoinService) walletCall(node bitcoinv1alpha1.Node, wallet string, out interface{}, method string, params ...interface{}) restErrors.IRestErr {
+	if !node.Spec.Wallet {
+		return restErrors.NewBadRequestError("node wallet is not enabled")
+	}
+	rpc, restErr := service.RPCClient(node, wallet)
+	if restErr != nil {
+		return restErr
+	}
+
+	err := rpc.CallFor(out, method, params...)
+	var rpcErr *jsonrpc.RPCError
+	if errors.As(err, &rpcErr) {
+		if rpcErr.Code == rpcWalletNotFound {
+			return restErrors.NewNotFoundError(fmt.Sprintf("wallet by name %s doesn't exist", wallet))
+		}
+		return restErrors.NewBadRequestError(rpcErr.Message)
+	}
+	if err != nil {
+		go logger.Warn(service.walletCall, err)
+		return restErrors.NewBadRequestError(fmt.Sprintf("can't reach node by name %s json-rpc server", node.Name))
+	}
+	return nil
+}
+
+// generateRPCPassword generates the password secret of the node rpc user
+func (service bitcoinService) generateRPCPassword(name string, namespace string, username string) (secret.GeneratedSecretDto, restErrors.IRestErr) {
+	return secretService.Generate(secret.GenerateSecretDto{
+		MetaDataDto: k8s.MetaDataDto{Name: rpcUserSecretName(name, username), Namespace: namespace},
+		Type:        secret.RPCPasswordGenerator,
+	})
+}
+
+// deleteRPCUserSecrets deletes all the versions of the generated rpc user password secret
+func (service bitcoinService) deleteRPCUserSecrets(namespace string, base string) restErrors.IRestErr {
+	list := &corev1.SecretList{}
+	if err := k8sClient.List(context.Background(), list, client.InNamespace(namespace), client.MatchingLabels{secret.NameLabel: base}); err != nil {
+		go logger.Error(service.deleteRPCUserSecrets, err)
+		return restErrors.NewInternalServerError(fmt.Sprintf("can't delete secret by name %s", base))
+	}
+
+	for i := range list.Items {
+		if restErr := secretService.Delete(&list.Items[i]); restErr != nil {
+			return restErr
+		}
+	}
+	return nil
+}
+
+// defaultRPCUser returns the index of the default rpc user in the node rpc users, -1 if it's removed
+func defaultRPCUser(node bitcoinv1alpha1.Node) int {
+	for i, v := range node.Spec.RPCUsers {
+		if v.Username == BitcoinJsonRpcDefaultUserName {
+			return i
+		}
+	}
+	return -1
+}
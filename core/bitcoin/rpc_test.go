@@ -0,0 +1,399 @@
+package bitcoin
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/kotalco/core-api/core/secret"
+	"github.com/kotalco/core-api/k8s"
+	restErrors "github.com/kotalco/core-api/pkg/errors"
+	bitcoinv1alpha1 "github.com/kotalco/kotal/apis/bitcoin/v1alpha1"
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+/*
+k8s client mocks
+*/
+var (
+	k8sClientGetFunc    func(key client.ObjectKey, obj client.Object) error
+	k8sClientListFunc   func(list client.ObjectList) error
+	k8sClientCreateFunc func(obj client.Object) error
+	k8sClientUpdateFunc func(obj client.Object) error
+)
+
+type k8sClientMock struct{}
+
+func (k8sClientMock) Get(ctx context.Context, key client.ObjectKey, obj client.Object, opts ...client.GetOption) error {
+	return k8sClientGetFunc(key, obj)
+}
+func (k8sClientMock) List(ctx context.Context, list client.ObjectList, opts ...client.ListOption) error {
+	return k8sClientListFunc(list)
+}
+func (k8sClientMock) Create(ctx context.Context, obj client.Object, opts ...client.CreateOption) error {
+	return k8sClientCreateFunc(obj)
+}
+func (k8sClientMock) Delete(ctx context.Context, obj client.Object, opts ...client.DeleteOption) error {
+	return nil
+}
+func (k8sClientMock) Update(ctx context.Context, obj client.Object, opts ...client.UpdateOption) error {
+	return k8sClientUpdateFunc(obj)
+}
+func (k8sClientMock) Patch(ctx context.Context, obj client.Object, patch client.Patch, opts ...client.PatchOption) error {
+	return nil
+}
+func (k8sClientMock) DeleteAllOf(ctx context.Context, obj client.Object, opts ...client.DeleteAllOfOption) error {
+	return nil
+}
+
+/*
+secret service mocks
+*/
+var (
+	secretGetFunc    func(key types.NamespacedName) (corev1.Secret, restErrors.IRestErr)
+	secretRotateFunc func(s corev1.Secret, data map[string]string) (corev1.Secret, []secret.ReferenceDto, restErrors.IRestErr)
+	generated        []string
+	deleted          []string
+)
+
+type secretServiceMock struct {
+	secret.IService
+}
+
+func (secretServiceMock) Get(key types.NamespacedName) (corev1.Secret, restErrors.IRestErr) {
+	return secretGetFunc(key)
+}
+func (secretServiceMock) Generate(dto secret.GenerateSecretDto) (secret.GeneratedSecretDto, restErrors.IRestErr) {
+	generated = append(generated, dto.Name)
+	return secret.GeneratedSecretDto{SecretDto: secret.SecretDto{MetaDataDto: dto.MetaDataDto, Type: secret.PasswordType}}, nil
+}
+func (secretServiceMock) Rotate(s corev1.Secret, data map[string]string) (corev1.Secret, []secret.ReferenceDto, restErrors.IRestErr) {
+	return secretRotateFunc(s, data)
+}
+func (secretServiceMock) Delete(s *corev1.Secret) restErrors.IRestErr {
+	deleted = append(deleted, s.Name)
+	return nil
+}
+
+var service IService
+
+func TestMain(m *testing.M) {
+	k8sClient = &k8sClientMock{}
+	secretService = &secretServiceMock{}
+	service = NewBitcoinService()
+
+	code := m.Run()
+	os.Exit(code)
+}
+
+// reset restores the default mocks, the pod is running and the generated secrets are the only versions
+func reset() {
+	generated, deleted = nil, nil
+	k8sClientGetFunc = func(key client.ObjectKey, obj client.Object) error {
+		obj.(*corev1.Pod).Status.PodIP = "127.0.0.1"
+		return nil
+	}
+	k8sClientListFunc = func(list client.ObjectList) error {
+		list.(*corev1.SecretList).Items = []corev1.Secret{{ObjectMeta: metav1.ObjectMeta{Name: "generated"}}}
+		return nil
+	}
+	k8sClientCreateFunc = func(obj client.Object) error {
+		return nil
+	}
+	k8sClientUpdateFunc = func(obj client.Object) error {
+		return nil
+	}
+	secretGetFunc = func(key types.NamespacedName) (corev1.Secret, restErrors.IRestErr) {
+		return corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: key.Name, Labels: map[string]string{secret.NameLabel: key.Name}},
+			Data:       map[string][]byte{"password": []byte("secret")},
+		}, nil
+	}
+}
+
+func bitcoinNode() bitcoinv1alpha1.Node {
+	return bitcoinv1alpha1.Node{
+		ObjectMeta: metav1.ObjectMeta{Name: "bitcoin", Namespace: "default"},
+		Spec: bitcoinv1alpha1.NodeSpec{
+			RPC:      true,
+			Wallet:   true,
+			RPCUsers: []bitcoinv1alpha1.RPCUser{{Username: "kotal", PasswordSecretName: "bitcoin-kotal-rpc-password"}},
+		},
+	}
+}
+
+// nodeServer serves the handler as the json-rpc server of the running node, calls are authenticated as the default rpc user
+func nodeServer(handler func(path string, method string, params []interface{}) interface{}) (*httptest.Server, bitcoinv1alpha1.Node) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		username, password, ok := r.BasicAuth()
+		if !ok || username != "kotal" || password != "secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		var req struct {
+			ID     int           `json:"id"`
+			Method string        `json:"method"`
+			Params []interface{} `json:"params"`
+		}
+		json.NewDecoder(r.Body).Decode(&req)
+		response := map[string]interface{}{"id": req.ID, "result": nil, "error": nil}
+		result := handler(r.URL.Path, req.Method, req.Params)
+		if rpcErr, ok := result.(map[string]interface{}); ok && rpcErr["code"] != nil {
+			// bitcoind responds to wallet errors with the http status of the error
+			response["error"] = rpcErr
+			w.WriteHeader(http.StatusNotFound)
+		} else {
+			response["result"] = result
+		}
+		json.NewEncoder(w).Encode(response)
+	}))
+	_, port, _ := net.SplitHostPort(server.Listener.Addr().String())
+	rpcPort, _ := strconv.Atoi(port)
+
+	node := bitcoinNode()
+	node.Spec.RPCPort = uint(rpcPort)
+	return server, node
+}
+
+func TestRPCUserDto_Validate(t *testing.T) {
+	t.Run("validate_should_pass", func(t *testing.T) {
+		assert.Nil(t, RPCUserDto{Username: "electrs"}.Validate("bitcoin"))
+	})
+
+	t.Run("validate_should_throw_if_username_is_invalid", func(t *testing.T) {
+		err := RPCUserDto{Username: "Electrs:admin"}.Validate("bitcoin")
+		assert.Contains(t, err.(restErrors.RestErr).Validations, "username")
+	})
+
+	t.Run("validate_should_throw_if_password_secret_name_is_too_long", func(t *testing.T) {
+		err := RPCUserDto{Username: strings.Repeat("u", 32)}.Validate(strings.Repeat("n", 30))
+		assert.EqualValues(t, map[string]string{"username": "username can't exceed 19 characters for node by name " + strings.Repeat("n", 30)}, err.(restErrors.RestErr).Validations)
+	})
+}
+
+func TestBitcoinDto_Validate(t *testing.T) {
+	t.Run("validate_should_pass", func(t *testing.T) {
+		assert.Nil(t, BitcoinDto{MetaDataDto: k8s.MetaDataDto{Name: "bitcoin"}}.Validate())
+	})
+
+	t.Run("validate_should_throw_if_password_secret_name_is_too_long", func(t *testing.T) {
+		err := BitcoinDto{MetaDataDto: k8s.MetaDataDto{Name: strings.Repeat("n", 45)}}.Validate()
+		assert.EqualValues(t, map[string]string{"name": "name can't exceed 44 characters"}, err.(restErrors.RestErr).Validations)
+	})
+}
+
+func TestAddressDto_Validate(t *testing.T) {
+	t.Run("validate_should_throw_if_address_type_is_invalid", func(t *testing.T) {
+		assert.Nil(t, AddressDto{AddressType: "bech32m"}.Validate())
+		err := AddressDto{AddressType: "taproot"}.Validate()
+		assert.Contains(t, err.(restErrors.RestErr).Validations, "addressType")
+	})
+}
+
+func TestService_Create(t *testing.T) {
+	t.Run("create_should_generate_the_default_rpc_user_password", func(t *testing.T) {
+		reset()
+
+		node, err := service.Create(BitcoinDto{MetaDataDto: k8s.MetaDataDto{Name: "bitcoin", Namespace: "default"}})
+		assert.Nil(t, err)
+		assert.EqualValues(t, []string{"bitcoin-kotal-rpc-password"}, generated)
+		assert.EqualValues(t, []bitcoinv1alpha1.RPCUser{{Username: "kotal", PasswordSecretName: "bitcoin-kotal-rpc-password"}}, node.Spec.RPCUsers)
+	})
+
+	t.Run("create_should_delete_the_generated_password_if_node_can't_be_created", func(t *testing.T) {
+		reset()
+		k8sClientCreateFunc = func(obj client.Object) error {
+			return http.ErrServerClosed
+		}
+
+		_, err := service.Create(BitcoinDto{MetaDataDto: k8s.MetaDataDto{Name: "bitcoin", Namespace: "default"}})
+		assert.EqualValues(t, http.StatusInternalServerError, err.StatusCode())
+		assert.EqualValues(t, []string{"generated"}, deleted)
+	})
+}
+
+func TestService_AddRPCUser(t *testing.T) {
+	t.Run("add_rpc_user_should_generate_the_user_password", func(t *testing.T) {
+		reset()
+		node := bitcoinNode()
+
+		user, err := service.AddRPCUser(&node, RPCUserDto{Username: "electrs"})
+		assert.Nil(t, err)
+		assert.EqualValues(t, RPCUser{Username: "electrs", PasswordSecretName: "bitcoin-electrs-rpc-password"}, user)
+		assert.Len(t, node.Spec.RPCUsers, 2)
+	})
+
+	t.Run("add_rpc_user_should_throw_if_user_exists", func(t *testing.T) {
+		reset()
+		node := bitcoinNode()
+
+		_, err := service.AddRPCUser(&node, RPCUserDto{Username: "kotal"})
+		assert.EqualValues(t, "rpc user kotal already exist", err.(restErrors.RestErr).Validations["username"])
+		assert.Empty(t, generated)
+	})
+
+	t.Run("add_rpc_user_should_delete_the_generated_password_if_node_can't_be_updated", func(t *testing.T) {
+		reset()
+		k8sClientUpdateFunc = func(obj client.Object) error {
+			return http.ErrServerClosed
+		}
+		node := bitcoinNode()
+
+		_, err := service.AddRPCUser(&node, RPCUserDto{Username: "electrs"})
+		assert.EqualValues(t, http.StatusInternalServerError, err.StatusCode())
+		assert.Len(t, node.Spec.RPCUsers, 1)
+		assert.EqualValues(t, []string{"generated"}, deleted)
+	})
+}
+
+func TestService_RemoveRPCUser(t *testing.T) {
+	t.Run("remove_rpc_user_should_delete_the_generated_password", func(t *testing.T) {
+		reset()
+		node := bitcoinNode()
+		node.Spec.RPCUsers = append(node.Spec.RPCUsers, bitcoinv1alpha1.RPCUser{Username: "electrs", PasswordSecretName: "bitcoin-electrs-rpc-password"})
+
+		err := service.RemoveRPCUser(&node, "electrs")
+		assert.Nil(t, err)
+		assert.Len(t, node.Spec.RPCUsers, 1)
+		assert.EqualValues(t, []string{"generated"}, deleted)
+	})
+
+	t.Run("remove_rpc_user_should_throw_if_user_is_the_default_user", func(t *testing.T) {
+		reset()
+		node := bitcoinNode()
+
+		err := service.RemoveRPCUser(&node, "kotal")
+		assert.EqualValues(t, http.StatusBadRequest, err.StatusCode())
+	})
+
+	t.Run("remove_rpc_user_should_throw_if_user_doesn't_exist", func(t *testing.T) {
+		reset()
+		node := bitcoinNode()
+
+		err := service.RemoveRPCUser(&node, "electrs")
+		assert.EqualValues(t, http.StatusNotFound, err.StatusCode())
+	})
+}
+
+func TestService_RotateRPCPassword(t *testing.T) {
+	t.Run("rotate_rpc_password_should_rotate_the_node_password_secret", func(t *testing.T) {
+		reset()
+		secretRotateFunc = func(s corev1.Secret, data map[string]string) (corev1.Secret, []secret.ReferenceDto, restErrors.IRestErr) {
+			assert.EqualValues(t, "bitcoin-kotal-rpc-password", s.Name)
+			assert.Len(t, data["password"], rpcPasswordLength)
+			return corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "bitcoin-kotal-rpc-password-v2"}}, nil, nil
+		}
+		node := bitcoinNode()
+
+		user, err := service.RotateRPCPassword(&node)
+		assert.Nil(t, err)
+		assert.EqualValues(t, RPCUser{Username: "kotal", PasswordSecretName: "bitcoin-kotal-rpc-password-v2"}, user)
+		assert.Empty(t, generated)
+	})
+
+	t.Run("rotate_rpc_password_should_move_the_node_off_the_shared_secret", func(t *testing.T) {
+		reset()
+		var updated *bitcoinv1alpha1.Node
+		k8sClientUpdateFunc = func(obj client.Object) error {
+			updated = obj.(*bitcoinv1alpha1.Node)
+			return nil
+		}
+		node := bitcoinNode()
+		node.Spec.RPCUsers[0].PasswordSecretName = "kotal-rpc-user-password"
+
+		user, err := service.RotateRPCPassword(&node)
+		assert.Nil(t, err)
+		assert.EqualValues(t, "bitcoin-kotal-rpc-password", user.PasswordSecretName)
+		assert.EqualValues(t, []string{"bitcoin-kotal-rpc-password"}, generated)
+		assert.EqualValues(t, "bitcoin-kotal-rpc-password", updated.Spec.RPCUsers[0].PasswordSecretName)
+	})
+}
+
+func TestService_Wallets(t *testing.T) {
+	t.Run("wallets_should_list_the_node_wallets", func(t *testing.T) {
+		reset()
+		server, node := nodeServer(func(path string, method string, params []interface{}) interface{} {
+			assert.EqualValues(t, "/", path)
+			assert.EqualValues(t, "listwallets", method)
+			return []string{"hot", "cold"}
+		})
+		defer server.Close()
+
+		wallets, err := service.Wallets(node)
+		assert.Nil(t, err)
+		assert.EqualValues(t, []string{"hot", "cold"}, wallets)
+	})
+
+	t.Run("wallets_should_throw_if_wallet_is_disabled", func(t *testing.T) {
+		reset()
+		node := bitcoinNode()
+		node.Spec.Wallet = false
+
+		_, err := service.Wallets(node)
+		assert.EqualValues(t, "node wallet is not enabled", err.Error())
+	})
+
+	t.Run("wallets_should_throw_if_node_isn't_running", func(t *testing.T) {
+		reset()
+		k8sClientGetFunc = func(key client.ObjectKey, obj client.Object) error {
+			return nil
+		}
+
+		_, err := service.Wallets(bitcoinNode())
+		assert.EqualValues(t, "node by name bitcoin isn't running", err.Error())
+	})
+}
+
+func TestService_Balances(t *testing.T) {
+	t.Run("balances_should_return_the_wallet_balances", func(t *testing.T) {
+		reset()
+		server, node := nodeServer(func(path string, method string, params []interface{}) interface{} {
+			assert.EqualValues(t, "/wallet/hot", path)
+			assert.EqualValues(t, "getbalances", method)
+			return map[string]interface{}{"mine": map[string]interface{}{"trusted": 1.5, "untrusted_pending": 0, "immature": 0.25}}
+		})
+		defer server.Close()
+
+		balances, err := service.Balances(node, "hot")
+		assert.Nil(t, err)
+		assert.EqualValues(t, BalancesDto{Wallet: "hot", Mine: BalanceDto{Trusted: "1.5", UntrustedPending: "0", Immature: "0.25"}}, balances)
+	})
+
+	t.Run("balances_should_throw_if_wallet_isn't_loaded", func(t *testing.T) {
+		reset()
+		server, node := nodeServer(func(path string, method string, params []interface{}) interface{} {
+			return map[string]interface{}{"code": rpcWalletNotFound, "message": "Requested wallet does not exist or is not loaded"}
+		})
+		defer server.Close()
+
+		_, err := service.Balances(node, "cold")
+		assert.EqualValues(t, http.StatusNotFound, err.StatusCode())
+		assert.EqualValues(t, "wallet by name cold doesn't exist", err.Error())
+	})
+}
+
+func TestService_NewAddress(t *testing.T) {
+	t.Run("new_address_should_return_the_wallet_receive_address", func(t *testing.T) {
+		reset()
+		server, node := nodeServer(func(path string, method string, params []interface{}) interface{} {
+			assert.EqualValues(t, "/wallet/hot", path)
+			assert.EqualValues(t, "getnewaddress", method)
+			assert.EqualValues(t, []interface{}{"donations", "bech32"}, params)
+			return "bc1qar0srrr7xfkvy5l643lydnw9re59gtzzwf5mdq"
+		})
+		defer server.Close()
+
+		address, err := service.NewAddress(node, "hot", AddressDto{Label: "donations", AddressType: "bech32"})
+		assert.Nil(t, err)
+		assert.EqualValues(t, "bc1qar0srrr7xfkvy5l643lydnw9re59gtzzwf5mdq", address.Address)
+	})
+}
@@ -0,0 +1,162 @@
+package bulk
+
+import (
+	restErrors "github.com/kotalco/core-api/pkg/errors"
+	"github.com/kotalco/core-api/pkg/roles"
+	timepkg "github.com/kotalco/core-api/pkg/time"
+	"path"
+	"strings"
+)
+
+// bulk actions
+const (
+	UpdateImageAction     = "update_image"
+	UpdateResourcesAction = "update_resources"
+	DeleteAction          = "delete"
+	RestartAction         = "restart"
+)
+
+// node results statuses
+const (
+	PendingStatus   = "pending"
+	SucceededStatus = "succeeded"
+	FailedStatus    = "failed"
+	SkippedStatus   = "skipped"
+)
+
+// job statuses
+const (
+	RunningStatus   = "running"
+	CompletedStatus = "completed"
+)
+
+// SelectorDto selects the workspace nodes a bulk action runs on, a node is selected if it matches all the given criteria
+type SelectorDto struct {
+	// Protocol is the protocol e.g. ethereum2 or the node kind e.g. ethereum2.beaconnodes
+	Protocol string `json:"protocol"`
+	// Network is the node spec network e.g. mainnet, the nodes not joining a network e.g. ipfs peers never match it
+	Network string `json:"network"`
+	// NamePattern is a shell name pattern e.g. geth-*
+	NamePattern string `json:"namePattern"`
+	// Names is an explicit list of node names
+	Names []string `json:"names"`
+}
+
+// ResourcesDto is the compute resources set by the update_resources action
+type ResourcesDto struct {
+	CPU         string `json:"cpu"`
+	CPULimit    string `json:"cpuLimit"`
+	Memory      string `json:"memory"`
+	MemoryLimit string `json:"memoryLimit"`
+}
+
+// BulkDto is the action to run on the selected nodes
+type BulkDto struct {
+	Selector  SelectorDto   `json:"selector"`
+	Action    string        `json:"action"`
+	Image     string        `json:"image"`
+	Resources *ResourcesDto `json:"resources"`
+	// Async runs the action in the background, the job is polled by id
+	Async bool `json:"async"`
+}
+
+// NodeResultDto is the bulk action result on a single node
+type NodeResultDto struct {
+	Resource string `json:"resource"`
+	Name     string `json:"name"`
+	Status   string `json:"status"`
+	Error    string `json:"error,omitempty"`
+}
+
+// JobDto is the bulk action progress and its per node results
+type JobDto struct {
+	timepkg.Time
+	ID         string          `json:"id"`
+	Action     string          `json:"action"`
+	Status     string          `json:"status"`
+	Total      int             `json:"total"`
+	Completed  int             `json:"completed"`
+	Failed     int             `json:"failed"`
+	Skipped    int             `json:"skipped"`
+	Results    []NodeResultDto `json:"results"`
+	FinishedAt string          `json:"finishedAt,omitempty"`
+}
+
+// Validate validates the selector and the action parameters
+func (dto BulkDto) Validate() restErrors.IRestErr {
+	fields := map[string]string{}
+
+	selector := dto.Selector
+	if selector.Protocol == "" && selector.Network == "" && selector.NamePattern == "" && len(selector.Names) == 0 {
+		fields["selector"] = "selector must have at least one of protocol, network, namePattern or names"
+	}
+	if selector.Protocol != "" && len(kindsOf(selector.Protocol)) == 0 {
+		fields["selector.protocol"] = "protocol must be a protocol e.g. ethereum2 or a node kind e.g. ethereum2.beaconnodes"
+	}
+	if _, err := path.Match(selector.NamePattern, ""); err != nil {
+		fields["selector.namePattern"] = "namePattern must be a valid shell name pattern"
+	}
+
+	switch dto.Action {
+	case UpdateImageAction:
+		if dto.Image == "" {
+			fields["image"] = "image is required"
+		}
+	case UpdateResourcesAction:
+		if dto.Resources == nil || *dto.Resources == (ResourcesDto{}) {
+			fields["resources"] = "resources must have at least one of cpu, cpuLimit, memory or memoryLimit"
+		}
+	case DeleteAction, RestartAction:
+	default:
+		fields["action"] = "action must be one of update_image, update_resources, delete or restart"
+	}
+
+	if len(fields) > 0 {
+		return restErrors.NewValidationError(fields)
+	}
+	return nil
+}
+
+// Permission returns the permission required to run the action on nodes of the resource
+func (dto BulkDto) Permission(resource string) string {
+	if dto.Action == DeleteAction {
+		return roles.Permission(resource, roles.Delete)
+	}
+	return roles.Permission(resource, roles.Update)
+}
+
+// Matches checks the node matches the selector network, name pattern and names, the protocol is matched by listing its kinds only
+func (selector SelectorDto) Matches(name string, network string) bool {
+	if selector.Network != "" && network != selector.Network {
+		return false
+	}
+	if selector.NamePattern != "" {
+		if matched, _ := path.Match(selector.NamePattern, name); !matched {
+			return false
+		}
+	}
+	if len(selector.Names) > 0 {
+		found := false
+		for _, v := range selector.Names {
+			if v == name {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+// kindsOf returns the node kinds of the protocol, all the node kinds if no protocol is given
+func kindsOf(protocol string) []nodeKind {
+	var result []nodeKind
+	for _, kind := range kinds {
+		if protocol == "" || kind.resource == protocol || strings.HasPrefix(kind.resource, protocol+".") {
+			result = append(result, kind)
+		}
+	}
+	return result
+}
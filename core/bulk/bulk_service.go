@@ -0,0 +1,234 @@
+// Package bulk internal is the domain layer for running actions on many nodes across protocols
+// uses the protocols services to act on the nodes
+package bulk
+
+import (
+	"context"
+	"fmt"
+	"github.com/google/uuid"
+	"github.com/kotalco/core-api/config"
+	"github.com/kotalco/core-api/k8s"
+	restErrors "github.com/kotalco/core-api/pkg/errors"
+	"github.com/kotalco/core-api/pkg/logger"
+	"github.com/kotalco/core-api/pkg/roles"
+	timepkg "github.com/kotalco/core-api/pkg/time"
+	sharedAPI "github.com/kotalco/kotal/apis/shared"
+	corev1 "k8s.io/api/core/v1"
+	apiErrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sync"
+	"time"
+)
+
+type bulkService struct{}
+
+type IService interface {
+	// Start runs the action on the selected nodes the member has permission to act on, the async jobs are returned right away
+	Start(namespace string, dto BulkDto, permissions []string) (JobDto, restErrors.IRestErr)
+	// Job returns the job progress
+	Job(namespace string, id string) (JobDto, restErrors.IRestErr)
+}
+
+var (
+	k8sClient = k8s.NewClientService()
+)
+
+func NewService() IService {
+	return bulkService{}
+}
+
+// target is a selected node with its kind
+type target struct {
+	kind nodeKind
+	node client.Object
+}
+
+// job is a running or finished bulk action, results are updated by the workers
+type job struct {
+	sync.Mutex
+	namespace string
+	dto       JobDto
+	finished  time.Time
+}
+
+// jobs are kept in memory, finished jobs are removed BULK_JOB_RETENTION seconds after they finish
+// jobs are lost on restart and can only be polled from the replica running them, the API is deployed as a single replica
+var jobs = struct {
+	sync.Mutex
+	items map[string]*job
+}{items: map[string]*job{}}
+
+// Start selects the nodes and runs the action on them with bounded concurrency
+func (service bulkService) Start(namespace string, dto BulkDto, permissions []string) (result JobDto, restErr restErrors.IRestErr) {
+	targets, restErr := service.selectNodes(namespace, dto.Selector)
+	if restErr != nil {
+		return
+	}
+
+	current := &job{namespace: namespace, dto: JobDto{
+		Time:    timepkg.Time{CreatedAt: time.Now().UTC().Format(timepkg.JavascriptISOString)},
+		ID:      uuid.NewString(),
+		Action:  dto.Action,
+		Status:  RunningStatus,
+		Total:   len(targets),
+		Results: make([]NodeResultDto, len(targets)),
+	}}
+
+	allowed := make([]int, 0, len(targets))
+	for i, t := range targets {
+		current.dto.Results[i] = NodeResultDto{Resource: t.kind.resource, Name: t.node.GetName(), Status: PendingStatus}
+		if !roles.Allowed(permissions, dto.Permission(t.kind.resource)) {
+			current.dto.Results[i].Status = SkippedStatus
+			current.dto.Results[i].Error = "unAuthorized action"
+			current.dto.Skipped++
+			continue
+		}
+		allowed = append(allowed, i)
+	}
+
+	store(current)
+
+	if dto.Async {
+		result = current.snapshot()
+		go service.run(current, dto, targets, allowed)
+		return
+	}
+
+	service.run(current, dto, targets, allowed)
+	return current.snapshot(), nil
+}
+
+// Job returns the workspace job by id
+func (service bulkService) Job(namespace string, id string) (JobDto, restErrors.IRestErr) {
+	jobs.Lock()
+	current, ok := jobs.items[id]
+	jobs.Unlock()
+
+	if !ok || current.namespace != namespace {
+		return JobDto{}, restErrors.NewNotFoundError(fmt.Sprintf("bulk job by id %s doesn't exist", id))
+	}
+	return current.snapshot(), nil
+}
+
+// selectNodes lists the nodes of the selector protocol kinds and filters them by the selector
+func (service bulkService) selectNodes(namespace string, selector SelectorDto) ([]target, restErrors.IRestErr) {
+	var targets []target
+	for _, kind := range kindsOf(selector.Protocol) {
+		nodes, restErr := kind.list(namespace)
+		if restErr != nil {
+			return nil, restErr
+		}
+		for _, node := range nodes {
+			if selector.Matches(node.GetName(), kind.network(node)) {
+				targets = append(targets, target{kind: kind, node: node})
+			}
+		}
+	}
+	return targets, nil
+}
+
+// run acts on the allowed targets, at most BULK_CONCURRENCY nodes at the same time
+func (service bulkService) run(current *job, dto BulkDto, targets []target, allowed []int) {
+	concurrency := config.Environment.BulkConcurrency
+	if concurrency < 1 {
+		concurrency = 1
+	}
+	semaphore := make(chan struct{}, concurrency)
+
+	var wg sync.WaitGroup
+	for _, i := range allowed {
+		wg.Add(1)
+		semaphore <- struct{}{}
+		go func(i int) {
+			defer func() {
+				<-semaphore
+				wg.Done()
+			}()
+			current.complete(i, service.act(dto, targets[i]))
+		}(i)
+	}
+	wg.Wait()
+
+	current.Lock()
+	current.dto.Status = CompletedStatus
+	current.finished = time.Now()
+	current.dto.FinishedAt = current.finished.UTC().Format(timepkg.JavascriptISOString)
+	current.Unlock()
+}
+
+// act runs the action on a single node
+func (service bulkService) act(dto BulkDto, t target) restErrors.IRestErr {
+	switch dto.Action {
+	case UpdateImageAction:
+		return t.kind.update(t.node, dto.Image, sharedAPI.Resources{})
+	case UpdateResourcesAction:
+		return t.kind.update(t.node, "", sharedAPI.Resources{
+			CPU:         dto.Resources.CPU,
+			CPULimit:    dto.Resources.CPULimit,
+			Memory:      dto.Resources.Memory,
+			MemoryLimit: dto.Resources.MemoryLimit,
+		})
+	case DeleteAction:
+		return t.kind.delete(t.node)
+	case RestartAction:
+		return service.restart(t.node)
+	}
+	return restErrors.NewBadRequestError(fmt.Sprintf("unknown action %s", dto.Action))
+}
+
+// restart deletes the node pod, the node statefulset recreates it
+func (service bulkService) restart(node client.Object) restErrors.IRestErr {
+	pod := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Namespace: node.GetNamespace(), Name: fmt.Sprintf("%s-0", node.GetName())}}
+	if err := k8sClient.Delete(context.Background(), pod); err != nil {
+		if apiErrors.IsNotFound(err) {
+			return restErrors.NewNotFoundError(fmt.Sprintf("pod by name %s doesn't exist", pod.Name))
+		}
+		go logger.Error(service.restart, err)
+		return restErrors.NewInternalServerError(fmt.Sprintf("can't restart node by name %s", node.GetName()))
+	}
+	return nil
+}
+
+// complete records the node result
+func (current *job) complete(i int, restErr restErrors.IRestErr) {
+	current.Lock()
+	defer current.Unlock()
+
+	current.dto.Completed++
+	if restErr != nil {
+		current.dto.Failed++
+		current.dto.Results[i].Status = FailedStatus
+		current.dto.Results[i].Error = restErr.Error()
+		return
+	}
+	current.dto.Results[i].Status = SucceededStatus
+}
+
+// snapshot returns a copy of the job progress safe to be marshalled while the workers run
+func (current *job) snapshot() JobDto {
+	current.Lock()
+	defer current.Unlock()
+
+	result := current.dto
+	result.Results = append([]NodeResultDto{}, current.dto.Results...)
+	return result
+}
+
+// store saves the job and removes the finished jobs beyond retention
+func store(current *job) {
+	retention := time.Duration(config.Environment.BulkJobRetention) * time.Second
+
+	jobs.Lock()
+	defer jobs.Unlock()
+
+	for id, item := range jobs.items {
+		item.Lock()
+		expired := !item.finished.IsZero() && time.Since(item.finished) > retention
+		item.Unlock()
+		if expired {
+			delete(jobs.items, id)
+		}
+	}
+	jobs.items[current.dto.ID] = current
+}
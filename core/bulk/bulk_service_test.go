@@ -0,0 +1,245 @@
+package bulk
+
+import (
+	"context"
+	"github.com/kotalco/core-api/config"
+	restErrors "github.com/kotalco/core-api/pkg/errors"
+	"github.com/kotalco/core-api/pkg/roles"
+	ethereumv1alpha1 "github.com/kotalco/kotal/apis/ethereum/v1alpha1"
+	ethereum2v1alpha1 "github.com/kotalco/kotal/apis/ethereum2/v1alpha1"
+	sharedAPI "github.com/kotalco/kotal/apis/shared"
+	"github.com/stretchr/testify/assert"
+	apiErrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"net/http"
+	"os"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+/*
+k8s client mocks
+*/
+var k8sClientDeleteFunc func(obj client.Object) error
+
+type k8sClientMock struct{}
+
+func (k8sClientMock) Get(ctx context.Context, key client.ObjectKey, obj client.Object, opts ...client.GetOption) error {
+	return nil
+}
+func (k8sClientMock) List(ctx context.Context, list client.ObjectList, opts ...client.ListOption) error {
+	return nil
+}
+func (k8sClientMock) Create(ctx context.Context, obj client.Object, opts ...client.CreateOption) error {
+	return nil
+}
+func (k8sClientMock) Delete(ctx context.Context, obj client.Object, opts ...client.DeleteOption) error {
+	return k8sClientDeleteFunc(obj)
+}
+func (k8sClientMock) Update(ctx context.Context, obj client.Object, opts ...client.UpdateOption) error {
+	return nil
+}
+func (k8sClientMock) Patch(ctx context.Context, obj client.Object, patch client.Patch, opts ...client.PatchOption) error {
+	return nil
+}
+func (k8sClientMock) DeleteAllOf(ctx context.Context, obj client.Object, opts ...client.DeleteAllOfOption) error {
+	return nil
+}
+
+/*
+node kinds mocks
+*/
+var (
+	nodeUpdateFunc func(node client.Object, image string, resources sharedAPI.Resources) restErrors.IRestErr
+	nodeDeleteFunc func(node client.Object) restErrors.IRestErr
+)
+
+func ethereumNode(name string, network string) client.Object {
+	node := &ethereumv1alpha1.Node{ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default"}}
+	node.Spec.Network = network
+	return node
+}
+
+func beaconNode(name string, network string) client.Object {
+	node := &ethereum2v1alpha1.BeaconNode{ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default"}}
+	node.Spec.Network = network
+	return node
+}
+
+func mockKind(resource string, nodes ...client.Object) nodeKind {
+	return nodeKind{
+		resource: resource,
+		list: func(namespace string) ([]client.Object, restErrors.IRestErr) {
+			return nodes, nil
+		},
+		network: func(node client.Object) string {
+			switch node := node.(type) {
+			case *ethereumv1alpha1.Node:
+				return node.Spec.Network
+			case *ethereum2v1alpha1.BeaconNode:
+				return node.Spec.Network
+			}
+			return ""
+		},
+		update: func(node client.Object, image string, resources sharedAPI.Resources) restErrors.IRestErr {
+			return nodeUpdateFunc(node, image, resources)
+		},
+		delete: func(node client.Object) restErrors.IRestErr {
+			return nodeDeleteFunc(node)
+		},
+	}
+}
+
+var service = NewService()
+
+func TestMain(m *testing.M) {
+	k8sClient = &k8sClientMock{}
+	kinds = []nodeKind{
+		mockKind(roles.EthereumNodes, ethereumNode("geth-1", "mainnet"), ethereumNode("geth-2", "goerli"), ethereumNode("nethermind-1", "goerli")),
+		mockKind(roles.Ethereum2BeaconNodes, beaconNode("prysm-1", "goerli")),
+	}
+
+	code := m.Run()
+	os.Exit(code)
+}
+
+func TestBulkDto_Validate(t *testing.T) {
+	t.Run("validate_should_pass", func(t *testing.T) {
+		dto := BulkDto{Selector: SelectorDto{Protocol: "ethereum2"}, Action: RestartAction}
+		assert.Nil(t, dto.Validate())
+	})
+	t.Run("validate_should_throw_if_selector_is_empty", func(t *testing.T) {
+		err := BulkDto{Action: DeleteAction}.Validate()
+		assert.EqualValues(t, http.StatusBadRequest, err.StatusCode())
+		assert.Contains(t, err.(restErrors.RestErr).Validations, "selector")
+	})
+	t.Run("validate_should_throw_if_protocol_is_unknown", func(t *testing.T) {
+		err := BulkDto{Selector: SelectorDto{Protocol: "solana"}, Action: DeleteAction}.Validate()
+		assert.Contains(t, err.(restErrors.RestErr).Validations, "selector.protocol")
+	})
+	t.Run("validate_should_throw_if_image_is_missing", func(t *testing.T) {
+		err := BulkDto{Selector: SelectorDto{Names: []string{"geth-1"}}, Action: UpdateImageAction}.Validate()
+		assert.Contains(t, err.(restErrors.RestErr).Validations, "image")
+	})
+	t.Run("validate_should_throw_if_resources_are_empty", func(t *testing.T) {
+		err := BulkDto{Selector: SelectorDto{Names: []string{"geth-1"}}, Action: UpdateResourcesAction, Resources: &ResourcesDto{}}.Validate()
+		assert.Contains(t, err.(restErrors.RestErr).Validations, "resources")
+	})
+	t.Run("validate_should_throw_if_name_pattern_is_invalid", func(t *testing.T) {
+		err := BulkDto{Selector: SelectorDto{NamePattern: "geth-["}, Action: RestartAction}.Validate()
+		assert.Contains(t, err.(restErrors.RestErr).Validations, "selector.namePattern")
+	})
+}
+
+func TestService_Start(t *testing.T) {
+	t.Run("start_should_update_the_selected_nodes_image", func(t *testing.T) {
+		var mu sync.Mutex
+		updated := map[string]string{}
+		nodeUpdateFunc = func(node client.Object, image string, resources sharedAPI.Resources) restErrors.IRestErr {
+			mu.Lock()
+			defer mu.Unlock()
+			updated[node.GetName()] = image
+			return nil
+		}
+
+		dto := BulkDto{Selector: SelectorDto{Protocol: "ethereum", Network: "goerli"}, Action: UpdateImageAction, Image: "geth:v1.13"}
+		job, err := service.Start("default", dto, []string{roles.Wildcard})
+		assert.Nil(t, err)
+		assert.EqualValues(t, CompletedStatus, job.Status)
+		assert.EqualValues(t, 2, job.Total)
+		assert.EqualValues(t, 2, job.Completed)
+		assert.EqualValues(t, map[string]string{"geth-2": "geth:v1.13", "nethermind-1": "geth:v1.13"}, updated)
+	})
+
+	t.Run("start_should_match_name_pattern_across_protocols", func(t *testing.T) {
+		nodeDeleteFunc = func(node client.Object) restErrors.IRestErr {
+			return nil
+		}
+
+		job, err := service.Start("default", BulkDto{Selector: SelectorDto{NamePattern: "*-1"}, Action: DeleteAction}, []string{roles.Wildcard})
+		assert.Nil(t, err)
+		assert.EqualValues(t, []NodeResultDto{
+			{Resource: roles.EthereumNodes, Name: "geth-1", Status: SucceededStatus},
+			{Resource: roles.EthereumNodes, Name: "nethermind-1", Status: SucceededStatus},
+			{Resource: roles.Ethereum2BeaconNodes, Name: "prysm-1", Status: SucceededStatus},
+		}, job.Results)
+	})
+
+	t.Run("start_should_skip_nodes_without_permission", func(t *testing.T) {
+		nodeDeleteFunc = func(node client.Object) restErrors.IRestErr {
+			return nil
+		}
+
+		permissions := []string{roles.Permission(roles.EthereumNodes, roles.Delete)}
+		job, err := service.Start("default", BulkDto{Selector: SelectorDto{Network: "goerli"}, Action: DeleteAction}, permissions)
+		assert.Nil(t, err)
+		assert.EqualValues(t, 3, job.Total)
+		assert.EqualValues(t, 2, job.Completed)
+		assert.EqualValues(t, 1, job.Skipped)
+		assert.EqualValues(t, NodeResultDto{Resource: roles.Ethereum2BeaconNodes, Name: "prysm-1", Status: SkippedStatus, Error: "unAuthorized action"}, job.Results[2])
+	})
+
+	t.Run("start_should_return_per_node_failures", func(t *testing.T) {
+		k8sClientDeleteFunc = func(obj client.Object) error {
+			if obj.GetName() == "geth-2-0" {
+				return apiErrors.NewNotFound(schema.GroupResource{Resource: "pods"}, obj.GetName())
+			}
+			return nil
+		}
+
+		job, err := service.Start("default", BulkDto{Selector: SelectorDto{Names: []string{"geth-1", "geth-2"}}, Action: RestartAction}, []string{roles.Wildcard})
+		assert.Nil(t, err)
+		assert.EqualValues(t, 1, job.Failed)
+		assert.EqualValues(t, SucceededStatus, job.Results[0].Status)
+		assert.EqualValues(t, NodeResultDto{Resource: roles.EthereumNodes, Name: "geth-2", Status: FailedStatus, Error: "pod by name geth-2-0 doesn't exist"}, job.Results[1])
+	})
+
+	t.Run("start_should_bound_concurrency", func(t *testing.T) {
+		config.Environment.BulkConcurrency = 2
+		var running, peak int32
+		nodeUpdateFunc = func(node client.Object, image string, resources sharedAPI.Resources) restErrors.IRestErr {
+			current := atomic.AddInt32(&running, 1)
+			for {
+				max := atomic.LoadInt32(&peak)
+				if current <= max || atomic.CompareAndSwapInt32(&peak, max, current) {
+					break
+				}
+			}
+			time.Sleep(20 * time.Millisecond)
+			atomic.AddInt32(&running, -1)
+			return nil
+		}
+
+		dto := BulkDto{Selector: SelectorDto{NamePattern: "*"}, Action: UpdateResourcesAction, Resources: &ResourcesDto{CPU: "2"}}
+		job, err := service.Start("default", dto, []string{roles.Wildcard})
+		assert.Nil(t, err)
+		assert.EqualValues(t, 4, job.Completed)
+		assert.LessOrEqual(t, peak, int32(2))
+	})
+
+	t.Run("start_async_should_be_polled_by_id", func(t *testing.T) {
+		release := make(chan struct{})
+		nodeDeleteFunc = func(node client.Object) restErrors.IRestErr {
+			<-release
+			return nil
+		}
+
+		job, err := service.Start("default", BulkDto{Selector: SelectorDto{Names: []string{"geth-1"}}, Action: DeleteAction, Async: true}, []string{roles.Wildcard})
+		assert.Nil(t, err)
+		assert.EqualValues(t, RunningStatus, job.Status)
+		assert.EqualValues(t, PendingStatus, job.Results[0].Status)
+
+		close(release)
+		assert.Eventually(t, func() bool {
+			polled, err := service.Job("default", job.ID)
+			return err == nil && polled.Status == CompletedStatus && polled.Results[0].Status == SucceededStatus
+		}, time.Second, 10*time.Millisecond)
+
+		_, err = service.Job("other-workspace", job.ID)
+		assert.EqualValues(t, http.StatusNotFound, err.StatusCode())
+	})
+}
@@ -0,0 +1,271 @@
+package bulk
+
+import (
+	"github.com/kotalco/core-api/core/aptos"
+	"github.com/kotalco/core-api/core/bitcoin"
+	"github.com/kotalco/core-api/core/chainlink"
+	"github.com/kotalco/core-api/core/ethereum"
+	"github.com/kotalco/core-api/core/ethereum2/beacon_node"
+	"github.com/kotalco/core-api/core/ethereum2/validator"
+	"github.com/kotalco/core-api/core/filecoin"
+	"github.com/kotalco/core-api/core/ipfs/ipfs_cluster_peer"
+	"github.com/kotalco/core-api/core/ipfs/ipfs_peer"
+	"github.com/kotalco/core-api/core/near"
+	"github.com/kotalco/core-api/core/polkadot"
+	"github.com/kotalco/core-api/core/stacks"
+	restErrors "github.com/kotalco/core-api/pkg/errors"
+	"github.com/kotalco/core-api/pkg/roles"
+	aptosv1alpha1 "github.com/kotalco/kotal/apis/aptos/v1alpha1"
+	bitcoinv1alpha1 "github.com/kotalco/kotal/apis/bitcoin/v1alpha1"
+	chainlinkv1alpha1 "github.com/kotalco/kotal/apis/chainlink/v1alpha1"
+	ethereumv1alpha1 "github.com/kotalco/kotal/apis/ethereum/v1alpha1"
+	ethereum2v1alpha1 "github.com/kotalco/kotal/apis/ethereum2/v1alpha1"
+	filecoinv1alpha1 "github.com/kotalco/kotal/apis/filecoin/v1alpha1"
+	ipfsv1alpha1 "github.com/kotalco/kotal/apis/ipfs/v1alpha1"
+	nearv1alpha1 "github.com/kotalco/kotal/apis/near/v1alpha1"
+	polkadotv1alpha1 "github.com/kotalco/kotal/apis/polkadot/v1alpha1"
+	sharedAPI "github.com/kotalco/kotal/apis/shared"
+	stacksv1alpha1 "github.com/kotalco/kotal/apis/stacks/v1alpha1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// nodeKind adapts the node kind service to the bulk actions
+// network returns the node spec network, empty for the kinds not joining a network e.g. ipfs peers
+// update only changes the image if given and the non empty resources, the other node spec fields are kept as is
+type nodeKind struct {
+	resource string
+	list     func(namespace string) ([]client.Object, restErrors.IRestErr)
+	network  func(node client.Object) string
+	update   func(node client.Object, image string, resources sharedAPI.Resources) restErrors.IRestErr
+	delete   func(node client.Object) restErrors.IRestErr
+}
+
+var (
+	aptosService           = aptos.NewAptosService()
+	bitcoinService         = bitcoin.NewBitcoinService()
+	chainlinkService       = chainlink.NewChainLinkService()
+	ethereumService        = ethereum.NewEthereumService()
+	beaconNodeService      = beacon_node.NewBeaconNodeService()
+	validatorService       = validator.NewValidatorService()
+	filecoinService        = filecoin.NewFilecoinService()
+	ipfsPeerService        = ipfs_peer.NewIpfsPeerService()
+	ipfsClusterPeerService = ipfs_cluster_peer.NewIpfsClusterPeerService()
+	nearService            = near.NewNearService()
+	polkadotService        = polkadot.NewPolkadotService()
+	stacksService          = stacks.NewStacksService()
+)
+
+// kinds are the node kinds bulk actions can run on
+var kinds = []nodeKind{
+	{
+		resource: roles.ChainlinkNodes,
+		list: func(namespace string) ([]client.Object, restErrors.IRestErr) {
+			list, err := chainlinkService.List(namespace)
+			return objects(list.Items, err)
+		},
+		network: func(node client.Object) string {
+			return ""
+		},
+		update: func(node client.Object, image string, resources sharedAPI.Resources) restErrors.IRestErr {
+			return chainlinkService.Update(chainlink.ChainlinkDto{Image: image, Resources: resources}, node.(*chainlinkv1alpha1.Node))
+		},
+		delete: func(node client.Object) restErrors.IRestErr {
+			return chainlinkService.Delete(node.(*chainlinkv1alpha1.Node))
+		},
+	},
+	{
+		resource: roles.EthereumNodes,
+		list: func(namespace string) ([]client.Object, restErrors.IRestErr) {
+			list, err := ethereumService.List(namespace)
+			return objects(list.Items, err)
+		},
+		network: func(node client.Object) string {
+			return node.(*ethereumv1alpha1.Node).Spec.Network
+		},
+		update: func(node client.Object, image string, resources sharedAPI.Resources) restErrors.IRestErr {
+			// the ethereum update replaces the bootnodes and static nodes, the current ones are passed to be kept
+			ethereumNode := node.(*ethereumv1alpha1.Node)
+			current := ethereum.EthereumDto{}.FromEthereumNode(*ethereumNode)
+			dto := ethereum.EthereumDto{Image: image, Resources: resources, Bootnodes: current.Bootnodes, StaticNodes: current.StaticNodes}
+			return ethereumService.Update(dto, ethereumNode)
+		},
+		delete: func(node client.Object) restErrors.IRestErr {
+			return ethereumService.Delete(node.(*ethereumv1alpha1.Node))
+		},
+	},
+	{
+		resource: roles.Ethereum2BeaconNodes,
+		list: func(namespace string) ([]client.Object, restErrors.IRestErr) {
+			list, err := beaconNodeService.List(namespace)
+			return objects(list.Items, err)
+		},
+		network: func(node client.Object) string {
+			return node.(*ethereum2v1alpha1.BeaconNode).Spec.Network
+		},
+		update: func(node client.Object, image string, resources sharedAPI.Resources) restErrors.IRestErr {
+			return beaconNodeService.Update(beacon_node.BeaconNodeDto{Image: image, Resources: resources}, node.(*ethereum2v1alpha1.BeaconNode))
+		},
+		delete: func(node client.Object) restErrors.IRestErr {
+			return beaconNodeService.Delete(node.(*ethereum2v1alpha1.BeaconNode))
+		},
+	},
+	{
+		resource: roles.Ethereum2Validators,
+		list: func(namespace string) ([]client.Object, restErrors.IRestErr) {
+			list, err := validatorService.List(namespace)
+			return objects(list.Items, err)
+		},
+		network: func(node client.Object) string {
+			return node.(*ethereum2v1alpha1.Validator).Spec.Network
+		},
+		update: func(node client.Object, image string, resources sharedAPI.Resources) restErrors.IRestErr {
+			return validatorService.Update(validator.ValidatorDto{Image: image, Resources: resources}, node.(*ethereum2v1alpha1.Validator))
+		},
+		delete: func(node client.Object) restErrors.IRestErr {
+			return validatorService.Delete(node.(*ethereum2v1alpha1.Validator))
+		},
+	},
+	{
+		resource: roles.FilecoinNodes,
+		list: func(namespace string) ([]client.Object, restErrors.IRestErr) {
+			list, err := filecoinService.List(namespace)
+			return objects(list.Items, err)
+		},
+		network: func(node client.Object) string {
+			return string(node.(*filecoinv1alpha1.Node).Spec.Network)
+		},
+		update: func(node client.Object, image string, resources sharedAPI.Resources) restErrors.IRestErr {
+			return filecoinService.Update(filecoin.FilecoinDto{Image: image, Resources: resources}, node.(*filecoinv1alpha1.Node))
+		},
+		delete: func(node client.Object) restErrors.IRestErr {
+			return filecoinService.Delete(node.(*filecoinv1alpha1.Node))
+		},
+	},
+	{
+		resource: roles.IPFSPeers,
+		list: func(namespace string) ([]client.Object, restErrors.IRestErr) {
+			list, err := ipfsPeerService.List(namespace)
+			return objects(list.Items, err)
+		},
+		network: func(node client.Object) string {
+			return ""
+		},
+		update: func(node client.Object, image string, resources sharedAPI.Resources) restErrors.IRestErr {
+			return ipfsPeerService.Update(ipfs_peer.PeerDto{Image: image, Resources: resources}, node.(*ipfsv1alpha1.Peer))
+		},
+		delete: func(node client.Object) restErrors.IRestErr {
+			return ipfsPeerService.Delete(node.(*ipfsv1alpha1.Peer))
+		},
+	},
+	{
+		resource: roles.IPFSClusterPeers,
+		list: func(namespace string) ([]client.Object, restErrors.IRestErr) {
+			list, err := ipfsClusterPeerService.List(namespace)
+			return objects(list.Items, err)
+		},
+		network: func(node client.Object) string {
+			return ""
+		},
+		update: func(node client.Object, image string, resources sharedAPI.Resources) restErrors.IRestErr {
+			return ipfsClusterPeerService.Update(ipfs_cluster_peer.ClusterPeerDto{Image: image, Resources: resources}, node.(*ipfsv1alpha1.ClusterPeer))
+		},
+		delete: func(node client.Object) restErrors.IRestErr {
+			return ipfsClusterPeerService.Delete(node.(*ipfsv1alpha1.ClusterPeer))
+		},
+	},
+	{
+		resource: roles.NearNodes,
+		list: func(namespace string) ([]client.Object, restErrors.IRestErr) {
+			list, err := nearService.List(namespace)
+			return objects(list.Items, err)
+		},
+		network: func(node client.Object) string {
+			return node.(*nearv1alpha1.Node).Spec.Network
+		},
+		update: func(node client.Object, image string, resources sharedAPI.Resources) restErrors.IRestErr {
+			return nearService.Update(near.NearDto{Image: image, Resources: resources}, node.(*nearv1alpha1.Node))
+		},
+		delete: func(node client.Object) restErrors.IRestErr {
+			return nearService.Delete(node.(*nearv1alpha1.Node))
+		},
+	},
+	{
+		resource: roles.PolkadotNodes,
+		list: func(namespace string) ([]client.Object, restErrors.IRestErr) {
+			list, err := polkadotService.List(namespace)
+			return objects(list.Items, err)
+		},
+		network: func(node client.Object) string {
+			return node.(*polkadotv1alpha1.Node).Spec.Network
+		},
+		update: func(node client.Object, image string, resources sharedAPI.Resources) restErrors.IRestErr {
+			return polkadotService.Update(polkadot.PolkadotDto{Image: image, Resources: resources}, node.(*polkadotv1alpha1.Node))
+		},
+		delete: func(node client.Object) restErrors.IRestErr {
+			return polkadotService.Delete(node.(*polkadotv1alpha1.Node))
+		},
+	},
+	{
+		resource: roles.BitcoinNodes,
+		list: func(namespace string) ([]client.Object, restErrors.IRestErr) {
+			list, err := bitcoinService.List(namespace)
+			return objects(list.Items, err)
+		},
+		network: func(node client.Object) string {
+			return string(node.(*bitcoinv1alpha1.Node).Spec.Network)
+		},
+		update: func(node client.Object, image string, resources sharedAPI.Resources) restErrors.IRestErr {
+			return bitcoinService.Update(bitcoin.BitcoinDto{Image: image, Resources: resources}, node.(*bitcoinv1alpha1.Node))
+		},
+		delete: func(node client.Object) restErrors.IRestErr {
+			return bitcoinService.Delete(node.(*bitcoinv1alpha1.Node))
+		},
+	},
+	{
+		resource: roles.StacksNodes,
+		list: func(namespace string) ([]client.Object, restErrors.IRestErr) {
+			list, err := stacksService.List(namespace)
+			return objects(list.Items, err)
+		},
+		network: func(node client.Object) string {
+			return string(node.(*stacksv1alpha1.Node).Spec.Network)
+		},
+		update: func(node client.Object, image string, resources sharedAPI.Resources) restErrors.IRestErr {
+			return stacksService.Update(stacks.StacksDto{Image: image, Resources: resources}, node.(*stacksv1alpha1.Node))
+		},
+		delete: func(node client.Object) restErrors.IRestErr {
+			return stacksService.Delete(node.(*stacksv1alpha1.Node))
+		},
+	},
+	{
+		resource: roles.AptosNodes,
+		list: func(namespace string) ([]client.Object, restErrors.IRestErr) {
+			list, err := aptosService.List(namespace)
+			return objects(list.Items, err)
+		},
+		network: func(node client.Object) string {
+			return string(node.(*aptosv1alpha1.Node).Spec.Network)
+		},
+		update: func(node client.Object, image string, resources sharedAPI.Resources) restErrors.IRestErr {
+			return aptosService.Update(aptos.AptosDto{Image: image, Resources: resources}, node.(*aptosv1alpha1.Node))
+		},
+		delete: func(node client.Object) restErrors.IRestErr {
+			return aptosService.Delete(node.(*aptosv1alpha1.Node))
+		},
+	},
+}
+
+// objects returns pointers to the listed nodes
+func objects[T any, PT interface {
+	*T
+	client.Object
+}](items []T, err restErrors.IRestErr) ([]client.Object, restErrors.IRestErr) {
+	if err != nil {
+		return nil, err
+	}
+	result := make([]client.Object, len(items))
+	for i := range items {
+		result[i] = PT(&items[i])
+	}
+	return result, nil
+}
@@ -2,6 +2,7 @@ package chainlink
 
 import (
 	"github.com/kotalco/core-api/k8s"
+	restErrors "github.com/kotalco/core-api/pkg/errors"
 	"github.com/kotalco/core-api/pkg/time"
 	chainlinkv1alpha1 "github.com/kotalco/kotal/apis/chainlink/v1alpha1"
 	sharedAPI "github.com/kotalco/kotal/apis/shared"
@@ -20,6 +21,8 @@ type ChainlinkDto struct {
 	EthereumWSEndpoint         string          `json:"ethereumWsEndpoint"`
 	DatabaseURL                string          `json:"databaseURL"`
 	EthereumHTTPEndpoints      []string        `json:"ethereumHttpEndpoints"`
+	EthereumNodeName           string          `json:"ethereumNodeName"`
+	ManagedDatabase            bool            `json:"managedDatabase"`
 	KeystorePasswordSecretName string          `json:"keystorePasswordSecretName"`
 	APICredentials             *apiCredentials `json:"apiCredentials"`
 	CORSDomains                []string        `json:"corsDomains"`
@@ -36,13 +39,44 @@ type ChainlinkDto struct {
 
 type ChainlinkListDto []ChainlinkDto
 
+// Validate validates the node and its managed database names
+// the ethereum endpoints are given or resolved from the ethereum node, the database url is given or managed
+func (dto ChainlinkDto) Validate() restErrors.IRestErr {
+	names := []string{dto.Name}
+	if dto.ManagedDatabase {
+		names = append(names, dto.Name+postgresSuffix)
+	}
+	for _, name := range names {
+		meta := k8s.MetaDataDto{Name: name}
+		if err := meta.Validate(); err != nil {
+			return err
+		}
+	}
+
+	fields := map[string]string{}
+	if dto.EthereumNodeName != "" && (dto.EthereumWSEndpoint != "" || len(dto.EthereumHTTPEndpoints) != 0) {
+		fields["ethereumNodeName"] = "ethereum node name can't be used with the ethereum endpoints"
+	} else if dto.EthereumNodeName == "" && dto.EthereumWSEndpoint == "" {
+		fields["ethereumWsEndpoint"] = "ethereum ws endpoint or ethereum node name is required"
+	}
+	if dto.ManagedDatabase && dto.DatabaseURL != "" {
+		fields["databaseURL"] = "database url can't be used with the managed database"
+	} else if !dto.ManagedDatabase && dto.DatabaseURL == "" {
+		fields["databaseURL"] = "database url or managed database is required"
+	}
+	if len(fields) > 0 {
+		return restErrors.NewValidationError(fields)
+	}
+	return nil
+}
+
 func (dto ChainlinkDto) FromChainlinkNode(n chainlinkv1alpha1.Node) ChainlinkDto {
 	dto.Name = n.Name
 	dto.Time = time.Time{CreatedAt: n.CreationTimestamp.UTC().Format(time.JavascriptISOString)}
 	dto.EthereumChainId = n.Spec.EthereumChainId
 	dto.LinkContractAddress = n.Spec.LinkContractAddress
 	dto.EthereumWSEndpoint = n.Spec.EthereumWSEndpoint
-	dto.DatabaseURL = n.Spec.DatabaseURL
+	dto.DatabaseURL, dto.ManagedDatabase = redactedDatabaseURL(n)
 	dto.EthereumHTTPEndpoints = n.Spec.EthereumHTTPEndpoints
 	dto.KeystorePasswordSecretName = n.Spec.KeystorePasswordSecretName
 	dto.APICredentials = &apiCredentials{
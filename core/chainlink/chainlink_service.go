@@ -5,6 +5,7 @@ package chainlink
 import (
 	"context"
 	"fmt"
+	"github.com/kotalco/core-api/core/ethereum"
 	"github.com/kotalco/core-api/k8s"
 	restErrors "github.com/kotalco/core-api/pkg/errors"
 	"github.com/kotalco/core-api/pkg/logger"
@@ -26,10 +27,29 @@ type IService interface {
 	List(namespace string) (chainlinkv1alpha1.NodeList, restErrors.IRestErr)
 	Count(namespace string) (int, restErrors.IRestErr)
 	Delete(*chainlinkv1alpha1.Node) restErrors.IRestErr
+	// Jobs lists the node jobs with the errors they hit
+	Jobs(chainlinkv1alpha1.Node) ([]JobDto, restErrors.IRestErr)
+	// CreateJob creates the TOML job spec on the node
+	CreateJob(chainlinkv1alpha1.Node, JobRequestDto) (JobDto, restErrors.IRestErr)
+	// DeleteJob deletes the node job by id
+	DeleteJob(node chainlinkv1alpha1.Node, id string) restErrors.IRestErr
+	// RunJob triggers a run of the node webhook job by id
+	RunJob(node chainlinkv1alpha1.Node, id string) (JobRunDto, restErrors.IRestErr)
+	// JobRuns lists the node job runs by id with their errors
+	JobRuns(node chainlinkv1alpha1.Node, id string) ([]JobRunDto, restErrors.IRestErr)
+	// Bridges lists the node bridges
+	Bridges(chainlinkv1alpha1.Node) ([]BridgeDto, restErrors.IRestErr)
+	// CreateBridge creates the external adapter bridge on the node
+	CreateBridge(chainlinkv1alpha1.Node, BridgeDto) (BridgeDto, restErrors.IRestErr)
+	// DeleteBridge deletes the node bridge by name
+	DeleteBridge(node chainlinkv1alpha1.Node, name string) restErrors.IRestErr
+	// Stats returns the node jobs count, health checks and eth keys balances
+	Stats(chainlinkv1alpha1.Node) (StatsDto, restErrors.IRestErr)
 }
 
 var (
-	k8sClient = k8s.NewClientService()
+	k8sClient       = k8s.NewClientService()
+	ethereumService = ethereum.NewEthereumService()
 )
 
 func NewChainLinkService() IService {
@@ -52,7 +72,24 @@ func (service chainlinkService) Get(namespacedName types.NamespacedName) (node c
 }
 
 // Create creates chainlink node from the given spec
+// the ethereum endpoints are resolved from the ethereum node if referenced, the managed database is created once the node exists to be owned by it
 func (service chainlinkService) Create(dto ChainlinkDto) (node chainlinkv1alpha1.Node, restErr restErrors.IRestErr) {
+	if dto.EthereumNodeName != "" {
+		if restErr = service.resolveEthereumNode(&dto); restErr != nil {
+			return
+		}
+	}
+
+	var databasePassword string
+	if dto.ManagedDatabase {
+		var err error
+		dto.DatabaseURL, databasePassword, err = databaseURL(dto.Name, dto.Namespace)
+		if err != nil {
+			go logger.Error(service.Create, err)
+			restErr = restErrors.NewInternalServerError("failed to create node")
+			return
+		}
+	}
 
 	node.ObjectMeta = dto.ObjectMetaFromMetadataDto()
 	node.Spec = chainlinkv1alpha1.NodeSpec{
@@ -60,6 +97,7 @@ func (service chainlinkService) Create(dto ChainlinkDto) (node chainlinkv1alpha1
 		LinkContractAddress:        dto.LinkContractAddress,
 		EthereumWSEndpoint:         dto.EthereumWSEndpoint,
 		DatabaseURL:                dto.DatabaseURL,
+		EthereumHTTPEndpoints:      dto.EthereumHTTPEndpoints,
 		KeystorePasswordSecretName: dto.KeystorePasswordSecretName,
 		Image:                      dto.Image,
 		APICredentials: chainlinkv1alpha1.APICredentials{
@@ -69,6 +107,7 @@ func (service chainlinkService) Create(dto ChainlinkDto) (node chainlinkv1alpha1
 	}
 
 	k8s.DefaultResources(&node.Spec.Resources)
+	k8s.RequestedStorage(&node.Spec.Resources, dto.Resources)
 
 	if os.Getenv("MOCK") == "true" {
 		node.Default()
@@ -85,6 +124,16 @@ func (service chainlinkService) Create(dto ChainlinkDto) (node chainlinkv1alpha1
 		return
 	}
 
+	if dto.ManagedDatabase {
+		if restErr = service.createDatabase(node, databasePassword, dto.StorageClass); restErr != nil {
+			// the created database resources are garbage collected with the node
+			if err := k8sClient.Delete(context.Background(), &node); err != nil {
+				go logger.Warn(service.Create, err)
+			}
+			return
+		}
+	}
+
 	return
 }
 
@@ -95,7 +144,8 @@ func (service chainlinkService) Update(dto ChainlinkDto, node *chainlinkv1alpha1
 		node.Spec.EthereumWSEndpoint = dto.EthereumWSEndpoint
 	}
 
-	if dto.DatabaseURL != "" {
+	// the redacted managed database url is sent back as is, it doesn't replace the one holding the password
+	if redacted, _ := redactedDatabaseURL(*node); dto.DatabaseURL != "" && dto.DatabaseURL != redacted {
 		node.Spec.DatabaseURL = dto.DatabaseURL
 	}
 
@@ -0,0 +1,447 @@
+package chainlink
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	restErrors "github.com/kotalco/core-api/pkg/errors"
+	"github.com/kotalco/core-api/pkg/logger"
+	chainlinkv1alpha1 "github.com/kotalco/kotal/apis/chainlink/v1alpha1"
+	"io"
+	"io/ioutil"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+)
+
+// sessionCookie is the chainlink operator api session cookie
+const sessionCookie = "clsession"
+
+// bridgeNamePattern is the chainlink bridge name rule
+var bridgeNamePattern = regexp.MustCompile(`^[a-z0-9_-]+$`)
+
+// jobTypePattern matches the job spec type key line with a non-empty string value
+var jobTypePattern = regexp.MustCompile(`^\s*type\s*=\s*("[^"]+"|'[^']+')\s*(#.*)?$`)
+
+var (
+	// apiClient calls the node operator api, cookies are sent by hand because secure cookies aren't sent over the in-cluster http
+	apiClient = &http.Client{Timeout: 30 * time.Second}
+	// sessions caches the nodes api session cookies by node namespaced name, a session is renewed once the node rejects it
+	sessions sync.Map
+)
+
+// JobDto is a job on the node with the errors it hit
+type JobDto struct {
+	ID            string   `json:"id"`
+	ExternalJobID string   `json:"externalJobId"`
+	Name          string   `json:"name"`
+	Type          string   `json:"type"`
+	SchemaVersion uint     `json:"schemaVersion"`
+	CreatedAt     string   `json:"createdAt"`
+	Errors        []string `json:"errors"`
+}
+
+// JobRequestDto is the TOML job spec to create on the node
+type JobRequestDto struct {
+	TOML string `json:"toml"`
+}
+
+// JobRunDto is a job pipeline run, errors are the pipeline tasks errors
+type JobRunDto struct {
+	ID         string          `json:"id"`
+	State      string          `json:"state"`
+	Outputs    json.RawMessage `json:"outputs"`
+	Errors     []string        `json:"errors"`
+	CreatedAt  string          `json:"createdAt"`
+	FinishedAt string          `json:"finishedAt"`
+}
+
+// BridgeDto is an external adapter bridge, the tokens are returned once on creation
+type BridgeDto struct {
+	Name                   string `json:"name"`
+	URL                    string `json:"url"`
+	Confirmations          uint32 `json:"confirmations"`
+	MinimumContractPayment string `json:"minimumContractPayment"`
+	IncomingToken          string `json:"incomingToken,omitempty"`
+	OutgoingToken          string `json:"outgoingToken,omitempty"`
+}
+
+// StatsDto is the node jobs count, health checks and eth keys balances
+type StatsDto struct {
+	JobsCount     int      `json:"jobsCount"`
+	Healthy       bool     `json:"healthy"`
+	FailingChecks []string `json:"failingChecks"`
+	Keys          []KeyDto `json:"keys"`
+}
+
+// KeyDto is a node eth key with its balances
+type KeyDto struct {
+	Address     string `json:"address"`
+	ETHBalance  string `json:"ethBalance"`
+	LINKBalance string `json:"linkBalance"`
+}
+
+// resource is a JSON:API resource returned by the node api
+type resource struct {
+	ID         string          `json:"id"`
+	Attributes json.RawMessage `json:"attributes"`
+}
+
+// Validate validates the job spec is a TOML job with a non-empty type key at its top level, before any table
+func (dto JobRequestDto) Validate() restErrors.IRestErr {
+	for _, line := range strings.Split(dto.TOML, "\n") {
+		if strings.HasPrefix(strings.TrimSpace(line), "[") {
+			break
+		}
+		if jobTypePattern.MatchString(line) {
+			return nil
+		}
+	}
+	return restErrors.NewValidationError(map[string]string{"toml": "toml must be a job spec with a type"})
+}
+
+// Validate validates the bridge name and url
+func (dto BridgeDto) Validate() restErrors.IRestErr {
+	fields := map[string]string{}
+	if !bridgeNamePattern.MatchString(dto.Name) {
+		fields["name"] = "name must be lowercase letters, digits, dashes or underscores"
+	}
+	if parsed, err := url.Parse(dto.URL); err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+		fields["url"] = "url must be a valid http or https url"
+	}
+	if len(fields) > 0 {
+		return restErrors.NewValidationError(fields)
+	}
+	return nil
+}
+
+// Jobs lists the node jobs
+func (service chainlinkService) Jobs(node chainlinkv1alpha1.Node) ([]JobDto, restErrors.IRestErr) {
+	var resources []resource
+	if restErr := service.call(node, http.MethodGet, "/v2/jobs", nil, &resources); restErr != nil {
+		return nil, restErr
+	}
+	jobs := make([]JobDto, 0, len(resources))
+	for _, v := range resources {
+		jobs = append(jobs, jobFromResource(v))
+	}
+	return jobs, nil
+}
+
+// CreateJob creates the TOML job spec on the node, the node validates the spec
+func (service chainlinkService) CreateJob(node chainlinkv1alpha1.Node, dto JobRequestDto) (JobDto, restErrors.IRestErr) {
+	var created resource
+	if restErr := service.call(node, http.MethodPost, "/v2/jobs", map[string]string{"toml": dto.TOML}, &created); restErr != nil {
+		return JobDto{}, restErr
+	}
+	return jobFromResource(created), nil
+}
+
+// DeleteJob deletes the node job by id
+func (service chainlinkService) DeleteJob(node chainlinkv1alpha1.Node, id string) restErrors.IRestErr {
+	return service.call(node, http.MethodDelete, fmt.Sprintf("/v2/jobs/%s", url.PathEscape(id)), nil, nil)
+}
+
+// RunJob triggers a run of the node job by id, only webhook jobs can be run on demand
+func (service chainlinkService) RunJob(node chainlinkv1alpha1.Node, id string) (JobRunDto, restErrors.IRestErr) {
+	var job resource
+	if restErr := service.call(node, http.MethodGet, fmt.Sprintf("/v2/jobs/%s", url.PathEscape(id)), nil, &job); restErr != nil {
+		return JobRunDto{}, restErr
+	}
+	// runs are triggered by the job external id
+	var run resource
+	if restErr := service.call(node, http.MethodPost, fmt.Sprintf("/v2/jobs/%s/runs", url.PathEscape(jobFromResource(job).ExternalJobID)), nil, &run); restErr != nil {
+		return JobRunDto{}, restErr
+	}
+	return runFromResource(run), nil
+}
+
+// JobRuns lists the node job runs by id with their errors
+func (service chainlinkService) JobRuns(node chainlinkv1alpha1.Node, id string) ([]JobRunDto, restErrors.IRestErr) {
+	var resources []resource
+	if restErr := service.call(node, http.MethodGet, fmt.Sprintf("/v2/jobs/%s/runs", url.PathEscape(id)), nil, &resources); restErr != nil {
+		return nil, restErr
+	}
+	runs := make([]JobRunDto, 0, len(resources))
+	for _, v := range resources {
+		runs = append(runs, runFromResource(v))
+	}
+	return runs, nil
+}
+
+// Bridges lists the node bridges
+func (service chainlinkService) Bridges(node chainlinkv1alpha1.Node) ([]BridgeDto, restErrors.IRestErr) {
+	var resources []resource
+	if restErr := service.call(node, http.MethodGet, "/v2/bridge_types", nil, &resources); restErr != nil {
+		return nil, restErr
+	}
+	bridges := make([]BridgeDto, 0, len(resources))
+	for _, v := range resources {
+		bridges = append(bridges, bridgeFromResource(v))
+	}
+	return bridges, nil
+}
+
+// CreateBridge creates the bridge on the node, the returned tokens authenticate the node and the external adapter
+func (service chainlinkService) CreateBridge(node chainlinkv1alpha1.Node, dto BridgeDto) (BridgeDto, restErrors.IRestErr) {
+	body := map[string]interface{}{
+		"name":                   dto.Name,
+		"url":                    dto.URL,
+		"confirmations":          dto.Confirmations,
+		"minimumContractPayment": dto.MinimumContractPayment,
+	}
+	var created resource
+	if restErr := service.call(node, http.MethodPost, "/v2/bridge_types", body, &created); restErr != nil {
+		return BridgeDto{}, restErr
+	}
+	return bridgeFromResource(created), nil
+}
+
+// DeleteBridge deletes the node bridge by name
+func (service chainlinkService) DeleteBridge(node chainlinkv1alpha1.Node, name string) restErrors.IRestErr {
+	return service.call(node, http.MethodDelete, fmt.Sprintf("/v2/bridge_types/%s", url.PathEscape(name)), nil, nil)
+}
+
+// Stats calls the node jobs, health and eth keys endpoints
+func (service chainlinkService) Stats(node chainlinkv1alpha1.Node) (stats StatsDto, restErr restErrors.IRestErr) {
+	jobs, restErr := service.Jobs(node)
+	if restErr != nil {
+		return
+	}
+	stats.JobsCount = len(jobs)
+
+	checks, restErr := service.health(node)
+	if restErr != nil {
+		return
+	}
+	stats.FailingChecks = []string{}
+	for _, v := range checks {
+		var check struct {
+			Name   string `json:"name"`
+			Status string `json:"status"`
+		}
+		json.Unmarshal(v.Attributes, &check)
+		if check.Status != "passing" {
+			stats.FailingChecks = append(stats.FailingChecks, check.Name)
+		}
+	}
+	stats.Healthy = len(stats.FailingChecks) == 0
+
+	var keys []resource
+	if restErr = service.call(node, http.MethodGet, "/v2/keys/eth", nil, &keys); restErr != nil {
+		return
+	}
+	stats.Keys = make([]KeyDto, 0, len(keys))
+	for _, v := range keys {
+		var key KeyDto
+		json.Unmarshal(v.Attributes, &key)
+		stats.Keys = append(stats.Keys, key)
+	}
+	return
+}
+
+// health returns the node health checks, the endpoint doesn't need a session and responds with 503 if any check is failing
+func (service chainlinkService) health(node chainlinkv1alpha1.Node) ([]resource, restErrors.IRestErr) {
+	apiURL, restErr := service.apiURL(node)
+	if restErr != nil {
+		return nil, restErr
+	}
+	resp, err := apiClient.Get(apiURL + "/health")
+	if err != nil {
+		go logger.Warn(service.health, err)
+		return nil, restErrors.NewBadRequestError(fmt.Sprintf("can't reach node by name %s api", node.Name))
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusServiceUnavailable {
+		resp.StatusCode = http.StatusOK
+	}
+
+	var checks []resource
+	return checks, decode(node, resp, &checks)
+}
+
+// apiURL returns the operator api url of the running node pod, requests don't leave the cluster
+func (service chainlinkService) apiURL(node chainlinkv1alpha1.Node) (string, restErrors.IRestErr) {
+	if !node.Spec.API {
+		return "", restErrors.NewBadRequestError("node api is not enabled")
+	}
+	pod := &corev1.Pod{}
+	if err := k8sClient.Get(context.Background(), types.NamespacedName{Namespace: node.Namespace, Name: fmt.Sprintf("%s-0", node.Name)}, pod); err != nil || pod.Status.PodIP == "" {
+		return "", restErrors.NewBadRequestError(fmt.Sprintf("node by name %s isn't running", node.Name))
+	}
+	return fmt.Sprintf("http://%s:%d", pod.Status.PodIP, node.Spec.APIPort), nil
+}
+
+// login creates an operator api session using the node api credentials password secret
+func (service chainlinkService) login(node chainlinkv1alpha1.Node, apiURL string) (string, restErrors.IRestErr) {
+	secret := &corev1.Secret{}
+	key := types.NamespacedName{Namespace: node.Namespace, Name: node.Spec.APICredentials.PasswordSecretName}
+	if err := k8sClient.Get(context.Background(), key, secret); err != nil {
+		return "", restErrors.NewBadRequestError(fmt.Sprintf("can't get node by name %s api credentials password secret %s", node.Name, key.Name))
+	}
+
+	body, _ := json.Marshal(map[string]string{"email": node.Spec.APICredentials.Email, "password": string(secret.Data["password"])})
+	resp, err := apiClient.Post(apiURL+"/sessions", "application/json", bytes.NewReader(body))
+	if err != nil {
+		go logger.Warn(service.login, err)
+		return "", restErrors.NewBadRequestError(fmt.Sprintf("can't reach node by name %s api", node.Name))
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", restErrors.NewBadRequestError(fmt.Sprintf("node by name %s api rejected the api credentials", node.Name))
+	}
+	for _, cookie := range resp.Cookies() {
+		if cookie.Name == sessionCookie {
+			sessions.Store(key.Namespace+"/"+node.Name, cookie.Value)
+			return cookie.Value, nil
+		}
+	}
+	return "", restErrors.NewInternalServerError(fmt.Sprintf("node by name %s api didn't return a session", node.Name))
+}
+
+// call calls the node operator api with the cached session, the session is renewed once if the node rejects it
+// the JSON:API data of the response is unmarshalled into out
+func (service chainlinkService) call(node chainlinkv1alpha1.Node, method string, path string, body interface{}, out interface{}) restErrors.IRestErr {
+	apiURL, restErr := service.apiURL(node)
+	if restErr != nil {
+		return restErr
+	}
+
+	var raw []byte
+	if body != nil {
+		raw, _ = json.Marshal(body)
+	}
+
+	sessionKey := node.Namespace + "/" + node.Name
+	for attempt := 0; ; attempt++ {
+		session, ok := sessions.Load(sessionKey)
+		if !ok {
+			if session, restErr = service.login(node, apiURL); restErr != nil {
+				return restErr
+			}
+		}
+
+		resp, restErr := service.request(node, method, apiURL+path, session.(string), bytes.NewReader(raw))
+		if restErr != nil {
+			return restErr
+		}
+		if resp.StatusCode == http.StatusUnauthorized && attempt == 0 {
+			resp.Body.Close()
+			sessions.Delete(sessionKey)
+			continue
+		}
+		defer resp.Body.Close()
+		return decode(node, resp, out)
+	}
+}
+
+// request sends the request with the session cookie
+func (service chainlinkService) request(node chainlinkv1alpha1.Node, method string, url string, session string, body io.Reader) (*http.Response, restErrors.IRestErr) {
+	req, err := http.NewRequest(method, url, body)
+	if err != nil {
+		go logger.Error(service.request, err)
+		return nil, restErrors.NewInternalServerError("something went wrong")
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.AddCookie(&http.Cookie{Name: sessionCookie, Value: session})
+
+	resp, err := apiClient.Do(req)
+	if err != nil {
+		go logger.Warn(service.request, err)
+		return nil, restErrors.NewBadRequestError(fmt.Sprintf("can't reach node by name %s api", node.Name))
+	}
+	return resp, nil
+}
+
+// decode unmarshalls the response JSON:API data into out, the node api errors are converted to rest errors
+func decode(node chainlinkv1alpha1.Node, resp *http.Response, out interface{}) restErrors.IRestErr {
+	raw, _ := ioutil.ReadAll(resp.Body)
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		var apiErr struct {
+			Errors []struct {
+				Detail string `json:"detail"`
+			} `json:"errors"`
+		}
+		json.Unmarshal(raw, &apiErr)
+		details := []string{}
+		for _, v := range apiErr.Errors {
+			details = append(details, v.Detail)
+		}
+		message := strings.Join(details, ", ")
+		if resp.StatusCode == http.StatusNotFound {
+			return restErrors.NewNotFoundError(message)
+		}
+		return restErrors.NewBadRequestError(fmt.Sprintf("node by name %s api returned %d: %s", node.Name, resp.StatusCode, message))
+	}
+	if out == nil || len(raw) == 0 {
+		return nil
+	}
+
+	var document struct {
+		Data json.RawMessage `json:"data"`
+	}
+	if err := json.Unmarshal(raw, &document); err != nil || json.Unmarshal(document.Data, out) != nil {
+		return restErrors.NewInternalServerError(fmt.Sprintf("can't read node by name %s api response", node.Name))
+	}
+	return nil
+}
+
+func jobFromResource(v resource) JobDto {
+	var attributes struct {
+		Name          string `json:"name"`
+		Type          string `json:"type"`
+		SchemaVersion uint   `json:"schemaVersion"`
+		ExternalJobID string `json:"externalJobID"`
+		CreatedAt     string `json:"createdAt"`
+		Errors        []struct {
+			Description string `json:"description"`
+		} `json:"errors"`
+	}
+	json.Unmarshal(v.Attributes, &attributes)
+
+	job := JobDto{ID: v.ID, ExternalJobID: attributes.ExternalJobID, Name: attributes.Name, Type: attributes.Type, SchemaVersion: attributes.SchemaVersion, CreatedAt: attributes.CreatedAt, Errors: []string{}}
+	for _, e := range attributes.Errors {
+		job.Errors = append(job.Errors, e.Description)
+	}
+	return job
+}
+
+func runFromResource(v resource) JobRunDto {
+	var attributes struct {
+		State       string          `json:"state"`
+		Outputs     json.RawMessage `json:"outputs"`
+		AllErrors   []string        `json:"allErrors"`
+		FatalErrors []*string       `json:"fatalErrors"`
+		CreatedAt   string          `json:"createdAt"`
+		FinishedAt  string          `json:"finishedAt"`
+	}
+	json.Unmarshal(v.Attributes, &attributes)
+
+	run := JobRunDto{ID: v.ID, State: attributes.State, Outputs: attributes.Outputs, CreatedAt: attributes.CreatedAt, FinishedAt: attributes.FinishedAt, Errors: []string{}}
+	seen := map[string]bool{}
+	for _, e := range attributes.AllErrors {
+		if e != "" && !seen[e] {
+			seen[e] = true
+			run.Errors = append(run.Errors, e)
+		}
+	}
+	for _, e := range attributes.FatalErrors {
+		if e != nil && *e != "" && !seen[*e] {
+			seen[*e] = true
+			run.Errors = append(run.Errors, *e)
+		}
+	}
+	return run
+}
+
+func bridgeFromResource(v resource) BridgeDto {
+	var bridge BridgeDto
+	json.Unmarshal(v.Attributes, &bridge)
+	return bridge
+}
@@ -0,0 +1,285 @@
+package chainlink
+
+import (
+	"context"
+	"encoding/json"
+	restErrors "github.com/kotalco/core-api/pkg/errors"
+	chainlinkv1alpha1 "github.com/kotalco/kotal/apis/chainlink/v1alpha1"
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"strconv"
+	"testing"
+)
+
+/*
+k8s client mocks
+*/
+var (
+	k8sClientGetFunc    func(key client.ObjectKey, obj client.Object) error
+	k8sClientCreateFunc func(obj client.Object) error
+	k8sClientDeleteFunc func(obj client.Object) error
+)
+
+type k8sClientMock struct{}
+
+func (k8sClientMock) Get(ctx context.Context, key client.ObjectKey, obj client.Object, opts ...client.GetOption) error {
+	return k8sClientGetFunc(key, obj)
+}
+func (k8sClientMock) List(ctx context.Context, list client.ObjectList, opts ...client.ListOption) error {
+	return nil
+}
+func (k8sClientMock) Create(ctx context.Context, obj client.Object, opts ...client.CreateOption) error {
+	return k8sClientCreateFunc(obj)
+}
+func (k8sClientMock) Delete(ctx context.Context, obj client.Object, opts ...client.DeleteOption) error {
+	return k8sClientDeleteFunc(obj)
+}
+func (k8sClientMock) Update(ctx context.Context, obj client.Object, opts ...client.UpdateOption) error {
+	return nil
+}
+func (k8sClientMock) Patch(ctx context.Context, obj client.Object, patch client.Patch, opts ...client.PatchOption) error {
+	return nil
+}
+func (k8sClientMock) DeleteAllOf(ctx context.Context, obj client.Object, opts ...client.DeleteAllOfOption) error {
+	return nil
+}
+
+var service IService
+
+func TestMain(m *testing.M) {
+	k8sClient = &k8sClientMock{}
+	k8sClientGetFunc = func(key client.ObjectKey, obj client.Object) error {
+		switch v := obj.(type) {
+		case *corev1.Pod:
+			v.Status.PodIP = "127.0.0.1"
+		case *corev1.Secret:
+			v.Data = map[string][]byte{"password": []byte("secret")}
+		}
+		return nil
+	}
+	k8sClientCreateFunc = func(obj client.Object) error {
+		return nil
+	}
+	k8sClientDeleteFunc = func(obj client.Object) error {
+		return nil
+	}
+	ethereumService = &ethereumServiceMock{}
+	service = NewChainLinkService()
+
+	code := m.Run()
+	os.Exit(code)
+}
+
+// nodeServer serves the handler as the operator api of a running node, sessions are created for the node api credentials
+func nodeServer(t *testing.T, handler http.HandlerFunc) (*httptest.Server, chainlinkv1alpha1.Node) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/sessions", func(w http.ResponseWriter, r *http.Request) {
+		var credentials map[string]string
+		json.NewDecoder(r.Body).Decode(&credentials)
+		if credentials["email"] != "admin@kotal.co" || credentials["password"] != "secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		http.SetCookie(w, &http.Cookie{Name: sessionCookie, Value: "session", Secure: true})
+	})
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/health" {
+			cookie, err := r.Cookie(sessionCookie)
+			if err != nil || cookie.Value != "session" {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+		}
+		handler(w, r)
+	})
+
+	server := httptest.NewServer(mux)
+	_, port, _ := net.SplitHostPort(server.Listener.Addr().String())
+	apiPort, _ := strconv.Atoi(port)
+	// every test server is a new node
+	sessions.Delete("default/" + t.Name())
+
+	return server, chainlinkv1alpha1.Node{
+		ObjectMeta: metav1.ObjectMeta{Name: t.Name(), Namespace: "default"},
+		Spec: chainlinkv1alpha1.NodeSpec{
+			API:            true,
+			APIPort:        uint(apiPort),
+			APICredentials: chainlinkv1alpha1.APICredentials{Email: "admin@kotal.co", PasswordSecretName: "api-password"},
+		},
+	}
+}
+
+func TestJobRequestDto_Validate(t *testing.T) {
+	t.Run("validate_should_pass", func(t *testing.T) {
+		assert.Nil(t, JobRequestDto{TOML: `type = "webhook"`}.Validate())
+	})
+
+	t.Run("validate_should_throw_if_toml_is_empty", func(t *testing.T) {
+		err := JobRequestDto{}.Validate()
+		assert.Contains(t, err.(restErrors.RestErr).Validations, "toml")
+	})
+
+	t.Run("validate_should_throw_if_type_is_only_mentioned", func(t *testing.T) {
+		for _, toml := range []string{
+			"# type = \"webhook\"\nname = \"job\"",
+			"name = \"type\"",
+			"type = \"\"",
+			"[pipeline]\ntype = \"webhook\"",
+		} {
+			err := JobRequestDto{TOML: toml}.Validate()
+			assert.Contains(t, err.(restErrors.RestErr).Validations, "toml", toml)
+		}
+	})
+
+	t.Run("validate_should_pass_if_type_is_after_other_keys", func(t *testing.T) {
+		assert.Nil(t, JobRequestDto{TOML: "schemaVersion = 1\n  type = 'directrequest' # comment\nname = \"job\""}.Validate())
+	})
+}
+
+func TestBridgeDto_Validate(t *testing.T) {
+	t.Run("validate_should_pass", func(t *testing.T) {
+		assert.Nil(t, BridgeDto{Name: "price_feed", URL: "http://adapter:8080"}.Validate())
+	})
+
+	t.Run("validate_should_throw_if_name_and_url_are_invalid", func(t *testing.T) {
+		err := BridgeDto{Name: "Price Feed", URL: "adapter"}.Validate()
+		assert.Contains(t, err.(restErrors.RestErr).Validations, "name")
+		assert.Contains(t, err.(restErrors.RestErr).Validations, "url")
+	})
+}
+
+func TestService_Jobs(t *testing.T) {
+	t.Run("jobs_should_list_the_node_jobs_with_their_errors", func(t *testing.T) {
+		server, node := nodeServer(t, func(w http.ResponseWriter, r *http.Request) {
+			assert.EqualValues(t, "/v2/jobs", r.URL.Path)
+			w.Write([]byte(`{"data":[{"id":"1","type":"jobs","attributes":{"name":"eth-usd","type":"webhook","schemaVersion":1,"externalJobID":"0eec7e1d-d0d2-476c-a1a8-72dfb6633f46","errors":[{"description":"task failed"}]}}]}`))
+		})
+		defer server.Close()
+
+		jobs, err := service.Jobs(node)
+		assert.Nil(t, err)
+		assert.EqualValues(t, []JobDto{{ID: "1", ExternalJobID: "0eec7e1d-d0d2-476c-a1a8-72dfb6633f46", Name: "eth-usd", Type: "webhook", SchemaVersion: 1, Errors: []string{"task failed"}}}, jobs)
+	})
+
+	t.Run("jobs_should_renew_the_session_if_the_node_rejects_it", func(t *testing.T) {
+		server, node := nodeServer(t, func(w http.ResponseWriter, r *http.Request) {
+			w.Write([]byte(`{"data":[]}`))
+		})
+		defer server.Close()
+		sessions.Store("default/"+node.Name, "expired")
+
+		jobs, err := service.Jobs(node)
+		assert.Nil(t, err)
+		assert.Empty(t, jobs)
+	})
+
+	t.Run("jobs_should_throw_if_api_credentials_are_wrong", func(t *testing.T) {
+		server, node := nodeServer(t, func(w http.ResponseWriter, r *http.Request) {})
+		defer server.Close()
+		node.Spec.APICredentials.Email = "someone@kotal.co"
+
+		_, err := service.Jobs(node)
+		assert.EqualValues(t, http.StatusBadRequest, err.StatusCode())
+		assert.Contains(t, err.Error(), "rejected the api credentials")
+	})
+
+	t.Run("jobs_should_throw_if_node_api_is_disabled", func(t *testing.T) {
+		_, err := service.Jobs(chainlinkv1alpha1.Node{})
+		assert.EqualValues(t, "node api is not enabled", err.Error())
+	})
+}
+
+func TestService_CreateJob(t *testing.T) {
+	t.Run("create_job_should_surface_the_node_spec_errors", func(t *testing.T) {
+		server, node := nodeServer(t, func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte(`{"errors":[{"detail":"unsupported job type"}]}`))
+		})
+		defer server.Close()
+
+		_, err := service.CreateJob(node, JobRequestDto{TOML: `type = "unknown"`})
+		assert.EqualValues(t, http.StatusBadRequest, err.StatusCode())
+		assert.Contains(t, err.Error(), "unsupported job type")
+	})
+}
+
+func TestService_RunJob(t *testing.T) {
+	t.Run("run_job_should_trigger_the_run_by_external_job_id", func(t *testing.T) {
+		server, node := nodeServer(t, func(w http.ResponseWriter, r *http.Request) {
+			switch r.URL.Path {
+			case "/v2/jobs/1":
+				w.Write([]byte(`{"data":{"id":"1","attributes":{"externalJobID":"0eec7e1d-d0d2-476c-a1a8-72dfb6633f46"}}}`))
+			case "/v2/jobs/0eec7e1d-d0d2-476c-a1a8-72dfb6633f46/runs":
+				assert.EqualValues(t, http.MethodPost, r.Method)
+				w.Write([]byte(`{"data":{"id":"7","attributes":{"state":"errored","allErrors":["http task failed",""],"fatalErrors":[null,"http task failed"]}}}`))
+			default:
+				w.WriteHeader(http.StatusNotFound)
+			}
+		})
+		defer server.Close()
+
+		run, err := service.RunJob(node, "1")
+		assert.Nil(t, err)
+		assert.EqualValues(t, "errored", run.State)
+		assert.EqualValues(t, []string{"http task failed"}, run.Errors)
+	})
+
+	t.Run("run_job_should_throw_if_job_doesn't_exist", func(t *testing.T) {
+		server, node := nodeServer(t, func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"errors":[{"detail":"JobSpec not found"}]}`))
+		})
+		defer server.Close()
+
+		_, err := service.RunJob(node, "2")
+		assert.EqualValues(t, http.StatusNotFound, err.StatusCode())
+	})
+}
+
+func TestService_CreateBridge(t *testing.T) {
+	t.Run("create_bridge_should_return_the_bridge_tokens", func(t *testing.T) {
+		server, node := nodeServer(t, func(w http.ResponseWriter, r *http.Request) {
+			var body map[string]interface{}
+			json.NewDecoder(r.Body).Decode(&body)
+			assert.EqualValues(t, "price_feed", body["name"])
+			w.WriteHeader(http.StatusCreated)
+			w.Write([]byte(`{"data":{"id":"price_feed","attributes":{"name":"price_feed","url":"http://adapter:8080","incomingToken":"in","outgoingToken":"out"}}}`))
+		})
+		defer server.Close()
+
+		bridge, err := service.CreateBridge(node, BridgeDto{Name: "price_feed", URL: "http://adapter:8080"})
+		assert.Nil(t, err)
+		assert.EqualValues(t, "in", bridge.IncomingToken)
+		assert.EqualValues(t, "out", bridge.OutgoingToken)
+	})
+}
+
+func TestService_Stats(t *testing.T) {
+	t.Run("stats_should_report_failing_checks_and_keys_balances", func(t *testing.T) {
+		server, node := nodeServer(t, func(w http.ResponseWriter, r *http.Request) {
+			switch r.URL.Path {
+			case "/v2/jobs":
+				w.Write([]byte(`{"data":[{"id":"1"},{"id":"2"}]}`))
+			case "/health":
+				w.WriteHeader(http.StatusServiceUnavailable)
+				w.Write([]byte(`{"data":[{"id":"EVM.1","attributes":{"name":"EVM.1","status":"failing"}},{"id":"Mailbox","attributes":{"name":"Mailbox","status":"passing"}}]}`))
+			case "/v2/keys/eth":
+				w.Write([]byte(`{"data":[{"id":"0x1","attributes":{"address":"0x1","ethBalance":"1.5","linkBalance":"10"}}]}`))
+			}
+		})
+		defer server.Close()
+
+		stats, err := service.Stats(node)
+		assert.Nil(t, err)
+		assert.EqualValues(t, 2, stats.JobsCount)
+		assert.False(t, stats.Healthy)
+		assert.EqualValues(t, []string{"EVM.1"}, stats.FailingChecks)
+		assert.EqualValues(t, []KeyDto{{Address: "0x1", ETHBalance: "1.5", LINKBalance: "10"}}, stats.Keys)
+	})
+}
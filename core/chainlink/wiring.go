@@ -0,0 +1,196 @@
+package chainlink
+
+import (
+	"context"
+	"fmt"
+	restErrors "github.com/kotalco/core-api/pkg/errors"
+	"github.com/kotalco/core-api/pkg/logger"
+	"github.com/kotalco/core-api/pkg/security"
+	chainlinkv1alpha1 "github.com/kotalco/kotal/apis/chainlink/v1alpha1"
+	ethereumv1alpha1 "github.com/kotalco/kotal/apis/ethereum/v1alpha1"
+	"github.com/ybbus/jsonrpc/v2"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	k8sresource "k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"net/http"
+	"net/url"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// postgresSuffix names the managed database statefulset, service and password secret after the node
+	postgresSuffix = "-postgres"
+	// postgresImage is the managed database image
+	postgresImage = "postgres:16-alpine"
+	// postgresPort is the managed database port
+	postgresPort = 5432
+	// postgresDatabase is the managed database name
+	postgresDatabase = "chainlink"
+	// postgresStorage is the managed database requested storage
+	postgresStorage = "10Gi"
+	// postgresPasswordLength is the managed database generated password length
+	postgresPasswordLength = 32
+)
+
+// rpcClient calls the ethereum node rpc to check the chain it serves
+var rpcClient = &http.Client{Timeout: 5 * time.Second}
+
+// resolveEthereumNode wires the chainlink node to the in-cluster ethereum node ws and rpc services
+// the ethereum node must serve the chain the chainlink node is configured for
+func (service chainlinkService) resolveEthereumNode(dto *ChainlinkDto) restErrors.IRestErr {
+	node, restErr := ethereumService.Get(types.NamespacedName{Namespace: dto.Namespace, Name: dto.EthereumNodeName})
+	if restErr != nil {
+		return restErr
+	}
+
+	fields := map[string]string{}
+	if !node.Spec.WS {
+		fields["ethereumNodeName"] = fmt.Sprintf("ethereum node %s web socket server is not enabled", node.Name)
+	} else if !node.Spec.RPC {
+		fields["ethereumNodeName"] = fmt.Sprintf("ethereum node %s rpc server is not enabled", node.Name)
+	} else if chainID, err := service.chainID(node); err != nil {
+		go logger.Warn(service.resolveEthereumNode, err)
+		fields["ethereumNodeName"] = fmt.Sprintf("can't get ethereum node %s chain id", node.Name)
+	} else if chainID != uint64(dto.EthereumChainId) {
+		fields["ethereumChainId"] = fmt.Sprintf("ethereum node %s serves chain id %d", node.Name, chainID)
+	}
+	if len(fields) > 0 {
+		return restErrors.NewValidationError(fields)
+	}
+
+	dto.EthereumWSEndpoint = fmt.Sprintf("ws://%s.%s:%d", node.Name, node.Namespace, node.Spec.WSPort)
+	dto.EthereumHTTPEndpoints = []string{fmt.Sprintf("http://%s.%s:%d", node.Name, node.Namespace, node.Spec.RPCPort)}
+	return nil
+}
+
+// chainID calls the running ethereum node pod eth_chainId
+func (service chainlinkService) chainID(node ethereumv1alpha1.Node) (uint64, error) {
+	pod := &corev1.Pod{}
+	if err := k8sClient.Get(context.Background(), types.NamespacedName{Namespace: node.Namespace, Name: fmt.Sprintf("%s-0", node.Name)}, pod); err != nil {
+		return 0, err
+	}
+	if pod.Status.PodIP == "" {
+		return 0, fmt.Errorf("ethereum node %s isn't running", node.Name)
+	}
+
+	var chainID string
+	ethereumRPC := jsonrpc.NewClientWithOpts(fmt.Sprintf("http://%s:%d", pod.Status.PodIP, node.Spec.RPCPort), &jsonrpc.RPCClientOpts{HTTPClient: rpcClient})
+	if err := ethereumRPC.CallFor(&chainID, "eth_chainId"); err != nil {
+		return 0, err
+	}
+	return strconv.ParseUint(strings.TrimPrefix(chainID, "0x"), 16, 64)
+}
+
+// databaseURL generates the managed database password and returns the database url the node connects with
+func databaseURL(name string, namespace string) (databaseURL string, password string, err error) {
+	password, err = security.GenerateSecureString(postgresPasswordLength)
+	if err != nil {
+		return
+	}
+	databaseURL = fmt.Sprintf("postgresql://postgres:%s@%s:%d/%s?sslmode=disable", password, databaseHost(name, namespace), postgresPort, postgresDatabase)
+	return
+}
+
+// databaseHost is the managed database service address
+func databaseHost(name string, namespace string) string {
+	return fmt.Sprintf("%s%s.%s", name, postgresSuffix, namespace)
+}
+
+// redactedDatabaseURL hides the managed database password from the node database url, the password is kept in the managed database password secret
+// the database urls given by users are returned as is
+func redactedDatabaseURL(node chainlinkv1alpha1.Node) (databaseURL string, managed bool) {
+	parsed, err := url.Parse(node.Spec.DatabaseURL)
+	if err != nil || parsed.Hostname() != databaseHost(node.Name, node.Namespace) {
+		return node.Spec.DatabaseURL, false
+	}
+	return parsed.Redacted(), true
+}
+
+// createDatabase creates the managed database password secret, service and statefulset owned by the node
+// they are garbage collected with the node, the database volume is deleted with the statefulset
+func (service chainlinkService) createDatabase(node chainlinkv1alpha1.Node, password string, storageClass *string) restErrors.IRestErr {
+	name := node.Name + postgresSuffix
+	labels := map[string]string{
+		"app.kubernetes.io/name":       "postgres",
+		"app.kubernetes.io/instance":   name,
+		"app.kubernetes.io/managed-by": "kotal-api",
+	}
+	meta := metav1.ObjectMeta{
+		Name:      name,
+		Namespace: node.Namespace,
+		Labels:    labels,
+		OwnerReferences: []metav1.OwnerReference{
+			*metav1.NewControllerRef(&node, chainlinkv1alpha1.GroupVersion.WithKind("Node")),
+		},
+	}
+
+	secret := &corev1.Secret{
+		ObjectMeta: meta,
+		StringData: map[string]string{"password": password},
+	}
+
+	svc := &corev1.Service{
+		ObjectMeta: meta,
+		Spec: corev1.ServiceSpec{
+			Selector: labels,
+			Ports:    []corev1.ServicePort{{Name: "postgres", Port: postgresPort, TargetPort: intstr.FromInt(postgresPort)}},
+		},
+	}
+
+	replicas := int32(1)
+	sts := &appsv1.StatefulSet{
+		ObjectMeta: meta,
+		Spec: appsv1.StatefulSetSpec{
+			Replicas:    &replicas,
+			ServiceName: name,
+			Selector:    &metav1.LabelSelector{MatchLabels: labels},
+			PersistentVolumeClaimRetentionPolicy: &appsv1.StatefulSetPersistentVolumeClaimRetentionPolicy{
+				WhenDeleted: appsv1.DeletePersistentVolumeClaimRetentionPolicyType,
+				WhenScaled:  appsv1.RetainPersistentVolumeClaimRetentionPolicyType,
+			},
+			Template: corev1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{Labels: labels},
+				Spec: corev1.PodSpec{
+					Containers: []corev1.Container{{
+						Name:  "postgres",
+						Image: postgresImage,
+						Env: []corev1.EnvVar{
+							{Name: "POSTGRES_DB", Value: postgresDatabase},
+							{Name: "PGDATA", Value: "/var/lib/postgresql/data/pgdata"},
+							{Name: "POSTGRES_PASSWORD", ValueFrom: &corev1.EnvVarSource{SecretKeyRef: &corev1.SecretKeySelector{
+								LocalObjectReference: corev1.LocalObjectReference{Name: name},
+								Key:                  "password",
+							}}},
+						},
+						Ports:        []corev1.ContainerPort{{Name: "postgres", ContainerPort: postgresPort}},
+						VolumeMounts: []corev1.VolumeMount{{Name: "data", MountPath: "/var/lib/postgresql/data"}},
+					}},
+				},
+			},
+			VolumeClaimTemplates: []corev1.PersistentVolumeClaim{{
+				ObjectMeta: metav1.ObjectMeta{Name: "data"},
+				Spec: corev1.PersistentVolumeClaimSpec{
+					AccessModes:      []corev1.PersistentVolumeAccessMode{corev1.ReadWriteOnce},
+					StorageClassName: storageClass,
+					Resources: corev1.VolumeResourceRequirements{
+						Requests: corev1.ResourceList{corev1.ResourceStorage: k8sresource.MustParse(postgresStorage)},
+					},
+				},
+			}},
+		},
+	}
+
+	for _, obj := range []client.Object{secret, svc, sts} {
+		if err := k8sClient.Create(context.Background(), obj); err != nil {
+			go logger.Error(service.createDatabase, err)
+			return restErrors.NewInternalServerError(fmt.Sprintf("can't create node by name %s database", node.Name))
+		}
+	}
+	return nil
+}
@@ -26,6 +26,7 @@ type IService interface {
 	List(namespace string) (ethereumv1alpha1.NodeList, restErrors.IRestErr)
 	Delete(*ethereumv1alpha1.Node) restErrors.IRestErr
 	Count(namespace string) (int, restErrors.IRestErr)
+	Stats(ethereumv1alpha1.Node) (StatsDto, restErrors.IRestErr)
 }
 
 var (
@@ -60,8 +61,14 @@ func (service ethereumService) Create(dto EthereumDto) (node ethereumv1alpha1.No
 		RPC:     true,
 		Image:   dto.Image,
 	}
+	if dto.Engine != nil && *dto.Engine {
+		node.Spec.Engine = true
+		node.Spec.EnginePort = dto.EnginePort
+		node.Spec.JWTSecretName = dto.JWTSecretName
+	}
 
 	k8s.DefaultResources(&node.Spec.Resources)
+	k8s.RequestedStorage(&node.Spec.Resources, dto.Resources)
 
 	if os.Getenv("MOCK") == "true" {
 		node.Default()
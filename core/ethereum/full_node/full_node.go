@@ -0,0 +1,175 @@
+package full_node
+
+import (
+	"fmt"
+	"github.com/kotalco/core-api/core/ethereum"
+	"github.com/kotalco/core-api/core/ethereum2/beacon_node"
+	"github.com/kotalco/core-api/k8s"
+	restErrors "github.com/kotalco/core-api/pkg/errors"
+	"github.com/kotalco/core-api/pkg/time"
+	ethereumv1alpha1 "github.com/kotalco/kotal/apis/ethereum/v1alpha1"
+	ethereum2v1alpha1 "github.com/kotalco/kotal/apis/ethereum2/v1alpha1"
+	sharedAPI "github.com/kotalco/kotal/apis/shared"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// FullNodeLabel pairs the execution and the beacon node of a full node, holds the full node name
+const FullNodeLabel = "kotal.io/ethereum-full-node"
+
+// the execution node, beacon node and jwt secret are named after the full node
+const (
+	executionSuffix = "-execution"
+	consensusSuffix = "-beacon"
+	jwtSecretSuffix = "-jwt"
+)
+
+// FullNode is an execution node paired with a beacon node, a half is nil if it was deleted on its own
+type FullNode struct {
+	Name      string
+	Execution *ethereumv1alpha1.Node
+	Consensus *ethereum2v1alpha1.BeaconNode
+}
+
+// createdAt returns the creation time of the first created node
+func (fullNode FullNode) createdAt() *metav1.Time {
+	if fullNode.Execution != nil {
+		return &fullNode.Execution.CreationTimestamp
+	}
+	return &fullNode.Consensus.CreationTimestamp
+}
+
+// namespace returns the full node workspace namespace
+func (fullNode FullNode) namespace() string {
+	if fullNode.Execution != nil {
+		return fullNode.Execution.Namespace
+	}
+	return fullNode.Consensus.Namespace
+}
+
+// owns checks the node by name is the full node execution or beacon node
+func (fullNode FullNode) owns(name string) bool {
+	return (fullNode.Execution != nil && fullNode.Execution.Name == name) || (fullNode.Consensus != nil && fullNode.Consensus.Name == name)
+}
+
+// FullNodeDto is an ethereum execution node and beacon node sharing an engine api jwt secret
+// the jwt secret is generated on creation unless an existing jwt secret name is given
+type FullNodeDto struct {
+	time.Time
+	k8s.MetaDataDto
+	Network       string       `json:"network"`
+	JWTSecretName string       `json:"jwtSecretName"`
+	Execution     ExecutionDto `json:"execution"`
+	Consensus     ConsensusDto `json:"consensus"`
+	Status        *StatusDto   `json:"status,omitempty"`
+}
+
+// ExecutionDto is the full node execution client, only the storage is taken from the resources on creation
+type ExecutionDto struct {
+	Name   string `json:"name"`
+	Client string `json:"client"`
+	Image  string `json:"image"`
+	sharedAPI.Resources
+}
+
+// ConsensusDto is the full node beacon node, only the storage is taken from the resources on creation
+type ConsensusDto struct {
+	Name                    string  `json:"name"`
+	Client                  string  `json:"client"`
+	Image                   string  `json:"image"`
+	CheckpointSyncURL       *string `json:"checkpointSyncUrl"`
+	ExecutionEngineEndpoint string  `json:"executionEngineEndpoint"`
+	sharedAPI.Resources
+}
+
+// StatusDto is the combined status of the execution and beacon nodes, synced once both of them are synced
+type StatusDto struct {
+	Execution      *ethereum.StatsDto    `json:"execution"`
+	ExecutionError string                `json:"executionError,omitempty"`
+	Consensus      *beacon_node.StatsDto `json:"consensus"`
+	ConsensusError string                `json:"consensusError,omitempty"`
+	Synced         bool                  `json:"synced"`
+}
+
+type FullNodeListDto []FullNodeDto
+
+// Validate validates the full node name and the execution and consensus clients
+func (dto FullNodeDto) Validate() restErrors.IRestErr {
+	for _, name := range []string{dto.Name, dto.Name + executionSuffix} {
+		meta := k8s.MetaDataDto{Name: name}
+		if err := meta.Validate(); err != nil {
+			return err
+		}
+	}
+
+	fields := map[string]string{}
+	if dto.Network == "" {
+		fields["network"] = "network is required"
+	}
+	switch ethereumv1alpha1.EthereumClient(dto.Execution.Client) {
+	case ethereumv1alpha1.GethClient, ethereumv1alpha1.BesuClient, ethereumv1alpha1.NethermindClient:
+	default:
+		fields["execution.client"] = "client must be one of geth, besu or nethermind"
+	}
+	switch ethereum2v1alpha1.Ethereum2Client(dto.Consensus.Client) {
+	case ethereum2v1alpha1.TekuClient, ethereum2v1alpha1.PrysmClient, ethereum2v1alpha1.LighthouseClient, ethereum2v1alpha1.NimbusClient:
+	default:
+		fields["consensus.client"] = "client must be one of teku, prysm, lighthouse or nimbus"
+	}
+
+	if len(fields) > 0 {
+		return restErrors.NewValidationError(fields)
+	}
+	return nil
+}
+
+// FromFullNode returns the dto of the full node, the execution node spec is used for the common fields
+func (dto FullNodeDto) FromFullNode(fullNode FullNode) FullNodeDto {
+	dto.Name = fullNode.Name
+
+	if execution := fullNode.Execution; execution != nil {
+		dto.Time = time.Time{CreatedAt: execution.CreationTimestamp.UTC().Format(time.JavascriptISOString)}
+		dto.Network = execution.Spec.Network
+		dto.JWTSecretName = execution.Spec.JWTSecretName
+		dto.Execution = ExecutionDto{
+			Name:      execution.Name,
+			Client:    string(execution.Spec.Client),
+			Image:     execution.Spec.Image,
+			Resources: execution.Spec.Resources,
+		}
+	}
+
+	if consensus := fullNode.Consensus; consensus != nil {
+		if fullNode.Execution == nil {
+			dto.Time = time.Time{CreatedAt: consensus.CreationTimestamp.UTC().Format(time.JavascriptISOString)}
+			dto.Network = consensus.Spec.Network
+			dto.JWTSecretName = consensus.Spec.JWTSecretName
+		}
+		dto.Consensus = ConsensusDto{
+			Name:                    consensus.Name,
+			Client:                  string(consensus.Spec.Client),
+			Image:                   consensus.Spec.Image,
+			CheckpointSyncURL:       &consensus.Spec.CheckpointSyncURL,
+			ExecutionEngineEndpoint: consensus.Spec.ExecutionEngineEndpoint,
+			Resources:               consensus.Spec.Resources,
+		}
+	}
+
+	return dto
+}
+
+func (nodes FullNodeListDto) FromFullNode(fullNodes []FullNode) FullNodeListDto {
+	result := make(FullNodeListDto, len(fullNodes))
+	for index, v := range fullNodes {
+		result[index] = FullNodeDto{}.FromFullNode(v)
+	}
+	return result
+}
+
+// engineEndpoint is the execution node engine api endpoint the beacon node connects to
+func engineEndpoint(node ethereumv1alpha1.Node) string {
+	port := node.Spec.EnginePort
+	if port == 0 {
+		port = ethereumv1alpha1.DefaultEngineRPCPort
+	}
+	return fmt.Sprintf("http://%s.%s:%d", node.Name, node.Namespace, port)
+}
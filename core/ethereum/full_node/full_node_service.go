@@ -0,0 +1,238 @@
+// Package full_node internal is the domain layer for the ethereum full nodes
+// uses the ethereum, beacon node and secret services to create, list and delete the paired nodes together
+package full_node
+
+import (
+	"fmt"
+	"github.com/kotalco/core-api/core/ethereum"
+	"github.com/kotalco/core-api/core/ethereum2/beacon_node"
+	"github.com/kotalco/core-api/core/secret"
+	"github.com/kotalco/core-api/k8s"
+	restErrors "github.com/kotalco/core-api/pkg/errors"
+	"github.com/kotalco/core-api/pkg/logger"
+	"k8s.io/apimachinery/pkg/types"
+	"sort"
+)
+
+type fullNodeService struct{}
+
+type IService interface {
+	// Get returns the full node by name, not found if neither of its nodes exist
+	Get(types.NamespacedName) (FullNode, restErrors.IRestErr)
+	// Create creates the jwt secret unless one is given, the execution node and the beacon node, the created ones are deleted if any of them can't be created
+	Create(FullNodeDto) (FullNode, restErrors.IRestErr)
+	// List returns the workspace full nodes, newest first
+	List(namespace string) ([]FullNode, restErrors.IRestErr)
+	// Delete deletes the beacon node, the execution node and the generated jwt secret unless other nodes reference it
+	Delete(FullNode) restErrors.IRestErr
+	// Status returns the execution and beacon nodes syncing status
+	Status(FullNode) StatusDto
+}
+
+var (
+	ethereumService   = ethereum.NewEthereumService()
+	beaconNodeService = beacon_node.NewBeaconNodeService()
+	secretService     = secret.NewSecretService()
+)
+
+func NewService() IService {
+	return fullNodeService{}
+}
+
+// Get returns the full node by name
+func (service fullNodeService) Get(key types.NamespacedName) (fullNode FullNode, restErr restErrors.IRestErr) {
+	fullNodes, restErr := service.List(key.Namespace)
+	if restErr != nil {
+		return
+	}
+	for _, v := range fullNodes {
+		if v.Name == key.Name {
+			return v, nil
+		}
+	}
+	restErr = restErrors.NewNotFoundError(fmt.Sprintf("full node by name %s doesn't exist", key.Name))
+	return
+}
+
+// Create creates the full node jwt secret, execution node with the engine api enabled and the beacon node connected to it
+// the given jwt secret is used instead if any, it's kept if the full node can't be created
+func (service fullNodeService) Create(dto FullNodeDto) (fullNode FullNode, restErr restErrors.IRestErr) {
+	labels := map[string]string{FullNodeLabel: dto.Name}
+	jwtSecretName := dto.JWTSecretName
+	// generatedSecret is the secret deleted on rollback, empty if the given jwt secret is used
+	generatedSecret := types.NamespacedName{}
+
+	if jwtSecretName != "" {
+		model, restErr := secretService.Get(types.NamespacedName{Namespace: dto.Namespace, Name: jwtSecretName})
+		if restErr != nil {
+			return fullNode, restErr
+		}
+		if model.Labels[secret.KeyTypeLabel] != secret.JWTSecretType {
+			return fullNode, restErrors.NewValidationError(map[string]string{"jwtSecretName": fmt.Sprintf("secret %s isn't a jwt secret", jwtSecretName)})
+		}
+	} else {
+		jwtSecretName = dto.Name + jwtSecretSuffix
+		_, restErr = secretService.Generate(secret.GenerateSecretDto{
+			MetaDataDto: k8s.MetaDataDto{Name: jwtSecretName, Namespace: dto.Namespace},
+			Type:        secret.JWTSecretGenerator,
+		})
+		if restErr != nil {
+			return
+		}
+		generatedSecret = types.NamespacedName{Namespace: dto.Namespace, Name: jwtSecretName}
+	}
+
+	engine := true
+	execution, restErr := ethereumService.Create(ethereum.EthereumDto{
+		MetaDataDto:   k8s.MetaDataDto{Name: dto.Name + executionSuffix, Namespace: dto.Namespace, Labels: labels},
+		Network:       dto.Network,
+		Client:        dto.Execution.Client,
+		Image:         dto.Execution.Image,
+		Engine:        &engine,
+		JWTSecretName: jwtSecretName,
+		Resources:     dto.Execution.Resources,
+	})
+	if restErr != nil {
+		service.rollback(FullNode{}, generatedSecret)
+		return
+	}
+
+	consensus, restErr := beaconNodeService.Create(beacon_node.BeaconNodeDto{
+		MetaDataDto:             k8s.MetaDataDto{Name: dto.Name + consensusSuffix, Namespace: dto.Namespace, Labels: labels},
+		Network:                 dto.Network,
+		Client:                  dto.Consensus.Client,
+		Image:                   dto.Consensus.Image,
+		CheckpointSyncURL:       dto.Consensus.CheckpointSyncURL,
+		ExecutionEngineEndpoint: engineEndpoint(execution),
+		JWTSecretName:           jwtSecretName,
+		Resources:               dto.Consensus.Resources,
+	})
+	if restErr != nil {
+		service.rollback(FullNode{Execution: &execution}, generatedSecret)
+		return
+	}
+
+	return FullNode{Name: dto.Name, Execution: &execution, Consensus: &consensus}, nil
+}
+
+// rollback deletes the nodes and the jwt secret created before the full node creation failed, the jwt secret is empty if it wasn't generated
+func (service fullNodeService) rollback(fullNode FullNode, jwtSecret types.NamespacedName) {
+	if fullNode.Execution != nil {
+		if restErr := ethereumService.Delete(fullNode.Execution); restErr != nil {
+			go logger.Warn(service.rollback, restErr)
+		}
+	}
+	if jwtSecret.Name == "" {
+		return
+	}
+	model, restErr := secretService.Get(jwtSecret)
+	if restErr == nil {
+		restErr = secretService.Delete(&model)
+	}
+	if restErr != nil {
+		go logger.Warn(service.rollback, restErr)
+	}
+}
+
+// List pairs the execution and beacon nodes by the full node label
+func (service fullNodeService) List(namespace string) ([]FullNode, restErrors.IRestErr) {
+	executions, restErr := ethereumService.List(namespace)
+	if restErr != nil {
+		return nil, restErr
+	}
+	consensuses, restErr := beaconNodeService.List(namespace)
+	if restErr != nil {
+		return nil, restErr
+	}
+
+	fullNodes := map[string]*FullNode{}
+	pair := func(name string) *FullNode {
+		if fullNodes[name] == nil {
+			fullNodes[name] = &FullNode{Name: name}
+		}
+		return fullNodes[name]
+	}
+	for i := range executions.Items {
+		if name := executions.Items[i].Labels[FullNodeLabel]; name != "" {
+			pair(name).Execution = &executions.Items[i]
+		}
+	}
+	for i := range consensuses.Items {
+		if name := consensuses.Items[i].Labels[FullNodeLabel]; name != "" {
+			pair(name).Consensus = &consensuses.Items[i]
+		}
+	}
+
+	result := make([]FullNode, 0, len(fullNodes))
+	for _, v := range fullNodes {
+		result = append(result, *v)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[j].createdAt().Before(result[i].createdAt())
+	})
+	return result, nil
+}
+
+// Delete deletes the full node beacon node first so it doesn't lose its execution node while running
+// the generated jwt secret is deleted last unless other nodes reference it or the nodes referencing it can't be listed
+func (service fullNodeService) Delete(fullNode FullNode) restErrors.IRestErr {
+	jwtSecretName := ""
+	if fullNode.Consensus != nil {
+		jwtSecretName = fullNode.Consensus.Spec.JWTSecretName
+		if restErr := beaconNodeService.Delete(fullNode.Consensus); restErr != nil {
+			return restErr
+		}
+	}
+	if fullNode.Execution != nil {
+		jwtSecretName = fullNode.Execution.Spec.JWTSecretName
+		if restErr := ethereumService.Delete(fullNode.Execution); restErr != nil {
+			return restErr
+		}
+	}
+	// the jwt secret given on creation isn't owned by the full node
+	if jwtSecretName != fullNode.Name+jwtSecretSuffix {
+		return nil
+	}
+
+	namespace := fullNode.namespace()
+	references, restErr := secretService.ReferencesOf(types.NamespacedName{Namespace: namespace, Name: jwtSecretName})
+	if restErr != nil {
+		// the secret is kept if a node kind can't be listed, it may still be referenced by another node
+		go logger.Warn(service.Delete, restErr)
+		return nil
+	}
+	// the deleted nodes may still be listed until they are finalized
+	for _, reference := range references {
+		if !fullNode.owns(reference.Name) {
+			return nil
+		}
+	}
+
+	model, restErr := secretService.Get(types.NamespacedName{Namespace: namespace, Name: jwtSecretName})
+	if restErr != nil {
+		return restErr
+	}
+	return secretService.Delete(&model)
+}
+
+// Status returns the stats of both nodes, a node stats error is reported without failing the other node stats
+func (service fullNodeService) Status(fullNode FullNode) (status StatusDto) {
+	if fullNode.Execution == nil {
+		status.ExecutionError = fmt.Sprintf("execution node of full node %s doesn't exist", fullNode.Name)
+	} else if stats, restErr := ethereumService.Stats(*fullNode.Execution); restErr != nil {
+		status.ExecutionError = restErr.Error()
+	} else {
+		status.Execution = &stats
+	}
+
+	if fullNode.Consensus == nil {
+		status.ConsensusError = fmt.Sprintf("beacon node of full node %s doesn't exist", fullNode.Name)
+	} else if stats, restErr := beaconNodeService.Stats(*fullNode.Consensus); restErr != nil {
+		status.ConsensusError = restErr.Error()
+	} else {
+		status.Consensus = &stats
+	}
+
+	status.Synced = status.Execution != nil && !status.Execution.Syncing && status.Consensus != nil && !status.Consensus.Syncing
+	return
+}
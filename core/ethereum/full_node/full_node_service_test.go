@@ -0,0 +1,464 @@
+package full_node
+
+import (
+	"github.com/kotalco/core-api/core/ethereum"
+	"github.com/kotalco/core-api/core/ethereum2/beacon_node"
+	"github.com/kotalco/core-api/core/secret"
+	"github.com/kotalco/core-api/k8s"
+	restErrors "github.com/kotalco/core-api/pkg/errors"
+	"github.com/kotalco/core-api/pkg/roles"
+	ethereumv1alpha1 "github.com/kotalco/kotal/apis/ethereum/v1alpha1"
+	ethereum2v1alpha1 "github.com/kotalco/kotal/apis/ethereum2/v1alpha1"
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"net/http"
+	"os"
+	"testing"
+)
+
+/*
+ethereum service mocks
+*/
+var (
+	ethereumCreateFunc func(dto ethereum.EthereumDto) (ethereumv1alpha1.Node, restErrors.IRestErr)
+	ethereumListFunc   func(namespace string) (ethereumv1alpha1.NodeList, restErrors.IRestErr)
+	ethereumDeleteFunc func(node *ethereumv1alpha1.Node) restErrors.IRestErr
+	ethereumStatsFunc  func(node ethereumv1alpha1.Node) (ethereum.StatsDto, restErrors.IRestErr)
+)
+
+type ethereumServiceMock struct{}
+
+func (ethereumServiceMock) Get(types.NamespacedName) (ethereumv1alpha1.Node, restErrors.IRestErr) {
+	return ethereumv1alpha1.Node{}, nil
+}
+func (ethereumServiceMock) Create(dto ethereum.EthereumDto) (ethereumv1alpha1.Node, restErrors.IRestErr) {
+	return ethereumCreateFunc(dto)
+}
+func (ethereumServiceMock) Update(ethereum.EthereumDto, *ethereumv1alpha1.Node) restErrors.IRestErr {
+	return nil
+}
+func (ethereumServiceMock) List(namespace string) (ethereumv1alpha1.NodeList, restErrors.IRestErr) {
+	return ethereumListFunc(namespace)
+}
+func (ethereumServiceMock) Delete(node *ethereumv1alpha1.Node) restErrors.IRestErr {
+	return ethereumDeleteFunc(node)
+}
+func (ethereumServiceMock) Count(namespace string) (int, restErrors.IRestErr) {
+	return 0, nil
+}
+func (ethereumServiceMock) Stats(node ethereumv1alpha1.Node) (ethereum.StatsDto, restErrors.IRestErr) {
+	return ethereumStatsFunc(node)
+}
+
+/*
+beacon node service mocks
+*/
+var (
+	beaconNodeCreateFunc func(dto beacon_node.BeaconNodeDto) (ethereum2v1alpha1.BeaconNode, restErrors.IRestErr)
+	beaconNodeListFunc   func(namespace string) (ethereum2v1alpha1.BeaconNodeList, restErrors.IRestErr)
+	beaconNodeDeleteFunc func(node *ethereum2v1alpha1.BeaconNode) restErrors.IRestErr
+	beaconNodeStatsFunc  func(node ethereum2v1alpha1.BeaconNode) (beacon_node.StatsDto, restErrors.IRestErr)
+)
+
+type beaconNodeServiceMock struct{}
+
+func (beaconNodeServiceMock) Get(types.NamespacedName) (ethereum2v1alpha1.BeaconNode, restErrors.IRestErr) {
+	return ethereum2v1alpha1.BeaconNode{}, nil
+}
+func (beaconNodeServiceMock) Create(dto beacon_node.BeaconNodeDto) (ethereum2v1alpha1.BeaconNode, restErrors.IRestErr) {
+	return beaconNodeCreateFunc(dto)
+}
+func (beaconNodeServiceMock) Update(beacon_node.BeaconNodeDto, *ethereum2v1alpha1.BeaconNode) restErrors.IRestErr {
+	return nil
+}
+func (beaconNodeServiceMock) List(namespace string) (ethereum2v1alpha1.BeaconNodeList, restErrors.IRestErr) {
+	return beaconNodeListFunc(namespace)
+}
+func (beaconNodeServiceMock) Delete(node *ethereum2v1alpha1.BeaconNode) restErrors.IRestErr {
+	return beaconNodeDeleteFunc(node)
+}
+func (beaconNodeServiceMock) Count(namespace string) (int, restErrors.IRestErr) {
+	return 0, nil
+}
+func (beaconNodeServiceMock) Stats(node ethereum2v1alpha1.BeaconNode) (beacon_node.StatsDto, restErrors.IRestErr) {
+	return beaconNodeStatsFunc(node)
+}
+
+/*
+secret service mocks
+*/
+var (
+	secretGetFunc          func(key types.NamespacedName) (corev1.Secret, restErrors.IRestErr)
+	secretGenerateFunc     func(dto secret.GenerateSecretDto) (secret.GeneratedSecretDto, restErrors.IRestErr)
+	secretDeleteFunc       func(model *corev1.Secret) restErrors.IRestErr
+	secretReferencesOfFunc func(key types.NamespacedName) ([]secret.ReferenceDto, restErrors.IRestErr)
+)
+
+type secretServiceMock struct{}
+
+func (secretServiceMock) Get(key types.NamespacedName) (corev1.Secret, restErrors.IRestErr) {
+	return secretGetFunc(key)
+}
+func (secretServiceMock) Create(secret.SecretDto) (corev1.Secret, restErrors.IRestErr) {
+	return corev1.Secret{}, nil
+}
+func (secretServiceMock) Generate(dto secret.GenerateSecretDto) (secret.GeneratedSecretDto, restErrors.IRestErr) {
+	return secretGenerateFunc(dto)
+}
+func (secretServiceMock) List(namespace string) (corev1.SecretList, restErrors.IRestErr) {
+	return corev1.SecretList{}, nil
+}
+func (secretServiceMock) Delete(model *corev1.Secret) restErrors.IRestErr {
+	return secretDeleteFunc(model)
+}
+func (secretServiceMock) Count(namespace string) (int, restErrors.IRestErr) {
+	return 0, nil
+}
+func (secretServiceMock) Rotate(corev1.Secret, map[string]string) (corev1.Secret, []secret.ReferenceDto, restErrors.IRestErr) {
+	return corev1.Secret{}, nil, nil
+}
+func (secretServiceMock) References(namespace string) (secret.ReferenceIndex, restErrors.IRestErr) {
+	return secret.ReferenceIndex{}, nil
+}
+func (secretServiceMock) ReferencesOf(key types.NamespacedName) ([]secret.ReferenceDto, restErrors.IRestErr) {
+	return secretReferencesOfFunc(key)
+}
+func (secretServiceMock) Materialize(namespace string, provider string) restErrors.IRestErr {
+	return nil
+}
+func (secretServiceMock) Sync() restErrors.IRestErr {
+	return nil
+}
+
+var service IService
+
+func TestMain(m *testing.M) {
+	ethereumService = &ethereumServiceMock{}
+	beaconNodeService = &beaconNodeServiceMock{}
+	secretService = &secretServiceMock{}
+	secretGetFunc = func(key types.NamespacedName) (corev1.Secret, restErrors.IRestErr) {
+		return corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: key.Name, Namespace: key.Namespace}}, nil
+	}
+	service = NewService()
+
+	code := m.Run()
+	os.Exit(code)
+}
+
+func validDto() FullNodeDto {
+	return FullNodeDto{
+		MetaDataDto: k8s.MetaDataDto{Name: "mainnet", Namespace: "default"},
+		Network:     "mainnet",
+		Execution:   ExecutionDto{Client: "geth"},
+		Consensus:   ConsensusDto{Client: "prysm"},
+	}
+}
+
+func executionNode(name string, fullNode string) ethereumv1alpha1.Node {
+	node := ethereumv1alpha1.Node{ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default", Labels: map[string]string{}}}
+	node.Spec.JWTSecretName = fullNode + jwtSecretSuffix
+	if fullNode != "" {
+		node.Labels[FullNodeLabel] = fullNode
+	}
+	return node
+}
+
+func beaconNode(name string, fullNode string) ethereum2v1alpha1.BeaconNode {
+	node := ethereum2v1alpha1.BeaconNode{ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default", Labels: map[string]string{}}}
+	node.Spec.JWTSecretName = fullNode + jwtSecretSuffix
+	if fullNode != "" {
+		node.Labels[FullNodeLabel] = fullNode
+	}
+	return node
+}
+
+func TestFullNodeDto_Validate(t *testing.T) {
+	t.Run("validate_should_pass", func(t *testing.T) {
+		assert.Nil(t, validDto().Validate())
+	})
+
+	t.Run("validate_should_throw_if_clients_are_invalid", func(t *testing.T) {
+		dto := validDto()
+		dto.Execution.Client = "parity"
+		dto.Consensus.Client = ""
+		err := dto.Validate()
+		assert.EqualValues(t, http.StatusBadRequest, err.StatusCode())
+		assert.Contains(t, err.(restErrors.RestErr).Validations, "execution.client")
+		assert.Contains(t, err.(restErrors.RestErr).Validations, "consensus.client")
+	})
+
+	t.Run("validate_should_throw_if_the_execution_node_name_is_too_long", func(t *testing.T) {
+		dto := validDto()
+		dto.Name = "a-very-long-full-node-name-that-fits-but-its-execution-node-not"
+		err := dto.Validate()
+		assert.Contains(t, err.(restErrors.RestErr).Validations, "name")
+	})
+}
+
+func TestService_Create(t *testing.T) {
+	t.Run("create_should_pair_the_nodes_with_a_shared_jwt_secret", func(t *testing.T) {
+		secretGenerateFunc = func(dto secret.GenerateSecretDto) (secret.GeneratedSecretDto, restErrors.IRestErr) {
+			assert.EqualValues(t, "mainnet-jwt", dto.Name)
+			assert.EqualValues(t, secret.JWTSecretGenerator, dto.Type)
+			return secret.GeneratedSecretDto{}, nil
+		}
+		ethereumCreateFunc = func(dto ethereum.EthereumDto) (ethereumv1alpha1.Node, restErrors.IRestErr) {
+			assert.True(t, *dto.Engine)
+			assert.EqualValues(t, "mainnet-jwt", dto.JWTSecretName)
+			assert.EqualValues(t, "mainnet", dto.Labels[FullNodeLabel])
+			node := executionNode(dto.Name, "mainnet")
+			node.Spec.EnginePort = 8551
+			return node, nil
+		}
+		beaconNodeCreateFunc = func(dto beacon_node.BeaconNodeDto) (ethereum2v1alpha1.BeaconNode, restErrors.IRestErr) {
+			assert.EqualValues(t, "http://mainnet-execution.default:8551", dto.ExecutionEngineEndpoint)
+			assert.EqualValues(t, "mainnet-jwt", dto.JWTSecretName)
+			assert.EqualValues(t, "mainnet", dto.Labels[FullNodeLabel])
+			return beaconNode(dto.Name, "mainnet"), nil
+		}
+
+		fullNode, err := service.Create(validDto())
+		assert.Nil(t, err)
+		assert.EqualValues(t, "mainnet-execution", fullNode.Execution.Name)
+		assert.EqualValues(t, "mainnet-beacon", fullNode.Consensus.Name)
+	})
+
+	t.Run("create_should_roll_back_if_the_beacon_node_can't_be_created", func(t *testing.T) {
+		secretGenerateFunc = func(dto secret.GenerateSecretDto) (secret.GeneratedSecretDto, restErrors.IRestErr) {
+			return secret.GeneratedSecretDto{}, nil
+		}
+		ethereumCreateFunc = func(dto ethereum.EthereumDto) (ethereumv1alpha1.Node, restErrors.IRestErr) {
+			return executionNode(dto.Name, "mainnet"), nil
+		}
+		beaconNodeCreateFunc = func(dto beacon_node.BeaconNodeDto) (ethereum2v1alpha1.BeaconNode, restErrors.IRestErr) {
+			return ethereum2v1alpha1.BeaconNode{}, restErrors.NewBadRequestError("beacon node by name mainnet-beacon already exist")
+		}
+		var deleted []string
+		ethereumDeleteFunc = func(node *ethereumv1alpha1.Node) restErrors.IRestErr {
+			deleted = append(deleted, node.Name)
+			return nil
+		}
+		secretDeleteFunc = func(model *corev1.Secret) restErrors.IRestErr {
+			deleted = append(deleted, model.Name)
+			return nil
+		}
+
+		_, err := service.Create(validDto())
+		assert.EqualValues(t, "beacon node by name mainnet-beacon already exist", err.Error())
+		assert.EqualValues(t, []string{"mainnet-execution", "mainnet-jwt"}, deleted)
+	})
+
+	t.Run("create_should_roll_back_if_the_execution_node_can't_be_created", func(t *testing.T) {
+		secretGenerateFunc = func(dto secret.GenerateSecretDto) (secret.GeneratedSecretDto, restErrors.IRestErr) {
+			return secret.GeneratedSecretDto{}, nil
+		}
+		ethereumCreateFunc = func(dto ethereum.EthereumDto) (ethereumv1alpha1.Node, restErrors.IRestErr) {
+			return ethereumv1alpha1.Node{}, restErrors.NewInternalServerError("failed to create node")
+		}
+		var deleted []string
+		secretDeleteFunc = func(model *corev1.Secret) restErrors.IRestErr {
+			deleted = append(deleted, model.Name)
+			return nil
+		}
+
+		_, err := service.Create(validDto())
+		assert.EqualValues(t, http.StatusInternalServerError, err.StatusCode())
+		assert.EqualValues(t, []string{"mainnet-jwt"}, deleted)
+	})
+	t.Run("create_should_use_the_given_jwt_secret_and_keep_it_on_roll_back", func(t *testing.T) {
+		secretGetFunc = func(key types.NamespacedName) (corev1.Secret, restErrors.IRestErr) {
+			return corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: key.Name, Namespace: key.Namespace, Labels: map[string]string{secret.KeyTypeLabel: secret.JWTSecretType}}}, nil
+		}
+		defer func() {
+			secretGetFunc = func(key types.NamespacedName) (corev1.Secret, restErrors.IRestErr) {
+				return corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: key.Name, Namespace: key.Namespace}}, nil
+			}
+		}()
+		secretGenerateFunc = func(dto secret.GenerateSecretDto) (secret.GeneratedSecretDto, restErrors.IRestErr) {
+			t.Fatal("the jwt secret shouldn't be generated")
+			return secret.GeneratedSecretDto{}, nil
+		}
+		ethereumCreateFunc = func(dto ethereum.EthereumDto) (ethereumv1alpha1.Node, restErrors.IRestErr) {
+			assert.EqualValues(t, "shared-jwt", dto.JWTSecretName)
+			return executionNode(dto.Name, "mainnet"), nil
+		}
+		beaconNodeCreateFunc = func(dto beacon_node.BeaconNodeDto) (ethereum2v1alpha1.BeaconNode, restErrors.IRestErr) {
+			assert.EqualValues(t, "shared-jwt", dto.JWTSecretName)
+			return ethereum2v1alpha1.BeaconNode{}, restErrors.NewBadRequestError("beacon node by name mainnet-beacon already exist")
+		}
+		var deleted []string
+		ethereumDeleteFunc = func(node *ethereumv1alpha1.Node) restErrors.IRestErr {
+			deleted = append(deleted, node.Name)
+			return nil
+		}
+		secretDeleteFunc = func(model *corev1.Secret) restErrors.IRestErr {
+			deleted = append(deleted, model.Name)
+			return nil
+		}
+
+		dto := validDto()
+		dto.JWTSecretName = "shared-jwt"
+		_, err := service.Create(dto)
+		assert.EqualValues(t, http.StatusBadRequest, err.StatusCode())
+		assert.EqualValues(t, []string{"mainnet-execution"}, deleted)
+	})
+
+	t.Run("create_should_throw_if_the_given_secret_isn't_a_jwt_secret", func(t *testing.T) {
+		dto := validDto()
+		dto.JWTSecretName = "my-password"
+		_, err := service.Create(dto)
+		assert.EqualValues(t, http.StatusBadRequest, err.StatusCode())
+		assert.Contains(t, err.(restErrors.RestErr).Validations, "jwtSecretName")
+	})
+}
+
+func TestService_List(t *testing.T) {
+	t.Run("list_should_pair_the_nodes_by_label", func(t *testing.T) {
+		ethereumListFunc = func(namespace string) (ethereumv1alpha1.NodeList, restErrors.IRestErr) {
+			return ethereumv1alpha1.NodeList{Items: []ethereumv1alpha1.Node{executionNode("mainnet-execution", "mainnet"), executionNode("standalone", "")}}, nil
+		}
+		beaconNodeListFunc = func(namespace string) (ethereum2v1alpha1.BeaconNodeList, restErrors.IRestErr) {
+			return ethereum2v1alpha1.BeaconNodeList{Items: []ethereum2v1alpha1.BeaconNode{beaconNode("mainnet-beacon", "mainnet"), beaconNode("goerli-beacon", "goerli")}}, nil
+		}
+
+		fullNodes, err := service.List("default")
+		assert.Nil(t, err)
+		assert.Len(t, fullNodes, 2)
+
+		fullNode, err := service.Get(types.NamespacedName{Namespace: "default", Name: "mainnet"})
+		assert.Nil(t, err)
+		assert.EqualValues(t, "mainnet-execution", fullNode.Execution.Name)
+		assert.EqualValues(t, "mainnet-beacon", fullNode.Consensus.Name)
+
+		fullNode, err = service.Get(types.NamespacedName{Namespace: "default", Name: "goerli"})
+		assert.Nil(t, err)
+		assert.Nil(t, fullNode.Execution)
+
+		_, err = service.Get(types.NamespacedName{Namespace: "default", Name: "standalone"})
+		assert.EqualValues(t, http.StatusNotFound, err.StatusCode())
+	})
+}
+
+func TestService_Delete(t *testing.T) {
+	execution := executionNode("mainnet-execution", "mainnet")
+	consensus := beaconNode("mainnet-beacon", "mainnet")
+	fullNode := FullNode{Name: "mainnet", Execution: &execution, Consensus: &consensus}
+
+	t.Run("delete_should_delete_the_nodes_and_the_jwt_secret", func(t *testing.T) {
+		var deleted []string
+		beaconNodeDeleteFunc = func(node *ethereum2v1alpha1.BeaconNode) restErrors.IRestErr {
+			deleted = append(deleted, node.Name)
+			return nil
+		}
+		ethereumDeleteFunc = func(node *ethereumv1alpha1.Node) restErrors.IRestErr {
+			deleted = append(deleted, node.Name)
+			return nil
+		}
+		secretReferencesOfFunc = func(key types.NamespacedName) ([]secret.ReferenceDto, restErrors.IRestErr) {
+			return []secret.ReferenceDto{{Resource: roles.EthereumNodes, Name: "mainnet-execution", Field: "jwtSecretName"}}, nil
+		}
+		secretDeleteFunc = func(model *corev1.Secret) restErrors.IRestErr {
+			deleted = append(deleted, model.Name)
+			return nil
+		}
+
+		err := service.Delete(fullNode)
+		assert.Nil(t, err)
+		assert.EqualValues(t, []string{"mainnet-beacon", "mainnet-execution", "mainnet-jwt"}, deleted)
+	})
+
+	t.Run("delete_should_keep_the_jwt_secret_referenced_by_other_nodes", func(t *testing.T) {
+		var deleted []string
+		beaconNodeDeleteFunc = func(node *ethereum2v1alpha1.BeaconNode) restErrors.IRestErr {
+			deleted = append(deleted, node.Name)
+			return nil
+		}
+		ethereumDeleteFunc = func(node *ethereumv1alpha1.Node) restErrors.IRestErr {
+			deleted = append(deleted, node.Name)
+			return nil
+		}
+		secretReferencesOfFunc = func(key types.NamespacedName) ([]secret.ReferenceDto, restErrors.IRestErr) {
+			return []secret.ReferenceDto{{Resource: roles.Ethereum2BeaconNodes, Name: "other-beacon", Field: "jwtSecretName"}}, nil
+		}
+
+		err := service.Delete(fullNode)
+		assert.Nil(t, err)
+		assert.EqualValues(t, []string{"mainnet-beacon", "mainnet-execution"}, deleted)
+	})
+
+	t.Run("delete_should_keep_the_jwt_secret_if_the_references_can't_be_listed", func(t *testing.T) {
+		var deleted []string
+		beaconNodeDeleteFunc = func(node *ethereum2v1alpha1.BeaconNode) restErrors.IRestErr {
+			deleted = append(deleted, node.Name)
+			return nil
+		}
+		ethereumDeleteFunc = func(node *ethereumv1alpha1.Node) restErrors.IRestErr {
+			deleted = append(deleted, node.Name)
+			return nil
+		}
+		secretReferencesOfFunc = func(key types.NamespacedName) ([]secret.ReferenceDto, restErrors.IRestErr) {
+			return nil, restErrors.NewInternalServerError("can't list ethereum2.beaconnodes referencing secrets")
+		}
+		secretDeleteFunc = func(model *corev1.Secret) restErrors.IRestErr {
+			deleted = append(deleted, model.Name)
+			return nil
+		}
+
+		err := service.Delete(fullNode)
+		assert.Nil(t, err)
+		assert.EqualValues(t, []string{"mainnet-beacon", "mainnet-execution"}, deleted)
+	})
+	t.Run("delete_should_keep_the_jwt_secret_given_on_creation", func(t *testing.T) {
+		execution, consensus := *fullNode.Execution, *fullNode.Consensus
+		execution.Spec.JWTSecretName, consensus.Spec.JWTSecretName = "shared-jwt", "shared-jwt"
+		var deleted []string
+		beaconNodeDeleteFunc = func(node *ethereum2v1alpha1.BeaconNode) restErrors.IRestErr {
+			deleted = append(deleted, node.Name)
+			return nil
+		}
+		ethereumDeleteFunc = func(node *ethereumv1alpha1.Node) restErrors.IRestErr {
+			deleted = append(deleted, node.Name)
+			return nil
+		}
+		secretDeleteFunc = func(model *corev1.Secret) restErrors.IRestErr {
+			deleted = append(deleted, model.Name)
+			return nil
+		}
+
+		err := service.Delete(FullNode{Name: "mainnet", Execution: &execution, Consensus: &consensus})
+		assert.Nil(t, err)
+		assert.EqualValues(t, []string{"mainnet-beacon", "mainnet-execution"}, deleted)
+	})
+}
+
+func TestService_Status(t *testing.T) {
+	execution := executionNode("mainnet-execution", "mainnet")
+	consensus := beaconNode("mainnet-beacon", "mainnet")
+
+	t.Run("status_should_be_synced_if_both_nodes_are_synced", func(t *testing.T) {
+		ethereumStatsFunc = func(node ethereumv1alpha1.Node) (ethereum.StatsDto, restErrors.IRestErr) {
+			return ethereum.StatsDto{CurrentBlock: 100, HighestBlock: 100, PeersCount: 25}, nil
+		}
+		beaconNodeStatsFunc = func(node ethereum2v1alpha1.BeaconNode) (beacon_node.StatsDto, restErrors.IRestErr) {
+			return beacon_node.StatsDto{CurrentSlot: 10, TargetSlot: 10, PeersCount: 50}, nil
+		}
+
+		status := service.Status(FullNode{Name: "mainnet", Execution: &execution, Consensus: &consensus})
+		assert.True(t, status.Synced)
+		assert.EqualValues(t, 25, status.Execution.PeersCount)
+		assert.EqualValues(t, 50, status.Consensus.PeersCount)
+	})
+
+	t.Run("status_should_report_each_node_error", func(t *testing.T) {
+		ethereumStatsFunc = func(node ethereumv1alpha1.Node) (ethereum.StatsDto, restErrors.IRestErr) {
+			return ethereum.StatsDto{}, restErrors.NewBadRequestError("rpc is not enabled")
+		}
+
+		status := service.Status(FullNode{Name: "mainnet", Execution: &execution})
+		assert.False(t, status.Synced)
+		assert.EqualValues(t, "rpc is not enabled", status.ExecutionError)
+		assert.EqualValues(t, "beacon node of full node mainnet doesn't exist", status.ConsensusError)
+	})
+}
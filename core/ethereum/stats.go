@@ -0,0 +1,81 @@
+package ethereum
+
+import (
+	"fmt"
+	restErrors "github.com/kotalco/core-api/pkg/errors"
+	"github.com/kotalco/core-api/pkg/logger"
+	ethereumv1alpha1 "github.com/kotalco/kotal/apis/ethereum/v1alpha1"
+	"github.com/ybbus/jsonrpc/v2"
+	"math/big"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// statsClient is the http client of the node json-rpc calls, the stats are polled so a hanging node mustn't block the poller
+var statsClient = &http.Client{Timeout: 4 * time.Second}
+
+// StatsDto is the execution node peers count and syncing status
+type StatsDto struct {
+	CurrentBlock uint64 `json:"currentBlock"`
+	HighestBlock uint64 `json:"highestBlock"`
+	PeersCount   uint64 `json:"peersCount"`
+	Syncing      bool   `json:"syncing"`
+}
+
+// Stats calls the node json-rpc eth_syncing, eth_blockNumber and net_peerCount methods
+func (service ethereumService) Stats(node ethereumv1alpha1.Node) (stats StatsDto, restErr restErrors.IRestErr) {
+	if !node.Spec.RPC {
+		restErr = restErrors.NewBadRequestError("rpc is not enabled")
+		return
+	}
+
+	client := jsonrpc.NewClientWithOpts(fmt.Sprintf("http://%s.%s:%d", node.Name, node.Namespace, node.Spec.RPCPort), &jsonrpc.RPCClientOpts{HTTPClient: statsClient})
+
+	// eth_syncing returns false once the node is synced
+	syncing, err := client.Call("eth_syncing")
+	if err == nil && syncing.Error != nil {
+		err = syncing.Error
+	}
+	if err != nil {
+		go logger.Warn(service.Stats, err)
+		restErr = restErrors.NewInternalServerError(fmt.Sprintf("can't get node by name %s syncing status", node.Name))
+		return
+	}
+
+	var syncStatus struct {
+		CurrentBlock string `json:"currentBlock"`
+		HighestBlock string `json:"highestBlock"`
+	}
+	if syncing.GetObject(&syncStatus) == nil && syncStatus.CurrentBlock != "" {
+		stats.Syncing = true
+		stats.CurrentBlock = hexToUint(syncStatus.CurrentBlock)
+		stats.HighestBlock = hexToUint(syncStatus.HighestBlock)
+	} else {
+		var blockNumber string
+		if err := client.CallFor(&blockNumber, "eth_blockNumber"); err != nil {
+			go logger.Warn(service.Stats, err)
+			restErr = restErrors.NewInternalServerError(fmt.Sprintf("can't get node by name %s block number", node.Name))
+			return
+		}
+		stats.CurrentBlock = hexToUint(blockNumber)
+		stats.HighestBlock = stats.CurrentBlock
+	}
+
+	var peerCount string
+	if err := client.CallFor(&peerCount, "net_peerCount"); err != nil {
+		go logger.Warn(service.Stats, err)
+		restErr = restErrors.NewInternalServerError(fmt.Sprintf("can't get node by name %s peers count", node.Name))
+		return
+	}
+	stats.PeersCount = hexToUint(peerCount)
+
+	return
+}
+
+// hexToUint parses the json-rpc 0x prefixed quantity
+func hexToUint(quantity string) uint64 {
+	value := new(big.Int)
+	value.SetString(strings.TrimPrefix(quantity, "0x"), 16)
+	return value.Uint64()
+}
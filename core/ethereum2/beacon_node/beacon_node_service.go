@@ -26,6 +26,7 @@ type IService interface {
 	List(namespace string) (ethereum2v1alpha1.BeaconNodeList, restErrors.IRestErr)
 	Delete(*ethereum2v1alpha1.BeaconNode) restErrors.IRestErr
 	Count(namespace string) (int, restErrors.IRestErr)
+	Stats(ethereum2v1alpha1.BeaconNode) (StatsDto, restErrors.IRestErr)
 }
 
 var (
@@ -75,6 +76,7 @@ func (service beaconNodeService) Create(dto BeaconNodeDto) (node ethereum2v1alph
 	}
 
 	k8s.DefaultResources(&node.Spec.Resources)
+	k8s.RequestedStorage(&node.Spec.Resources, dto.Resources)
 
 	if os.Getenv("MOCK") == "true" {
 		node.Default()
@@ -0,0 +1,124 @@
+package beacon_node
+
+import (
+	"errors"
+	"fmt"
+	restErrors "github.com/kotalco/core-api/pkg/errors"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// genesisValidatorsRoots identify the networks the checkpoint sync providers serve, providers of other networks are only checked to serve finalized headers
+var genesisValidatorsRoots = map[string]string{
+	"mainnet": "0x4b363db94e286120d76eb905340fdd4e54bfe9f06bf33ff6cf5ad27f511bfe95",
+	"goerli":  "0x043db0d9a83813551ee2f33450d23797757d430911a9320530ad8a0eabc43efb",
+	"sepolia": "0xd8ea171f3c94aea21ebc42a1ed61052acf3f9209c00e4efbaaddac09ed9b8078",
+	"holesky": "0x9143aa7c615a7f7115e2b6aac319c03529df8242ae705fba9df39b79c59fa8b1",
+	"gnosis":  "0xf5dcb5564e829aab27264b9becd5dfaa017085611224cb3036f573368dbb9d47",
+}
+
+var errPrivateCheckpointSyncURL = errors.New("checkpoint sync url must be a public address")
+
+// checkpointClient is used to reach the checkpoint sync providers configured by the platform admin
+var checkpointClient = &http.Client{Timeout: 5 * time.Second}
+
+// publicCheckpointClient is used to reach the checkpoint sync urls sent by users
+// it refuses to dial private and cluster addresses, including the ones reached by redirects or dns rebinding
+var publicCheckpointClient = &http.Client{
+	Timeout: 5 * time.Second,
+	Transport: &http.Transport{
+		DialContext: (&net.Dialer{
+			Timeout: 5 * time.Second,
+			Control: func(network, address string, c syscall.RawConn) error {
+				host, _, err := net.SplitHostPort(address)
+				if err != nil {
+					return err
+				}
+				if ip := net.ParseIP(host); ip == nil || !isPublicAddress(ip) {
+					return errPrivateCheckpointSyncURL
+				}
+				return nil
+			},
+		}).DialContext,
+	},
+}
+
+// isPublicAddress reports whether the ip is reachable outside the cluster network, it's a variable to be replaced in tests
+var isPublicAddress = func(ip net.IP) bool {
+	return !(ip.IsLoopback() || ip.IsPrivate() || ip.IsUnspecified() || ip.IsLinkLocalUnicast() ||
+		ip.IsLinkLocalMulticast() || ip.IsInterfaceLocalMulticast() || ip.IsMulticast() || sharedAddressSpace.Contains(ip))
+}
+
+// sharedAddressSpace is the carrier-grade nat range some clusters use for pods and services
+var sharedAddressSpace = &net.IPNet{IP: net.IPv4(100, 64, 0, 0), Mask: net.CIDRMask(10, 32)}
+
+// ValidateCheckpointSyncURL validates the user sent checkpoint sync url is public and serves the finalized headers of the network
+// the beacon node crash loops if it starts syncing from a provider of another network
+func ValidateCheckpointSyncURL(checkpointSyncURL string, network string) restErrors.IRestErr {
+	if checkpointSyncURL == "" {
+		return nil
+	}
+	if err := verifyCheckpointSyncURL(checkpointSyncURL, network, true); err != nil {
+		return restErrors.NewValidationError(map[string]string{"checkpointSyncUrl": err.Error()})
+	}
+	return nil
+}
+
+// VerifyCheckpointSyncURL fetches the finalized beacon block header and the genesis from the checkpoint sync url
+// the genesis validators root is compared with the network one if the network is known
+func VerifyCheckpointSyncURL(checkpointSyncURL string, network string) error {
+	return verifyCheckpointSyncURL(checkpointSyncURL, network, false)
+}
+
+// verifyCheckpointSyncURL verifies the checkpoint sync url, public restricts it to addresses outside the cluster network
+func verifyCheckpointSyncURL(checkpointSyncURL string, network string, public bool) error {
+	parsed, err := url.Parse(checkpointSyncURL)
+	if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+		return errors.New("checkpoint sync url must be a valid http or https url")
+	}
+	client := checkpointClient
+	if public {
+		client = publicCheckpointClient
+		// resolve the host first to report private addresses instead of failing the fetch
+		ips, err := net.LookupIP(parsed.Hostname())
+		if err != nil {
+			return errors.New("checkpoint sync url host can't be resolved")
+		}
+		for _, ip := range ips {
+			if !isPublicAddress(ip) {
+				return errPrivateCheckpointSyncURL
+			}
+		}
+	}
+	baseURL := strings.TrimSuffix(checkpointSyncURL, "/")
+
+	var header struct {
+		Data struct {
+			Root string `json:"root"`
+		} `json:"data"`
+	}
+	if err := fetchJSON(client, fmt.Sprintf("%s/eth/v1/beacon/headers/finalized", baseURL), &header); err != nil || header.Data.Root == "" {
+		return errors.New("checkpoint sync url doesn't serve the finalized beacon block header")
+	}
+
+	root, ok := genesisValidatorsRoots[network]
+	if !ok {
+		return nil
+	}
+	var genesis struct {
+		Data struct {
+			GenesisValidatorsRoot string `json:"genesis_validators_root"`
+		} `json:"data"`
+	}
+	if err := fetchJSON(client, fmt.Sprintf("%s/eth/v1/beacon/genesis", baseURL), &genesis); err != nil {
+		return errors.New("checkpoint sync url doesn't serve the network genesis")
+	}
+	if !strings.EqualFold(genesis.Data.GenesisValidatorsRoot, root) {
+		return fmt.Errorf("checkpoint sync url doesn't serve %s network", network)
+	}
+	return nil
+}
@@ -0,0 +1,99 @@
+package beacon_node
+
+import (
+	restErrors "github.com/kotalco/core-api/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// checkpointServer serves the finalized header and the genesis of the network having the genesis validators root
+func checkpointServer(genesisValidatorsRoot string) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/eth/v1/beacon/headers/finalized":
+			w.Write([]byte(`{"data":{"root":"0xabc"}}`))
+		case "/eth/v1/beacon/genesis":
+			w.Write([]byte(`{"data":{"genesis_validators_root":"` + genesisValidatorsRoot + `"}}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+}
+
+// allowLoopbackAddress lets the checkpoint servers listening on the loopback address pass the public address guard
+func allowLoopbackAddress(t *testing.T) {
+	isPublic := isPublicAddress
+	isPublicAddress = func(ip net.IP) bool { return ip.IsLoopback() || isPublic(ip) }
+	t.Cleanup(func() { isPublicAddress = isPublic })
+}
+
+func TestValidateCheckpointSyncURL(t *testing.T) {
+	t.Run("validate_checkpoint_sync_url_should_throw_if_url_is_private", func(t *testing.T) {
+		server := checkpointServer(genesisValidatorsRoots["mainnet"])
+		defer server.Close()
+
+		err := ValidateCheckpointSyncURL(server.URL, "mainnet")
+		assert.EqualValues(t, map[string]string{"checkpointSyncUrl": "checkpoint sync url must be a public address"}, err.(restErrors.RestErr).Validations)
+
+		err = ValidateCheckpointSyncURL("http://10.0.0.1:5052", "mainnet")
+		assert.EqualValues(t, map[string]string{"checkpointSyncUrl": "checkpoint sync url must be a public address"}, err.(restErrors.RestErr).Validations)
+	})
+
+	allowLoopbackAddress(t)
+
+	t.Run("validate_checkpoint_sync_url_should_pass_if_url_serves_the_network", func(t *testing.T) {
+		server := checkpointServer(genesisValidatorsRoots["sepolia"])
+		defer server.Close()
+
+		err := ValidateCheckpointSyncURL(server.URL+"/", "sepolia")
+		assert.Nil(t, err)
+	})
+
+	t.Run("validate_checkpoint_sync_url_should_pass_if_url_is_empty", func(t *testing.T) {
+		err := ValidateCheckpointSyncURL("", "mainnet")
+		assert.Nil(t, err)
+	})
+
+	t.Run("validate_checkpoint_sync_url_should_skip_genesis_check_of_unknown_networks", func(t *testing.T) {
+		server := checkpointServer("0x01")
+		defer server.Close()
+
+		err := ValidateCheckpointSyncURL(server.URL, "my-devnet")
+		assert.Nil(t, err)
+	})
+
+	t.Run("validate_checkpoint_sync_url_should_throw_if_url_serves_another_network", func(t *testing.T) {
+		server := checkpointServer(genesisValidatorsRoots["holesky"])
+		defer server.Close()
+
+		err := ValidateCheckpointSyncURL(server.URL, "mainnet")
+		assert.EqualValues(t, http.StatusBadRequest, err.StatusCode())
+		assert.EqualValues(t, map[string]string{"checkpointSyncUrl": "checkpoint sync url doesn't serve mainnet network"}, err.(restErrors.RestErr).Validations)
+	})
+
+	t.Run("validate_checkpoint_sync_url_should_throw_if_url_is_invalid", func(t *testing.T) {
+		err := ValidateCheckpointSyncURL("ftp://provider.io", "mainnet")
+		assert.EqualValues(t, map[string]string{"checkpointSyncUrl": "checkpoint sync url must be a valid http or https url"}, err.(restErrors.RestErr).Validations)
+	})
+
+	t.Run("validate_checkpoint_sync_url_should_throw_if_url_is_unreachable", func(t *testing.T) {
+		server := checkpointServer(genesisValidatorsRoots["mainnet"])
+		server.Close()
+
+		err := ValidateCheckpointSyncURL(server.URL, "mainnet")
+		assert.EqualValues(t, map[string]string{"checkpointSyncUrl": "checkpoint sync url doesn't serve the finalized beacon block header"}, err.(restErrors.RestErr).Validations)
+	})
+}
+
+func TestVerifyCheckpointSyncURL(t *testing.T) {
+	t.Run("verify_checkpoint_sync_url_should_allow_private_admin_providers", func(t *testing.T) {
+		server := checkpointServer(genesisValidatorsRoots["mainnet"])
+		defer server.Close()
+
+		err := VerifyCheckpointSyncURL(server.URL, "mainnet")
+		assert.Nil(t, err)
+	})
+}
@@ -0,0 +1,213 @@
+package beacon_node
+
+import (
+	"encoding/json"
+	"fmt"
+	restErrors "github.com/kotalco/core-api/pkg/errors"
+	"github.com/kotalco/core-api/pkg/logger"
+	ethereum2v1alpha1 "github.com/kotalco/kotal/apis/ethereum2/v1alpha1"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// StatsDto is the beacon node peers count, syncing status and chain head
+// the chain head fields are left empty and the execution client status is nil if the beacon node didn't report them in time
+type StatsDto struct {
+	CurrentSlot    int    `json:"currentSlot"`
+	TargetSlot     int    `json:"targetSlot"`
+	PeersCount     int    `json:"peersCount"`
+	Syncing        bool   `json:"syncing"`
+	FinalizedEpoch int    `json:"finalizedEpoch"`
+	HeadRoot       string `json:"headRoot"`
+	ForkVersion    string `json:"forkVersion"`
+	ELOnline       *bool  `json:"elOnline"`
+}
+
+// clientAdapter is the beacon node client adapter, the clients serve the standard beacon api on different servers
+type clientAdapter interface {
+	// apiURL returns the in-cluster url of the server serving the beacon api, bad request if the server isn't enabled
+	apiURL(node ethereum2v1alpha1.BeaconNode) (string, restErrors.IRestErr)
+	// peersCount returns the connected peers count
+	peersCount(apiURL string) (int, error)
+}
+
+// restClient is lighthouse, teku and nimbus serving the beacon api on their REST api server
+type restClient struct{}
+
+// prysmClient is prysm serving the beacon api on its gRPC gateway, the gRPC server itself doesn't serve REST
+type prysmClient struct{}
+
+var clients = map[ethereum2v1alpha1.Ethereum2Client]clientAdapter{
+	ethereum2v1alpha1.LighthouseClient: restClient{},
+	ethereum2v1alpha1.TekuClient:       restClient{},
+	ethereum2v1alpha1.NimbusClient:     restClient{},
+	ethereum2v1alpha1.PrysmClient:      prysmClient{},
+}
+
+// httpClient timeout bounds every beacon api call so the stats are always returned
+var httpClient = &http.Client{Timeout: 4 * time.Second}
+
+func (restClient) apiURL(node ethereum2v1alpha1.BeaconNode) (string, restErrors.IRestErr) {
+	if !node.Spec.REST {
+		return "", restErrors.NewBadRequestError("REST API sever is not enabled")
+	}
+	return fmt.Sprintf("http://%s.%s:%d", node.Name, node.Namespace, node.Spec.RESTPort), nil
+}
+
+func (restClient) peersCount(apiURL string) (int, error) {
+	var peers struct {
+		Data struct {
+			Connected string `json:"connected"`
+		} `json:"data"`
+	}
+	if err := getJSON(fmt.Sprintf("%s/eth/v1/node/peer_count", apiURL), &peers); err != nil {
+		return 0, err
+	}
+	return strconv.Atoi(peers.Data.Connected)
+}
+
+// apiURL returns the gRPC gateway url, the spec grpc port is the gateway port
+func (prysmClient) apiURL(node ethereum2v1alpha1.BeaconNode) (string, restErrors.IRestErr) {
+	if !node.Spec.GRPC {
+		return "", restErrors.NewBadRequestError("gRPC gateway sever is not enabled")
+	}
+	return fmt.Sprintf("http://%s.%s:%d", node.Name, node.Namespace, node.Spec.GRPCPort), nil
+}
+
+// peersCount counts the connected peers, the gateway of older prysm releases doesn't serve the peer_count endpoint
+func (prysmClient) peersCount(apiURL string) (int, error) {
+	var peers struct {
+		Data []json.RawMessage `json:"data"`
+	}
+	if err := getJSON(fmt.Sprintf("%s/eth/v1/node/peers?state=connected", apiURL), &peers); err != nil {
+		return 0, err
+	}
+	return len(peers.Data), nil
+}
+
+// APIURL returns the in-cluster url of the beacon node api, bad request if the node api server isn't enabled
+func APIURL(node ethereum2v1alpha1.BeaconNode) (string, restErrors.IRestErr) {
+	adapter, ok := clients[node.Spec.Client]
+	if !ok {
+		return "", restErrors.NewBadRequestError(fmt.Sprintf("client %s is not supported", node.Spec.Client))
+	}
+	return adapter.apiURL(node)
+}
+
+// Stats calls the beacon node api node, head, finality and fork endpoints
+func (service beaconNodeService) Stats(node ethereum2v1alpha1.BeaconNode) (stats StatsDto, restErr restErrors.IRestErr) {
+	apiURL, restErr := APIURL(node)
+	if restErr != nil {
+		return
+	}
+
+	stats, err := collectStats(clients[node.Spec.Client], apiURL)
+	if err != nil {
+		go logger.Warn(service.Stats, err)
+		restErr = restErrors.NewInternalServerError(fmt.Sprintf("can't get beacon node by name %s stats, %s", node.Name, err.Error()))
+	}
+	return
+}
+
+// collectStats calls the beacon api endpoints at the same time, the peers count and syncing status are required and the chain head is best effort
+func collectStats(adapter clientAdapter, apiURL string) (stats StatsDto, err error) {
+	var syncing struct {
+		Data struct {
+			HeadSlot     string `json:"head_slot"`
+			SyncDistance string `json:"sync_distance"`
+			IsSyncing    bool   `json:"is_syncing"`
+			ELOffline    *bool  `json:"el_offline"`
+		} `json:"data"`
+	}
+	var head struct {
+		Data struct {
+			Root string `json:"root"`
+		} `json:"data"`
+	}
+	var finality struct {
+		Data struct {
+			Finalized struct {
+				Epoch string `json:"epoch"`
+			} `json:"finalized"`
+		} `json:"data"`
+	}
+	var fork struct {
+		Data struct {
+			CurrentVersion string `json:"current_version"`
+		} `json:"data"`
+	}
+
+	var peersErr, syncingErr error
+	var wg sync.WaitGroup
+	wg.Add(5)
+	go func() {
+		defer wg.Done()
+		stats.PeersCount, peersErr = adapter.peersCount(apiURL)
+	}()
+	go func() {
+		defer wg.Done()
+		syncingErr = getJSON(fmt.Sprintf("%s/eth/v1/node/syncing", apiURL), &syncing)
+	}()
+	go func() {
+		defer wg.Done()
+		getJSON(fmt.Sprintf("%s/eth/v1/beacon/headers/head", apiURL), &head)
+	}()
+	go func() {
+		defer wg.Done()
+		getJSON(fmt.Sprintf("%s/eth/v1/beacon/states/head/finality_checkpoints", apiURL), &finality)
+	}()
+	go func() {
+		defer wg.Done()
+		getJSON(fmt.Sprintf("%s/eth/v1/beacon/states/head/fork", apiURL), &fork)
+	}()
+	wg.Wait()
+
+	if peersErr != nil {
+		err = fmt.Errorf("can't get peers count: %s", peersErr)
+		return
+	}
+	if syncingErr != nil {
+		err = fmt.Errorf("can't get syncing status: %s", syncingErr)
+		return
+	}
+
+	stats.CurrentSlot, _ = strconv.Atoi(syncing.Data.HeadSlot)
+	stats.Syncing = syncing.Data.IsSyncing
+	syncDistance, _ := strconv.Atoi(syncing.Data.SyncDistance)
+	stats.TargetSlot = stats.CurrentSlot + syncDistance
+	if syncing.Data.ELOffline != nil {
+		online := !*syncing.Data.ELOffline
+		stats.ELOnline = &online
+	}
+
+	stats.HeadRoot = head.Data.Root
+	stats.FinalizedEpoch, _ = strconv.Atoi(finality.Data.Finalized.Epoch)
+	stats.ForkVersion = fork.Data.CurrentVersion
+	return
+}
+
+// getJSON gets the beacon node api url and unmarshalls the response body into out
+func getJSON(url string, out interface{}) error {
+	return fetchJSON(httpClient, url, out)
+}
+
+// fetchJSON gets the url using the client and unmarshalls the response body into out
+func fetchJSON(client *http.Client, url string, out interface{}) error {
+	resp, err := client.Get(url)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("%s returned %d: %s", url, resp.StatusCode, body)
+	}
+	return json.Unmarshal(body, out)
+}
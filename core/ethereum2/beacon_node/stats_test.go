@@ -0,0 +1,119 @@
+package beacon_node
+
+import (
+	ethereum2v1alpha1 "github.com/kotalco/kotal/apis/ethereum2/v1alpha1"
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+// beaconServer serves the beacon api, the chain head endpoints hang if slow is set
+func beaconServer(t *testing.T, slow bool) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/eth/v1/node/peer_count":
+			w.Write([]byte(`{"data":{"connected":"25"}}`))
+		case "/eth/v1/node/peers":
+			assert.Equal(t, "connected", r.URL.Query().Get("state"))
+			w.Write([]byte(`{"data":[{"peer_id":"a"},{"peer_id":"b"}]}`))
+		case "/eth/v1/node/syncing":
+			w.Write([]byte(`{"data":{"head_slot":"100","sync_distance":"20","is_syncing":true,"el_offline":false}}`))
+		case "/eth/v1/beacon/headers/head", "/eth/v1/beacon/states/head/finality_checkpoints", "/eth/v1/beacon/states/head/fork":
+			if slow {
+				time.Sleep(200 * time.Millisecond)
+				return
+			}
+			switch r.URL.Path {
+			case "/eth/v1/beacon/headers/head":
+				w.Write([]byte(`{"data":{"root":"0xabc"}}`))
+			case "/eth/v1/beacon/states/head/finality_checkpoints":
+				w.Write([]byte(`{"data":{"finalized":{"epoch":"2"}}}`))
+			default:
+				w.Write([]byte(`{"data":{"current_version":"0x04000000"}}`))
+			}
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+}
+
+func TestAPIURL(t *testing.T) {
+	node := ethereum2v1alpha1.BeaconNode{ObjectMeta: metav1.ObjectMeta{Name: "my-node", Namespace: "default"}}
+
+	t.Run("api_url_should_use_prysm_grpc_gateway_port", func(t *testing.T) {
+		node.Spec = ethereum2v1alpha1.BeaconNodeSpec{Client: ethereum2v1alpha1.PrysmClient, GRPC: true, GRPCPort: 3500, RPCPort: 4000}
+		url, err := APIURL(node)
+		assert.Nil(t, err)
+		assert.EqualValues(t, "http://my-node.default:3500", url)
+	})
+
+	t.Run("api_url_should_throw_if_prysm_grpc_gateway_is_disabled", func(t *testing.T) {
+		node.Spec = ethereum2v1alpha1.BeaconNodeSpec{Client: ethereum2v1alpha1.PrysmClient}
+		_, err := APIURL(node)
+		assert.EqualValues(t, http.StatusBadRequest, err.StatusCode())
+	})
+
+	t.Run("api_url_should_use_rest_port", func(t *testing.T) {
+		node.Spec = ethereum2v1alpha1.BeaconNodeSpec{Client: ethereum2v1alpha1.NimbusClient, REST: true, RESTPort: 5052}
+		url, err := APIURL(node)
+		assert.Nil(t, err)
+		assert.EqualValues(t, "http://my-node.default:5052", url)
+	})
+
+	t.Run("api_url_should_throw_if_rest_is_disabled", func(t *testing.T) {
+		node.Spec = ethereum2v1alpha1.BeaconNodeSpec{Client: ethereum2v1alpha1.TekuClient}
+		_, err := APIURL(node)
+		assert.EqualValues(t, "REST API sever is not enabled", err.Error())
+	})
+}
+
+func TestCollectStats(t *testing.T) {
+	t.Run("collect_stats_should_return_the_node_stats", func(t *testing.T) {
+		server := beaconServer(t, false)
+		defer server.Close()
+
+		stats, err := collectStats(restClient{}, server.URL)
+		assert.Nil(t, err)
+		assert.EqualValues(t, 25, stats.PeersCount)
+		assert.EqualValues(t, 100, stats.CurrentSlot)
+		assert.EqualValues(t, 120, stats.TargetSlot)
+		assert.True(t, stats.Syncing)
+		assert.True(t, *stats.ELOnline)
+		assert.EqualValues(t, "0xabc", stats.HeadRoot)
+		assert.EqualValues(t, 2, stats.FinalizedEpoch)
+		assert.EqualValues(t, "0x04000000", stats.ForkVersion)
+	})
+
+	t.Run("collect_stats_should_count_prysm_connected_peers", func(t *testing.T) {
+		server := beaconServer(t, false)
+		defer server.Close()
+
+		stats, err := collectStats(prysmClient{}, server.URL)
+		assert.Nil(t, err)
+		assert.EqualValues(t, 2, stats.PeersCount)
+	})
+
+	t.Run("collect_stats_should_return_once_the_chain_head_calls_time_out", func(t *testing.T) {
+		timeout := httpClient.Timeout
+		httpClient.Timeout = 100 * time.Millisecond
+		defer func() { httpClient.Timeout = timeout }()
+		server := beaconServer(t, true)
+		defer server.Close()
+
+		stats, err := collectStats(restClient{}, server.URL)
+		assert.Nil(t, err)
+		assert.EqualValues(t, 25, stats.PeersCount)
+		assert.EqualValues(t, "", stats.HeadRoot)
+	})
+
+	t.Run("collect_stats_should_throw_if_the_node_is_unreachable", func(t *testing.T) {
+		server := beaconServer(t, false)
+		server.Close()
+
+		_, err := collectStats(restClient{}, server.URL)
+		assert.NotNil(t, err)
+	})
+}
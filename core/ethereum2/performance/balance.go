@@ -0,0 +1,20 @@
+package performance
+
+import "time"
+
+// Balance is a validator public key balance at the start of an epoch, recorded once per epoch for the rewards charts
+type Balance struct {
+	ID               string `gorm:"uniqueIndex"`
+	Namespace        string `gorm:"uniqueIndex:idx_balance_epoch"`
+	Validator        string `gorm:"uniqueIndex:idx_balance_epoch"`
+	PublicKey        string `gorm:"uniqueIndex:idx_balance_epoch"`
+	Epoch            uint64 `gorm:"uniqueIndex:idx_balance_epoch"`
+	Balance          uint64
+	EffectiveBalance uint64
+	CreatedAt        time.Time
+}
+
+// TableName names the table after the validators, balances alone is ambiguous
+func (Balance) TableName() string {
+	return "validator_balances"
+}
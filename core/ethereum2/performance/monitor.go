@@ -0,0 +1,48 @@
+package performance
+
+import (
+	"fmt"
+	"github.com/kotalco/core-api/config"
+	"github.com/kotalco/core-api/pkg/logger"
+	"time"
+)
+
+// StartMonitor records the balances of the validators in all namespaces every interval in the background
+// balances already recorded for the epoch are skipped, so the interval can be shorter than an epoch
+// balances recorded more than VALIDATOR_BALANCE_RETENTION seconds ago are deleted
+func StartMonitor(interval time.Duration) {
+	go func() {
+		for {
+			recordBalances()
+			pruneBalances()
+			time.Sleep(interval)
+		}
+	}()
+}
+
+// pruneBalances deletes the balances recorded before the retention window
+func pruneBalances() {
+	retention := time.Duration(config.Environment.ValidatorBalanceRetention) * time.Second
+	if restErr := NewService().Prune(retention); restErr != nil {
+		go logger.Warn("VALIDATOR_MONITOR", fmt.Errorf("can't prune validators balances: %s", restErr.Error()))
+	}
+}
+
+// recordBalances records the balances of the validators whose beacon node is reachable
+func recordBalances() {
+	validators, restErr := validatorService.List("")
+	if restErr != nil {
+		return
+	}
+
+	service := NewService()
+	for _, model := range validators.Items {
+		performance, restErr := service.Performance(model)
+		if restErr != nil {
+			continue
+		}
+		if restErr = service.Record(model, performance); restErr != nil {
+			go logger.Warn("VALIDATOR_MONITOR", fmt.Errorf("can't record validator %s balances: %s", model.Name, restErr.Error()))
+		}
+	}
+}
@@ -0,0 +1,62 @@
+package performance
+
+// PerformanceDto is the validator keys status, balances and duties at the beacon node head epoch, balances are in gwei
+type PerformanceDto struct {
+	Epoch uint64              `json:"epoch"`
+	Keys  []KeyPerformanceDto `json:"keys"`
+}
+
+// KeyPerformanceDto is a validator key performance, the index is nil until the key deposit is processed by the beacon chain
+// attested is whether the key attested in the previous epoch, nil if the beacon node doesn't support the liveness endpoint
+type KeyPerformanceDto struct {
+	PublicKey        string   `json:"publicKey"`
+	Index            *uint64  `json:"index"`
+	Status           string   `json:"status"`
+	Balance          uint64   `json:"balance"`
+	EffectiveBalance uint64   `json:"effectiveBalance"`
+	Attested         *bool    `json:"attested"`
+	AttestationSlot  *uint64  `json:"attestationSlot"`
+	ProposalSlots    []uint64 `json:"proposalSlots"`
+}
+
+// EpochBalanceDto is the validator keys total balance at an epoch, the reward is the balance change of the keys recorded in the previous epoch too
+type EpochBalanceDto struct {
+	Epoch            uint64          `json:"epoch"`
+	Balance          uint64          `json:"balance"`
+	EffectiveBalance uint64          `json:"effectiveBalance"`
+	Reward           int64           `json:"reward"`
+	Keys             []KeyBalanceDto `json:"keys"`
+}
+
+// KeyBalanceDto is a validator key balance at an epoch
+type KeyBalanceDto struct {
+	PublicKey        string `json:"publicKey"`
+	Balance          uint64 `json:"balance"`
+	EffectiveBalance uint64 `json:"effectiveBalance"`
+}
+
+// unknownStatus is the status of the keys the beacon chain doesn't know yet
+const unknownStatus = "unknown"
+
+// FromBalances groups the recorded balances by epoch, balances must be ordered by epoch
+func FromBalances(balances []Balance) []EpochBalanceDto {
+	result := []EpochBalanceDto{}
+	previous := map[string]uint64{}
+	for i := 0; i < len(balances); {
+		epoch := EpochBalanceDto{Epoch: balances[i].Epoch, Keys: []KeyBalanceDto{}}
+		current := map[string]uint64{}
+		for ; i < len(balances) && balances[i].Epoch == epoch.Epoch; i++ {
+			balance := balances[i]
+			epoch.Balance += balance.Balance
+			epoch.EffectiveBalance += balance.EffectiveBalance
+			epoch.Keys = append(epoch.Keys, KeyBalanceDto{PublicKey: balance.PublicKey, Balance: balance.Balance, EffectiveBalance: balance.EffectiveBalance})
+			if before, ok := previous[balance.PublicKey]; ok {
+				epoch.Reward += int64(balance.Balance) - int64(before)
+			}
+			current[balance.PublicKey] = balance.Balance
+		}
+		previous = current
+		result = append(result, epoch)
+	}
+	return result
+}
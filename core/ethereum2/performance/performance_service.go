@@ -0,0 +1,297 @@
+// Package performance internal is the domain layer for the ethereum 2.0 validators performance
+// queries the beacon node the validator is connected to for its keys status, balances and duties and records the balances per epoch
+package performance
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"github.com/google/uuid"
+	"github.com/kotalco/core-api/core/ethereum2/beacon_node"
+	"github.com/kotalco/core-api/core/ethereum2/validator"
+	restErrors "github.com/kotalco/core-api/pkg/errors"
+	"github.com/kotalco/core-api/pkg/logger"
+	ethereum2v1alpha1 "github.com/kotalco/kotal/apis/ethereum2/v1alpha1"
+	"io/ioutil"
+	"k8s.io/apimachinery/pkg/types"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type performanceService struct{}
+
+type IService interface {
+	// Performance returns the validator keys status, balances and duties at the beacon node head epoch
+	Performance(ethereum2v1alpha1.Validator) (PerformanceDto, restErrors.IRestErr)
+	// History returns the validator balances recorded in the last epochs
+	History(validator ethereum2v1alpha1.Validator, epochs uint64) ([]EpochBalanceDto, restErrors.IRestErr)
+	// Record records the validator keys balances of the performance epoch
+	Record(ethereum2v1alpha1.Validator, PerformanceDto) restErrors.IRestErr
+	// Prune deletes the balances recorded before the retention window
+	Prune(retention time.Duration) restErrors.IRestErr
+}
+
+var (
+	balanceRepository = NewRepository()
+	validatorService  = validator.NewValidatorService()
+	beaconNodeService = beacon_node.NewBeaconNodeService()
+	httpClient        = &http.Client{Timeout: 4 * time.Second}
+)
+
+// defaultSlotsPerEpoch is used if the beacon node spec can't be read
+const defaultSlotsPerEpoch = 32
+
+func NewService() IService {
+	return performanceService{}
+}
+
+// Performance calls the beacon node api for the validator keys state, the current epoch duties and the previous epoch liveness
+func (service performanceService) Performance(model ethereum2v1alpha1.Validator) (performance PerformanceDto, restErr restErrors.IRestErr) {
+	apiURL, restErr := service.beaconAPIURL(model)
+	if restErr != nil {
+		return
+	}
+	performance.Keys = []KeyPerformanceDto{}
+
+	publicKeys := validatorService.PublicKeys(model)
+	if len(publicKeys) == 0 {
+		return
+	}
+
+	var head struct {
+		Data struct {
+			Header struct {
+				Message struct {
+					Slot string `json:"slot"`
+				} `json:"message"`
+			} `json:"header"`
+		} `json:"data"`
+	}
+	if err := call(http.MethodGet, fmt.Sprintf("%s/eth/v1/beacon/headers/head", apiURL), nil, &head); err != nil {
+		go logger.Warn(service.Performance, err)
+		restErr = restErrors.NewInternalServerError(fmt.Sprintf("can't get validator by name %s beacon node head", model.Name))
+		return
+	}
+	slot, _ := strconv.ParseUint(head.Data.Header.Message.Slot, 10, 64)
+	performance.Epoch = slot / service.slotsPerEpoch(apiURL)
+
+	var states struct {
+		Data []struct {
+			Index     string `json:"index"`
+			Balance   string `json:"balance"`
+			Status    string `json:"status"`
+			Validator struct {
+				Pubkey           string `json:"pubkey"`
+				EffectiveBalance string `json:"effective_balance"`
+			} `json:"validator"`
+		} `json:"data"`
+	}
+	if err := call(http.MethodGet, fmt.Sprintf("%s/eth/v1/beacon/states/head/validators?id=%s", apiURL, strings.Join(publicKeys, ",")), nil, &states); err != nil {
+		go logger.Warn(service.Performance, err)
+		restErr = restErrors.NewInternalServerError(fmt.Sprintf("can't get validator by name %s keys state", model.Name))
+		return
+	}
+
+	keys := map[string]*KeyPerformanceDto{}
+	indices := []string{}
+	for _, publicKey := range publicKeys {
+		performance.Keys = append(performance.Keys, KeyPerformanceDto{PublicKey: publicKey, Status: unknownStatus, ProposalSlots: []uint64{}})
+	}
+	for i := range performance.Keys {
+		keys[performance.Keys[i].PublicKey] = &performance.Keys[i]
+	}
+	for _, state := range states.Data {
+		key, ok := keys[strings.ToLower(state.Validator.Pubkey)]
+		if !ok {
+			continue
+		}
+		index, _ := strconv.ParseUint(state.Index, 10, 64)
+		key.Index = &index
+		key.Status = state.Status
+		key.Balance, _ = strconv.ParseUint(state.Balance, 10, 64)
+		key.EffectiveBalance, _ = strconv.ParseUint(state.Validator.EffectiveBalance, 10, 64)
+		indices = append(indices, state.Index)
+	}
+	if len(indices) == 0 {
+		return
+	}
+
+	byIndex := map[string]*KeyPerformanceDto{}
+	for _, key := range keys {
+		if key.Index != nil {
+			byIndex[strconv.FormatUint(*key.Index, 10)] = key
+		}
+	}
+
+	var duties struct {
+		Data []struct {
+			ValidatorIndex string `json:"validator_index"`
+			Slot           string `json:"slot"`
+		} `json:"data"`
+	}
+	if err := call(http.MethodPost, fmt.Sprintf("%s/eth/v1/validator/duties/attester/%d", apiURL, performance.Epoch), indices, &duties); err != nil {
+		go logger.Warn(service.Performance, err)
+	}
+	for _, duty := range duties.Data {
+		if key, ok := byIndex[duty.ValidatorIndex]; ok {
+			slot, _ := strconv.ParseUint(duty.Slot, 10, 64)
+			key.AttestationSlot = &slot
+		}
+	}
+
+	duties.Data = nil
+	if err := call(http.MethodGet, fmt.Sprintf("%s/eth/v1/validator/duties/proposer/%d", apiURL, performance.Epoch), nil, &duties); err != nil {
+		go logger.Warn(service.Performance, err)
+	}
+	for _, duty := range duties.Data {
+		if key, ok := byIndex[duty.ValidatorIndex]; ok {
+			slot, _ := strconv.ParseUint(duty.Slot, 10, 64)
+			key.ProposalSlots = append(key.ProposalSlots, slot)
+		}
+	}
+
+	if performance.Epoch == 0 {
+		return
+	}
+	var liveness struct {
+		Data []struct {
+			Index  string `json:"index"`
+			IsLive bool   `json:"is_live"`
+		} `json:"data"`
+	}
+	// the liveness endpoint isn't implemented by all the clients
+	if err := call(http.MethodPost, fmt.Sprintf("%s/eth/v1/validator/liveness/%d", apiURL, performance.Epoch-1), indices, &liveness); err != nil {
+		return
+	}
+	for _, live := range liveness.Data {
+		if key, ok := byIndex[live.Index]; ok {
+			attested := live.IsLive
+			key.Attested = &attested
+		}
+	}
+
+	return
+}
+
+// History returns the recorded balances grouped by epoch
+func (service performanceService) History(model ethereum2v1alpha1.Validator, epochs uint64) ([]EpochBalanceDto, restErrors.IRestErr) {
+	balances, restErr := balanceRepository.History(model.Namespace, model.Name, epochs)
+	if restErr != nil {
+		return nil, restErr
+	}
+	return FromBalances(balances), nil
+}
+
+// Record records the balances of the keys known by the beacon chain
+func (service performanceService) Record(model ethereum2v1alpha1.Validator, performance PerformanceDto) restErrors.IRestErr {
+	balances := []*Balance{}
+	for _, key := range performance.Keys {
+		if key.Index == nil {
+			continue
+		}
+		balances = append(balances, &Balance{
+			ID:               uuid.NewString(),
+			Namespace:        model.Namespace,
+			Validator:        model.Name,
+			PublicKey:        key.PublicKey,
+			Epoch:            performance.Epoch,
+			Balance:          key.Balance,
+			EffectiveBalance: key.EffectiveBalance,
+		})
+	}
+	return balanceRepository.Create(balances)
+}
+
+// Prune deletes the balances recorded more than retention ago, the balances are kept if the retention isn't positive
+func (service performanceService) Prune(retention time.Duration) restErrors.IRestErr {
+	if retention <= 0 {
+		return nil
+	}
+	return balanceRepository.DeleteBefore(time.Now().Add(-retention))
+}
+
+// beaconAPIURL returns the api url of the beacon node the validator connects to
+// the beacon node is looked up by the first beacon endpoint host, other endpoints are used as they are unless the validator is prysm connecting using gRPC
+func (service performanceService) beaconAPIURL(model ethereum2v1alpha1.Validator) (string, restErrors.IRestErr) {
+	if len(model.Spec.BeaconEndpoints) == 0 {
+		return "", restErrors.NewBadRequestError(fmt.Sprintf("validator by name %s has no beacon endpoints", model.Name))
+	}
+	endpoint := model.Spec.BeaconEndpoints[0]
+
+	host := endpoint
+	if i := strings.Index(host, "://"); i != -1 {
+		host = host[i+3:]
+	}
+	if i := strings.IndexAny(host, ":/"); i != -1 {
+		host = host[:i]
+	}
+	labels := strings.Split(host, ".")
+	key := types.NamespacedName{Namespace: model.Namespace, Name: labels[0]}
+	if len(labels) > 1 {
+		key.Namespace = labels[1]
+	}
+
+	if node, restErr := beaconNodeService.Get(key); restErr == nil {
+		return beacon_node.APIURL(node)
+	}
+	if model.Spec.Client == ethereum2v1alpha1.PrysmClient {
+		return "", restErrors.NewBadRequestError(fmt.Sprintf("can't find the beacon node of validator by name %s", model.Name))
+	}
+	if !strings.Contains(endpoint, "://") {
+		endpoint = "http://" + endpoint
+	}
+	return strings.TrimSuffix(endpoint, "/"), nil
+}
+
+// slotsPerEpoch returns the beacon node network slots per epoch
+func (service performanceService) slotsPerEpoch(apiURL string) uint64 {
+	var spec struct {
+		Data struct {
+			SlotsPerEpoch string `json:"SLOTS_PER_EPOCH"`
+		} `json:"data"`
+	}
+	if err := call(http.MethodGet, fmt.Sprintf("%s/eth/v1/config/spec", apiURL), nil, &spec); err != nil {
+		return defaultSlotsPerEpoch
+	}
+	if slots, err := strconv.ParseUint(spec.Data.SlotsPerEpoch, 10, 64); err == nil && slots > 0 {
+		return slots
+	}
+	return defaultSlotsPerEpoch
+}
+
+// call calls the beacon node api url with the json body and unmarshalls the response body into out
+func call(method string, url string, body interface{}, out interface{}) error {
+	var reader *bytes.Reader
+	if body != nil {
+		raw, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		reader = bytes.NewReader(raw)
+	} else {
+		reader = bytes.NewReader(nil)
+	}
+
+	req, err := http.NewRequest(method, url, reader)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	raw, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("%s returned %d: %s", url, resp.StatusCode, raw)
+	}
+	return json.Unmarshal(raw, out)
+}
@@ -0,0 +1,265 @@
+package performance
+
+import (
+	"encoding/json"
+	"github.com/kotalco/core-api/core/ethereum2/beacon_node"
+	"github.com/kotalco/core-api/core/ethereum2/validator"
+	restErrors "github.com/kotalco/core-api/pkg/errors"
+	ethereum2v1alpha1 "github.com/kotalco/kotal/apis/ethereum2/v1alpha1"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+	"time"
+)
+
+/*
+balance repository mocks
+*/
+var (
+	balanceCreateFunc  func(balances []*Balance) restErrors.IRestErr
+	balanceHistoryFunc func(namespace string, validator string, epochs uint64) ([]Balance, restErrors.IRestErr)
+	balanceDeleteFunc  func(recordedBefore time.Time) restErrors.IRestErr
+)
+
+type balanceRepositoryMock struct{}
+
+func (r balanceRepositoryMock) WithTransaction(txHandle *gorm.DB) IRepository {
+	return r
+}
+func (r balanceRepositoryMock) WithoutTransaction() IRepository {
+	return r
+}
+func (balanceRepositoryMock) Create(balances []*Balance) restErrors.IRestErr {
+	return balanceCreateFunc(balances)
+}
+func (balanceRepositoryMock) History(namespace string, validator string, epochs uint64) ([]Balance, restErrors.IRestErr) {
+	return balanceHistoryFunc(namespace, validator, epochs)
+}
+func (balanceRepositoryMock) DeleteBefore(recordedBefore time.Time) restErrors.IRestErr {
+	return balanceDeleteFunc(recordedBefore)
+}
+
+/*
+validator and beacon node services mocks
+*/
+var (
+	validatorPublicKeysFunc func(model ethereum2v1alpha1.Validator) []string
+	beaconNodeGetFunc       func(key types.NamespacedName) (ethereum2v1alpha1.BeaconNode, restErrors.IRestErr)
+)
+
+type validatorServiceMock struct {
+	validator.IService
+}
+
+func (validatorServiceMock) PublicKeys(model ethereum2v1alpha1.Validator) []string {
+	return validatorPublicKeysFunc(model)
+}
+
+type beaconNodeServiceMock struct {
+	beacon_node.IService
+}
+
+func (beaconNodeServiceMock) Get(key types.NamespacedName) (ethereum2v1alpha1.BeaconNode, restErrors.IRestErr) {
+	return beaconNodeGetFunc(key)
+}
+
+const (
+	publicKey1 = "0x93247f2209abcacf57b75a51dafae777f9dd38bc7053d1af526f220a7489a6d3a2753e5f3e8b1cfe39b56f43611df74a"
+	publicKey2 = "0xa99a76ed7796f7be22d5b7e85deeb7c5677e88e511e0b337618f8c4eb61349b4bf2d153f649f7b53359fe8b94a38e44c"
+)
+
+func TestMain(m *testing.M) {
+	balanceRepository = &balanceRepositoryMock{}
+	validatorService = &validatorServiceMock{}
+	beaconNodeService = &beaconNodeServiceMock{}
+	validatorPublicKeysFunc = func(model ethereum2v1alpha1.Validator) []string {
+		return []string{publicKey1, publicKey2}
+	}
+	beaconNodeGetFunc = func(key types.NamespacedName) (ethereum2v1alpha1.BeaconNode, restErrors.IRestErr) {
+		return ethereum2v1alpha1.BeaconNode{}, restErrors.NewNotFoundError("beacon node doesn't exist")
+	}
+
+	code := m.Run()
+	os.Exit(code)
+}
+
+// beaconServer serves a beacon node api knowing only the first public key with index 7
+func beaconServer(t *testing.T, liveness bool) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/eth/v1/config/spec":
+			w.Write([]byte(`{"data":{"SLOTS_PER_EPOCH":"32"}}`))
+		case "/eth/v1/beacon/headers/head":
+			w.Write([]byte(`{"data":{"header":{"message":{"slot":"6410"}}}}`))
+		case "/eth/v1/beacon/states/head/validators":
+			assert.Equal(t, publicKey1+","+publicKey2, r.URL.Query().Get("id"))
+			w.Write([]byte(`{"data":[{"index":"7","balance":"32001000000","status":"active_ongoing","validator":{"pubkey":"` + publicKey1 + `","effective_balance":"32000000000"}}]}`))
+		case "/eth/v1/validator/duties/attester/200":
+			var indices []string
+			json.NewDecoder(r.Body).Decode(&indices)
+			assert.EqualValues(t, []string{"7"}, indices)
+			w.Write([]byte(`{"data":[{"validator_index":"7","slot":"6415"}]}`))
+		case "/eth/v1/validator/duties/proposer/200":
+			w.Write([]byte(`{"data":[{"validator_index":"3","slot":"6400"},{"validator_index":"7","slot":"6420"}]}`))
+		case "/eth/v1/validator/liveness/199":
+			if !liveness {
+				w.WriteHeader(http.StatusNotFound)
+				return
+			}
+			w.Write([]byte(`{"data":[{"index":"7","is_live":true}]}`))
+		default:
+			t.Errorf("unexpected call to %s", r.URL.Path)
+		}
+	}))
+}
+
+func TestService_Performance(t *testing.T) {
+	t.Run("performance_should_throw_if_validator_has_no_beacon_endpoints", func(t *testing.T) {
+		_, err := NewService().Performance(ethereum2v1alpha1.Validator{ObjectMeta: metav1.ObjectMeta{Name: "my-validator"}})
+		assert.EqualValues(t, "validator by name my-validator has no beacon endpoints", err.Error())
+	})
+
+	t.Run("performance_should_throw_if_prysm_beacon_node_is_not_found", func(t *testing.T) {
+		_, err := NewService().Performance(ethereum2v1alpha1.Validator{
+			ObjectMeta: metav1.ObjectMeta{Name: "my-validator", Namespace: "default"},
+			Spec:       ethereum2v1alpha1.ValidatorSpec{Client: ethereum2v1alpha1.PrysmClient, BeaconEndpoints: []string{"my-beacon:4000"}},
+		})
+		assert.EqualValues(t, http.StatusBadRequest, err.StatusCode())
+	})
+
+	t.Run("performance_should_return_keys_state_and_duties", func(t *testing.T) {
+		server := beaconServer(t, true)
+		defer server.Close()
+
+		performance, err := NewService().Performance(ethereum2v1alpha1.Validator{
+			ObjectMeta: metav1.ObjectMeta{Name: "my-validator", Namespace: "default"},
+			Spec:       ethereum2v1alpha1.ValidatorSpec{Client: ethereum2v1alpha1.LighthouseClient, BeaconEndpoints: []string{server.URL + "/"}},
+		})
+		assert.Nil(t, err)
+		assert.EqualValues(t, 200, performance.Epoch)
+		assert.Len(t, performance.Keys, 2)
+
+		key := performance.Keys[0]
+		assert.EqualValues(t, 7, *key.Index)
+		assert.EqualValues(t, "active_ongoing", key.Status)
+		assert.EqualValues(t, 32001000000, key.Balance)
+		assert.EqualValues(t, 32000000000, key.EffectiveBalance)
+		assert.EqualValues(t, 6415, *key.AttestationSlot)
+		assert.EqualValues(t, []uint64{6420}, key.ProposalSlots)
+		assert.True(t, *key.Attested)
+
+		assert.Nil(t, performance.Keys[1].Index)
+		assert.EqualValues(t, "unknown", performance.Keys[1].Status)
+	})
+
+	t.Run("performance_should_leave_attested_unset_if_liveness_is_not_supported", func(t *testing.T) {
+		server := beaconServer(t, false)
+		defer server.Close()
+
+		performance, err := NewService().Performance(ethereum2v1alpha1.Validator{
+			ObjectMeta: metav1.ObjectMeta{Name: "my-validator", Namespace: "default"},
+			Spec:       ethereum2v1alpha1.ValidatorSpec{Client: ethereum2v1alpha1.TekuClient, BeaconEndpoints: []string{server.URL}},
+		})
+		assert.Nil(t, err)
+		assert.Nil(t, performance.Keys[0].Attested)
+	})
+
+	t.Run("performance_should_query_the_paired_beacon_node_api", func(t *testing.T) {
+		beaconNodeGetFunc = func(key types.NamespacedName) (ethereum2v1alpha1.BeaconNode, restErrors.IRestErr) {
+			assert.EqualValues(t, types.NamespacedName{Namespace: "other", Name: "my-beacon"}, key)
+			return ethereum2v1alpha1.BeaconNode{ObjectMeta: metav1.ObjectMeta{Name: "my-beacon", Namespace: "other"}, Spec: ethereum2v1alpha1.BeaconNodeSpec{Client: ethereum2v1alpha1.LighthouseClient}}, nil
+		}
+		defer func() {
+			beaconNodeGetFunc = func(key types.NamespacedName) (ethereum2v1alpha1.BeaconNode, restErrors.IRestErr) {
+				return ethereum2v1alpha1.BeaconNode{}, restErrors.NewNotFoundError("beacon node doesn't exist")
+			}
+		}()
+
+		_, err := NewService().Performance(ethereum2v1alpha1.Validator{
+			ObjectMeta: metav1.ObjectMeta{Name: "my-validator", Namespace: "default"},
+			Spec:       ethereum2v1alpha1.ValidatorSpec{Client: ethereum2v1alpha1.PrysmClient, BeaconEndpoints: []string{"my-beacon.other:4000"}},
+		})
+		assert.EqualValues(t, "REST API sever is not enabled", err.Error())
+	})
+}
+
+func TestService_Record(t *testing.T) {
+	t.Run("record_should_record_the_keys_known_by_the_beacon_chain", func(t *testing.T) {
+		var recorded []*Balance
+		balanceCreateFunc = func(balances []*Balance) restErrors.IRestErr {
+			recorded = balances
+			return nil
+		}
+		index := uint64(7)
+
+		err := NewService().Record(ethereum2v1alpha1.Validator{ObjectMeta: metav1.ObjectMeta{Name: "my-validator", Namespace: "default"}}, PerformanceDto{
+			Epoch: 200,
+			Keys:  []KeyPerformanceDto{{PublicKey: publicKey1, Index: &index, Balance: 2, EffectiveBalance: 1}, {PublicKey: publicKey2}},
+		})
+		assert.Nil(t, err)
+		assert.Len(t, recorded, 1)
+		assert.EqualValues(t, Balance{ID: recorded[0].ID, Namespace: "default", Validator: "my-validator", PublicKey: publicKey1, Epoch: 200, Balance: 2, EffectiveBalance: 1}, *recorded[0])
+	})
+}
+
+func TestService_History(t *testing.T) {
+	t.Run("history_should_group_balances_by_epoch_with_rewards", func(t *testing.T) {
+		balanceHistoryFunc = func(namespace string, validator string, epochs uint64) ([]Balance, restErrors.IRestErr) {
+			assert.EqualValues(t, 10, epochs)
+			return []Balance{
+				{PublicKey: publicKey1, Epoch: 1, Balance: 100, EffectiveBalance: 100},
+				{PublicKey: publicKey1, Epoch: 2, Balance: 110, EffectiveBalance: 100},
+				{PublicKey: publicKey2, Epoch: 2, Balance: 50, EffectiveBalance: 50},
+				{PublicKey: publicKey1, Epoch: 3, Balance: 105, EffectiveBalance: 100},
+				{PublicKey: publicKey2, Epoch: 3, Balance: 53, EffectiveBalance: 50},
+			}, nil
+		}
+
+		history, err := NewService().History(ethereum2v1alpha1.Validator{ObjectMeta: metav1.ObjectMeta{Name: "my-validator", Namespace: "default"}}, 10)
+		assert.Nil(t, err)
+		assert.Len(t, history, 3)
+		assert.EqualValues(t, 0, history[0].Reward)
+		assert.EqualValues(t, 10, history[1].Reward)
+		assert.EqualValues(t, 160, history[1].Balance)
+		assert.EqualValues(t, -2, history[2].Reward)
+		assert.Len(t, history[2].Keys, 2)
+	})
+
+	t.Run("history_should_throw_if_repo_throws", func(t *testing.T) {
+		balanceHistoryFunc = func(namespace string, validator string, epochs uint64) ([]Balance, restErrors.IRestErr) {
+			return nil, restErrors.NewInternalServerError("something went wrong")
+		}
+
+		_, err := NewService().History(ethereum2v1alpha1.Validator{}, 10)
+		assert.EqualValues(t, "something went wrong", err.Error())
+	})
+}
+
+func TestService_Prune(t *testing.T) {
+	t.Run("prune_should_delete_the_balances_recorded_before_the_retention", func(t *testing.T) {
+		var deletedBefore time.Time
+		balanceDeleteFunc = func(recordedBefore time.Time) restErrors.IRestErr {
+			deletedBefore = recordedBefore
+			return nil
+		}
+
+		err := NewService().Prune(time.Hour)
+		assert.Nil(t, err)
+		assert.WithinDuration(t, time.Now().Add(-time.Hour), deletedBefore, time.Minute)
+	})
+
+	t.Run("prune_should_keep_the_balances_if_the_retention_isn't_positive", func(t *testing.T) {
+		balanceDeleteFunc = func(recordedBefore time.Time) restErrors.IRestErr {
+			t.Fatal("the balances shouldn't be deleted")
+			return nil
+		}
+
+		err := NewService().Prune(0)
+		assert.Nil(t, err)
+	})
+}
@@ -0,0 +1,73 @@
+package performance
+
+import (
+	restErrors "github.com/kotalco/core-api/pkg/errors"
+	"github.com/kotalco/core-api/pkg/logger"
+	"github.com/kotalco/core-api/pkg/sqlclient"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"time"
+)
+
+type repository struct {
+	db *gorm.DB
+}
+
+type IRepository interface {
+	WithTransaction(txHandle *gorm.DB) IRepository
+	WithoutTransaction() IRepository
+	// Create records the balances, balances already recorded for their epoch are skipped
+	Create(balances []*Balance) restErrors.IRestErr
+	// History returns the validator balances recorded in the last epochs ordered by epoch
+	History(namespace string, validator string, epochs uint64) ([]Balance, restErrors.IRestErr)
+	// DeleteBefore deletes the balances recorded before the given time
+	DeleteBefore(recordedBefore time.Time) restErrors.IRestErr
+}
+
+func NewRepository() IRepository {
+	newRepo := repository{}
+	newRepo.db = sqlclient.OpenDBConnection()
+	return newRepo
+}
+
+func (r repository) WithTransaction(txHandle *gorm.DB) IRepository {
+	r.db = txHandle
+	return r
+}
+
+func (r repository) WithoutTransaction() IRepository {
+	r.db = sqlclient.OpenDBConnection()
+	return r
+}
+
+func (r repository) Create(balances []*Balance) restErrors.IRestErr {
+	if len(balances) == 0 {
+		return nil
+	}
+	res := r.db.Clauses(clause.OnConflict{DoNothing: true}).Create(balances)
+	if res.Error != nil {
+		go logger.Error(r.Create, res.Error)
+		return restErrors.NewInternalServerError("something went wrong")
+	}
+	return nil
+}
+
+func (r repository) History(namespace string, validator string, epochs uint64) ([]Balance, restErrors.IRestErr) {
+	var balances []Balance
+	latest := r.db.Model(&Balance{}).Select("COALESCE(MAX(epoch), 0)").Where("namespace = ? AND validator = ?", namespace, validator)
+	res := r.db.Where("namespace = ? AND validator = ? AND epoch + ? > (?)", namespace, validator, epochs, latest).Order("epoch ASC").Find(&balances)
+	if res.Error != nil {
+		go logger.Error(r.History, res.Error)
+		return nil, restErrors.NewInternalServerError("something went wrong")
+	}
+	return balances, nil
+}
+
+func (r repository) DeleteBefore(recordedBefore time.Time) restErrors.IRestErr {
+	res := r.db.Where("created_at < ?", recordedBefore).Delete(&Balance{})
+	if res.Error != nil {
+		go logger.Error(r.DeleteBefore, res.Error)
+		return restErrors.NewInternalServerError("something went wrong")
+	}
+	return nil
+}
@@ -0,0 +1,474 @@
+package validator
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/kotalco/core-api/core/secret"
+	"github.com/kotalco/core-api/k8s"
+	restErrors "github.com/kotalco/core-api/pkg/errors"
+	"github.com/kotalco/core-api/pkg/logger"
+	ethereum2v1alpha1 "github.com/kotalco/kotal/apis/ethereum2/v1alpha1"
+	"io/ioutil"
+	corev1 "k8s.io/api/core/v1"
+	apiErrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// KeymanagerPortAnnotation and KeymanagerTokenAnnotation hold the validator client keymanager api port and the secret holding its bearer token
+	KeymanagerPortAnnotation  = "kotal.io/keymanager-port"
+	KeymanagerTokenAnnotation = "kotal.io/keymanager-token-secret"
+	// keymanagerTokenKey is the token secret data key
+	keymanagerTokenKey = "token"
+	// slashingProtectionKey is the slashing protection secret data key
+	slashingProtectionKey = "interchange"
+)
+
+// keymanagerPorts are the validator clients default keymanager api ports
+var keymanagerPorts = map[ethereum2v1alpha1.Ethereum2Client]uint{
+	ethereum2v1alpha1.LighthouseClient: 5062,
+	ethereum2v1alpha1.TekuClient:       5052,
+	ethereum2v1alpha1.PrysmClient:      7500,
+	ethereum2v1alpha1.NimbusClient:     5052,
+}
+
+var keymanagerClient = &http.Client{Timeout: 10 * time.Second}
+
+// KeymanagerDto is the validator client keymanager api port and the secret holding its bearer token
+type KeymanagerDto struct {
+	Port            uint   `json:"port"`
+	TokenSecretName string `json:"tokenSecretName"`
+}
+
+// KeyDto is a public key loaded by the validator client, the secret name is set if the key keystore is in the validator spec
+type KeyDto struct {
+	PublicKey      string `json:"publicKey"`
+	DerivationPath string `json:"derivationPath,omitempty"`
+	Readonly       bool   `json:"readonly"`
+	SecretName     string `json:"secretName,omitempty"`
+}
+
+// ImportKeystoreDto is an EIP-2335 keystore secret, the password is read from the keystore secret unless a password secret is given
+type ImportKeystoreDto struct {
+	SecretName         string `json:"secretName"`
+	PasswordSecretName string `json:"passwordSecretName"`
+}
+
+// ImportKeysDto is the keystores to import and the slashing protection secret exported when the keys were removed from another validator
+type ImportKeysDto struct {
+	Keystores                    []ImportKeystoreDto `json:"keystores"`
+	SlashingProtectionSecretName string              `json:"slashingProtectionSecretName"`
+}
+
+// RemoveKeysDto is the public keys to remove
+type RemoveKeysDto struct {
+	PublicKeys []string `json:"publicKeys"`
+}
+
+// KeyResultDto is the keymanager api result of importing or removing a key
+type KeyResultDto struct {
+	PublicKey string `json:"publicKey"`
+	Status    string `json:"status"`
+	Message   string `json:"message,omitempty"`
+}
+
+// RemovedKeysDto is the removed keys results and their EIP-3076 slashing protection interchange, also saved to a secret
+// the failures after the keys are removed are reported as warnings, the interchange must reach the caller anyway
+type RemovedKeysDto struct {
+	Results                      []KeyResultDto  `json:"results"`
+	SlashingProtection           json.RawMessage `json:"slashingProtection"`
+	SlashingProtectionSecretName string          `json:"slashingProtectionSecretName"`
+	Warnings                     []string        `json:"warnings,omitempty"`
+}
+
+// Validate validates the keymanager token secret is given
+func (dto KeymanagerDto) Validate() restErrors.IRestErr {
+	if dto.TokenSecretName == "" {
+		return restErrors.NewValidationError(map[string]string{"tokenSecretName": "tokenSecretName is required"})
+	}
+	return nil
+}
+
+// Validate validates the keystores secrets are given
+func (dto ImportKeysDto) Validate() restErrors.IRestErr {
+	fields := map[string]string{}
+	if len(dto.Keystores) == 0 {
+		fields["keystores"] = "keystores must have at least one keystore"
+	}
+	for i, keystore := range dto.Keystores {
+		if keystore.SecretName == "" {
+			fields[fmt.Sprintf("keystores[%d].secretName", i)] = "secretName is required"
+		}
+	}
+	if len(fields) > 0 {
+		return restErrors.NewValidationError(fields)
+	}
+	return nil
+}
+
+// Validate validates the public keys are BLS public keys
+func (dto RemoveKeysDto) Validate() restErrors.IRestErr {
+	fields := map[string]string{}
+	if len(dto.PublicKeys) == 0 {
+		fields["publicKeys"] = "publicKeys must have at least one public key"
+	}
+	for i, publicKey := range dto.PublicKeys {
+		if len(normalizePublicKey(publicKey)) != 98 {
+			fields[fmt.Sprintf("publicKeys[%d]", i)] = "public key must be 48 bytes hex"
+		}
+	}
+	if len(fields) > 0 {
+		return restErrors.NewValidationError(fields)
+	}
+	return nil
+}
+
+// UpdateKeymanager saves the validator client keymanager api port and token secret to the validator
+func (service validatorService) UpdateKeymanager(dto KeymanagerDto, validator *ethereum2v1alpha1.Validator) restErrors.IRestErr {
+	if _, restErr := service.secretData(types.NamespacedName{Namespace: validator.Namespace, Name: dto.TokenSecretName}, keymanagerTokenKey); restErr != nil {
+		return restErr
+	}
+
+	if validator.Annotations == nil {
+		validator.Annotations = map[string]string{}
+	}
+	validator.Annotations[KeymanagerTokenAnnotation] = dto.TokenSecretName
+	if dto.Port != 0 {
+		validator.Annotations[KeymanagerPortAnnotation] = strconv.Itoa(int(dto.Port))
+	} else {
+		delete(validator.Annotations, KeymanagerPortAnnotation)
+	}
+
+	if err := k8sClient.Update(context.Background(), validator); err != nil {
+		go logger.Error(service.UpdateKeymanager, err)
+		return restErrors.NewInternalServerError(fmt.Sprintf("can't update validator by name %s", validator.Name))
+	}
+	return nil
+}
+
+// Keys lists the public keys loaded by the validator client
+func (service validatorService) Keys(validator ethereum2v1alpha1.Validator) ([]KeyDto, restErrors.IRestErr) {
+	var response struct {
+		Data []struct {
+			ValidatingPubkey string `json:"validating_pubkey"`
+			DerivationPath   string `json:"derivation_path"`
+			Readonly         bool   `json:"readonly"`
+		} `json:"data"`
+	}
+	if restErr := service.keymanager(validator, http.MethodGet, nil, &response); restErr != nil {
+		return nil, restErr
+	}
+
+	secretNames := service.keystoresByPublicKey(validator)
+	keys := make([]KeyDto, len(response.Data))
+	for i, key := range response.Data {
+		keys[i] = KeyDto{
+			PublicKey:      key.ValidatingPubkey,
+			DerivationPath: key.DerivationPath,
+			Readonly:       key.Readonly,
+			SecretName:     secretNames[normalizePublicKey(key.ValidatingPubkey)],
+		}
+	}
+	return keys, nil
+}
+
+// ImportKeys imports the keystores into the validator client, the imported keystores are added to the validator spec to be loaded on restarts
+func (service validatorService) ImportKeys(dto ImportKeysDto, validator *ethereum2v1alpha1.Validator) ([]KeyResultDto, restErrors.IRestErr) {
+	request := struct {
+		Keystores          []string `json:"keystores"`
+		Passwords          []string `json:"passwords"`
+		SlashingProtection string   `json:"slashing_protection,omitempty"`
+	}{}
+	publicKeys := make([]string, len(dto.Keystores))
+
+	for i, keystore := range dto.Keystores {
+		key := types.NamespacedName{Namespace: validator.Namespace, Name: keystore.SecretName}
+		keystoreJSON, restErr := service.secretData(key, "keystore")
+		if restErr != nil {
+			return nil, restErr
+		}
+		if keystore.PasswordSecretName != "" {
+			key.Name = keystore.PasswordSecretName
+		}
+		password, restErr := service.secretData(key, "password")
+		if restErr != nil {
+			return nil, restErr
+		}
+
+		publicKeys[i] = keystorePublicKey(keystoreJSON)
+		request.Keystores = append(request.Keystores, keystoreJSON)
+		request.Passwords = append(request.Passwords, password)
+	}
+
+	if dto.SlashingProtectionSecretName != "" {
+		interchange, restErr := service.secretData(types.NamespacedName{Namespace: validator.Namespace, Name: dto.SlashingProtectionSecretName}, slashingProtectionKey)
+		if restErr != nil {
+			return nil, restErr
+		}
+		request.SlashingProtection = interchange
+	}
+
+	var response struct {
+		Data []struct {
+			Status  string `json:"status"`
+			Message string `json:"message"`
+		} `json:"data"`
+	}
+	if restErr := service.keymanager(*validator, http.MethodPost, request, &response); restErr != nil {
+		return nil, restErr
+	}
+
+	results := make([]KeyResultDto, len(dto.Keystores))
+	inSpec := service.keystoresByPublicKey(*validator)
+	added := false
+	for i, keystore := range dto.Keystores {
+		results[i] = KeyResultDto{PublicKey: publicKeys[i]}
+		if i < len(response.Data) {
+			results[i].Status = response.Data[i].Status
+			results[i].Message = response.Data[i].Message
+		}
+		if results[i].Status != "imported" && results[i].Status != "duplicate" {
+			continue
+		}
+		if _, ok := inSpec[normalizePublicKey(publicKeys[i])]; ok {
+			continue
+		}
+		validator.Spec.Keystores = append(validator.Spec.Keystores, ethereum2v1alpha1.Keystore{PublicKey: publicKeys[i], SecretName: keystore.SecretName})
+		added = true
+	}
+
+	if added {
+		if err := k8sClient.Update(context.Background(), validator); err != nil {
+			go logger.Error(service.ImportKeys, err)
+			return results, restErrors.NewInternalServerError(fmt.Sprintf("keys are imported but can't update validator by name %s keystores", validator.Name))
+		}
+	}
+	return results, nil
+}
+
+// RemoveKeys removes the keys from the validator client and its spec, the exported slashing protection interchange is saved to a secret
+// the validator must keep at least one keystore in its spec
+func (service validatorService) RemoveKeys(dto RemoveKeysDto, validator *ethereum2v1alpha1.Validator) (result RemovedKeysDto, restErr restErrors.IRestErr) {
+	removed := map[string]bool{}
+	for _, publicKey := range dto.PublicKeys {
+		removed[normalizePublicKey(publicKey)] = true
+	}
+
+	inSpec := service.keystoresByPublicKey(*validator)
+	remaining := len(validator.Spec.Keystores)
+	for publicKey := range inSpec {
+		if removed[publicKey] {
+			remaining--
+		}
+	}
+	if remaining < 1 {
+		restErr = restErrors.NewBadRequestError("validator must keep at least one keystore")
+		return
+	}
+
+	request := struct {
+		Pubkeys []string `json:"pubkeys"`
+	}{Pubkeys: dto.PublicKeys}
+	var response struct {
+		Data []struct {
+			Status  string `json:"status"`
+			Message string `json:"message"`
+		} `json:"data"`
+		SlashingProtection string `json:"slashing_protection"`
+	}
+	if restErr = service.keymanager(*validator, http.MethodDelete, request, &response); restErr != nil {
+		return
+	}
+
+	result.Results = make([]KeyResultDto, len(dto.PublicKeys))
+	stopped := map[string]bool{}
+	for i, publicKey := range dto.PublicKeys {
+		result.Results[i] = KeyResultDto{PublicKey: publicKey}
+		if i < len(response.Data) {
+			result.Results[i].Status = response.Data[i].Status
+			result.Results[i].Message = response.Data[i].Message
+		}
+		switch result.Results[i].Status {
+		case "deleted", "not_active", "not_found":
+			stopped[normalizePublicKey(publicKey)] = true
+		}
+	}
+	result.SlashingProtection = json.RawMessage(response.SlashingProtection)
+
+	if response.SlashingProtection != "" {
+		result.SlashingProtectionSecretName = fmt.Sprintf("%s-slashing-protection-%d", validator.Name, time.Now().Unix())
+		_, restErr = secretService.Create(secret.SecretDto{
+			MetaDataDto: k8s.MetaDataDto{Name: result.SlashingProtectionSecretName, Namespace: validator.Namespace},
+			Type:        secret.SlashingProtectionType,
+			Data:        map[string]string{slashingProtectionKey: response.SlashingProtection},
+		})
+		if restErr != nil {
+			// the interchange is still returned to be kept by the caller
+			go logger.Error(service.RemoveKeys, restErr)
+			result.SlashingProtectionSecretName = ""
+			result.Warnings = append(result.Warnings, "slashing protection interchange can't be saved to a secret, keep the returned interchange")
+			restErr = nil
+		}
+	}
+
+	keystores := []ethereum2v1alpha1.Keystore{}
+	for _, keystore := range validator.Spec.Keystores {
+		if publicKey := service.specPublicKey(validator.Namespace, keystore); !stopped[publicKey] {
+			keystores = append(keystores, keystore)
+		}
+	}
+	if len(keystores) != len(validator.Spec.Keystores) {
+		validator.Spec.Keystores = keystores
+		if err := k8sClient.Update(context.Background(), validator); err != nil {
+			go logger.Error(service.RemoveKeys, err)
+			result.Warnings = append(result.Warnings, fmt.Sprintf("keys are removed but can't update validator by name %s keystores", validator.Name))
+		}
+	}
+	return
+}
+
+// keymanager calls the validator client keymanager api keystores endpoint
+func (service validatorService) keymanager(validator ethereum2v1alpha1.Validator, method string, body interface{}, out interface{}) restErrors.IRestErr {
+	tokenSecret := validator.Annotations[KeymanagerTokenAnnotation]
+	if tokenSecret == "" {
+		return restErrors.NewBadRequestError(fmt.Sprintf("validator by name %s keymanager api isn't configured", validator.Name))
+	}
+	token, restErr := service.secretData(types.NamespacedName{Namespace: validator.Namespace, Name: tokenSecret}, keymanagerTokenKey)
+	if restErr != nil {
+		return restErr
+	}
+
+	port := keymanagerPorts[validator.Spec.Client]
+	if value, err := strconv.Atoi(validator.Annotations[KeymanagerPortAnnotation]); err == nil {
+		port = uint(value)
+	}
+
+	pod := &corev1.Pod{}
+	if err := k8sClient.Get(context.Background(), types.NamespacedName{Namespace: validator.Namespace, Name: fmt.Sprintf("%s-0", validator.Name)}, pod); err != nil || pod.Status.PodIP == "" {
+		return restErrors.NewBadRequestError(fmt.Sprintf("validator by name %s isn't running", validator.Name))
+	}
+
+	var reader *bytes.Reader
+	if body != nil {
+		raw, _ := json.Marshal(body)
+		reader = bytes.NewReader(raw)
+	} else {
+		reader = bytes.NewReader(nil)
+	}
+
+	req, err := http.NewRequest(method, fmt.Sprintf("http://%s:%d/eth/v1/keystores", pod.Status.PodIP, port), reader)
+	if err != nil {
+		go logger.Error(service.keymanager, err)
+		return restErrors.NewInternalServerError("something went wrong")
+	}
+	req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", strings.TrimSpace(token)))
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := keymanagerClient.Do(req)
+	if err != nil {
+		go logger.Warn(service.keymanager, err)
+		return restErrors.NewBadRequestError(fmt.Sprintf("can't reach validator by name %s keymanager api", validator.Name))
+	}
+	defer resp.Body.Close()
+
+	raw, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		go logger.Warn(service.keymanager, err)
+		return restErrors.NewInternalServerError(fmt.Sprintf("can't read validator by name %s keymanager api response", validator.Name))
+	}
+	if resp.StatusCode == http.StatusUnauthorized || resp.StatusCode == http.StatusForbidden {
+		return restErrors.NewBadRequestError(fmt.Sprintf("validator by name %s keymanager api rejected the token", validator.Name))
+	}
+	if resp.StatusCode != http.StatusOK {
+		var apiErr struct {
+			Message string `json:"message"`
+		}
+		json.Unmarshal(raw, &apiErr)
+		return restErrors.NewBadRequestError(fmt.Sprintf("validator by name %s keymanager api returned %d: %s", validator.Name, resp.StatusCode, apiErr.Message))
+	}
+
+	if err := json.Unmarshal(raw, out); err != nil {
+		go logger.Warn(service.keymanager, err)
+		return restErrors.NewInternalServerError(fmt.Sprintf("can't read validator by name %s keymanager api response", validator.Name))
+	}
+	return nil
+}
+
+// secretData returns the secret data key value
+func (service validatorService) secretData(key types.NamespacedName, dataKey string) (string, restErrors.IRestErr) {
+	model := corev1.Secret{}
+	if err := k8sClient.Get(context.Background(), key, &model); err != nil {
+		if apiErrors.IsNotFound(err) {
+			return "", restErrors.NewNotFoundError(fmt.Sprintf("secret by name %s doesn't exist", key.Name))
+		}
+		go logger.Error(service.secretData, err)
+		return "", restErrors.NewInternalServerError(fmt.Sprintf("can't get secret by name %s", key.Name))
+	}
+
+	value, ok := model.Data[dataKey]
+	if !ok || len(value) == 0 {
+		return "", restErrors.NewBadRequestError(fmt.Sprintf("secret by name %s has no %s", key.Name, dataKey))
+	}
+	return string(value), nil
+}
+
+// PublicKeys returns the validator spec keystores public keys, keystores whose public key can't be read are skipped
+func (service validatorService) PublicKeys(validator ethereum2v1alpha1.Validator) []string {
+	publicKeys := []string{}
+	for _, keystore := range validator.Spec.Keystores {
+		if publicKey := service.specPublicKey(validator.Namespace, keystore); publicKey != "" {
+			publicKeys = append(publicKeys, publicKey)
+		}
+	}
+	return publicKeys
+}
+
+// keystoresByPublicKey returns the validator spec keystores secret names by their public keys
+func (service validatorService) keystoresByPublicKey(validator ethereum2v1alpha1.Validator) map[string]string {
+	result := map[string]string{}
+	for _, keystore := range validator.Spec.Keystores {
+		if publicKey := service.specPublicKey(validator.Namespace, keystore); publicKey != "" {
+			result[publicKey] = keystore.SecretName
+		}
+	}
+	return result
+}
+
+// specPublicKey returns the spec keystore public key, read from its keystore secret if the spec doesn't have it
+func (service validatorService) specPublicKey(namespace string, keystore ethereum2v1alpha1.Keystore) string {
+	if keystore.PublicKey != "" {
+		return normalizePublicKey(keystore.PublicKey)
+	}
+	keystoreJSON, restErr := service.secretData(types.NamespacedName{Namespace: namespace, Name: keystore.SecretName}, "keystore")
+	if restErr != nil {
+		return ""
+	}
+	return normalizePublicKey(keystorePublicKey(keystoreJSON))
+}
+
+// keystorePublicKey returns the EIP-2335 keystore 0x prefixed public key
+func keystorePublicKey(keystoreJSON string) string {
+	keystore := struct {
+		Pubkey string `json:"pubkey"`
+	}{}
+	json.Unmarshal([]byte(keystoreJSON), &keystore)
+	if keystore.Pubkey == "" {
+		return ""
+	}
+	return "0x" + strings.TrimPrefix(keystore.Pubkey, "0x")
+}
+
+// normalizePublicKey returns the lower case 0x prefixed public key
+func normalizePublicKey(publicKey string) string {
+	publicKey = strings.ToLower(strings.TrimSpace(publicKey))
+	if publicKey == "" {
+		return ""
+	}
+	return "0x" + strings.TrimPrefix(publicKey, "0x")
+}
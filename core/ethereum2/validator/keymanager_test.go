@@ -0,0 +1,278 @@
+package validator
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"github.com/kotalco/core-api/core/secret"
+	restErrors "github.com/kotalco/core-api/pkg/errors"
+	ethereum2v1alpha1 "github.com/kotalco/kotal/apis/ethereum2/v1alpha1"
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	apiErrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"strings"
+	"testing"
+)
+
+/*
+k8s client mocks
+*/
+var (
+	k8sClientGetFunc    func(key client.ObjectKey, obj client.Object) error
+	k8sClientUpdateFunc func(obj client.Object) error
+)
+
+type k8sClientMock struct{}
+
+func (k8sClientMock) Get(ctx context.Context, key client.ObjectKey, obj client.Object, opts ...client.GetOption) error {
+	return k8sClientGetFunc(key, obj)
+}
+func (k8sClientMock) List(ctx context.Context, list client.ObjectList, opts ...client.ListOption) error {
+	return nil
+}
+func (k8sClientMock) Create(ctx context.Context, obj client.Object, opts ...client.CreateOption) error {
+	return nil
+}
+func (k8sClientMock) Delete(ctx context.Context, obj client.Object, opts ...client.DeleteOption) error {
+	return nil
+}
+func (k8sClientMock) Update(ctx context.Context, obj client.Object, opts ...client.UpdateOption) error {
+	return k8sClientUpdateFunc(obj)
+}
+func (k8sClientMock) Patch(ctx context.Context, obj client.Object, patch client.Patch, opts ...client.PatchOption) error {
+	return nil
+}
+func (k8sClientMock) DeleteAllOf(ctx context.Context, obj client.Object, opts ...client.DeleteAllOfOption) error {
+	return nil
+}
+
+/*
+secret service mocks
+*/
+var secretCreateFunc func(dto secret.SecretDto) (corev1.Secret, restErrors.IRestErr)
+
+type secretServiceMock struct {
+	secret.IService
+}
+
+func (secretServiceMock) Create(dto secret.SecretDto) (corev1.Secret, restErrors.IRestErr) {
+	return secretCreateFunc(dto)
+}
+
+const (
+	publicKey1 = "0x93247f2209abcacf57b75a51dafae777f9dd38bc7053d1af526f220a7489a6d3a2753e5f3e8b1cfe39b56f43611df74a"
+	publicKey2 = "0xa99a76ed7796f7be22d5b7e85deeb7c5677e88e511e0b337618f8c4eb61349b4bf2d153f649f7b53359fe8b94a38e44c"
+)
+
+func TestMain(m *testing.M) {
+	k8sClient = &k8sClientMock{}
+	secretService = &secretServiceMock{}
+
+	code := m.Run()
+	os.Exit(code)
+}
+
+// keymanagerServer serves the handler as the keymanager api of a running validator using a token secret
+func keymanagerServer(t *testing.T, handler http.HandlerFunc, secrets map[string]map[string][]byte) (*httptest.Server, ethereum2v1alpha1.Validator) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "Bearer my-token", r.Header.Get("Authorization"))
+		assert.Equal(t, "/eth/v1/keystores", r.URL.Path)
+		handler(w, r)
+	}))
+	_, port, _ := net.SplitHostPort(server.Listener.Addr().String())
+
+	secrets["my-token"] = map[string][]byte{"token": []byte("my-token\n")}
+	k8sClientGetFunc = func(key client.ObjectKey, obj client.Object) error {
+		switch obj := obj.(type) {
+		case *corev1.Pod:
+			obj.Status.PodIP = "127.0.0.1"
+			return nil
+		case *corev1.Secret:
+			data, ok := secrets[key.Name]
+			if !ok {
+				return apiErrors.NewNotFound(schema.GroupResource{}, key.Name)
+			}
+			obj.Data = data
+			return nil
+		}
+		return nil
+	}
+
+	return server, ethereum2v1alpha1.Validator{
+		ObjectMeta: metav1.ObjectMeta{Name: "my-validator", Namespace: "default", Annotations: map[string]string{
+			KeymanagerTokenAnnotation: "my-token",
+			KeymanagerPortAnnotation:  port,
+		}},
+		Spec: ethereum2v1alpha1.ValidatorSpec{
+			Client:    ethereum2v1alpha1.LighthouseClient,
+			Keystores: []ethereum2v1alpha1.Keystore{{PublicKey: publicKey1, SecretName: "keystore-1"}},
+		},
+	}
+}
+
+func keystoreSecret(publicKey string) map[string][]byte {
+	return map[string][]byte{
+		"keystore": []byte(`{"pubkey":"` + strings.TrimPrefix(publicKey, "0x") + `","version":4}`),
+		"password": []byte("secret"),
+	}
+}
+
+func TestRemoveKeysDto_Validate(t *testing.T) {
+	t.Run("validate_should_throw_if_public_keys_are_missing", func(t *testing.T) {
+		err := RemoveKeysDto{}.Validate()
+		assert.EqualValues(t, restErrors.NewValidationError(map[string]string{"publicKeys": "publicKeys must have at least one public key"}), err)
+	})
+	t.Run("validate_should_throw_for_invalid_public_key", func(t *testing.T) {
+		err := RemoveKeysDto{PublicKeys: []string{publicKey1, "0x1234"}}.Validate()
+		assert.EqualValues(t, restErrors.NewValidationError(map[string]string{"publicKeys[1]": "public key must be 48 bytes hex"}), err)
+	})
+}
+
+func TestService_Keys(t *testing.T) {
+	t.Run("keys_should_throw_if_keymanager_is_not_configured", func(t *testing.T) {
+		_, err := NewValidatorService().Keys(ethereum2v1alpha1.Validator{ObjectMeta: metav1.ObjectMeta{Name: "my-validator"}})
+		assert.EqualValues(t, http.StatusBadRequest, err.StatusCode())
+		assert.EqualValues(t, "validator by name my-validator keymanager api isn't configured", err.Error())
+	})
+
+	t.Run("keys_should_list_loaded_keys_with_their_spec_secrets", func(t *testing.T) {
+		server, validator := keymanagerServer(t, func(w http.ResponseWriter, r *http.Request) {
+			assert.Equal(t, http.MethodGet, r.Method)
+			w.Write([]byte(`{"data":[{"validating_pubkey":"` + strings.ToUpper(publicKey1[2:]) + `","readonly":false},{"validating_pubkey":"` + publicKey2 + `","derivation_path":"m/12381/3600/1/0/0","readonly":true}]}`))
+		}, map[string]map[string][]byte{})
+		defer server.Close()
+
+		keys, err := NewValidatorService().Keys(validator)
+		assert.Nil(t, err)
+		assert.Len(t, keys, 2)
+		assert.EqualValues(t, "keystore-1", keys[0].SecretName)
+		assert.EqualValues(t, "", keys[1].SecretName)
+		assert.True(t, keys[1].Readonly)
+	})
+
+	t.Run("keys_should_throw_if_token_is_rejected", func(t *testing.T) {
+		server, validator := keymanagerServer(t, func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusUnauthorized)
+		}, map[string]map[string][]byte{})
+		defer server.Close()
+
+		_, err := NewValidatorService().Keys(validator)
+		assert.EqualValues(t, "validator by name my-validator keymanager api rejected the token", err.Error())
+	})
+}
+
+func TestService_ImportKeys(t *testing.T) {
+	t.Run("import_keys_should_import_keystores_with_slashing_protection_and_add_them_to_spec", func(t *testing.T) {
+		server, validator := keymanagerServer(t, func(w http.ResponseWriter, r *http.Request) {
+			assert.Equal(t, http.MethodPost, r.Method)
+			request := struct {
+				Keystores          []string `json:"keystores"`
+				Passwords          []string `json:"passwords"`
+				SlashingProtection string   `json:"slashing_protection"`
+			}{}
+			assert.Nil(t, json.NewDecoder(r.Body).Decode(&request))
+			assert.Len(t, request.Keystores, 2)
+			assert.EqualValues(t, []string{"secret", "other-secret"}, request.Passwords)
+			assert.EqualValues(t, `{"metadata":{}}`, request.SlashingProtection)
+			w.Write([]byte(`{"data":[{"status":"duplicate"},{"status":"imported"}]}`))
+		}, map[string]map[string][]byte{
+			"keystore-1":          keystoreSecret(publicKey1),
+			"keystore-2":          keystoreSecret(publicKey2),
+			"password-2":          {"password": []byte("other-secret")},
+			"slashing-protection": {"interchange": []byte(`{"metadata":{}}`)},
+		})
+		defer server.Close()
+
+		updated := false
+		k8sClientUpdateFunc = func(obj client.Object) error {
+			updated = true
+			return nil
+		}
+
+		results, err := NewValidatorService().ImportKeys(ImportKeysDto{
+			Keystores:                    []ImportKeystoreDto{{SecretName: "keystore-1"}, {SecretName: "keystore-2", PasswordSecretName: "password-2"}},
+			SlashingProtectionSecretName: "slashing-protection",
+		}, &validator)
+		assert.Nil(t, err)
+		assert.True(t, updated)
+		assert.EqualValues(t, []KeyResultDto{{PublicKey: publicKey1, Status: "duplicate"}, {PublicKey: publicKey2, Status: "imported"}}, results)
+		assert.EqualValues(t, []ethereum2v1alpha1.Keystore{{PublicKey: publicKey1, SecretName: "keystore-1"}, {PublicKey: publicKey2, SecretName: "keystore-2"}}, validator.Spec.Keystores)
+	})
+
+	t.Run("import_keys_should_throw_if_keystore_secret_does_not_exist", func(t *testing.T) {
+		server, validator := keymanagerServer(t, func(w http.ResponseWriter, r *http.Request) {
+			t.Fail()
+		}, map[string]map[string][]byte{})
+		defer server.Close()
+
+		_, err := NewValidatorService().ImportKeys(ImportKeysDto{Keystores: []ImportKeystoreDto{{SecretName: "keystore-2"}}}, &validator)
+		assert.EqualValues(t, http.StatusNotFound, err.StatusCode())
+	})
+}
+
+func TestService_RemoveKeys(t *testing.T) {
+	t.Run("remove_keys_should_throw_if_no_keystore_is_left", func(t *testing.T) {
+		server, validator := keymanagerServer(t, func(w http.ResponseWriter, r *http.Request) {
+			t.Fail()
+		}, map[string]map[string][]byte{})
+		defer server.Close()
+
+		_, err := NewValidatorService().RemoveKeys(RemoveKeysDto{PublicKeys: []string{publicKey1}}, &validator)
+		assert.EqualValues(t, "validator must keep at least one keystore", err.Error())
+	})
+
+	t.Run("remove_keys_should_export_slashing_protection_and_remove_keys_from_spec", func(t *testing.T) {
+		server, validator := keymanagerServer(t, func(w http.ResponseWriter, r *http.Request) {
+			assert.Equal(t, http.MethodDelete, r.Method)
+			w.Write([]byte(`{"data":[{"status":"deleted"}],"slashing_protection":"{\"metadata\":{},\"data\":[]}"}`))
+		}, map[string]map[string][]byte{"keystore-2": keystoreSecret(publicKey2)})
+		defer server.Close()
+		validator.Spec.Keystores = append(validator.Spec.Keystores, ethereum2v1alpha1.Keystore{SecretName: "keystore-2"})
+
+		var exported secret.SecretDto
+		secretCreateFunc = func(dto secret.SecretDto) (corev1.Secret, restErrors.IRestErr) {
+			exported = dto
+			return corev1.Secret{}, nil
+		}
+		k8sClientUpdateFunc = func(obj client.Object) error {
+			return nil
+		}
+
+		removed, err := NewValidatorService().RemoveKeys(RemoveKeysDto{PublicKeys: []string{publicKey2}}, &validator)
+		assert.Nil(t, err)
+		assert.EqualValues(t, []KeyResultDto{{PublicKey: publicKey2, Status: "deleted"}}, removed.Results)
+		assert.EqualValues(t, `{"metadata":{},"data":[]}`, string(removed.SlashingProtection))
+		assert.EqualValues(t, exported.Name, removed.SlashingProtectionSecretName)
+		assert.EqualValues(t, secret.SlashingProtectionType, exported.Type)
+		assert.EqualValues(t, `{"metadata":{},"data":[]}`, exported.Data["interchange"])
+		assert.EqualValues(t, []ethereum2v1alpha1.Keystore{{PublicKey: publicKey1, SecretName: "keystore-1"}}, validator.Spec.Keystores)
+	})
+
+	t.Run("remove_keys_should_return_the_slashing_protection_with_warnings_if_it_can't_be_saved", func(t *testing.T) {
+		server, validator := keymanagerServer(t, func(w http.ResponseWriter, r *http.Request) {
+			w.Write([]byte(`{"data":[{"status":"deleted"}],"slashing_protection":"{\"metadata\":{},\"data\":[]}"}`))
+		}, map[string]map[string][]byte{"keystore-2": keystoreSecret(publicKey2)})
+		defer server.Close()
+		validator.Spec.Keystores = append(validator.Spec.Keystores, ethereum2v1alpha1.Keystore{SecretName: "keystore-2"})
+
+		secretCreateFunc = func(dto secret.SecretDto) (corev1.Secret, restErrors.IRestErr) {
+			return corev1.Secret{}, restErrors.NewInternalServerError("can't create secret")
+		}
+		k8sClientUpdateFunc = func(obj client.Object) error {
+			return errors.New("conflict")
+		}
+
+		removed, err := NewValidatorService().RemoveKeys(RemoveKeysDto{PublicKeys: []string{publicKey2}}, &validator)
+		assert.Nil(t, err)
+		assert.EqualValues(t, `{"metadata":{},"data":[]}`, string(removed.SlashingProtection))
+		assert.Empty(t, removed.SlashingProtectionSecretName)
+		assert.Len(t, removed.Warnings, 2)
+	})
+}
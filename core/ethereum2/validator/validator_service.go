@@ -3,6 +3,7 @@ package validator
 import (
 	"context"
 	"fmt"
+	"github.com/kotalco/core-api/core/secret"
 	"github.com/kotalco/core-api/k8s"
 	restErrors "github.com/kotalco/core-api/pkg/errors"
 	"github.com/kotalco/core-api/pkg/logger"
@@ -24,10 +25,21 @@ type IService interface {
 	List(namespace string) (ethereum2v1alpha1.ValidatorList, restErrors.IRestErr)
 	Delete(*ethereum2v1alpha1.Validator) restErrors.IRestErr
 	Count(namespace string) (int, restErrors.IRestErr)
+	// UpdateKeymanager saves the validator client keymanager api port and token secret
+	UpdateKeymanager(KeymanagerDto, *ethereum2v1alpha1.Validator) restErrors.IRestErr
+	// Keys lists the public keys loaded by the validator client
+	Keys(ethereum2v1alpha1.Validator) ([]KeyDto, restErrors.IRestErr)
+	// ImportKeys imports EIP-2335 keystores into the validator client and adds them to its spec
+	ImportKeys(ImportKeysDto, *ethereum2v1alpha1.Validator) ([]KeyResultDto, restErrors.IRestErr)
+	// RemoveKeys removes keys from the validator client and its spec, exporting their slashing protection interchange
+	RemoveKeys(RemoveKeysDto, *ethereum2v1alpha1.Validator) (RemovedKeysDto, restErrors.IRestErr)
+	// PublicKeys returns the public keys of the validator spec keystores
+	PublicKeys(ethereum2v1alpha1.Validator) []string
 }
 
 var (
-	k8sClient = k8s.NewClientService()
+	k8sClient     = k8s.NewClientService()
+	secretService = secret.NewSecretService()
 )
 
 func NewValidatorService() IService {
@@ -63,6 +75,7 @@ func (service validatorService) Create(dto ValidatorDto) (validator ethereum2v1a
 	}
 
 	k8s.DefaultResources(&validator.Spec.Resources)
+	k8s.RequestedStorage(&validator.Spec.Resources, dto.Resources)
 
 	if dto.Client == string(ethereum2v1alpha1.PrysmClient) && dto.WalletPasswordSecretName != "" {
 		validator.Spec.WalletPasswordSecret = dto.WalletPasswordSecretName
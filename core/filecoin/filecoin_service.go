@@ -62,6 +62,7 @@ func (service filecoinService) Create(dto FilecoinDto) (node filecoinv1alpha1.No
 	}
 
 	k8s.DefaultResources(&node.Spec.Resources)
+	k8s.RequestedStorage(&node.Spec.Resources, dto.Resources)
 
 	if os.Getenv("MOCK") == "true" {
 		node.Default()
@@ -0,0 +1,212 @@
+package ipfs_cluster
+
+import (
+	"fmt"
+	"github.com/kotalco/core-api/k8s"
+	restErrors "github.com/kotalco/core-api/pkg/errors"
+	"github.com/kotalco/core-api/pkg/time"
+	ipfsv1alpha1 "github.com/kotalco/kotal/apis/ipfs/v1alpha1"
+	sharedAPI "github.com/kotalco/kotal/apis/shared"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"strconv"
+)
+
+const (
+	// ClusterLabel groups the ipfs peers and cluster peers of a cluster, holds the cluster name
+	ClusterLabel = "kotal.io/ipfs-cluster"
+	// MemberLabel holds the index of the cluster member the ipfs peer or cluster peer belongs to
+	MemberLabel = "kotal.io/ipfs-cluster-member"
+)
+
+// MaxSize is the max number of members of a cluster
+const MaxSize = 20
+
+// the cluster swarm port the cluster peers bootstrap to
+const clusterSwarmPort = 9096
+
+// Cluster is ipfs peers paired with cluster peers, a member half is nil if it was deleted on its own
+type Cluster struct {
+	Name    string
+	Members []Member
+}
+
+// Member is an ipfs peer and the cluster peer using it
+type Member struct {
+	Index       int
+	Peer        *ipfsv1alpha1.Peer
+	ClusterPeer *ipfsv1alpha1.ClusterPeer
+}
+
+// ClusterDto is the ipfs cluster members spec and their identities
+type ClusterDto struct {
+	time.Time
+	k8s.MetaDataDto
+	Size              uint          `json:"size"`
+	Consensus         string        `json:"consensus"`
+	ClusterSecretName string        `json:"clusterSecretName"`
+	Peer              MemberSpecDto `json:"peer"`
+	ClusterPeer       MemberSpecDto `json:"clusterPeer"`
+	Members           []MemberDto   `json:"members"`
+}
+
+// MemberSpecDto is the image and resources of the cluster ipfs peers or cluster peers, only the storage is taken from the resources on creation
+type MemberSpecDto struct {
+	Image string `json:"image"`
+	sharedAPI.Resources
+}
+
+// MemberDto is a cluster member ipfs peer and cluster peer names with the cluster peer id
+type MemberDto struct {
+	Index       int    `json:"index"`
+	Peer        string `json:"peer"`
+	ClusterPeer string `json:"clusterPeer"`
+	ID          string `json:"id"`
+}
+
+// MemberStatusDto is a cluster member connectivity and pins count
+// connectedMembers is the count of the other members in the cluster peer peerset
+type MemberStatusDto struct {
+	MemberDto
+	IPFSPeerID       string `json:"ipfsPeerId"`
+	IPFSConnected    bool   `json:"ipfsConnected"`
+	ConnectedMembers int    `json:"connectedMembers"`
+	PinCount         int    `json:"pinCount"`
+	Error            string `json:"error,omitempty"`
+}
+
+// ScaleDto is the cluster members count
+type ScaleDto struct {
+	Size uint `json:"size"`
+}
+
+type ClusterListDto []ClusterDto
+
+// Validate validates the cluster members names, the cluster size and the consensus algorithm
+func (dto ClusterDto) Validate() restErrors.IRestErr {
+	// the longest generated name is the identity secret of the last member
+	meta := k8s.MetaDataDto{Name: identitySecretName(dto.Name, MaxSize-1)}
+	if err := meta.Validate(); err != nil {
+		return err
+	}
+
+	fields := map[string]string{}
+	if err := (ScaleDto{Size: dto.Size}).Validate(); err != nil {
+		fields["size"] = fmt.Sprintf("size must be between 1 and %d", MaxSize)
+	}
+	switch ipfsv1alpha1.ConsensusAlgorithm(dto.Consensus) {
+	case "", ipfsv1alpha1.CRDT, ipfsv1alpha1.Raft:
+	default:
+		fields["consensus"] = "consensus must be one of crdt or raft"
+	}
+	if len(fields) > 0 {
+		return restErrors.NewValidationError(fields)
+	}
+	return nil
+}
+
+// Validate validates the cluster size
+func (dto ScaleDto) Validate() restErrors.IRestErr {
+	if dto.Size < 1 || dto.Size > MaxSize {
+		return restErrors.NewValidationError(map[string]string{"size": fmt.Sprintf("size must be between 1 and %d", MaxSize)})
+	}
+	return nil
+}
+
+// FromCluster returns the dto of the cluster, the first member spec is used for the common fields
+func (dto ClusterDto) FromCluster(cluster Cluster) ClusterDto {
+	dto.Name = cluster.Name
+	dto.Size = uint(len(cluster.Members))
+	dto.Members = make([]MemberDto, 0, len(cluster.Members))
+
+	for i, member := range cluster.Members {
+		memberDto := MemberDto{Index: member.Index}
+		if peer := member.Peer; peer != nil {
+			memberDto.Peer = peer.Name
+			if i == 0 {
+				dto.Time = time.Time{CreatedAt: peer.CreationTimestamp.UTC().Format(time.JavascriptISOString)}
+				dto.Peer = MemberSpecDto{Image: peer.Spec.Image, Resources: peer.Spec.Resources}
+			}
+		}
+		if clusterPeer := member.ClusterPeer; clusterPeer != nil {
+			memberDto.ClusterPeer = clusterPeer.Name
+			memberDto.ID = clusterPeer.Spec.ID
+			if i == 0 {
+				dto.Consensus = string(clusterPeer.Spec.Consensus)
+				dto.ClusterSecretName = clusterPeer.Spec.ClusterSecretName
+				dto.ClusterPeer = MemberSpecDto{Image: clusterPeer.Spec.Image, Resources: clusterPeer.Spec.Resources}
+			}
+		}
+		dto.Members = append(dto.Members, memberDto)
+	}
+
+	return dto
+}
+
+func (clusters ClusterListDto) FromCluster(list []Cluster) ClusterListDto {
+	result := make(ClusterListDto, len(list))
+	for index, v := range list {
+		result[index] = ClusterDto{}.FromCluster(v)
+	}
+	return result
+}
+
+// createdAt returns the creation time of the first member
+func (cluster Cluster) createdAt() *metav1.Time {
+	member := cluster.Members[0]
+	if member.Peer != nil {
+		return &member.Peer.CreationTimestamp
+	}
+	return &member.ClusterPeer.CreationTimestamp
+}
+
+// namespace returns the cluster workspace namespace
+func (cluster Cluster) namespace() string {
+	member := cluster.Members[0]
+	if member.Peer != nil {
+		return member.Peer.Namespace
+	}
+	return member.ClusterPeer.Namespace
+}
+
+// ids returns the cluster peers ids of the members
+func (cluster Cluster) ids() []string {
+	ids := []string{}
+	for _, member := range cluster.Members {
+		if member.ClusterPeer != nil && member.ClusterPeer.Spec.ID != "" {
+			ids = append(ids, member.ClusterPeer.Spec.ID)
+		}
+	}
+	return ids
+}
+
+// the ipfs peers, cluster peers and secrets are named after the cluster and the member index
+func peerName(cluster string, index int) string {
+	return fmt.Sprintf("%s-peer-%d", cluster, index)
+}
+
+func clusterPeerName(cluster string, index int) string {
+	return fmt.Sprintf("%s-cluster-peer-%d", cluster, index)
+}
+
+func identitySecretName(cluster string, index int) string {
+	return clusterPeerName(cluster, index) + "-identity"
+}
+
+func clusterSecretName(cluster string) string {
+	return cluster + "-cluster-secret"
+}
+
+// memberLabels are the labels of the member ipfs peer and cluster peer
+func memberLabels(cluster string, index int) map[string]string {
+	return map[string]string{ClusterLabel: cluster, MemberLabel: strconv.Itoa(index)}
+}
+
+// peerEndpoint is the ipfs peer api endpoint the member cluster peer uses
+func peerEndpoint(cluster string, index int) string {
+	return fmt.Sprintf("/dns4/%s/tcp/%d", peerName(cluster, index), ipfsv1alpha1.DefaultAPIPort)
+}
+
+// bootstrapPeer is the multiaddress of the member cluster peer swarm
+func bootstrapPeer(cluster string, index int, id string) string {
+	return fmt.Sprintf("/dns4/%s/tcp/%d/p2p/%s", clusterPeerName(cluster, index), clusterSwarmPort, id)
+}
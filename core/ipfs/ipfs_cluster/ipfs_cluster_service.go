@@ -0,0 +1,463 @@
+// Package ipfs_cluster internal is the domain layer for the ipfs clusters
+// uses the ipfs peer, cluster peer and secret services to provision the cluster members and their identities together
+package ipfs_cluster
+
+import (
+	"fmt"
+	"github.com/kotalco/core-api/core/ipfs/ipfs_cluster_peer"
+	"github.com/kotalco/core-api/core/ipfs/ipfs_peer"
+	"github.com/kotalco/core-api/core/secret"
+	"github.com/kotalco/core-api/k8s"
+	restErrors "github.com/kotalco/core-api/pkg/errors"
+	"github.com/kotalco/core-api/pkg/logger"
+	ipfsv1alpha1 "github.com/kotalco/kotal/apis/ipfs/v1alpha1"
+	"k8s.io/apimachinery/pkg/types"
+	"net/http"
+	"sort"
+	"strconv"
+	"sync"
+)
+
+type ipfsClusterService struct{}
+
+type IService interface {
+	// Get returns the cluster by name, not found if none of its members exist
+	Get(types.NamespacedName) (Cluster, restErrors.IRestErr)
+	// Create creates the cluster secret, the members identities, ipfs peers and cluster peers, the created ones are deleted if any of them can't be created
+	Create(ClusterDto) (Cluster, restErrors.IRestErr)
+	// List returns the workspace clusters, newest first
+	List(namespace string) ([]Cluster, restErrors.IRestErr)
+	// Scale adds or removes members until the cluster has size members, the trusted peers of the remaining members are updated
+	Scale(cluster Cluster, size uint) (Cluster, restErrors.IRestErr)
+	// Delete deletes the cluster peers, the ipfs peers, the members identities and the cluster secret
+	Delete(Cluster) restErrors.IRestErr
+	// Topology returns every member identity, connectivity and pins count
+	Topology(Cluster) []MemberStatusDto
+}
+
+var (
+	ipfsPeerService        = ipfs_peer.NewIpfsPeerService()
+	ipfsClusterPeerService = ipfs_cluster_peer.NewIpfsClusterPeerService()
+	secretService          = secret.NewSecretService()
+)
+
+func NewService() IService {
+	return ipfsClusterService{}
+}
+
+// identity is a generated cluster peer private key secret and its peer id
+type identity struct {
+	index      int
+	secretName string
+	id         string
+}
+
+// Get returns the cluster by name
+func (service ipfsClusterService) Get(key types.NamespacedName) (cluster Cluster, restErr restErrors.IRestErr) {
+	clusters, restErr := service.List(key.Namespace)
+	if restErr != nil {
+		return
+	}
+	for _, v := range clusters {
+		if v.Name == key.Name {
+			return v, nil
+		}
+	}
+	restErr = restErrors.NewNotFoundError(fmt.Sprintf("cluster by name %s doesn't exist", key.Name))
+	return
+}
+
+// Create generates the cluster secret and all the members identities first so every cluster peer trusts all the members
+// every member bootstraps to the members created before it
+func (service ipfsClusterService) Create(dto ClusterDto) (cluster Cluster, restErr restErrors.IRestErr) {
+	secretName := clusterSecretName(dto.Name)
+	_, restErr = secretService.Generate(secret.GenerateSecretDto{
+		MetaDataDto: k8s.MetaDataDto{Name: secretName, Namespace: dto.Namespace, Labels: map[string]string{ClusterLabel: dto.Name}},
+		Type:        secret.IPFSClusterSecretGenerator,
+	})
+	if restErr != nil {
+		return
+	}
+
+	secrets := []string{secretName}
+	identities, restErr := service.generateIdentities(dto.Name, dto.Namespace, 0, int(dto.Size))
+	for _, v := range identities {
+		secrets = append(secrets, v.secretName)
+	}
+	if restErr != nil {
+		service.rollback(dto.Namespace, nil, secrets)
+		return
+	}
+
+	if dto.Consensus == "" {
+		dto.Consensus = string(ipfsv1alpha1.DefaultIPFSClusterConsensus)
+	}
+	dto.ClusterSecretName = secretName
+	trustedPeers := idsOf(identities)
+
+	cluster.Name = dto.Name
+	for _, v := range identities {
+		var member Member
+		member, restErr = service.createMember(dto, v, trustedPeers, bootstrapPeers(dto.Name, identities[:v.index]))
+		if member.Peer != nil || member.ClusterPeer != nil {
+			cluster.Members = append(cluster.Members, member)
+		}
+		if restErr != nil {
+			service.rollback(dto.Namespace, cluster.Members, secrets)
+			return Cluster{}, restErr
+		}
+	}
+
+	return
+}
+
+// generateIdentities generates the libp2p private keys of the members with indices from start to end
+func (service ipfsClusterService) generateIdentities(name string, namespace string, start int, end int) ([]identity, restErrors.IRestErr) {
+	identities := make([]identity, 0, end-start)
+	for i := start; i < end; i++ {
+		secretName := identitySecretName(name, i)
+		generated, restErr := secretService.Generate(secret.GenerateSecretDto{
+			MetaDataDto: k8s.MetaDataDto{Name: secretName, Namespace: namespace, Labels: memberLabels(name, i)},
+			Type:        secret.Ed25519Generator,
+			Format:      secret.Libp2pFormat,
+		})
+		if restErr != nil {
+			return identities, restErr
+		}
+		identities = append(identities, identity{index: i, secretName: secretName, id: generated.Public["peerId"]})
+	}
+	return identities, nil
+}
+
+// createMember creates the member ipfs peer and the cluster peer using its api
+func (service ipfsClusterService) createMember(dto ClusterDto, member identity, trustedPeers []string, bootstrapPeers []string) (result Member, restErr restErrors.IRestErr) {
+	result.Index = member.index
+	labels := memberLabels(dto.Name, member.index)
+
+	peer, restErr := ipfsPeerService.Create(ipfs_peer.PeerDto{
+		MetaDataDto: k8s.MetaDataDto{Name: peerName(dto.Name, member.index), Namespace: dto.Namespace, Labels: labels},
+		Image:       dto.Peer.Image,
+		Resources:   dto.Peer.Resources,
+	})
+	if restErr != nil {
+		return
+	}
+	result.Peer = &peer
+
+	clusterPeer, restErr := ipfsClusterPeerService.Create(ipfs_cluster_peer.ClusterPeerDto{
+		MetaDataDto:          k8s.MetaDataDto{Name: clusterPeerName(dto.Name, member.index), Namespace: dto.Namespace, Labels: labels},
+		ID:                   member.id,
+		PrivatekeySecretName: member.secretName,
+		TrustedPeers:         trustedPeers,
+		BootstrapPeers:       bootstrapPeers,
+		Consensus:            dto.Consensus,
+		ClusterSecretName:    dto.ClusterSecretName,
+		PeerEndpoint:         peerEndpoint(dto.Name, member.index),
+		Image:                dto.ClusterPeer.Image,
+		Resources:            dto.ClusterPeer.Resources,
+	})
+	if restErr != nil {
+		return
+	}
+	result.ClusterPeer = &clusterPeer
+	return
+}
+
+// rollback deletes the members and the secrets created before the cluster creation or scaling failed
+func (service ipfsClusterService) rollback(namespace string, members []Member, secrets []string) {
+	for _, member := range members {
+		if restErr := service.deleteMember(member); restErr != nil {
+			go logger.Warn(service.rollback, restErr)
+		}
+	}
+	for _, name := range secrets {
+		if restErr := service.deleteSecret(namespace, name); restErr != nil {
+			go logger.Warn(service.rollback, restErr)
+		}
+	}
+}
+
+// List groups the ipfs peers and cluster peers by the cluster label and pairs them by the member label
+func (service ipfsClusterService) List(namespace string) ([]Cluster, restErrors.IRestErr) {
+	peers, restErr := ipfsPeerService.List(namespace)
+	if restErr != nil {
+		return nil, restErr
+	}
+	clusterPeers, restErr := ipfsClusterPeerService.List(namespace)
+	if restErr != nil {
+		return nil, restErr
+	}
+
+	members := map[string]map[int]*Member{}
+	pair := func(labels map[string]string) *Member {
+		name := labels[ClusterLabel]
+		index, err := strconv.Atoi(labels[MemberLabel])
+		if name == "" || err != nil {
+			return nil
+		}
+		if members[name] == nil {
+			members[name] = map[int]*Member{}
+		}
+		if members[name][index] == nil {
+			members[name][index] = &Member{Index: index}
+		}
+		return members[name][index]
+	}
+	for i := range peers.Items {
+		if member := pair(peers.Items[i].Labels); member != nil {
+			member.Peer = &peers.Items[i]
+		}
+	}
+	for i := range clusterPeers.Items {
+		if member := pair(clusterPeers.Items[i].Labels); member != nil {
+			member.ClusterPeer = &clusterPeers.Items[i]
+		}
+	}
+
+	result := make([]Cluster, 0, len(members))
+	for name, v := range members {
+		cluster := Cluster{Name: name}
+		for _, member := range v {
+			cluster.Members = append(cluster.Members, *member)
+		}
+		sort.Slice(cluster.Members, func(i, j int) bool {
+			return cluster.Members[i].Index < cluster.Members[j].Index
+		})
+		result = append(result, cluster)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[j].createdAt().Before(result[i].createdAt())
+	})
+	return result, nil
+}
+
+// Scale adds members after the last member or removes the last members
+func (service ipfsClusterService) Scale(cluster Cluster, size uint) (Cluster, restErrors.IRestErr) {
+	current := len(cluster.Members)
+	switch {
+	case int(size) > current:
+		return service.scaleUp(cluster, int(size)-current)
+	case int(size) < current:
+		return service.scaleDown(cluster, current-int(size))
+	}
+	return cluster, nil
+}
+
+// scaleUp creates the new members bootstrapping to the existing ones, then the existing members trust the new ones
+// the new members use the spec of the first member
+func (service ipfsClusterService) scaleUp(cluster Cluster, count int) (Cluster, restErrors.IRestErr) {
+	first := cluster.Members[0]
+	if first.ClusterPeer == nil {
+		return cluster, restErrors.NewBadRequestError(fmt.Sprintf("cluster peer of cluster %s member %d doesn't exist", cluster.Name, first.Index))
+	}
+	namespace := cluster.namespace()
+	dto := ClusterDto{}.FromCluster(cluster)
+	dto.Namespace = namespace
+
+	start := cluster.Members[len(cluster.Members)-1].Index + 1
+	identities, restErr := service.generateIdentities(cluster.Name, namespace, start, start+count)
+	secrets := []string{}
+	for _, v := range identities {
+		secrets = append(secrets, v.secretName)
+	}
+	if restErr != nil {
+		service.rollback(namespace, nil, secrets)
+		return cluster, restErr
+	}
+
+	existing := []identity{}
+	for _, member := range cluster.Members {
+		if member.ClusterPeer != nil && member.ClusterPeer.Spec.ID != "" {
+			existing = append(existing, identity{index: member.Index, id: member.ClusterPeer.Spec.ID})
+		}
+	}
+	trustedPeers := append(cluster.ids(), idsOf(identities)...)
+	bootstrap := bootstrapPeers(cluster.Name, existing)
+
+	created := []Member{}
+	for _, v := range identities {
+		member, restErr := service.createMember(dto, v, trustedPeers, bootstrap)
+		if member.Peer != nil || member.ClusterPeer != nil {
+			created = append(created, member)
+		}
+		if restErr != nil {
+			service.rollback(namespace, created, secrets)
+			return cluster, restErr
+		}
+	}
+
+	cluster.Members = append(cluster.Members, created...)
+	return cluster, service.trust(cluster, trustedPeers)
+}
+
+// scaleDown deletes the last members, then the remaining members stop trusting them
+func (service ipfsClusterService) scaleDown(cluster Cluster, count int) (Cluster, restErrors.IRestErr) {
+	namespace := cluster.namespace()
+	remaining := cluster.Members[:len(cluster.Members)-count]
+	for _, member := range cluster.Members[len(remaining):] {
+		if restErr := service.deleteMember(member); restErr != nil {
+			return cluster, restErr
+		}
+		if restErr := service.deleteSecret(namespace, memberIdentitySecretName(cluster.Name, member)); restErr != nil {
+			return cluster, restErr
+		}
+	}
+
+	cluster.Members = remaining
+	return cluster, service.trust(cluster, cluster.ids())
+}
+
+// trust sets the trusted peers of the members cluster peers
+func (service ipfsClusterService) trust(cluster Cluster, trustedPeers []string) restErrors.IRestErr {
+	for _, member := range cluster.Members {
+		if member.ClusterPeer == nil {
+			continue
+		}
+		if restErr := ipfsClusterPeerService.Update(ipfs_cluster_peer.ClusterPeerDto{TrustedPeers: trustedPeers}, member.ClusterPeer); restErr != nil {
+			return restErr
+		}
+	}
+	return nil
+}
+
+// Delete deletes every member cluster peer before its ipfs peer so it doesn't lose its ipfs peer while running
+func (service ipfsClusterService) Delete(cluster Cluster) restErrors.IRestErr {
+	namespace := cluster.namespace()
+	secretName := clusterSecretName(cluster.Name)
+	for _, member := range cluster.Members {
+		if member.ClusterPeer != nil && member.ClusterPeer.Spec.ClusterSecretName != "" {
+			secretName = member.ClusterPeer.Spec.ClusterSecretName
+		}
+		if restErr := service.deleteMember(member); restErr != nil {
+			return restErr
+		}
+		if restErr := service.deleteSecret(namespace, memberIdentitySecretName(cluster.Name, member)); restErr != nil {
+			return restErr
+		}
+	}
+	return service.deleteSecret(namespace, secretName)
+}
+
+// deleteMember deletes the member cluster peer then its ipfs peer
+func (service ipfsClusterService) deleteMember(member Member) restErrors.IRestErr {
+	if member.ClusterPeer != nil {
+		if restErr := ipfsClusterPeerService.Delete(member.ClusterPeer); restErr != nil {
+			return restErr
+		}
+	}
+	if member.Peer != nil {
+		if restErr := ipfsPeerService.Delete(member.Peer); restErr != nil {
+			return restErr
+		}
+	}
+	return nil
+}
+
+// deleteSecret deletes the secret by name, secrets deleted on their own are skipped
+func (service ipfsClusterService) deleteSecret(namespace string, name string) restErrors.IRestErr {
+	model, restErr := secretService.Get(types.NamespacedName{Namespace: namespace, Name: name})
+	if restErr != nil {
+		if restErr.StatusCode() == http.StatusNotFound {
+			return nil
+		}
+		return restErr
+	}
+	return secretService.Delete(&model)
+}
+
+// Topology calls every member cluster peer and ipfs peer at the same time, a member error is reported without failing the other members
+func (service ipfsClusterService) Topology(cluster Cluster) []MemberStatusDto {
+	dto := ClusterDto{}.FromCluster(cluster)
+	members := map[string]bool{}
+	for _, id := range cluster.ids() {
+		members[id] = true
+	}
+
+	statuses := make([]MemberStatusDto, len(cluster.Members))
+	var wg sync.WaitGroup
+	wg.Add(len(cluster.Members))
+	for i := range cluster.Members {
+		go func(i int) {
+			defer wg.Done()
+			statuses[i] = service.memberStatus(cluster.Members[i], dto.Members[i], members)
+		}(i)
+	}
+	wg.Wait()
+	return statuses
+}
+
+// memberStatus returns the member cluster peer identity, the other members in its peerset and its ipfs peer pins count
+func (service ipfsClusterService) memberStatus(member Member, memberDto MemberDto, members map[string]bool) (status MemberStatusDto) {
+	status.MemberDto = memberDto
+	if member.ClusterPeer == nil {
+		status.Error = fmt.Sprintf("cluster peer of member %d doesn't exist", member.Index)
+		return
+	}
+	if member.Peer == nil {
+		status.Error = fmt.Sprintf("peer of member %d doesn't exist", member.Index)
+		return
+	}
+
+	var id ipfs_cluster_peer.IDDto
+	var pins []ipfs_peer.PinDto
+	var idErr, pinsErr restErrors.IRestErr
+	var wg sync.WaitGroup
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		id, idErr = ipfsClusterPeerService.ID(*member.ClusterPeer)
+	}()
+	go func() {
+		defer wg.Done()
+		pins, pinsErr = ipfsPeerService.Pins(*member.Peer)
+	}()
+	wg.Wait()
+
+	if idErr != nil {
+		status.Error = idErr.Error()
+		return
+	}
+	status.IPFSPeerID = id.IPFSPeerID
+	status.IPFSConnected = id.IPFSPeerID != "" && id.IPFSError == ""
+	for _, peer := range id.ClusterPeers {
+		if peer != id.ID && members[peer] {
+			status.ConnectedMembers++
+		}
+	}
+	if id.Error != "" {
+		status.Error = id.Error
+	}
+
+	if pinsErr != nil {
+		status.Error = pinsErr.Error()
+		return
+	}
+	status.PinCount = len(pins)
+	return
+}
+
+// memberIdentitySecretName returns the member cluster peer private key secret name, the generated name if the cluster peer was deleted
+func memberIdentitySecretName(cluster string, member Member) string {
+	if member.ClusterPeer != nil && member.ClusterPeer.Spec.PrivateKeySecretName != "" {
+		return member.ClusterPeer.Spec.PrivateKeySecretName
+	}
+	return identitySecretName(cluster, member.Index)
+}
+
+// idsOf returns the peer ids of the identities
+func idsOf(identities []identity) []string {
+	ids := make([]string, 0, len(identities))
+	for _, v := range identities {
+		ids = append(ids, v.id)
+	}
+	return ids
+}
+
+// bootstrapPeers returns the swarm multiaddresses of the members cluster peers
+func bootstrapPeers(cluster string, identities []identity) []string {
+	peers := make([]string, 0, len(identities))
+	for _, v := range identities {
+		peers = append(peers, bootstrapPeer(cluster, v.index, v.id))
+	}
+	return peers
+}
@@ -0,0 +1,423 @@
+package ipfs_cluster
+
+import (
+	"fmt"
+	"github.com/kotalco/core-api/core/ipfs/ipfs_cluster_peer"
+	"github.com/kotalco/core-api/core/ipfs/ipfs_peer"
+	"github.com/kotalco/core-api/core/secret"
+	"github.com/kotalco/core-api/k8s"
+	restErrors "github.com/kotalco/core-api/pkg/errors"
+	ipfsv1alpha1 "github.com/kotalco/kotal/apis/ipfs/v1alpha1"
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"net/http"
+	"os"
+	"testing"
+)
+
+/*
+ipfs peer service mocks
+*/
+var (
+	peerCreateFunc func(dto ipfs_peer.PeerDto) (ipfsv1alpha1.Peer, restErrors.IRestErr)
+	peerListFunc   func(namespace string) (ipfsv1alpha1.PeerList, restErrors.IRestErr)
+	peerDeleteFunc func(peer *ipfsv1alpha1.Peer) restErrors.IRestErr
+	peerPinsFunc   func(peer ipfsv1alpha1.Peer) ([]ipfs_peer.PinDto, restErrors.IRestErr)
+)
+
+type ipfsPeerServiceMock struct {
+	ipfs_peer.IService
+}
+
+func (ipfsPeerServiceMock) Create(dto ipfs_peer.PeerDto) (ipfsv1alpha1.Peer, restErrors.IRestErr) {
+	return peerCreateFunc(dto)
+}
+func (ipfsPeerServiceMock) List(namespace string) (ipfsv1alpha1.PeerList, restErrors.IRestErr) {
+	return peerListFunc(namespace)
+}
+func (ipfsPeerServiceMock) Delete(peer *ipfsv1alpha1.Peer) restErrors.IRestErr {
+	return peerDeleteFunc(peer)
+}
+func (ipfsPeerServiceMock) Pins(peer ipfsv1alpha1.Peer) ([]ipfs_peer.PinDto, restErrors.IRestErr) {
+	return peerPinsFunc(peer)
+}
+
+/*
+ipfs cluster peer service mocks
+*/
+var (
+	clusterPeerCreateFunc func(dto ipfs_cluster_peer.ClusterPeerDto) (ipfsv1alpha1.ClusterPeer, restErrors.IRestErr)
+	clusterPeerUpdateFunc func(dto ipfs_cluster_peer.ClusterPeerDto, peer *ipfsv1alpha1.ClusterPeer) restErrors.IRestErr
+	clusterPeerListFunc   func(namespace string) (ipfsv1alpha1.ClusterPeerList, restErrors.IRestErr)
+	clusterPeerDeleteFunc func(peer *ipfsv1alpha1.ClusterPeer) restErrors.IRestErr
+	clusterPeerIDFunc     func(peer ipfsv1alpha1.ClusterPeer) (ipfs_cluster_peer.IDDto, restErrors.IRestErr)
+)
+
+type ipfsClusterPeerServiceMock struct {
+	ipfs_cluster_peer.IService
+}
+
+func (ipfsClusterPeerServiceMock) Create(dto ipfs_cluster_peer.ClusterPeerDto) (ipfsv1alpha1.ClusterPeer, restErrors.IRestErr) {
+	return clusterPeerCreateFunc(dto)
+}
+func (ipfsClusterPeerServiceMock) Update(dto ipfs_cluster_peer.ClusterPeerDto, peer *ipfsv1alpha1.ClusterPeer) restErrors.IRestErr {
+	return clusterPeerUpdateFunc(dto, peer)
+}
+func (ipfsClusterPeerServiceMock) List(namespace string) (ipfsv1alpha1.ClusterPeerList, restErrors.IRestErr) {
+	return clusterPeerListFunc(namespace)
+}
+func (ipfsClusterPeerServiceMock) Delete(peer *ipfsv1alpha1.ClusterPeer) restErrors.IRestErr {
+	return clusterPeerDeleteFunc(peer)
+}
+func (ipfsClusterPeerServiceMock) ID(peer ipfsv1alpha1.ClusterPeer) (ipfs_cluster_peer.IDDto, restErrors.IRestErr) {
+	return clusterPeerIDFunc(peer)
+}
+
+/*
+secret service mocks
+*/
+var (
+	secretGenerateFunc func(dto secret.GenerateSecretDto) (secret.GeneratedSecretDto, restErrors.IRestErr)
+	secretGetFunc      func(key types.NamespacedName) (corev1.Secret, restErrors.IRestErr)
+	secretDeleteFunc   func(model *corev1.Secret) restErrors.IRestErr
+)
+
+type secretServiceMock struct {
+	secret.IService
+}
+
+func (secretServiceMock) Generate(dto secret.GenerateSecretDto) (secret.GeneratedSecretDto, restErrors.IRestErr) {
+	return secretGenerateFunc(dto)
+}
+func (secretServiceMock) Get(key types.NamespacedName) (corev1.Secret, restErrors.IRestErr) {
+	return secretGetFunc(key)
+}
+func (secretServiceMock) Delete(model *corev1.Secret) restErrors.IRestErr {
+	return secretDeleteFunc(model)
+}
+
+var service IService
+
+func TestMain(m *testing.M) {
+	ipfsPeerService = &ipfsPeerServiceMock{}
+	ipfsClusterPeerService = &ipfsClusterPeerServiceMock{}
+	secretService = &secretServiceMock{}
+	service = NewService()
+
+	code := m.Run()
+	os.Exit(code)
+}
+
+func validDto() ClusterDto {
+	return ClusterDto{
+		MetaDataDto: k8s.MetaDataDto{Name: "storage", Namespace: "default"},
+		Size:        3,
+	}
+}
+
+// generatedIdentities generates the secrets with peer ids named after the secret
+func generatedIdentities() {
+	secretGenerateFunc = func(dto secret.GenerateSecretDto) (secret.GeneratedSecretDto, restErrors.IRestErr) {
+		return secret.GeneratedSecretDto{Public: map[string]string{"peerId": "id-of-" + dto.Name}}, nil
+	}
+}
+
+func createdMembers() {
+	peerCreateFunc = func(dto ipfs_peer.PeerDto) (ipfsv1alpha1.Peer, restErrors.IRestErr) {
+		return ipfsv1alpha1.Peer{ObjectMeta: metav1.ObjectMeta{Name: dto.Name, Namespace: dto.Namespace, Labels: dto.Labels}}, nil
+	}
+	clusterPeerCreateFunc = func(dto ipfs_cluster_peer.ClusterPeerDto) (ipfsv1alpha1.ClusterPeer, restErrors.IRestErr) {
+		peer := ipfsv1alpha1.ClusterPeer{ObjectMeta: metav1.ObjectMeta{Name: dto.Name, Namespace: dto.Namespace, Labels: dto.Labels}}
+		peer.Spec.ID = dto.ID
+		peer.Spec.PrivateKeySecretName = dto.PrivatekeySecretName
+		peer.Spec.ClusterSecretName = dto.ClusterSecretName
+		peer.Spec.Consensus = ipfsv1alpha1.ConsensusAlgorithm(dto.Consensus)
+		return peer, nil
+	}
+}
+
+// cluster returns the cluster members as created by the service
+func cluster(name string, size int) Cluster {
+	result := Cluster{Name: name}
+	for i := 0; i < size; i++ {
+		peer := ipfsv1alpha1.Peer{ObjectMeta: metav1.ObjectMeta{Name: peerName(name, i), Namespace: "default", Labels: memberLabels(name, i)}}
+		clusterPeer := ipfsv1alpha1.ClusterPeer{ObjectMeta: metav1.ObjectMeta{Name: clusterPeerName(name, i), Namespace: "default", Labels: memberLabels(name, i)}}
+		clusterPeer.Spec.ID = fmt.Sprintf("id-%d", i)
+		clusterPeer.Spec.PrivateKeySecretName = identitySecretName(name, i)
+		clusterPeer.Spec.ClusterSecretName = clusterSecretName(name)
+		result.Members = append(result.Members, Member{Index: i, Peer: &peer, ClusterPeer: &clusterPeer})
+	}
+	return result
+}
+
+func TestClusterDto_Validate(t *testing.T) {
+	t.Run("validate_should_pass", func(t *testing.T) {
+		assert.Nil(t, validDto().Validate())
+	})
+
+	t.Run("validate_should_throw_if_size_and_consensus_are_invalid", func(t *testing.T) {
+		dto := validDto()
+		dto.Size = 0
+		dto.Consensus = "paxos"
+		err := dto.Validate()
+		assert.EqualValues(t, http.StatusBadRequest, err.StatusCode())
+		assert.Contains(t, err.(restErrors.RestErr).Validations, "size")
+		assert.Contains(t, err.(restErrors.RestErr).Validations, "consensus")
+	})
+
+	t.Run("validate_should_throw_if_the_members_secrets_names_are_too_long", func(t *testing.T) {
+		dto := validDto()
+		dto.Name = "a-very-long-cluster-name-that-fits-but-its-members-secrets-not"
+		err := dto.Validate()
+		assert.Contains(t, err.(restErrors.RestErr).Validations, "name")
+	})
+}
+
+func TestService_Create(t *testing.T) {
+	t.Run("create_should_wire_the_members_identities_and_bootstrap_peers", func(t *testing.T) {
+		var generated []string
+		secretGenerateFunc = func(dto secret.GenerateSecretDto) (secret.GeneratedSecretDto, restErrors.IRestErr) {
+			generated = append(generated, dto.Name)
+			if dto.Name != "storage-cluster-secret" {
+				assert.EqualValues(t, secret.Ed25519Generator, dto.Type)
+				assert.EqualValues(t, secret.Libp2pFormat, dto.Format)
+			}
+			return secret.GeneratedSecretDto{Public: map[string]string{"peerId": "id-of-" + dto.Name}}, nil
+		}
+		createdMembers()
+		var clusterPeers []ipfs_cluster_peer.ClusterPeerDto
+		create := clusterPeerCreateFunc
+		clusterPeerCreateFunc = func(dto ipfs_cluster_peer.ClusterPeerDto) (ipfsv1alpha1.ClusterPeer, restErrors.IRestErr) {
+			clusterPeers = append(clusterPeers, dto)
+			return create(dto)
+		}
+
+		result, err := service.Create(validDto())
+		assert.Nil(t, err)
+		assert.Len(t, result.Members, 3)
+		assert.EqualValues(t, []string{"storage-cluster-secret", "storage-cluster-peer-0-identity", "storage-cluster-peer-1-identity", "storage-cluster-peer-2-identity"}, generated)
+
+		ids := []string{"id-of-storage-cluster-peer-0-identity", "id-of-storage-cluster-peer-1-identity", "id-of-storage-cluster-peer-2-identity"}
+		for i, dto := range clusterPeers {
+			assert.EqualValues(t, ids[i], dto.ID)
+			assert.EqualValues(t, ids, dto.TrustedPeers)
+			assert.EqualValues(t, "crdt", dto.Consensus)
+			assert.EqualValues(t, "storage-cluster-secret", dto.ClusterSecretName)
+			assert.EqualValues(t, fmt.Sprintf("/dns4/storage-peer-%d/tcp/5001", i), dto.PeerEndpoint)
+			assert.EqualValues(t, "storage", dto.Labels[ClusterLabel])
+			assert.Len(t, dto.BootstrapPeers, i)
+		}
+		assert.EqualValues(t, []string{"/dns4/storage-cluster-peer-0/tcp/9096/p2p/id-of-storage-cluster-peer-0-identity"}, clusterPeers[1].BootstrapPeers)
+	})
+
+	t.Run("create_should_roll_back_if_a_member_can't_be_created", func(t *testing.T) {
+		generatedIdentities()
+		createdMembers()
+		clusterPeerCreateFunc = func(dto ipfs_cluster_peer.ClusterPeerDto) (ipfsv1alpha1.ClusterPeer, restErrors.IRestErr) {
+			if dto.Name == "storage-cluster-peer-1" {
+				return ipfsv1alpha1.ClusterPeer{}, restErrors.NewBadRequestError("cluster peer by name storage-cluster-peer-1 already exits")
+			}
+			return ipfsv1alpha1.ClusterPeer{ObjectMeta: metav1.ObjectMeta{Name: dto.Name}}, nil
+		}
+		var deleted []string
+		clusterPeerDeleteFunc = func(peer *ipfsv1alpha1.ClusterPeer) restErrors.IRestErr {
+			deleted = append(deleted, peer.Name)
+			return nil
+		}
+		peerDeleteFunc = func(peer *ipfsv1alpha1.Peer) restErrors.IRestErr {
+			deleted = append(deleted, peer.Name)
+			return nil
+		}
+		secretGetFunc = func(key types.NamespacedName) (corev1.Secret, restErrors.IRestErr) {
+			return corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: key.Name}}, nil
+		}
+		secretDeleteFunc = func(model *corev1.Secret) restErrors.IRestErr {
+			deleted = append(deleted, model.Name)
+			return nil
+		}
+
+		_, err := service.Create(validDto())
+		assert.EqualValues(t, "cluster peer by name storage-cluster-peer-1 already exits", err.Error())
+		assert.EqualValues(t, []string{
+			"storage-cluster-peer-0", "storage-peer-0", "storage-peer-1",
+			"storage-cluster-secret", "storage-cluster-peer-0-identity", "storage-cluster-peer-1-identity", "storage-cluster-peer-2-identity",
+		}, deleted)
+	})
+}
+
+func TestService_List(t *testing.T) {
+	t.Run("list_should_group_the_members_by_cluster", func(t *testing.T) {
+		storage := cluster("storage", 2)
+		backup := cluster("backup", 1)
+		peerListFunc = func(namespace string) (ipfsv1alpha1.PeerList, restErrors.IRestErr) {
+			return ipfsv1alpha1.PeerList{Items: []ipfsv1alpha1.Peer{
+				*storage.Members[1].Peer, *backup.Members[0].Peer, *storage.Members[0].Peer,
+				{ObjectMeta: metav1.ObjectMeta{Name: "standalone"}},
+			}}, nil
+		}
+		clusterPeerListFunc = func(namespace string) (ipfsv1alpha1.ClusterPeerList, restErrors.IRestErr) {
+			return ipfsv1alpha1.ClusterPeerList{Items: []ipfsv1alpha1.ClusterPeer{
+				*storage.Members[0].ClusterPeer, *storage.Members[1].ClusterPeer,
+			}}, nil
+		}
+
+		clusters, err := service.List("default")
+		assert.Nil(t, err)
+		assert.Len(t, clusters, 2)
+
+		result, err := service.Get(types.NamespacedName{Namespace: "default", Name: "storage"})
+		assert.Nil(t, err)
+		assert.Len(t, result.Members, 2)
+		assert.EqualValues(t, 0, result.Members[0].Index)
+		assert.EqualValues(t, "storage-cluster-peer-1", result.Members[1].ClusterPeer.Name)
+
+		result, err = service.Get(types.NamespacedName{Namespace: "default", Name: "backup"})
+		assert.Nil(t, err)
+		assert.Nil(t, result.Members[0].ClusterPeer)
+	})
+
+	t.Run("get_should_throw_if_cluster_doesn't_exist", func(t *testing.T) {
+		peerListFunc = func(namespace string) (ipfsv1alpha1.PeerList, restErrors.IRestErr) {
+			return ipfsv1alpha1.PeerList{}, nil
+		}
+		clusterPeerListFunc = func(namespace string) (ipfsv1alpha1.ClusterPeerList, restErrors.IRestErr) {
+			return ipfsv1alpha1.ClusterPeerList{}, nil
+		}
+
+		_, err := service.Get(types.NamespacedName{Namespace: "default", Name: "storage"})
+		assert.EqualValues(t, http.StatusNotFound, err.StatusCode())
+	})
+}
+
+func TestService_Scale(t *testing.T) {
+	t.Run("scale_up_should_bootstrap_the_new_members_to_the_existing_ones", func(t *testing.T) {
+		generatedIdentities()
+		createdMembers()
+		var bootstrapPeers [][]string
+		create := clusterPeerCreateFunc
+		clusterPeerCreateFunc = func(dto ipfs_cluster_peer.ClusterPeerDto) (ipfsv1alpha1.ClusterPeer, restErrors.IRestErr) {
+			assert.EqualValues(t, "storage-cluster-secret", dto.ClusterSecretName)
+			bootstrapPeers = append(bootstrapPeers, dto.BootstrapPeers)
+			return create(dto)
+		}
+		trusted := map[string][]string{}
+		clusterPeerUpdateFunc = func(dto ipfs_cluster_peer.ClusterPeerDto, peer *ipfsv1alpha1.ClusterPeer) restErrors.IRestErr {
+			trusted[peer.Name] = dto.TrustedPeers
+			return nil
+		}
+
+		result, err := service.Scale(cluster("storage", 2), 3)
+		assert.Nil(t, err)
+		assert.Len(t, result.Members, 3)
+		assert.EqualValues(t, "storage-cluster-peer-2", result.Members[2].ClusterPeer.Name)
+		assert.EqualValues(t, [][]string{{
+			"/dns4/storage-cluster-peer-0/tcp/9096/p2p/id-0",
+			"/dns4/storage-cluster-peer-1/tcp/9096/p2p/id-1",
+		}}, bootstrapPeers)
+		assert.Len(t, trusted, 3)
+		assert.EqualValues(t, []string{"id-0", "id-1", "id-of-storage-cluster-peer-2-identity"}, trusted["storage-cluster-peer-0"])
+	})
+
+	t.Run("scale_down_should_delete_the_last_members", func(t *testing.T) {
+		var deleted []string
+		clusterPeerDeleteFunc = func(peer *ipfsv1alpha1.ClusterPeer) restErrors.IRestErr {
+			deleted = append(deleted, peer.Name)
+			return nil
+		}
+		peerDeleteFunc = func(peer *ipfsv1alpha1.Peer) restErrors.IRestErr {
+			deleted = append(deleted, peer.Name)
+			return nil
+		}
+		secretGetFunc = func(key types.NamespacedName) (corev1.Secret, restErrors.IRestErr) {
+			return corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: key.Name}}, nil
+		}
+		secretDeleteFunc = func(model *corev1.Secret) restErrors.IRestErr {
+			deleted = append(deleted, model.Name)
+			return nil
+		}
+		trusted := map[string][]string{}
+		clusterPeerUpdateFunc = func(dto ipfs_cluster_peer.ClusterPeerDto, peer *ipfsv1alpha1.ClusterPeer) restErrors.IRestErr {
+			trusted[peer.Name] = dto.TrustedPeers
+			return nil
+		}
+
+		result, err := service.Scale(cluster("storage", 3), 1)
+		assert.Nil(t, err)
+		assert.Len(t, result.Members, 1)
+		assert.EqualValues(t, []string{
+			"storage-cluster-peer-1", "storage-peer-1", "storage-cluster-peer-1-identity",
+			"storage-cluster-peer-2", "storage-peer-2", "storage-cluster-peer-2-identity",
+		}, deleted)
+		assert.EqualValues(t, map[string][]string{"storage-cluster-peer-0": {"id-0"}}, trusted)
+	})
+}
+
+func TestService_Delete(t *testing.T) {
+	t.Run("delete_should_delete_the_members_and_secrets", func(t *testing.T) {
+		var deleted []string
+		clusterPeerDeleteFunc = func(peer *ipfsv1alpha1.ClusterPeer) restErrors.IRestErr {
+			deleted = append(deleted, peer.Name)
+			return nil
+		}
+		peerDeleteFunc = func(peer *ipfsv1alpha1.Peer) restErrors.IRestErr {
+			deleted = append(deleted, peer.Name)
+			return nil
+		}
+		secretGetFunc = func(key types.NamespacedName) (corev1.Secret, restErrors.IRestErr) {
+			if key.Name == "storage-cluster-peer-0-identity" {
+				return corev1.Secret{}, restErrors.NewNotFoundError("secret by name storage-cluster-peer-0-identity doesn't exist")
+			}
+			return corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: key.Name}}, nil
+		}
+		secretDeleteFunc = func(model *corev1.Secret) restErrors.IRestErr {
+			deleted = append(deleted, model.Name)
+			return nil
+		}
+
+		err := service.Delete(cluster("storage", 2))
+		assert.Nil(t, err)
+		assert.EqualValues(t, []string{
+			"storage-cluster-peer-0", "storage-peer-0",
+			"storage-cluster-peer-1", "storage-peer-1", "storage-cluster-peer-1-identity",
+			"storage-cluster-secret",
+		}, deleted)
+	})
+
+	t.Run("delete_should_throw_if_a_member_can't_be_deleted", func(t *testing.T) {
+		clusterPeerDeleteFunc = func(peer *ipfsv1alpha1.ClusterPeer) restErrors.IRestErr {
+			return restErrors.NewInternalServerError(fmt.Sprintf("can't delete cluster peer by name %s", peer.Name))
+		}
+
+		err := service.Delete(cluster("storage", 2))
+		assert.EqualValues(t, "can't delete cluster peer by name storage-cluster-peer-0", err.Error())
+	})
+}
+
+func TestService_Topology(t *testing.T) {
+	t.Run("topology_should_report_every_member_connectivity_and_pins", func(t *testing.T) {
+		clusterPeerIDFunc = func(peer ipfsv1alpha1.ClusterPeer) (ipfs_cluster_peer.IDDto, restErrors.IRestErr) {
+			if peer.Name == "storage-cluster-peer-2" {
+				return ipfs_cluster_peer.IDDto{}, restErrors.NewBadRequestError("cluster peer by name storage-cluster-peer-2 isn't running")
+			}
+			return ipfs_cluster_peer.IDDto{ID: peer.Spec.ID, ClusterPeers: []string{"id-0", "id-1", "outsider"}, IPFSPeerID: "ipfs-" + peer.Spec.ID}, nil
+		}
+		peerPinsFunc = func(peer ipfsv1alpha1.Peer) ([]ipfs_peer.PinDto, restErrors.IRestErr) {
+			return []ipfs_peer.PinDto{{CID: "a"}, {CID: "b"}}, nil
+		}
+		storage := cluster("storage", 4)
+		storage.Members[3].Peer = nil
+
+		statuses := service.Topology(storage)
+		assert.Len(t, statuses, 4)
+		assert.EqualValues(t, "ipfs-id-0", statuses[0].IPFSPeerID)
+		assert.True(t, statuses[0].IPFSConnected)
+		assert.EqualValues(t, 1, statuses[0].ConnectedMembers)
+		assert.EqualValues(t, 2, statuses[0].PinCount)
+		assert.Empty(t, statuses[1].Error)
+		assert.EqualValues(t, "cluster peer by name storage-cluster-peer-2 isn't running", statuses[2].Error)
+		assert.EqualValues(t, "peer of member 3 doesn't exist", statuses[3].Error)
+		assert.EqualValues(t, "storage-cluster-peer-3", statuses[3].ClusterPeer)
+	})
+}
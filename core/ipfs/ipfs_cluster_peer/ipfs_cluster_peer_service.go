@@ -8,6 +8,7 @@ import (
 	"github.com/kotalco/core-api/pkg/logger"
 	ipfsv1alpha1 "github.com/kotalco/kotal/apis/ipfs/v1alpha1"
 	sharedAPIs "github.com/kotalco/kotal/apis/shared"
+	"io"
 	corev1 "k8s.io/api/core/v1"
 	apiErrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/types"
@@ -24,6 +25,18 @@ type IService interface {
 	List(namespace string) (ipfsv1alpha1.ClusterPeerList, restErrors.IRestErr)
 	Delete(*ipfsv1alpha1.ClusterPeer) restErrors.IRestErr
 	Count(namespace string) (int, restErrors.IRestErr)
+	// Pins lists the cluster pinset with the pins status on every cluster peer
+	Pins(ipfsv1alpha1.ClusterPeer) ([]PinDto, restErrors.IRestErr)
+	// Pin pins the CID on the cluster with the replication factor
+	Pin(ipfsv1alpha1.ClusterPeer, PinRequestDto) (PinDto, restErrors.IRestErr)
+	// Unpin removes the CID from the cluster pinset
+	Unpin(peer ipfsv1alpha1.ClusterPeer, cid string) restErrors.IRestErr
+	// PinStatus returns the CID status on every cluster peer
+	PinStatus(peer ipfsv1alpha1.ClusterPeer, cid string) (PinDto, restErrors.IRestErr)
+	// ID returns the cluster peer identity and the cluster peers it's connected to
+	ID(ipfsv1alpha1.ClusterPeer) (IDDto, restErrors.IRestErr)
+	// Add streams the multipart body to the cluster peer and pins the added files with the replication factor
+	Add(peer ipfsv1alpha1.ClusterPeer, dto AddRequestDto, body io.Reader, contentType string) ([]AddedDto, restErrors.IRestErr)
 }
 
 var (
@@ -60,6 +73,7 @@ func (service ipfsClusterPeerService) Create(dto ClusterPeerDto) (peer ipfsv1alp
 	}
 
 	k8s.DefaultResources(&peer.Spec.Resources)
+	k8s.RequestedStorage(&peer.Spec.Resources, dto.Resources)
 
 	if dto.PeerEndpoint != "" {
 		peer.Spec.PeerEndpoint = dto.PeerEndpoint
@@ -132,6 +146,10 @@ func (service ipfsClusterPeerService) Update(dto ClusterPeerDto, peer *ipfsv1alp
 		peer.Spec.BootstrapPeers = dto.BootstrapPeers
 	}
 
+	if len(dto.TrustedPeers) != 0 {
+		peer.Spec.TrustedPeers = dto.TrustedPeers
+	}
+
 	if dto.CPU != "" {
 		peer.Spec.CPU = dto.CPU
 	}
@@ -0,0 +1,334 @@
+package ipfs_cluster_peer
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/kotalco/core-api/core/ipfs/ipfs_peer"
+	restErrors "github.com/kotalco/core-api/pkg/errors"
+	"github.com/kotalco/core-api/pkg/logger"
+	ipfsv1alpha1 "github.com/kotalco/kotal/apis/ipfs/v1alpha1"
+	"io"
+	"io/ioutil"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// restAPIPort is the cluster peer REST api port, it's not configurable in the cluster peer spec
+var restAPIPort uint = 9094
+
+var (
+	// restClient calls the cluster peer REST api pins endpoints
+	restClient = &http.Client{Timeout: 30 * time.Second}
+	// addClient streams the uploaded files to the cluster peer, files may take long to be chunked and allocated
+	addClient = &http.Client{Timeout: 30 * time.Minute}
+)
+
+// PinDto is a CID pinned on the cluster with its status on every cluster peer
+type PinDto struct {
+	CID   string       `json:"cid"`
+	Name  string       `json:"name"`
+	Peers []PinPeerDto `json:"peers"`
+}
+
+// PinPeerDto is the CID pin status on a cluster peer e.g. pinned, pinning, queued or pin_error
+type PinPeerDto struct {
+	PeerID   string `json:"peerId"`
+	PeerName string `json:"peerName"`
+	Status   string `json:"status"`
+	Error    string `json:"error,omitempty"`
+}
+
+// PinRequestDto is the CID to pin on the cluster
+// replication factor 0 uses the cluster default replication and -1 pins on every cluster peer
+type PinRequestDto struct {
+	CID               string `json:"cid"`
+	Name              string `json:"name"`
+	ReplicationFactor int    `json:"replicationFactor"`
+}
+
+// AddRequestDto is the replication of the files added to the cluster
+type AddRequestDto struct {
+	Name              string
+	ReplicationFactor int
+}
+
+// AddedDto is a file or directory added to the cluster
+type AddedDto struct {
+	Name string `json:"name"`
+	CID  string `json:"cid"`
+	Size uint64 `json:"size"`
+}
+
+// Validate validates the CID and the replication factor
+func (dto PinRequestDto) Validate() restErrors.IRestErr {
+	fields := map[string]string{}
+	if !ipfs_peer.ValidCID(dto.CID) {
+		fields["cid"] = "invalid cid"
+	}
+	if dto.ReplicationFactor < -1 {
+		fields["replicationFactor"] = "replication factor must be -1 for all peers, 0 for the cluster default or the peers count"
+	}
+	if len(fields) > 0 {
+		return restErrors.NewValidationError(fields)
+	}
+	return nil
+}
+
+// Validate validates the replication factor
+func (dto AddRequestDto) Validate() restErrors.IRestErr {
+	if dto.ReplicationFactor < -1 {
+		return restErrors.NewValidationError(map[string]string{"replicationFactor": "replication factor must be -1 for all peers, 0 for the cluster default or the peers count"})
+	}
+	return nil
+}
+
+// replicationArgs sets the replication min and max query args, the cluster default is used if the replication factor is 0
+func replicationArgs(args url.Values, replicationFactor int) url.Values {
+	if replicationFactor != 0 {
+		args.Set("replication-min", strconv.Itoa(replicationFactor))
+		args.Set("replication-max", strconv.Itoa(replicationFactor))
+	}
+	return args
+}
+
+// globalPinInfo is the cluster REST api CID status on every cluster peer
+type globalPinInfo struct {
+	CID     string `json:"cid"`
+	Name    string `json:"name"`
+	PeerMap map[string]struct {
+		PeerName string `json:"peername"`
+		Status   string `json:"status"`
+		Error    string `json:"error"`
+	} `json:"peer_map"`
+}
+
+func (info globalPinInfo) pinDto() PinDto {
+	pin := PinDto{CID: info.CID, Name: info.Name, Peers: make([]PinPeerDto, 0, len(info.PeerMap))}
+	for id, status := range info.PeerMap {
+		pin.Peers = append(pin.Peers, PinPeerDto{PeerID: id, PeerName: status.PeerName, Status: status.Status, Error: status.Error})
+	}
+	sort.Slice(pin.Peers, func(i, j int) bool {
+		return pin.Peers[i].PeerID < pin.Peers[j].PeerID
+	})
+	return pin
+}
+
+// Pins lists the cluster pinset with the pins status on every cluster peer
+func (service ipfsClusterPeerService) Pins(peer ipfsv1alpha1.ClusterPeer) ([]PinDto, restErrors.IRestErr) {
+	raw, restErr := service.rest(peer, http.MethodGet, "pins", nil)
+	if restErr != nil {
+		return nil, restErr
+	}
+
+	var infos []globalPinInfo
+	if err := decodeStream(raw, &infos); err != nil {
+		go logger.Warn(service.Pins, err)
+		return nil, restErrors.NewInternalServerError(fmt.Sprintf("can't read cluster peer by name %s api response", peer.Name))
+	}
+
+	pins := make([]PinDto, 0, len(infos))
+	for _, info := range infos {
+		pins = append(pins, info.pinDto())
+	}
+	sort.Slice(pins, func(i, j int) bool {
+		return pins[i].CID < pins[j].CID
+	})
+	return pins, nil
+}
+
+// Pin pins the CID on the cluster peers allocated by the replication factor
+func (service ipfsClusterPeerService) Pin(peer ipfsv1alpha1.ClusterPeer, dto PinRequestDto) (PinDto, restErrors.IRestErr) {
+	args := replicationArgs(url.Values{}, dto.ReplicationFactor)
+	if dto.Name != "" {
+		args.Set("name", dto.Name)
+	}
+	_, restErr := service.rest(peer, http.MethodPost, fmt.Sprintf("pins/%s?%s", dto.CID, args.Encode()), nil)
+	if restErr != nil {
+		return PinDto{}, restErr
+	}
+	return service.PinStatus(peer, dto.CID)
+}
+
+// Unpin removes the CID from the cluster pinset, the cluster peers unpin it from their ipfs peers
+func (service ipfsClusterPeerService) Unpin(peer ipfsv1alpha1.ClusterPeer, cid string) restErrors.IRestErr {
+	if !ipfs_peer.ValidCID(cid) {
+		return restErrors.NewValidationError(map[string]string{"cid": "invalid cid"})
+	}
+	_, restErr := service.rest(peer, http.MethodDelete, fmt.Sprintf("pins/%s", cid), nil)
+	return restErr
+}
+
+// PinStatus returns the CID status on every cluster peer
+func (service ipfsClusterPeerService) PinStatus(peer ipfsv1alpha1.ClusterPeer, cid string) (PinDto, restErrors.IRestErr) {
+	if !ipfs_peer.ValidCID(cid) {
+		return PinDto{}, restErrors.NewValidationError(map[string]string{"cid": "invalid cid"})
+	}
+	raw, restErr := service.rest(peer, http.MethodGet, fmt.Sprintf("pins/%s", cid), nil)
+	if restErr != nil {
+		return PinDto{}, restErr
+	}
+
+	var info globalPinInfo
+	if err := json.Unmarshal(raw, &info); err != nil {
+		go logger.Warn(service.PinStatus, err)
+		return PinDto{}, restErrors.NewInternalServerError(fmt.Sprintf("can't read cluster peer by name %s api response", peer.Name))
+	}
+	if info.CID == "" {
+		info.CID = cid
+	}
+	return info.pinDto(), nil
+}
+
+// IDDto is the cluster peer identity, the cluster peers in its peerset and its ipfs peer connection
+type IDDto struct {
+	ID           string   `json:"id"`
+	PeerName     string   `json:"peerName"`
+	ClusterPeers []string `json:"clusterPeers"`
+	IPFSPeerID   string   `json:"ipfsPeerId"`
+	IPFSError    string   `json:"ipfsError,omitempty"`
+	Error        string   `json:"error,omitempty"`
+}
+
+// ID returns the cluster peer identity and the cluster peers it's connected to
+func (service ipfsClusterPeerService) ID(peer ipfsv1alpha1.ClusterPeer) (IDDto, restErrors.IRestErr) {
+	raw, restErr := service.rest(peer, http.MethodGet, "id", nil)
+	if restErr != nil {
+		return IDDto{}, restErr
+	}
+
+	var id struct {
+		ID           string   `json:"id"`
+		PeerName     string   `json:"peername"`
+		ClusterPeers []string `json:"cluster_peers"`
+		IPFS         struct {
+			ID    string `json:"id"`
+			Error string `json:"error"`
+		} `json:"ipfs"`
+		Error string `json:"error"`
+	}
+	if err := json.Unmarshal(raw, &id); err != nil {
+		go logger.Warn(service.ID, err)
+		return IDDto{}, restErrors.NewInternalServerError(fmt.Sprintf("can't read cluster peer by name %s api response", peer.Name))
+	}
+	return IDDto{ID: id.ID, PeerName: id.PeerName, ClusterPeers: id.ClusterPeers, IPFSPeerID: id.IPFS.ID, IPFSError: id.IPFS.Error, Error: id.Error}, nil
+}
+
+// Add streams the multipart body to the cluster peer add endpoint, the added files are pinned with the replication factor
+func (service ipfsClusterPeerService) Add(peer ipfsv1alpha1.ClusterPeer, dto AddRequestDto, body io.Reader, contentType string) ([]AddedDto, restErrors.IRestErr) {
+	if !strings.HasPrefix(contentType, "multipart/form-data") {
+		return nil, restErrors.NewBadRequestError("request body must be multipart/form-data")
+	}
+	args := replicationArgs(url.Values{}, dto.ReplicationFactor)
+	if dto.Name != "" {
+		args.Set("name", dto.Name)
+	}
+
+	req, restErr := service.request(peer, http.MethodPost, fmt.Sprintf("add?%s", args.Encode()), body)
+	if restErr != nil {
+		return nil, restErr
+	}
+	req.Header.Set("Content-Type", contentType)
+	raw, restErr := service.do(peer, addClient, req)
+	if restErr != nil {
+		return nil, restErr
+	}
+
+	var files []struct {
+		Name string `json:"name"`
+		CID  string `json:"cid"`
+		Size uint64 `json:"size"`
+	}
+	if err := decodeStream(raw, &files); err != nil {
+		go logger.Warn(service.Add, err)
+		return nil, restErrors.NewInternalServerError(fmt.Sprintf("can't read cluster peer by name %s api response", peer.Name))
+	}
+
+	added := make([]AddedDto, 0, len(files))
+	for _, file := range files {
+		added = append(added, AddedDto{Name: file.Name, CID: file.CID, Size: file.Size})
+	}
+	return added, nil
+}
+
+// rest calls the cluster peer REST api and returns the response body
+func (service ipfsClusterPeerService) rest(peer ipfsv1alpha1.ClusterPeer, method string, path string, body io.Reader) ([]byte, restErrors.IRestErr) {
+	req, restErr := service.request(peer, method, path, body)
+	if restErr != nil {
+		return nil, restErr
+	}
+	return service.do(peer, restClient, req)
+}
+
+// request creates a request to the REST api of the running cluster peer pod, requests don't leave the cluster
+func (service ipfsClusterPeerService) request(peer ipfsv1alpha1.ClusterPeer, method string, path string, body io.Reader) (*http.Request, restErrors.IRestErr) {
+	pod := &corev1.Pod{}
+	if err := k8sClient.Get(context.Background(), types.NamespacedName{Namespace: peer.Namespace, Name: fmt.Sprintf("%s-0", peer.Name)}, pod); err != nil || pod.Status.PodIP == "" {
+		return nil, restErrors.NewBadRequestError(fmt.Sprintf("cluster peer by name %s isn't running", peer.Name))
+	}
+
+	req, err := http.NewRequest(method, fmt.Sprintf("http://%s:%d/%s", pod.Status.PodIP, restAPIPort, path), body)
+	if err != nil {
+		go logger.Error(service.request, err)
+		return nil, restErrors.NewInternalServerError("something went wrong")
+	}
+	return req, nil
+}
+
+// do sends the request, the REST api errors are converted to rest errors
+func (service ipfsClusterPeerService) do(peer ipfsv1alpha1.ClusterPeer, client *http.Client, req *http.Request) ([]byte, restErrors.IRestErr) {
+	resp, err := client.Do(req)
+	if err != nil {
+		go logger.Warn(service.do, err)
+		return nil, restErrors.NewBadRequestError(fmt.Sprintf("can't reach cluster peer by name %s api", peer.Name))
+	}
+	defer resp.Body.Close()
+
+	raw, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		go logger.Warn(service.do, err)
+		return nil, restErrors.NewInternalServerError(fmt.Sprintf("can't read cluster peer by name %s api response", peer.Name))
+	}
+	if resp.StatusCode >= http.StatusBadRequest {
+		var apiErr struct {
+			Message string `json:"message"`
+		}
+		json.Unmarshal(raw, &apiErr)
+		if resp.StatusCode == http.StatusNotFound {
+			return nil, restErrors.NewNotFoundError(apiErr.Message)
+		}
+		return nil, restErrors.NewBadRequestError(fmt.Sprintf("cluster peer by name %s api returned %d: %s", peer.Name, resp.StatusCode, apiErr.Message))
+	}
+	return raw, nil
+}
+
+// decodeStream decodes a json array or a stream of json objects into out slice pointer
+// the REST api streams the pins and the added files since ipfs cluster v1.0
+func decodeStream(raw []byte, out interface{}) error {
+	raw = bytes.TrimSpace(raw)
+	if len(raw) == 0 {
+		raw = []byte("[]")
+	}
+	if raw[0] == '[' {
+		return json.Unmarshal(raw, out)
+	}
+
+	var items []json.RawMessage
+	decoder := json.NewDecoder(bytes.NewReader(raw))
+	for decoder.More() {
+		var item json.RawMessage
+		if err := decoder.Decode(&item); err != nil {
+			return err
+		}
+		items = append(items, item)
+	}
+	array, _ := json.Marshal(items)
+	return json.Unmarshal(array, out)
+}
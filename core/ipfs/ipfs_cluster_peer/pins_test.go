@@ -0,0 +1,215 @@
+package ipfs_cluster_peer
+
+import (
+	"bytes"
+	"context"
+	restErrors "github.com/kotalco/core-api/pkg/errors"
+	ipfsv1alpha1 "github.com/kotalco/kotal/apis/ipfs/v1alpha1"
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"mime/multipart"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"strconv"
+	"testing"
+)
+
+/*
+k8s client mocks
+*/
+var k8sClientGetFunc func(key client.ObjectKey, obj client.Object) error
+
+type k8sClientMock struct{}
+
+func (k8sClientMock) Get(ctx context.Context, key client.ObjectKey, obj client.Object, opts ...client.GetOption) error {
+	return k8sClientGetFunc(key, obj)
+}
+func (k8sClientMock) List(ctx context.Context, list client.ObjectList, opts ...client.ListOption) error {
+	return nil
+}
+func (k8sClientMock) Create(ctx context.Context, obj client.Object, opts ...client.CreateOption) error {
+	return nil
+}
+func (k8sClientMock) Delete(ctx context.Context, obj client.Object, opts ...client.DeleteOption) error {
+	return nil
+}
+func (k8sClientMock) Update(ctx context.Context, obj client.Object, opts ...client.UpdateOption) error {
+	return nil
+}
+func (k8sClientMock) Patch(ctx context.Context, obj client.Object, patch client.Patch, opts ...client.PatchOption) error {
+	return nil
+}
+func (k8sClientMock) DeleteAllOf(ctx context.Context, obj client.Object, opts ...client.DeleteAllOfOption) error {
+	return nil
+}
+
+const cid = "QmYwAPJzv5CZsnA625s3Xf2nemtYgPpHdWEz79ojWnPbdG"
+
+var clusterPeer = ipfsv1alpha1.ClusterPeer{ObjectMeta: metav1.ObjectMeta{Name: "my-cluster-peer", Namespace: "default"}}
+
+func TestMain(m *testing.M) {
+	k8sClient = &k8sClientMock{}
+	k8sClientGetFunc = func(key client.ObjectKey, obj client.Object) error {
+		obj.(*corev1.Pod).Status.PodIP = "127.0.0.1"
+		return nil
+	}
+
+	code := m.Run()
+	os.Exit(code)
+}
+
+// clusterServer serves the handler as the REST api of the running cluster peer
+func clusterServer(handler http.HandlerFunc) *httptest.Server {
+	server := httptest.NewServer(handler)
+	_, port, _ := net.SplitHostPort(server.Listener.Addr().String())
+	apiPort, _ := strconv.Atoi(port)
+	restAPIPort = uint(apiPort)
+	return server
+}
+
+const pinInfo = `{"cid":"` + cid + `","name":"my-file","peer_map":{"12D3KooWB":{"peername":"peer-b","status":"pinning"},"12D3KooWA":{"peername":"peer-a","status":"pinned"}}}`
+
+func TestPinRequestDto_Validate(t *testing.T) {
+	t.Run("validate_should_pass", func(t *testing.T) {
+		assert.Nil(t, PinRequestDto{CID: cid, ReplicationFactor: -1}.Validate())
+	})
+	t.Run("validate_should_throw_for_invalid_cid_and_replication_factor", func(t *testing.T) {
+		err := PinRequestDto{CID: "invalid", ReplicationFactor: -2}.Validate()
+		assert.EqualValues(t, http.StatusBadRequest, err.StatusCode())
+	})
+}
+
+func TestService_Pins(t *testing.T) {
+	t.Run("pins_should_read_the_streamed_pins", func(t *testing.T) {
+		server := clusterServer(func(w http.ResponseWriter, r *http.Request) {
+			assert.Equal(t, http.MethodGet, r.Method)
+			assert.Equal(t, "/pins", r.URL.Path)
+			w.Write([]byte(pinInfo + "\n"))
+		})
+		defer server.Close()
+
+		pins, err := NewIpfsClusterPeerService().Pins(clusterPeer)
+		assert.Nil(t, err)
+		assert.EqualValues(t, []PinDto{{CID: cid, Name: "my-file", Peers: []PinPeerDto{
+			{PeerID: "12D3KooWA", PeerName: "peer-a", Status: "pinned"},
+			{PeerID: "12D3KooWB", PeerName: "peer-b", Status: "pinning"},
+		}}}, pins)
+	})
+
+	t.Run("pins_should_read_the_pins_array_of_older_clusters", func(t *testing.T) {
+		server := clusterServer(func(w http.ResponseWriter, r *http.Request) {
+			w.Write([]byte("[" + pinInfo + "]"))
+		})
+		defer server.Close()
+
+		pins, err := NewIpfsClusterPeerService().Pins(clusterPeer)
+		assert.Nil(t, err)
+		assert.Len(t, pins, 1)
+	})
+
+	t.Run("pins_should_throw_if_cluster_peer_is_not_running", func(t *testing.T) {
+		k8sClientGetFunc = func(key client.ObjectKey, obj client.Object) error {
+			return nil
+		}
+		defer func() {
+			k8sClientGetFunc = func(key client.ObjectKey, obj client.Object) error {
+				obj.(*corev1.Pod).Status.PodIP = "127.0.0.1"
+				return nil
+			}
+		}()
+
+		_, err := NewIpfsClusterPeerService().Pins(clusterPeer)
+		assert.EqualValues(t, "cluster peer by name my-cluster-peer isn't running", err.Error())
+	})
+}
+
+func TestService_Pin(t *testing.T) {
+	t.Run("pin_should_pin_with_the_replication_factor", func(t *testing.T) {
+		server := clusterServer(func(w http.ResponseWriter, r *http.Request) {
+			assert.Equal(t, "/pins/"+cid, r.URL.Path)
+			if r.Method == http.MethodPost {
+				assert.Equal(t, "2", r.URL.Query().Get("replication-min"))
+				assert.Equal(t, "2", r.URL.Query().Get("replication-max"))
+				assert.Equal(t, "my-file", r.URL.Query().Get("name"))
+				w.Write([]byte(`{"cid":"` + cid + `"}`))
+				return
+			}
+			w.Write([]byte(pinInfo))
+		})
+		defer server.Close()
+
+		pin, err := NewIpfsClusterPeerService().Pin(clusterPeer, PinRequestDto{CID: cid, Name: "my-file", ReplicationFactor: 2})
+		assert.Nil(t, err)
+		assert.Len(t, pin.Peers, 2)
+	})
+}
+
+func TestService_Unpin(t *testing.T) {
+	t.Run("unpin_should_throw_not_found_if_cid_is_not_pinned", func(t *testing.T) {
+		server := clusterServer(func(w http.ResponseWriter, r *http.Request) {
+			assert.Equal(t, http.MethodDelete, r.Method)
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"code":404,"message":"cid is not part of the global state"}`))
+		})
+		defer server.Close()
+
+		err := NewIpfsClusterPeerService().Unpin(clusterPeer, cid)
+		assert.EqualValues(t, http.StatusNotFound, err.StatusCode())
+		assert.EqualValues(t, "cid is not part of the global state", err.Error())
+	})
+	t.Run("unpin_should_throw_for_invalid_cid", func(t *testing.T) {
+		err := NewIpfsClusterPeerService().Unpin(clusterPeer, "../peers")
+		assert.EqualValues(t, http.StatusBadRequest, err.StatusCode())
+		assert.EqualValues(t, map[string]string{"cid": "invalid cid"}, err.(restErrors.RestErr).Validations)
+	})
+}
+
+func TestService_PinStatus(t *testing.T) {
+	t.Run("pin_status_should_throw_for_invalid_cid", func(t *testing.T) {
+		_, err := NewIpfsClusterPeerService().PinStatus(clusterPeer, "../peers")
+		assert.EqualValues(t, http.StatusBadRequest, err.StatusCode())
+		assert.EqualValues(t, map[string]string{"cid": "invalid cid"}, err.(restErrors.RestErr).Validations)
+	})
+}
+
+func TestService_Add(t *testing.T) {
+	t.Run("add_should_stream_the_files_to_the_cluster_peer", func(t *testing.T) {
+		server := clusterServer(func(w http.ResponseWriter, r *http.Request) {
+			assert.Equal(t, "/add", r.URL.Path)
+			assert.Equal(t, "-1", r.URL.Query().Get("replication-min"))
+			_, header, err := r.FormFile("file")
+			assert.Nil(t, err)
+			assert.Equal(t, "hello.txt", header.Filename)
+			w.Write([]byte(`{"name":"hello.txt","cid":"` + cid + `","size":11}` + "\n"))
+		})
+		defer server.Close()
+
+		body := new(bytes.Buffer)
+		writer := multipart.NewWriter(body)
+		part, _ := writer.CreateFormFile("file", "hello.txt")
+		part.Write([]byte("hello world"))
+		writer.Close()
+
+		added, err := NewIpfsClusterPeerService().Add(clusterPeer, AddRequestDto{ReplicationFactor: -1}, body, writer.FormDataContentType())
+		assert.Nil(t, err)
+		assert.EqualValues(t, []AddedDto{{Name: "hello.txt", CID: cid, Size: 11}}, added)
+	})
+}
+
+func TestService_ID(t *testing.T) {
+	t.Run("id_should_return_the_cluster_peer_identity", func(t *testing.T) {
+		server := clusterServer(func(w http.ResponseWriter, r *http.Request) {
+			assert.Equal(t, "/id", r.URL.Path)
+			w.Write([]byte(`{"id":"12D3KooWA","peername":"peer-a","cluster_peers":["12D3KooWA","12D3KooWB"],"ipfs":{"id":"12D3KooWI","error":""}}`))
+		})
+		defer server.Close()
+
+		id, err := NewIpfsClusterPeerService().ID(clusterPeer)
+		assert.Nil(t, err)
+		assert.EqualValues(t, IDDto{ID: "12D3KooWA", PeerName: "peer-a", ClusterPeers: []string{"12D3KooWA", "12D3KooWB"}, IPFSPeerID: "12D3KooWI"}, id)
+	})
+}
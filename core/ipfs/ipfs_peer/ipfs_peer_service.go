@@ -8,6 +8,7 @@ import (
 	"github.com/kotalco/core-api/pkg/logger"
 	ipfsv1alpha1 "github.com/kotalco/kotal/apis/ipfs/v1alpha1"
 	sharedAPIs "github.com/kotalco/kotal/apis/shared"
+	"io"
 	corev1 "k8s.io/api/core/v1"
 	apiErrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/types"
@@ -24,6 +25,16 @@ type IService interface {
 	List(namespace string) (ipfsv1alpha1.PeerList, restErrors.IRestErr)
 	Delete(*ipfsv1alpha1.Peer) restErrors.IRestErr
 	Count(namespace string) (int, restErrors.IRestErr)
+	// Pins lists the recursively pinned CIDs of the peer
+	Pins(ipfsv1alpha1.Peer) ([]PinDto, restErrors.IRestErr)
+	// Pin pins the CID on the peer
+	Pin(ipfsv1alpha1.Peer, PinRequestDto) (PinDto, restErrors.IRestErr)
+	// Unpin removes the CID pin from the peer
+	Unpin(peer ipfsv1alpha1.Peer, cid string) restErrors.IRestErr
+	// PinStatus returns the CID pin, not found if the CID isn't pinned
+	PinStatus(peer ipfsv1alpha1.Peer, cid string) (PinDto, restErrors.IRestErr)
+	// Add streams the multipart body to the peer and pins the added files
+	Add(peer ipfsv1alpha1.Peer, body io.Reader, contentType string) ([]AddedDto, restErrors.IRestErr)
 }
 
 var (
@@ -67,6 +78,7 @@ func (service ipfsPeerService) Create(dto PeerDto) (peer ipfsv1alpha1.Peer, rest
 	}
 
 	k8s.DefaultResources(&peer.Spec.Resources)
+	k8s.RequestedStorage(&peer.Spec.Resources, dto.Resources)
 
 	if os.Getenv("MOCK") == "true" {
 		peer.Default()
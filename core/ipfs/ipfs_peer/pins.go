@@ -0,0 +1,214 @@
+package ipfs_peer
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	restErrors "github.com/kotalco/core-api/pkg/errors"
+	"github.com/kotalco/core-api/pkg/logger"
+	ipfsv1alpha1 "github.com/kotalco/kotal/apis/ipfs/v1alpha1"
+	"io"
+	"io/ioutil"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"net/http"
+	"net/url"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+)
+
+// cidPattern matches CIDv0 base58 multihashes and CIDv1 base32 strings
+var cidPattern = regexp.MustCompile(`^(Qm[1-9A-HJ-NP-Za-km-z]{44}|b[a-z2-7]{58,})$`)
+
+var (
+	// rpcClient calls the peer rpc api pin endpoints
+	rpcClient = &http.Client{Timeout: 30 * time.Second}
+	// addClient streams the uploaded files to the peer, files may take long to be chunked and stored
+	addClient = &http.Client{Timeout: 30 * time.Minute}
+)
+
+// PinDto is a CID pinned on the peer
+type PinDto struct {
+	CID  string `json:"cid"`
+	Type string `json:"type"`
+}
+
+// PinRequestDto is the CID to pin on the peer
+type PinRequestDto struct {
+	CID string `json:"cid"`
+}
+
+// AddedDto is a file or directory added to the peer
+type AddedDto struct {
+	Name string `json:"name"`
+	CID  string `json:"cid"`
+	Size string `json:"size"`
+}
+
+// Validate validates the CID is v0 or base32 v1
+func (dto PinRequestDto) Validate() restErrors.IRestErr {
+	if !ValidCID(dto.CID) {
+		return restErrors.NewValidationError(map[string]string{"cid": "invalid cid"})
+	}
+	return nil
+}
+
+// ValidCID checks the CID is v0 or base32 v1
+func ValidCID(cid string) bool {
+	return cidPattern.MatchString(cid)
+}
+
+// Pins lists the recursively pinned CIDs of the peer
+func (service ipfsPeerService) Pins(peer ipfsv1alpha1.Peer) ([]PinDto, restErrors.IRestErr) {
+	var response struct {
+		Keys map[string]struct {
+			Type string
+		}
+	}
+	restErr := service.rpc(peer, "pin/ls", url.Values{"type": {"recursive"}}, &response)
+	if restErr != nil {
+		return nil, restErr
+	}
+
+	pins := make([]PinDto, 0, len(response.Keys))
+	for cid, pin := range response.Keys {
+		pins = append(pins, PinDto{CID: cid, Type: pin.Type})
+	}
+	sort.Slice(pins, func(i, j int) bool {
+		return pins[i].CID < pins[j].CID
+	})
+	return pins, nil
+}
+
+// Pin pins the CID recursively on the peer, the peer fetches the content from the network if it doesn't have it
+func (service ipfsPeerService) Pin(peer ipfsv1alpha1.Peer, dto PinRequestDto) (PinDto, restErrors.IRestErr) {
+	var response struct {
+		Pins []string
+	}
+	restErr := service.rpc(peer, "pin/add", url.Values{"arg": {dto.CID}}, &response)
+	if restErr != nil {
+		return PinDto{}, restErr
+	}
+	return PinDto{CID: dto.CID, Type: "recursive"}, nil
+}
+
+// Unpin removes the CID recursive pin from the peer
+func (service ipfsPeerService) Unpin(peer ipfsv1alpha1.Peer, cid string) restErrors.IRestErr {
+	var response struct {
+		Pins []string
+	}
+	return service.rpc(peer, "pin/rm", url.Values{"arg": {cid}}, &response)
+}
+
+// PinStatus returns the CID pin type, not found if the CID isn't pinned
+func (service ipfsPeerService) PinStatus(peer ipfsv1alpha1.Peer, cid string) (PinDto, restErrors.IRestErr) {
+	var response struct {
+		Keys map[string]struct {
+			Type string
+		}
+	}
+	restErr := service.rpc(peer, "pin/ls", url.Values{"arg": {cid}}, &response)
+	if restErr != nil {
+		return PinDto{}, restErr
+	}
+	for key, pin := range response.Keys {
+		return PinDto{CID: key, Type: pin.Type}, nil
+	}
+	return PinDto{}, restErrors.NewNotFoundError(fmt.Sprintf("cid %s is not pinned", cid))
+}
+
+// Add streams the multipart body to the peer add endpoint, the added files are pinned
+func (service ipfsPeerService) Add(peer ipfsv1alpha1.Peer, body io.Reader, contentType string) ([]AddedDto, restErrors.IRestErr) {
+	if !strings.HasPrefix(contentType, "multipart/form-data") {
+		return nil, restErrors.NewBadRequestError("request body must be multipart/form-data")
+	}
+	apiURL, restErr := service.apiURL(peer)
+	if restErr != nil {
+		return nil, restErr
+	}
+
+	req, err := http.NewRequest(http.MethodPost, fmt.Sprintf("%s/add?%s", apiURL, url.Values{"pin": {"true"}, "progress": {"false"}}.Encode()), body)
+	if err != nil {
+		go logger.Error(service.Add, err)
+		return nil, restErrors.NewInternalServerError("something went wrong")
+	}
+	req.Header.Set("Content-Type", contentType)
+
+	resp, err := addClient.Do(req)
+	if err != nil {
+		go logger.Warn(service.Add, err)
+		return nil, restErrors.NewBadRequestError(fmt.Sprintf("can't reach peer by name %s api", peer.Name))
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, rpcError(peer, resp)
+	}
+
+	// the added files are returned as a stream of json objects
+	added := make([]AddedDto, 0)
+	decoder := json.NewDecoder(resp.Body)
+	for decoder.More() {
+		var file struct {
+			Name string
+			Hash string
+			Size string
+		}
+		if err := decoder.Decode(&file); err != nil {
+			go logger.Warn(service.Add, err)
+			return nil, restErrors.NewInternalServerError(fmt.Sprintf("can't read peer by name %s api response", peer.Name))
+		}
+		added = append(added, AddedDto{Name: file.Name, CID: file.Hash, Size: file.Size})
+	}
+	return added, nil
+}
+
+// apiURL returns the rpc api url of the running peer pod, requests don't leave the cluster
+func (service ipfsPeerService) apiURL(peer ipfsv1alpha1.Peer) (string, restErrors.IRestErr) {
+	if !peer.Spec.API {
+		return "", restErrors.NewBadRequestError("peer api is not enabled")
+	}
+	pod := &corev1.Pod{}
+	if err := k8sClient.Get(context.Background(), types.NamespacedName{Namespace: peer.Namespace, Name: fmt.Sprintf("%s-0", peer.Name)}, pod); err != nil || pod.Status.PodIP == "" {
+		return "", restErrors.NewBadRequestError(fmt.Sprintf("peer by name %s isn't running", peer.Name))
+	}
+	return fmt.Sprintf("http://%s:%d/api/v0", pod.Status.PodIP, peer.Spec.APIPort), nil
+}
+
+// rpc calls the peer rpc api command, all commands are POST requests with query args
+func (service ipfsPeerService) rpc(peer ipfsv1alpha1.Peer, command string, args url.Values, out interface{}) restErrors.IRestErr {
+	apiURL, restErr := service.apiURL(peer)
+	if restErr != nil {
+		return restErr
+	}
+
+	resp, err := rpcClient.Post(fmt.Sprintf("%s/%s?%s", apiURL, command, args.Encode()), "", nil)
+	if err != nil {
+		go logger.Warn(service.rpc, err)
+		return restErrors.NewBadRequestError(fmt.Sprintf("can't reach peer by name %s api", peer.Name))
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return rpcError(peer, resp)
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		go logger.Warn(service.rpc, err)
+		return restErrors.NewInternalServerError(fmt.Sprintf("can't read peer by name %s api response", peer.Name))
+	}
+	return nil
+}
+
+// rpcError converts the peer rpc api error, unpinned CIDs are not found
+func rpcError(peer ipfsv1alpha1.Peer, resp *http.Response) restErrors.IRestErr {
+	raw, _ := ioutil.ReadAll(resp.Body)
+	var apiErr struct {
+		Message string
+	}
+	json.Unmarshal(raw, &apiErr)
+	if strings.Contains(apiErr.Message, "not pinned") {
+		return restErrors.NewNotFoundError(apiErr.Message)
+	}
+	return restErrors.NewBadRequestError(fmt.Sprintf("peer by name %s api returned %d: %s", peer.Name, resp.StatusCode, apiErr.Message))
+}
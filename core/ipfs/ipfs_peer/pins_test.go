@@ -0,0 +1,188 @@
+package ipfs_peer
+
+import (
+	"bytes"
+	"context"
+	ipfsv1alpha1 "github.com/kotalco/kotal/apis/ipfs/v1alpha1"
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"mime/multipart"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"strconv"
+	"testing"
+)
+
+/*
+k8s client mocks
+*/
+var k8sClientGetFunc func(key client.ObjectKey, obj client.Object) error
+
+type k8sClientMock struct{}
+
+func (k8sClientMock) Get(ctx context.Context, key client.ObjectKey, obj client.Object, opts ...client.GetOption) error {
+	return k8sClientGetFunc(key, obj)
+}
+func (k8sClientMock) List(ctx context.Context, list client.ObjectList, opts ...client.ListOption) error {
+	return nil
+}
+func (k8sClientMock) Create(ctx context.Context, obj client.Object, opts ...client.CreateOption) error {
+	return nil
+}
+func (k8sClientMock) Delete(ctx context.Context, obj client.Object, opts ...client.DeleteOption) error {
+	return nil
+}
+func (k8sClientMock) Update(ctx context.Context, obj client.Object, opts ...client.UpdateOption) error {
+	return nil
+}
+func (k8sClientMock) Patch(ctx context.Context, obj client.Object, patch client.Patch, opts ...client.PatchOption) error {
+	return nil
+}
+func (k8sClientMock) DeleteAllOf(ctx context.Context, obj client.Object, opts ...client.DeleteAllOfOption) error {
+	return nil
+}
+
+const (
+	cid1 = "QmYwAPJzv5CZsnA625s3Xf2nemtYgPpHdWEz79ojWnPbdG"
+	cid2 = "bafybeigdyrzt5sfp7udm7hu76uh7y26nf3efuylqabf3oclgtqy55fbzdi"
+)
+
+func TestMain(m *testing.M) {
+	k8sClient = &k8sClientMock{}
+	k8sClientGetFunc = func(key client.ObjectKey, obj client.Object) error {
+		obj.(*corev1.Pod).Status.PodIP = "127.0.0.1"
+		return nil
+	}
+
+	code := m.Run()
+	os.Exit(code)
+}
+
+// peerServer serves the handler as the rpc api of a running peer
+func peerServer(handler http.HandlerFunc) (*httptest.Server, ipfsv1alpha1.Peer) {
+	server := httptest.NewServer(handler)
+	_, port, _ := net.SplitHostPort(server.Listener.Addr().String())
+	apiPort, _ := strconv.Atoi(port)
+
+	return server, ipfsv1alpha1.Peer{
+		ObjectMeta: metav1.ObjectMeta{Name: "my-peer", Namespace: "default"},
+		Spec:       ipfsv1alpha1.PeerSpec{API: true, APIPort: uint(apiPort)},
+	}
+}
+
+func TestPinRequestDto_Validate(t *testing.T) {
+	t.Run("validate_should_pass_for_cid_v0_and_v1", func(t *testing.T) {
+		assert.Nil(t, PinRequestDto{CID: cid1}.Validate())
+		assert.Nil(t, PinRequestDto{CID: cid2}.Validate())
+	})
+	t.Run("validate_should_throw_for_invalid_cid", func(t *testing.T) {
+		err := PinRequestDto{CID: "Qm1234"}.Validate()
+		assert.EqualValues(t, http.StatusBadRequest, err.StatusCode())
+	})
+}
+
+func TestService_Pins(t *testing.T) {
+	t.Run("pins_should_list_the_recursive_pins", func(t *testing.T) {
+		server, peer := peerServer(func(w http.ResponseWriter, r *http.Request) {
+			assert.Equal(t, http.MethodPost, r.Method)
+			assert.Equal(t, "/api/v0/pin/ls", r.URL.Path)
+			assert.Equal(t, "recursive", r.URL.Query().Get("type"))
+			w.Write([]byte(`{"Keys":{"` + cid2 + `":{"Type":"recursive"},"` + cid1 + `":{"Type":"recursive"}}}`))
+		})
+		defer server.Close()
+
+		pins, err := NewIpfsPeerService().Pins(peer)
+		assert.Nil(t, err)
+		assert.EqualValues(t, []PinDto{{CID: cid1, Type: "recursive"}, {CID: cid2, Type: "recursive"}}, pins)
+	})
+
+	t.Run("pins_should_throw_if_peer_api_is_not_enabled", func(t *testing.T) {
+		_, err := NewIpfsPeerService().Pins(ipfsv1alpha1.Peer{})
+		assert.EqualValues(t, "peer api is not enabled", err.Error())
+	})
+
+	t.Run("pins_should_throw_if_peer_is_unreachable", func(t *testing.T) {
+		server, peer := peerServer(nil)
+		server.Close()
+
+		_, err := NewIpfsPeerService().Pins(peer)
+		assert.EqualValues(t, "can't reach peer by name my-peer api", err.Error())
+	})
+}
+
+func TestService_Pin(t *testing.T) {
+	t.Run("pin_should_pin_the_cid", func(t *testing.T) {
+		server, peer := peerServer(func(w http.ResponseWriter, r *http.Request) {
+			assert.Equal(t, "/api/v0/pin/add", r.URL.Path)
+			assert.Equal(t, cid1, r.URL.Query().Get("arg"))
+			w.Write([]byte(`{"Pins":["` + cid1 + `"]}`))
+		})
+		defer server.Close()
+
+		pin, err := NewIpfsPeerService().Pin(peer, PinRequestDto{CID: cid1})
+		assert.Nil(t, err)
+		assert.EqualValues(t, PinDto{CID: cid1, Type: "recursive"}, pin)
+	})
+}
+
+func TestService_Unpin(t *testing.T) {
+	t.Run("unpin_should_throw_not_found_if_cid_is_not_pinned", func(t *testing.T) {
+		server, peer := peerServer(func(w http.ResponseWriter, r *http.Request) {
+			assert.Equal(t, "/api/v0/pin/rm", r.URL.Path)
+			w.WriteHeader(http.StatusInternalServerError)
+			w.Write([]byte(`{"Message":"not pinned or pinned indirectly","Code":0,"Type":"error"}`))
+		})
+		defer server.Close()
+
+		err := NewIpfsPeerService().Unpin(peer, cid1)
+		assert.EqualValues(t, http.StatusNotFound, err.StatusCode())
+	})
+}
+
+func TestService_PinStatus(t *testing.T) {
+	t.Run("pin_status_should_return_the_cid_pin", func(t *testing.T) {
+		server, peer := peerServer(func(w http.ResponseWriter, r *http.Request) {
+			assert.Equal(t, cid1, r.URL.Query().Get("arg"))
+			w.Write([]byte(`{"Keys":{"` + cid1 + `":{"Type":"recursive"}}}`))
+		})
+		defer server.Close()
+
+		pin, err := NewIpfsPeerService().PinStatus(peer, cid1)
+		assert.Nil(t, err)
+		assert.EqualValues(t, "recursive", pin.Type)
+	})
+}
+
+func TestService_Add(t *testing.T) {
+	t.Run("add_should_stream_the_files_to_the_peer", func(t *testing.T) {
+		server, peer := peerServer(func(w http.ResponseWriter, r *http.Request) {
+			assert.Equal(t, "/api/v0/add", r.URL.Path)
+			assert.Equal(t, "true", r.URL.Query().Get("pin"))
+			file, header, err := r.FormFile("file")
+			assert.Nil(t, err)
+			defer file.Close()
+			assert.Equal(t, "hello.txt", header.Filename)
+			w.Write([]byte(`{"Name":"hello.txt","Hash":"` + cid1 + `","Size":"13"}` + "\n"))
+		})
+		defer server.Close()
+
+		body := new(bytes.Buffer)
+		writer := multipart.NewWriter(body)
+		part, _ := writer.CreateFormFile("file", "hello.txt")
+		part.Write([]byte("hello world"))
+		writer.Close()
+
+		added, err := NewIpfsPeerService().Add(peer, body, writer.FormDataContentType())
+		assert.Nil(t, err)
+		assert.EqualValues(t, []AddedDto{{Name: "hello.txt", CID: cid1, Size: "13"}}, added)
+	})
+
+	t.Run("add_should_throw_if_body_is_not_multipart", func(t *testing.T) {
+		_, err := NewIpfsPeerService().Add(ipfsv1alpha1.Peer{}, bytes.NewReader(nil), "application/json")
+		assert.EqualValues(t, "request body must be multipart/form-data", err.Error())
+	})
+}
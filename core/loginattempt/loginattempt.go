@@ -0,0 +1,42 @@
+package loginattempt
+
+import "time"
+
+// LoginAttempt tracks the failed sign in and totp verification attempts of a single account
+type LoginAttempt struct {
+	ID             string
+	UserId         string `gorm:"uniqueIndex"`
+	FailedAttempts uint
+	LastFailedAt   int64
+	LockedUntil    int64
+}
+
+// IsLocked checks if the lockout period is still running
+func (attempt LoginAttempt) IsLocked(now time.Time) bool {
+	return attempt.LockedUntil > now.Unix()
+}
+
+// Delay returns the time the account has to wait after its last failed attempt
+func (attempt LoginAttempt) Delay() time.Duration {
+	if attempt.FailedAttempts <= FreeAttempts {
+		return 0
+	}
+
+	delay := BaseDelay
+	for i := uint(FreeAttempts + 1); i < attempt.FailedAttempts && delay < MaxDelay; i++ {
+		delay *= 2
+	}
+	if delay > MaxDelay {
+		delay = MaxDelay
+	}
+
+	return delay
+}
+
+// expired checks if the previous failures shouldn't be counted anymore, either the lockout is over or the last failure is out of FailuresWindow
+func (attempt LoginAttempt) expired(now time.Time) bool {
+	if attempt.LockedUntil != 0 {
+		return !attempt.IsLocked(now)
+	}
+	return now.Sub(time.Unix(attempt.LastFailedAt, 0)) > FailuresWindow
+}
@@ -0,0 +1,120 @@
+package loginattempt
+
+import (
+	"fmt"
+	"github.com/google/uuid"
+	restErrors "github.com/kotalco/core-api/pkg/errors"
+	"github.com/kotalco/core-api/pkg/logger"
+	"github.com/kotalco/core-api/pkg/sqlclient"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type repository struct {
+	db *gorm.DB
+}
+
+type IRepository interface {
+	WithTransaction(txHandle *gorm.DB) IRepository
+	WithoutTransaction() IRepository
+	Transaction(fn func(repository IRepository) restErrors.IRestErr) restErrors.IRestErr
+	Create(attempt *LoginAttempt) restErrors.IRestErr
+	GetByUserId(userId string) (*LoginAttempt, restErrors.IRestErr)
+	LockByUserId(userId string) (*LoginAttempt, restErrors.IRestErr)
+	Update(attempt *LoginAttempt) restErrors.IRestErr
+	DeleteByUserId(userId string) restErrors.IRestErr
+}
+
+func NewRepository() IRepository {
+	newRepository := repository{}
+	return newRepository
+}
+
+func (r repository) WithTransaction(txHandle *gorm.DB) IRepository {
+	r.db = txHandle
+	return r
+}
+func (r repository) WithoutTransaction() IRepository {
+	r.db = sqlclient.OpenDBConnection()
+	return r
+}
+
+// Transaction runs fn with a repository bound to a new transaction, the transaction is rolled back if fn throws
+func (r repository) Transaction(fn func(repository IRepository) restErrors.IRestErr) (restErr restErrors.IRestErr) {
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		restErr = fn(repository{db: tx})
+		if restErr != nil {
+			return restErr
+		}
+		return nil
+	})
+	if err != nil && restErr == nil {
+		go logger.Error(repository.Transaction, err)
+		restErr = restErrors.NewInternalServerError("something went wrong")
+	}
+
+	return
+}
+
+func (r repository) Create(attempt *LoginAttempt) restErrors.IRestErr {
+	res := r.db.Create(attempt)
+	if res.Error != nil {
+		go logger.Error(repository.Create, res.Error)
+		return restErrors.NewInternalServerError("something went wrong")
+	}
+
+	return nil
+}
+
+func (r repository) GetByUserId(userId string) (*LoginAttempt, restErrors.IRestErr) {
+	var attempt = new(LoginAttempt)
+
+	result := r.db.Where("user_id = ?", userId).First(attempt)
+	if result.Error != nil {
+		return nil, restErrors.NewNotFoundError(fmt.Sprintf("can't find login attempts with userId %s", userId))
+	}
+
+	return attempt, nil
+}
+
+// LockByUserId returns the account login attempts locked for update till the end of the transaction
+// the row is created first if the account has no failed attempts, so the first attempts of the account are serialized too
+func (r repository) LockByUserId(userId string) (*LoginAttempt, restErrors.IRestErr) {
+	var attempt = new(LoginAttempt)
+	attempt.ID = uuid.NewString()
+	attempt.UserId = userId
+
+	res := r.db.Clauses(clause.OnConflict{Columns: []clause.Column{{Name: "user_id"}}, DoNothing: true}).Create(attempt)
+	if res.Error != nil {
+		go logger.Error(repository.LockByUserId, res.Error)
+		return nil, restErrors.NewInternalServerError("something went wrong")
+	}
+
+	res = r.db.Clauses(clause.Locking{Strength: "UPDATE"}).Where("user_id = ?", userId).First(attempt)
+	if res.Error != nil {
+		go logger.Error(repository.LockByUserId, res.Error)
+		return nil, restErrors.NewInternalServerError("something went wrong")
+	}
+
+	return attempt, nil
+}
+
+func (r repository) Update(attempt *LoginAttempt) restErrors.IRestErr {
+	resp := r.db.Save(attempt)
+	if resp.Error != nil {
+		go logger.Error(repository.Update, resp.Error)
+		return restErrors.NewInternalServerError("something went wrong")
+	}
+
+	return nil
+}
+
+func (r repository) DeleteByUserId(userId string) restErrors.IRestErr {
+	resp := r.db.Where("user_id = ?", userId).Delete(new(LoginAttempt))
+	if resp.Error != nil {
+		go logger.Error(repository.DeleteByUserId, resp.Error)
+		return restErrors.NewInternalServerError("something went wrong")
+	}
+
+	return nil
+}
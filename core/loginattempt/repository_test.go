@@ -0,0 +1,120 @@
+package loginattempt
+
+import (
+	"fmt"
+	"github.com/google/uuid"
+	restErrors "github.com/kotalco/core-api/pkg/errors"
+	"github.com/kotalco/core-api/pkg/sqlclient"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"testing"
+)
+
+var (
+	repo = NewRepository()
+)
+
+func init() {
+	err := sqlclient.OpenDBConnection().AutoMigrate(new(LoginAttempt))
+	if err != nil {
+		panic(err.Error())
+	}
+}
+
+func cleanUp(attempt LoginAttempt) {
+	sqlclient.OpenDBConnection().Delete(attempt)
+}
+
+func TestRepository_Create(t *testing.T) {
+	t.Run("Create_Should_Pass", func(t *testing.T) {
+		attempt := createAttempt(t)
+		assert.EqualValues(t, 0, attempt.FailedAttempts)
+		cleanUp(attempt)
+	})
+}
+
+func TestRepository_GetByUserId(t *testing.T) {
+	t.Run("Get_By_User_Id_Should_Pass", func(t *testing.T) {
+		attempt := createAttempt(t)
+		result, restErr := repo.WithoutTransaction().GetByUserId(attempt.UserId)
+		assert.Nil(t, restErr)
+		assert.EqualValues(t, attempt.ID, result.ID)
+		cleanUp(attempt)
+	})
+	t.Run("Get_By_User_Id_Should_Throw_If_Record_Does't_Exit", func(t *testing.T) {
+		attempt, restErr := repo.WithoutTransaction().GetByUserId("")
+		assert.Nil(t, attempt)
+		assert.EqualValues(t, fmt.Sprintf("can't find login attempts with userId %s", ""), restErr.Error())
+		assert.EqualValues(t, http.StatusNotFound, restErr.StatusCode())
+	})
+}
+
+func TestRepository_Update(t *testing.T) {
+	t.Run("Update_Should_Pass", func(t *testing.T) {
+		attempt := createAttempt(t)
+		attempt.FailedAttempts = 2
+
+		restErr := repo.WithoutTransaction().Update(&attempt)
+		assert.Nil(t, restErr)
+
+		result, _ := repo.WithoutTransaction().GetByUserId(attempt.UserId)
+		assert.EqualValues(t, 2, result.FailedAttempts)
+		cleanUp(attempt)
+	})
+}
+
+func TestRepository_LockByUserId(t *testing.T) {
+	t.Run("Lock_By_User_Id_Should_Return_Existing_Record", func(t *testing.T) {
+		attempt := createAttempt(t)
+		restErr := repo.WithoutTransaction().Transaction(func(repository IRepository) restErrors.IRestErr {
+			result, restErr := repository.LockByUserId(attempt.UserId)
+			assert.Nil(t, restErr)
+			assert.EqualValues(t, attempt.ID, result.ID)
+			return nil
+		})
+		assert.Nil(t, restErr)
+		cleanUp(attempt)
+	})
+	t.Run("Lock_By_User_Id_Should_Create_Missing_Record", func(t *testing.T) {
+		var attempt *LoginAttempt
+		restErr := repo.WithoutTransaction().Transaction(func(repository IRepository) (restErr restErrors.IRestErr) {
+			attempt, restErr = repository.LockByUserId(uuid.NewString())
+			return
+		})
+		assert.Nil(t, restErr)
+		assert.EqualValues(t, 0, attempt.FailedAttempts)
+		cleanUp(*attempt)
+	})
+	t.Run("Transaction_Should_Rollback_If_Fn_Throws", func(t *testing.T) {
+		userId := uuid.NewString()
+		restErr := repo.WithoutTransaction().Transaction(func(repository IRepository) restErrors.IRestErr {
+			repository.LockByUserId(userId)
+			return restErrors.NewTooManyRequestsError("too many failed attempts, try again later", 0)
+		})
+		assert.EqualValues(t, http.StatusTooManyRequests, restErr.StatusCode())
+
+		_, restErr = repo.WithoutTransaction().GetByUserId(userId)
+		assert.EqualValues(t, http.StatusNotFound, restErr.StatusCode())
+	})
+}
+
+func TestRepository_DeleteByUserId(t *testing.T) {
+	t.Run("Delete_By_User_Id_Should_Pass", func(t *testing.T) {
+		attempt := createAttempt(t)
+
+		restErr := repo.WithoutTransaction().DeleteByUserId(attempt.UserId)
+		assert.Nil(t, restErr)
+
+		_, restErr = repo.WithoutTransaction().GetByUserId(attempt.UserId)
+		assert.EqualValues(t, http.StatusNotFound, restErr.StatusCode())
+	})
+}
+
+func createAttempt(t *testing.T) LoginAttempt {
+	attempt := new(LoginAttempt)
+	attempt.ID = uuid.NewString()
+	attempt.UserId = uuid.NewString()
+	restErr := repo.WithoutTransaction().Create(attempt)
+	assert.Nil(t, restErr)
+	return *attempt
+}
@@ -0,0 +1,133 @@
+package loginattempt
+
+import (
+	restErrors "github.com/kotalco/core-api/pkg/errors"
+	"gorm.io/gorm"
+	"net/http"
+	"time"
+)
+
+const (
+	// FreeAttempts is the number of failed attempts allowed before delaying the account
+	FreeAttempts = 3
+	// MaxAttempts is the number of failed attempts that locks the account
+	MaxAttempts = 10
+	// BaseDelay is the delay after the first failed attempt beyond FreeAttempts, doubled with every failure
+	BaseDelay = 1 * time.Second
+	// MaxDelay caps the progressive delay
+	MaxDelay = 1 * time.Minute
+	// LockoutDuration is how long the account stays locked after MaxAttempts
+	LockoutDuration = 15 * time.Minute
+	// FailuresWindow is the period after the last failure where failed attempts are still counted
+	FailuresWindow = 1 * time.Hour
+)
+
+type service struct{}
+
+type IService interface {
+	WithTransaction(txHandle *gorm.DB) IService
+	WithoutTransaction() IService
+	Attempt(userId string, verify func() bool) (*LoginAttempt, restErrors.IRestErr)
+	Reset(userId string) restErrors.IRestErr
+	IsLocked(userId string) (bool, restErrors.IRestErr)
+}
+
+var attemptRepository = NewRepository()
+
+func NewService() IService {
+	newService := &service{}
+	return newService
+}
+
+func (s service) WithTransaction(txHandle *gorm.DB) IService {
+	attemptRepository = attemptRepository.WithTransaction(txHandle)
+	return s
+}
+func (s service) WithoutTransaction() IService {
+	attemptRepository = attemptRepository.WithoutTransaction()
+	return s
+}
+
+// Attempt runs the sign in or totp verification of the account holding its login attempts row lock, concurrent attempts of the same account are serialized
+// the attempt is rejected if the account is locked or still in its progressive delay, otherwise a failed verification is recorded and returned
+func (service) Attempt(userId string, verify func() bool) (failed *LoginAttempt, restErr restErrors.IRestErr) {
+	restErr = attemptRepository.Transaction(func(repository IRepository) restErrors.IRestErr {
+		attempt, err := repository.LockByUserId(userId)
+		if err != nil {
+			return err
+		}
+
+		err = check(attempt, time.Now())
+		if err != nil {
+			return err
+		}
+
+		if verify() {
+			return nil
+		}
+
+		recordFailure(attempt, time.Now())
+		err = repository.Update(attempt)
+		if err != nil {
+			return err
+		}
+
+		failed = attempt
+		return nil
+	})
+	if restErr != nil {
+		return nil, restErr
+	}
+
+	return failed, nil
+}
+
+// check returns too many requests error if the account is locked or still in its progressive delay
+func check(attempt *LoginAttempt, now time.Time) restErrors.IRestErr {
+	if attempt.IsLocked(now) {
+		return restErrors.NewTooManyRequestsError("account temporarily locked, too many failed attempts", time.Unix(attempt.LockedUntil, 0).Sub(now))
+	}
+
+	if attempt.expired(now) {
+		return nil
+	}
+
+	nextAttemptAt := time.Unix(attempt.LastFailedAt, 0).Add(attempt.Delay())
+	if now.Before(nextAttemptAt) {
+		return restErrors.NewTooManyRequestsError("too many failed attempts, try again later", nextAttemptAt.Sub(now))
+	}
+
+	return nil
+}
+
+// recordFailure counts the failed attempt and locks the account once it reaches MaxAttempts
+func recordFailure(attempt *LoginAttempt, now time.Time) {
+	if attempt.expired(now) {
+		attempt.FailedAttempts = 0
+		attempt.LockedUntil = 0
+	}
+
+	attempt.FailedAttempts++
+	attempt.LastFailedAt = now.Unix()
+	if attempt.FailedAttempts >= MaxAttempts {
+		attempt.LockedUntil = now.Add(LockoutDuration).Unix()
+	}
+}
+
+// Reset clears the failed attempts of the account, used after successful sign in and to unlock accounts
+func (service) Reset(userId string) restErrors.IRestErr {
+	return attemptRepository.DeleteByUserId(userId)
+}
+
+// IsLocked checks if the account is currently locked
+func (service) IsLocked(userId string) (bool, restErrors.IRestErr) {
+	attempt, err := attemptRepository.GetByUserId(userId)
+	if err != nil {
+		if err.StatusCode() == http.StatusNotFound {
+			return false, nil
+		}
+		return false, err
+	}
+
+	return attempt.IsLocked(time.Now()), nil
+}
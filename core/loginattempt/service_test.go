@@ -0,0 +1,186 @@
+package loginattempt
+
+import (
+	restErrors "github.com/kotalco/core-api/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+	"net/http"
+	"os"
+	"testing"
+	"time"
+)
+
+var (
+	attemptService     IService
+	CreateFunc         func(attempt *LoginAttempt) restErrors.IRestErr
+	GetByUserIdFunc    func(userId string) (*LoginAttempt, restErrors.IRestErr)
+	LockByUserIdFunc   func(userId string) (*LoginAttempt, restErrors.IRestErr)
+	UpdateFunc         func(attempt *LoginAttempt) restErrors.IRestErr
+	DeleteByUserIdFunc func(userId string) restErrors.IRestErr
+)
+
+type attemptRepositoryMock struct{}
+
+func (r attemptRepositoryMock) WithTransaction(txHandle *gorm.DB) IRepository {
+	return r
+}
+func (r attemptRepositoryMock) WithoutTransaction() IRepository {
+	return r
+}
+
+func (r attemptRepositoryMock) Transaction(fn func(repository IRepository) restErrors.IRestErr) restErrors.IRestErr {
+	return fn(r)
+}
+func (attemptRepositoryMock) LockByUserId(userId string) (*LoginAttempt, restErrors.IRestErr) {
+	return LockByUserIdFunc(userId)
+}
+func (attemptRepositoryMock) Create(attempt *LoginAttempt) restErrors.IRestErr {
+	return CreateFunc(attempt)
+}
+func (attemptRepositoryMock) GetByUserId(userId string) (*LoginAttempt, restErrors.IRestErr) {
+	return GetByUserIdFunc(userId)
+}
+func (attemptRepositoryMock) Update(attempt *LoginAttempt) restErrors.IRestErr {
+	return UpdateFunc(attempt)
+}
+func (attemptRepositoryMock) DeleteByUserId(userId string) restErrors.IRestErr {
+	return DeleteByUserIdFunc(userId)
+}
+
+func TestMain(m *testing.M) {
+	attemptRepository = &attemptRepositoryMock{}
+	attemptService = NewService()
+	code := m.Run()
+	os.Exit(code)
+}
+
+func TestService_Attempt(t *testing.T) {
+	valid := func() bool { return true }
+	invalid := func() bool { return false }
+	UpdateFunc = func(attempt *LoginAttempt) restErrors.IRestErr {
+		return nil
+	}
+
+	t.Run("Attempt_Should_Pass_If_No_Failed_Attempts", func(t *testing.T) {
+		LockByUserIdFunc = func(userId string) (*LoginAttempt, restErrors.IRestErr) {
+			return &LoginAttempt{UserId: userId}, nil
+		}
+		failed, err := attemptService.Attempt("1", valid)
+		assert.Nil(t, err)
+		assert.Nil(t, failed)
+	})
+	t.Run("Attempt_Should_Pass_Within_Free_Attempts", func(t *testing.T) {
+		LockByUserIdFunc = func(userId string) (*LoginAttempt, restErrors.IRestErr) {
+			return &LoginAttempt{FailedAttempts: FreeAttempts, LastFailedAt: time.Now().Unix()}, nil
+		}
+		failed, err := attemptService.Attempt("1", valid)
+		assert.Nil(t, err)
+		assert.Nil(t, failed)
+	})
+	t.Run("Attempt_Should_Throw_If_Delay_Didn't_Pass", func(t *testing.T) {
+		LockByUserIdFunc = func(userId string) (*LoginAttempt, restErrors.IRestErr) {
+			return &LoginAttempt{FailedAttempts: FreeAttempts + 3, LastFailedAt: time.Now().Unix()}, nil
+		}
+		_, err := attemptService.Attempt("1", func() bool {
+			t.Fatal("credentials must not be verified during the delay")
+			return true
+		})
+		assert.EqualValues(t, http.StatusTooManyRequests, err.StatusCode())
+		assert.EqualValues(t, 4, restErrors.RetryAfter(err))
+	})
+	t.Run("Attempt_Should_Throw_If_Account_Locked", func(t *testing.T) {
+		LockByUserIdFunc = func(userId string) (*LoginAttempt, restErrors.IRestErr) {
+			return &LoginAttempt{FailedAttempts: MaxAttempts, LastFailedAt: time.Now().Unix(), LockedUntil: time.Now().Add(LockoutDuration).Unix()}, nil
+		}
+		_, err := attemptService.Attempt("1", valid)
+		assert.EqualValues(t, http.StatusTooManyRequests, err.StatusCode())
+		assert.EqualValues(t, "account temporarily locked, too many failed attempts", err.Error())
+	})
+	t.Run("Attempt_Should_Pass_If_Lockout_Is_Over", func(t *testing.T) {
+		LockByUserIdFunc = func(userId string) (*LoginAttempt, restErrors.IRestErr) {
+			return &LoginAttempt{FailedAttempts: MaxAttempts, LastFailedAt: time.Now().Add(-LockoutDuration).Unix(), LockedUntil: time.Now().Add(-time.Second).Unix()}, nil
+		}
+		failed, err := attemptService.Attempt("1", valid)
+		assert.Nil(t, err)
+		assert.Nil(t, failed)
+	})
+	t.Run("Attempt_Should_Record_First_Failure", func(t *testing.T) {
+		LockByUserIdFunc = func(userId string) (*LoginAttempt, restErrors.IRestErr) {
+			return &LoginAttempt{UserId: userId}, nil
+		}
+		failed, err := attemptService.Attempt("1", invalid)
+		assert.Nil(t, err)
+		assert.EqualValues(t, "1", failed.UserId)
+		assert.EqualValues(t, 1, failed.FailedAttempts)
+	})
+	t.Run("Attempt_Should_Lock_Account_After_Max_Attempts", func(t *testing.T) {
+		LockByUserIdFunc = func(userId string) (*LoginAttempt, restErrors.IRestErr) {
+			return &LoginAttempt{FailedAttempts: MaxAttempts - 1, LastFailedAt: time.Now().Add(-MaxDelay).Unix()}, nil
+		}
+		failed, err := attemptService.Attempt("1", invalid)
+		assert.Nil(t, err)
+		assert.True(t, failed.IsLocked(time.Now()))
+	})
+	t.Run("Attempt_Should_Reset_Counter_Out_Of_Failures_Window", func(t *testing.T) {
+		LockByUserIdFunc = func(userId string) (*LoginAttempt, restErrors.IRestErr) {
+			return &LoginAttempt{FailedAttempts: MaxAttempts - 1, LastFailedAt: time.Now().Add(-2 * FailuresWindow).Unix()}, nil
+		}
+		failed, err := attemptService.Attempt("1", invalid)
+		assert.Nil(t, err)
+		assert.EqualValues(t, 1, failed.FailedAttempts)
+		assert.False(t, failed.IsLocked(time.Now()))
+	})
+	t.Run("Attempt_Should_Throw_If_Repository_Throws", func(t *testing.T) {
+		LockByUserIdFunc = func(userId string) (*LoginAttempt, restErrors.IRestErr) {
+			return &LoginAttempt{}, nil
+		}
+		UpdateFunc = func(attempt *LoginAttempt) restErrors.IRestErr {
+			return restErrors.NewInternalServerError("something went wrong")
+		}
+		failed, err := attemptService.Attempt("1", invalid)
+		assert.Nil(t, failed)
+		assert.EqualValues(t, http.StatusInternalServerError, err.StatusCode())
+	})
+	t.Run("Attempt_Should_Throw_If_Lock_Throws", func(t *testing.T) {
+		LockByUserIdFunc = func(userId string) (*LoginAttempt, restErrors.IRestErr) {
+			return nil, restErrors.NewInternalServerError("something went wrong")
+		}
+		_, err := attemptService.Attempt("1", valid)
+		assert.EqualValues(t, http.StatusInternalServerError, err.StatusCode())
+	})
+}
+
+func TestService_Reset(t *testing.T) {
+	t.Run("Reset_Should_Pass", func(t *testing.T) {
+		DeleteByUserIdFunc = func(userId string) restErrors.IRestErr {
+			return nil
+		}
+		assert.Nil(t, attemptService.Reset("1"))
+	})
+}
+
+func TestService_IsLocked(t *testing.T) {
+	t.Run("Is_Locked_Should_Return_False_If_No_Failed_Attempts", func(t *testing.T) {
+		GetByUserIdFunc = func(userId string) (*LoginAttempt, restErrors.IRestErr) {
+			return nil, restErrors.NewNotFoundError("not found")
+		}
+		locked, err := attemptService.IsLocked("1")
+		assert.Nil(t, err)
+		assert.False(t, locked)
+	})
+	t.Run("Is_Locked_Should_Return_True_If_Locked", func(t *testing.T) {
+		GetByUserIdFunc = func(userId string) (*LoginAttempt, restErrors.IRestErr) {
+			return &LoginAttempt{LockedUntil: time.Now().Add(time.Minute).Unix()}, nil
+		}
+		locked, err := attemptService.IsLocked("1")
+		assert.Nil(t, err)
+		assert.True(t, locked)
+	})
+}
+
+func TestLoginAttempt_Delay(t *testing.T) {
+	assert.EqualValues(t, 0, LoginAttempt{FailedAttempts: FreeAttempts}.Delay())
+	assert.EqualValues(t, BaseDelay, LoginAttempt{FailedAttempts: FreeAttempts + 1}.Delay())
+	assert.EqualValues(t, 4*BaseDelay, LoginAttempt{FailedAttempts: FreeAttempts + 3}.Delay())
+	assert.EqualValues(t, MaxDelay, LoginAttempt{FailedAttempts: 100}.Delay())
+}
@@ -63,6 +63,7 @@ func (service nearService) Create(dto NearDto) (node nearv1alpha1.Node, restErr
 	}
 
 	k8s.DefaultResources(&node.Spec.Resources)
+	k8s.RequestedStorage(&node.Spec.Resources, dto.Resources)
 
 	if os.Getenv("MOCK") == "true" {
 		node.Default()
@@ -60,6 +60,7 @@ func (service polkadtoService) Create(dto PolkadotDto) (node polkadotv1alpha1.No
 	}
 
 	k8s.DefaultResources(&node.Spec.Resources)
+	k8s.RequestedStorage(&node.Spec.Resources, dto.Resources)
 
 	if os.Getenv("MOCK") == "true" {
 		node.Default()
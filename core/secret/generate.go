@@ -0,0 +1,389 @@
+package secret
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"github.com/google/uuid"
+	"github.com/kotalco/core-api/k8s"
+	restErrors "github.com/kotalco/core-api/pkg/errors"
+	"github.com/kotalco/core-api/pkg/logger"
+	"github.com/kotalco/core-api/pkg/security"
+	"golang.org/x/crypto/pbkdf2"
+	"golang.org/x/crypto/sha3"
+	"math/big"
+	"net"
+	"strings"
+	"time"
+)
+
+// generators, the secret types generated server side
+const (
+	Secp256k1Generator      = "secp256k1"
+	Ed25519Generator        = "ed25519"
+	BLSKeystoreGenerator    = "bls_keystore"
+	RPCPasswordGenerator    = "rpc_password"
+	JWTSecretGenerator      = "jwt_secret"
+	TLSCertificateGenerator = "tls_certificate"
+	// IPFSClusterSecretGenerator generates the secret shared by the ipfs cluster peers of a cluster
+	IPFSClusterSecretGenerator = "ipfs_cluster_secret"
+)
+
+// ed25519 key formats, hex is read by polkadot and aptos nodes, near by near nodes and libp2p by ipfs cluster peers
+const (
+	HexFormat    = "hex"
+	NearFormat   = "near"
+	Libp2pFormat = "libp2p"
+)
+
+const (
+	// generatedPasswordLength is the length of the generated rpc and keystore passwords
+	generatedPasswordLength = 32
+	// keystorePBKDF2Iterations is the EIP-2335 keystore pbkdf2 kdf iterations count
+	keystorePBKDF2Iterations = 262144
+	// certificateValidity is the validity of the generated self-signed certificates
+	certificateValidity = 365 * 24 * time.Hour
+)
+
+// GenerateSecretDto is the request to generate a secret server side
+type GenerateSecretDto struct {
+	k8s.MetaDataDto
+	// Type is the generator, decides the generated secret key type
+	Type string `json:"type"`
+	// Format is the ed25519 private key format, defaults to hex
+	Format string `json:"format"`
+	// Hosts are the dns names and ip addresses of the tls certificate, defaults to the secret name
+	Hosts []string `json:"hosts"`
+	// Provider keeps the secret data, defaults to the workspace secret provider
+	Provider string `json:"provider"`
+}
+
+// GeneratedSecretDto is the generated secret with its public material, the private material is never returned
+type GeneratedSecretDto struct {
+	SecretDto
+	Public map[string]string `json:"public"`
+}
+
+// generated is the generated secret data to be stored and the public material to be returned
+type generated struct {
+	keyType string
+	data    map[string]string
+	public  map[string]string
+}
+
+var generators = map[string]func(dto GenerateSecretDto) (generated, error){
+	Secp256k1Generator:         generateSecp256k1,
+	Ed25519Generator:           generateEd25519,
+	BLSKeystoreGenerator:       generateBLSKeystore,
+	RPCPasswordGenerator:       generateRPCPassword,
+	JWTSecretGenerator:         generateJWTSecret,
+	TLSCertificateGenerator:    generateTLSCertificate,
+	IPFSClusterSecretGenerator: generateIPFSClusterSecret,
+}
+
+// Validate validates the secret name, the generator and the ed25519 key format
+func (dto GenerateSecretDto) Validate() restErrors.IRestErr {
+	if err := dto.MetaDataDto.Validate(); err != nil {
+		return err
+	}
+
+	fields := map[string]string{}
+	if _, ok := generators[dto.Type]; !ok {
+		fields["type"] = "type must be one of secp256k1, ed25519, bls_keystore, rpc_password, jwt_secret, tls_certificate or ipfs_cluster_secret"
+	}
+	if dto.Type == Ed25519Generator {
+		switch dto.Format {
+		case "", HexFormat, NearFormat, Libp2pFormat:
+		default:
+			fields["format"] = "format must be one of hex, near or libp2p"
+		}
+	}
+	if len(fields) > 0 {
+		return restErrors.NewValidationError(fields)
+	}
+	return nil
+}
+
+// Generate generates the secret private material and stores it as a secret, returns the public material only
+func (service secretService) Generate(dto GenerateSecretDto) (result GeneratedSecretDto, restErr restErrors.IRestErr) {
+	generate, ok := generators[dto.Type]
+	if !ok {
+		restErr = restErrors.NewBadRequestError(fmt.Sprintf("can't generate secrets of type %s", dto.Type))
+		return
+	}
+
+	secret, err := generate(dto)
+	if err != nil {
+		go logger.Error(service.Generate, err)
+		restErr = restErrors.NewInternalServerError(fmt.Sprintf("can't generate %s secret", dto.Type))
+		return
+	}
+
+	model, restErr := service.Create(SecretDto{MetaDataDto: dto.MetaDataDto, Type: secret.keyType, Data: secret.data, Provider: dto.Provider})
+	if restErr != nil {
+		return
+	}
+
+	result.SecretDto = SecretDto{}.FromCoreSecret(model)
+	result.Public = secret.public
+	return
+}
+
+// generateSecp256k1 generates an ethereum node key, the public material is the account address and the enode id
+func generateSecp256k1(dto GenerateSecretDto) (secret generated, err error) {
+	privateKey, publicKey, err := security.GenerateSecp256k1Key()
+	if err != nil {
+		return
+	}
+
+	hash := sha3.NewLegacyKeccak256()
+	hash.Write(publicKey[1:])
+	address := hash.Sum(nil)[12:]
+
+	secret.keyType = PrivateKeyType
+	secret.data = map[string]string{"key": hex.EncodeToString(privateKey)}
+	secret.public = map[string]string{
+		"address":   "0x" + hex.EncodeToString(address),
+		"publicKey": "0x" + hex.EncodeToString(publicKey),
+		"enodeId":   hex.EncodeToString(publicKey[1:]),
+	}
+	return
+}
+
+// generateEd25519 generates an ed25519 node key in the given format, the public material is the public key and the libp2p peer id
+func generateEd25519(dto GenerateSecretDto) (secret generated, err error) {
+	privateKey, publicKey, err := security.GenerateEd25519Key()
+	if err != nil {
+		return
+	}
+
+	secret.keyType = PrivateKeyType
+	secret.public = map[string]string{
+		"publicKey": hex.EncodeToString(publicKey),
+		"peerId":    base58Encode(libp2pIdentity(publicKey)),
+	}
+
+	switch dto.Format {
+	case NearFormat:
+		nodeKey, _ := json.Marshal(map[string]string{
+			"account_id": "node",
+			"public_key": "ed25519:" + base58Encode(publicKey),
+			"secret_key": "ed25519:" + base58Encode(privateKey),
+		})
+		secret.data = map[string]string{"key": string(nodeKey)}
+		secret.public["publicKey"] = "ed25519:" + base58Encode(publicKey)
+	case Libp2pFormat:
+		// libp2p PrivateKey protobuf message with Ed25519 key type
+		message := append([]byte{0x08, 0x01, 0x12, byte(len(privateKey))}, privateKey...)
+		secret.data = map[string]string{"key": base64.StdEncoding.EncodeToString(message)}
+	default:
+		secret.data = map[string]string{"key": hex.EncodeToString(privateKey.Seed())}
+	}
+	return
+}
+
+// generateBLSKeystore generates a validator BLS key encrypted in an EIP-2335 keystore with a random password, the public material is the validator public key
+func generateBLSKeystore(dto GenerateSecretDto) (secret generated, err error) {
+	secretKey, publicKey, err := security.GenerateBLSKey()
+	if err != nil {
+		return
+	}
+	password, err := security.GenerateSecureString(generatedPasswordLength)
+	if err != nil {
+		return
+	}
+
+	keystore, err := encryptKeystore(secretKey, publicKey, password)
+	if err != nil {
+		return
+	}
+
+	secret.keyType = KeystoreType
+	secret.data = map[string]string{"keystore": string(keystore), "password": password}
+	secret.public = map[string]string{"publicKey": "0x" + hex.EncodeToString(publicKey)}
+	return
+}
+
+// encryptKeystore encrypts the BLS secret key in an EIP-2335 keystore using pbkdf2 and aes-128-ctr
+func encryptKeystore(secretKey []byte, publicKey []byte, password string) ([]byte, error) {
+	salt, err := security.GenerateRandomBytes(32)
+	if err != nil {
+		return nil, err
+	}
+	iv, err := security.GenerateRandomBytes(aes.BlockSize)
+	if err != nil {
+		return nil, err
+	}
+
+	decryptionKey := pbkdf2.Key([]byte(password), salt, keystorePBKDF2Iterations, 32, sha256.New)
+	block, err := aes.NewCipher(decryptionKey[:16])
+	if err != nil {
+		return nil, err
+	}
+	cipherText := make([]byte, len(secretKey))
+	cipher.NewCTR(block, iv).XORKeyStream(cipherText, secretKey)
+	checksum := sha256.Sum256(append(append([]byte{}, decryptionKey[16:32]...), cipherText...))
+
+	return json.Marshal(map[string]interface{}{
+		"crypto": map[string]interface{}{
+			"kdf": map[string]interface{}{
+				"function": "pbkdf2",
+				"params":   map[string]interface{}{"dklen": 32, "c": keystorePBKDF2Iterations, "prf": "hmac-sha256", "salt": hex.EncodeToString(salt)},
+				"message":  "",
+			},
+			"checksum": map[string]interface{}{
+				"function": "sha256",
+				"params":   map[string]interface{}{},
+				"message":  hex.EncodeToString(checksum[:]),
+			},
+			"cipher": map[string]interface{}{
+				"function": "aes-128-ctr",
+				"params":   map[string]interface{}{"iv": hex.EncodeToString(iv)},
+				"message":  hex.EncodeToString(cipherText),
+			},
+		},
+		"description": "generated by kotal",
+		"pubkey":      hex.EncodeToString(publicKey),
+		"path":        "",
+		"uuid":        uuid.NewString(),
+		"version":     4,
+	})
+}
+
+// generateRPCPassword generates a random password, there's no public material
+func generateRPCPassword(dto GenerateSecretDto) (secret generated, err error) {
+	password, err := security.GenerateSecureString(generatedPasswordLength)
+	if err != nil {
+		return
+	}
+
+	secret.keyType = PasswordType
+	secret.data = map[string]string{"password": password}
+	secret.public = map[string]string{}
+	return
+}
+
+// generateJWTSecret generates the execution and consensus clients engine api jwt secret, there's no public material
+func generateJWTSecret(dto GenerateSecretDto) (secret generated, err error) {
+	jwt, err := security.GenerateRandomBytes(32)
+	if err != nil {
+		return
+	}
+
+	secret.keyType = JWTSecretType
+	secret.data = map[string]string{"secret": hex.EncodeToString(jwt)}
+	secret.public = map[string]string{}
+	return
+}
+
+// generateIPFSClusterSecret generates the ipfs cluster secret, there's no public material
+func generateIPFSClusterSecret(dto GenerateSecretDto) (secret generated, err error) {
+	clusterSecret, err := security.GenerateRandomBytes(32)
+	if err != nil {
+		return
+	}
+
+	secret.keyType = IPFSClusterSecretType
+	secret.data = map[string]string{"secret": hex.EncodeToString(clusterSecret)}
+	secret.public = map[string]string{}
+	return
+}
+
+// generateTLSCertificate generates a self-signed ecdsa certificate for the hosts, the public material is the certificate and its fingerprint
+func generateTLSCertificate(dto GenerateSecretDto) (secret generated, err error) {
+	privateKey, publicKey, err := security.NewEllipticCurve().GenerateKeys()
+	if err != nil {
+		return
+	}
+	serial, err := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 128))
+	if err != nil {
+		return
+	}
+
+	hosts := dto.Hosts
+	if len(hosts) == 0 {
+		hosts = []string{dto.Name}
+	}
+	now := time.Now()
+	template := x509.Certificate{
+		SerialNumber:          serial,
+		Subject:               pkix.Name{CommonName: hosts[0], Organization: []string{"kotal"}},
+		NotBefore:             now,
+		NotAfter:              now.Add(certificateValidity),
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageKeyEncipherment,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+		BasicConstraintsValid: true,
+	}
+	for _, host := range hosts {
+		if ip := net.ParseIP(host); ip != nil {
+			template.IPAddresses = append(template.IPAddresses, ip)
+		} else {
+			template.DNSNames = append(template.DNSNames, host)
+		}
+	}
+
+	certificate, err := x509.CreateCertificate(rand.Reader, &template, &template, publicKey, privateKey)
+	if err != nil {
+		return
+	}
+	key, err := x509.MarshalECPrivateKey(privateKey)
+	if err != nil {
+		return
+	}
+
+	certificatePEM := string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: certificate}))
+	fingerprint := sha256.Sum256(certificate)
+
+	secret.keyType = TLSCertificateType
+	secret.data = map[string]string{
+		"tls.crt": certificatePEM,
+		"tls.key": string(pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: key})),
+	}
+	secret.public = map[string]string{
+		"certificate": certificatePEM,
+		"fingerprint": strings.ToUpper(hex.EncodeToString(fingerprint[:])),
+		"notAfter":    template.NotAfter.UTC().Format(time.RFC3339),
+	}
+	return
+}
+
+// libp2pIdentity returns the libp2p peer id multihash of the ed25519 public key
+// the PublicKey protobuf message is short enough to be inlined with the identity multihash
+func libp2pIdentity(publicKey []byte) []byte {
+	message := append([]byte{0x08, 0x01, 0x12, byte(len(publicKey))}, publicKey...)
+	return append([]byte{0x00, byte(len(message))}, message...)
+}
+
+const base58Alphabet = "123456789ABCDEFGHJKLMNPQRSTUVWXYZabcdefghijkmnopqrstuvwxyz"
+
+// base58Encode encodes the bytes using the bitcoin base58 alphabet
+func base58Encode(input []byte) string {
+	number := new(big.Int).SetBytes(input)
+	base := big.NewInt(58)
+	mod := new(big.Int)
+
+	var result []byte
+	for number.Sign() > 0 {
+		number.DivMod(number, base, mod)
+		result = append(result, base58Alphabet[mod.Int64()])
+	}
+	for _, b := range input {
+		if b != 0 {
+			break
+		}
+		result = append(result, base58Alphabet[0])
+	}
+
+	for i, j := 0, len(result)-1; i < j; i, j = i+1, j-1 {
+		result[i], result[j] = result[j], result[i]
+	}
+	return string(result)
+}
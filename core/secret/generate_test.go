@@ -0,0 +1,181 @@
+package secret
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"github.com/decred/dcrd/dcrec/secp256k1/v4"
+	"github.com/kotalco/core-api/k8s"
+	restErrors "github.com/kotalco/core-api/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/crypto/pbkdf2"
+	corev1 "k8s.io/api/core/v1"
+	apiErrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"net/http"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"strings"
+	"testing"
+)
+
+// generate generates the secret and returns the created secret
+func generate(t *testing.T, dto GenerateSecretDto) (GeneratedSecretDto, corev1.Secret) {
+	var created corev1.Secret
+	k8sClientCreateFunc = func(obj client.Object) error {
+		created = *obj.(*corev1.Secret)
+		return nil
+	}
+
+	dto.MetaDataDto = k8s.MetaDataDto{Name: "generated", Namespace: "default"}
+	result, err := NewSecretService().Generate(dto)
+	assert.Nil(t, err)
+	return result, created
+}
+
+func TestGenerateSecretDto_Validate(t *testing.T) {
+	t.Run("validate_should_pass", func(t *testing.T) {
+		dto := GenerateSecretDto{MetaDataDto: k8s.MetaDataDto{Name: "my-key"}, Type: Ed25519Generator, Format: NearFormat}
+		assert.Nil(t, dto.Validate())
+	})
+	t.Run("validate_should_throw_if_unknown_type", func(t *testing.T) {
+		dto := GenerateSecretDto{MetaDataDto: k8s.MetaDataDto{Name: "my-key"}, Type: "rsa"}
+		err := dto.Validate()
+		assert.EqualValues(t, http.StatusBadRequest, err.StatusCode())
+		assert.Contains(t, err.(restErrors.RestErr).Validations["type"], "type must be one of")
+	})
+	t.Run("validate_should_throw_if_unknown_format", func(t *testing.T) {
+		dto := GenerateSecretDto{MetaDataDto: k8s.MetaDataDto{Name: "my-key"}, Type: Ed25519Generator, Format: "pem"}
+		err := dto.Validate()
+		assert.EqualValues(t, http.StatusBadRequest, err.StatusCode())
+	})
+}
+
+func TestService_Generate(t *testing.T) {
+	t.Run("generate_secp256k1_should_return_address_and_enode_id", func(t *testing.T) {
+		result, created := generate(t, GenerateSecretDto{Type: Secp256k1Generator})
+
+		assert.EqualValues(t, PrivateKeyType, result.Type)
+		assert.Nil(t, SecretDto{Type: PrivateKeyType, Data: created.StringData}.ValidateData())
+
+		privateKey, _ := hex.DecodeString(created.StringData["key"])
+		publicKey := secp256k1.PrivKeyFromBytes(privateKey).PubKey().SerializeUncompressed()
+		assert.EqualValues(t, hex.EncodeToString(publicKey[1:]), result.Public["enodeId"])
+		assert.Regexp(t, "^0x[0-9a-f]{40}$", result.Public["address"])
+		assert.Nil(t, result.Data)
+	})
+
+	t.Run("generate_ed25519_should_return_peer_id", func(t *testing.T) {
+		result, created := generate(t, GenerateSecretDto{Type: Ed25519Generator})
+
+		assert.Len(t, created.StringData["key"], 64)
+		assert.True(t, strings.HasPrefix(result.Public["peerId"], "12D3KooW"))
+	})
+
+	t.Run("generate_ed25519_should_store_near_node_key", func(t *testing.T) {
+		result, created := generate(t, GenerateSecretDto{Type: Ed25519Generator, Format: NearFormat})
+
+		nodeKey := map[string]string{}
+		assert.Nil(t, json.Unmarshal([]byte(created.StringData["key"]), &nodeKey))
+		assert.EqualValues(t, result.Public["publicKey"], nodeKey["public_key"])
+		assert.True(t, strings.HasPrefix(nodeKey["secret_key"], "ed25519:"))
+	})
+
+	t.Run("generate_ed25519_should_store_libp2p_private_key", func(t *testing.T) {
+		_, created := generate(t, GenerateSecretDto{Type: Ed25519Generator, Format: Libp2pFormat})
+
+		message, err := base64.StdEncoding.DecodeString(created.StringData["key"])
+		assert.Nil(t, err)
+		assert.Len(t, message, 68)
+		assert.EqualValues(t, []byte{0x08, 0x01, 0x12, 0x40}, message[:4])
+	})
+
+	t.Run("generate_bls_keystore_should_be_decryptable_with_the_password", func(t *testing.T) {
+		result, created := generate(t, GenerateSecretDto{Type: BLSKeystoreGenerator})
+
+		assert.EqualValues(t, KeystoreType, result.Type)
+		assert.Nil(t, SecretDto{Type: KeystoreType, Data: created.StringData}.ValidateData())
+
+		keystore := struct {
+			Crypto struct {
+				KDF struct {
+					Params struct {
+						C    int    `json:"c"`
+						Salt string `json:"salt"`
+					} `json:"params"`
+				} `json:"kdf"`
+				Checksum struct {
+					Message string `json:"message"`
+				} `json:"checksum"`
+				Cipher struct {
+					Params struct {
+						IV string `json:"iv"`
+					} `json:"params"`
+					Message string `json:"message"`
+				} `json:"cipher"`
+			} `json:"crypto"`
+			Pubkey string `json:"pubkey"`
+		}{}
+		assert.Nil(t, json.Unmarshal([]byte(created.StringData["keystore"]), &keystore))
+		assert.EqualValues(t, result.Public["publicKey"], "0x"+keystore.Pubkey)
+
+		salt, _ := hex.DecodeString(keystore.Crypto.KDF.Params.Salt)
+		iv, _ := hex.DecodeString(keystore.Crypto.Cipher.Params.IV)
+		cipherText, _ := hex.DecodeString(keystore.Crypto.Cipher.Message)
+		decryptionKey := pbkdf2.Key([]byte(created.StringData["password"]), salt, keystore.Crypto.KDF.Params.C, 32, sha256.New)
+		checksum := sha256.Sum256(append(decryptionKey[16:32], cipherText...))
+		assert.EqualValues(t, keystore.Crypto.Checksum.Message, hex.EncodeToString(checksum[:]))
+
+		block, _ := aes.NewCipher(decryptionKey[:16])
+		secretKey := make([]byte, len(cipherText))
+		cipher.NewCTR(block, iv).XORKeyStream(secretKey, cipherText)
+		assert.Len(t, secretKey, 32)
+	})
+
+	t.Run("generate_rpc_password_should_not_return_public_material", func(t *testing.T) {
+		result, created := generate(t, GenerateSecretDto{Type: RPCPasswordGenerator})
+
+		assert.EqualValues(t, PasswordType, result.Type)
+		assert.Len(t, created.StringData["password"], generatedPasswordLength)
+		assert.Empty(t, result.Public)
+	})
+
+	t.Run("generate_jwt_secret_should_be_valid", func(t *testing.T) {
+		_, created := generate(t, GenerateSecretDto{Type: JWTSecretGenerator})
+
+		assert.Nil(t, SecretDto{Type: JWTSecretType, Data: created.StringData}.ValidateData())
+	})
+
+	t.Run("generate_ipfs_cluster_secret_should_be_valid", func(t *testing.T) {
+		result, created := generate(t, GenerateSecretDto{Type: IPFSClusterSecretGenerator})
+
+		assert.EqualValues(t, IPFSClusterSecretType, result.Type)
+		assert.Nil(t, SecretDto{Type: IPFSClusterSecretType, Data: created.StringData}.ValidateData())
+	})
+
+	t.Run("generate_tls_certificate_should_return_the_certificate_only", func(t *testing.T) {
+		result, created := generate(t, GenerateSecretDto{Type: TLSCertificateGenerator, Hosts: []string{"node.kotal.io", "10.0.0.1"}})
+
+		assert.Nil(t, SecretDto{Type: TLSCertificateType, Data: created.StringData}.ValidateData())
+		assert.EqualValues(t, created.StringData["tls.crt"], result.Public["certificate"])
+		assert.NotContains(t, result.Public, "tls.key")
+	})
+
+	t.Run("generate_should_throw_if_secret_exists", func(t *testing.T) {
+		k8sClientCreateFunc = func(obj client.Object) error {
+			return apiErrors.NewAlreadyExists(schema.GroupResource{Resource: "secrets"}, obj.GetName())
+		}
+
+		_, err := NewSecretService().Generate(GenerateSecretDto{MetaDataDto: k8s.MetaDataDto{Name: "generated", Namespace: "default"}, Type: RPCPasswordGenerator})
+		assert.EqualValues(t, http.StatusBadRequest, err.StatusCode())
+		assert.EqualValues(t, "secret by name generated already exist", err.Error())
+	})
+}
+
+func TestBase58Encode(t *testing.T) {
+	assert.EqualValues(t, "", base58Encode(nil))
+	assert.EqualValues(t, "1112", base58Encode([]byte{0, 0, 0, 1}))
+	assert.EqualValues(t, "StV1DL6CwTryKyV", base58Encode([]byte("hello world")))
+}
@@ -0,0 +1,89 @@
+package secret
+
+import (
+	"context"
+	"fmt"
+	"github.com/kotalco/core-api/config"
+	restErrors "github.com/kotalco/core-api/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// secret providers, the workspace default provider keeps the data of the secrets created in the workspace
+const (
+	KubernetesProvider = "kubernetes"
+	VaultProvider      = "vault"
+)
+
+// ProviderSecret is the secret as kept by a provider, the version changes whenever the data changes
+type ProviderSecret struct {
+	Type    string
+	Data    map[string]string
+	Version string
+}
+
+// IProvider is the source of truth of the secrets data
+// the operator only reads kubernetes secrets, so the secrets of the other providers are materialized as kubernetes secrets and kept in sync
+type IProvider interface {
+	// Store saves the secret data and returns its version
+	Store(key types.NamespacedName, secret ProviderSecret) (string, restErrors.IRestErr)
+	// Load returns the secret data and its version
+	Load(key types.NamespacedName) (ProviderSecret, restErrors.IRestErr)
+	// Remove deletes the secret data with all its versions
+	Remove(key types.NamespacedName) restErrors.IRestErr
+}
+
+var providers = map[string]IProvider{
+	KubernetesProvider: kubernetesProvider{},
+}
+
+func init() {
+	if config.Environment.VaultAddress != "" {
+		providers[VaultProvider] = NewVaultProvider(config.Environment.VaultAddress, config.Environment.VaultToken, config.Environment.VaultKVMount, config.Environment.VaultPathPrefix)
+	}
+}
+
+// Provider returns the provider by name, secrets without provider are kept in kubernetes
+func Provider(name string) (IProvider, restErrors.IRestErr) {
+	if name == "" {
+		name = KubernetesProvider
+	}
+	provider, ok := providers[name]
+	if !ok {
+		return nil, restErrors.NewBadRequestError(fmt.Sprintf("secret provider %s isn't configured", name))
+	}
+	return provider, nil
+}
+
+// ProviderAvailable checks the provider is configured and can be picked as a workspace default provider
+func ProviderAvailable(name string) bool {
+	_, ok := providers[name]
+	return ok
+}
+
+// kubernetesProvider keeps the data in the kubernetes secret itself, there's nothing to materialize or sync
+type kubernetesProvider struct{}
+
+func (kubernetesProvider) Store(key types.NamespacedName, secret ProviderSecret) (string, restErrors.IRestErr) {
+	return "", nil
+}
+
+func (kubernetesProvider) Load(key types.NamespacedName) (secret ProviderSecret, restErr restErrors.IRestErr) {
+	model := corev1.Secret{}
+	if err := k8sClient.Get(context.Background(), key, &model); err != nil {
+		restErr = restErrors.NewNotFoundError(fmt.Sprintf("secret by name %s doesn't exist", key.Name))
+		return
+	}
+
+	secret.Type = model.Labels[KeyTypeLabel]
+	secret.Version = model.ResourceVersion
+	secret.Data = make(map[string]string, len(model.Data))
+	for name, value := range model.Data {
+		secret.Data[name] = string(value)
+	}
+	return
+}
+
+func (kubernetesProvider) Remove(key types.NamespacedName) restErrors.IRestErr {
+	return nil
+}
@@ -0,0 +1,170 @@
+package secret
+
+import (
+	"context"
+	"fmt"
+	restErrors "github.com/kotalco/core-api/pkg/errors"
+	"github.com/kotalco/core-api/pkg/logger"
+	"github.com/kotalco/core-api/pkg/roles"
+	aptosv1alpha1 "github.com/kotalco/kotal/apis/aptos/v1alpha1"
+	bitcoinv1alpha1 "github.com/kotalco/kotal/apis/bitcoin/v1alpha1"
+	chainlinkv1alpha1 "github.com/kotalco/kotal/apis/chainlink/v1alpha1"
+	ethereumv1alpha1 "github.com/kotalco/kotal/apis/ethereum/v1alpha1"
+	ethereum2v1alpha1 "github.com/kotalco/kotal/apis/ethereum2/v1alpha1"
+	ipfsv1alpha1 "github.com/kotalco/kotal/apis/ipfs/v1alpha1"
+	nearv1alpha1 "github.com/kotalco/kotal/apis/near/v1alpha1"
+	polkadotv1alpha1 "github.com/kotalco/kotal/apis/polkadot/v1alpha1"
+	stacksv1alpha1 "github.com/kotalco/kotal/apis/stacks/v1alpha1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"strings"
+)
+
+// ReferenceDto is a node referencing a secret, the resource is the node permission resource e.g. ethereum.nodes
+type ReferenceDto struct {
+	Resource string `json:"resource"`
+	Name     string `json:"name"`
+	Field    string `json:"field"`
+}
+
+// ReferenceIndex is the reverse reference index of the workspace secrets, the nodes referencing each secret by secret name
+type ReferenceIndex map[string][]ReferenceDto
+
+// Of returns the nodes referencing the secret
+func (index ReferenceIndex) Of(name string) []ReferenceDto {
+	if references, ok := index[name]; ok {
+		return references
+	}
+	return make([]ReferenceDto, 0)
+}
+
+// secretField is a node spec field holding a secret name
+type secretField struct {
+	field string
+	name  *string
+}
+
+// referrers are the node kinds that can reference secrets
+var referrers = []struct {
+	resource string
+	newList  func() client.ObjectList
+}{
+	{roles.EthereumNodes, func() client.ObjectList { return &ethereumv1alpha1.NodeList{} }},
+	{roles.Ethereum2BeaconNodes, func() client.ObjectList { return &ethereum2v1alpha1.BeaconNodeList{} }},
+	{roles.Ethereum2Validators, func() client.ObjectList { return &ethereum2v1alpha1.ValidatorList{} }},
+	{roles.IPFSPeers, func() client.ObjectList { return &ipfsv1alpha1.PeerList{} }},
+	{roles.IPFSClusterPeers, func() client.ObjectList { return &ipfsv1alpha1.ClusterPeerList{} }},
+	{roles.ChainlinkNodes, func() client.ObjectList { return &chainlinkv1alpha1.NodeList{} }},
+	{roles.NearNodes, func() client.ObjectList { return &nearv1alpha1.NodeList{} }},
+	{roles.PolkadotNodes, func() client.ObjectList { return &polkadotv1alpha1.NodeList{} }},
+	{roles.BitcoinNodes, func() client.ObjectList { return &bitcoinv1alpha1.NodeList{} }},
+	{roles.StacksNodes, func() client.ObjectList { return &stacksv1alpha1.NodeList{} }},
+	{roles.AptosNodes, func() client.ObjectList { return &aptosv1alpha1.NodeList{} }},
+}
+
+// secretFields returns the node spec fields holding secret names, empty fields are skipped
+func secretFields(obj client.Object) []secretField {
+	var fields []secretField
+	switch node := obj.(type) {
+	case *ethereumv1alpha1.Node:
+		fields = []secretField{{"nodePrivateKeySecretName", &node.Spec.NodePrivateKeySecretName}, {"jwtSecretName", &node.Spec.JWTSecretName}}
+		if node.Spec.Import != nil {
+			fields = append(fields, secretField{"import.privateKeySecretName", &node.Spec.Import.PrivateKeySecretName}, secretField{"import.passwordSecretName", &node.Spec.Import.PasswordSecretName})
+		}
+	case *ethereum2v1alpha1.BeaconNode:
+		fields = []secretField{{"jwtSecretName", &node.Spec.JWTSecretName}, {"certSecretName", &node.Spec.CertSecretName}}
+	case *ethereum2v1alpha1.Validator:
+		fields = []secretField{{"certSecretName", &node.Spec.CertSecretName}, {"walletPasswordSecret", &node.Spec.WalletPasswordSecret}}
+		for i := range node.Spec.Keystores {
+			fields = append(fields, secretField{fmt.Sprintf("keystores[%d].secretName", i), &node.Spec.Keystores[i].SecretName})
+		}
+	case *ipfsv1alpha1.Peer:
+		fields = []secretField{{"swarmKeySecretName", &node.Spec.SwarmKeySecretName}}
+	case *ipfsv1alpha1.ClusterPeer:
+		fields = []secretField{{"privateKeySecretName", &node.Spec.PrivateKeySecretName}, {"clusterSecretName", &node.Spec.ClusterSecretName}}
+	case *chainlinkv1alpha1.Node:
+		fields = []secretField{{"keystorePasswordSecretName", &node.Spec.KeystorePasswordSecretName}, {"apiCredentials.passwordSecretName", &node.Spec.APICredentials.PasswordSecretName}, {"certSecretName", &node.Spec.CertSecretName}}
+	case *nearv1alpha1.Node:
+		fields = []secretField{{"nodePrivateKeySecretName", &node.Spec.NodePrivateKeySecretName}, {"validatorSecretName", &node.Spec.ValidatorSecretName}}
+	case *polkadotv1alpha1.Node:
+		fields = []secretField{{"nodePrivateKeySecretName", &node.Spec.NodePrivateKeySecretName}}
+	case *bitcoinv1alpha1.Node:
+		for i := range node.Spec.RPCUsers {
+			fields = append(fields, secretField{fmt.Sprintf("rpcUsers[%d].passwordSecretName", i), &node.Spec.RPCUsers[i].PasswordSecretName})
+		}
+	case *stacksv1alpha1.Node:
+		fields = []secretField{{"bitcoinNode.rpcPasswordSecretName", &node.Spec.BitcoinNode.RpcPasswordSecretName}, {"seedPrivateKeySecretName", &node.Spec.SeedPrivateKeySecretName}, {"nodePrivateKeySecretName", &node.Spec.NodePrivateKeySecretName}}
+	case *aptosv1alpha1.Node:
+		fields = []secretField{{"nodePrivateKeySecretName", &node.Spec.NodePrivateKeySecretName}}
+	}
+
+	result := make([]secretField, 0, len(fields))
+	for _, field := range fields {
+		if *field.name != "" {
+			result = append(result, field)
+		}
+	}
+	return result
+}
+
+// referencingNode is a node referencing a secret with the fields referencing it
+type referencingNode struct {
+	resource string
+	node     client.Object
+	fields   []secretField
+}
+
+// referencingNodes lists the workspace nodes referencing the secret
+// it throws if a node kind can't be listed, the secret rotation must re-point every referencing node
+func referencingNodes(namespace string, secretName string) ([]referencingNode, restErrors.IRestErr) {
+	var result []referencingNode
+	unlisted := forEachNode(namespace, func(resource string, node client.Object) {
+		var fields []secretField
+		for _, field := range secretFields(node) {
+			if *field.name == secretName {
+				fields = append(fields, field)
+			}
+		}
+		if len(fields) > 0 {
+			result = append(result, referencingNode{resource: resource, node: node, fields: fields})
+		}
+	})
+	if len(unlisted) > 0 {
+		return nil, restErrors.NewInternalServerError(fmt.Sprintf("can't list %s referencing secrets", strings.Join(unlisted, ", ")))
+	}
+	return result, nil
+}
+
+// forEachNode calls fn with every workspace node that can reference secrets
+// the node kinds that can't be listed e.g. their CRD isn't installed are logged and skipped, their resources are returned
+func forEachNode(namespace string, fn func(resource string, node client.Object)) (unlisted []string) {
+	for _, referrer := range referrers {
+		list := referrer.newList()
+		if err := k8sClient.List(context.Background(), list, client.InNamespace(namespace)); err != nil {
+			go logger.Error(forEachNode, err)
+			unlisted = append(unlisted, referrer.resource)
+			continue
+		}
+
+		items, err := meta.ExtractList(list)
+		if err != nil {
+			go logger.Error(forEachNode, err)
+			unlisted = append(unlisted, referrer.resource)
+			continue
+		}
+		for _, item := range items {
+			if node, ok := item.(client.Object); ok {
+				fn(referrer.resource, node)
+			}
+		}
+	}
+	return
+}
+
+func (node referencingNode) references() []ReferenceDto {
+	result := make([]ReferenceDto, len(node.fields))
+	for i, field := range node.fields {
+		result[i] = ReferenceDto{Resource: node.resource, Name: node.node.GetName(), Field: field.field}
+	}
+	return result
+}
@@ -4,6 +4,19 @@ import (
 	"github.com/kotalco/core-api/k8s"
 	"github.com/kotalco/core-api/pkg/time"
 	corev1 "k8s.io/api/core/v1"
+	"strconv"
+)
+
+const (
+	// KeyTypeLabel holds the secret key type
+	KeyTypeLabel = "kotal.io/key-type"
+	// NameLabel and VersionLabel track the secret versions, rotating a secret creates its next version named <name>-v<version>
+	NameLabel    = "kotal.io/secret-name"
+	VersionLabel = "kotal.io/secret-version"
+	// ProviderLabel holds the provider keeping the secret data
+	ProviderLabel = "kotal.io/secret-provider"
+	// SyncedVersionAnnotation is the provider data version the secret was last synced to
+	SyncedVersionAnnotation = "kotal.io/secret-synced-version"
 )
 
 type SecretDto struct {
@@ -11,6 +24,17 @@ type SecretDto struct {
 	k8s.MetaDataDto
 	Type string            `json:"type"`
 	Data map[string]string `json:"data,omitempty"`
+	// Version is the secret version, 1 unless the secret is a rotation of another secret
+	Version int `json:"version"`
+	// Provider keeps the secret data, defaults to the workspace secret provider
+	Provider string `json:"provider"`
+	// Nodes are the nodes referencing the secret
+	Nodes []ReferenceDto `json:"nodes"`
+}
+
+// RotateSecretDto is the data of the new secret version
+type RotateSecretDto struct {
+	Data map[string]string `json:"data"`
 }
 
 type SecretsDto []SecretDto
@@ -18,11 +42,31 @@ type SecretsDto []SecretDto
 func (dto SecretDto) FromCoreSecret(s corev1.Secret) SecretDto {
 	dto.Name = s.Name
 	dto.Time = time.Time{CreatedAt: s.CreationTimestamp.UTC().Format(time.JavascriptISOString)}
-	dto.Type = s.Labels["kotal.io/key-type"]
+	dto.Type = s.Labels[KeyTypeLabel]
+	dto.Provider = s.Labels[ProviderLabel]
+	if dto.Provider == "" {
+		dto.Provider = KubernetesProvider
+	}
+	dto.Version, _ = strconv.Atoi(s.Labels[VersionLabel])
+	if dto.Version == 0 {
+		dto.Version = 1
+	}
+	if dto.Nodes == nil {
+		dto.Nodes = make([]ReferenceDto, 0)
+	}
 
 	return dto
 }
 
+// RevealData returns the secret data, only revealed to the workspace admins
+func (dto SecretDto) RevealData(s corev1.Secret) SecretDto {
+	dto.Data = make(map[string]string, len(s.Data))
+	for key, value := range s.Data {
+		dto.Data[key] = string(value)
+	}
+	return dto
+}
+
 func (secret SecretsDto) FromCoreSecret(secrets []corev1.Secret) SecretsDto {
 	result := make(SecretsDto, len(secrets))
 	for index, value := range secrets {
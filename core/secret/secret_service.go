@@ -12,7 +12,9 @@ import (
 	apiErrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
+	"net/http"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"strconv"
 )
 
 type secretService struct{}
@@ -20,9 +22,15 @@ type secretService struct{}
 type IService interface {
 	Get(name types.NamespacedName) (corev1.Secret, restErrors.IRestErr)
 	Create(SecretDto) (corev1.Secret, restErrors.IRestErr)
+	Generate(GenerateSecretDto) (GeneratedSecretDto, restErrors.IRestErr)
 	List(namespace string) (corev1.SecretList, restErrors.IRestErr)
 	Delete(*corev1.Secret) restErrors.IRestErr
 	Count(namespace string) (int, restErrors.IRestErr)
+	Rotate(secret corev1.Secret, data map[string]string) (corev1.Secret, []ReferenceDto, restErrors.IRestErr)
+	References(namespace string) (ReferenceIndex, restErrors.IRestErr)
+	ReferencesOf(key types.NamespacedName) ([]ReferenceDto, restErrors.IRestErr)
+	Materialize(namespace string, provider string) restErrors.IRestErr
+	Sync() restErrors.IRestErr
 }
 
 var (
@@ -49,31 +57,78 @@ func (service secretService) Get(namespacedName types.NamespacedName) (secret co
 
 // Create creates a secret from the given spec
 func (service secretService) Create(dto SecretDto) (secret corev1.Secret, restErr restErrors.IRestErr) {
+	return service.create(dto, dto.Name, 1)
+}
 
-	t := true
-	secret.ObjectMeta = metav1.ObjectMeta{
-		Name:      dto.Name,
-		Namespace: dto.Namespace,
-		Labels: map[string]string{
-			"kotal.io/key-type":            dto.Type,
-			"app.kubernetes.io/created-by": "kotal-api",
-		},
+// create creates the version of the secret named base, the data is stored by the secret provider
+// the secrets kept by kubernetes are immutable, the ones materialized from other providers are updated on sync
+func (service secretService) create(dto SecretDto, base string, version int) (secret corev1.Secret, restErr restErrors.IRestErr) {
+	if dto.Provider == "" {
+		dto.Provider = KubernetesProvider
+	}
+	provider, restErr := Provider(dto.Provider)
+	if restErr != nil {
+		return
+	}
+
+	key := types.NamespacedName{Namespace: dto.Namespace, Name: dto.Name}
+	providerSecret := ProviderSecret{Type: dto.Type, Data: dto.Data}
+	if dto.Provider != KubernetesProvider {
+		// the provider data of an existing secret must not be overwritten
+		if _, restErr = service.Get(key); restErr == nil {
+			restErr = restErrors.NewBadRequestError(fmt.Sprintf("secret by name %s already exist", dto.Name))
+			return
+		} else if restErr.StatusCode() != http.StatusNotFound {
+			return
+		}
+		if providerSecret.Version, restErr = provider.Store(key, providerSecret); restErr != nil {
+			return
+		}
+	}
+
+	secret = service.materialized(key, dto.Provider, providerSecret)
+	secret.Labels[NameLabel] = base
+	secret.Labels[VersionLabel] = strconv.Itoa(version)
+	if dto.Provider == KubernetesProvider {
+		t := true
+		secret.Immutable = &t
 	}
-	secret.StringData = dto.Data
-	secret.Immutable = &t
 
 	if err := k8sClient.Create(context.Background(), &secret); err != nil {
+		if dto.Provider != KubernetesProvider {
+			_ = provider.Remove(key)
+		}
 		if apiErrors.IsAlreadyExists(err) {
 			restErr = restErrors.NewBadRequestError(fmt.Sprintf("secret by name %s already exist", dto.Name))
 			return
 		}
-		go logger.Error(service.Create, err)
+		go logger.Error(service.create, err)
 		restErr = restErrors.NewInternalServerError("error creating secret")
 		return
 	}
 	return
 }
 
+// materialized returns the kubernetes secret holding the provider secret data
+func (service secretService) materialized(key types.NamespacedName, provider string, providerSecret ProviderSecret) (secret corev1.Secret) {
+	secret.ObjectMeta = metav1.ObjectMeta{
+		Name:      key.Name,
+		Namespace: key.Namespace,
+		Labels: map[string]string{
+			KeyTypeLabel:                   providerSecret.Type,
+			NameLabel:                      key.Name,
+			VersionLabel:                   "1",
+			ProviderLabel:                  provider,
+			"app.kubernetes.io/created-by": "kotal-api",
+		},
+	}
+	if provider != KubernetesProvider {
+		secret.Annotations = map[string]string{SyncedVersionAnnotation: providerSecret.Version}
+	}
+	secret.StringData = providerSecret.Data
+	return
+}
+
 // List returns all secrets
 func (service secretService) List(namespace string) (list corev1.SecretList, restErr restErrors.IRestErr) {
 	if err := k8sClient.List(context.Background(), &list, client.InNamespace(namespace), client.HasLabels{"app.kubernetes.io/created-by"}); err != nil {
@@ -85,23 +140,214 @@ func (service secretService) List(namespace string) (list corev1.SecretList, res
 	return
 }
 
-// Delete a single secret node by name
+// Delete a single secret node by name, the secret data is removed from its provider too
 func (service secretService) Delete(secret *corev1.Secret) (restErr restErrors.IRestErr) {
 	if err := k8sClient.Delete(context.Background(), secret); err != nil {
 		go logger.Error(service.Delete, err)
 		restErr = restErrors.NewInternalServerError(fmt.Sprintf("can't delete secret by name %s", secret.Name))
 		return
 	}
+
+	if name := secret.Labels[ProviderLabel]; name != "" && name != KubernetesProvider {
+		provider, restErr := Provider(name)
+		if restErr != nil {
+			return restErr
+		}
+		return provider.Remove(types.NamespacedName{Namespace: secret.Namespace, Name: secret.Name})
+	}
 	return
 }
 
 // Count counts secrets
 func (service secretService) Count(namespace string) (count int, restErr restErrors.IRestErr) {
 	secrets := &corev1.SecretList{}
-	if err := k8sClient.List(context.Background(), secrets, client.InNamespace(namespace), client.HasLabels{"kotal.io/key-type"}); err != nil {
+	if err := k8sClient.List(context.Background(), secrets, client.InNamespace(namespace), client.HasLabels{KeyTypeLabel}); err != nil {
 		go logger.Error(service.Count, err)
 		restErr = restErrors.NewInternalServerError("failed to get all secrets")
 		return
 	}
 	return len(secrets.Items), nil
 }
+
+// Rotate replaces the secret with a new version holding the new data and re-points the nodes referencing it to the new version
+// secrets are immutable, the previous version is kept to roll back to, the nodes are restored if any of them can't be re-pointed
+func (service secretService) Rotate(secret corev1.Secret, data map[string]string) (rotated corev1.Secret, references []ReferenceDto, restErr restErrors.IRestErr) {
+	dto := SecretDto{Type: secret.Labels[KeyTypeLabel], Data: data, Provider: secret.Labels[ProviderLabel]}
+	if restErr = dto.ValidateData(); restErr != nil {
+		return
+	}
+
+	base := secret.Labels[NameLabel]
+	if base == "" {
+		base = secret.Name
+	}
+	version, restErr := service.latestVersion(secret.Namespace, base)
+	if restErr != nil {
+		return
+	}
+
+	dto.Name = fmt.Sprintf("%s-v%d", base, version+1)
+	dto.Namespace = secret.Namespace
+	rotated, restErr = service.create(dto, base, version+1)
+	if restErr != nil {
+		return
+	}
+
+	nodes, restErr := referencingNodes(secret.Namespace, secret.Name)
+	if restErr != nil {
+		_ = service.Delete(&rotated)
+		return
+	}
+
+	references = make([]ReferenceDto, 0)
+	for i, node := range nodes {
+		for _, field := range node.fields {
+			*field.name = rotated.Name
+		}
+		if err := k8sClient.Update(context.Background(), node.node); err != nil {
+			go logger.Error(service.Rotate, err)
+			for _, repointed := range nodes[:i] {
+				for _, field := range repointed.fields {
+					*field.name = secret.Name
+				}
+				if err := k8sClient.Update(context.Background(), repointed.node); err != nil {
+					go logger.Error(service.Rotate, err)
+				}
+			}
+			_ = service.Delete(&rotated)
+			restErr = restErrors.NewInternalServerError(fmt.Sprintf("can't re-point %s %s to secret %s", node.resource, node.node.GetName(), rotated.Name))
+			return
+		}
+		references = append(references, node.references()...)
+	}
+
+	return
+}
+
+// latestVersion returns the latest version of the secret named base, secrets created before versioning are version 1
+func (service secretService) latestVersion(namespace string, base string) (int, restErrors.IRestErr) {
+	list := &corev1.SecretList{}
+	if err := k8sClient.List(context.Background(), list, client.InNamespace(namespace), client.MatchingLabels{NameLabel: base}); err != nil {
+		go logger.Error(service.latestVersion, err)
+		restErr := restErrors.NewInternalServerError(fmt.Sprintf("can't get versions of secret %s", base))
+		return 0, restErr
+	}
+
+	latest := 1
+	for _, secret := range list.Items {
+		if version, _ := strconv.Atoi(secret.Labels[VersionLabel]); version > latest {
+			latest = version
+		}
+	}
+	return latest, nil
+}
+
+// References builds the reverse reference index of the workspace secrets by scanning the specs of all the workspace nodes
+// the node kinds that can't be listed are skipped, so one failing kind doesn't fail the secrets listing
+func (service secretService) References(namespace string) (ReferenceIndex, restErrors.IRestErr) {
+	result := ReferenceIndex{}
+	forEachNode(namespace, func(resource string, node client.Object) {
+		for _, field := range secretFields(node) {
+			result[*field.name] = append(result[*field.name], ReferenceDto{Resource: resource, Name: node.GetName(), Field: field.field})
+		}
+	})
+	return result, nil
+}
+
+// ReferencesOf returns the nodes referencing the secret
+// unlike References it throws if a node kind can't be listed, it's used before deleting the secret
+func (service secretService) ReferencesOf(key types.NamespacedName) ([]ReferenceDto, restErrors.IRestErr) {
+	nodes, restErr := referencingNodes(key.Namespace, key.Name)
+	if restErr != nil {
+		return nil, restErr
+	}
+
+	result := make([]ReferenceDto, 0)
+	for _, node := range nodes {
+		result = append(result, node.references()...)
+	}
+	return result, nil
+}
+
+// Materialize creates the kubernetes secrets referenced by the workspace nodes from the workspace provider if they don't exist
+func (service secretService) Materialize(namespace string, providerName string) restErrors.IRestErr {
+	if providerName == "" || providerName == KubernetesProvider {
+		return nil
+	}
+	provider, restErr := Provider(providerName)
+	if restErr != nil {
+		return restErr
+	}
+
+	references, restErr := service.References(namespace)
+	if restErr != nil {
+		return restErr
+	}
+
+	for name := range references {
+		key := types.NamespacedName{Namespace: namespace, Name: name}
+		if _, restErr := service.Get(key); restErr == nil || restErr.StatusCode() != http.StatusNotFound {
+			continue
+		}
+
+		providerSecret, restErr := provider.Load(key)
+		if restErr != nil {
+			if restErr.StatusCode() != http.StatusNotFound {
+				return restErr
+			}
+			continue
+		}
+
+		secret := service.materialized(key, providerName, providerSecret)
+		if err := k8sClient.Create(context.Background(), &secret); err != nil && !apiErrors.IsAlreadyExists(err) {
+			go logger.Error(service.Materialize, err)
+			return restErrors.NewInternalServerError(fmt.Sprintf("can't materialize secret %s", name))
+		}
+	}
+
+	return nil
+}
+
+// Sync updates the kubernetes secrets materialized from providers whose data changed since the last sync
+func (service secretService) Sync() restErrors.IRestErr {
+	list := &corev1.SecretList{}
+	if err := k8sClient.List(context.Background(), list, client.HasLabels{ProviderLabel}); err != nil {
+		go logger.Error(service.Sync, err)
+		return restErrors.NewInternalServerError("failed to get materialized secrets")
+	}
+
+	for _, secret := range list.Items {
+		providerName := secret.Labels[ProviderLabel]
+		if providerName == KubernetesProvider {
+			continue
+		}
+		provider, restErr := Provider(providerName)
+		if restErr != nil {
+			continue
+		}
+
+		providerSecret, restErr := provider.Load(types.NamespacedName{Namespace: secret.Namespace, Name: secret.Name})
+		if restErr != nil {
+			go logger.Warn("SECRET_SYNC", fmt.Errorf("can't sync secret %s/%s: %s", secret.Namespace, secret.Name, restErr.Error()))
+			continue
+		}
+		if providerSecret.Version == secret.Annotations[SyncedVersionAnnotation] {
+			continue
+		}
+
+		secret := secret
+		if secret.Annotations == nil {
+			secret.Annotations = map[string]string{}
+		}
+		secret.Annotations[SyncedVersionAnnotation] = providerSecret.Version
+		secret.Data = make(map[string][]byte, len(providerSecret.Data))
+		for name, value := range providerSecret.Data {
+			secret.Data[name] = []byte(value)
+		}
+		if err := k8sClient.Update(context.Background(), &secret); err != nil {
+			go logger.Warn("SECRET_SYNC", fmt.Errorf("can't sync secret %s/%s: %s", secret.Namespace, secret.Name, err.Error()))
+		}
+	}
+
+	return nil
+}
@@ -1 +1,298 @@
 package secret
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"errors"
+	restErrors "github.com/kotalco/core-api/pkg/errors"
+	"github.com/kotalco/core-api/pkg/roles"
+	ethereumv1alpha1 "github.com/kotalco/kotal/apis/ethereum/v1alpha1"
+	ethereum2v1alpha1 "github.com/kotalco/kotal/apis/ethereum2/v1alpha1"
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"math/big"
+	"net/http"
+	"os"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"testing"
+	"time"
+)
+
+/*
+k8s client mocks
+*/
+var (
+	k8sClientGetFunc    func(key client.ObjectKey, obj client.Object) error
+	k8sClientListFunc   func(list client.ObjectList) error
+	k8sClientCreateFunc func(obj client.Object) error
+	k8sClientUpdateFunc func(obj client.Object) error
+	k8sClientDeleteFunc func(obj client.Object) error
+)
+
+type k8sClientMock struct{}
+
+func (k8sClientMock) Get(ctx context.Context, key client.ObjectKey, obj client.Object, opts ...client.GetOption) error {
+	return k8sClientGetFunc(key, obj)
+}
+func (k8sClientMock) List(ctx context.Context, list client.ObjectList, opts ...client.ListOption) error {
+	return k8sClientListFunc(list)
+}
+func (k8sClientMock) Create(ctx context.Context, obj client.Object, opts ...client.CreateOption) error {
+	return k8sClientCreateFunc(obj)
+}
+func (k8sClientMock) Delete(ctx context.Context, obj client.Object, opts ...client.DeleteOption) error {
+	return k8sClientDeleteFunc(obj)
+}
+func (k8sClientMock) Update(ctx context.Context, obj client.Object, opts ...client.UpdateOption) error {
+	return k8sClientUpdateFunc(obj)
+}
+func (k8sClientMock) Patch(ctx context.Context, obj client.Object, patch client.Patch, opts ...client.PatchOption) error {
+	return nil
+}
+func (k8sClientMock) DeleteAllOf(ctx context.Context, obj client.Object, opts ...client.DeleteAllOfOption) error {
+	return nil
+}
+
+func TestMain(m *testing.M) {
+	k8sClient = &k8sClientMock{}
+	k8sClientGetFunc = func(key client.ObjectKey, obj client.Object) error {
+		return nil
+	}
+
+	code := m.Run()
+	os.Exit(code)
+}
+
+// workspace returns a k8s client list func listing the given secrets and nodes
+func workspace(secrets []corev1.Secret, nodes []ethereumv1alpha1.Node, beaconNodes []ethereum2v1alpha1.BeaconNode) func(list client.ObjectList) error {
+	return func(list client.ObjectList) error {
+		switch list := list.(type) {
+		case *corev1.SecretList:
+			list.Items = secrets
+		case *ethereumv1alpha1.NodeList:
+			list.Items = nodes
+		case *ethereum2v1alpha1.BeaconNodeList:
+			list.Items = beaconNodes
+		}
+		return nil
+	}
+}
+
+func jwtSecret(name string, version string) corev1.Secret {
+	return corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default", Labels: map[string]string{
+		KeyTypeLabel: JWTSecretType,
+		NameLabel:    "my-jwt",
+		VersionLabel: version,
+	}}}
+}
+
+func tlsPair(t *testing.T) (string, string) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	assert.Nil(t, err)
+	template := x509.Certificate{SerialNumber: big.NewInt(1), Subject: pkix.Name{CommonName: "kotal"}, NotBefore: time.Now(), NotAfter: time.Now().Add(time.Hour)}
+	cert, err := x509.CreateCertificate(rand.Reader, &template, &template, &key.PublicKey, key)
+	assert.Nil(t, err)
+	encodedKey, err := x509.MarshalECPrivateKey(key)
+	assert.Nil(t, err)
+
+	return string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: cert})), string(pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: encodedKey}))
+}
+
+func TestSecretDto_ValidateData(t *testing.T) {
+	t.Run("validate data should throw if data is missing", func(t *testing.T) {
+		err := SecretDto{Type: PasswordType}.ValidateData()
+		assert.EqualValues(t, restErrors.NewValidationError(map[string]string{"data": "data is required"}), err)
+	})
+
+	t.Run("validate data should validate ecdsa private keys", func(t *testing.T) {
+		privateKey, _, err := ellipticCurve.GenerateKeys()
+		assert.Nil(t, err)
+		encoded, err := ellipticCurve.EncodePrivate(privateKey)
+		assert.Nil(t, err)
+
+		assert.Nil(t, SecretDto{Type: PrivateKeyType, Data: map[string]string{"key": encoded}}.ValidateData())
+		assert.Nil(t, SecretDto{Type: PrivateKeyType, Data: map[string]string{"key": "0x4c0883a69102937d6231471b5dbb6204fe5129617082792ae468d01a3f362318"}}.ValidateData())
+		err2 := SecretDto{Type: PrivateKeyType, Data: map[string]string{"key": "not a key"}}.ValidateData()
+		assert.EqualValues(t, http.StatusBadRequest, err2.StatusCode())
+	})
+
+	t.Run("validate data should validate keystores", func(t *testing.T) {
+		keystore := `{"crypto": {"kdf": {}, "checksum": {}, "cipher": {}}, "pubkey": "", "version": 4}`
+		assert.Nil(t, SecretDto{Type: KeystoreType, Data: map[string]string{"keystore": keystore, "password": "secret"}}.ValidateData())
+
+		err := SecretDto{Type: KeystoreType, Data: map[string]string{"keystore": "{}"}}.ValidateData()
+		assert.EqualValues(t, restErrors.NewValidationError(map[string]string{
+			"keystore": "keystore must be a valid EIP-2335 keystore json",
+			"password": "password is required",
+		}), err)
+	})
+
+	t.Run("validate data should validate tls certificates", func(t *testing.T) {
+		cert, key := tlsPair(t)
+		assert.Nil(t, SecretDto{Type: TLSCertificateType, Data: map[string]string{"tls.crt": cert, "tls.key": key}}.ValidateData())
+
+		_, otherKey := tlsPair(t)
+		err := SecretDto{Type: TLSCertificateType, Data: map[string]string{"tls.crt": cert, "tls.key": otherKey}}.ValidateData()
+		assert.EqualValues(t, http.StatusBadRequest, err.StatusCode())
+	})
+
+	t.Run("validate data should validate slashing protection interchanges", func(t *testing.T) {
+		assert.Nil(t, SecretDto{Type: SlashingProtectionType, Data: map[string]string{"interchange": `{"metadata":{"interchange_format_version":"5"},"data":[]}`}}.ValidateData())
+		err := SecretDto{Type: SlashingProtectionType, Data: map[string]string{"interchange": `{"data":[]}`}}.ValidateData()
+		assert.EqualValues(t, restErrors.NewValidationError(map[string]string{"interchange": "interchange must be a valid EIP-3076 slashing protection interchange json"}), err)
+	})
+
+	t.Run("validate data should validate jwt secrets", func(t *testing.T) {
+		assert.Nil(t, SecretDto{Type: JWTSecretType, Data: map[string]string{"secret": "0x7365637265747365637265747365637265747365637265747365637265747365"}}.ValidateData())
+		err := SecretDto{Type: JWTSecretType, Data: map[string]string{"secret": "short"}}.ValidateData()
+		assert.EqualValues(t, restErrors.NewValidationError(map[string]string{"secret": "secret must be 32 bytes hex encoded"}), err)
+	})
+
+	t.Run("validate data should pass unknown types", func(t *testing.T) {
+		assert.Nil(t, SecretDto{Type: "ipfs_swarm_key", Data: map[string]string{"key": "anything"}}.ValidateData())
+	})
+}
+
+func TestService_Rotate(t *testing.T) {
+	data := map[string]string{"secret": "7365637265747365637265747365637265747365637265747365637265747365"}
+	node := ethereumv1alpha1.Node{ObjectMeta: metav1.ObjectMeta{Name: "my-node", Namespace: "default"}}
+	node.Spec.JWTSecretName = "my-jwt-v2"
+	beaconNode := ethereum2v1alpha1.BeaconNode{ObjectMeta: metav1.ObjectMeta{Name: "my-beacon-node", Namespace: "default"}}
+	beaconNode.Spec.JWTSecretName = "my-jwt-v2"
+	k8sClientCreateFunc = func(obj client.Object) error {
+		return nil
+	}
+
+	t.Run("rotate should create the next version and re-point the nodes", func(t *testing.T) {
+		k8sClientListFunc = workspace([]corev1.Secret{jwtSecret("my-jwt", "1"), jwtSecret("my-jwt-v2", "2")}, []ethereumv1alpha1.Node{node}, []ethereum2v1alpha1.BeaconNode{beaconNode})
+		updated := map[string]string{}
+		k8sClientUpdateFunc = func(obj client.Object) error {
+			switch obj := obj.(type) {
+			case *ethereumv1alpha1.Node:
+				updated[obj.Name] = obj.Spec.JWTSecretName
+			case *ethereum2v1alpha1.BeaconNode:
+				updated[obj.Name] = obj.Spec.JWTSecretName
+			}
+			return nil
+		}
+
+		rotated, references, err := NewSecretService().Rotate(jwtSecret("my-jwt-v2", "2"), data)
+		assert.Nil(t, err)
+		assert.EqualValues(t, "my-jwt-v3", rotated.Name)
+		assert.EqualValues(t, "my-jwt", rotated.Labels[NameLabel])
+		assert.EqualValues(t, "3", rotated.Labels[VersionLabel])
+		assert.EqualValues(t, data, rotated.StringData)
+		assert.EqualValues(t, map[string]string{"my-node": "my-jwt-v3", "my-beacon-node": "my-jwt-v3"}, updated)
+		assert.EqualValues(t, []ReferenceDto{
+			{Resource: roles.EthereumNodes, Name: "my-node", Field: "jwtSecretName"},
+			{Resource: roles.Ethereum2BeaconNodes, Name: "my-beacon-node", Field: "jwtSecretName"},
+		}, references)
+	})
+
+	t.Run("rotate should throw validation error for invalid data", func(t *testing.T) {
+		_, _, err := NewSecretService().Rotate(jwtSecret("my-jwt", "1"), map[string]string{"secret": "short"})
+		assert.EqualValues(t, http.StatusBadRequest, err.StatusCode())
+	})
+
+	t.Run("rotate should restore the nodes and delete the new version if a node can't be re-pointed", func(t *testing.T) {
+		k8sClientListFunc = workspace([]corev1.Secret{jwtSecret("my-jwt-v2", "2")}, []ethereumv1alpha1.Node{node}, []ethereum2v1alpha1.BeaconNode{beaconNode})
+		updated := map[string]string{}
+		k8sClientUpdateFunc = func(obj client.Object) error {
+			switch obj := obj.(type) {
+			case *ethereumv1alpha1.Node:
+				updated[obj.Name] = obj.Spec.JWTSecretName
+			case *ethereum2v1alpha1.BeaconNode:
+				return errors.New("conflict")
+			}
+			return nil
+		}
+		deleted := ""
+		k8sClientDeleteFunc = func(obj client.Object) error {
+			deleted = obj.GetName()
+			return nil
+		}
+
+		_, _, err := NewSecretService().Rotate(jwtSecret("my-jwt-v2", "2"), data)
+		assert.EqualValues(t, http.StatusInternalServerError, err.StatusCode())
+		assert.EqualValues(t, "my-jwt-v2", updated["my-node"])
+		assert.EqualValues(t, "my-jwt-v3", deleted)
+	})
+	t.Run("rotate should delete the new version if a node kind can't be listed", func(t *testing.T) {
+		k8sClientListFunc = func(list client.ObjectList) error {
+			if _, ok := list.(*ethereum2v1alpha1.BeaconNodeList); ok {
+				return errors.New("no matches for kind BeaconNode")
+			}
+			return workspace([]corev1.Secret{jwtSecret("my-jwt-v2", "2")}, []ethereumv1alpha1.Node{node}, nil)(list)
+		}
+		deleted := ""
+		k8sClientDeleteFunc = func(obj client.Object) error {
+			deleted = obj.GetName()
+			return nil
+		}
+
+		_, _, err := NewSecretService().Rotate(jwtSecret("my-jwt-v2", "2"), data)
+		assert.EqualValues(t, http.StatusInternalServerError, err.StatusCode())
+		assert.EqualValues(t, "my-jwt-v3", deleted)
+	})
+}
+
+func TestService_References(t *testing.T) {
+	node := ethereumv1alpha1.Node{ObjectMeta: metav1.ObjectMeta{Name: "my-node", Namespace: "default"}}
+	node.Spec.NodePrivateKeySecretName = "my-key"
+	node.Spec.Import = &ethereumv1alpha1.ImportedAccount{PrivateKeySecretName: "my-key", PasswordSecretName: "my-password"}
+	k8sClientListFunc = workspace(nil, []ethereumv1alpha1.Node{node}, nil)
+
+	t.Run("references should group the referencing nodes by secret", func(t *testing.T) {
+		references, err := NewSecretService().References("default")
+		assert.Nil(t, err)
+		assert.EqualValues(t, ReferenceIndex{
+			"my-key": {
+				{Resource: roles.EthereumNodes, Name: "my-node", Field: "nodePrivateKeySecretName"},
+				{Resource: roles.EthereumNodes, Name: "my-node", Field: "import.privateKeySecretName"},
+			},
+			"my-password": {{Resource: roles.EthereumNodes, Name: "my-node", Field: "import.passwordSecretName"}},
+		}, references)
+		assert.Empty(t, references.Of("unreferenced"))
+		assert.NotNil(t, references.Of("unreferenced"))
+	})
+	t.Run("references should skip the node kinds that can't be listed", func(t *testing.T) {
+		k8sClientListFunc = func(list client.ObjectList) error {
+			if _, ok := list.(*ethereum2v1alpha1.BeaconNodeList); ok {
+				return errors.New("no matches for kind BeaconNode")
+			}
+			return workspace(nil, []ethereumv1alpha1.Node{node}, nil)(list)
+		}
+		references, err := NewSecretService().References("default")
+		assert.Nil(t, err)
+		assert.Len(t, references.Of("my-key"), 2)
+	})
+}
+
+func TestService_ReferencesOf(t *testing.T) {
+	node := ethereumv1alpha1.Node{ObjectMeta: metav1.ObjectMeta{Name: "my-node", Namespace: "default"}}
+	node.Spec.NodePrivateKeySecretName = "my-key"
+
+	t.Run("references of should return the nodes referencing the secret", func(t *testing.T) {
+		k8sClientListFunc = workspace(nil, []ethereumv1alpha1.Node{node}, nil)
+		references, err := NewSecretService().ReferencesOf(types.NamespacedName{Namespace: "default", Name: "my-key"})
+		assert.Nil(t, err)
+		assert.EqualValues(t, []ReferenceDto{{Resource: roles.EthereumNodes, Name: "my-node", Field: "nodePrivateKeySecretName"}}, references)
+	})
+	t.Run("references of should throw if a node kind can't be listed", func(t *testing.T) {
+		k8sClientListFunc = func(list client.ObjectList) error {
+			if _, ok := list.(*ethereum2v1alpha1.ValidatorList); ok {
+				return errors.New("no matches for kind Validator")
+			}
+			return workspace(nil, []ethereumv1alpha1.Node{node}, nil)(list)
+		}
+		_, err := NewSecretService().ReferencesOf(types.NamespacedName{Namespace: "default", Name: "my-key"})
+		assert.EqualValues(t, http.StatusInternalServerError, err.StatusCode())
+	})
+}
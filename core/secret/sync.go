@@ -0,0 +1,13 @@
+package secret
+
+import "time"
+
+// StartSync syncs the secrets materialized from providers every interval in the background
+func StartSync(interval time.Duration) {
+	go func() {
+		for {
+			_ = NewSecretService().Sync()
+			time.Sleep(interval)
+		}
+	}()
+}
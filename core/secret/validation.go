@@ -0,0 +1,130 @@
+package secret
+
+import (
+	"crypto/tls"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	restErrors "github.com/kotalco/core-api/pkg/errors"
+	"github.com/kotalco/core-api/pkg/security"
+	"strings"
+)
+
+// secret key types, the type is saved to the kotal.io/key-type label and decides the data keys the operator reads
+const (
+	PrivateKeyType     = "ethereum_privatekey"
+	KeystoreType       = "ethereum2_keystore"
+	PasswordType       = "password"
+	JWTSecretType      = "jwt_secret"
+	TLSCertificateType = "tls_certificate"
+	// IPFSClusterSecretType is the 32 bytes hex secret shared by the ipfs cluster peers of a cluster
+	IPFSClusterSecretType = "ipfs_cluster_secret"
+	// SlashingProtectionType holds the EIP-3076 slashing protection interchange exported when validator keys are removed
+	SlashingProtectionType = "slashing_protection"
+)
+
+var ellipticCurve = security.NewEllipticCurve()
+
+// dataValidators validate the secret data of the known key types, secrets of the other types are only required to have data
+var dataValidators = map[string]func(data map[string]string) map[string]string{
+	PrivateKeyType:         validatePrivateKey,
+	KeystoreType:           validateKeystore,
+	PasswordType:           validatePassword,
+	JWTSecretType:          validateJWTSecret,
+	TLSCertificateType:     validateTLSCertificate,
+	SlashingProtectionType: validateSlashingProtection,
+	IPFSClusterSecretType:  validateIPFSClusterSecret,
+}
+
+// ValidateData validates the secret data against its key type
+func (dto SecretDto) ValidateData() restErrors.IRestErr {
+	if len(dto.Data) == 0 {
+		return restErrors.NewValidationError(map[string]string{"data": "data is required"})
+	}
+
+	validate, ok := dataValidators[dto.Type]
+	if !ok {
+		return nil
+	}
+
+	if fields := validate(dto.Data); len(fields) > 0 {
+		return restErrors.NewValidationError(fields)
+	}
+	return nil
+}
+
+// validatePrivateKey accepts the hex encoded ASN.1 ecdsa private keys, and the raw 32 bytes hex keys used as secp256k1 node keys
+func validatePrivateKey(data map[string]string) map[string]string {
+	key := strings.TrimPrefix(data["key"], "0x")
+	if _, err := ellipticCurve.DecodePrivate(key); err == nil {
+		return nil
+	}
+	if raw, err := hex.DecodeString(key); err == nil && len(raw) == 32 {
+		return nil
+	}
+	return map[string]string{"key": "key must be a hex encoded ecdsa private key"}
+}
+
+// validateKeystore validates the EIP-2335 keystore and its password
+func validateKeystore(data map[string]string) map[string]string {
+	fields := map[string]string{}
+
+	keystore := struct {
+		Crypto  map[string]interface{} `json:"crypto"`
+		Pubkey  string                 `json:"pubkey"`
+		Version int                    `json:"version"`
+	}{}
+	if err := json.Unmarshal([]byte(data["keystore"]), &keystore); err != nil || keystore.Crypto == nil || keystore.Version != 4 {
+		fields["keystore"] = "keystore must be a valid EIP-2335 keystore json"
+	}
+	if data["password"] == "" {
+		fields["password"] = "password is required"
+	}
+
+	return fields
+}
+
+func validatePassword(data map[string]string) map[string]string {
+	if data["password"] == "" {
+		return map[string]string{"password": "password is required"}
+	}
+	return nil
+}
+
+// validateJWTSecret validates the engine api jwt secret is 32 bytes hex
+func validateJWTSecret(data map[string]string) map[string]string {
+	secret, err := hex.DecodeString(strings.TrimPrefix(data["secret"], "0x"))
+	if err != nil || len(secret) != 32 {
+		return map[string]string{"secret": "secret must be 32 bytes hex encoded"}
+	}
+	return nil
+}
+
+// validateIPFSClusterSecret validates the ipfs cluster secret is 32 bytes hex
+func validateIPFSClusterSecret(data map[string]string) map[string]string {
+	secret, err := hex.DecodeString(data["secret"])
+	if err != nil || len(secret) != 32 {
+		return map[string]string{"secret": "secret must be 32 bytes hex encoded"}
+	}
+	return nil
+}
+
+// validateTLSCertificate validates the certificate and the private key are a matching pem pair
+func validateTLSCertificate(data map[string]string) map[string]string {
+	if _, err := tls.X509KeyPair([]byte(data["tls.crt"]), []byte(data["tls.key"])); err != nil {
+		return map[string]string{"tls.crt": fmt.Sprintf("tls.crt and tls.key must be a valid pem certificate and key pair: %s", err.Error())}
+	}
+	return nil
+}
+
+// validateSlashingProtection validates the EIP-3076 slashing protection interchange has its metadata and data
+func validateSlashingProtection(data map[string]string) map[string]string {
+	interchange := struct {
+		Metadata map[string]interface{} `json:"metadata"`
+		Data     []interface{}          `json:"data"`
+	}{}
+	if err := json.Unmarshal([]byte(data["interchange"]), &interchange); err != nil || interchange.Metadata == nil || interchange.Data == nil {
+		return map[string]string{"interchange": "interchange must be a valid EIP-3076 slashing protection interchange json"}
+	}
+	return nil
+}
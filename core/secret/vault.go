@@ -0,0 +1,137 @@
+package secret
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	restErrors "github.com/kotalco/core-api/pkg/errors"
+	"github.com/kotalco/core-api/pkg/logger"
+	"io"
+	"k8s.io/apimachinery/pkg/types"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// keyTypeMetadata is the kv custom metadata holding the secret key type
+const keyTypeMetadata = "kotal-key-type"
+
+// vaultProvider keeps the secrets data in a HashiCorp Vault kv version 2 secrets engine under <prefix>/<namespace>/<name>
+type vaultProvider struct {
+	address string
+	token   string
+	mount   string
+	prefix  string
+	client  *http.Client
+}
+
+func NewVaultProvider(address string, token string, mount string, prefix string) IProvider {
+	return vaultProvider{
+		address: strings.TrimSuffix(address, "/"),
+		token:   token,
+		mount:   strings.Trim(mount, "/"),
+		prefix:  strings.Trim(prefix, "/"),
+		client:  &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+// url returns the kv api url of the secret, the api is either data or metadata
+func (vault vaultProvider) url(api string, key types.NamespacedName) string {
+	return fmt.Sprintf("%s/v1/%s/%s/%s/%s/%s", vault.address, vault.mount, api, vault.prefix, key.Namespace, key.Name)
+}
+
+// do sends the kv api request and decodes the response data into out if given
+func (vault vaultProvider) do(method string, url string, body interface{}, out interface{}) (int, error) {
+	var reader io.Reader
+	if body != nil {
+		payload, err := json.Marshal(body)
+		if err != nil {
+			return 0, err
+		}
+		reader = bytes.NewReader(payload)
+	}
+
+	req, err := http.NewRequest(method, url, reader)
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("X-Vault-Token", vault.token)
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := vault.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= http.StatusBadRequest {
+		message, _ := io.ReadAll(resp.Body)
+		return resp.StatusCode, fmt.Errorf("vault responded with %d: %s", resp.StatusCode, strings.TrimSpace(string(message)))
+	}
+	if out != nil && resp.StatusCode != http.StatusNoContent {
+		if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+			return resp.StatusCode, err
+		}
+	}
+	return resp.StatusCode, nil
+}
+
+// Store writes a new version of the secret data, and the key type to the secret custom metadata
+func (vault vaultProvider) Store(key types.NamespacedName, secret ProviderSecret) (string, restErrors.IRestErr) {
+	written := struct {
+		Data struct {
+			Version int `json:"version"`
+		} `json:"data"`
+	}{}
+	if _, err := vault.do(http.MethodPost, vault.url("data", key), map[string]interface{}{"data": secret.Data}, &written); err != nil {
+		go logger.Error(vault.Store, err)
+		return "", restErrors.NewInternalServerError(fmt.Sprintf("can't store secret %s in vault", key.Name))
+	}
+
+	metadata := map[string]interface{}{"custom_metadata": map[string]string{keyTypeMetadata: secret.Type}}
+	if _, err := vault.do(http.MethodPost, vault.url("metadata", key), metadata, nil); err != nil {
+		go logger.Error(vault.Store, err)
+		return "", restErrors.NewInternalServerError(fmt.Sprintf("can't store secret %s in vault", key.Name))
+	}
+
+	return strconv.Itoa(written.Data.Version), nil
+}
+
+// Load reads the latest version of the secret data
+func (vault vaultProvider) Load(key types.NamespacedName) (secret ProviderSecret, restErr restErrors.IRestErr) {
+	read := struct {
+		Data struct {
+			Data     map[string]string `json:"data"`
+			Metadata struct {
+				Version        int               `json:"version"`
+				CustomMetadata map[string]string `json:"custom_metadata"`
+			} `json:"metadata"`
+		} `json:"data"`
+	}{}
+
+	status, err := vault.do(http.MethodGet, vault.url("data", key), nil, &read)
+	if status == http.StatusNotFound || err == nil && read.Data.Data == nil {
+		restErr = restErrors.NewNotFoundError(fmt.Sprintf("secret by name %s doesn't exist in vault", key.Name))
+		return
+	}
+	if err != nil {
+		go logger.Error(vault.Load, err)
+		restErr = restErrors.NewInternalServerError(fmt.Sprintf("can't load secret %s from vault", key.Name))
+		return
+	}
+
+	secret.Data = read.Data.Data
+	secret.Type = read.Data.Metadata.CustomMetadata[keyTypeMetadata]
+	secret.Version = strconv.Itoa(read.Data.Metadata.Version)
+	return
+}
+
+// Remove deletes the secret metadata and all its versions
+func (vault vaultProvider) Remove(key types.NamespacedName) restErrors.IRestErr {
+	if status, err := vault.do(http.MethodDelete, vault.url("metadata", key), nil, nil); err != nil && status != http.StatusNotFound {
+		go logger.Error(vault.Remove, err)
+		return restErrors.NewInternalServerError(fmt.Sprintf("can't remove secret %s from vault", key.Name))
+	}
+	return nil
+}
@@ -0,0 +1,220 @@
+package secret
+
+import (
+	"encoding/json"
+	"github.com/kotalco/core-api/k8s"
+	ethereumv1alpha1 "github.com/kotalco/kotal/apis/ethereum/v1alpha1"
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	apiErrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"net/http"
+	"net/http/httptest"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"strings"
+	"testing"
+)
+
+// vaultDevServer is an in memory vault kv version 2 secrets engine mounted at secret
+type vaultDevServer struct {
+	versions map[string][]map[string]string
+	metadata map[string]map[string]string
+}
+
+func newVaultDevServer(t *testing.T) (*vaultDevServer, *httptest.Server) {
+	vault := &vaultDevServer{versions: map[string][]map[string]string{}, metadata: map[string]map[string]string{}}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.EqualValues(t, "root", r.Header.Get("X-Vault-Token"))
+
+		var path string
+		switch {
+		case strings.HasPrefix(r.URL.Path, "/v1/secret/data/"):
+			path = strings.TrimPrefix(r.URL.Path, "/v1/secret/data/")
+			switch r.Method {
+			case http.MethodPost:
+				body := struct {
+					Data map[string]string `json:"data"`
+				}{}
+				_ = json.NewDecoder(r.Body).Decode(&body)
+				vault.versions[path] = append(vault.versions[path], body.Data)
+				_ = json.NewEncoder(w).Encode(map[string]interface{}{"data": map[string]interface{}{"version": len(vault.versions[path])}})
+			case http.MethodGet:
+				versions, ok := vault.versions[path]
+				if !ok {
+					w.WriteHeader(http.StatusNotFound)
+					_, _ = w.Write([]byte(`{"errors":[]}`))
+					return
+				}
+				_ = json.NewEncoder(w).Encode(map[string]interface{}{"data": map[string]interface{}{
+					"data":     versions[len(versions)-1],
+					"metadata": map[string]interface{}{"version": len(versions), "custom_metadata": vault.metadata[path]},
+				}})
+			}
+		case strings.HasPrefix(r.URL.Path, "/v1/secret/metadata/"):
+			path = strings.TrimPrefix(r.URL.Path, "/v1/secret/metadata/")
+			switch r.Method {
+			case http.MethodPost:
+				body := struct {
+					CustomMetadata map[string]string `json:"custom_metadata"`
+				}{}
+				_ = json.NewDecoder(r.Body).Decode(&body)
+				vault.metadata[path] = body.CustomMetadata
+				w.WriteHeader(http.StatusNoContent)
+			case http.MethodDelete:
+				delete(vault.versions, path)
+				delete(vault.metadata, path)
+				w.WriteHeader(http.StatusNoContent)
+			}
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	return vault, server
+}
+
+func TestVaultProvider(t *testing.T) {
+	vault, server := newVaultDevServer(t)
+	defer server.Close()
+	provider := NewVaultProvider(server.URL+"/", "root", "secret", "kotal")
+	key := types.NamespacedName{Namespace: "default", Name: "my-password"}
+
+	t.Run("store should write a new version of the secret", func(t *testing.T) {
+		version, err := provider.Store(key, ProviderSecret{Type: PasswordType, Data: map[string]string{"password": "first"}})
+		assert.Nil(t, err)
+		assert.EqualValues(t, "1", version)
+
+		version, err = provider.Store(key, ProviderSecret{Type: PasswordType, Data: map[string]string{"password": "second"}})
+		assert.Nil(t, err)
+		assert.EqualValues(t, "2", version)
+		assert.Len(t, vault.versions["kotal/default/my-password"], 2)
+	})
+
+	t.Run("load should read the latest version of the secret", func(t *testing.T) {
+		secret, err := provider.Load(key)
+		assert.Nil(t, err)
+		assert.EqualValues(t, ProviderSecret{Type: PasswordType, Data: map[string]string{"password": "second"}, Version: "2"}, secret)
+	})
+
+	t.Run("remove should delete all the secret versions", func(t *testing.T) {
+		assert.Nil(t, provider.Remove(key))
+		_, err := provider.Load(key)
+		assert.EqualValues(t, http.StatusNotFound, err.StatusCode())
+	})
+}
+
+func TestService_CreateWithVaultProvider(t *testing.T) {
+	vault, server := newVaultDevServer(t)
+	defer server.Close()
+	providers[VaultProvider] = NewVaultProvider(server.URL, "root", "secret", "kotal")
+	defer delete(providers, VaultProvider)
+
+	k8sClientGetFunc = func(key client.ObjectKey, obj client.Object) error {
+		return apiErrors.NewNotFound(schema.GroupResource{Resource: "secrets"}, key.Name)
+	}
+
+	t.Run("create should store the data in vault and materialize a mutable secret", func(t *testing.T) {
+		var created corev1.Secret
+		k8sClientCreateFunc = func(obj client.Object) error {
+			created = *obj.(*corev1.Secret)
+			return nil
+		}
+
+		_, err := NewSecretService().Create(SecretDto{MetaDataDto: k8s.MetaDataDto{Name: "my-password", Namespace: "default"}, Type: PasswordType, Provider: VaultProvider, Data: map[string]string{"password": "secret"}})
+		assert.Nil(t, err)
+		assert.EqualValues(t, map[string]string{"password": "secret"}, vault.versions["kotal/default/my-password"][0])
+		assert.EqualValues(t, VaultProvider, created.Labels[ProviderLabel])
+		assert.EqualValues(t, "1", created.Annotations[SyncedVersionAnnotation])
+		assert.Nil(t, created.Immutable)
+	})
+
+	t.Run("create should throw if the secret provider isn't configured", func(t *testing.T) {
+		_, err := NewSecretService().Create(SecretDto{MetaDataDto: k8s.MetaDataDto{Name: "my-password", Namespace: "default"}, Type: PasswordType, Provider: "kms", Data: map[string]string{"password": "secret"}})
+		assert.EqualValues(t, "secret provider kms isn't configured", err.Error())
+	})
+}
+
+func TestService_Sync(t *testing.T) {
+	vault, server := newVaultDevServer(t)
+	defer server.Close()
+	providers[VaultProvider] = NewVaultProvider(server.URL, "root", "secret", "kotal")
+	defer delete(providers, VaultProvider)
+	vault.versions["kotal/default/my-password"] = []map[string]string{{"password": "first"}, {"password": "second"}}
+
+	materialized := func(name string, version string) corev1.Secret {
+		return corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default",
+			Labels:      map[string]string{ProviderLabel: VaultProvider},
+			Annotations: map[string]string{SyncedVersionAnnotation: version},
+		}}
+	}
+
+	t.Run("sync should update the secrets whose vault data changed", func(t *testing.T) {
+		k8sClientListFunc = workspace([]corev1.Secret{materialized("my-password", "1"), materialized("removed-from-vault", "1")}, nil, nil)
+		var updated []corev1.Secret
+		k8sClientUpdateFunc = func(obj client.Object) error {
+			updated = append(updated, *obj.(*corev1.Secret))
+			return nil
+		}
+
+		assert.Nil(t, NewSecretService().Sync())
+		assert.Len(t, updated, 1)
+		assert.EqualValues(t, "2", updated[0].Annotations[SyncedVersionAnnotation])
+		assert.EqualValues(t, map[string][]byte{"password": []byte("second")}, updated[0].Data)
+	})
+
+	t.Run("sync should skip the secrets in sync", func(t *testing.T) {
+		k8sClientListFunc = workspace([]corev1.Secret{materialized("my-password", "2")}, nil, nil)
+		updated := 0
+		k8sClientUpdateFunc = func(obj client.Object) error {
+			updated++
+			return nil
+		}
+
+		assert.Nil(t, NewSecretService().Sync())
+		assert.EqualValues(t, 0, updated)
+	})
+}
+
+func TestService_Materialize(t *testing.T) {
+	vault, server := newVaultDevServer(t)
+	defer server.Close()
+	providers[VaultProvider] = NewVaultProvider(server.URL, "root", "secret", "kotal")
+	defer delete(providers, VaultProvider)
+	vault.versions["kotal/default/my-key"] = []map[string]string{{"key": "0x01"}}
+	vault.metadata["kotal/default/my-key"] = map[string]string{keyTypeMetadata: PrivateKeyType}
+
+	node := ethereumv1alpha1.Node{ObjectMeta: metav1.ObjectMeta{Name: "my-node", Namespace: "default"}}
+	node.Spec.NodePrivateKeySecretName = "my-key"
+	node.Spec.JWTSecretName = "existing-jwt"
+	node.Spec.Import = &ethereumv1alpha1.ImportedAccount{PrivateKeySecretName: "not-in-vault"}
+	k8sClientListFunc = workspace(nil, []ethereumv1alpha1.Node{node}, nil)
+	k8sClientGetFunc = func(key client.ObjectKey, obj client.Object) error {
+		if key.Name == "existing-jwt" {
+			return nil
+		}
+		return apiErrors.NewNotFound(schema.GroupResource{Resource: "secrets"}, key.Name)
+	}
+
+	t.Run("materialize should create the missing referenced secrets from vault", func(t *testing.T) {
+		var created []corev1.Secret
+		k8sClientCreateFunc = func(obj client.Object) error {
+			created = append(created, *obj.(*corev1.Secret))
+			return nil
+		}
+
+		assert.Nil(t, NewSecretService().Materialize("default", VaultProvider))
+		assert.Len(t, created, 1)
+		assert.EqualValues(t, "my-key", created[0].Name)
+		assert.EqualValues(t, PrivateKeyType, created[0].Labels[KeyTypeLabel])
+		assert.EqualValues(t, map[string]string{"key": "0x01"}, created[0].StringData)
+	})
+
+	t.Run("materialize should skip workspaces keeping secrets in kubernetes", func(t *testing.T) {
+		k8sClientCreateFunc = func(obj client.Object) error {
+			t.Fail()
+			return nil
+		}
+		assert.Nil(t, NewSecretService().Materialize("default", KubernetesProvider))
+	})
+}
@@ -1,15 +1,24 @@
 package setting
 
 import (
+	"fmt"
 	"github.com/go-playground/validator/v10"
+	"github.com/kotalco/core-api/config"
 	k8svc "github.com/kotalco/core-api/k8s/svc"
 	restErrors "github.com/kotalco/core-api/pkg/errors"
 	"github.com/kotalco/core-api/pkg/logger"
+	"strings"
 )
 
 const (
 	DomainKey                    = "domain"
 	RegistrationKey              = "registration_is_enabled"
+	PasswordPolicyKey            = "password_policy"
+	MailKey                      = "mail"
+	MailTemplateKeyPrefix        = "mail_template_"
+	CheckpointSyncProvidersKey   = "checkpoint_sync_providers"
+	SendgridMailTransport        = "sendgrid"
+	SMTPMailTransport            = "smtp"
 	CustomTLSSecretName          = "kotal-tls-secret-for-traefik"
 	KotalLetsEncryptResolverName = "kotal-lets-encrypt-resolver"
 )
@@ -22,6 +31,67 @@ type ConfigureRegistrationRequestDto struct {
 	EnableRegistration *bool `json:"enable_registration" validate:"required,boolean"`
 }
 
+// PasswordPolicyDto is the platform-wide password policy, stored as json under PasswordPolicyKey
+// HistorySize is the number of previous passwords that can't be reused, MaxAgeDays of 0 means passwords never expire
+type PasswordPolicyDto struct {
+	MinLength        uint `json:"min_length" validate:"gte=6,lte=100"`
+	RequireUppercase bool `json:"require_uppercase"`
+	RequireLowercase bool `json:"require_lowercase"`
+	RequireDigit     bool `json:"require_digit"`
+	RequireSymbol    bool `json:"require_symbol"`
+	HistorySize      uint `json:"history_size" validate:"lte=10"`
+	MaxAgeDays       uint `json:"max_age_days" validate:"lte=365"`
+	CheckBreached    bool `json:"check_breached"`
+}
+
+// MailSettingsDto selects the mail transport and the branding used in emails, stored as json under MailKey
+type MailSettingsDto struct {
+	Transport   string           `json:"transport" validate:"required,oneof=sendgrid smtp"`
+	SenderName  string           `json:"sender_name" validate:"required,lte=100"`
+	SenderEmail string           `json:"sender_email" validate:"required,email"`
+	ProductName string           `json:"product_name" validate:"required,lte=100"`
+	LogoURL     string           `json:"logo_url" validate:"omitempty,url"`
+	SMTP        *SMTPSettingsDto `json:"smtp,omitempty" validate:"required_if=Transport smtp"`
+}
+
+// SMTPSettingsDto is the smtp relay configuration, authentication is skipped if Username is empty
+type SMTPSettingsDto struct {
+	Host     string `json:"host" validate:"required"`
+	Port     uint   `json:"port" validate:"required,lte=65535"`
+	Username string `json:"username"`
+	Password string `json:"password,omitempty"`
+	StartTLS bool   `json:"starttls"`
+}
+
+// MailTemplateDto overrides the subject and html body of one of the mail templates
+type MailTemplateDto struct {
+	Subject string `json:"subject" validate:"required,lte=200"`
+	HTML    string `json:"html" validate:"required"`
+}
+
+type MailTemplateResponseDto struct {
+	Name       string `json:"name"`
+	Subject    string `json:"subject"`
+	HTML       string `json:"html"`
+	Overridden bool   `json:"overridden"`
+}
+
+// CheckpointSyncProviderDto is a beacon node checkpoint sync provider of a network
+type CheckpointSyncProviderDto struct {
+	Name    string `json:"name" validate:"required,lte=100"`
+	Network string `json:"network" validate:"required"`
+	URL     string `json:"url" validate:"required,url"`
+}
+
+// CheckpointSyncProvidersDto is the curated checkpoint sync providers offered on beacon nodes creation, stored as json under CheckpointSyncProvidersKey
+type CheckpointSyncProvidersDto struct {
+	Providers []CheckpointSyncProviderDto `json:"providers" validate:"dive"`
+}
+
+type TestMailRequestDto struct {
+	Email string `json:"email" validate:"required,email"`
+}
+
 type SettingResponseDto struct {
 	Key   string `json:"key"`
 	Value string `json:"value"`
@@ -46,6 +116,57 @@ func Validate(dto interface{}) restErrors.IRestErr {
 			case "EnableRegistration":
 				fields["enable_registration"] = "invalid registration value"
 				break
+			case "MinLength":
+				fields["min_length"] = "min_length should be between 6 and 100"
+				break
+			case "HistorySize":
+				fields["history_size"] = "history_size can't exceed 10"
+				break
+			case "MaxAgeDays":
+				fields["max_age_days"] = "max_age_days can't exceed 365"
+				break
+			case "Transport":
+				fields["transport"] = "transport can only be sendgrid or smtp"
+				break
+			case "SenderName":
+				fields["sender_name"] = "invalid sender name"
+				break
+			case "SenderEmail":
+				fields["sender_email"] = "invalid sender email address"
+				break
+			case "ProductName":
+				fields["product_name"] = "invalid product name"
+				break
+			case "LogoURL":
+				fields["logo_url"] = "invalid logo url"
+				break
+			case "SMTP":
+				fields["smtp"] = "smtp settings are required for smtp transport"
+				break
+			case "Host":
+				fields["host"] = "invalid smtp host"
+				break
+			case "Port":
+				fields["port"] = "invalid smtp port"
+				break
+			case "Subject":
+				fields["subject"] = "subject is required and can't exceed 200 chars"
+				break
+			case "HTML":
+				fields["html"] = "html is required"
+				break
+			case "Email":
+				fields["email"] = "invalid email address"
+				break
+			case "Name":
+				fields[providerField(err, "name")] = "provider name is required and can't exceed 100 chars"
+				break
+			case "Network":
+				fields[providerField(err, "network")] = "provider network is required"
+				break
+			case "URL":
+				fields[providerField(err, "url")] = "invalid provider url"
+				break
 			}
 		}
 
@@ -57,6 +178,63 @@ func Validate(dto interface{}) restErrors.IRestErr {
 	return nil
 }
 
+// providerField keys the checkpoint sync provider field error by the provider index, i.e. providers[1].url
+func providerField(err validator.FieldError, field string) string {
+	namespace := err.StructNamespace()
+	start := strings.Index(namespace, "Providers[")
+	if start < 0 {
+		return field
+	}
+	end := strings.Index(namespace[start:], "]")
+	return fmt.Sprintf("providers%s.%s", namespace[start+len("Providers"):start+end+1], field)
+}
+
+// DefaultPasswordPolicy is used till the platform admin configures the password policy
+func DefaultPasswordPolicy() *PasswordPolicyDto {
+	return &PasswordPolicyDto{
+		MinLength:     6,
+		CheckBreached: true,
+	}
+}
+
+// DefaultMailSettings is used till the platform admin configures the mail settings
+func DefaultMailSettings() *MailSettingsDto {
+	return &MailSettingsDto{
+		Transport:   SendgridMailTransport,
+		SenderName:  config.Environment.SendgridSenderName,
+		SenderEmail: config.Environment.SendgridsenderEmail,
+		ProductName: "Kotal",
+	}
+}
+
+// DefaultCheckpointSyncProviders is used till the platform admin configures the checkpoint sync providers
+func DefaultCheckpointSyncProviders() *CheckpointSyncProvidersDto {
+	return &CheckpointSyncProvidersDto{
+		Providers: []CheckpointSyncProviderDto{
+			{Name: "Sigma Prime", Network: "mainnet", URL: "https://mainnet.checkpoint.sigp.io"},
+			{Name: "EthStaker", Network: "mainnet", URL: "https://beaconstate.ethstaker.cc"},
+			{Name: "Beaconcha.in", Network: "mainnet", URL: "https://sync-mainnet.beaconcha.in"},
+			{Name: "Beaconstate", Network: "mainnet", URL: "https://beaconstate.info"},
+			{Name: "Beaconstate", Network: "sepolia", URL: "https://sepolia.beaconstate.info"},
+			{Name: "ethPandaOps", Network: "sepolia", URL: "https://checkpoint-sync.sepolia.ethpandaops.io"},
+			{Name: "Beaconstate", Network: "holesky", URL: "https://holesky.beaconstate.info"},
+			{Name: "ethPandaOps", Network: "holesky", URL: "https://checkpoint-sync.holesky.ethpandaops.io"},
+			{Name: "Gnosis", Network: "gnosis", URL: "https://checkpoint.gnosischain.com"},
+		},
+	}
+}
+
+// Network returns the providers of the network
+func (dto CheckpointSyncProvidersDto) Network(network string) CheckpointSyncProvidersDto {
+	result := CheckpointSyncProvidersDto{Providers: []CheckpointSyncProviderDto{}}
+	for _, provider := range dto.Providers {
+		if provider.Network == network {
+			result.Providers = append(result.Providers, provider)
+		}
+	}
+	return result
+}
+
 // Marshall creates user response from user model
 func (dto SettingResponseDto) Marshall(model *Setting) SettingResponseDto {
 	dto.Key = model.Key
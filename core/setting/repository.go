@@ -20,6 +20,7 @@ type IRepository interface {
 	Create(key string, value string) restErrors.IRestErr
 	Update(key string, value string) restErrors.IRestErr
 	Find() ([]*Setting, restErrors.IRestErr)
+	Delete(key string) restErrors.IRestErr
 }
 
 func NewRepository() IRepository {
@@ -86,3 +87,12 @@ func (r repository) Find() ([]*Setting, restErrors.IRestErr) {
 
 	return setting, nil
 }
+
+func (r repository) Delete(key string) restErrors.IRestErr {
+	result := r.db.Where("key = ?", key).Delete(&Setting{})
+	if result.Error != nil {
+		go logger.Error(r.Delete, result.Error)
+		return restErrors.NewInternalServerError("something went wrong")
+	}
+	return nil
+}
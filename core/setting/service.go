@@ -1,7 +1,9 @@
 package setting
 
 import (
+	"encoding/json"
 	restErrors "github.com/kotalco/core-api/pkg/errors"
+	"github.com/kotalco/core-api/pkg/logger"
 	"gorm.io/gorm"
 	"net/http"
 	"strconv"
@@ -18,6 +20,15 @@ type IService interface {
 	IsDomainConfigured() bool
 	ConfigureRegistration(dto *ConfigureRegistrationRequestDto) restErrors.IRestErr
 	IsRegistrationEnabled() bool
+	ConfigurePasswordPolicy(dto *PasswordPolicyDto) restErrors.IRestErr
+	GetPasswordPolicy() (*PasswordPolicyDto, restErrors.IRestErr)
+	ConfigureMail(dto *MailSettingsDto) restErrors.IRestErr
+	GetMailSettings() (*MailSettingsDto, restErrors.IRestErr)
+	ConfigureMailTemplate(name string, dto *MailTemplateDto) restErrors.IRestErr
+	GetMailTemplate(name string) (*MailTemplateDto, restErrors.IRestErr)
+	DeleteMailTemplate(name string) restErrors.IRestErr
+	ConfigureCheckpointSyncProviders(dto *CheckpointSyncProvidersDto) restErrors.IRestErr
+	GetCheckpointSyncProviders() (*CheckpointSyncProvidersDto, restErrors.IRestErr)
 }
 
 var (
@@ -38,8 +49,20 @@ func (s service) WithoutTransaction() IService {
 	return s
 }
 
+// Settings lists the platform settings, except the mail settings which hold the smtp credentials
 func (s service) Settings() ([]*Setting, restErrors.IRestErr) {
-	return settingRepo.Find()
+	list, err := settingRepo.Find()
+	if err != nil {
+		return nil, err
+	}
+
+	settings := make([]*Setting, 0)
+	for _, v := range list {
+		if v.Key != MailKey {
+			settings = append(settings, v)
+		}
+	}
+	return settings, nil
 }
 
 func (s service) ConfigureDomain(dto *ConfigureDomainRequestDto) restErrors.IRestErr {
@@ -86,3 +109,150 @@ func (s service) IsRegistrationEnabled() bool {
 	}
 	return false
 }
+
+func (s service) ConfigurePasswordPolicy(dto *PasswordPolicyDto) restErrors.IRestErr {
+	value, err := json.Marshal(dto)
+	if err != nil {
+		go logger.Error(s.ConfigurePasswordPolicy, err)
+		return restErrors.NewInternalServerError("something went wrong")
+	}
+
+	_, restErr := settingRepo.Get(PasswordPolicyKey)
+	if restErr != nil {
+		if restErr.StatusCode() == http.StatusNotFound {
+			//the record doesn't exist create new one
+			return settingRepo.Create(PasswordPolicyKey, string(value))
+		}
+		return restErr
+	}
+	//record exits update it
+	return settingRepo.Update(PasswordPolicyKey, string(value))
+}
+
+// GetPasswordPolicy returns the configured password policy or the default policy if not configured yet
+func (s service) GetPasswordPolicy() (*PasswordPolicyDto, restErrors.IRestErr) {
+	value, restErr := settingRepo.Get(PasswordPolicyKey)
+	if restErr != nil {
+		if restErr.StatusCode() == http.StatusNotFound {
+			return DefaultPasswordPolicy(), nil
+		}
+		return nil, restErr
+	}
+
+	policy := new(PasswordPolicyDto)
+	err := json.Unmarshal([]byte(value), policy)
+	if err != nil {
+		go logger.Error(s.GetPasswordPolicy, err)
+		return nil, restErrors.NewInternalServerError("something went wrong")
+	}
+
+	return policy, nil
+}
+
+// ConfigureMail sets the mail transport and branding
+func (s service) ConfigureMail(dto *MailSettingsDto) restErrors.IRestErr {
+	value, err := json.Marshal(dto)
+	if err != nil {
+		go logger.Error(s.ConfigureMail, err)
+		return restErrors.NewInternalServerError("something went wrong")
+	}
+
+	return s.upsert(MailKey, string(value))
+}
+
+// GetMailSettings returns the configured mail settings or the default settings if not configured yet
+func (s service) GetMailSettings() (*MailSettingsDto, restErrors.IRestErr) {
+	value, restErr := settingRepo.Get(MailKey)
+	if restErr != nil {
+		if restErr.StatusCode() == http.StatusNotFound {
+			return DefaultMailSettings(), nil
+		}
+		return nil, restErr
+	}
+
+	settings := new(MailSettingsDto)
+	err := json.Unmarshal([]byte(value), settings)
+	if err != nil {
+		go logger.Error(s.GetMailSettings, err)
+		return nil, restErrors.NewInternalServerError("something went wrong")
+	}
+
+	return settings, nil
+}
+
+// ConfigureMailTemplate overrides the built-in mail template with the given name
+func (s service) ConfigureMailTemplate(name string, dto *MailTemplateDto) restErrors.IRestErr {
+	value, err := json.Marshal(dto)
+	if err != nil {
+		go logger.Error(s.ConfigureMailTemplate, err)
+		return restErrors.NewInternalServerError("something went wrong")
+	}
+
+	return s.upsert(MailTemplateKeyPrefix+name, string(value))
+}
+
+// GetMailTemplate returns the overridden mail template, not found if the built-in template is used
+func (s service) GetMailTemplate(name string) (*MailTemplateDto, restErrors.IRestErr) {
+	value, restErr := settingRepo.Get(MailTemplateKeyPrefix + name)
+	if restErr != nil {
+		return nil, restErr
+	}
+
+	template := new(MailTemplateDto)
+	err := json.Unmarshal([]byte(value), template)
+	if err != nil {
+		go logger.Error(s.GetMailTemplate, err)
+		return nil, restErrors.NewInternalServerError("something went wrong")
+	}
+
+	return template, nil
+}
+
+// DeleteMailTemplate restores the built-in mail template with the given name
+func (s service) DeleteMailTemplate(name string) restErrors.IRestErr {
+	return settingRepo.Delete(MailTemplateKeyPrefix + name)
+}
+
+// ConfigureCheckpointSyncProviders replaces the checkpoint sync providers catalog
+func (s service) ConfigureCheckpointSyncProviders(dto *CheckpointSyncProvidersDto) restErrors.IRestErr {
+	value, err := json.Marshal(dto)
+	if err != nil {
+		go logger.Error(s.ConfigureCheckpointSyncProviders, err)
+		return restErrors.NewInternalServerError("something went wrong")
+	}
+
+	return s.upsert(CheckpointSyncProvidersKey, string(value))
+}
+
+// GetCheckpointSyncProviders returns the configured checkpoint sync providers or the default providers if not configured yet
+func (s service) GetCheckpointSyncProviders() (*CheckpointSyncProvidersDto, restErrors.IRestErr) {
+	value, restErr := settingRepo.Get(CheckpointSyncProvidersKey)
+	if restErr != nil {
+		if restErr.StatusCode() == http.StatusNotFound {
+			return DefaultCheckpointSyncProviders(), nil
+		}
+		return nil, restErr
+	}
+
+	providers := new(CheckpointSyncProvidersDto)
+	err := json.Unmarshal([]byte(value), providers)
+	if err != nil {
+		go logger.Error(s.GetCheckpointSyncProviders, err)
+		return nil, restErrors.NewInternalServerError("something went wrong")
+	}
+
+	return providers, nil
+}
+
+func (s service) upsert(key string, value string) restErrors.IRestErr {
+	_, restErr := settingRepo.Get(key)
+	if restErr != nil {
+		if restErr.StatusCode() == http.StatusNotFound {
+			//the record doesn't exist create new one
+			return settingRepo.Create(key, value)
+		}
+		return restErr
+	}
+	//record exits update it
+	return settingRepo.Update(key, value)
+}
@@ -15,6 +15,7 @@ var (
 	settingCreateFunc func(key string, value string) restErrors.IRestErr
 	settingUpdateFunc func(key string, value string) restErrors.IRestErr
 	settingFindFunc   func() ([]*Setting, restErrors.IRestErr)
+	settingDeleteFunc func(key string) restErrors.IRestErr
 )
 
 type settingRepoMocks struct{}
@@ -43,6 +44,10 @@ func (s settingRepoMocks) Find() ([]*Setting, restErrors.IRestErr) {
 	return settingFindFunc()
 }
 
+func (s settingRepoMocks) Delete(key string) restErrors.IRestErr {
+	return settingDeleteFunc(key)
+}
+
 func TestMain(m *testing.M) {
 	sqlclient.OpenDBConnection()
 	settingService = NewService()
@@ -172,3 +177,178 @@ func TestService_IsRegistrationEnabled(t *testing.T) {
 		assert.False(t, settingService.IsRegistrationEnabled())
 	})
 }
+
+func TestService_ConfigurePasswordPolicy(t *testing.T) {
+	t.Run("configure password policy should pass and create new record", func(t *testing.T) {
+		settingGetFunc = func(key string) (string, restErrors.IRestErr) {
+			return "", restErrors.NewNotFoundError("no such record")
+		}
+		settingCreateFunc = func(key string, value string) restErrors.IRestErr {
+			assert.EqualValues(t, PasswordPolicyKey, key)
+			return nil
+		}
+		err := settingService.ConfigurePasswordPolicy(&PasswordPolicyDto{MinLength: 10})
+		assert.Nil(t, err)
+	})
+	t.Run("configure password policy should pass and update the old record", func(t *testing.T) {
+		settingGetFunc = func(key string) (string, restErrors.IRestErr) {
+			return "{}", nil
+		}
+		settingUpdateFunc = func(key string, value string) restErrors.IRestErr {
+			return nil
+		}
+		err := settingService.ConfigurePasswordPolicy(&PasswordPolicyDto{MinLength: 10})
+		assert.Nil(t, err)
+	})
+	t.Run("configure password policy should throw if repo throws", func(t *testing.T) {
+		settingGetFunc = func(key string) (string, restErrors.IRestErr) {
+			return "", restErrors.NewInternalServerError("something went wrong")
+		}
+		err := settingService.ConfigurePasswordPolicy(&PasswordPolicyDto{MinLength: 10})
+		assert.EqualValues(t, "something went wrong", err.Error())
+	})
+}
+
+func TestService_GetPasswordPolicy(t *testing.T) {
+	t.Run("get password policy should return the configured policy", func(t *testing.T) {
+		settingGetFunc = func(key string) (string, restErrors.IRestErr) {
+			return `{"min_length":12,"require_digit":true,"history_size":5}`, nil
+		}
+		policy, err := settingService.GetPasswordPolicy()
+		assert.Nil(t, err)
+		assert.EqualValues(t, 12, policy.MinLength)
+		assert.True(t, policy.RequireDigit)
+		assert.EqualValues(t, 5, policy.HistorySize)
+	})
+	t.Run("get password policy should return the default policy if not configured", func(t *testing.T) {
+		settingGetFunc = func(key string) (string, restErrors.IRestErr) {
+			return "", restErrors.NewNotFoundError("no such record")
+		}
+		policy, err := settingService.GetPasswordPolicy()
+		assert.Nil(t, err)
+		assert.EqualValues(t, DefaultPasswordPolicy(), policy)
+	})
+	t.Run("get password policy should throw if the stored value is invalid", func(t *testing.T) {
+		settingGetFunc = func(key string) (string, restErrors.IRestErr) {
+			return "invalid", nil
+		}
+		policy, err := settingService.GetPasswordPolicy()
+		assert.Nil(t, policy)
+		assert.EqualValues(t, "something went wrong", err.Error())
+	})
+}
+
+func TestService_Settings_HidesMailSettings(t *testing.T) {
+	t.Run("settings should not list the mail settings", func(t *testing.T) {
+		settingFindFunc = func() ([]*Setting, restErrors.IRestErr) {
+			return []*Setting{{Key: DomainKey}, {Key: MailKey}}, nil
+		}
+		list, err := settingService.Settings()
+		assert.Nil(t, err)
+		assert.Len(t, list, 1)
+		assert.EqualValues(t, DomainKey, list[0].Key)
+	})
+}
+
+func TestService_ConfigureMail(t *testing.T) {
+	t.Run("configure mail should pass and create new record", func(t *testing.T) {
+		settingGetFunc = func(key string) (string, restErrors.IRestErr) {
+			return "", restErrors.NewNotFoundError("no such record")
+		}
+		settingCreateFunc = func(key string, value string) restErrors.IRestErr {
+			assert.EqualValues(t, MailKey, key)
+			return nil
+		}
+		err := settingService.ConfigureMail(DefaultMailSettings())
+		assert.Nil(t, err)
+	})
+	t.Run("configure mail should throw if repo throws", func(t *testing.T) {
+		settingGetFunc = func(key string) (string, restErrors.IRestErr) {
+			return "", restErrors.NewInternalServerError("something went wrong")
+		}
+		err := settingService.ConfigureMail(DefaultMailSettings())
+		assert.EqualValues(t, "something went wrong", err.Error())
+	})
+}
+
+func TestService_GetMailSettings(t *testing.T) {
+	t.Run("get mail settings should return the configured settings", func(t *testing.T) {
+		settingGetFunc = func(key string) (string, restErrors.IRestErr) {
+			return `{"transport":"smtp","smtp":{"host":"smtp.internal","port":587}}`, nil
+		}
+		settings, err := settingService.GetMailSettings()
+		assert.Nil(t, err)
+		assert.EqualValues(t, SMTPMailTransport, settings.Transport)
+		assert.EqualValues(t, 587, settings.SMTP.Port)
+	})
+	t.Run("get mail settings should return the default settings if not configured", func(t *testing.T) {
+		settingGetFunc = func(key string) (string, restErrors.IRestErr) {
+			return "", restErrors.NewNotFoundError("no such record")
+		}
+		settings, err := settingService.GetMailSettings()
+		assert.Nil(t, err)
+		assert.EqualValues(t, DefaultMailSettings(), settings)
+	})
+}
+
+func TestService_MailTemplate(t *testing.T) {
+	t.Run("configure mail template should store the template under its key", func(t *testing.T) {
+		settingGetFunc = func(key string) (string, restErrors.IRestErr) {
+			return "", restErrors.NewNotFoundError("no such record")
+		}
+		settingCreateFunc = func(key string, value string) restErrors.IRestErr {
+			assert.EqualValues(t, MailTemplateKeyPrefix+"confirm_email", key)
+			return nil
+		}
+		err := settingService.ConfigureMailTemplate("confirm_email", &MailTemplateDto{Subject: "subject", HTML: "html"})
+		assert.Nil(t, err)
+	})
+	t.Run("get mail template should return the overridden template", func(t *testing.T) {
+		settingGetFunc = func(key string) (string, restErrors.IRestErr) {
+			return `{"subject":"subject","html":"html"}`, nil
+		}
+		template, err := settingService.GetMailTemplate("confirm_email")
+		assert.Nil(t, err)
+		assert.EqualValues(t, "subject", template.Subject)
+	})
+	t.Run("delete mail template should delete the override", func(t *testing.T) {
+		settingDeleteFunc = func(key string) restErrors.IRestErr {
+			assert.EqualValues(t, MailTemplateKeyPrefix+"confirm_email", key)
+			return nil
+		}
+		err := settingService.DeleteMailTemplate("confirm_email")
+		assert.Nil(t, err)
+	})
+}
+
+func TestService_CheckpointSyncProviders(t *testing.T) {
+	t.Run("configure checkpoint sync providers should store the providers", func(t *testing.T) {
+		settingGetFunc = func(key string) (string, restErrors.IRestErr) {
+			return "", restErrors.NewNotFoundError("no such record")
+		}
+		settingCreateFunc = func(key string, value string) restErrors.IRestErr {
+			assert.EqualValues(t, CheckpointSyncProvidersKey, key)
+			assert.EqualValues(t, `{"providers":[{"name":"provider","network":"mainnet","url":"https://provider.io"}]}`, value)
+			return nil
+		}
+		err := settingService.ConfigureCheckpointSyncProviders(&CheckpointSyncProvidersDto{Providers: []CheckpointSyncProviderDto{{Name: "provider", Network: "mainnet", URL: "https://provider.io"}}})
+		assert.Nil(t, err)
+	})
+	t.Run("get checkpoint sync providers should return the configured providers", func(t *testing.T) {
+		settingGetFunc = func(key string) (string, restErrors.IRestErr) {
+			return `{"providers":[{"name":"provider","network":"mainnet","url":"https://provider.io"},{"name":"provider","network":"sepolia","url":"https://sepolia.provider.io"}]}`, nil
+		}
+		providers, err := settingService.GetCheckpointSyncProviders()
+		assert.Nil(t, err)
+		assert.Len(t, providers.Providers, 2)
+		assert.EqualValues(t, []CheckpointSyncProviderDto{{Name: "provider", Network: "sepolia", URL: "https://sepolia.provider.io"}}, providers.Network("sepolia").Providers)
+	})
+	t.Run("get checkpoint sync providers should return the default providers if not configured", func(t *testing.T) {
+		settingGetFunc = func(key string) (string, restErrors.IRestErr) {
+			return "", restErrors.NewNotFoundError("no such record")
+		}
+		providers, err := settingService.GetCheckpointSyncProviders()
+		assert.Nil(t, err)
+		assert.EqualValues(t, DefaultCheckpointSyncProviders(), providers)
+	})
+}
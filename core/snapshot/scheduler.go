@@ -0,0 +1,69 @@
+package snapshot
+
+import (
+	"fmt"
+	"github.com/kotalco/core-api/pkg/logger"
+	"k8s.io/apimachinery/pkg/types"
+	"strconv"
+	"time"
+)
+
+// StartScheduler takes the due scheduled snapshots every interval in the background
+func StartScheduler(interval time.Duration) {
+	go func() {
+		for {
+			runSchedules(NewService(), time.Now())
+			time.Sleep(interval)
+		}
+	}()
+}
+
+// runSchedules snapshots the volumes whose schedule is due, and deletes their scheduled snapshots beyond the kept ones
+func runSchedules(service IService, now time.Time) {
+	claims, restErr := pvcService.ListAll()
+	if restErr != nil {
+		return
+	}
+
+	for _, claim := range claims.Items {
+		schedule := claim.Annotations[ScheduleAnnotation]
+		if schedule == "" {
+			continue
+		}
+		keep, _ := strconv.Atoi(claim.Annotations[KeepAnnotation])
+		node := types.NamespacedName{Namespace: claim.Namespace, Name: claim.Name}
+
+		snapshots, restErr := service.ListByNode(node)
+		if restErr != nil {
+			continue
+		}
+
+		scheduled := snapshots.Items[:0]
+		var latest time.Time
+		for _, snapshot := range snapshots.Items {
+			if snapshot.Labels[TriggerLabel] != ScheduledTrigger {
+				continue
+			}
+			scheduled = append(scheduled, snapshot)
+			if snapshot.CreationTimestamp.Time.After(latest) {
+				latest = snapshot.CreationTimestamp.Time
+			}
+		}
+
+		if Due(schedule, latest, now) {
+			snapshot, restErr := service.Create(node, CreateSnapshotDto{SnapshotClass: claim.Annotations[ClassAnnotation]}, ScheduledTrigger)
+			if restErr != nil {
+				go logger.Warn("SNAPSHOT_SCHEDULER", fmt.Errorf("can't snapshot volume %s: %s", node.String(), restErr.Error()))
+				continue
+			}
+			scheduled = append(scheduled, snapshot)
+		}
+
+		for _, snapshot := range Expired(scheduled, keep) {
+			snapshot := snapshot
+			if restErr := service.Delete(&snapshot); restErr != nil {
+				go logger.Warn("SNAPSHOT_SCHEDULER", fmt.Errorf("can't delete snapshot %s: %s", snapshot.Name, restErr.Error()))
+			}
+		}
+	}
+}
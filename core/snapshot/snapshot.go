@@ -0,0 +1,148 @@
+package snapshot
+
+import (
+	"github.com/go-playground/validator/v10"
+	"github.com/kotalco/core-api/k8s"
+	"github.com/kotalco/core-api/k8s/volumesnapshot"
+	restErrors "github.com/kotalco/core-api/pkg/errors"
+	"sort"
+	"time"
+)
+
+const (
+	// ScheduleAnnotation, KeepAnnotation and ClassAnnotation hold the node snapshot schedule on the node persistent volume claim
+	ScheduleAnnotation = "kotal.io/snapshot-schedule"
+	KeepAnnotation     = "kotal.io/snapshot-keep"
+	ClassAnnotation    = "kotal.io/snapshot-class"
+	// TriggerLabel tells the snapshots taken on demand from the scheduled ones, only scheduled snapshots are pruned
+	TriggerLabel     = "kotal.io/snapshot-trigger"
+	ManualTrigger    = "manual"
+	ScheduledTrigger = "scheduled"
+)
+
+// schedules are the supported snapshot schedules and the period between their snapshots
+var schedules = map[string]time.Duration{
+	"hourly": time.Hour,
+	"daily":  24 * time.Hour,
+	"weekly": 7 * 24 * time.Hour,
+}
+
+// SnapshotDto is a volume snapshot of a node volume
+type SnapshotDto struct {
+	Name          string    `json:"name"`
+	Node          string    `json:"node"`
+	Protocol      string    `json:"protocol"`
+	SnapshotClass string    `json:"snapshotClass"`
+	Trigger       string    `json:"trigger"`
+	ReadyToUse    bool      `json:"readyToUse"`
+	RestoreSize   string    `json:"restoreSize"`
+	Error         string    `json:"error,omitempty"`
+	CreatedAt     time.Time `json:"createdAt"`
+}
+
+type SnapshotListDto []SnapshotDto
+
+// CreateSnapshotDto names the snapshot, the snapshot is named after the node and the time if no name is given
+// the cluster default volume snapshot class is used if no snapshot class is given
+type CreateSnapshotDto struct {
+	Name          string `json:"name"`
+	SnapshotClass string `json:"snapshotClass"`
+}
+
+// ScheduleDto is the node snapshot schedule, an empty schedule disables scheduled snapshots
+type ScheduleDto struct {
+	Schedule      string `json:"schedule" validate:"omitempty,oneof=hourly daily weekly"`
+	Keep          int    `json:"keep" validate:"required_with=Schedule,omitempty,min=1,max=100"`
+	SnapshotClass string `json:"snapshotClass"`
+}
+
+// RestoreDto is the volume of the node being created from a snapshot
+// the volume storage defaults to the snapshot restore size, and the storage class to the snapshot source volume one
+type RestoreDto struct {
+	Name         string
+	Storage      string
+	StorageClass *string
+}
+
+func (dto SnapshotDto) FromVolumeSnapshot(snapshot volumesnapshot.VolumeSnapshot) SnapshotDto {
+	dto.Name = snapshot.Name
+	dto.Node = snapshot.Labels["app.kubernetes.io/instance"]
+	dto.Protocol = snapshot.Labels["kotal.io/protocol"]
+	dto.Trigger = snapshot.Labels[TriggerLabel]
+	dto.CreatedAt = snapshot.CreationTimestamp.Time
+	if snapshot.Spec.VolumeSnapshotClassName != nil {
+		dto.SnapshotClass = *snapshot.Spec.VolumeSnapshotClassName
+	}
+
+	if status := snapshot.Status; status != nil {
+		dto.ReadyToUse = status.ReadyToUse != nil && *status.ReadyToUse
+		if status.RestoreSize != nil {
+			dto.RestoreSize = status.RestoreSize.String()
+		}
+		if status.Error != nil && status.Error.Message != nil {
+			dto.Error = *status.Error.Message
+		}
+	}
+
+	return dto
+}
+
+func (snapshotListDto SnapshotListDto) FromVolumeSnapshot(list []volumesnapshot.VolumeSnapshot) SnapshotListDto {
+	result := make(SnapshotListDto, len(list))
+	for index, value := range list {
+		result[index] = SnapshotDto{}.FromVolumeSnapshot(value)
+	}
+	return result
+}
+
+// Validate validates the snapshot name if given
+func (dto CreateSnapshotDto) Validate() restErrors.IRestErr {
+	if dto.Name == "" {
+		return nil
+	}
+	meta := k8s.MetaDataDto{Name: dto.Name}
+	return meta.Validate()
+}
+
+// Validate validates the schedule is supported and keeps at least one snapshot
+func (dto ScheduleDto) Validate() restErrors.IRestErr {
+	err := validator.New().Struct(dto)
+	if err != nil {
+		fields := map[string]string{}
+		for _, err := range err.(validator.ValidationErrors) {
+			switch err.Field() {
+			case "Schedule":
+				fields["schedule"] = "schedule can only be hourly, daily or weekly"
+			case "Keep":
+				fields["keep"] = "keep must be between 1 and 100 snapshots"
+			}
+		}
+
+		if len(fields) > 0 {
+			return restErrors.NewValidationError(fields)
+		}
+	}
+
+	return nil
+}
+
+// Due checks if the latest snapshot of the schedule is older than the schedule period
+func Due(schedule string, latest time.Time, now time.Time) bool {
+	period, ok := schedules[schedule]
+	if !ok {
+		return false
+	}
+	return latest.IsZero() || now.Sub(latest) >= period
+}
+
+// Expired returns the snapshots beyond the newest keep snapshots
+func Expired(snapshots []volumesnapshot.VolumeSnapshot, keep int) []volumesnapshot.VolumeSnapshot {
+	if keep < 1 || len(snapshots) <= keep {
+		return nil
+	}
+	sorted := append([]volumesnapshot.VolumeSnapshot{}, snapshots...)
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[j].CreationTimestamp.Before(&sorted[i].CreationTimestamp)
+	})
+	return sorted[keep:]
+}
@@ -0,0 +1,193 @@
+// Package snapshot internal is the domain layer for the nodes volume snapshots
+// uses the csi volume snapshots to snapshot the node volume and to provision new nodes volumes from them
+package snapshot
+
+import (
+	"fmt"
+	"github.com/kotalco/core-api/k8s/pvc"
+	"github.com/kotalco/core-api/k8s/volumesnapshot"
+	restErrors "github.com/kotalco/core-api/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+type snapshotService struct{}
+
+type IService interface {
+	Get(key types.NamespacedName) (volumesnapshot.VolumeSnapshot, restErrors.IRestErr)
+	Create(node types.NamespacedName, dto CreateSnapshotDto, trigger string) (volumesnapshot.VolumeSnapshot, restErrors.IRestErr)
+	List(namespace string) (volumesnapshot.VolumeSnapshotList, restErrors.IRestErr)
+	ListByNode(node types.NamespacedName) (volumesnapshot.VolumeSnapshotList, restErrors.IRestErr)
+	Delete(*volumesnapshot.VolumeSnapshot) restErrors.IRestErr
+	GetSchedule(node types.NamespacedName) (ScheduleDto, restErrors.IRestErr)
+	UpdateSchedule(node types.NamespacedName, dto ScheduleDto) restErrors.IRestErr
+	Restore(snapshot volumesnapshot.VolumeSnapshot, dto RestoreDto) (corev1.PersistentVolumeClaim, restErrors.IRestErr)
+	Discard(claim *corev1.PersistentVolumeClaim) restErrors.IRestErr
+}
+
+var (
+	pvcService            = pvc.NewService()
+	volumeSnapshotService = volumesnapshot.NewService()
+)
+
+func NewService() IService {
+	return snapshotService{}
+}
+
+// Get returns a single snapshot by name
+func (service snapshotService) Get(key types.NamespacedName) (volumesnapshot.VolumeSnapshot, restErrors.IRestErr) {
+	return volumeSnapshotService.Get(key)
+}
+
+// Create snapshots the node volume, kotal names the node persistent volume claim after the node
+func (service snapshotService) Create(node types.NamespacedName, dto CreateSnapshotDto, trigger string) (snapshot volumesnapshot.VolumeSnapshot, restErr restErrors.IRestErr) {
+	claim, restErr := pvcService.Get(node)
+	if restErr != nil {
+		return
+	}
+
+	name := dto.Name
+	if name == "" {
+		name = fmt.Sprintf("%s-%s", node.Name, time.Now().UTC().Format("20060102150405"))
+	}
+
+	snapshot.ObjectMeta = metav1.ObjectMeta{
+		Name:      name,
+		Namespace: node.Namespace,
+		Labels: map[string]string{
+			"app.kubernetes.io/instance":   claim.Name,
+			"app.kubernetes.io/created-by": "kotal-api",
+			"kotal.io/protocol":            claim.Labels["kotal.io/protocol"],
+			TriggerLabel:                   trigger,
+		},
+	}
+	snapshot.Spec.Source.PersistentVolumeClaimName = &claim.Name
+	if dto.SnapshotClass != "" {
+		snapshot.Spec.VolumeSnapshotClassName = &dto.SnapshotClass
+	}
+
+	restErr = volumeSnapshotService.Create(&snapshot)
+	return
+}
+
+// List returns the snapshots of the workspace nodes
+func (service snapshotService) List(namespace string) (volumesnapshot.VolumeSnapshotList, restErrors.IRestErr) {
+	return volumeSnapshotService.List(namespace, map[string]string{"app.kubernetes.io/created-by": "kotal-api"})
+}
+
+// ListByNode returns the snapshots of the node volume
+func (service snapshotService) ListByNode(node types.NamespacedName) (volumesnapshot.VolumeSnapshotList, restErrors.IRestErr) {
+	return volumeSnapshotService.List(node.Namespace, map[string]string{
+		"app.kubernetes.io/created-by": "kotal-api",
+		"app.kubernetes.io/instance":   node.Name,
+	})
+}
+
+// Delete deletes a single snapshot
+func (service snapshotService) Delete(snapshot *volumesnapshot.VolumeSnapshot) restErrors.IRestErr {
+	return volumeSnapshotService.Delete(snapshot)
+}
+
+// GetSchedule returns the node snapshot schedule from its persistent volume claim annotations
+func (service snapshotService) GetSchedule(node types.NamespacedName) (dto ScheduleDto, restErr restErrors.IRestErr) {
+	claim, restErr := pvcService.Get(node)
+	if restErr != nil {
+		return
+	}
+
+	dto.Schedule = claim.Annotations[ScheduleAnnotation]
+	dto.Keep, _ = strconv.Atoi(claim.Annotations[KeepAnnotation])
+	dto.SnapshotClass = claim.Annotations[ClassAnnotation]
+	return
+}
+
+// UpdateSchedule saves the node snapshot schedule to its persistent volume claim annotations
+// the schedule lives with the node volume and is removed with it
+func (service snapshotService) UpdateSchedule(node types.NamespacedName, dto ScheduleDto) restErrors.IRestErr {
+	claim, restErr := pvcService.Get(node)
+	if restErr != nil {
+		return restErr
+	}
+
+	if claim.Annotations == nil {
+		claim.Annotations = map[string]string{}
+	}
+	if dto.Schedule == "" {
+		delete(claim.Annotations, ScheduleAnnotation)
+		delete(claim.Annotations, KeepAnnotation)
+		delete(claim.Annotations, ClassAnnotation)
+	} else {
+		claim.Annotations[ScheduleAnnotation] = dto.Schedule
+		claim.Annotations[KeepAnnotation] = strconv.Itoa(dto.Keep)
+		claim.Annotations[ClassAnnotation] = dto.SnapshotClass
+	}
+
+	return pvcService.Update(&claim)
+}
+
+// Restore creates the volume of the node being created from the snapshot
+// the operator adopts the volume as it's named after the node, the volume requests can't be less than the snapshot restore size
+func (service snapshotService) Restore(snapshot volumesnapshot.VolumeSnapshot, dto RestoreDto) (claim corev1.PersistentVolumeClaim, restErr restErrors.IRestErr) {
+	status := snapshot.Status
+	if status == nil || status.ReadyToUse == nil || !*status.ReadyToUse || status.RestoreSize == nil {
+		restErr = restErrors.NewBadRequestError(fmt.Sprintf("snapshot %s isn't ready to use", snapshot.Name))
+		return
+	}
+
+	storage := *status.RestoreSize
+	if dto.Storage != "" {
+		requested, err := resource.ParseQuantity(dto.Storage)
+		if err != nil || requested.Cmp(storage) < 0 {
+			restErr = restErrors.NewValidationError(map[string]string{"storage": fmt.Sprintf("storage can't be less than the snapshot restore size %s", storage.String())})
+			return
+		}
+		storage = requested
+	}
+
+	storageClass := dto.StorageClass
+	if storageClass == nil && snapshot.Spec.Source.PersistentVolumeClaimName != nil {
+		source, restErr := pvcService.Get(types.NamespacedName{Namespace: snapshot.Namespace, Name: *snapshot.Spec.Source.PersistentVolumeClaimName})
+		if restErr != nil && restErr.StatusCode() != http.StatusNotFound {
+			return claim, restErr
+		}
+		storageClass = source.Spec.StorageClassName
+	}
+
+	apiGroup := volumesnapshot.GroupVersion.Group
+	claim = corev1.PersistentVolumeClaim{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      dto.Name,
+			Namespace: snapshot.Namespace,
+			Labels: map[string]string{
+				"app.kubernetes.io/instance":   dto.Name,
+				"app.kubernetes.io/managed-by": "kotal-operator",
+				"kotal.io/protocol":            snapshot.Labels["kotal.io/protocol"],
+			},
+		},
+		Spec: corev1.PersistentVolumeClaimSpec{
+			AccessModes: []corev1.PersistentVolumeAccessMode{corev1.ReadWriteOnce},
+			Resources: corev1.VolumeResourceRequirements{
+				Requests: corev1.ResourceList{corev1.ResourceStorage: storage},
+			},
+			StorageClassName: storageClass,
+			DataSource: &corev1.TypedLocalObjectReference{
+				APIGroup: &apiGroup,
+				Kind:     "VolumeSnapshot",
+				Name:     snapshot.Name,
+			},
+		},
+	}
+
+	restErr = pvcService.Create(&claim)
+	return
+}
+
+// Discard deletes the volume restored for a node which failed to be created
+func (service snapshotService) Discard(claim *corev1.PersistentVolumeClaim) restErrors.IRestErr {
+	return pvcService.Delete(claim)
+}
@@ -0,0 +1,294 @@
+package snapshot
+
+import (
+	"github.com/kotalco/core-api/k8s/pvc"
+	"github.com/kotalco/core-api/k8s/volumesnapshot"
+	restErrors "github.com/kotalco/core-api/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"net/http"
+	"os"
+	"testing"
+	"time"
+)
+
+/*
+persistent volume claim service mocks
+*/
+var (
+	pvcGetFunc     func(key types.NamespacedName) (corev1.PersistentVolumeClaim, restErrors.IRestErr)
+	pvcCreateFunc  func(claim *corev1.PersistentVolumeClaim) restErrors.IRestErr
+	pvcUpdateFunc  func(claim *corev1.PersistentVolumeClaim) restErrors.IRestErr
+	pvcListAllFunc func() (corev1.PersistentVolumeClaimList, restErrors.IRestErr)
+)
+
+type pvcServiceMock struct{}
+
+func (pvcServiceMock) Get(key types.NamespacedName) (corev1.PersistentVolumeClaim, restErrors.IRestErr) {
+	return pvcGetFunc(key)
+}
+func (pvcServiceMock) Create(claim *corev1.PersistentVolumeClaim) restErrors.IRestErr {
+	return pvcCreateFunc(claim)
+}
+func (pvcServiceMock) Update(claim *corev1.PersistentVolumeClaim) restErrors.IRestErr {
+	return pvcUpdateFunc(claim)
+}
+func (pvcServiceMock) Delete(claim *corev1.PersistentVolumeClaim) restErrors.IRestErr {
+	return nil
+}
+func (pvcServiceMock) List(namespace string) (corev1.PersistentVolumeClaimList, restErrors.IRestErr) {
+	return corev1.PersistentVolumeClaimList{}, nil
+}
+func (pvcServiceMock) ListAll() (corev1.PersistentVolumeClaimList, restErrors.IRestErr) {
+	return pvcListAllFunc()
+}
+func (pvcServiceMock) Stats(namespace string) (map[string]pvc.VolumeStats, restErrors.IRestErr) {
+	return map[string]pvc.VolumeStats{}, nil
+}
+
+/*
+volume snapshot service mocks
+*/
+var (
+	volumeSnapshotCreateFunc func(snapshot *volumesnapshot.VolumeSnapshot) restErrors.IRestErr
+	volumeSnapshotListFunc   func(namespace string, labels map[string]string) (volumesnapshot.VolumeSnapshotList, restErrors.IRestErr)
+	volumeSnapshotDeleteFunc func(snapshot *volumesnapshot.VolumeSnapshot) restErrors.IRestErr
+)
+
+type volumeSnapshotServiceMock struct{}
+
+func (volumeSnapshotServiceMock) Get(key types.NamespacedName) (volumesnapshot.VolumeSnapshot, restErrors.IRestErr) {
+	return volumesnapshot.VolumeSnapshot{}, nil
+}
+func (volumeSnapshotServiceMock) Create(snapshot *volumesnapshot.VolumeSnapshot) restErrors.IRestErr {
+	return volumeSnapshotCreateFunc(snapshot)
+}
+func (volumeSnapshotServiceMock) List(namespace string, labels map[string]string) (volumesnapshot.VolumeSnapshotList, restErrors.IRestErr) {
+	return volumeSnapshotListFunc(namespace, labels)
+}
+func (volumeSnapshotServiceMock) Delete(snapshot *volumesnapshot.VolumeSnapshot) restErrors.IRestErr {
+	return volumeSnapshotDeleteFunc(snapshot)
+}
+
+func TestMain(m *testing.M) {
+	pvcService = &pvcServiceMock{}
+	volumeSnapshotService = &volumeSnapshotServiceMock{}
+
+	code := m.Run()
+	os.Exit(code)
+}
+
+func nodeClaim(annotations map[string]string) corev1.PersistentVolumeClaim {
+	standard := "standard"
+	return corev1.PersistentVolumeClaim{
+		ObjectMeta: metav1.ObjectMeta{Name: "my-node", Namespace: "default", Labels: map[string]string{"kotal.io/protocol": "ethereum"}, Annotations: annotations},
+		Spec:       corev1.PersistentVolumeClaimSpec{StorageClassName: &standard},
+	}
+}
+
+func readySnapshot(name string, restoreSize string, created time.Time) volumesnapshot.VolumeSnapshot {
+	ready := true
+	size := resource.MustParse(restoreSize)
+	source := "my-node"
+	return volumesnapshot.VolumeSnapshot{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default", CreationTimestamp: metav1.NewTime(created), Labels: map[string]string{"kotal.io/protocol": "ethereum", TriggerLabel: ScheduledTrigger}},
+		Spec:       volumesnapshot.VolumeSnapshotSpec{Source: volumesnapshot.VolumeSnapshotSource{PersistentVolumeClaimName: &source}},
+		Status:     &volumesnapshot.VolumeSnapshotStatus{ReadyToUse: &ready, RestoreSize: &size},
+	}
+}
+
+func TestScheduleDto_Validate(t *testing.T) {
+	t.Run("validate should pass", func(t *testing.T) {
+		assert.Nil(t, ScheduleDto{Schedule: "daily", Keep: 7}.Validate())
+		assert.Nil(t, ScheduleDto{}.Validate())
+	})
+	t.Run("validate should throw validation error", func(t *testing.T) {
+		err := ScheduleDto{Schedule: "monthly", Keep: 0}.Validate()
+		assert.EqualValues(t, restErrors.NewValidationError(map[string]string{
+			"schedule": "schedule can only be hourly, daily or weekly",
+			"keep":     "keep must be between 1 and 100 snapshots",
+		}), err)
+	})
+}
+
+func TestDue(t *testing.T) {
+	now := time.Now()
+	t.Run("due should be true if no snapshot was taken", func(t *testing.T) {
+		assert.True(t, Due("daily", time.Time{}, now))
+	})
+	t.Run("due should compare the latest snapshot to the schedule period", func(t *testing.T) {
+		assert.True(t, Due("daily", now.Add(-25*time.Hour), now))
+		assert.False(t, Due("daily", now.Add(-23*time.Hour), now))
+		assert.False(t, Due("unknown", time.Time{}, now))
+	})
+}
+
+func TestExpired(t *testing.T) {
+	now := time.Now()
+	snapshots := []volumesnapshot.VolumeSnapshot{
+		readySnapshot("oldest", "1Gi", now.Add(-3*time.Hour)),
+		readySnapshot("newest", "1Gi", now),
+		readySnapshot("older", "1Gi", now.Add(-2*time.Hour)),
+	}
+
+	t.Run("expired should return the snapshots beyond the kept ones", func(t *testing.T) {
+		expired := Expired(snapshots, 2)
+		assert.Len(t, expired, 1)
+		assert.EqualValues(t, "oldest", expired[0].Name)
+	})
+	t.Run("expired should return nothing if the snapshots are within the kept ones", func(t *testing.T) {
+		assert.Empty(t, Expired(snapshots, 3))
+	})
+}
+
+func TestService_Create(t *testing.T) {
+	pvcGetFunc = func(key types.NamespacedName) (corev1.PersistentVolumeClaim, restErrors.IRestErr) {
+		return nodeClaim(nil), nil
+	}
+	volumeSnapshotCreateFunc = func(snapshot *volumesnapshot.VolumeSnapshot) restErrors.IRestErr {
+		return nil
+	}
+
+	t.Run("create should snapshot the node volume", func(t *testing.T) {
+		snapshot, err := NewService().Create(types.NamespacedName{Namespace: "default", Name: "my-node"}, CreateSnapshotDto{SnapshotClass: "csi-snapclass"}, ManualTrigger)
+		assert.Nil(t, err)
+		assert.Regexp(t, "^my-node-[0-9]{14}$", snapshot.Name)
+		assert.EqualValues(t, "my-node", *snapshot.Spec.Source.PersistentVolumeClaimName)
+		assert.EqualValues(t, "csi-snapclass", *snapshot.Spec.VolumeSnapshotClassName)
+		assert.EqualValues(t, "ethereum", snapshot.Labels["kotal.io/protocol"])
+		assert.EqualValues(t, ManualTrigger, snapshot.Labels[TriggerLabel])
+	})
+	t.Run("create should throw if the node has no volume", func(t *testing.T) {
+		pvcGetFunc = func(key types.NamespacedName) (corev1.PersistentVolumeClaim, restErrors.IRestErr) {
+			return corev1.PersistentVolumeClaim{}, restErrors.NewNotFoundError("not found")
+		}
+		_, err := NewService().Create(types.NamespacedName{Namespace: "default", Name: "my-node"}, CreateSnapshotDto{}, ManualTrigger)
+		assert.EqualValues(t, http.StatusNotFound, err.StatusCode())
+	})
+}
+
+func TestService_UpdateSchedule(t *testing.T) {
+	var updated corev1.PersistentVolumeClaim
+	pvcUpdateFunc = func(claim *corev1.PersistentVolumeClaim) restErrors.IRestErr {
+		updated = *claim
+		return nil
+	}
+	node := types.NamespacedName{Namespace: "default", Name: "my-node"}
+
+	t.Run("update schedule should annotate the node volume", func(t *testing.T) {
+		pvcGetFunc = func(key types.NamespacedName) (corev1.PersistentVolumeClaim, restErrors.IRestErr) {
+			return nodeClaim(nil), nil
+		}
+		err := NewService().UpdateSchedule(node, ScheduleDto{Schedule: "daily", Keep: 7})
+		assert.Nil(t, err)
+		assert.EqualValues(t, "daily", updated.Annotations[ScheduleAnnotation])
+		assert.EqualValues(t, "7", updated.Annotations[KeepAnnotation])
+	})
+	t.Run("update schedule should remove the schedule if empty", func(t *testing.T) {
+		pvcGetFunc = func(key types.NamespacedName) (corev1.PersistentVolumeClaim, restErrors.IRestErr) {
+			return nodeClaim(map[string]string{ScheduleAnnotation: "daily", KeepAnnotation: "7"}), nil
+		}
+		err := NewService().UpdateSchedule(node, ScheduleDto{})
+		assert.Nil(t, err)
+		assert.NotContains(t, updated.Annotations, ScheduleAnnotation)
+	})
+}
+
+func TestService_Restore(t *testing.T) {
+	var created corev1.PersistentVolumeClaim
+	pvcCreateFunc = func(claim *corev1.PersistentVolumeClaim) restErrors.IRestErr {
+		created = *claim
+		return nil
+	}
+	pvcGetFunc = func(key types.NamespacedName) (corev1.PersistentVolumeClaim, restErrors.IRestErr) {
+		return nodeClaim(nil), nil
+	}
+
+	t.Run("restore should provision the new node volume from the snapshot", func(t *testing.T) {
+		claim, err := NewService().Restore(readySnapshot("my-snapshot", "100Gi", time.Now()), RestoreDto{Name: "new-node"})
+		assert.Nil(t, err)
+		assert.EqualValues(t, created.Name, claim.Name)
+		assert.EqualValues(t, "new-node", claim.Name)
+		assert.EqualValues(t, "VolumeSnapshot", claim.Spec.DataSource.Kind)
+		assert.EqualValues(t, "my-snapshot", claim.Spec.DataSource.Name)
+		assert.EqualValues(t, "standard", *claim.Spec.StorageClassName)
+		storage := claim.Spec.Resources.Requests[corev1.ResourceStorage]
+		assert.EqualValues(t, "100Gi", storage.String())
+		assert.EqualValues(t, "kotal-operator", claim.Labels["app.kubernetes.io/managed-by"])
+	})
+	t.Run("restore should use the requested storage", func(t *testing.T) {
+		claim, err := NewService().Restore(readySnapshot("my-snapshot", "100Gi", time.Now()), RestoreDto{Name: "new-node", Storage: "200Gi"})
+		assert.Nil(t, err)
+		storage := claim.Spec.Resources.Requests[corev1.ResourceStorage]
+		assert.EqualValues(t, "200Gi", storage.String())
+	})
+	t.Run("restore should throw if the requested storage is less than the restore size", func(t *testing.T) {
+		_, err := NewService().Restore(readySnapshot("my-snapshot", "100Gi", time.Now()), RestoreDto{Name: "new-node", Storage: "50Gi"})
+		assert.EqualValues(t, restErrors.NewValidationError(map[string]string{"storage": "storage can't be less than the snapshot restore size 100Gi"}), err)
+	})
+	t.Run("restore should throw if the snapshot isn't ready", func(t *testing.T) {
+		snapshot := readySnapshot("my-snapshot", "100Gi", time.Now())
+		snapshot.Status = nil
+		_, err := NewService().Restore(snapshot, RestoreDto{Name: "new-node"})
+		assert.EqualValues(t, "snapshot my-snapshot isn't ready to use", err.Error())
+	})
+}
+
+func TestRunSchedules(t *testing.T) {
+	now := time.Now()
+	pvcListAllFunc = func() (corev1.PersistentVolumeClaimList, restErrors.IRestErr) {
+		return corev1.PersistentVolumeClaimList{Items: []corev1.PersistentVolumeClaim{
+			nodeClaim(map[string]string{ScheduleAnnotation: "daily", KeepAnnotation: "2"}),
+			{ObjectMeta: metav1.ObjectMeta{Name: "unscheduled", Namespace: "default"}},
+		}}, nil
+	}
+	pvcGetFunc = func(key types.NamespacedName) (corev1.PersistentVolumeClaim, restErrors.IRestErr) {
+		return nodeClaim(nil), nil
+	}
+
+	t.Run("run schedules should snapshot due volumes and prune the expired snapshots", func(t *testing.T) {
+		volumeSnapshotListFunc = func(namespace string, labels map[string]string) (volumesnapshot.VolumeSnapshotList, restErrors.IRestErr) {
+			assert.EqualValues(t, "my-node", labels["app.kubernetes.io/instance"])
+			manual := readySnapshot("manual", "1Gi", now.Add(-72*time.Hour))
+			manual.Labels[TriggerLabel] = ManualTrigger
+			return volumesnapshot.VolumeSnapshotList{Items: []volumesnapshot.VolumeSnapshot{
+				readySnapshot("two-days-ago", "1Gi", now.Add(-48*time.Hour)),
+				readySnapshot("yesterday", "1Gi", now.Add(-25*time.Hour)),
+				manual,
+			}}, nil
+		}
+		created := 0
+		volumeSnapshotCreateFunc = func(snapshot *volumesnapshot.VolumeSnapshot) restErrors.IRestErr {
+			created++
+			assert.EqualValues(t, ScheduledTrigger, snapshot.Labels[TriggerLabel])
+			snapshot.CreationTimestamp = metav1.NewTime(now)
+			return nil
+		}
+		deleted := []string{}
+		volumeSnapshotDeleteFunc = func(snapshot *volumesnapshot.VolumeSnapshot) restErrors.IRestErr {
+			deleted = append(deleted, snapshot.Name)
+			return nil
+		}
+
+		runSchedules(NewService(), now)
+		assert.EqualValues(t, 1, created)
+		assert.EqualValues(t, []string{"two-days-ago"}, deleted)
+	})
+
+	t.Run("run schedules should skip volumes that aren't due", func(t *testing.T) {
+		volumeSnapshotListFunc = func(namespace string, labels map[string]string) (volumesnapshot.VolumeSnapshotList, restErrors.IRestErr) {
+			return volumesnapshot.VolumeSnapshotList{Items: []volumesnapshot.VolumeSnapshot{readySnapshot("recent", "1Gi", now.Add(-time.Hour))}}, nil
+		}
+		created := 0
+		volumeSnapshotCreateFunc = func(snapshot *volumesnapshot.VolumeSnapshot) restErrors.IRestErr {
+			created++
+			return nil
+		}
+
+		runSchedules(NewService(), now)
+		assert.EqualValues(t, 0, created)
+	})
+}
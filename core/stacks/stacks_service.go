@@ -43,6 +43,7 @@ func (service stacksService) Create(dto StacksDto) (node stacksv1alpha1.Node, re
 	}
 
 	k8s.DefaultResources(&node.Spec.Resources)
+	k8s.RequestedStorage(&node.Spec.Resources, dto.Resources)
 
 	if err := k8sClient.Create(context.Background(), &node); err != nil {
 		if apiErrors.IsAlreadyExists(err) {
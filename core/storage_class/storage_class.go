@@ -1,24 +1,76 @@
 package storage_class
 
 import (
+	"github.com/go-playground/validator/v10"
+	"github.com/kotalco/core-api/k8s"
+	restErrors "github.com/kotalco/core-api/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
 	storagev1 "k8s.io/api/storage/v1"
+	"sort"
+)
+
+const (
+	// DefaultClassAnnotation marks the cluster default storage class, used by claims without storage class
+	DefaultClassAnnotation = "storageclass.kubernetes.io/is-default-class"
+	// betaDefaultClassAnnotation is the deprecated default storage class annotation still honored by kubernetes
+	betaDefaultClassAnnotation = "storageclass.beta.kubernetes.io/is-default-class"
 )
 
 // StorageClass is Kubernetes storage class
 type StorageClassDto struct {
-	Name                 string `json:"name"`
-	Provisioner          string `json:"provisioner"`
-	ReclaimPolicy        string `json:"reclaimPolicy"`
-	AllowVolumeExpansion bool   `json:"allowVolumeExpansion"`
+	Name                 string            `json:"name"`
+	Provisioner          string            `json:"provisioner" validate:"required"`
+	ReclaimPolicy        string            `json:"reclaimPolicy" validate:"omitempty,oneof=Delete Retain"`
+	AllowVolumeExpansion bool              `json:"allowVolumeExpansion"`
+	Parameters           map[string]string `json:"parameters,omitempty"`
+	VolumeBindingMode    string            `json:"volumeBindingMode" validate:"omitempty,oneof=Immediate WaitForFirstConsumer"`
+	MountOptions         []string          `json:"mountOptions,omitempty"`
+	IsDefault            bool              `json:"isDefault"`
+}
+
+// UpdateStorageClassDto holds the storage class fields kubernetes allows to change after creation
+type UpdateStorageClassDto struct {
+	AllowVolumeExpansion *bool    `json:"allowVolumeExpansion"`
+	MountOptions         []string `json:"mountOptions"`
+	IsDefault            *bool    `json:"isDefault"`
 }
 
 type StorageClassListDto []StorageClassDto
 
+// VolumeDto is a node persistent volume claim with its usage, usage is nil if the kubelet didn't report it
+type VolumeDto struct {
+	Name           string `json:"name"`
+	Node           string `json:"node"`
+	Protocol       string `json:"protocol"`
+	StorageClass   string `json:"storageClass"`
+	Phase          string `json:"phase"`
+	RequestedBytes int64  `json:"requestedBytes"`
+	CapacityBytes  *int64 `json:"capacityBytes"`
+	UsedBytes      *int64 `json:"usedBytes"`
+	AvailableBytes *int64 `json:"availableBytes"`
+	// CapacityWarning is set if the volume is above the storage warning threshold
+	CapacityWarning bool `json:"capacityWarning"`
+}
+
+type VolumesDto struct {
+	Volumes []VolumeDto `json:"volumes"`
+	// DeletableStorageClasses are the storage classes no persistent volume claim references
+	DeletableStorageClasses []string `json:"deletableStorageClasses"`
+}
+
 func (dto StorageClassDto) FromCoreStorageClass(sc storagev1.StorageClass) StorageClassDto {
 	dto.Name = sc.Name
 	dto.Provisioner = sc.Provisioner
-	dto.ReclaimPolicy = string(*sc.ReclaimPolicy)
+	if sc.ReclaimPolicy != nil {
+		dto.ReclaimPolicy = string(*sc.ReclaimPolicy)
+	}
 	dto.AllowVolumeExpansion = sc.AllowVolumeExpansion != nil && *sc.AllowVolumeExpansion
+	dto.Parameters = sc.Parameters
+	if sc.VolumeBindingMode != nil {
+		dto.VolumeBindingMode = string(*sc.VolumeBindingMode)
+	}
+	dto.MountOptions = sc.MountOptions
+	dto.IsDefault = IsDefault(sc)
 
 	return dto
 }
@@ -30,3 +82,79 @@ func (storageClassListDto StorageClassListDto) FromCoreSecret(list []storagev1.S
 	}
 	return result
 }
+
+// Validate validates the storage class name and spec
+func (dto StorageClassDto) Validate() restErrors.IRestErr {
+	meta := k8s.MetaDataDto{Name: dto.Name}
+	restErr := meta.Validate()
+	if restErr != nil {
+		return restErr
+	}
+
+	err := validator.New().Struct(dto)
+	if err != nil {
+		fields := map[string]string{}
+		for _, err := range err.(validator.ValidationErrors) {
+			switch err.Field() {
+			case "Provisioner":
+				fields["provisioner"] = "provisioner is required"
+			case "ReclaimPolicy":
+				fields["reclaimPolicy"] = "reclaimPolicy can only be Delete or Retain"
+			case "VolumeBindingMode":
+				fields["volumeBindingMode"] = "volumeBindingMode can only be Immediate or WaitForFirstConsumer"
+			}
+		}
+
+		if len(fields) > 0 {
+			return restErrors.NewValidationError(fields)
+		}
+	}
+
+	return nil
+}
+
+// IsDefault checks if the storage class is annotated as the cluster default storage class
+func IsDefault(sc storagev1.StorageClass) bool {
+	return sc.Annotations[DefaultClassAnnotation] == "true" || sc.Annotations[betaDefaultClassAnnotation] == "true"
+}
+
+// ClaimStorageClass returns the storage class name the claim uses, claims without storage class use the default one
+func ClaimStorageClass(claim corev1.PersistentVolumeClaim, classes []storagev1.StorageClass) string {
+	if claim.Spec.StorageClassName != nil {
+		return *claim.Spec.StorageClassName
+	}
+	for _, sc := range classes {
+		if IsDefault(sc) {
+			return sc.Name
+		}
+	}
+	return ""
+}
+
+// References returns the claims referencing the storage class with the given name
+func References(name string, classes []storagev1.StorageClass, claims []corev1.PersistentVolumeClaim) []corev1.PersistentVolumeClaim {
+	references := make([]corev1.PersistentVolumeClaim, 0)
+	for _, claim := range claims {
+		if ClaimStorageClass(claim, classes) == name {
+			references = append(references, claim)
+		}
+	}
+	return references
+}
+
+// Unreferenced returns the names of the storage classes no claim references, which are safe to delete
+func Unreferenced(classes []storagev1.StorageClass, claims []corev1.PersistentVolumeClaim) []string {
+	referenced := map[string]bool{}
+	for _, claim := range claims {
+		referenced[ClaimStorageClass(claim, classes)] = true
+	}
+
+	names := make([]string, 0)
+	for _, sc := range classes {
+		if !referenced[sc.Name] {
+			names = append(names, sc.Name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
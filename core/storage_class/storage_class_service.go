@@ -8,8 +8,10 @@ import (
 	"github.com/kotalco/core-api/k8s"
 	restErrors "github.com/kotalco/core-api/pkg/errors"
 	"github.com/kotalco/core-api/pkg/logger"
+	corev1 "k8s.io/api/core/v1"
 	storagev1 "k8s.io/api/storage/v1"
 	apiErrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
 )
 
@@ -18,7 +20,7 @@ type storageClassService struct{}
 type IService interface {
 	Get(name string) (storagev1.StorageClass, restErrors.IRestErr)
 	Create(dto StorageClassDto) (storagev1.StorageClass, restErrors.IRestErr)
-	Update(StorageClassDto, *storagev1.StorageClass) restErrors.IRestErr
+	Update(UpdateStorageClassDto, *storagev1.StorageClass) restErrors.IRestErr
 	List() (storagev1.StorageClassList, restErrors.IRestErr)
 	Delete(*storagev1.StorageClass) restErrors.IRestErr
 	Count() (int, restErrors.IRestErr)
@@ -49,14 +51,71 @@ func (service storageClassService) Get(name string) (storageClass storagev1.Stor
 }
 
 // Create creates a storage class from the given spec
-// todo
+// if the storage class is the default one, the default annotation is removed from the other storage classes
 func (service storageClassService) Create(dto StorageClassDto) (storageClass storagev1.StorageClass, restErr restErrors.IRestErr) {
+	reclaimPolicy := corev1.PersistentVolumeReclaimDelete
+	if dto.ReclaimPolicy != "" {
+		reclaimPolicy = corev1.PersistentVolumeReclaimPolicy(dto.ReclaimPolicy)
+	}
+	volumeBindingMode := storagev1.VolumeBindingImmediate
+	if dto.VolumeBindingMode != "" {
+		volumeBindingMode = storagev1.VolumeBindingMode(dto.VolumeBindingMode)
+	}
+
+	storageClass.ObjectMeta = metav1.ObjectMeta{
+		Name: dto.Name,
+		Labels: map[string]string{
+			"app.kubernetes.io/created-by": "kotal-api",
+		},
+	}
+	if dto.IsDefault {
+		storageClass.Annotations = map[string]string{DefaultClassAnnotation: "true"}
+	}
+	storageClass.Provisioner = dto.Provisioner
+	storageClass.Parameters = dto.Parameters
+	storageClass.ReclaimPolicy = &reclaimPolicy
+	storageClass.VolumeBindingMode = &volumeBindingMode
+	storageClass.MountOptions = dto.MountOptions
+	storageClass.AllowVolumeExpansion = &dto.AllowVolumeExpansion
+
+	if err := k8sClient.Create(context.Background(), &storageClass); err != nil {
+		if apiErrors.IsAlreadyExists(err) {
+			restErr = restErrors.NewBadRequestError(fmt.Sprintf("storage class by name %s already exist", dto.Name))
+			return
+		}
+		go logger.Error(service.Create, err)
+		restErr = restErrors.NewInternalServerError("error creating storage class")
+		return
+	}
+
+	if dto.IsDefault {
+		restErr = service.unsetDefault(storageClass.Name)
+	}
 	return
 }
 
-// Update creates a storage class from the given spec
-// todo
-func (service storageClassService) Update(dto StorageClassDto, storageClass *storagev1.StorageClass) (restErr restErrors.IRestErr) {
+// Update updates the storage class mutable fields, kubernetes forbids updating the provisioner, parameters, reclaim policy and binding mode
+// if the storage class became the default one, the default annotation is removed from the other storage classes
+func (service storageClassService) Update(dto UpdateStorageClassDto, storageClass *storagev1.StorageClass) (restErr restErrors.IRestErr) {
+	if dto.AllowVolumeExpansion != nil {
+		storageClass.AllowVolumeExpansion = dto.AllowVolumeExpansion
+	}
+	if dto.MountOptions != nil {
+		storageClass.MountOptions = dto.MountOptions
+	}
+	if dto.IsDefault != nil {
+		setDefaultAnnotation(storageClass, *dto.IsDefault)
+	}
+
+	if err := k8sClient.Update(context.Background(), storageClass); err != nil {
+		go logger.Error(service.Update, err)
+		restErr = restErrors.NewInternalServerError(fmt.Sprintf("can't update storage class by name %s", storageClass.Name))
+		return
+	}
+
+	if dto.IsDefault != nil && *dto.IsDefault {
+		restErr = service.unsetDefault(storageClass.Name)
+	}
 	return
 }
 
@@ -70,14 +129,54 @@ func (service storageClassService) List() (list storagev1.StorageClassList, rest
 	return
 }
 
-// Delete a single storage node by name
-// todo
+// Delete a single storage class by name
 func (service storageClassService) Delete(storageClass *storagev1.StorageClass) (restErr restErrors.IRestErr) {
+	if err := k8sClient.Delete(context.Background(), storageClass); err != nil {
+		go logger.Error(service.Delete, err)
+		restErr = restErrors.NewInternalServerError(fmt.Sprintf("can't delete storage class by name %s", storageClass.Name))
+		return
+	}
 	return
 }
 
-// Count a list of storage classes
-// todo
+// Count counts the storage classes
 func (service storageClassService) Count() (count int, restErr restErrors.IRestErr) {
-	return
+	list, restErr := service.List()
+	if restErr != nil {
+		return
+	}
+	return len(list.Items), nil
+}
+
+// unsetDefault removes the default annotation from all the storage classes except the one with the given name
+func (service storageClassService) unsetDefault(except string) restErrors.IRestErr {
+	list, restErr := service.List()
+	if restErr != nil {
+		return restErr
+	}
+
+	for i := range list.Items {
+		sc := &list.Items[i]
+		if sc.Name == except || !IsDefault(*sc) {
+			continue
+		}
+		setDefaultAnnotation(sc, false)
+		if err := k8sClient.Update(context.Background(), sc); err != nil {
+			go logger.Error(service.unsetDefault, err)
+			return restErrors.NewInternalServerError(fmt.Sprintf("can't unset storage class %s as default", sc.Name))
+		}
+	}
+	return nil
+}
+
+func setDefaultAnnotation(storageClass *storagev1.StorageClass, isDefault bool) {
+	if isDefault {
+		if storageClass.Annotations == nil {
+			storageClass.Annotations = map[string]string{}
+		}
+		storageClass.Annotations[DefaultClassAnnotation] = "true"
+		return
+	}
+	delete(storageClass.Annotations, DefaultClassAnnotation)
+	delete(storageClass.Annotations, betaDefaultClassAnnotation)
 }
@@ -1 +1,89 @@
 package storage_class
+
+import (
+	restErrors "github.com/kotalco/core-api/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	storagev1 "k8s.io/api/storage/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"testing"
+)
+
+func storageClass(name string, isDefault bool) storagev1.StorageClass {
+	sc := storagev1.StorageClass{ObjectMeta: metav1.ObjectMeta{Name: name}}
+	if isDefault {
+		sc.Annotations = map[string]string{DefaultClassAnnotation: "true"}
+	}
+	return sc
+}
+
+func claim(storageClassName *string) corev1.PersistentVolumeClaim {
+	return corev1.PersistentVolumeClaim{Spec: corev1.PersistentVolumeClaimSpec{StorageClassName: storageClassName}}
+}
+
+func TestStorageClassDto_FromCoreStorageClass(t *testing.T) {
+	t.Run("from core storage class should map all fields", func(t *testing.T) {
+		reclaimPolicy := corev1.PersistentVolumeReclaimRetain
+		bindingMode := storagev1.VolumeBindingWaitForFirstConsumer
+		expansion := true
+		sc := storageClass("fast", true)
+		sc.Provisioner = "ebs.csi.aws.com"
+		sc.Parameters = map[string]string{"type": "gp3"}
+		sc.ReclaimPolicy = &reclaimPolicy
+		sc.VolumeBindingMode = &bindingMode
+		sc.MountOptions = []string{"noatime"}
+		sc.AllowVolumeExpansion = &expansion
+
+		dto := new(StorageClassDto).FromCoreStorageClass(sc)
+		assert.EqualValues(t, StorageClassDto{
+			Name:                 "fast",
+			Provisioner:          "ebs.csi.aws.com",
+			ReclaimPolicy:        "Retain",
+			AllowVolumeExpansion: true,
+			Parameters:           map[string]string{"type": "gp3"},
+			VolumeBindingMode:    "WaitForFirstConsumer",
+			MountOptions:         []string{"noatime"},
+			IsDefault:            true,
+		}, dto)
+	})
+}
+
+func TestStorageClassDto_Validate(t *testing.T) {
+	t.Run("validate should pass", func(t *testing.T) {
+		dto := StorageClassDto{Name: "fast", Provisioner: "ebs.csi.aws.com", ReclaimPolicy: "Retain", VolumeBindingMode: "Immediate"}
+		assert.Nil(t, dto.Validate())
+	})
+	t.Run("validate should throw validation error", func(t *testing.T) {
+		dto := StorageClassDto{Name: "fast", ReclaimPolicy: "Recycle", VolumeBindingMode: "Lazy"}
+		err := dto.Validate()
+		assert.EqualValues(t, restErrors.NewValidationError(map[string]string{
+			"provisioner":       "provisioner is required",
+			"reclaimPolicy":     "reclaimPolicy can only be Delete or Retain",
+			"volumeBindingMode": "volumeBindingMode can only be Immediate or WaitForFirstConsumer",
+		}), err)
+	})
+}
+
+func TestClaimStorageClass(t *testing.T) {
+	classes := []storagev1.StorageClass{storageClass("standard", true), storageClass("fast", false)}
+	fast := "fast"
+
+	t.Run("claim storage class should return the claim storage class", func(t *testing.T) {
+		assert.EqualValues(t, "fast", ClaimStorageClass(claim(&fast), classes))
+	})
+	t.Run("claim storage class should return the default storage class if the claim has none", func(t *testing.T) {
+		assert.EqualValues(t, "standard", ClaimStorageClass(claim(nil), classes))
+	})
+}
+
+func TestUnreferenced(t *testing.T) {
+	t.Run("unreferenced should return the storage classes no claim references", func(t *testing.T) {
+		classes := []storagev1.StorageClass{storageClass("standard", true), storageClass("fast", false), storageClass("archive", false)}
+		fast := "fast"
+		claims := []corev1.PersistentVolumeClaim{claim(&fast), claim(nil)}
+
+		assert.EqualValues(t, []string{"archive"}, Unreferenced(classes, claims))
+		assert.Len(t, References("fast", classes, claims), 1)
+		assert.Empty(t, References("archive", classes, claims))
+	})
+}
@@ -52,6 +52,11 @@ type DisableTOTPRequestDto struct {
 	Password string `json:"password" validate:"required,gte=6,lte=100"`
 }
 
+type DeleteAccountRequestDto struct {
+	Password string `json:"password" validate:"required,gte=6,lte=100"`
+	TOTP     string `json:"totp"`
+}
+
 type TOTPRequestDto struct {
 	TOTP string `json:"totp"`
 }
@@ -61,6 +66,7 @@ type UserResponseDto struct {
 	TwoFactorEnabled bool `json:"two_factor_enabled"`
 	PlatformAdmin    bool `json:"platform_admin"`
 	IsCustomer       bool `json:"is_customer"`
+	Disabled         bool `json:"disabled"`
 }
 
 type PublicUserResponseDto struct {
@@ -80,6 +86,7 @@ func (dto UserResponseDto) Marshall(model *User) UserResponseDto {
 	dto.Email = model.Email
 	dto.TwoFactorEnabled = model.TwoFactorEnabled
 	dto.PlatformAdmin = model.PlatformAdmin
+	dto.Disabled = model.Disabled
 	return dto
 }
 
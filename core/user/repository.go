@@ -22,6 +22,12 @@ type IRepository interface {
 	Update(user *User) restErrors.IRestErr
 	FindWhereIdInSlice(ids []string) ([]*User, restErrors.IRestErr)
 	Count() (int64, restErrors.IRestErr)
+	Search(email string) ([]*User, restErrors.IRestErr)
+	CreatePasswordHistory(history *PasswordHistory) restErrors.IRestErr
+	GetPasswordHistory(userId string, limit int) ([]*PasswordHistory, restErrors.IRestErr)
+	PrunePasswordHistory(userId string, keep int) restErrors.IRestErr
+	Delete(user *User) restErrors.IRestErr
+	CountPlatformAdmins() (int64, restErrors.IRestErr)
 }
 
 func NewRepository() IRepository {
@@ -99,3 +105,72 @@ func (r repository) Count() (int64, restErrors.IRestErr) {
 	}
 	return count, nil
 }
+
+// Search returns users whose email contains the given term, all users if the term is empty
+func (r repository) Search(email string) ([]*User, restErrors.IRestErr) {
+	var users []*User
+	query := r.db.Order("email")
+	if email != "" {
+		query = query.Where("email ILIKE ?", "%"+email+"%")
+	}
+	result := query.Find(&users)
+	if result.Error != nil {
+		go logger.Error(repository.Search, result.Error)
+		return nil, restErrors.NewInternalServerError("something went wrong")
+	}
+	return users, nil
+}
+
+func (r repository) CreatePasswordHistory(history *PasswordHistory) restErrors.IRestErr {
+	res := r.db.Create(history)
+	if res.Error != nil {
+		go logger.Error(repository.CreatePasswordHistory, res.Error)
+		return restErrors.NewInternalServerError("error processing your request")
+	}
+	return nil
+}
+
+// GetPasswordHistory returns the user last previous passwords, newest first
+func (r repository) GetPasswordHistory(userId string, limit int) ([]*PasswordHistory, restErrors.IRestErr) {
+	var history []*PasswordHistory
+	result := r.db.Where("user_id = ?", userId).Order("created_at desc").Limit(limit).Find(&history)
+	if result.Error != nil {
+		go logger.Error(repository.GetPasswordHistory, result.Error)
+		return nil, restErrors.NewInternalServerError("something went wrong")
+	}
+	return history, nil
+}
+
+// PrunePasswordHistory deletes the user previous passwords except the newest keep records
+func (r repository) PrunePasswordHistory(userId string, keep int) restErrors.IRestErr {
+	query := r.db.Where("user_id = ?", userId)
+	if keep > 0 {
+		newest := r.db.Model(new(PasswordHistory)).Select("id").Where("user_id = ?", userId).Order("created_at desc").Limit(keep)
+		query = query.Where("id NOT IN (?)", newest)
+	}
+	result := query.Delete(new(PasswordHistory))
+	if result.Error != nil {
+		go logger.Error(repository.PrunePasswordHistory, result.Error)
+		return restErrors.NewInternalServerError("something went wrong")
+	}
+	return nil
+}
+
+func (r repository) Delete(user *User) restErrors.IRestErr {
+	result := r.db.Delete(user)
+	if result.Error != nil {
+		go logger.Error(repository.Delete, result.Error)
+		return restErrors.NewInternalServerError("something went wrong")
+	}
+	return nil
+}
+
+func (r repository) CountPlatformAdmins() (int64, restErrors.IRestErr) {
+	var count int64
+	result := r.db.Model(User{}).Where("platform_admin = ?", true).Count(&count)
+	if result.Error != nil {
+		go logger.Error(repository.CountPlatformAdmins, result.Error)
+		return 0, restErrors.NewInternalServerError("something went wrong")
+	}
+	return count, nil
+}
@@ -1,6 +1,7 @@
 package user
 
 import (
+	"fmt"
 	"github.com/google/uuid"
 	"github.com/kotalco/core-api/pkg/security"
 	"github.com/kotalco/core-api/pkg/sqlclient"
@@ -14,7 +15,7 @@ var (
 )
 
 func init() {
-	err := sqlclient.OpenDBConnection().AutoMigrate(new(User))
+	err := sqlclient.OpenDBConnection().AutoMigrate(new(User), new(PasswordHistory))
 	if err != nil {
 		panic(err.Error())
 	}
@@ -105,6 +106,50 @@ func TestRepository_Count(t *testing.T) {
 	})
 }
 
+func TestRepository_Search(t *testing.T) {
+	t.Run("search should return users matching the email", func(t *testing.T) {
+		user := createUser(t)
+		users, err := repo.Search(user.Email[:5])
+		assert.Nil(t, err)
+		assert.Len(t, users, 1)
+		assert.EqualValues(t, user.ID, users[0].ID)
+		cleanUp(user)
+	})
+
+	t.Run("search should return empty list if no user matches", func(t *testing.T) {
+		users, err := repo.Search("no-such-email")
+		assert.Nil(t, err)
+		assert.Len(t, users, 0)
+	})
+}
+
+func TestRepository_PasswordHistory(t *testing.T) {
+	t.Run("Password_History_Should_Be_Returned_Newest_First_And_Pruned", func(t *testing.T) {
+		user := createUser(t)
+		for i := 1; i <= 3; i++ {
+			restErr := repo.CreatePasswordHistory(&PasswordHistory{ID: uuid.NewString(), UserId: user.ID, Password: fmt.Sprintf("hash-%d", i), CreatedAt: int64(i)})
+			assert.Nil(t, restErr)
+		}
+
+		history, restErr := repo.GetPasswordHistory(user.ID, 2)
+		assert.Nil(t, restErr)
+		assert.Len(t, history, 2)
+		assert.EqualValues(t, "hash-3", history[0].Password)
+
+		restErr = repo.PrunePasswordHistory(user.ID, 1)
+		assert.Nil(t, restErr)
+		history, _ = repo.GetPasswordHistory(user.ID, 10)
+		assert.Len(t, history, 1)
+		assert.EqualValues(t, "hash-3", history[0].Password)
+
+		restErr = repo.PrunePasswordHistory(user.ID, 0)
+		assert.Nil(t, restErr)
+		history, _ = repo.GetPasswordHistory(user.ID, 10)
+		assert.Empty(t, history)
+		cleanUp(user)
+	})
+}
+
 func createUser(t *testing.T) User {
 	user := new(User)
 	user.ID = uuid.New().String()
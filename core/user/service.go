@@ -2,18 +2,29 @@ package user
 
 import (
 	"bytes"
+	"fmt"
 	"github.com/google/uuid"
 	"github.com/kotalco/core-api/config"
+	"github.com/kotalco/core-api/core/loginattempt"
+	"github.com/kotalco/core-api/core/setting"
 	restErrors "github.com/kotalco/core-api/pkg/errors"
 	"github.com/kotalco/core-api/pkg/logger"
+	"github.com/kotalco/core-api/pkg/mailer"
 	"github.com/kotalco/core-api/pkg/security"
 	"github.com/kotalco/core-api/pkg/tfa"
 	"github.com/kotalco/core-api/pkg/token"
 	"gorm.io/gorm"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
 )
 
 type service struct{}
 
+// invalidatedPasswordPrefix marks a password hash invalidated by an admin, a prefixed hash never matches any password
+const invalidatedPasswordPrefix = "!"
+
 type IService interface {
 	WithTransaction(txHandle *gorm.DB) IService
 	WithoutTransaction() IService
@@ -24,6 +35,7 @@ type IService interface {
 	GetById(ID string) (*User, restErrors.IRestErr)
 	VerifyEmail(model *User) restErrors.IRestErr
 	ResetPassword(model *User, password string) restErrors.IRestErr
+	InvalidatePassword(model *User) restErrors.IRestErr
 	ChangePassword(model *User, dto *ChangePasswordRequestDto) restErrors.IRestErr
 	ChangeEmail(model *User, dto *ChangeEmailRequestDto) restErrors.IRestErr
 	CreateTOTP(model *User, dto *CreateTOTPRequestDto) (bytes.Buffer, restErrors.IRestErr)
@@ -32,6 +44,14 @@ type IService interface {
 	FindWhereIdInSlice(ids []string) ([]*User, restErrors.IRestErr)
 	Count() (int64, restErrors.IRestErr)
 	SetAsPlatformAdmin(model *User) restErrors.IRestErr
+	UnsetPlatformAdmin(model *User) restErrors.IRestErr
+	Search(email string) ([]*User, restErrors.IRestErr)
+	Disable(model *User) restErrors.IRestErr
+	Enable(model *User) restErrors.IRestErr
+	ResetTwoFactorAuth(model *User) restErrors.IRestErr
+	CheckPasswordPolicy(model *User, password string) restErrors.IRestErr
+	Reauthenticate(model *User, dto *DeleteAccountRequestDto) restErrors.IRestErr
+	Delete(model *User) restErrors.IRestErr
 }
 
 var (
@@ -40,6 +60,11 @@ var (
 	hashing        = security.NewHashing()
 	tfaService     = tfa.NewTfa()
 	tokenService   = token.NewToken()
+
+	loginAttemptService = loginattempt.NewService()
+	mailService         = mailer.NewService()
+	settingService      = setting.NewService()
+	breachedPasswords   = security.NewBreachedPasswords()
 )
 
 func NewService() IService {
@@ -49,10 +74,12 @@ func NewService() IService {
 
 func (uService service) WithTransaction(txHandle *gorm.DB) IService {
 	userRepository = userRepository.WithTransaction(txHandle)
+	loginAttemptService = loginAttemptService.WithTransaction(txHandle)
 	return uService
 }
 func (uService service) WithoutTransaction() IService {
 	userRepository = userRepository.WithoutTransaction()
+	loginAttemptService = loginAttemptService.WithoutTransaction()
 	return uService
 }
 
@@ -69,6 +96,7 @@ func (service) SignUp(dto *SignUpRequestDto) (*User, restErrors.IRestErr) {
 	user.Email = dto.Email
 	user.IsEmailVerified = false
 	user.Password = string(hashedPassword)
+	user.PasswordChangedAt = time.Now().Unix()
 
 	restErr := userRepository.Create(user)
 	if restErr != nil {
@@ -94,9 +122,23 @@ func (service) SignIn(dto *SignInRequestDto) (*UserSessionResponseDto, restError
 		}
 	}
 
-	invalidPassError := hashing.VerifyHash(user.Password, dto.Password)
-	if invalidPassError != nil {
-		return nil, restErrors.NewUnAuthorizedError("Invalid Credentials")
+	failed, restErr := loginAttemptService.Attempt(user.ID, func() bool {
+		return hashing.VerifyHash(user.Password, dto.Password) == nil
+	})
+	if restErr != nil {
+		return nil, restErr
+	}
+	if failed != nil {
+		return nil, failedAttempt(user, failed, restErrors.NewUnAuthorizedError("Invalid Credentials"))
+	}
+
+	if user.Disabled {
+		return nil, restErrors.NewForbiddenError("account disabled")
+	}
+
+	restErr = checkPasswordAge(user)
+	if restErr != nil {
+		return nil, restErr
 	}
 
 	var authorized bool
@@ -104,6 +146,11 @@ func (service) SignIn(dto *SignInRequestDto) (*UserSessionResponseDto, restError
 		authorized = false
 	} else {
 		authorized = true
+		// users with 2fa keep their failed attempts till they verify their totp
+		restErr = loginAttemptService.Reset(user.ID)
+		if restErr != nil {
+			return nil, restErr
+		}
 	}
 
 	token, err := tokenService.CreateToken(user.ID, dto.RememberMe, authorized)
@@ -157,9 +204,12 @@ func (service) ResetPassword(model *User, password string) restErrors.IRestErr {
 		return restErrors.NewInternalServerError("something went wrong.")
 	}
 
-	model.Password = string(hashedPassword)
+	err := rotatePassword(model, string(hashedPassword))
+	if err != nil {
+		return err
+	}
 
-	err := userRepository.Update(model)
+	err = userRepository.Update(model)
 	if err != nil {
 		return err
 	}
@@ -167,6 +217,16 @@ func (service) ResetPassword(model *User, password string) restErrors.IRestErr {
 	return nil
 }
 
+// InvalidatePassword locks the user current password so it can't be used to sign in until the user resets it
+// the password history isn't touched, the locked password is recorded as the user real password on the next reset
+func (service) InvalidatePassword(model *User) restErrors.IRestErr {
+	if strings.HasPrefix(model.Password, invalidatedPasswordPrefix) {
+		return nil
+	}
+	model.Password = invalidatedPasswordPrefix + model.Password
+	return userRepository.Update(model)
+}
+
 // ChangePassword change user password  for authenticated users
 func (service) ChangePassword(model *User, dto *ChangePasswordRequestDto) restErrors.IRestErr {
 
@@ -175,15 +235,23 @@ func (service) ChangePassword(model *User, dto *ChangePasswordRequestDto) restEr
 		return restErrors.NewUnAuthorizedError("invalid old password")
 	}
 
+	err := checkPasswordPolicy(model, dto.Password)
+	if err != nil {
+		return err
+	}
+
 	hashedPassword, error := hashing.Hash(dto.Password, 13)
 	if error != nil {
 		go logger.Error(service.ChangePassword, error)
 		return restErrors.NewInternalServerError("something went wrong.")
 	}
 
-	model.Password = string(hashedPassword)
+	err = rotatePassword(model, string(hashedPassword))
+	if err != nil {
+		return err
+	}
 
-	err := userRepository.Update(model)
+	err = userRepository.Update(model)
 	if err != nil {
 		return err
 	}
@@ -277,9 +345,19 @@ func (service) VerifyTOTP(model *User, totp string) (*UserSessionResponseDto, re
 		return nil, restErrors.NewInternalServerError("something went wrong")
 	}
 
-	valid := tfaService.CheckOtp(TOTPSecret, totp)
-	if !valid {
-		return nil, restErrors.NewBadRequestError("invalid totp code")
+	failed, restErr := loginAttemptService.Attempt(model.ID, func() bool {
+		return tfaService.CheckOtp(TOTPSecret, totp)
+	})
+	if restErr != nil {
+		return nil, restErr
+	}
+	if failed != nil {
+		return nil, failedAttempt(model, failed, restErrors.NewBadRequestError("invalid totp code"))
+	}
+
+	restErr = loginAttemptService.Reset(model.ID)
+	if restErr != nil {
+		return nil, restErr
 	}
 
 	token, restErr := tokenService.CreateToken(model.ID, true, true)
@@ -332,3 +410,254 @@ func (service) SetAsPlatformAdmin(model *User) restErrors.IRestErr {
 	return userRepository.Update(model)
 
 }
+
+// UnsetPlatformAdmin removes the platform admin privileges from the passed user
+func (service) UnsetPlatformAdmin(model *User) restErrors.IRestErr {
+	model.PlatformAdmin = false
+	return userRepository.Update(model)
+}
+
+// Search returns users whose email matches the search term
+func (service) Search(email string) ([]*User, restErrors.IRestErr) {
+	return userRepository.Search(email)
+}
+
+// Disable prevents the user from signing in or using existing tokens
+func (service) Disable(model *User) restErrors.IRestErr {
+	model.Disabled = true
+	return userRepository.Update(model)
+}
+
+// Enable re-enables a disabled user
+func (service) Enable(model *User) restErrors.IRestErr {
+	model.Disabled = false
+	return userRepository.Update(model)
+}
+
+// ResetTwoFactorAuth disables two-factor auth for the user without password confirmation, used by platform admins
+func (service) ResetTwoFactorAuth(model *User) restErrors.IRestErr {
+	model.TwoFactorEnabled = false
+	model.TwoFactorCipher = ""
+	return userRepository.Update(model)
+}
+
+// CheckPasswordPolicy validates the password against the platform password policy
+// model is nil for new users, otherwise the password is checked against the user recent passwords
+func (service) CheckPasswordPolicy(model *User, password string) restErrors.IRestErr {
+	return checkPasswordPolicy(model, password)
+}
+
+// Reauthenticate verifies the user password and the totp if 2fa is enabled before sensitive actions like deleting the account
+// failures count towards the account lockout the same as sign in failures
+func (service) Reauthenticate(model *User, dto *DeleteAccountRequestDto) restErrors.IRestErr {
+	var TOTPSecret string
+	if model.TwoFactorEnabled {
+		var err error
+		TOTPSecret, err = encryption.Decrypt(model.TwoFactorCipher, config.Environment.TwoFactorSecret)
+		if err != nil {
+			go logger.Error(service.Reauthenticate, err)
+			return restErrors.NewInternalServerError("something went wrong")
+		}
+	}
+
+	var failure restErrors.IRestErr
+	failed, restErr := loginAttemptService.Attempt(model.ID, func() bool {
+		if hashing.VerifyHash(model.Password, dto.Password) != nil {
+			failure = restErrors.NewBadRequestError("Invalid password")
+			return false
+		}
+		if model.TwoFactorEnabled && !tfaService.CheckOtp(TOTPSecret, dto.TOTP) {
+			failure = restErrors.NewBadRequestError("invalid totp code")
+			return false
+		}
+		return true
+	})
+	if restErr != nil {
+		return restErr
+	}
+	if failed != nil {
+		return failedAttempt(model, failed, failure)
+	}
+
+	return nil
+}
+
+// Delete deletes the user record with its password history and failed sign in attempts
+// the last platform admin can't be deleted, otherwise no one would be able to administrate the platform
+func (service) Delete(model *User) restErrors.IRestErr {
+	if model.PlatformAdmin {
+		count, err := userRepository.CountPlatformAdmins()
+		if err != nil {
+			return err
+		}
+		if count <= 1 {
+			return restErrors.NewBadRequestError("you're the only platform admin, promote another user before deleting your account")
+		}
+	}
+
+	err := userRepository.PrunePasswordHistory(model.ID, 0)
+	if err != nil {
+		return err
+	}
+
+	err = loginAttemptService.Reset(model.ID)
+	if err != nil {
+		return err
+	}
+
+	return userRepository.Delete(model)
+}
+
+func checkPasswordPolicy(model *User, password string) restErrors.IRestErr {
+	policy, restErr := settingService.GetPasswordPolicy()
+	if restErr != nil {
+		return restErr
+	}
+
+	// every violated rule is reported under its own key, so clients can show which rules failed
+	violations := map[string]string{}
+	if uint(utf8.RuneCountInString(password)) < policy.MinLength {
+		violations["password.minLength"] = fmt.Sprintf("password should be at least %d chars", policy.MinLength)
+	}
+
+	var hasUpper, hasLower, hasDigit, hasSymbol bool
+	for _, char := range password {
+		switch {
+		case unicode.IsUpper(char):
+			hasUpper = true
+		case unicode.IsLower(char):
+			hasLower = true
+		case unicode.IsDigit(char):
+			hasDigit = true
+		case unicode.IsPunct(char) || unicode.IsSymbol(char):
+			hasSymbol = true
+		}
+	}
+	if policy.RequireUppercase && !hasUpper {
+		violations["password.uppercase"] = "password should contain an uppercase letter"
+	}
+	if policy.RequireLowercase && !hasLower {
+		violations["password.lowercase"] = "password should contain a lowercase letter"
+	}
+	if policy.RequireDigit && !hasDigit {
+		violations["password.digit"] = "password should contain a digit"
+	}
+	if policy.RequireSymbol && !hasSymbol {
+		violations["password.symbol"] = "password should contain a symbol"
+	}
+
+	if policy.CheckBreached && breachedPasswords.IsBreached(password) {
+		violations["password.breached"] = "password has appeared in a data breach, please choose a different password"
+	}
+
+	if model != nil && policy.HistorySize > 0 {
+		reused, restErr := isRecentPassword(model, password, policy.HistorySize)
+		if restErr != nil {
+			return restErr
+		}
+		if reused {
+			violations["password.history"] = fmt.Sprintf("password can't be one of your last %d passwords", policy.HistorySize)
+		}
+	}
+
+	if len(violations) > 0 {
+		return restErrors.NewValidationError(violations)
+	}
+	return nil
+}
+
+// isRecentPassword checks the password against the user current password and the previous historySize-1 passwords
+func isRecentPassword(model *User, password string, historySize uint) (bool, restErrors.IRestErr) {
+	if hashing.VerifyHash(currentPasswordHash(model), password) == nil {
+		return true, nil
+	}
+	if historySize == 1 {
+		return false, nil
+	}
+
+	history, restErr := userRepository.GetPasswordHistory(model.ID, int(historySize-1))
+	if restErr != nil {
+		return false, restErr
+	}
+	for _, record := range history {
+		if hashing.VerifyHash(record.Password, password) == nil {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// rotatePassword moves the user current password to the password history and sets the new hashed password
+// the history is pruned to the policy history size
+func rotatePassword(model *User, hashedPassword string) restErrors.IRestErr {
+	policy, restErr := settingService.GetPasswordPolicy()
+	if restErr != nil {
+		return restErr
+	}
+
+	keep := 0
+	if policy.HistorySize > 1 {
+		keep = int(policy.HistorySize - 1)
+		record := new(PasswordHistory)
+		record.ID = uuid.NewString()
+		record.UserId = model.ID
+		record.Password = currentPasswordHash(model)
+		record.CreatedAt = time.Now().Unix()
+		restErr = userRepository.CreatePasswordHistory(record)
+		if restErr != nil {
+			return restErr
+		}
+	}
+
+	restErr = userRepository.PrunePasswordHistory(model.ID, keep)
+	if restErr != nil {
+		return restErr
+	}
+
+	model.Password = hashedPassword
+	model.PasswordChangedAt = time.Now().Unix()
+	return nil
+}
+
+// currentPasswordHash returns the user current password hash, including passwords invalidated by an admin
+func currentPasswordHash(model *User) string {
+	return strings.TrimPrefix(model.Password, invalidatedPasswordPrefix)
+}
+
+// checkPasswordAge returns forbidden error if the user password is older than the password policy max age
+// users who didn't change their password since the policy was introduced start the clock from their next sign in
+func checkPasswordAge(model *User) restErrors.IRestErr {
+	policy, restErr := settingService.GetPasswordPolicy()
+	if restErr != nil {
+		return restErr
+	}
+
+	if model.PasswordChangedAt == 0 {
+		model.PasswordChangedAt = time.Now().Unix()
+		return userRepository.Update(model)
+	}
+
+	if policy.MaxAgeDays == 0 {
+		return nil
+	}
+	maxAge := time.Duration(policy.MaxAgeDays) * 24 * time.Hour
+	if time.Since(time.Unix(model.PasswordChangedAt, 0)) > maxAge {
+		return restErrors.NewForbiddenError("password expired, please reset your password")
+	}
+	return nil
+}
+
+// failedAttempt returns failure of the recorded failed sign in or totp attempt,
+// once the account gets locked the user is notified by email and too many requests error is returned instead
+func failedAttempt(model *User, attempt *loginattempt.LoginAttempt, failure restErrors.IRestErr) restErrors.IRestErr {
+	if !attempt.IsLocked(time.Now()) {
+		return failure
+	}
+
+	mailRequest := new(mailer.AccountLockedMailRequestDto)
+	mailRequest.Email = model.Email
+	mailRequest.LockedUntil = time.Unix(attempt.LockedUntil, 0)
+	go mailService.AccountLocked(mailRequest)
+
+	return restErrors.NewTooManyRequestsError("account temporarily locked, too many failed attempts", loginattempt.LockoutDuration)
+}
@@ -3,13 +3,17 @@ package user
 import (
 	"bytes"
 	"errors"
+	"github.com/kotalco/core-api/core/loginattempt"
+	"github.com/kotalco/core-api/core/setting"
 	restErrors "github.com/kotalco/core-api/pkg/errors"
+	"github.com/kotalco/core-api/pkg/mailer"
 	"github.com/kotalco/core-api/pkg/token"
 	"github.com/stretchr/testify/assert"
 	"gorm.io/gorm"
 	"net/http"
 	"os"
 	"testing"
+	"time"
 )
 
 var (
@@ -21,6 +25,13 @@ var (
 	UpdateFunc             func(user *User) restErrors.IRestErr
 	FindWhereIdInSliceFunc func(ids []string) ([]*User, restErrors.IRestErr)
 	CountFunc              func() (int64, restErrors.IRestErr)
+	SearchFunc             func(email string) ([]*User, restErrors.IRestErr)
+
+	CreatePasswordHistoryFunc func(history *PasswordHistory) restErrors.IRestErr
+	GetPasswordHistoryFunc    func(userId string, limit int) ([]*PasswordHistory, restErrors.IRestErr)
+	PrunePasswordHistoryFunc  func(userId string, keep int) restErrors.IRestErr
+	DeleteFunc                func(user *User) restErrors.IRestErr
+	CountPlatformAdminsFunc   func() (int64, restErrors.IRestErr)
 
 	EncryptFunc func(data []byte, passphrase string) (string, error)
 	DecryptFunc func(encodedCipher string, passphrase string) (string, error)
@@ -33,6 +44,16 @@ var (
 
 	CreateQRCodeFunc func(accountName string) (bytes.Buffer, string, error)
 	CheckOtpFunc     func(userTOTPSecret string, otp string) bool
+
+	CheckAttemptsFunc func(userId string) restErrors.IRestErr
+	RecordFailureFunc func(userId string) (*loginattempt.LoginAttempt, restErrors.IRestErr)
+	ResetAttemptsFunc func(userId string) restErrors.IRestErr
+	IsLockedFunc      func(userId string) (bool, restErrors.IRestErr)
+
+	AccountLockedMailFunc        func(dto *mailer.AccountLockedMailRequestDto) restErrors.IRestErr
+	WorkspaceTransferredMailFunc func(dto *mailer.WorkspaceOwnershipMailRequestDto) restErrors.IRestErr
+
+	GetPasswordPolicyFunc func() (*setting.PasswordPolicyDto, restErrors.IRestErr)
 )
 
 type userRepositoryMock struct{}
@@ -40,6 +61,9 @@ type encryptionServiceMock struct{}
 type hashingServiceMock struct{}
 type tokenServiceMock struct{}
 type tfaServiceMock struct{}
+type loginAttemptServiceMock struct{}
+type mailServiceMock struct{}
+type settingServiceMock struct{}
 
 // user repository methods
 func (r userRepositoryMock) WithTransaction(txHandle *gorm.DB) IRepository {
@@ -71,6 +95,24 @@ func (userRepositoryMock) FindWhereIdInSlice(ids []string) ([]*User, restErrors.
 func (userRepositoryMock) Count() (int64, restErrors.IRestErr) {
 	return CountFunc()
 }
+func (userRepositoryMock) Search(email string) ([]*User, restErrors.IRestErr) {
+	return SearchFunc(email)
+}
+func (userRepositoryMock) CreatePasswordHistory(history *PasswordHistory) restErrors.IRestErr {
+	return CreatePasswordHistoryFunc(history)
+}
+func (userRepositoryMock) GetPasswordHistory(userId string, limit int) ([]*PasswordHistory, restErrors.IRestErr) {
+	return GetPasswordHistoryFunc(userId, limit)
+}
+func (userRepositoryMock) PrunePasswordHistory(userId string, keep int) restErrors.IRestErr {
+	return PrunePasswordHistoryFunc(userId, keep)
+}
+func (userRepositoryMock) Delete(user *User) restErrors.IRestErr {
+	return DeleteFunc(user)
+}
+func (userRepositoryMock) CountPlatformAdmins() (int64, restErrors.IRestErr) {
+	return CountPlatformAdminsFunc()
+}
 
 // encryption service methods
 func (encryptionServiceMock) Encrypt(data []byte, passphrase string) (string, error) {
@@ -108,12 +150,149 @@ func (tfaServiceMock) CheckOtp(userTOTPSecret string, otp string) bool {
 	return CheckOtpFunc(userTOTPSecret, otp)
 }
 
+// login attempt service methods
+func (s loginAttemptServiceMock) WithTransaction(txHandle *gorm.DB) loginattempt.IService {
+	return s
+}
+func (s loginAttemptServiceMock) WithoutTransaction() loginattempt.IService {
+	return s
+}
+
+// Attempt checks the account attempts, runs the verification and records its failure
+func (loginAttemptServiceMock) Attempt(userId string, verify func() bool) (*loginattempt.LoginAttempt, restErrors.IRestErr) {
+	if restErr := CheckAttemptsFunc(userId); restErr != nil {
+		return nil, restErr
+	}
+	if verify() {
+		return nil, nil
+	}
+	return RecordFailureFunc(userId)
+}
+
+func (loginAttemptServiceMock) Reset(userId string) restErrors.IRestErr {
+	return ResetAttemptsFunc(userId)
+}
+
+func (loginAttemptServiceMock) IsLocked(userId string) (bool, restErrors.IRestErr) {
+	return IsLockedFunc(userId)
+}
+
+// mail service methods, only the account locked mail is sent from the user service
+func (mailServiceMock) SignUp(dto *mailer.MailRequestDto) restErrors.IRestErr {
+	return nil
+}
+func (mailServiceMock) ResendEmailVerification(dto *mailer.MailRequestDto) restErrors.IRestErr {
+	return nil
+}
+func (mailServiceMock) ForgetPassword(dto *mailer.MailRequestDto) restErrors.IRestErr {
+	return nil
+}
+func (mailServiceMock) WorkspaceInvitation(dto *mailer.WorkspaceInvitationMailRequestDto) restErrors.IRestErr {
+	return nil
+}
+func (mailServiceMock) Ping() restErrors.IRestErr {
+	return nil
+}
+func (mailServiceMock) AccountLocked(dto *mailer.AccountLockedMailRequestDto) restErrors.IRestErr {
+	return AccountLockedMailFunc(dto)
+}
+func (mailServiceMock) WorkspaceTransferred(dto *mailer.WorkspaceOwnershipMailRequestDto) restErrors.IRestErr {
+	return WorkspaceTransferredMailFunc(dto)
+}
+
+func (mailServiceMock) Test(dto *mailer.MailRequestDto) restErrors.IRestErr {
+	return nil
+}
+
+func (mailServiceMock) Verify(settings *setting.MailSettingsDto) restErrors.IRestErr {
+	return nil
+}
+
+// setting service methods, only the password policy is read from the user service
+func (s settingServiceMock) WithTransaction(txHandle *gorm.DB) setting.IService {
+	return s
+}
+func (s settingServiceMock) WithoutTransaction() setting.IService {
+	return s
+}
+func (settingServiceMock) Settings() ([]*setting.Setting, restErrors.IRestErr) {
+	return nil, nil
+}
+func (settingServiceMock) ConfigureDomain(dto *setting.ConfigureDomainRequestDto) restErrors.IRestErr {
+	return nil
+}
+func (settingServiceMock) GetDomain() (string, restErrors.IRestErr) {
+	return "", nil
+}
+func (settingServiceMock) IsDomainConfigured() bool {
+	return false
+}
+func (settingServiceMock) ConfigureRegistration(dto *setting.ConfigureRegistrationRequestDto) restErrors.IRestErr {
+	return nil
+}
+func (settingServiceMock) IsRegistrationEnabled() bool {
+	return false
+}
+func (settingServiceMock) ConfigurePasswordPolicy(dto *setting.PasswordPolicyDto) restErrors.IRestErr {
+	return nil
+}
+func (settingServiceMock) GetPasswordPolicy() (*setting.PasswordPolicyDto, restErrors.IRestErr) {
+	return GetPasswordPolicyFunc()
+}
+
+func (settingServiceMock) ConfigureMail(dto *setting.MailSettingsDto) restErrors.IRestErr {
+	return nil
+}
+
+func (settingServiceMock) GetMailSettings() (*setting.MailSettingsDto, restErrors.IRestErr) {
+	return setting.DefaultMailSettings(), nil
+}
+
+func (settingServiceMock) ConfigureMailTemplate(name string, dto *setting.MailTemplateDto) restErrors.IRestErr {
+	return nil
+}
+
+func (settingServiceMock) GetMailTemplate(name string) (*setting.MailTemplateDto, restErrors.IRestErr) {
+	return nil, restErrors.NewNotFoundError("not found")
+}
+
+func (settingServiceMock) DeleteMailTemplate(name string) restErrors.IRestErr {
+	return nil
+}
+
+func (settingServiceMock) ConfigureCheckpointSyncProviders(dto *setting.CheckpointSyncProvidersDto) restErrors.IRestErr {
+	return nil
+}
+
+func (settingServiceMock) GetCheckpointSyncProviders() (*setting.CheckpointSyncProvidersDto, restErrors.IRestErr) {
+	return setting.DefaultCheckpointSyncProviders(), nil
+}
+
 func TestMain(m *testing.M) {
 	userRepository = &userRepositoryMock{}
 	encryption = &encryptionServiceMock{}
 	hashing = &hashingServiceMock{}
 	tokenService = &tokenServiceMock{}
 	tfaService = &tfaServiceMock{}
+	loginAttemptService = &loginAttemptServiceMock{}
+	mailService = &mailServiceMock{}
+	settingService = &settingServiceMock{}
+
+	CheckAttemptsFunc = func(userId string) restErrors.IRestErr {
+		return nil
+	}
+	RecordFailureFunc = func(userId string) (*loginattempt.LoginAttempt, restErrors.IRestErr) {
+		return &loginattempt.LoginAttempt{UserId: userId, FailedAttempts: 1}, nil
+	}
+	ResetAttemptsFunc = func(userId string) restErrors.IRestErr {
+		return nil
+	}
+	GetPasswordPolicyFunc = func() (*setting.PasswordPolicyDto, restErrors.IRestErr) {
+		return &setting.PasswordPolicyDto{}, nil
+	}
+	PrunePasswordHistoryFunc = func(userId string, keep int) restErrors.IRestErr {
+		return nil
+	}
 
 	userService = NewService()
 	code := m.Run()
@@ -169,6 +348,9 @@ func TestService_SignIn(t *testing.T) {
 	dto.Email = "test@test.com"
 	dto.Password = "123456"
 	dto.RememberMe = true
+	UpdateFunc = func(user *User) restErrors.IRestErr {
+		return nil
+	}
 
 	t.Run("SignIn_Should_Pass", func(t *testing.T) {
 		VerifyHashFunc = func(hashedPassword, password string) error {
@@ -226,6 +408,25 @@ func TestService_SignIn(t *testing.T) {
 		assert.EqualValues(t, http.StatusForbidden, err.StatusCode())
 	})
 
+	t.Run("SignIn_Should_Throw_If_User_Disabled", func(t *testing.T) {
+		GetByEmailFunc = func(email string) (*User, restErrors.IRestErr) {
+			user := new(User)
+			user.Email = dto.Email
+			user.IsEmailVerified = true
+			user.Disabled = true
+			return user, nil
+		}
+		VerifyHashFunc = func(hashedPassword, password string) error {
+			return nil
+		}
+
+		session, err := userService.SignIn(dto)
+
+		assert.Nil(t, session)
+		assert.EqualValues(t, "account disabled", err.Error())
+		assert.EqualValues(t, http.StatusForbidden, err.StatusCode())
+	})
+
 	t.Run("SingIn_Should_Throw_If_User_Does't_Exit", func(t *testing.T) {
 		GetByEmailFunc = func(email string) (*User, restErrors.IRestErr) {
 			return nil, restErrors.NewUnAuthorizedError("invalid credentials")
@@ -274,6 +475,84 @@ func TestService_SignIn(t *testing.T) {
 		assert.EqualValues(t, "can't create token", err.Error())
 	})
 
+	t.Run("SignIn_Should_Throw_If_Password_Expired", func(t *testing.T) {
+		GetByEmailFunc = func(email string) (*User, restErrors.IRestErr) {
+			user := new(User)
+			user.Email = dto.Email
+			user.IsEmailVerified = true
+			user.PasswordChangedAt = time.Now().Add(-31 * 24 * time.Hour).Unix()
+			return user, nil
+		}
+		VerifyHashFunc = func(hashedPassword, password string) error {
+			return nil
+		}
+		GetPasswordPolicyFunc = func() (*setting.PasswordPolicyDto, restErrors.IRestErr) {
+			return &setting.PasswordPolicyDto{MaxAgeDays: 30}, nil
+		}
+		defer func() {
+			GetPasswordPolicyFunc = func() (*setting.PasswordPolicyDto, restErrors.IRestErr) {
+				return &setting.PasswordPolicyDto{}, nil
+			}
+		}()
+
+		session, err := userService.SignIn(dto)
+		assert.Nil(t, session)
+		assert.EqualValues(t, "password expired, please reset your password", err.Error())
+		assert.EqualValues(t, http.StatusForbidden, err.StatusCode())
+	})
+
+	t.Run("SignIn_Should_Throw_If_Account_Is_Throttled", func(t *testing.T) {
+		GetByEmailFunc = func(email string) (*User, restErrors.IRestErr) {
+			user := new(User)
+			user.Email = dto.Email
+			user.IsEmailVerified = true
+			return user, nil
+		}
+		CheckAttemptsFunc = func(userId string) restErrors.IRestErr {
+			return restErrors.NewTooManyRequestsError("too many failed attempts, try again later", time.Second)
+		}
+		defer func() {
+			CheckAttemptsFunc = func(userId string) restErrors.IRestErr {
+				return nil
+			}
+		}()
+
+		session, err := userService.SignIn(dto)
+		assert.Nil(t, session)
+		assert.EqualValues(t, http.StatusTooManyRequests, err.StatusCode())
+		assert.EqualValues(t, 1, restErrors.RetryAfter(err))
+	})
+
+	t.Run("SignIn_Should_Lock_Account_And_Send_Mail_After_Max_Attempts", func(t *testing.T) {
+		GetByEmailFunc = func(email string) (*User, restErrors.IRestErr) {
+			user := new(User)
+			user.Email = dto.Email
+			user.IsEmailVerified = true
+			return user, nil
+		}
+		VerifyHashFunc = func(hashedPassword, password string) error {
+			return errors.New("Invalid Credentials")
+		}
+		RecordFailureFunc = func(userId string) (*loginattempt.LoginAttempt, restErrors.IRestErr) {
+			return &loginattempt.LoginAttempt{FailedAttempts: loginattempt.MaxAttempts, LockedUntil: time.Now().Add(loginattempt.LockoutDuration).Unix()}, nil
+		}
+		mailed := make(chan string, 1)
+		AccountLockedMailFunc = func(dto *mailer.AccountLockedMailRequestDto) restErrors.IRestErr {
+			mailed <- dto.Email
+			return nil
+		}
+		defer func() {
+			RecordFailureFunc = func(userId string) (*loginattempt.LoginAttempt, restErrors.IRestErr) {
+				return &loginattempt.LoginAttempt{UserId: userId, FailedAttempts: 1}, nil
+			}
+		}()
+
+		session, err := userService.SignIn(dto)
+		assert.Nil(t, session)
+		assert.EqualValues(t, http.StatusTooManyRequests, err.StatusCode())
+		assert.EqualValues(t, dto.Email, <-mailed)
+	})
+
 }
 
 func TestService_GetByEmail(t *testing.T) {
@@ -725,6 +1004,27 @@ func TestService_VerifyTOTP(t *testing.T) {
 		assert.EqualValues(t, "can't create token", err.Error())
 	})
 
+	t.Run("Verify_TOTP_Should_Throw_If_Account_Is_Throttled", func(t *testing.T) {
+		DecryptFunc = func(encodedCipher string, passphrase string) (string, error) {
+			return "", nil
+		}
+		CheckAttemptsFunc = func(userId string) restErrors.IRestErr {
+			return restErrors.NewTooManyRequestsError("account temporarily locked, too many failed attempts", time.Minute)
+		}
+		defer func() {
+			CheckAttemptsFunc = func(userId string) restErrors.IRestErr {
+				return nil
+			}
+		}()
+
+		user := new(User)
+		user.TwoFactorEnabled = true
+		session, err := userService.VerifyTOTP(user, "123")
+
+		assert.Nil(t, session)
+		assert.EqualValues(t, http.StatusTooManyRequests, err.StatusCode())
+	})
+
 }
 
 func TestService_DisableTwoFactorAuth(t *testing.T) {
@@ -844,3 +1144,371 @@ func TestService_SetAsPlatformAdmin(t *testing.T) {
 		assert.EqualValues(t, "something went wrong", restErr.Error())
 	})
 }
+
+func TestService_UnsetPlatformAdmin(t *testing.T) {
+	user := new(User)
+	user.PlatformAdmin = true
+
+	t.Run("unset platform admin should pass", func(t *testing.T) {
+		UpdateFunc = func(user *User) restErrors.IRestErr {
+			return nil
+		}
+
+		restErr := userService.UnsetPlatformAdmin(user)
+		assert.Nil(t, restErr)
+		assert.False(t, user.PlatformAdmin)
+	})
+
+	t.Run("unset platform admin should throw if repo throws", func(t *testing.T) {
+		UpdateFunc = func(user *User) restErrors.IRestErr {
+			return restErrors.NewInternalServerError("something went wrong")
+		}
+
+		restErr := userService.UnsetPlatformAdmin(user)
+		assert.EqualValues(t, "something went wrong", restErr.Error())
+	})
+}
+
+func TestService_Search(t *testing.T) {
+	t.Run("search should pass", func(t *testing.T) {
+		SearchFunc = func(email string) ([]*User, restErrors.IRestErr) {
+			return []*User{new(User)}, nil
+		}
+		list, err := userService.Search("test")
+		assert.Nil(t, err)
+		assert.Len(t, list, 1)
+	})
+
+	t.Run("search should throw if repo throws", func(t *testing.T) {
+		SearchFunc = func(email string) ([]*User, restErrors.IRestErr) {
+			return nil, restErrors.NewInternalServerError("something went wrong")
+		}
+		list, err := userService.Search("test")
+		assert.Nil(t, list)
+		assert.EqualValues(t, "something went wrong", err.Error())
+	})
+}
+
+func TestService_Disable(t *testing.T) {
+	user := new(User)
+
+	t.Run("disable should pass", func(t *testing.T) {
+		UpdateFunc = func(user *User) restErrors.IRestErr {
+			return nil
+		}
+
+		restErr := userService.Disable(user)
+		assert.Nil(t, restErr)
+		assert.True(t, user.Disabled)
+	})
+
+	t.Run("disable should throw if repo throws", func(t *testing.T) {
+		UpdateFunc = func(user *User) restErrors.IRestErr {
+			return restErrors.NewInternalServerError("something went wrong")
+		}
+
+		restErr := userService.Disable(user)
+		assert.EqualValues(t, "something went wrong", restErr.Error())
+	})
+}
+
+func TestService_Enable(t *testing.T) {
+	user := new(User)
+	user.Disabled = true
+
+	t.Run("enable should pass", func(t *testing.T) {
+		UpdateFunc = func(user *User) restErrors.IRestErr {
+			return nil
+		}
+
+		restErr := userService.Enable(user)
+		assert.Nil(t, restErr)
+		assert.False(t, user.Disabled)
+	})
+}
+
+func TestService_ResetTwoFactorAuth(t *testing.T) {
+	user := new(User)
+	user.TwoFactorEnabled = true
+	user.TwoFactorCipher = "cipher"
+
+	t.Run("reset two factor auth should pass", func(t *testing.T) {
+		UpdateFunc = func(user *User) restErrors.IRestErr {
+			return nil
+		}
+
+		restErr := userService.ResetTwoFactorAuth(user)
+		assert.Nil(t, restErr)
+		assert.False(t, user.TwoFactorEnabled)
+		assert.Empty(t, user.TwoFactorCipher)
+	})
+}
+
+func TestService_CheckPasswordPolicy(t *testing.T) {
+	defer func() {
+		GetPasswordPolicyFunc = func() (*setting.PasswordPolicyDto, restErrors.IRestErr) {
+			return &setting.PasswordPolicyDto{}, nil
+		}
+	}()
+
+	t.Run("Check_Password_Policy_Should_Pass", func(t *testing.T) {
+		GetPasswordPolicyFunc = func() (*setting.PasswordPolicyDto, restErrors.IRestErr) {
+			return &setting.PasswordPolicyDto{MinLength: 8, RequireUppercase: true, RequireLowercase: true, RequireDigit: true, RequireSymbol: true, CheckBreached: true}, nil
+		}
+		err := userService.CheckPasswordPolicy(nil, "Kotal-Node-42")
+		assert.Nil(t, err)
+	})
+
+	t.Run("Check_Password_Policy_Should_Throw_Validation_Error_With_All_Violations", func(t *testing.T) {
+		GetPasswordPolicyFunc = func() (*setting.PasswordPolicyDto, restErrors.IRestErr) {
+			return &setting.PasswordPolicyDto{MinLength: 8, RequireUppercase: true, RequireDigit: true, RequireSymbol: true}, nil
+		}
+		err := userService.CheckPasswordPolicy(nil, "kotal")
+		assert.EqualValues(t, http.StatusBadRequest, err.StatusCode())
+		assert.EqualValues(t, map[string]string{
+			"password.minLength": "password should be at least 8 chars",
+			"password.uppercase": "password should contain an uppercase letter",
+			"password.digit":     "password should contain a digit",
+			"password.symbol":    "password should contain a symbol",
+		}, err.(restErrors.RestErr).Validations)
+	})
+
+	t.Run("Check_Password_Policy_Should_Throw_If_Password_Is_Breached", func(t *testing.T) {
+		GetPasswordPolicyFunc = func() (*setting.PasswordPolicyDto, restErrors.IRestErr) {
+			return &setting.PasswordPolicyDto{CheckBreached: true}, nil
+		}
+		err := userService.CheckPasswordPolicy(nil, "password123")
+		assert.EqualValues(t, "password has appeared in a data breach, please choose a different password", err.(restErrors.RestErr).Validations["password.breached"])
+	})
+
+	t.Run("Check_Password_Policy_Should_Throw_If_Password_Was_Used_Recently", func(t *testing.T) {
+		GetPasswordPolicyFunc = func() (*setting.PasswordPolicyDto, restErrors.IRestErr) {
+			return &setting.PasswordPolicyDto{HistorySize: 3}, nil
+		}
+		GetPasswordHistoryFunc = func(userId string, limit int) ([]*PasswordHistory, restErrors.IRestErr) {
+			assert.EqualValues(t, 2, limit)
+			return []*PasswordHistory{{Password: "old-hash"}}, nil
+		}
+		VerifyHashFunc = func(hashedPassword, password string) error {
+			if hashedPassword == "old-hash" {
+				return nil
+			}
+			return errors.New("mismatch")
+		}
+		err := userService.CheckPasswordPolicy(&User{Password: "current-hash"}, "old-password")
+		assert.EqualValues(t, "password can't be one of your last 3 passwords", err.(restErrors.RestErr).Validations["password.history"])
+	})
+
+	t.Run("Check_Password_Policy_Should_Throw_If_Setting_Service_Throws", func(t *testing.T) {
+		GetPasswordPolicyFunc = func() (*setting.PasswordPolicyDto, restErrors.IRestErr) {
+			return nil, restErrors.NewInternalServerError("something went wrong")
+		}
+		err := userService.CheckPasswordPolicy(nil, "Kotal-Node-42")
+		assert.EqualValues(t, http.StatusInternalServerError, err.StatusCode())
+	})
+}
+
+func TestService_ResetPassword_PasswordHistory(t *testing.T) {
+	defer func() {
+		GetPasswordPolicyFunc = func() (*setting.PasswordPolicyDto, restErrors.IRestErr) {
+			return &setting.PasswordPolicyDto{}, nil
+		}
+	}()
+
+	t.Run("Reset_Password_Should_Keep_The_Old_Password_In_History", func(t *testing.T) {
+		GetPasswordPolicyFunc = func() (*setting.PasswordPolicyDto, restErrors.IRestErr) {
+			return &setting.PasswordPolicyDto{HistorySize: 5}, nil
+		}
+		HashFunc = func(password string, cost int) ([]byte, error) {
+			return []byte("new-hash"), nil
+		}
+		UpdateFunc = func(user *User) restErrors.IRestErr {
+			return nil
+		}
+		var stored *PasswordHistory
+		CreatePasswordHistoryFunc = func(history *PasswordHistory) restErrors.IRestErr {
+			stored = history
+			return nil
+		}
+		var kept int
+		PrunePasswordHistoryFunc = func(userId string, keep int) restErrors.IRestErr {
+			kept = keep
+			return nil
+		}
+
+		user := &User{ID: "1", Password: "old-hash"}
+		err := userService.ResetPassword(user, "123456")
+		assert.Nil(t, err)
+		assert.EqualValues(t, "old-hash", stored.Password)
+		assert.EqualValues(t, 4, kept)
+		assert.EqualValues(t, "new-hash", user.Password)
+		assert.NotZero(t, user.PasswordChangedAt)
+	})
+	t.Run("Reset_Password_Should_Keep_The_Real_Password_In_History_After_Admin_Reset", func(t *testing.T) {
+		GetPasswordPolicyFunc = func() (*setting.PasswordPolicyDto, restErrors.IRestErr) {
+			return &setting.PasswordPolicyDto{HistorySize: 5}, nil
+		}
+		HashFunc = func(password string, cost int) ([]byte, error) {
+			return []byte("new-hash"), nil
+		}
+		UpdateFunc = func(user *User) restErrors.IRestErr {
+			return nil
+		}
+		var stored *PasswordHistory
+		CreatePasswordHistoryFunc = func(history *PasswordHistory) restErrors.IRestErr {
+			stored = history
+			return nil
+		}
+		PrunePasswordHistoryFunc = func(userId string, keep int) restErrors.IRestErr {
+			return nil
+		}
+
+		user := &User{ID: "1", Password: invalidatedPasswordPrefix + "old-hash"}
+		err := userService.ResetPassword(user, "123456")
+		assert.Nil(t, err)
+		assert.EqualValues(t, "old-hash", stored.Password)
+		assert.EqualValues(t, "new-hash", user.Password)
+	})
+}
+
+func TestService_InvalidatePassword(t *testing.T) {
+	t.Run("Invalidate_Password_Should_Pass_Without_Touching_The_History", func(t *testing.T) {
+		UpdateFunc = func(user *User) restErrors.IRestErr {
+			return nil
+		}
+		recorded := false
+		CreatePasswordHistoryFunc = func(history *PasswordHistory) restErrors.IRestErr {
+			recorded = true
+			return nil
+		}
+		PrunePasswordHistoryFunc = func(userId string, keep int) restErrors.IRestErr {
+			recorded = true
+			return nil
+		}
+
+		user := &User{ID: "1", Password: "hash"}
+		err := userService.InvalidatePassword(user)
+		assert.Nil(t, err)
+		assert.EqualValues(t, invalidatedPasswordPrefix+"hash", user.Password)
+		assert.False(t, recorded)
+
+		err = userService.InvalidatePassword(user)
+		assert.Nil(t, err)
+		assert.EqualValues(t, invalidatedPasswordPrefix+"hash", user.Password)
+	})
+	t.Run("Invalidate_Password_Should_Throw_If_Repo_Throws", func(t *testing.T) {
+		UpdateFunc = func(user *User) restErrors.IRestErr {
+			return restErrors.NewInternalServerError("something went wrong")
+		}
+
+		err := userService.InvalidatePassword(&User{ID: "1", Password: "hash"})
+		assert.EqualValues(t, "something went wrong", err.Error())
+	})
+}
+
+func TestService_Reauthenticate(t *testing.T) {
+	dto := new(DeleteAccountRequestDto)
+	dto.Password = "123456"
+	dto.TOTP = "123456"
+
+	t.Run("Reauthenticate_Should_Pass", func(t *testing.T) {
+		VerifyHashFunc = func(hashedPassword, password string) error {
+			return nil
+		}
+
+		err := userService.Reauthenticate(new(User), dto)
+		assert.Nil(t, err)
+	})
+	t.Run("Reauthenticate_Should_Verify_Totp_If_Tfa_Enabled", func(t *testing.T) {
+		VerifyHashFunc = func(hashedPassword, password string) error {
+			return nil
+		}
+		DecryptFunc = func(encodedCipher string, passphrase string) (string, error) {
+			return "secret", nil
+		}
+		CheckOtpFunc = func(userTOTPSecret string, otp string) bool {
+			return false
+		}
+
+		user := new(User)
+		user.TwoFactorEnabled = true
+		err := userService.Reauthenticate(user, dto)
+		assert.EqualValues(t, "invalid totp code", err.Error())
+		assert.EqualValues(t, http.StatusBadRequest, err.StatusCode())
+	})
+	t.Run("Reauthenticate_Should_Throw_If_Password_Is_Invalid", func(t *testing.T) {
+		VerifyHashFunc = func(hashedPassword, password string) error {
+			return errors.New("invalid password")
+		}
+		recorded := false
+		RecordFailureFunc = func(userId string) (*loginattempt.LoginAttempt, restErrors.IRestErr) {
+			recorded = true
+			return &loginattempt.LoginAttempt{UserId: userId, FailedAttempts: 1}, nil
+		}
+
+		err := userService.Reauthenticate(new(User), dto)
+		assert.EqualValues(t, "Invalid password", err.Error())
+		assert.True(t, recorded)
+	})
+	t.Run("Reauthenticate_Should_Throw_If_Account_Locked", func(t *testing.T) {
+		CheckAttemptsFunc = func(userId string) restErrors.IRestErr {
+			return restErrors.NewTooManyRequestsError("account temporarily locked, too many failed attempts", time.Minute)
+		}
+
+		err := userService.Reauthenticate(new(User), dto)
+		assert.EqualValues(t, http.StatusTooManyRequests, err.StatusCode())
+
+		CheckAttemptsFunc = func(userId string) restErrors.IRestErr {
+			return nil
+		}
+	})
+}
+
+func TestService_Delete(t *testing.T) {
+	t.Run("Delete_Should_Pass", func(t *testing.T) {
+		pruned := -1
+		PrunePasswordHistoryFunc = func(userId string, keep int) restErrors.IRestErr {
+			pruned = keep
+			return nil
+		}
+		DeleteFunc = func(user *User) restErrors.IRestErr {
+			return nil
+		}
+
+		err := userService.Delete(new(User))
+		assert.Nil(t, err)
+		assert.EqualValues(t, 0, pruned)
+	})
+	t.Run("Delete_Should_Pass_If_Other_Platform_Admins_Exist", func(t *testing.T) {
+		CountPlatformAdminsFunc = func() (int64, restErrors.IRestErr) {
+			return 2, nil
+		}
+		DeleteFunc = func(user *User) restErrors.IRestErr {
+			return nil
+		}
+
+		user := new(User)
+		user.PlatformAdmin = true
+		err := userService.Delete(user)
+		assert.Nil(t, err)
+	})
+	t.Run("Delete_Should_Throw_If_Last_Platform_Admin", func(t *testing.T) {
+		CountPlatformAdminsFunc = func() (int64, restErrors.IRestErr) {
+			return 1, nil
+		}
+
+		user := new(User)
+		user.PlatformAdmin = true
+		err := userService.Delete(user)
+		assert.EqualValues(t, http.StatusBadRequest, err.StatusCode())
+		assert.EqualValues(t, "you're the only platform admin, promote another user before deleting your account", err.Error())
+	})
+	t.Run("Delete_Should_Throw_If_Repo_Throws", func(t *testing.T) {
+		DeleteFunc = func(user *User) restErrors.IRestErr {
+			return restErrors.NewInternalServerError("something went wrong")
+		}
+
+		err := userService.Delete(new(User))
+		assert.EqualValues(t, "something went wrong", err.Error())
+	})
+}
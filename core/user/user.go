@@ -8,4 +8,15 @@ type User struct {
 	TwoFactorCipher  string
 	TwoFactorEnabled bool
 	PlatformAdmin    bool `gorm:"default:false"`
+	Disabled         bool `gorm:"default:false"`
+	// PasswordChangedAt used to expire passwords with the password policy max age
+	PasswordChangedAt int64
+}
+
+// PasswordHistory keeps the user previous password hashes to prevent reusing them
+type PasswordHistory struct {
+	ID        string
+	UserId    string `gorm:"index"`
+	Password  string
+	CreatedAt int64
 }
@@ -20,6 +20,7 @@ type IRepository interface {
 	Create(verification *Verification) restErrors.IRestErr
 	GetByUserId(userId string) (*Verification, restErrors.IRestErr)
 	Update(verification *Verification) restErrors.IRestErr
+	DeleteByUserId(userId string) restErrors.IRestErr
 }
 
 func NewRepository() IRepository {
@@ -70,3 +71,13 @@ func (r repository) Update(verification *Verification) restErrors.IRestErr {
 
 	return nil
 }
+
+func (r repository) DeleteByUserId(userId string) restErrors.IRestErr {
+	resp := r.db.Where("user_id = ?", userId).Delete(new(Verification))
+	if resp.Error != nil {
+		go logger.Error(repository.DeleteByUserId, resp.Error)
+		return restErrors.NewInternalServerError("some thing went wrong!")
+	}
+
+	return nil
+}
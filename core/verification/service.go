@@ -24,6 +24,7 @@ type IService interface {
 	GetByUserId(userId string) (*Verification, restErrors.IRestErr)
 	Resend(userId string) (string, restErrors.IRestErr)
 	Verify(userId string, token string) restErrors.IRestErr
+	DeleteByUserId(userId string) restErrors.IRestErr
 }
 
 var (
@@ -150,6 +151,11 @@ func (vService service) Resend(userId string) (string, restErrors.IRestErr) {
 	return token, nil
 }
 
+// DeleteByUserId deletes the user verification records, used when the user deletes the account
+func (service) DeleteByUserId(userId string) restErrors.IRestErr {
+	return verificationRepository.DeleteByUserId(userId)
+}
+
 // generateToken creates a random token to the user which will be sent to user email
 var generateToken = func() (string, restErrors.IRestErr) {
 	tokenLength, err := strconv.Atoi(config.Environment.VerificationTokenLength)
@@ -17,6 +17,7 @@ var (
 	CreateFunc          func(verification *Verification) restErrors.IRestErr
 	GetByUserIdFunc     func(userId string) (*Verification, restErrors.IRestErr)
 	UpdateFunc          func(verification *Verification) restErrors.IRestErr
+	DeleteByUserIdFunc  func(userId string) restErrors.IRestErr
 	WithTransactionFunc func(txHandle *gorm.DB) IRepository
 
 	HashFunc       func(password string, cost int) ([]byte, error)
@@ -46,6 +47,10 @@ func (verificationRepositoryMock) Update(verification *Verification) restErrors.
 	return UpdateFunc(verification)
 }
 
+func (verificationRepositoryMock) DeleteByUserId(userId string) restErrors.IRestErr {
+	return DeleteByUserIdFunc(userId)
+}
+
 // hashing service methods
 func (hashingServiceMock) Hash(password string, cost int) ([]byte, error) {
 	return HashFunc(password, cost)
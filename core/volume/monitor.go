@@ -0,0 +1,85 @@
+package volume
+
+import (
+	"fmt"
+	"github.com/kotalco/core-api/config"
+	"github.com/kotalco/core-api/k8s/pvc"
+	"github.com/kotalco/core-api/pkg/logger"
+	"k8s.io/apimachinery/pkg/types"
+	"sync"
+	"time"
+)
+
+// usage is the nodes volumes usage last reported by the kubelets, refreshed by the monitor
+var usage = struct {
+	sync.RWMutex
+	stats map[types.NamespacedName]pvc.VolumeStats
+}{stats: map[types.NamespacedName]pvc.VolumeStats{}}
+
+// defaultMonitorInterval is used when the configured storage monitor interval isn't positive
+const defaultMonitorInterval = 60 * time.Second
+
+// MonitorInterval returns the configured storage monitor interval, or the default if it's not positive
+func MonitorInterval() time.Duration {
+	if config.Environment.StorageMonitorInterval <= 0 {
+		return defaultMonitorInterval
+	}
+	return time.Duration(config.Environment.StorageMonitorInterval) * time.Second
+}
+
+// StartMonitor refreshes the nodes volumes usage every interval in the background
+func StartMonitor(interval time.Duration) {
+	go func() {
+		for {
+			refreshUsage(config.Environment.StorageWarningThreshold)
+			time.Sleep(interval)
+		}
+	}()
+}
+
+// CapacityWarning checks if the node volume usage is above the warning threshold
+func CapacityWarning(key types.NamespacedName) bool {
+	usage.RLock()
+	defer usage.RUnlock()
+
+	stats, ok := usage.stats[key]
+	return ok && AboveThreshold(stats, config.Environment.StorageWarningThreshold)
+}
+
+// refreshUsage gets the usage of the kotal nodes volumes in all namespaces
+// a warning is logged once a volume crosses the threshold
+func refreshUsage(threshold int) {
+	claims, restErr := pvcService.ListAll()
+	if restErr != nil {
+		return
+	}
+
+	namespaces := map[string]bool{}
+	for _, claim := range claims.Items {
+		if claim.Labels["app.kubernetes.io/managed-by"] == "kotal-operator" {
+			namespaces[claim.Namespace] = true
+		}
+	}
+
+	latest := map[types.NamespacedName]pvc.VolumeStats{}
+	for namespace := range namespaces {
+		stats, restErr := pvcService.Stats(namespace)
+		if restErr != nil {
+			continue
+		}
+		for name, claimStats := range stats {
+			latest[types.NamespacedName{Namespace: namespace, Name: name}] = claimStats
+		}
+	}
+
+	usage.Lock()
+	previous := usage.stats
+	usage.stats = latest
+	usage.Unlock()
+
+	for key, stats := range latest {
+		if AboveThreshold(stats, threshold) && !AboveThreshold(previous[key], threshold) {
+			go logger.Warn("STORAGE_MONITOR", fmt.Errorf("volume %s is %.1f%% full", key.String(), stats.UsedPercentage()))
+		}
+	}
+}
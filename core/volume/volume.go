@@ -0,0 +1,98 @@
+package volume
+
+import (
+	"github.com/go-playground/validator/v10"
+	"github.com/kotalco/core-api/k8s/pvc"
+	restErrors "github.com/kotalco/core-api/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"time"
+)
+
+// ExpandVolumeDto is the new storage size of the node volume
+type ExpandVolumeDto struct {
+	Storage string `json:"storage" validate:"required"`
+}
+
+// VolumeConditionDto is a persistent volume claim condition reported while the volume is being resized
+type VolumeConditionDto struct {
+	Type               string    `json:"type"`
+	Status             string    `json:"status"`
+	Reason             string    `json:"reason,omitempty"`
+	Message            string    `json:"message,omitempty"`
+	LastTransitionTime time.Time `json:"lastTransitionTime"`
+}
+
+// VolumeStatusDto is the node volume size, resize progress and usage, usage is nil if the kubelet didn't report it
+type VolumeStatusDto struct {
+	Name         string `json:"name"`
+	StorageClass string `json:"storageClass"`
+	Phase        string `json:"phase"`
+	// Requested is the size the node asks for, Capacity is the size of the provisioned volume
+	Requested       string               `json:"requested"`
+	Capacity        string               `json:"capacity"`
+	Resizing        bool                 `json:"resizing"`
+	ResizeStatus    string               `json:"resizeStatus,omitempty"`
+	Conditions      []VolumeConditionDto `json:"conditions"`
+	UsedBytes       *int64               `json:"usedBytes"`
+	UsedPercentage  *float64             `json:"usedPercentage"`
+	CapacityWarning bool                 `json:"capacityWarning"`
+}
+
+// Validate validates the new storage size is a valid quantity
+func (dto ExpandVolumeDto) Validate() restErrors.IRestErr {
+	err := validator.New().Struct(dto)
+	if err != nil {
+		return restErrors.NewValidationError(map[string]string{"storage": "storage is required"})
+	}
+
+	if _, err := resource.ParseQuantity(dto.Storage); err != nil {
+		return restErrors.NewValidationError(map[string]string{"storage": "storage must be a valid quantity like 500Gi"})
+	}
+
+	return nil
+}
+
+// FromClaim creates the volume status from the node persistent volume claim and its usage
+func (dto VolumeStatusDto) FromClaim(claim corev1.PersistentVolumeClaim, storageClass string, stats *pvc.VolumeStats, threshold int) VolumeStatusDto {
+	requested := claim.Spec.Resources.Requests[corev1.ResourceStorage]
+	capacity := claim.Status.Capacity[corev1.ResourceStorage]
+
+	dto.Name = claim.Name
+	dto.StorageClass = storageClass
+	dto.Phase = string(claim.Status.Phase)
+	dto.Requested = requested.String()
+	dto.Capacity = capacity.String()
+	dto.ResizeStatus = string(claim.Status.AllocatedResourceStatuses[corev1.ResourceStorage])
+
+	resizing := false
+	dto.Conditions = make([]VolumeConditionDto, 0)
+	for _, condition := range claim.Status.Conditions {
+		if condition.Type == corev1.PersistentVolumeClaimResizing || condition.Type == corev1.PersistentVolumeClaimFileSystemResizePending {
+			resizing = resizing || condition.Status == corev1.ConditionTrue
+		}
+		dto.Conditions = append(dto.Conditions, VolumeConditionDto{
+			Type:               string(condition.Type),
+			Status:             string(condition.Status),
+			Reason:             condition.Reason,
+			Message:            condition.Message,
+			LastTransitionTime: condition.LastTransitionTime.Time,
+		})
+	}
+	// the volume is resizing till the provisioned capacity catches up with the requested size
+	// or while the controller or the kubelet reports a resize in progress
+	dto.Resizing = claim.Status.Phase == corev1.ClaimBound && capacity.Cmp(requested) < 0 || resizing
+
+	if stats != nil {
+		used, percentage := int64(stats.UsedBytes), stats.UsedPercentage()
+		dto.UsedBytes, dto.UsedPercentage = &used, &percentage
+		dto.CapacityWarning = AboveThreshold(*stats, threshold)
+	}
+
+	return dto
+}
+
+// AboveThreshold checks if the volume fill percentage is above the warning threshold
+func AboveThreshold(stats pvc.VolumeStats, threshold int) bool {
+	return stats.CapacityBytes != 0 && stats.UsedPercentage() >= float64(threshold)
+}
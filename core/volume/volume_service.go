@@ -0,0 +1,119 @@
+// Package volume internal is the domain layer for the nodes volumes
+// uses the k8 client to expand the node storage and report the volume usage
+package volume
+
+import (
+	"context"
+	"fmt"
+	"github.com/kotalco/core-api/config"
+	"github.com/kotalco/core-api/core/storage_class"
+	"github.com/kotalco/core-api/k8s"
+	"github.com/kotalco/core-api/k8s/pvc"
+	restErrors "github.com/kotalco/core-api/pkg/errors"
+	"github.com/kotalco/core-api/pkg/logger"
+	sharedAPI "github.com/kotalco/kotal/apis/shared"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+type volumeService struct{}
+
+type IService interface {
+	Status(key types.NamespacedName) (VolumeStatusDto, restErrors.IRestErr)
+	Expand(node client.Object, resources *sharedAPI.Resources, dto ExpandVolumeDto) (VolumeStatusDto, restErrors.IRestErr)
+}
+
+var (
+	k8sClient           = k8s.NewClientService()
+	pvcService          = pvc.NewService()
+	storageClassService = storage_class.NewStorageClassService()
+)
+
+func NewService() IService {
+	return volumeService{}
+}
+
+// Status returns the node volume size, resize progress and usage
+// kotal names the node persistent volume claim after the node
+func (service volumeService) Status(key types.NamespacedName) (status VolumeStatusDto, restErr restErrors.IRestErr) {
+	claim, restErr := pvcService.Get(key)
+	if restErr != nil {
+		return
+	}
+
+	storageClass, restErr := service.storageClass(claim)
+	if restErr != nil {
+		return
+	}
+
+	stats, restErr := pvcService.Stats(key.Namespace)
+	if restErr != nil {
+		return
+	}
+
+	var usage *pvc.VolumeStats
+	if claimStats, ok := stats[claim.Name]; ok {
+		usage = &claimStats
+	}
+
+	return VolumeStatusDto{}.FromClaim(claim, storageClass, usage, config.Environment.StorageWarningThreshold), nil
+}
+
+// Expand updates the node storage to the given size, the operator patches the node volume which the storage class resizes online
+// volumes can't shrink, and can grow only if the storage class allows volume expansion
+func (service volumeService) Expand(node client.Object, resources *sharedAPI.Resources, dto ExpandVolumeDto) (status VolumeStatusDto, restErr restErrors.IRestErr) {
+	key := types.NamespacedName{Namespace: node.GetNamespace(), Name: node.GetName()}
+	claim, restErr := pvcService.Get(key)
+	if restErr != nil {
+		return
+	}
+
+	storage := resource.MustParse(dto.Storage)
+	requested := claim.Spec.Resources.Requests[corev1.ResourceStorage]
+	if storage.Cmp(requested) <= 0 {
+		restErr = restErrors.NewBadRequestError(fmt.Sprintf("storage must be greater than the current size %s", requested.String()))
+		return
+	}
+
+	storageClassName, restErr := service.storageClass(claim)
+	if restErr != nil {
+		return
+	}
+	if storageClassName == "" {
+		restErr = restErrors.NewBadRequestError("volume doesn't have a storage class")
+		return
+	}
+
+	storageClass, restErr := storageClassService.Get(storageClassName)
+	if restErr != nil {
+		return
+	}
+	if storageClass.AllowVolumeExpansion == nil || !*storageClass.AllowVolumeExpansion {
+		restErr = restErrors.NewBadRequestError(fmt.Sprintf("storage class %s doesn't allow volume expansion", storageClassName))
+		return
+	}
+
+	resources.Storage = dto.Storage
+	if err := k8sClient.Update(context.Background(), node); err != nil {
+		go logger.Error(service.Expand, err)
+		restErr = restErrors.NewInternalServerError(fmt.Sprintf("can't expand storage of node by name %s", node.GetName()))
+		return
+	}
+
+	return service.Status(key)
+}
+
+// storageClass returns the storage class name the claim uses
+func (service volumeService) storageClass(claim corev1.PersistentVolumeClaim) (string, restErrors.IRestErr) {
+	if claim.Spec.StorageClassName != nil {
+		return *claim.Spec.StorageClassName, nil
+	}
+
+	classes, restErr := storageClassService.List()
+	if restErr != nil {
+		return "", restErr
+	}
+	return storage_class.ClaimStorageClass(claim, classes.Items), nil
+}
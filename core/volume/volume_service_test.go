@@ -0,0 +1,250 @@
+package volume
+
+import (
+	"context"
+	"errors"
+	"github.com/kotalco/core-api/core/storage_class"
+	"github.com/kotalco/core-api/k8s/pvc"
+	restErrors "github.com/kotalco/core-api/pkg/errors"
+	ethereumv1alpha1 "github.com/kotalco/kotal/apis/ethereum/v1alpha1"
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	storagev1 "k8s.io/api/storage/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"net/http"
+	"os"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"testing"
+)
+
+/*
+persistent volume claim service mocks
+*/
+var (
+	pvcGetFunc   func(key types.NamespacedName) (corev1.PersistentVolumeClaim, restErrors.IRestErr)
+	pvcStatsFunc func(namespace string) (map[string]pvc.VolumeStats, restErrors.IRestErr)
+)
+
+type pvcServiceMock struct{}
+
+func (pvcServiceMock) Get(key types.NamespacedName) (corev1.PersistentVolumeClaim, restErrors.IRestErr) {
+	return pvcGetFunc(key)
+}
+func (pvcServiceMock) Create(claim *corev1.PersistentVolumeClaim) restErrors.IRestErr {
+	return nil
+}
+func (pvcServiceMock) Update(claim *corev1.PersistentVolumeClaim) restErrors.IRestErr {
+	return nil
+}
+func (pvcServiceMock) Delete(claim *corev1.PersistentVolumeClaim) restErrors.IRestErr {
+	return nil
+}
+func (pvcServiceMock) List(namespace string) (corev1.PersistentVolumeClaimList, restErrors.IRestErr) {
+	return corev1.PersistentVolumeClaimList{}, nil
+}
+func (pvcServiceMock) ListAll() (corev1.PersistentVolumeClaimList, restErrors.IRestErr) {
+	return corev1.PersistentVolumeClaimList{}, nil
+}
+func (pvcServiceMock) Stats(namespace string) (map[string]pvc.VolumeStats, restErrors.IRestErr) {
+	return pvcStatsFunc(namespace)
+}
+
+/*
+storage class service mocks
+*/
+var storageClassGetFunc func(name string) (storagev1.StorageClass, restErrors.IRestErr)
+
+type storageClassServiceMock struct{}
+
+func (storageClassServiceMock) Get(name string) (storagev1.StorageClass, restErrors.IRestErr) {
+	return storageClassGetFunc(name)
+}
+func (storageClassServiceMock) Create(dto storage_class.StorageClassDto) (storagev1.StorageClass, restErrors.IRestErr) {
+	return storagev1.StorageClass{}, nil
+}
+func (storageClassServiceMock) Update(dto storage_class.UpdateStorageClassDto, sc *storagev1.StorageClass) restErrors.IRestErr {
+	return nil
+}
+func (storageClassServiceMock) List() (storagev1.StorageClassList, restErrors.IRestErr) {
+	return storagev1.StorageClassList{}, nil
+}
+func (storageClassServiceMock) Delete(sc *storagev1.StorageClass) restErrors.IRestErr {
+	return nil
+}
+func (storageClassServiceMock) Count() (int, restErrors.IRestErr) {
+	return 0, nil
+}
+
+/*
+k8s client mocks
+*/
+var k8sClientUpdateFunc func(obj client.Object) error
+
+type k8sClientMock struct{}
+
+func (k8sClientMock) Get(ctx context.Context, key client.ObjectKey, obj client.Object, opts ...client.GetOption) error {
+	return nil
+}
+func (k8sClientMock) List(ctx context.Context, list client.ObjectList, opts ...client.ListOption) error {
+	return nil
+}
+func (k8sClientMock) Create(ctx context.Context, obj client.Object, opts ...client.CreateOption) error {
+	return nil
+}
+func (k8sClientMock) Delete(ctx context.Context, obj client.Object, opts ...client.DeleteOption) error {
+	return nil
+}
+func (k8sClientMock) Update(ctx context.Context, obj client.Object, opts ...client.UpdateOption) error {
+	return k8sClientUpdateFunc(obj)
+}
+func (k8sClientMock) Patch(ctx context.Context, obj client.Object, patch client.Patch, opts ...client.PatchOption) error {
+	return nil
+}
+func (k8sClientMock) DeleteAllOf(ctx context.Context, obj client.Object, opts ...client.DeleteAllOfOption) error {
+	return nil
+}
+
+func TestMain(m *testing.M) {
+	pvcService = &pvcServiceMock{}
+	storageClassService = &storageClassServiceMock{}
+	k8sClient = &k8sClientMock{}
+
+	code := m.Run()
+	os.Exit(code)
+}
+
+func claim(requested string, capacity string) corev1.PersistentVolumeClaim {
+	standard := "standard"
+	return corev1.PersistentVolumeClaim{
+		ObjectMeta: metav1.ObjectMeta{Name: "my-node", Namespace: "default"},
+		Spec: corev1.PersistentVolumeClaimSpec{
+			StorageClassName: &standard,
+			Resources:        corev1.VolumeResourceRequirements{Requests: corev1.ResourceList{corev1.ResourceStorage: resource.MustParse(requested)}},
+		},
+		Status: corev1.PersistentVolumeClaimStatus{
+			Phase:    corev1.ClaimBound,
+			Capacity: corev1.ResourceList{corev1.ResourceStorage: resource.MustParse(capacity)},
+		},
+	}
+}
+
+func node() *ethereumv1alpha1.Node {
+	node := &ethereumv1alpha1.Node{ObjectMeta: metav1.ObjectMeta{Name: "my-node", Namespace: "default"}}
+	node.Spec.Storage = "100Gi"
+	return node
+}
+
+func TestExpandVolumeDto_Validate(t *testing.T) {
+	t.Run("validate should pass", func(t *testing.T) {
+		assert.Nil(t, ExpandVolumeDto{Storage: "500Gi"}.Validate())
+	})
+	t.Run("validate should throw if storage is missing", func(t *testing.T) {
+		err := ExpandVolumeDto{}.Validate()
+		assert.EqualValues(t, http.StatusBadRequest, err.StatusCode())
+	})
+	t.Run("validate should throw if storage isn't a quantity", func(t *testing.T) {
+		err := ExpandVolumeDto{Storage: "large"}.Validate()
+		assert.EqualValues(t, restErrors.NewValidationError(map[string]string{"storage": "storage must be a valid quantity like 500Gi"}), err)
+	})
+}
+
+func TestVolumeStatusDto_FromClaim(t *testing.T) {
+	t.Run("from claim should report resize progress and usage", func(t *testing.T) {
+		pending := claim("200Gi", "100Gi")
+		pending.Status.Conditions = []corev1.PersistentVolumeClaimCondition{{Type: corev1.PersistentVolumeClaimFileSystemResizePending, Status: corev1.ConditionTrue}}
+		stats := &pvc.VolumeStats{CapacityBytes: 100, UsedBytes: 90, AvailableBytes: 10}
+
+		dto := VolumeStatusDto{}.FromClaim(pending, "standard", stats, 85)
+		assert.EqualValues(t, "200Gi", dto.Requested)
+		assert.EqualValues(t, "100Gi", dto.Capacity)
+		assert.True(t, dto.Resizing)
+		assert.EqualValues(t, "FileSystemResizePending", dto.Conditions[0].Type)
+		assert.EqualValues(t, 90, *dto.UsedPercentage)
+		assert.True(t, dto.CapacityWarning)
+	})
+	t.Run("from claim should report a resized volume without usage", func(t *testing.T) {
+		dto := VolumeStatusDto{}.FromClaim(claim("200Gi", "200Gi"), "standard", nil, 85)
+		assert.False(t, dto.Resizing)
+		assert.Empty(t, dto.Conditions)
+		assert.Nil(t, dto.UsedPercentage)
+		assert.False(t, dto.CapacityWarning)
+	})
+	t.Run("from claim should report a resize pending the file system of a resized volume", func(t *testing.T) {
+		resized := claim("200Gi", "200Gi")
+		resized.Status.Conditions = []corev1.PersistentVolumeClaimCondition{{Type: corev1.PersistentVolumeClaimFileSystemResizePending, Status: corev1.ConditionTrue}}
+
+		dto := VolumeStatusDto{}.FromClaim(resized, "standard", nil, 85)
+		assert.True(t, dto.Resizing)
+	})
+	t.Run("from claim shouldn't report unrelated conditions as resizing", func(t *testing.T) {
+		resized := claim("200Gi", "200Gi")
+		resized.Status.Conditions = []corev1.PersistentVolumeClaimCondition{{Type: corev1.PersistentVolumeClaimVolumeModifyingVolume, Status: corev1.ConditionTrue}}
+
+		dto := VolumeStatusDto{}.FromClaim(resized, "standard", nil, 85)
+		assert.False(t, dto.Resizing)
+		assert.EqualValues(t, "ModifyingVolume", dto.Conditions[0].Type)
+	})
+}
+
+func TestService_Expand(t *testing.T) {
+	expansion := true
+	pvcGetFunc = func(key types.NamespacedName) (corev1.PersistentVolumeClaim, restErrors.IRestErr) {
+		return claim("100Gi", "100Gi"), nil
+	}
+	pvcStatsFunc = func(namespace string) (map[string]pvc.VolumeStats, restErrors.IRestErr) {
+		return map[string]pvc.VolumeStats{}, nil
+	}
+	storageClassGetFunc = func(name string) (storagev1.StorageClass, restErrors.IRestErr) {
+		return storagev1.StorageClass{ObjectMeta: metav1.ObjectMeta{Name: name}, AllowVolumeExpansion: &expansion}, nil
+	}
+	k8sClientUpdateFunc = func(obj client.Object) error {
+		return nil
+	}
+
+	t.Run("expand should update the node storage", func(t *testing.T) {
+		node := node()
+		status, err := NewService().Expand(node, &node.Spec.Resources, ExpandVolumeDto{Storage: "200Gi"})
+		assert.Nil(t, err)
+		assert.EqualValues(t, "200Gi", node.Spec.Storage)
+		assert.EqualValues(t, "standard", status.StorageClass)
+	})
+
+	t.Run("expand should throw if the storage isn't greater than the current size", func(t *testing.T) {
+		node := node()
+		_, err := NewService().Expand(node, &node.Spec.Resources, ExpandVolumeDto{Storage: "50Gi"})
+		assert.EqualValues(t, "storage must be greater than the current size 100Gi", err.Error())
+		assert.EqualValues(t, "100Gi", node.Spec.Storage)
+	})
+
+	t.Run("expand should throw if the storage class doesn't allow volume expansion", func(t *testing.T) {
+		storageClassGetFunc = func(name string) (storagev1.StorageClass, restErrors.IRestErr) {
+			return storagev1.StorageClass{ObjectMeta: metav1.ObjectMeta{Name: name}}, nil
+		}
+		node := node()
+		_, err := NewService().Expand(node, &node.Spec.Resources, ExpandVolumeDto{Storage: "200Gi"})
+		assert.EqualValues(t, http.StatusBadRequest, err.StatusCode())
+		assert.EqualValues(t, "storage class standard doesn't allow volume expansion", err.Error())
+	})
+
+	t.Run("expand should throw if the node can't be updated", func(t *testing.T) {
+		storageClassGetFunc = func(name string) (storagev1.StorageClass, restErrors.IRestErr) {
+			return storagev1.StorageClass{ObjectMeta: metav1.ObjectMeta{Name: name}, AllowVolumeExpansion: &expansion}, nil
+		}
+		k8sClientUpdateFunc = func(obj client.Object) error {
+			return errors.New("conflict")
+		}
+		node := node()
+		_, err := NewService().Expand(node, &node.Spec.Resources, ExpandVolumeDto{Storage: "200Gi"})
+		assert.EqualValues(t, http.StatusInternalServerError, err.StatusCode())
+	})
+}
+
+func TestAboveThreshold(t *testing.T) {
+	t.Run("above threshold should check the fill percentage", func(t *testing.T) {
+		assert.True(t, AboveThreshold(pvc.VolumeStats{CapacityBytes: 100, UsedBytes: 85}, 85))
+		assert.False(t, AboveThreshold(pvc.VolumeStats{CapacityBytes: 100, UsedBytes: 84}, 85))
+		assert.False(t, AboveThreshold(pvc.VolumeStats{}, 85))
+	})
+}
@@ -3,8 +3,6 @@ package workspace
 import (
 	"github.com/go-playground/validator/v10"
 	restErrors "github.com/kotalco/core-api/pkg/errors"
-	"github.com/kotalco/core-api/pkg/logger"
-	"github.com/kotalco/core-api/pkg/roles"
 )
 
 const DefaultWorkspaceName = "default"
@@ -19,20 +17,30 @@ type UpdateWorkspaceRequestDto struct {
 }
 
 type UpdateWorkspaceUserRequestDto struct {
-	Role string `json:"role" validate:"roles"`
+	Role string `json:"role" validate:"omitempty,lte=100"`
+}
+
+type TransferOwnershipRequestDto struct {
+	UserId string `json:"user_id" validate:"required"`
+}
+
+// UpdateSecretProviderRequestDto picks the provider keeping the data of the secrets created in the workspace
+type UpdateSecretProviderRequestDto struct {
+	SecretProvider string `json:"secret_provider" validate:"required,oneof=kubernetes vault"`
 }
 
 type WorkspaceResponseDto struct {
-	ID           string `json:"id"`
-	Name         string `json:"name"`
-	K8sNamespace string `json:"k8s_namespace"`
-	Role         string `json:"role,omitempty"`
-	UserId       string `json:"user_id"`
+	ID             string `json:"id"`
+	Name           string `json:"name"`
+	K8sNamespace   string `json:"k8s_namespace"`
+	Role           string `json:"role,omitempty"`
+	UserId         string `json:"user_id"`
+	SecretProvider string `json:"secret_provider"`
 }
 
 type AddWorkspaceMemberDto struct {
 	Email string `json:"email" validate:"required,email"`
-	Role  string `json:"role" validate:"roles"`
+	Role  string `json:"role" validate:"required,gte=1,lte=100"`
 }
 
 // Marshall creates workspace response from workspace model
@@ -41,20 +49,17 @@ func (dto *WorkspaceResponseDto) Marshall(model *Workspace) *WorkspaceResponseDt
 	dto.Name = model.Name
 	dto.K8sNamespace = model.K8sNamespace
 	dto.UserId = model.UserId
+	dto.SecretProvider = model.SecretProvider
+	if dto.SecretProvider == "" {
+		dto.SecretProvider = "kubernetes"
+	}
 	return dto
 }
 
 // Validate validates workspace requests fields
 func Validate(dto interface{}) restErrors.IRestErr {
 	newValidator := validator.New()
-	err := newValidator.RegisterValidation("roles", func(fl validator.FieldLevel) bool {
-		return roles.New().Exist(fl.Field().String())
-	})
-	if err != nil {
-		logger.Warn("USER_DTO_VALIDATE", err)
-		return restErrors.NewInternalServerError("something went wrong!")
-	}
-	err = newValidator.Struct(dto)
+	err := newValidator.Struct(dto)
 
 	if err != nil {
 		fields := map[string]string{}
@@ -69,6 +74,12 @@ func Validate(dto interface{}) restErrors.IRestErr {
 			case "Role":
 				fields["role"] = "invalid role"
 				break
+			case "UserId":
+				fields["user_id"] = "user_id is required"
+				break
+			case "SecretProvider":
+				fields["secret_provider"] = "secret provider can only be kubernetes or vault"
+				break
 			}
 		}
 		if len(fields) > 0 {
@@ -28,6 +28,7 @@ type IRepository interface {
 	CountByUserId(userId string) (int64, restErrors.IRestErr)
 	UpdateWorkspaceUser(workspaceUser *workspaceuser.WorkspaceUser) restErrors.IRestErr
 	GetByNamespace(namespace string) (*Workspace, restErrors.IRestErr)
+	Search(name string) ([]*Workspace, restErrors.IRestErr)
 }
 
 func NewRepository() IRepository {
@@ -200,3 +201,18 @@ func (repo *repository) GetByNamespace(namespace string) (*Workspace, restErrors
 
 	return workspace, nil
 }
+
+// Search returns workspaces whose name contains the given term, all workspaces if the term is empty
+func (repo *repository) Search(name string) ([]*Workspace, restErrors.IRestErr) {
+	var workspaces []*Workspace
+	query := repo.db.Preload("WorkspaceUsers").Order("name")
+	if name != "" {
+		query = query.Where("name ILIKE ?", "%"+name+"%")
+	}
+	result := query.Find(&workspaces)
+	if result.Error != nil {
+		go logger.Error(repo.Search, result.Error)
+		return nil, restErrors.NewInternalServerError("something went wrong")
+	}
+	return workspaces, nil
+}
@@ -194,6 +194,17 @@ func TestRepository_GetByNamespace(t *testing.T) {
 	})
 }
 
+func TestRepository_Search(t *testing.T) {
+	t.Run("search_should_return_workspaces_matching_the_name", func(t *testing.T) {
+		workspace := createWorkspace(t)
+		list, err := repo.Search(workspace.Name)
+		assert.Nil(t, err)
+		assert.EqualValues(t, 1, len(list))
+		assert.EqualValues(t, 1, len(list[0].WorkspaceUsers))
+		cleanUp(workspace)
+	})
+}
+
 func createWorkspace(t *testing.T) Workspace {
 	workspace := new(Workspace)
 	workspace.ID = uuid.New().String()
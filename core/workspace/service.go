@@ -27,6 +27,9 @@ type IService interface {
 	CountByUserId(userId string) (int64, restErrors.IRestErr)
 	UpdateWorkspaceUser(workspaceUser *workspaceuser.WorkspaceUser, dto *UpdateWorkspaceUserRequestDto) restErrors.IRestErr
 	GetByNamespace(namespace string) (*Workspace, restErrors.IRestErr)
+	Search(name string) ([]*Workspace, restErrors.IRestErr)
+	TransferOwnership(workspace *Workspace, newOwnerId string) restErrors.IRestErr
+	UpdateSecretProvider(workspace *Workspace, provider string) restErrors.IRestErr
 }
 
 var (
@@ -152,10 +155,63 @@ func (service) CountByUserId(userId string) (int64, restErrors.IRestErr) {
 }
 
 func (service) UpdateWorkspaceUser(workspaceUser *workspaceuser.WorkspaceUser, dto *UpdateWorkspaceUserRequestDto) restErrors.IRestErr {
-	workspaceUser.Role = dto.Role
+	if dto.Role != "" {
+		workspaceUser.Role = dto.Role
+	}
 	return workspaceRepo.UpdateWorkspaceUser(workspaceUser)
 }
 
 func (service) GetByNamespace(namespace string) (*Workspace, restErrors.IRestErr) {
 	return workspaceRepo.GetByNamespace(namespace)
 }
+
+// Search returns workspaces whose name matches the search term
+func (service) Search(name string) ([]*Workspace, restErrors.IRestErr) {
+	return workspaceRepo.Search(name)
+}
+
+// TransferOwnership makes newOwnerId the workspace owner, the new owner gets the admin role in the workspace
+func (service) TransferOwnership(workspace *Workspace, newOwnerId string) restErrors.IRestErr {
+	if workspace.UserId == newOwnerId {
+		return restErrors.NewBadRequestError("user already owns the workspace")
+	}
+
+	exist, err := workspaceRepo.GetByNameAndUserId(workspace.Name, newOwnerId)
+	if err != nil {
+		return err
+	}
+	if len(exist) > 0 {
+		return restErrors.NewConflictError("new owner has another workspace with the same name")
+	}
+
+	member, err := workspaceRepo.GetWorkspaceMemberByWorkspaceIdAndUserId(workspace.ID, newOwnerId)
+	if err != nil {
+		if err.StatusCode() != http.StatusNotFound {
+			return err
+		}
+		newWorkspaceUser := new(workspaceuser.WorkspaceUser)
+		newWorkspaceUser.ID = uuid.NewString()
+		newWorkspaceUser.WorkspaceID = workspace.ID
+		newWorkspaceUser.UserId = newOwnerId
+		newWorkspaceUser.Role = roles.Admin
+		err = workspaceRepo.AddWorkspaceMember(workspace, newWorkspaceUser)
+		if err != nil {
+			return err
+		}
+	} else if member.Role != roles.Admin {
+		member.Role = roles.Admin
+		err = workspaceRepo.UpdateWorkspaceUser(member)
+		if err != nil {
+			return err
+		}
+	}
+
+	workspace.UserId = newOwnerId
+	return workspaceRepo.Update(workspace)
+}
+
+// UpdateSecretProvider sets the workspace default secret provider, the existing secrets are kept by their providers
+func (service) UpdateSecretProvider(workspace *Workspace, provider string) restErrors.IRestErr {
+	workspace.SecretProvider = provider
+	return workspaceRepo.Update(workspace)
+}
@@ -30,6 +30,7 @@ var (
 	CountByUserIdFunc                            func(userId string) (int64, restErrors.IRestErr)
 	UpdateWorkspaceUserFunc                      func(workspaceUser *workspaceuser.WorkspaceUser) restErrors.IRestErr
 	GetByNamespaceFunc                           func(namespace string) (*Workspace, restErrors.IRestErr)
+	SearchFunc                                   func(name string) ([]*Workspace, restErrors.IRestErr)
 )
 
 type workspaceRepositoryMock struct{}
@@ -85,6 +86,10 @@ func (workspaceRepositoryMock) GetByNamespace(namespace string) (*Workspace, res
 	return GetByNamespaceFunc(namespace)
 }
 
+func (workspaceRepositoryMock) Search(name string) ([]*Workspace, restErrors.IRestErr) {
+	return SearchFunc(name)
+}
+
 func TestMain(m *testing.M) {
 	workspaceRepo = &workspaceRepositoryMock{}
 	workspaceTestService = NewService()
@@ -358,6 +363,18 @@ func TestService_UpdateWorkspaceUser(t *testing.T) {
 		assert.Nil(t, err)
 	})
 
+	t.Run("update_workspace_user_should_keep_role_if_empty", func(t *testing.T) {
+		UpdateWorkspaceUserFunc = func(workspaceUser *workspaceuser.WorkspaceUser) restErrors.IRestErr {
+			return nil
+		}
+
+		workspaceUser := new(workspaceuser.WorkspaceUser)
+		workspaceUser.Role = roles.Writer
+		err := workspaceTestService.UpdateWorkspaceUser(workspaceUser, new(UpdateWorkspaceUserRequestDto))
+		assert.Nil(t, err)
+		assert.EqualValues(t, roles.Writer, workspaceUser.Role)
+	})
+
 	t.Run("update_workspace_user_should_throw_if_repo_throws", func(t *testing.T) {
 		UpdateWorkspaceUserFunc = func(workspaceUser *workspaceuser.WorkspaceUser) restErrors.IRestErr {
 			return restErrors.NewInternalServerError("some thing went wrong")
@@ -370,3 +387,76 @@ func TestService_UpdateWorkspaceUser(t *testing.T) {
 		assert.EqualValues(t, "some thing went wrong", err.Error())
 	})
 }
+
+func TestService_TransferOwnership(t *testing.T) {
+	t.Run("transfer_ownership_should_pass_and_add_new_owner_as_admin", func(t *testing.T) {
+		GetByNameAndUserIdFunc = func(name string, userId string) ([]*Workspace, restErrors.IRestErr) {
+			return []*Workspace{}, nil
+		}
+		GetWorkspaceMemberByWorkspaceIdAndUserIdFunc = func(workspaceId string, userId string) (*workspaceuser.WorkspaceUser, restErrors.IRestErr) {
+			return nil, restErrors.NewNotFoundError("record not found")
+		}
+		var added *workspaceuser.WorkspaceUser
+		addWorkspaceMemberFunc = func(workspace *Workspace, workspaceUser *workspaceuser.WorkspaceUser) restErrors.IRestErr {
+			added = workspaceUser
+			return nil
+		}
+		UpdateWorkspaceFunc = func(workspace *Workspace) restErrors.IRestErr {
+			return nil
+		}
+
+		model := &Workspace{ID: "1", UserId: "1"}
+		err := workspaceTestService.TransferOwnership(model, "2")
+		assert.Nil(t, err)
+		assert.EqualValues(t, "2", model.UserId)
+		assert.EqualValues(t, roles.Admin, added.Role)
+	})
+
+	t.Run("transfer_ownership_should_promote_existing_member_to_admin", func(t *testing.T) {
+		GetByNameAndUserIdFunc = func(name string, userId string) ([]*Workspace, restErrors.IRestErr) {
+			return []*Workspace{}, nil
+		}
+		member := &workspaceuser.WorkspaceUser{UserId: "2", Role: roles.Reader}
+		GetWorkspaceMemberByWorkspaceIdAndUserIdFunc = func(workspaceId string, userId string) (*workspaceuser.WorkspaceUser, restErrors.IRestErr) {
+			return member, nil
+		}
+		UpdateWorkspaceUserFunc = func(workspaceUser *workspaceuser.WorkspaceUser) restErrors.IRestErr {
+			return nil
+		}
+		UpdateWorkspaceFunc = func(workspace *Workspace) restErrors.IRestErr {
+			return nil
+		}
+
+		model := &Workspace{ID: "1", UserId: "1"}
+		err := workspaceTestService.TransferOwnership(model, "2")
+		assert.Nil(t, err)
+		assert.EqualValues(t, roles.Admin, member.Role)
+	})
+
+	t.Run("transfer_ownership_should_throw_if_user_already_owns_the_workspace", func(t *testing.T) {
+		model := &Workspace{ID: "1", UserId: "1"}
+		err := workspaceTestService.TransferOwnership(model, "1")
+		assert.EqualValues(t, http.StatusBadRequest, err.StatusCode())
+	})
+
+	t.Run("transfer_ownership_should_throw_if_new_owner_has_workspace_with_the_same_name", func(t *testing.T) {
+		GetByNameAndUserIdFunc = func(name string, userId string) ([]*Workspace, restErrors.IRestErr) {
+			return []*Workspace{new(Workspace)}, nil
+		}
+
+		model := &Workspace{ID: "1", UserId: "1"}
+		err := workspaceTestService.TransferOwnership(model, "2")
+		assert.EqualValues(t, http.StatusConflict, err.StatusCode())
+	})
+}
+
+func TestService_Search(t *testing.T) {
+	t.Run("search_should_pass", func(t *testing.T) {
+		SearchFunc = func(name string) ([]*Workspace, restErrors.IRestErr) {
+			return []*Workspace{new(Workspace)}, nil
+		}
+		list, err := workspaceTestService.Search("name")
+		assert.Nil(t, err)
+		assert.Len(t, list, 1)
+	})
+}
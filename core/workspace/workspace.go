@@ -7,5 +7,6 @@ type Workspace struct {
 	Name           string
 	K8sNamespace   string                        `gorm:"<-:create;uniqueIndex"` //allow read and create only
 	UserId         string                        `gorm:"index"`
+	SecretProvider string                        // SecretProvider keeps the data of the secrets created in the workspace, empty is kubernetes
 	WorkspaceUsers []workspaceuser.WorkspaceUser `gorm:"constraint:OnDelete:CASCADE"`
 }
@@ -0,0 +1,72 @@
+package workspacerole
+
+import (
+	"github.com/go-playground/validator/v10"
+	restErrors "github.com/kotalco/core-api/pkg/errors"
+	"github.com/kotalco/core-api/pkg/logger"
+	"github.com/kotalco/core-api/pkg/roles"
+	"strings"
+)
+
+type CreateWorkspaceRoleRequestDto struct {
+	Name        string   `json:"name" validate:"required,gte=1,lte=100"`
+	Permissions []string `json:"permissions" validate:"required,gt=0,dive,permission"`
+}
+
+type UpdateWorkspaceRoleRequestDto struct {
+	Permissions []string `json:"permissions" validate:"required,gt=0,dive,permission"`
+}
+
+type WorkspaceRoleResponseDto struct {
+	ID          string   `json:"id,omitempty"`
+	Name        string   `json:"name"`
+	Permissions []string `json:"permissions"`
+	BuiltIn     bool     `json:"built_in"`
+}
+
+type PermissionsResponseDto struct {
+	Resources []string `json:"resources"`
+	Actions   []string `json:"actions"`
+}
+
+// Marshall creates workspace role response from workspace role model
+func (dto WorkspaceRoleResponseDto) Marshall(model *WorkspaceRole) WorkspaceRoleResponseDto {
+	dto.ID = model.ID
+	dto.Name = model.Name
+	dto.Permissions = model.Permissions
+	return dto
+}
+
+// Validate validates workspace role requests fields
+func Validate(dto interface{}) restErrors.IRestErr {
+	newValidator := validator.New()
+	err := newValidator.RegisterValidation("permission", func(fl validator.FieldLevel) bool {
+		return roles.ValidPermission(fl.Field().String())
+	})
+	if err != nil {
+		logger.Warn("WORKSPACE_ROLE_DTO_VALIDATE", err)
+		return restErrors.NewInternalServerError("something went wrong!")
+	}
+	err = newValidator.Struct(dto)
+
+	if err != nil {
+		fields := map[string]string{}
+		for _, err := range err.(validator.ValidationErrors) {
+			//slice elements errors are reported as Permissions[i]
+			switch strings.Split(err.Field(), "[")[0] {
+			case "Name":
+				fields["name"] = "name should be greater than 1 char and less than 100 char"
+				break
+			case "Permissions":
+				fields["permissions"] = "permissions should be a list of resource:action e.g. ethereum.nodes:update"
+				break
+			}
+		}
+
+		if len(fields) > 0 {
+			return restErrors.NewValidationError(fields)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,122 @@
+package workspacerole
+
+import (
+	"errors"
+	"fmt"
+	restErrors "github.com/kotalco/core-api/pkg/errors"
+	"github.com/kotalco/core-api/pkg/logger"
+	"github.com/kotalco/core-api/pkg/sqlclient"
+	"gorm.io/gorm"
+)
+
+type repository struct {
+	db *gorm.DB
+}
+
+type IRepository interface {
+	WithTransaction(txHandle *gorm.DB) IRepository
+	WithoutTransaction() IRepository
+	Create(role *WorkspaceRole) restErrors.IRestErr
+	Update(role *WorkspaceRole) restErrors.IRestErr
+	Delete(role *WorkspaceRole) restErrors.IRestErr
+	GetById(id string, workspaceId string) (*WorkspaceRole, restErrors.IRestErr)
+	GetByName(name string, workspaceId string) (*WorkspaceRole, restErrors.IRestErr)
+	GetByWorkspaceId(workspaceId string) ([]*WorkspaceRole, restErrors.IRestErr)
+	DeleteByWorkspaceId(workspaceId string) restErrors.IRestErr
+}
+
+func NewRepository() IRepository {
+	newRepository := repository{}
+	return newRepository
+}
+
+func (r repository) WithTransaction(txHandle *gorm.DB) IRepository {
+	r.db = txHandle
+	return r
+}
+func (r repository) WithoutTransaction() IRepository {
+	r.db = sqlclient.OpenDBConnection()
+	return r
+}
+
+func (r repository) Create(role *WorkspaceRole) restErrors.IRestErr {
+	res := r.db.Create(role)
+	if res.Error != nil {
+		go logger.Error(repository.Create, res.Error)
+		return restErrors.NewInternalServerError("something went wrong")
+	}
+
+	return nil
+}
+
+func (r repository) Update(role *WorkspaceRole) restErrors.IRestErr {
+	resp := r.db.Save(role)
+	if resp.Error != nil {
+		go logger.Error(repository.Update, resp.Error)
+		return restErrors.NewInternalServerError("something went wrong")
+	}
+
+	return nil
+}
+
+func (r repository) Delete(role *WorkspaceRole) restErrors.IRestErr {
+	resp := r.db.Delete(role)
+	if resp.Error != nil {
+		go logger.Error(repository.Delete, resp.Error)
+		return restErrors.NewInternalServerError("something went wrong")
+	}
+
+	return nil
+}
+
+func (r repository) GetById(id string, workspaceId string) (*WorkspaceRole, restErrors.IRestErr) {
+	var role = new(WorkspaceRole)
+
+	result := r.db.Where("id = ? AND workspace_id = ?", id, workspaceId).First(role)
+	if result.Error != nil {
+		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+			return nil, restErrors.NewNotFoundError(fmt.Sprintf("can't find role with id %s", id))
+		}
+		go logger.Error(repository.GetById, result.Error)
+		return nil, restErrors.NewInternalServerError("something went wrong")
+	}
+
+	return role, nil
+}
+
+func (r repository) GetByName(name string, workspaceId string) (*WorkspaceRole, restErrors.IRestErr) {
+	var role = new(WorkspaceRole)
+
+	result := r.db.Where("name = ? AND workspace_id = ?", name, workspaceId).First(role)
+	if result.Error != nil {
+		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+			return nil, restErrors.NewNotFoundError(fmt.Sprintf("can't find role %s", name))
+		}
+		go logger.Error(repository.GetByName, result.Error)
+		return nil, restErrors.NewInternalServerError("something went wrong")
+	}
+
+	return role, nil
+}
+
+func (r repository) GetByWorkspaceId(workspaceId string) ([]*WorkspaceRole, restErrors.IRestErr) {
+	var list []*WorkspaceRole
+
+	result := r.db.Where("workspace_id = ?", workspaceId).Order("name").Find(&list)
+	if result.Error != nil {
+		go logger.Error(repository.GetByWorkspaceId, result.Error)
+		return nil, restErrors.NewInternalServerError("something went wrong")
+	}
+
+	return list, nil
+}
+
+func (r repository) DeleteByWorkspaceId(workspaceId string) restErrors.IRestErr {
+	resp := r.db.Where("workspace_id = ?", workspaceId).Delete(new(WorkspaceRole))
+	if resp.Error != nil {
+		go logger.Error(repository.DeleteByWorkspaceId, resp.Error)
+		return restErrors.NewInternalServerError("something went wrong")
+	}
+
+	return nil
+}
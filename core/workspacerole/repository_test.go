@@ -0,0 +1,125 @@
+package workspacerole
+
+import (
+	"fmt"
+	"github.com/google/uuid"
+	"github.com/kotalco/core-api/pkg/sqlclient"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"testing"
+)
+
+var (
+	repo = NewRepository()
+)
+
+func init() {
+	err := sqlclient.OpenDBConnection().AutoMigrate(new(WorkspaceRole))
+	if err != nil {
+		panic(err.Error())
+	}
+}
+
+func cleanUp(role WorkspaceRole) {
+	sqlclient.OpenDBConnection().Delete(role)
+}
+
+func TestRepository_Create(t *testing.T) {
+	t.Run("Create_Should_Pass", func(t *testing.T) {
+		role := createRole(t)
+		result, restErr := repo.WithoutTransaction().GetById(role.ID, role.WorkspaceID)
+		assert.Nil(t, restErr)
+		assert.EqualValues(t, role.Permissions, result.Permissions)
+		cleanUp(role)
+	})
+}
+
+func TestRepository_GetById(t *testing.T) {
+	t.Run("Get_By_Id_Should_Pass", func(t *testing.T) {
+		role := createRole(t)
+		result, restErr := repo.WithoutTransaction().GetById(role.ID, role.WorkspaceID)
+		assert.Nil(t, restErr)
+		assert.EqualValues(t, role.Name, result.Name)
+		cleanUp(role)
+	})
+	t.Run("Get_By_Id_Should_Throw_If_Role_Belongs_To_Another_Workspace", func(t *testing.T) {
+		role := createRole(t)
+		result, restErr := repo.WithoutTransaction().GetById(role.ID, uuid.NewString())
+		assert.Nil(t, result)
+		assert.EqualValues(t, fmt.Sprintf("can't find role with id %s", role.ID), restErr.Error())
+		assert.EqualValues(t, http.StatusNotFound, restErr.StatusCode())
+		cleanUp(role)
+	})
+}
+
+func TestRepository_GetByName(t *testing.T) {
+	t.Run("Get_By_Name_Should_Pass", func(t *testing.T) {
+		role := createRole(t)
+		result, restErr := repo.WithoutTransaction().GetByName(role.Name, role.WorkspaceID)
+		assert.Nil(t, restErr)
+		assert.EqualValues(t, role.ID, result.ID)
+		cleanUp(role)
+	})
+	t.Run("Get_By_Name_Should_Throw_If_Role_Does't_Exist", func(t *testing.T) {
+		result, restErr := repo.WithoutTransaction().GetByName("operator", uuid.NewString())
+		assert.Nil(t, result)
+		assert.EqualValues(t, http.StatusNotFound, restErr.StatusCode())
+	})
+}
+
+func TestRepository_Update(t *testing.T) {
+	t.Run("Update_Should_Pass", func(t *testing.T) {
+		role := createRole(t)
+		role.Permissions = []string{"secrets:read"}
+
+		restErr := repo.WithoutTransaction().Update(&role)
+		assert.Nil(t, restErr)
+
+		result, _ := repo.WithoutTransaction().GetById(role.ID, role.WorkspaceID)
+		assert.EqualValues(t, []string{"secrets:read"}, result.Permissions)
+		cleanUp(role)
+	})
+}
+
+func TestRepository_GetByWorkspaceId(t *testing.T) {
+	t.Run("Get_By_Workspace_Id_Should_Pass", func(t *testing.T) {
+		role := createRole(t)
+		list, restErr := repo.WithoutTransaction().GetByWorkspaceId(role.WorkspaceID)
+		assert.Nil(t, restErr)
+		assert.Len(t, list, 1)
+		cleanUp(role)
+	})
+}
+
+func TestRepository_Delete(t *testing.T) {
+	t.Run("Delete_Should_Pass", func(t *testing.T) {
+		role := createRole(t)
+		restErr := repo.WithoutTransaction().Delete(&role)
+		assert.Nil(t, restErr)
+
+		_, restErr = repo.WithoutTransaction().GetById(role.ID, role.WorkspaceID)
+		assert.EqualValues(t, http.StatusNotFound, restErr.StatusCode())
+	})
+}
+
+func TestRepository_DeleteByWorkspaceId(t *testing.T) {
+	t.Run("Delete_By_Workspace_Id_Should_Pass", func(t *testing.T) {
+		role := createRole(t)
+		restErr := repo.WithoutTransaction().DeleteByWorkspaceId(role.WorkspaceID)
+		assert.Nil(t, restErr)
+
+		list, _ := repo.WithoutTransaction().GetByWorkspaceId(role.WorkspaceID)
+		assert.Len(t, list, 0)
+	})
+}
+
+func createRole(t *testing.T) WorkspaceRole {
+	role := new(WorkspaceRole)
+	role.ID = uuid.NewString()
+	role.WorkspaceID = uuid.NewString()
+	role.Name = "operator"
+	role.Permissions = []string{"ethereum.nodes:*", "secrets:read"}
+	restErr := repo.WithoutTransaction().Create(role)
+	assert.Nil(t, restErr)
+	return *role
+}
@@ -0,0 +1,109 @@
+package workspacerole
+
+import (
+	"fmt"
+	"github.com/google/uuid"
+	restErrors "github.com/kotalco/core-api/pkg/errors"
+	"github.com/kotalco/core-api/pkg/roles"
+	"gorm.io/gorm"
+	"net/http"
+)
+
+type service struct{}
+
+type IService interface {
+	WithTransaction(txHandle *gorm.DB) IService
+	WithoutTransaction() IService
+	Create(dto *CreateWorkspaceRoleRequestDto, workspaceId string) (*WorkspaceRole, restErrors.IRestErr)
+	Update(dto *UpdateWorkspaceRoleRequestDto, role *WorkspaceRole) restErrors.IRestErr
+	Delete(role *WorkspaceRole) restErrors.IRestErr
+	GetById(id string, workspaceId string) (*WorkspaceRole, restErrors.IRestErr)
+	GetByWorkspaceId(workspaceId string) ([]*WorkspaceRole, restErrors.IRestErr)
+	DeleteByWorkspaceId(workspaceId string) restErrors.IRestErr
+	Permissions(workspaceId string, role string) ([]string, restErrors.IRestErr)
+}
+
+var roleRepository = NewRepository()
+
+func NewService() IService {
+	newService := &service{}
+	return newService
+}
+
+func (s service) WithTransaction(txHandle *gorm.DB) IService {
+	roleRepository = roleRepository.WithTransaction(txHandle)
+	return s
+}
+func (s service) WithoutTransaction() IService {
+	roleRepository = roleRepository.WithoutTransaction()
+	return s
+}
+
+// Create creates a custom workspace role, names can't shadow the built-in roles
+func (service) Create(dto *CreateWorkspaceRoleRequestDto, workspaceId string) (*WorkspaceRole, restErrors.IRestErr) {
+	if roles.New().Exist(dto.Name) {
+		return nil, restErrors.NewConflictError(fmt.Sprintf("%s is a built-in role", dto.Name))
+	}
+
+	_, err := roleRepository.GetByName(dto.Name, workspaceId)
+	if err == nil {
+		return nil, restErrors.NewConflictError("role already exist")
+	}
+	if err.StatusCode() != http.StatusNotFound {
+		return nil, err
+	}
+
+	role := new(WorkspaceRole)
+	role.ID = uuid.NewString()
+	role.WorkspaceID = workspaceId
+	role.Name = dto.Name
+	role.Permissions = dto.Permissions
+
+	err = roleRepository.Create(role)
+	if err != nil {
+		return nil, err
+	}
+
+	return role, nil
+}
+
+// Update replaces the role permissions, roles can't be renamed because members reference them by name
+func (service) Update(dto *UpdateWorkspaceRoleRequestDto, role *WorkspaceRole) restErrors.IRestErr {
+	role.Permissions = dto.Permissions
+	return roleRepository.Update(role)
+}
+
+// Delete deletes a custom workspace role
+func (service) Delete(role *WorkspaceRole) restErrors.IRestErr {
+	return roleRepository.Delete(role)
+}
+
+// GetById gets a custom role by its id within the workspace
+func (service) GetById(id string, workspaceId string) (*WorkspaceRole, restErrors.IRestErr) {
+	return roleRepository.GetById(id, workspaceId)
+}
+
+// GetByWorkspaceId lists the workspace custom roles
+func (service) GetByWorkspaceId(workspaceId string) ([]*WorkspaceRole, restErrors.IRestErr) {
+	return roleRepository.GetByWorkspaceId(workspaceId)
+}
+
+// DeleteByWorkspaceId deletes all the workspace custom roles, used when the workspace gets deleted
+func (service) DeleteByWorkspaceId(workspaceId string) restErrors.IRestErr {
+	return roleRepository.DeleteByWorkspaceId(workspaceId)
+}
+
+// Permissions resolves the role permissions, from the built-in permission sets or from the workspace custom roles
+// returns not found error if the role isn't built-in and the workspace doesn't define it
+func (service) Permissions(workspaceId string, role string) ([]string, restErrors.IRestErr) {
+	if permissions, ok := roles.New().Permissions(role); ok {
+		return permissions, nil
+	}
+
+	model, err := roleRepository.GetByName(role, workspaceId)
+	if err != nil {
+		return nil, err
+	}
+
+	return model.Permissions, nil
+}
@@ -0,0 +1,151 @@
+package workspacerole
+
+import (
+	restErrors "github.com/kotalco/core-api/pkg/errors"
+	"github.com/kotalco/core-api/pkg/roles"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+	"net/http"
+	"os"
+	"testing"
+)
+
+var (
+	roleService             IService
+	CreateFunc              func(role *WorkspaceRole) restErrors.IRestErr
+	UpdateFunc              func(role *WorkspaceRole) restErrors.IRestErr
+	DeleteFunc              func(role *WorkspaceRole) restErrors.IRestErr
+	GetByIdFunc             func(id string, workspaceId string) (*WorkspaceRole, restErrors.IRestErr)
+	GetByNameFunc           func(name string, workspaceId string) (*WorkspaceRole, restErrors.IRestErr)
+	GetByWorkspaceIdFunc    func(workspaceId string) ([]*WorkspaceRole, restErrors.IRestErr)
+	DeleteByWorkspaceIdFunc func(workspaceId string) restErrors.IRestErr
+)
+
+type roleRepositoryMock struct{}
+
+func (r roleRepositoryMock) WithTransaction(txHandle *gorm.DB) IRepository {
+	return r
+}
+func (r roleRepositoryMock) WithoutTransaction() IRepository {
+	return r
+}
+
+func (roleRepositoryMock) Create(role *WorkspaceRole) restErrors.IRestErr {
+	return CreateFunc(role)
+}
+func (roleRepositoryMock) Update(role *WorkspaceRole) restErrors.IRestErr {
+	return UpdateFunc(role)
+}
+func (roleRepositoryMock) Delete(role *WorkspaceRole) restErrors.IRestErr {
+	return DeleteFunc(role)
+}
+func (roleRepositoryMock) GetById(id string, workspaceId string) (*WorkspaceRole, restErrors.IRestErr) {
+	return GetByIdFunc(id, workspaceId)
+}
+func (roleRepositoryMock) GetByName(name string, workspaceId string) (*WorkspaceRole, restErrors.IRestErr) {
+	return GetByNameFunc(name, workspaceId)
+}
+func (roleRepositoryMock) GetByWorkspaceId(workspaceId string) ([]*WorkspaceRole, restErrors.IRestErr) {
+	return GetByWorkspaceIdFunc(workspaceId)
+}
+func (roleRepositoryMock) DeleteByWorkspaceId(workspaceId string) restErrors.IRestErr {
+	return DeleteByWorkspaceIdFunc(workspaceId)
+}
+
+func TestMain(m *testing.M) {
+	roleRepository = &roleRepositoryMock{}
+	roleService = NewService()
+	code := m.Run()
+	os.Exit(code)
+}
+
+func TestService_Create(t *testing.T) {
+	dto := &CreateWorkspaceRoleRequestDto{Name: "operator", Permissions: []string{"ethereum.nodes:*"}}
+
+	t.Run("Create_Should_Pass", func(t *testing.T) {
+		GetByNameFunc = func(name string, workspaceId string) (*WorkspaceRole, restErrors.IRestErr) {
+			return nil, restErrors.NewNotFoundError("not found")
+		}
+		CreateFunc = func(role *WorkspaceRole) restErrors.IRestErr {
+			return nil
+		}
+
+		role, err := roleService.Create(dto, "1")
+		assert.Nil(t, err)
+		assert.EqualValues(t, "1", role.WorkspaceID)
+		assert.EqualValues(t, dto.Permissions, role.Permissions)
+		assert.NotEmpty(t, role.ID)
+	})
+	t.Run("Create_Should_Throw_If_Name_Is_Built_In", func(t *testing.T) {
+		role, err := roleService.Create(&CreateWorkspaceRoleRequestDto{Name: roles.Admin, Permissions: []string{"*"}}, "1")
+		assert.Nil(t, role)
+		assert.EqualValues(t, http.StatusConflict, err.StatusCode())
+	})
+	t.Run("Create_Should_Throw_If_Role_Exists", func(t *testing.T) {
+		GetByNameFunc = func(name string, workspaceId string) (*WorkspaceRole, restErrors.IRestErr) {
+			return new(WorkspaceRole), nil
+		}
+
+		role, err := roleService.Create(dto, "1")
+		assert.Nil(t, role)
+		assert.EqualValues(t, "role already exist", err.Error())
+		assert.EqualValues(t, http.StatusConflict, err.StatusCode())
+	})
+	t.Run("Create_Should_Throw_If_Repository_Throws", func(t *testing.T) {
+		GetByNameFunc = func(name string, workspaceId string) (*WorkspaceRole, restErrors.IRestErr) {
+			return nil, restErrors.NewNotFoundError("not found")
+		}
+		CreateFunc = func(role *WorkspaceRole) restErrors.IRestErr {
+			return restErrors.NewInternalServerError("something went wrong")
+		}
+
+		role, err := roleService.Create(dto, "1")
+		assert.Nil(t, role)
+		assert.EqualValues(t, http.StatusInternalServerError, err.StatusCode())
+	})
+}
+
+func TestService_Update(t *testing.T) {
+	t.Run("Update_Should_Replace_Permissions", func(t *testing.T) {
+		UpdateFunc = func(role *WorkspaceRole) restErrors.IRestErr {
+			return nil
+		}
+		role := &WorkspaceRole{Name: "operator", Permissions: []string{"secrets:read"}}
+
+		err := roleService.Update(&UpdateWorkspaceRoleRequestDto{Permissions: []string{"endpoints:*"}}, role)
+		assert.Nil(t, err)
+		assert.EqualValues(t, []string{"endpoints:*"}, role.Permissions)
+		assert.EqualValues(t, "operator", role.Name)
+	})
+}
+
+func TestService_Permissions(t *testing.T) {
+	t.Run("Permissions_Should_Resolve_Built_In_Roles", func(t *testing.T) {
+		GetByNameFunc = func(name string, workspaceId string) (*WorkspaceRole, restErrors.IRestErr) {
+			t.Fatal("built-in roles shouldn't hit the repository")
+			return nil, nil
+		}
+
+		permissions, err := roleService.Permissions("1", roles.Reader)
+		assert.Nil(t, err)
+		assert.True(t, roles.Allowed(permissions, "secrets:read"))
+	})
+	t.Run("Permissions_Should_Resolve_Custom_Roles", func(t *testing.T) {
+		GetByNameFunc = func(name string, workspaceId string) (*WorkspaceRole, restErrors.IRestErr) {
+			return &WorkspaceRole{Name: name, Permissions: []string{"endpoints:*"}}, nil
+		}
+
+		permissions, err := roleService.Permissions("1", "operator")
+		assert.Nil(t, err)
+		assert.EqualValues(t, []string{"endpoints:*"}, permissions)
+	})
+	t.Run("Permissions_Should_Throw_If_Role_Does't_Exist", func(t *testing.T) {
+		GetByNameFunc = func(name string, workspaceId string) (*WorkspaceRole, restErrors.IRestErr) {
+			return nil, restErrors.NewNotFoundError("not found")
+		}
+
+		permissions, err := roleService.Permissions("1", "operator")
+		assert.Nil(t, permissions)
+		assert.EqualValues(t, http.StatusNotFound, err.StatusCode())
+	})
+}
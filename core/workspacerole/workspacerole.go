@@ -0,0 +1,9 @@
+package workspacerole
+
+// WorkspaceRole is a custom role defined by the workspace, the built-in roles aren't stored
+type WorkspaceRole struct {
+	ID          string
+	WorkspaceID string   `gorm:"uniqueIndex:idx_workspace_role_name"`
+	Name        string   `gorm:"uniqueIndex:idx_workspace_role_name"`
+	Permissions []string `gorm:"serializer:json"`
+}
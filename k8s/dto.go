@@ -12,12 +12,15 @@ import (
 type MetaDataDto struct {
 	Name      string `json:"name" validate:"regexp,lt=64"`
 	Namespace string `json:"namespace,omitempty"`
+	// Labels are set by the api only e.g. to group the nodes created together
+	Labels map[string]string `json:"-"`
 }
 
 func (metaDto *MetaDataDto) ObjectMetaFromMetadataDto() metav1.ObjectMeta {
 	return metav1.ObjectMeta{
 		Name:      metaDto.Name,
 		Namespace: metaDto.Namespace,
+		Labels:    metaDto.Labels,
 	}
 }
 
@@ -55,3 +58,13 @@ func DefaultResources(res *sharedAPI.Resources) {
 	res.CPU = "1"
 	res.Memory = "1Gi"
 }
+
+// RequestedStorage sets the storage and storage class requested on creation, kotal defaults the storage if it's not requested
+func RequestedStorage(res *sharedAPI.Resources, requested sharedAPI.Resources) {
+	if requested.Storage != "" {
+		res.Storage = requested.Storage
+	}
+	if requested.StorageClass != nil {
+		res.StorageClass = requested.StorageClass
+	}
+}
@@ -0,0 +1,31 @@
+package pvc
+
+// VolumeStats is the persistent volume claim usage reported by the kubelet
+type VolumeStats struct {
+	CapacityBytes  uint64
+	UsedBytes      uint64
+	AvailableBytes uint64
+}
+
+// summary is the subset of the kubelet stats summary api response holding the pods volumes usage
+type summary struct {
+	Pods []struct {
+		Volume []struct {
+			CapacityBytes  *uint64 `json:"capacityBytes"`
+			UsedBytes      *uint64 `json:"usedBytes"`
+			AvailableBytes *uint64 `json:"availableBytes"`
+			PVCRef         *struct {
+				Name      string `json:"name"`
+				Namespace string `json:"namespace"`
+			} `json:"pvcRef"`
+		} `json:"volume"`
+	} `json:"pods"`
+}
+
+// UsedPercentage returns the percentage of the volume capacity in use
+func (stats VolumeStats) UsedPercentage() float64 {
+	if stats.CapacityBytes == 0 {
+		return 0
+	}
+	return float64(stats.UsedBytes) * 100 / float64(stats.CapacityBytes)
+}
@@ -0,0 +1,167 @@
+package pvc
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/kotalco/core-api/k8s"
+	restErrors "github.com/kotalco/core-api/pkg/errors"
+	"github.com/kotalco/core-api/pkg/logger"
+	corev1 "k8s.io/api/core/v1"
+	apiErrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+var k8sClient = k8s.NewClientService()
+
+type IPersistentVolumeClaim interface {
+	Get(key types.NamespacedName) (corev1.PersistentVolumeClaim, restErrors.IRestErr)
+	Create(claim *corev1.PersistentVolumeClaim) restErrors.IRestErr
+	Update(claim *corev1.PersistentVolumeClaim) restErrors.IRestErr
+	Delete(claim *corev1.PersistentVolumeClaim) restErrors.IRestErr
+	List(namespace string) (corev1.PersistentVolumeClaimList, restErrors.IRestErr)
+	ListAll() (corev1.PersistentVolumeClaimList, restErrors.IRestErr)
+	Stats(namespace string) (map[string]VolumeStats, restErrors.IRestErr)
+}
+
+type persistentVolumeClaim struct {
+}
+
+func NewService() IPersistentVolumeClaim {
+	return &persistentVolumeClaim{}
+}
+
+// Get returns a single persistent volume claim by name, kotal names the node volume after the node
+func (p *persistentVolumeClaim) Get(key types.NamespacedName) (claim corev1.PersistentVolumeClaim, restErr restErrors.IRestErr) {
+	if err := k8sClient.Get(context.Background(), key, &claim); err != nil {
+		if apiErrors.IsNotFound(err) {
+			restErr = restErrors.NewNotFoundError(fmt.Sprintf("persistent volume claim by name %s doesn't exist", key.Name))
+			return
+		}
+		go logger.Error(p.Get, err)
+		restErr = restErrors.NewInternalServerError(fmt.Sprintf("can't get persistent volume claim by name %s", key.Name))
+		return
+	}
+	return
+}
+
+// Create creates a persistent volume claim from the given spec
+func (p *persistentVolumeClaim) Create(claim *corev1.PersistentVolumeClaim) restErrors.IRestErr {
+	if err := k8sClient.Create(context.Background(), claim); err != nil {
+		if apiErrors.IsAlreadyExists(err) {
+			return restErrors.NewBadRequestError(fmt.Sprintf("persistent volume claim by name %s already exists", claim.Name))
+		}
+		go logger.Error(p.Create, err)
+		return restErrors.NewInternalServerError("failed to create persistent volume claim")
+	}
+	return nil
+}
+
+// Update updates the persistent volume claim, only its metadata and storage requests are mutable
+func (p *persistentVolumeClaim) Update(claim *corev1.PersistentVolumeClaim) restErrors.IRestErr {
+	if err := k8sClient.Update(context.Background(), claim); err != nil {
+		go logger.Error(p.Update, err)
+		return restErrors.NewInternalServerError(fmt.Sprintf("can't update persistent volume claim by name %s", claim.Name))
+	}
+	return nil
+}
+
+// Delete deletes the persistent volume claim
+func (p *persistentVolumeClaim) Delete(claim *corev1.PersistentVolumeClaim) restErrors.IRestErr {
+	if err := k8sClient.Delete(context.Background(), claim); err != nil && !apiErrors.IsNotFound(err) {
+		go logger.Error(p.Delete, err)
+		return restErrors.NewInternalServerError(fmt.Sprintf("can't delete persistent volume claim by name %s", claim.Name))
+	}
+	return nil
+}
+
+// List returns the persistent volume claims of the kotal nodes in the namespace
+func (p *persistentVolumeClaim) List(namespace string) (list corev1.PersistentVolumeClaimList, restErr restErrors.IRestErr) {
+	err := k8sClient.List(context.Background(), &list, &client.MatchingLabels{"app.kubernetes.io/managed-by": "kotal-operator"}, client.InNamespace(namespace))
+	if err != nil {
+		go logger.Error(p.List, err)
+		restErr = restErrors.NewInternalServerError("can't get persistent volume claims")
+		return
+	}
+	return
+}
+
+// ListAll returns the persistent volume claims of all namespaces, used to find the storage classes references
+func (p *persistentVolumeClaim) ListAll() (list corev1.PersistentVolumeClaimList, restErr restErrors.IRestErr) {
+	err := k8sClient.List(context.Background(), &list)
+	if err != nil {
+		go logger.Error(p.ListAll, err)
+		restErr = restErrors.NewInternalServerError("can't get persistent volume claims")
+		return
+	}
+	return
+}
+
+// Stats returns the usage of the namespace persistent volume claims by claim name
+// the kubelet of every cluster node running the namespace pods is queried, unreachable kubelets are skipped
+func (p *persistentVolumeClaim) Stats(namespace string) (map[string]VolumeStats, restErrors.IRestErr) {
+	pods := &corev1.PodList{}
+	err := k8sClient.List(context.Background(), pods, client.InNamespace(namespace))
+	if err != nil {
+		go logger.Error(p.Stats, err)
+		return nil, restErrors.NewInternalServerError("can't get volume stats")
+	}
+
+	nodes := map[string]bool{}
+	for _, pod := range pods.Items {
+		if pod.Spec.NodeName != "" {
+			nodes[pod.Spec.NodeName] = true
+		}
+	}
+
+	stats := map[string]VolumeStats{}
+	for node := range nodes {
+		result, err := nodeSummary(node)
+		if err != nil {
+			go logger.Warn(p.Stats, err)
+			continue
+		}
+		for _, pod := range result.Pods {
+			for _, volume := range pod.Volume {
+				if volume.PVCRef == nil || volume.PVCRef.Namespace != namespace {
+					continue
+				}
+				stats[volume.PVCRef.Name] = VolumeStats{
+					CapacityBytes:  value(volume.CapacityBytes),
+					UsedBytes:      value(volume.UsedBytes),
+					AvailableBytes: value(volume.AvailableBytes),
+				}
+			}
+		}
+	}
+
+	return stats, nil
+}
+
+// nodeSummary gets the kubelet stats summary of the node through the api server node proxy
+var nodeSummary = func(node string) (*summary, error) {
+	clientset := k8s.Clientset()
+	if clientset == nil {
+		return nil, errors.New("kubernetes clientset isn't configured")
+	}
+
+	raw, err := clientset.CoreV1().RESTClient().Get().AbsPath("/api/v1/nodes", node, "proxy/stats/summary").DoRaw(context.Background())
+	if err != nil {
+		return nil, err
+	}
+
+	result := new(summary)
+	if err = json.Unmarshal(raw, result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+func value(v *uint64) uint64 {
+	if v == nil {
+		return 0
+	}
+	return *v
+}
@@ -0,0 +1,123 @@
+package volumesnapshot
+
+import (
+	"context"
+	"fmt"
+	"github.com/kotalco/core-api/k8s"
+	restErrors "github.com/kotalco/core-api/pkg/errors"
+	"github.com/kotalco/core-api/pkg/logger"
+	apiErrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+var k8sClient = k8s.NewClientService()
+
+type IVolumeSnapshot interface {
+	Get(key types.NamespacedName) (VolumeSnapshot, restErrors.IRestErr)
+	Create(snapshot *VolumeSnapshot) restErrors.IRestErr
+	List(namespace string, labels map[string]string) (VolumeSnapshotList, restErrors.IRestErr)
+	Delete(snapshot *VolumeSnapshot) restErrors.IRestErr
+}
+
+type volumeSnapshot struct{}
+
+func NewService() IVolumeSnapshot {
+	return &volumeSnapshot{}
+}
+
+// Get returns a single volume snapshot by name
+func (v *volumeSnapshot) Get(key types.NamespacedName) (snapshot VolumeSnapshot, restErr restErrors.IRestErr) {
+	obj := newUnstructured("VolumeSnapshot")
+	if err := k8sClient.Get(context.Background(), key, obj); err != nil {
+		if apiErrors.IsNotFound(err) {
+			restErr = restErrors.NewNotFoundError(fmt.Sprintf("snapshot by name %s doesn't exist", key.Name))
+			return
+		}
+		restErr = v.restErr(v.Get, err, fmt.Sprintf("can't get snapshot by name %s", key.Name))
+		return
+	}
+
+	if err := runtime.DefaultUnstructuredConverter.FromUnstructured(obj.Object, &snapshot); err != nil {
+		restErr = v.restErr(v.Get, err, fmt.Sprintf("can't get snapshot by name %s", key.Name))
+	}
+	return
+}
+
+// Create creates a volume snapshot of the persistent volume claim in the snapshot spec
+func (v *volumeSnapshot) Create(snapshot *VolumeSnapshot) restErrors.IRestErr {
+	snapshot.TypeMeta.APIVersion = GroupVersion.String()
+	snapshot.TypeMeta.Kind = "VolumeSnapshot"
+
+	content, err := runtime.DefaultUnstructuredConverter.ToUnstructured(snapshot)
+	if err != nil {
+		return v.restErr(v.Create, err, "failed to create snapshot")
+	}
+
+	obj := &unstructured.Unstructured{Object: content}
+	if err = k8sClient.Create(context.Background(), obj); err != nil {
+		if apiErrors.IsAlreadyExists(err) {
+			return restErrors.NewBadRequestError(fmt.Sprintf("snapshot by name %s already exists", snapshot.Name))
+		}
+		return v.restErr(v.Create, err, "failed to create snapshot")
+	}
+
+	if err = runtime.DefaultUnstructuredConverter.FromUnstructured(obj.Object, snapshot); err != nil {
+		return v.restErr(v.Create, err, "failed to create snapshot")
+	}
+	return nil
+}
+
+// List returns the volume snapshots in the namespace matching the labels
+func (v *volumeSnapshot) List(namespace string, labels map[string]string) (list VolumeSnapshotList, restErr restErrors.IRestErr) {
+	objs := &unstructured.UnstructuredList{}
+	objs.SetGroupVersionKind(GroupVersion.WithKind("VolumeSnapshotList"))
+
+	opts := []client.ListOption{client.MatchingLabels(labels)}
+	if namespace != "" {
+		opts = append(opts, client.InNamespace(namespace))
+	}
+	if err := k8sClient.List(context.Background(), objs, opts...); err != nil {
+		restErr = v.restErr(v.List, err, "failed to get all snapshots")
+		return
+	}
+
+	list.Items = make([]VolumeSnapshot, len(objs.Items))
+	for i, obj := range objs.Items {
+		if err := runtime.DefaultUnstructuredConverter.FromUnstructured(obj.Object, &list.Items[i]); err != nil {
+			restErr = v.restErr(v.List, err, "failed to get all snapshots")
+			return
+		}
+	}
+	return
+}
+
+// Delete deletes the volume snapshot, the storage provider removes the snapshot content if its deletion policy is Delete
+func (v *volumeSnapshot) Delete(snapshot *VolumeSnapshot) restErrors.IRestErr {
+	obj := newUnstructured("VolumeSnapshot")
+	obj.SetNamespace(snapshot.Namespace)
+	obj.SetName(snapshot.Name)
+
+	if err := k8sClient.Delete(context.Background(), obj); err != nil && !apiErrors.IsNotFound(err) {
+		return v.restErr(v.Delete, err, fmt.Sprintf("can't delete snapshot by name %s", snapshot.Name))
+	}
+	return nil
+}
+
+// restErr maps the cluster missing the csi snapshot crds to a bad request, and logs the other errors
+func (v *volumeSnapshot) restErr(location interface{}, err error, message string) restErrors.IRestErr {
+	if meta.IsNoMatchError(err) {
+		return restErrors.NewBadRequestError("volume snapshots aren't supported by the cluster, install the csi snapshot controller")
+	}
+	go logger.Error(location, err)
+	return restErrors.NewInternalServerError(message)
+}
+
+func newUnstructured(kind string) *unstructured.Unstructured {
+	obj := &unstructured.Unstructured{}
+	obj.SetGroupVersionKind(GroupVersion.WithKind(kind))
+	return obj
+}
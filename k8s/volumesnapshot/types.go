@@ -0,0 +1,43 @@
+package volumesnapshot
+
+import (
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// GroupVersion is the csi external snapshotter api group version, the snapshot types aren't registered in the client scheme
+var GroupVersion = schema.GroupVersion{Group: "snapshot.storage.k8s.io", Version: "v1"}
+
+// VolumeSnapshot is the subset of the csi VolumeSnapshot used to snapshot the nodes volumes
+type VolumeSnapshot struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+	Spec              VolumeSnapshotSpec    `json:"spec"`
+	Status            *VolumeSnapshotStatus `json:"status,omitempty"`
+}
+
+type VolumeSnapshotSpec struct {
+	Source                  VolumeSnapshotSource `json:"source"`
+	VolumeSnapshotClassName *string              `json:"volumeSnapshotClassName,omitempty"`
+}
+
+type VolumeSnapshotSource struct {
+	PersistentVolumeClaimName *string `json:"persistentVolumeClaimName,omitempty"`
+}
+
+type VolumeSnapshotStatus struct {
+	CreationTime *metav1.Time         `json:"creationTime,omitempty"`
+	ReadyToUse   *bool                `json:"readyToUse,omitempty"`
+	RestoreSize  *resource.Quantity   `json:"restoreSize,omitempty"`
+	Error        *VolumeSnapshotError `json:"error,omitempty"`
+}
+
+type VolumeSnapshotError struct {
+	Time    *metav1.Time `json:"time,omitempty"`
+	Message *string      `json:"message,omitempty"`
+}
+
+type VolumeSnapshotList struct {
+	Items []VolumeSnapshot
+}
@@ -1,25 +1,45 @@
 package main
 
 import (
+	"fmt"
 	"github.com/gofiber/fiber/v2"
 	"github.com/gofiber/fiber/v2/middleware/pprof"
 	"github.com/kotalco/core-api/api"
 	"github.com/kotalco/core-api/config"
+	"github.com/kotalco/core-api/core/ethereum2/performance"
+	"github.com/kotalco/core-api/core/secret"
+	"github.com/kotalco/core-api/core/snapshot"
+	"github.com/kotalco/core-api/core/volume"
 	"github.com/kotalco/core-api/pkg/middleware"
 	"github.com/kotalco/core-api/pkg/migration"
 	"github.com/kotalco/core-api/pkg/monitor"
 	"github.com/kotalco/core-api/pkg/seeder"
 	"github.com/kotalco/core-api/pkg/sqlclient"
 	"github.com/kotalco/core-api/server"
+	"os"
+	"time"
 )
 
 func main() {
+	if len(os.Args) > 1 && os.Args[1] == migration.CommandName {
+		migrationService := migration.NewService(sqlclient.OpenDBConnection())
+		if err := migration.Command(migrationService, os.Args[2:], os.Stdout); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		return
+	}
+
 	app := fiber.New(config.FiberConfig())
 	middleware.FiberMiddleware(app)
 	monitor.NewMonitor(app)
 	app.Use(pprof.New())
 	api.MapUrl(app)
 
+	uploadApp := fiber.New(config.UploadFiberConfig())
+	middleware.FiberMiddleware(uploadApp)
+	api.MapUploadUrl(uploadApp)
+
 	dbClient := sqlclient.OpenDBConnection()
 
 	migrationService := migration.NewService(dbClient)
@@ -28,5 +48,10 @@ func main() {
 	seederService := seeder.NewService(dbClient)
 	seederService.Run()
 
-	server.StartServerWithGracefulShutdown(app)
+	volume.StartMonitor(volume.MonitorInterval())
+	snapshot.StartScheduler(time.Duration(config.Environment.SnapshotSchedulerInterval) * time.Second)
+	secret.StartSync(time.Duration(config.Environment.SecretSyncInterval) * time.Second)
+	performance.StartMonitor(time.Duration(config.Environment.ValidatorMonitorInterval) * time.Second)
+
+	server.StartServerWithGracefulShutdown(app, uploadApp)
 }
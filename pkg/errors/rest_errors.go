@@ -1,7 +1,9 @@
 package errors
 
 import (
+	"math"
 	"net/http"
+	"time"
 )
 
 type IRestErr interface {
@@ -14,6 +16,8 @@ type RestErr struct {
 	Status      int               `json:"status"`
 	Name        string            `json:"name"`
 	Validations map[string]string `json:"validations,omitempty"`
+	RetryAfter  int64             `json:"retry_after,omitempty"`
+	Details     interface{}       `json:"details,omitempty"`
 }
 
 func NewRestErr() IRestErr {
@@ -74,14 +78,32 @@ func NewForbiddenError(message string) IRestErr {
 	}
 }
 
-func NewTooManyRequestsError(message string) IRestErr {
+func NewRequestEntityTooLargeError(message string) IRestErr {
 	return RestErr{
 		Message: message,
-		Status:  http.StatusTooManyRequests,
-		Name:    "Too Many Requests",
+		Status:  http.StatusRequestEntityTooLarge,
+		Name:    "Request Entity Too Large",
 	}
 }
 
+// NewTooManyRequestsError creates too many requests error, retryAfter is rounded up to seconds and sent back to the client as Retry-After
+func NewTooManyRequestsError(message string, retryAfter time.Duration) IRestErr {
+	return RestErr{
+		Message:    message,
+		Status:     http.StatusTooManyRequests,
+		Name:       "Too Many Requests",
+		RetryAfter: int64(math.Ceil(retryAfter.Seconds())),
+	}
+}
+
+// RetryAfter returns the seconds the client should wait before retrying the request, 0 if the error has no retry hint
+func RetryAfter(err IRestErr) int64 {
+	if restErr, ok := err.(RestErr); ok {
+		return restErr.RetryAfter
+	}
+	return 0
+}
+
 func NewConflictError(message string) IRestErr {
 	return RestErr{
 		Message: message,
@@ -89,3 +111,13 @@ func NewConflictError(message string) IRestErr {
 		Name:    "Conflict",
 	}
 }
+
+// NewConflictErrorWithDetails creates conflict error with details about the conflicting resources
+func NewConflictErrorWithDetails(message string, details interface{}) IRestErr {
+	return RestErr{
+		Message: message,
+		Status:  http.StatusConflict,
+		Name:    "Conflict",
+		Details: details,
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"github.com/stretchr/testify/assert"
 	"net/http"
 	"testing"
+	"time"
 )
 
 func TestNewBadRequestError(t *testing.T) {
@@ -22,3 +23,21 @@ func TestNewInternalServerError(t *testing.T) {
 	assert.EqualValues(t, err.Error(), "internal server error")
 	assert.EqualValues(t, http.StatusInternalServerError, err.StatusCode())
 }
+
+func TestNewTooManyRequestsError(t *testing.T) {
+	err := NewTooManyRequestsError("too many requests", 1500*time.Millisecond)
+	assert.EqualValues(t, err.Error(), "too many requests")
+	assert.EqualValues(t, http.StatusTooManyRequests, err.StatusCode())
+	assert.EqualValues(t, 2, RetryAfter(err))
+}
+
+func TestRetryAfter(t *testing.T) {
+	assert.EqualValues(t, 0, RetryAfter(NewBadRequestError("Bad Request")))
+}
+
+func TestNewConflictErrorWithDetails(t *testing.T) {
+	err := NewConflictErrorWithDetails("conflict", []string{"dependent"})
+	assert.EqualValues(t, err.Error(), "conflict")
+	assert.EqualValues(t, http.StatusConflict, err.StatusCode())
+	assert.EqualValues(t, []string{"dependent"}, err.(RestErr).Details)
+}
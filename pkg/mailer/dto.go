@@ -0,0 +1,25 @@
+package mailer
+
+import "time"
+
+type MailRequestDto struct {
+	Email string
+	Token string
+}
+
+type WorkspaceInvitationMailRequestDto struct {
+	Email         string
+	WorkspaceName string
+	WorkspaceId   string
+}
+
+type AccountLockedMailRequestDto struct {
+	Email       string
+	LockedUntil time.Time
+}
+
+type WorkspaceOwnershipMailRequestDto struct {
+	Email         string
+	WorkspaceName string
+	NewOwnerEmail string
+}
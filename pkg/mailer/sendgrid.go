@@ -0,0 +1,46 @@
+package mailer
+
+import (
+	"errors"
+	"github.com/kotalco/core-api/config"
+	restErrors "github.com/kotalco/core-api/pkg/errors"
+	"github.com/sendgrid/sendgrid-go"
+	"github.com/sendgrid/sendgrid-go/helpers/mail"
+	"net/http"
+)
+
+type sendgridTransport struct{}
+
+func (sendgridTransport) Send(message *Message) error {
+	from := mail.NewEmail(message.FromName, message.FromEmail)
+	to := mail.NewEmail(greeting, message.To)
+	sgMessage := mail.NewSingleEmail(from, message.Subject, to, "", message.HTML)
+
+	restResponse, err := GetClient().Send(sgMessage)
+	if err != nil {
+		return err
+	}
+	if restResponse.StatusCode >= 400 {
+		return errors.New(restResponse.Body)
+	}
+	return nil
+}
+
+func (sendgridTransport) Ping() restErrors.IRestErr {
+	if config.Environment.SendgridAPIKey == "" {
+		restErr := restErrors.NewForbiddenError("Sendgrid is not configured")
+		return restErr
+	}
+	request := sendgrid.GetRequest(config.Environment.SendgridAPIKey, "/v3/user/profile", "")
+	request.Method = http.MethodGet
+
+	response, err := sendgrid.API(request)
+	if err != nil {
+		return restErrors.NewForbiddenError(err.Error())
+	} else {
+		if response.StatusCode == http.StatusUnauthorized {
+			return restErrors.NewForbiddenError("Sendgrid API key is not valid")
+		}
+		return nil
+	}
+}
@@ -0,0 +1,168 @@
+package mailer
+
+import (
+	"fmt"
+	"github.com/kotalco/core-api/core/setting"
+	restErrors "github.com/kotalco/core-api/pkg/errors"
+	"github.com/kotalco/core-api/pkg/logger"
+	"time"
+)
+
+type service struct{}
+
+type IService interface {
+	SignUp(dto *MailRequestDto) restErrors.IRestErr
+	ResendEmailVerification(dto *MailRequestDto) restErrors.IRestErr
+	ForgetPassword(dto *MailRequestDto) restErrors.IRestErr
+	WorkspaceInvitation(dto *WorkspaceInvitationMailRequestDto) restErrors.IRestErr
+	AccountLocked(dto *AccountLockedMailRequestDto) restErrors.IRestErr
+	WorkspaceTransferred(dto *WorkspaceOwnershipMailRequestDto) restErrors.IRestErr
+	Test(dto *MailRequestDto) restErrors.IRestErr
+	Verify(settings *setting.MailSettingsDto) restErrors.IRestErr
+	Ping() restErrors.IRestErr
+}
+
+var (
+	greeting        = "Hello there!" //default value for user name
+	settingService  = setting.NewService()
+	getMailSettings = func() (*setting.MailSettingsDto, restErrors.IRestErr) {
+		return settingService.WithoutTransaction().GetMailSettings()
+	}
+)
+
+func NewService() IService {
+	newService := &service{}
+	return newService
+}
+
+// Verify checks that the transport of the given settings can deliver emails
+func (service) Verify(settings *setting.MailSettingsDto) restErrors.IRestErr {
+	return NewTransport(settings).Ping()
+}
+
+// Ping checks that the configured transport can deliver emails
+func (s service) Ping() restErrors.IRestErr {
+	settings, restErr := getMailSettings()
+	if restErr != nil {
+		return restErr
+	}
+	return s.Verify(settings)
+}
+
+func (s service) SignUp(dto *MailRequestDto) restErrors.IRestErr {
+	domainBaseUrl, restErr := setting.GetDomainBaseUrl()
+	if restErr != nil {
+		return restErr
+	}
+	data := &TemplateData{
+		CallToActionHref: fmt.Sprintf("https://app.%s/confirm-email?email=%s&token=%s", domainBaseUrl, dto.Email, dto.Token),
+	}
+	return s.send(ConfirmEmailTemplate, dto.Email, data)
+}
+
+func (s service) ResendEmailVerification(dto *MailRequestDto) restErrors.IRestErr {
+	domainBaseUrl, restErr := setting.GetDomainBaseUrl()
+	if restErr != nil {
+		return restErr
+	}
+	data := &TemplateData{
+		CallToActionHref: fmt.Sprintf("https://app.%s/confirm-email?email=%s&token=%s", domainBaseUrl, dto.Email, dto.Token),
+	}
+	return s.send(ConfirmEmailTemplate, dto.Email, data)
+}
+
+func (s service) ForgetPassword(dto *MailRequestDto) restErrors.IRestErr {
+	domainBaseUrl, restErr := setting.GetDomainBaseUrl()
+	if restErr != nil {
+		return restErr
+	}
+	data := &TemplateData{
+		CallToActionHref: fmt.Sprintf("https://app.%s/reset-password?email=%s&token=%s", domainBaseUrl, dto.Email, dto.Token),
+	}
+	return s.send(ResetPasswordTemplate, dto.Email, data)
+}
+
+func (s service) WorkspaceInvitation(dto *WorkspaceInvitationMailRequestDto) restErrors.IRestErr {
+	domainBaseUrl, restErr := setting.GetDomainBaseUrl()
+	if restErr != nil {
+		return restErr
+	}
+	data := &TemplateData{
+		CallToActionHref: fmt.Sprintf("https://app.%s/workspaces/%s", domainBaseUrl, dto.WorkspaceId),
+		WorkspaceName:    dto.WorkspaceName,
+	}
+	return s.send(WorkspaceInvitationTemplate, dto.Email, data)
+}
+
+func (s service) AccountLocked(dto *AccountLockedMailRequestDto) restErrors.IRestErr {
+	data := &TemplateData{
+		LockedUntil: dto.LockedUntil.UTC().Format(time.RFC1123),
+	}
+	return s.send(AccountLockedTemplate, dto.Email, data)
+}
+
+func (s service) WorkspaceTransferred(dto *WorkspaceOwnershipMailRequestDto) restErrors.IRestErr {
+	data := &TemplateData{
+		WorkspaceName: dto.WorkspaceName,
+		NewOwnerEmail: dto.NewOwnerEmail,
+	}
+	return s.send(WorkspaceTransferredTemplate, dto.Email, data)
+}
+
+// Test sends the test email, returning the transport error so the admin can fix the mail settings
+func (s service) Test(dto *MailRequestDto) restErrors.IRestErr {
+	settings, restErr := getMailSettings()
+	if restErr != nil {
+		return restErr
+	}
+
+	message, restErr := newMessage(settings, TestEmailTemplate, dto.Email, new(TemplateData))
+	if restErr != nil {
+		return restErr
+	}
+
+	err := NewTransport(settings).Send(message)
+	if err != nil {
+		go logger.Warn(s.Test, err)
+		return restErrors.NewBadRequestError(fmt.Sprintf("can't send test email: %s", err.Error()))
+	}
+	return nil
+}
+
+// send renders the template with the given name and delivers it through the configured transport
+func (s service) send(name string, email string, data *TemplateData) restErrors.IRestErr {
+	settings, restErr := getMailSettings()
+	if restErr != nil {
+		return restErr
+	}
+
+	message, restErr := newMessage(settings, name, email, data)
+	if restErr != nil {
+		return restErr
+	}
+
+	err := NewTransport(settings).Send(message)
+	if err != nil {
+		go logger.Error(s.send, err)
+		return restErrors.NewInternalServerError("some thing went wrong")
+	}
+	return nil
+}
+
+func newMessage(settings *setting.MailSettingsDto, name string, email string, data *TemplateData) (*Message, restErrors.IRestErr) {
+	data.ProductName = settings.ProductName
+	data.LogoURL = settings.LogoURL
+
+	subject, html, restErr := render(name, data)
+	if restErr != nil {
+		return nil, restErr
+	}
+
+	return &Message{
+		FromName:  settings.SenderName,
+		FromEmail: settings.SenderEmail,
+		To:        email,
+		Subject:   subject,
+		HTML:      html,
+	}, nil
+}
@@ -1,4 +1,4 @@
-package sendgrid
+package mailer
 
 import (
 	"github.com/kotalco/core-api/config"
@@ -1,4 +1,4 @@
-package sendgrid
+package mailer
 
 import (
 	"github.com/stretchr/testify/assert"
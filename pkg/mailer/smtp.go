@@ -0,0 +1,139 @@
+package mailer
+
+import (
+	"bytes"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"github.com/google/uuid"
+	"github.com/kotalco/core-api/core/setting"
+	restErrors "github.com/kotalco/core-api/pkg/errors"
+	"mime"
+	"mime/quotedprintable"
+	"net"
+	"net/mail"
+	"net/smtp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const smtpDialTimeout = 10 * time.Second
+
+type smtpTransport struct {
+	settings *setting.SMTPSettingsDto
+}
+
+// dial connects to the smtp relay, upgrades the connection with STARTTLS if enabled then authenticates if credentials are set
+// net/smtp refuses plain auth over unencrypted connections to remote hosts
+func (t smtpTransport) dial() (*smtp.Client, error) {
+	if t.settings == nil {
+		return nil, errors.New("smtp is not configured")
+	}
+
+	conn, err := net.DialTimeout("tcp", net.JoinHostPort(t.settings.Host, strconv.Itoa(int(t.settings.Port))), smtpDialTimeout)
+	if err != nil {
+		return nil, err
+	}
+
+	client, err := smtp.NewClient(conn, t.settings.Host)
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+
+	if t.settings.StartTLS {
+		if ok, _ := client.Extension("STARTTLS"); !ok {
+			client.Close()
+			return nil, errors.New("smtp server doesn't support STARTTLS")
+		}
+		if err = client.StartTLS(&tls.Config{ServerName: t.settings.Host}); err != nil {
+			client.Close()
+			return nil, err
+		}
+	}
+
+	if t.settings.Username != "" {
+		if err = client.Auth(smtp.PlainAuth("", t.settings.Username, t.settings.Password, t.settings.Host)); err != nil {
+			client.Close()
+			return nil, err
+		}
+	}
+
+	return client, nil
+}
+
+func (t smtpTransport) Send(message *Message) error {
+	client, err := t.dial()
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+
+	if err = client.Mail(message.FromEmail); err != nil {
+		return err
+	}
+	if err = client.Rcpt(message.To); err != nil {
+		return err
+	}
+
+	writer, err := client.Data()
+	if err != nil {
+		return err
+	}
+	body, err := encode(message)
+	if err != nil {
+		return err
+	}
+	if _, err = writer.Write(body); err != nil {
+		return err
+	}
+	if err = writer.Close(); err != nil {
+		return err
+	}
+
+	return client.Quit()
+}
+
+func (t smtpTransport) Ping() restErrors.IRestErr {
+	client, err := t.dial()
+	if err != nil {
+		return restErrors.NewForbiddenError(fmt.Sprintf("can't connect to smtp server: %s", err.Error()))
+	}
+	defer client.Close()
+
+	if err = client.Noop(); err != nil {
+		return restErrors.NewForbiddenError(fmt.Sprintf("can't connect to smtp server: %s", err.Error()))
+	}
+	_ = client.Quit()
+	return nil
+}
+
+// encode builds the MIME message with the html body quoted-printable encoded to respect the smtp line length limit
+func encode(message *Message) ([]byte, error) {
+	from := mail.Address{Name: message.FromName, Address: message.FromEmail}
+	domain := "localhost"
+	if at := strings.LastIndex(message.FromEmail, "@"); at != -1 {
+		domain = message.FromEmail[at+1:]
+	}
+
+	var buffer bytes.Buffer
+	fmt.Fprintf(&buffer, "From: %s\r\n", from.String())
+	fmt.Fprintf(&buffer, "To: %s\r\n", message.To)
+	fmt.Fprintf(&buffer, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", message.Subject))
+	fmt.Fprintf(&buffer, "Date: %s\r\n", time.Now().UTC().Format(time.RFC1123Z))
+	fmt.Fprintf(&buffer, "Message-ID: <%s@%s>\r\n", uuid.NewString(), domain)
+	buffer.WriteString("MIME-Version: 1.0\r\n")
+	buffer.WriteString("Content-Type: text/html; charset=\"UTF-8\"\r\n")
+	buffer.WriteString("Content-Transfer-Encoding: quoted-printable\r\n\r\n")
+
+	writer := quotedprintable.NewWriter(&buffer)
+	if _, err := writer.Write([]byte(message.HTML)); err != nil {
+		return nil, err
+	}
+	if err := writer.Close(); err != nil {
+		return nil, err
+	}
+
+	return buffer.Bytes(), nil
+}
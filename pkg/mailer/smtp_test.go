@@ -0,0 +1,115 @@
+package mailer
+
+import (
+	"bufio"
+	"github.com/kotalco/core-api/core/setting"
+	"github.com/stretchr/testify/assert"
+	"net"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// fakeSMTPServer accepts a single connection and records the DATA it receives
+func fakeSMTPServer(t *testing.T) (*setting.SMTPSettingsDto, chan string) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	received := make(chan string, 1)
+
+	go func() {
+		defer listener.Close()
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+
+		reader := bufio.NewReader(conn)
+		write := func(line string) { conn.Write([]byte(line + "\r\n")) }
+		write("220 localhost ESMTP")
+		for {
+			line, err := reader.ReadString('\n')
+			if err != nil {
+				return
+			}
+			command := strings.ToUpper(strings.TrimSpace(line))
+			switch {
+			case strings.HasPrefix(command, "EHLO"):
+				write("250-localhost")
+				write("250 8BITMIME")
+			case strings.HasPrefix(command, "DATA"):
+				write("354 go ahead")
+				var data strings.Builder
+				for {
+					dataLine, err := reader.ReadString('\n')
+					if err != nil || dataLine == ".\r\n" {
+						break
+					}
+					data.WriteString(dataLine)
+				}
+				received <- data.String()
+				write("250 queued")
+			case strings.HasPrefix(command, "QUIT"):
+				write("221 bye")
+				return
+			default:
+				write("250 OK")
+			}
+		}
+	}()
+
+	host, port, _ := net.SplitHostPort(listener.Addr().String())
+	portNumber, _ := strconv.Atoi(port)
+	return &setting.SMTPSettingsDto{Host: host, Port: uint(portNumber)}, received
+}
+
+func TestSMTPTransport_Send(t *testing.T) {
+	t.Run("send should deliver the message", func(t *testing.T) {
+		settings, received := fakeSMTPServer(t)
+		transport := smtpTransport{settings: settings}
+
+		err := transport.Send(&Message{
+			FromName:  "Kotal Notifications",
+			FromEmail: "notifications@kotal.co",
+			To:        "user@kotal.co",
+			Subject:   "Confirm Your Email",
+			HTML:      "<p>hello</p>",
+		})
+		assert.Nil(t, err)
+
+		data := <-received
+		assert.Contains(t, data, "Subject: Confirm Your Email\r\n")
+		assert.Contains(t, data, "<p>hello</p>")
+	})
+
+	t.Run("send should throw if STARTTLS is required but not supported", func(t *testing.T) {
+		settings, _ := fakeSMTPServer(t)
+		settings.StartTLS = true
+		transport := smtpTransport{settings: settings}
+
+		err := transport.Send(&Message{FromEmail: "notifications@kotal.co", To: "user@kotal.co"})
+		assert.EqualValues(t, "smtp server doesn't support STARTTLS", err.Error())
+	})
+
+	t.Run("send should throw if smtp isn't configured", func(t *testing.T) {
+		transport := smtpTransport{}
+		err := transport.Send(&Message{})
+		assert.EqualValues(t, "smtp is not configured", err.Error())
+	})
+}
+
+func TestSMTPTransport_Ping(t *testing.T) {
+	t.Run("ping should pass", func(t *testing.T) {
+		settings, _ := fakeSMTPServer(t)
+		transport := smtpTransport{settings: settings}
+		assert.Nil(t, transport.Ping())
+	})
+
+	t.Run("ping should throw if server is unreachable", func(t *testing.T) {
+		transport := smtpTransport{settings: &setting.SMTPSettingsDto{Host: "127.0.0.1", Port: 1}}
+		err := transport.Ping()
+		assert.True(t, strings.HasPrefix(err.Error(), "can't connect to smtp server"))
+	})
+}
@@ -0,0 +1,130 @@
+package mailer
+
+import (
+	"bytes"
+	"embed"
+	"fmt"
+	"github.com/kotalco/core-api/core/setting"
+	restErrors "github.com/kotalco/core-api/pkg/errors"
+	htmlTemplate "html/template"
+	"net/http"
+	textTemplate "text/template"
+)
+
+const (
+	ConfirmEmailTemplate         = "confirm_email"
+	ResetPasswordTemplate        = "reset_password"
+	WorkspaceInvitationTemplate  = "workspace_invitation"
+	AccountLockedTemplate        = "account_locked"
+	WorkspaceTransferredTemplate = "workspace_transferred"
+	TestEmailTemplate            = "test_email"
+)
+
+var (
+	//go:embed templates/*.html
+	templatesFS embed.FS
+	// defaultSubjects are the built-in templates subjects, also used as the list of the known templates
+	defaultSubjects = map[string]string{
+		ConfirmEmailTemplate:         "Confirm Your Email",
+		ResetPasswordTemplate:        "Reset Your Password",
+		WorkspaceInvitationTemplate:  "You're invited to join workspace",
+		AccountLockedTemplate:        "Your account has been temporarily locked",
+		WorkspaceTransferredTemplate: "Workspace ownership transferred",
+		TestEmailTemplate:            "{{.ProductName}} test email",
+	}
+	getMailTemplate = func(name string) (*setting.MailTemplateDto, restErrors.IRestErr) {
+		return settingService.WithoutTransaction().GetMailTemplate(name)
+	}
+)
+
+// TemplateData is the data available to the mail templates
+// Branding fields are always set, the rest are set only by the templates using them
+type TemplateData struct {
+	ProductName      string
+	LogoURL          string
+	CallToActionHref string
+	WorkspaceName    string
+	LockedUntil      string
+	NewOwnerEmail    string
+}
+
+// TemplateNames returns the names of the mail templates that can be overridden
+func TemplateNames() []string {
+	return []string{
+		ConfirmEmailTemplate,
+		ResetPasswordTemplate,
+		WorkspaceInvitationTemplate,
+		AccountLockedTemplate,
+		WorkspaceTransferredTemplate,
+		TestEmailTemplate,
+	}
+}
+
+// DefaultTemplate returns the built-in subject and html body of the template with the given name
+func DefaultTemplate(name string) (*setting.MailTemplateDto, restErrors.IRestErr) {
+	subject, ok := defaultSubjects[name]
+	if !ok {
+		return nil, restErrors.NewNotFoundError(fmt.Sprintf("can't find mail template %s", name))
+	}
+	html, err := templatesFS.ReadFile(fmt.Sprintf("templates/%s.html", name))
+	if err != nil {
+		return nil, restErrors.NewNotFoundError(fmt.Sprintf("can't find mail template %s", name))
+	}
+	return &setting.MailTemplateDto{Subject: subject, HTML: string(html)}, nil
+}
+
+// ValidateTemplate parses and executes the template against sample data so broken overrides are rejected before they're saved
+func ValidateTemplate(dto *setting.MailTemplateDto) restErrors.IRestErr {
+	sample := &TemplateData{
+		ProductName:      "Kotal",
+		LogoURL:          "https://kotal.co/logo.png",
+		CallToActionHref: "https://app.kotal.co",
+		WorkspaceName:    "workspace",
+		LockedUntil:      "Mon, 02 Jan 2006 15:04:05 UTC",
+		NewOwnerEmail:    "owner@kotal.co",
+	}
+	if _, _, err := execute(dto, sample); err != nil {
+		return restErrors.NewBadRequestError(fmt.Sprintf("invalid template: %s", err.Error()))
+	}
+	return nil
+}
+
+// render renders the overridden template with the given name or the built-in one if it wasn't overridden
+func render(name string, data *TemplateData) (string, string, restErrors.IRestErr) {
+	dto, restErr := getMailTemplate(name)
+	if restErr != nil {
+		if restErr.StatusCode() != http.StatusNotFound {
+			return "", "", restErr
+		}
+		dto, restErr = DefaultTemplate(name)
+		if restErr != nil {
+			return "", "", restErr
+		}
+	}
+
+	subject, html, err := execute(dto, data)
+	if err != nil {
+		return "", "", restErrors.NewInternalServerError(fmt.Sprintf("can't render mail template %s", name))
+	}
+	return subject, html, nil
+}
+
+func execute(dto *setting.MailTemplateDto, data *TemplateData) (string, string, error) {
+	subjectTemplate, err := textTemplate.New("subject").Parse(dto.Subject)
+	if err != nil {
+		return "", "", err
+	}
+	htmlBodyTemplate, err := htmlTemplate.New("html").Parse(dto.HTML)
+	if err != nil {
+		return "", "", err
+	}
+
+	var subject, html bytes.Buffer
+	if err = subjectTemplate.Execute(&subject, data); err != nil {
+		return "", "", err
+	}
+	if err = htmlBodyTemplate.Execute(&html, data); err != nil {
+		return "", "", err
+	}
+	return subject.String(), html.String(), nil
+}
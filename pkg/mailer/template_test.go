@@ -0,0 +1,95 @@
+package mailer
+
+import (
+	"github.com/kotalco/core-api/core/setting"
+	restErrors "github.com/kotalco/core-api/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"strings"
+	"testing"
+)
+
+func TestDefaultTemplate(t *testing.T) {
+	t.Run("default template should exist for all templates", func(t *testing.T) {
+		for _, name := range TemplateNames() {
+			template, err := DefaultTemplate(name)
+			assert.Nil(t, err)
+			assert.NotEmpty(t, template.Subject)
+			assert.Nil(t, ValidateTemplate(template))
+		}
+	})
+
+	t.Run("default template should throw if template doesn't exist", func(t *testing.T) {
+		template, err := DefaultTemplate("unknown")
+		assert.Nil(t, template)
+		assert.EqualValues(t, "can't find mail template unknown", err.Error())
+	})
+}
+
+func TestValidateTemplate(t *testing.T) {
+	t.Run("validate template should throw if template can't be parsed", func(t *testing.T) {
+		err := ValidateTemplate(&setting.MailTemplateDto{Subject: "subject", HTML: "{{.ProductName"})
+		assert.NotNil(t, err)
+		assert.True(t, strings.HasPrefix(err.Error(), "invalid template"))
+	})
+
+	t.Run("validate template should throw if template uses unknown fields", func(t *testing.T) {
+		err := ValidateTemplate(&setting.MailTemplateDto{Subject: "{{.Unknown}}", HTML: "html"})
+		assert.NotNil(t, err)
+	})
+}
+
+func TestRender(t *testing.T) {
+	data := &TemplateData{ProductName: "Acme", CallToActionHref: "https://app.acme.io/confirm-email"}
+
+	t.Run("render should use the built-in template if not overridden", func(t *testing.T) {
+		getMailTemplate = func(name string) (*setting.MailTemplateDto, restErrors.IRestErr) {
+			return nil, restErrors.NewNotFoundError("not found")
+		}
+		subject, html, err := render(ConfirmEmailTemplate, data)
+		assert.Nil(t, err)
+		assert.EqualValues(t, "Confirm Your Email", subject)
+		assert.Contains(t, html, "https://app.acme.io/confirm-email")
+		assert.Contains(t, html, "Acme Team")
+		assert.NotContains(t, html, "<img")
+	})
+
+	t.Run("render should use the overridden template and escape the data", func(t *testing.T) {
+		getMailTemplate = func(name string) (*setting.MailTemplateDto, restErrors.IRestErr) {
+			return &setting.MailTemplateDto{Subject: "Welcome to {{.ProductName}}", HTML: "<p>{{.WorkspaceName}}</p>"}, nil
+		}
+		subject, html, err := render(WorkspaceInvitationTemplate, &TemplateData{ProductName: "Acme", WorkspaceName: "<script>"})
+		assert.Nil(t, err)
+		assert.EqualValues(t, "Welcome to Acme", subject)
+		assert.EqualValues(t, "<p>&lt;script&gt;</p>", html)
+	})
+
+	t.Run("render should throw if the template can't be fetched", func(t *testing.T) {
+		getMailTemplate = func(name string) (*setting.MailTemplateDto, restErrors.IRestErr) {
+			return nil, restErrors.NewInternalServerError("something went wrong")
+		}
+		_, _, err := render(ConfirmEmailTemplate, data)
+		assert.EqualValues(t, "something went wrong", err.Error())
+	})
+}
+
+func TestEncode(t *testing.T) {
+	t.Run("encode should build the mime message", func(t *testing.T) {
+		body, err := encode(&Message{
+			FromName:  "Kotal Notifications",
+			FromEmail: "notifications@kotal.co",
+			To:        "user@kotal.co",
+			Subject:   "Confirm Your Email",
+			HTML:      "<p>" + strings.Repeat("a", 200) + "</p>",
+		})
+		assert.Nil(t, err)
+
+		message := string(body)
+		assert.Contains(t, message, "From: \"Kotal Notifications\" <notifications@kotal.co>\r\n")
+		assert.Contains(t, message, "To: user@kotal.co\r\n")
+		assert.Contains(t, message, "Content-Transfer-Encoding: quoted-printable\r\n")
+		assert.Contains(t, message, "@kotal.co>\r\n")
+		for _, line := range strings.Split(message, "\r\n") {
+			assert.LessOrEqual(t, len(line), 998)
+		}
+	})
+}
@@ -0,0 +1,31 @@
+package mailer
+
+import (
+	"github.com/kotalco/core-api/core/setting"
+	restErrors "github.com/kotalco/core-api/pkg/errors"
+)
+
+// Message is a rendered email ready to be delivered by a transport
+type Message struct {
+	FromName  string
+	FromEmail string
+	To        string
+	Subject   string
+	HTML      string
+}
+
+// ITransport delivers messages through a mail provider
+type ITransport interface {
+	Send(message *Message) error
+	Ping() restErrors.IRestErr
+}
+
+// NewTransport creates the transport selected in the mail settings
+var NewTransport = func(settings *setting.MailSettingsDto) ITransport {
+	switch settings.Transport {
+	case setting.SMTPMailTransport:
+		return &smtpTransport{settings: settings.SMTP}
+	default:
+		return &sendgridTransport{}
+	}
+}
@@ -0,0 +1,17 @@
+package middleware
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/kotalco/core-api/core/workspaceuser"
+	restErrors "github.com/kotalco/core-api/pkg/errors"
+	"github.com/kotalco/core-api/pkg/roles"
+)
+
+// IsWorkspaceAdmin checks the workspace member has the built-in admin role
+func IsWorkspaceAdmin(c *fiber.Ctx) error {
+	if c.Locals("workspaceUser").(workspaceuser.WorkspaceUser).Role != roles.Admin {
+		forbidden := restErrors.NewForbiddenError("unAuthorized action")
+		return c.Status(forbidden.StatusCode()).JSON(forbidden)
+	}
+	return c.Next()
+}
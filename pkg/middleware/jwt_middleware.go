@@ -27,6 +27,10 @@ func JWTProtected(c *fiber.Ctx) error {
 		}
 		return c.Status(err.StatusCode()).JSON(err)
 	}
+	if user.Disabled {
+		unAuthErr := restErrors.NewUnAuthorizedError("account disabled")
+		return c.Status(unAuthErr.StatusCode()).JSON(unAuthErr)
+	}
 	userDetails := new(token.UserDetails)
 	userDetails.ID = user.ID
 	userDetails.PlatformAdmin = user.PlatformAdmin
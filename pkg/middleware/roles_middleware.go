@@ -2,40 +2,46 @@ package middleware
 
 import (
 	"github.com/gofiber/fiber/v2"
+	"github.com/kotalco/core-api/core/workspacerole"
 	"github.com/kotalco/core-api/core/workspaceuser"
 	restErrors "github.com/kotalco/core-api/pkg/errors"
 	"github.com/kotalco/core-api/pkg/roles"
+	"net/http"
 )
 
-func IsAdmin(c *fiber.Ctx) error {
-	workspaceUser := c.Locals("workspaceUser").(workspaceuser.WorkspaceUser)
+var workspaceRoleService = workspacerole.NewService()
 
-	if workspaceUser.Role != roles.Admin {
-		forbidden := restErrors.NewForbiddenError("unAuthorized action")
-		return c.Status(forbidden.StatusCode()).JSON(forbidden)
-	}
-	c.Next()
-	return nil
-}
+// HasPermission checks the workspace member role grants the required permission e.g. ethereum.nodes:update
+// the role permissions are resolved from the built-in permission sets or the workspace custom roles, creates permissions local
+func HasPermission(permission string) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		workspaceUser := c.Locals("workspaceUser").(workspaceuser.WorkspaceUser)
 
-func IsWriter(c *fiber.Ctx) error {
-	workspaceUser := c.Locals("workspaceUser").(workspaceuser.WorkspaceUser)
+		permissions, err := workspaceRoleService.WithoutTransaction().Permissions(workspaceUser.WorkspaceID, workspaceUser.Role)
+		if err != nil && err.StatusCode() != http.StatusNotFound {
+			return c.Status(err.StatusCode()).JSON(err)
+		}
+
+		if !roles.Allowed(permissions, permission) {
+			forbidden := restErrors.NewForbiddenError("unAuthorized action")
+			return c.Status(forbidden.StatusCode()).JSON(forbidden)
+		}
 
-	if workspaceUser.Role != roles.Admin && workspaceUser.Role != roles.Writer {
-		forbidden := restErrors.NewForbiddenError("unAuthorized action")
-		return c.Status(forbidden.StatusCode()).JSON(forbidden)
+		c.Locals("permissions", permissions)
+		return c.Next()
 	}
-	c.Next()
-	return nil
 }
 
-func IsReader(c *fiber.Ctx) error {
+// LoadPermissions resolves the workspace member role permissions without requiring any, creates permissions local
+// used by the handlers checking the permissions per resource e.g. the bulk actions across node kinds
+func LoadPermissions(c *fiber.Ctx) error {
 	workspaceUser := c.Locals("workspaceUser").(workspaceuser.WorkspaceUser)
 
-	if workspaceUser.Role != roles.Admin && workspaceUser.Role != roles.Writer && workspaceUser.Role != roles.Reader {
-		forbidden := restErrors.NewForbiddenError("unAuthorized action")
-		return c.Status(forbidden.StatusCode()).JSON(forbidden)
+	permissions, err := workspaceRoleService.WithoutTransaction().Permissions(workspaceUser.WorkspaceID, workspaceUser.Role)
+	if err != nil && err.StatusCode() != http.StatusNotFound {
+		return c.Status(err.StatusCode()).JSON(err)
 	}
-	c.Next()
-	return nil
+
+	c.Locals("permissions", permissions)
+	return c.Next()
 }
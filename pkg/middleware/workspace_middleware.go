@@ -44,6 +44,7 @@ func WorkspaceProtected(c *fiber.Ctx) error {
 
 	c.Locals("workspace", *model)
 	c.Locals("namespace", model.K8sNamespace)
+	c.Locals("secretProvider", model.SecretProvider)
 	return c.Next()
 
 }
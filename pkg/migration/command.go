@@ -0,0 +1,55 @@
+package migration
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"text/tabwriter"
+	"time"
+)
+
+const CommandName = "migrate"
+
+var commandUsage = errors.New("usage: migrate status|up|down|to <version>")
+
+// Command runs the migrate sub-command of the binary with the arguments following it
+func Command(migrationService IService, args []string, out io.Writer) error {
+	if len(args) == 0 {
+		return commandUsage
+	}
+
+	switch args[0] {
+	case "status":
+		list, err := migrationService.Status()
+		if err != nil {
+			return err
+		}
+		writer := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(writer, "VERSION\tNAME\tSTATUS\tAPPLIED AT")
+		for _, v := range list {
+			status, appliedAt := "pending", "-"
+			if v.Applied {
+				status = "applied"
+				appliedAt = v.AppliedAt.Format(time.RFC3339)
+			}
+			fmt.Fprintf(writer, "%d\t%s\t%s\t%s\n", v.Version, v.Name, status, appliedAt)
+		}
+		return writer.Flush()
+	case "up":
+		return migrationService.Up()
+	case "down":
+		return migrationService.Down()
+	case "to":
+		if len(args) != 2 {
+			return commandUsage
+		}
+		version, err := strconv.ParseUint(args[1], 10, 32)
+		if err != nil {
+			return fmt.Errorf("invalid migration version %s", args[1])
+		}
+		return migrationService.To(uint(version))
+	default:
+		return commandUsage
+	}
+}
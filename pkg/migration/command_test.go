@@ -0,0 +1,71 @@
+package migration
+
+import (
+	"bytes"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+var (
+	migrationStatusFunc func() ([]Status, error)
+	migrationUpFunc     func() error
+	migrationDownFunc   func() error
+	migrationToFunc     func(version uint) error
+)
+
+type migrationServiceMock struct{}
+
+func (migrationServiceMock) Migrations() []Definition {
+	return nil
+}
+func (migrationServiceMock) Run() {}
+func (migrationServiceMock) Status() ([]Status, error) {
+	return migrationStatusFunc()
+}
+func (migrationServiceMock) Up() error {
+	return migrationUpFunc()
+}
+func (migrationServiceMock) Down() error {
+	return migrationDownFunc()
+}
+func (migrationServiceMock) To(version uint) error {
+	return migrationToFunc(version)
+}
+
+func TestCommand(t *testing.T) {
+	t.Run("status should print the migrations", func(t *testing.T) {
+		appliedAt := time.Date(2023, 1, 2, 3, 4, 5, 0, time.UTC)
+		migrationStatusFunc = func() ([]Status, error) {
+			return []Status{
+				{Version: 1, Name: MigrateUserTable, Applied: true, AppliedAt: &appliedAt},
+				{Version: 2, Name: MigrateVerificationTable},
+			}, nil
+		}
+		out := new(bytes.Buffer)
+		err := Command(&migrationServiceMock{}, []string{"status"}, out)
+		assert.Nil(t, err)
+		assert.Contains(t, out.String(), "MigrateUserTable          applied  2023-01-02T03:04:05Z")
+		assert.Contains(t, out.String(), "MigrateVerificationTable  pending  -")
+	})
+	t.Run("to should pass the version", func(t *testing.T) {
+		var target uint
+		migrationToFunc = func(version uint) error {
+			target = version
+			return nil
+		}
+		err := Command(&migrationServiceMock{}, []string{"to", "3"}, new(bytes.Buffer))
+		assert.Nil(t, err)
+		assert.EqualValues(t, 3, target)
+	})
+	t.Run("to should throw if version is invalid", func(t *testing.T) {
+		err := Command(&migrationServiceMock{}, []string{"to", "latest"}, new(bytes.Buffer))
+		assert.EqualValues(t, "invalid migration version latest", err.Error())
+	})
+	t.Run("command should throw usage if sub-command is unknown", func(t *testing.T) {
+		err := Command(&migrationServiceMock{}, []string{"redo"}, new(bytes.Buffer))
+		assert.EqualValues(t, commandUsage, err)
+		err = Command(&migrationServiceMock{}, []string{}, new(bytes.Buffer))
+		assert.EqualValues(t, commandUsage, err)
+	})
+}
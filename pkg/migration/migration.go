@@ -1,21 +1,20 @@
 package migration
 
 import (
+	"github.com/kotalco/core-api/core/audit"
 	"github.com/kotalco/core-api/core/endpointactivity"
+	"github.com/kotalco/core-api/core/ethereum2/performance"
+	"github.com/kotalco/core-api/core/loginattempt"
 	"github.com/kotalco/core-api/core/setting"
 	"github.com/kotalco/core-api/core/user"
 	"github.com/kotalco/core-api/core/verification"
 	"github.com/kotalco/core-api/core/workspace"
+	"github.com/kotalco/core-api/core/workspacerole"
 	"github.com/kotalco/core-api/core/workspaceuser"
 	"github.com/kotalco/core-api/pkg/logger"
 	"gorm.io/gorm"
 )
 
-type Definition struct {
-	Name string
-	Run  func() error
-}
-
 type migration struct {
 	dbClient *gorm.DB
 }
@@ -27,6 +26,14 @@ type IMigration interface {
 	CreateWorkspaceUserTable() error
 	CreateSettingTable() error
 	CreateEndpointActivityTable() error
+	CreateLoginAttemptTable() error
+	CreatePasswordHistoryTable() error
+	CreateWorkspaceRoleTable() error
+	AddWorkspaceSecretProviderColumn() error
+	CreateValidatorBalanceTable() error
+	CreateAuditRecordTable() error
+	DropTable(model interface{}) error
+	DropColumn(model interface{}, field string) error
 }
 
 func NewMigration(dbClient *gorm.DB) IMigration {
@@ -38,7 +45,7 @@ func NewMigration(dbClient *gorm.DB) IMigration {
 func (m migration) CreateUserTable() error {
 	err := m.dbClient.Migrator().AutoMigrate(user.User{})
 	if err != nil {
-		go logger.Error(m.CreateWorkspaceTable, err)
+		go logger.Error(m.CreateUserTable, err)
 		return err
 	}
 	go logger.Info(m.CreateUserTable, "CreateUserTable")
@@ -58,7 +65,7 @@ func (m migration) CreateVerificationTable() error {
 func (m migration) CreateWorkspaceTable() error {
 	err := m.dbClient.Migrator().AutoMigrate(workspace.Workspace{})
 	if err != nil {
-		go logger.Error(m.CreateWorkspaceUserTable, err)
+		go logger.Error(m.CreateWorkspaceTable, err)
 		return err
 	}
 	go logger.Info(m.CreateWorkspaceTable, "CreateWorkspaceTable")
@@ -76,19 +83,105 @@ func (m migration) CreateWorkspaceUserTable() error {
 }
 
 func (m migration) CreateSettingTable() error {
-	exits := m.dbClient.Migrator().HasTable(setting.Setting{})
-	if !exits {
-		go logger.Info(m.CreateSettingTable, "CreateSettingTable")
-		return m.dbClient.AutoMigrate(setting.Setting{})
+	err := m.dbClient.Migrator().AutoMigrate(setting.Setting{})
+	if err != nil {
+		go logger.Error(m.CreateSettingTable, err)
+		return err
 	}
+	go logger.Info(m.CreateSettingTable, "CreateSettingTable")
 	return nil
 }
 
 func (m migration) CreateEndpointActivityTable() error {
-	exits := m.dbClient.Migrator().HasTable(endpointactivity.Activity{})
-	if !exits {
-		go logger.Info(m.CreateEndpointActivityTable, "CreateEndpointActivityTable")
-		return m.dbClient.AutoMigrate(endpointactivity.Activity{})
+	err := m.dbClient.Migrator().AutoMigrate(endpointactivity.Activity{})
+	if err != nil {
+		go logger.Error(m.CreateEndpointActivityTable, err)
+		return err
+	}
+	go logger.Info(m.CreateEndpointActivityTable, "CreateEndpointActivityTable")
+	return nil
+}
+
+func (m migration) CreateLoginAttemptTable() error {
+	err := m.dbClient.Migrator().AutoMigrate(loginattempt.LoginAttempt{})
+	if err != nil {
+		go logger.Error(m.CreateLoginAttemptTable, err)
+		return err
+	}
+	go logger.Info(m.CreateLoginAttemptTable, "CreateLoginAttemptTable")
+	return nil
+}
+
+func (m migration) CreatePasswordHistoryTable() error {
+	err := m.dbClient.Migrator().AutoMigrate(user.PasswordHistory{})
+	if err != nil {
+		go logger.Error(m.CreatePasswordHistoryTable, err)
+		return err
+	}
+	go logger.Info(m.CreatePasswordHistoryTable, "CreatePasswordHistoryTable")
+	return nil
+}
+
+func (m migration) CreateWorkspaceRoleTable() error {
+	err := m.dbClient.Migrator().AutoMigrate(workspacerole.WorkspaceRole{})
+	if err != nil {
+		go logger.Error(m.CreateWorkspaceRoleTable, err)
+		return err
+	}
+	go logger.Info(m.CreateWorkspaceRoleTable, "CreateWorkspaceRoleTable")
+	return nil
+}
+
+func (m migration) AddWorkspaceSecretProviderColumn() error {
+	// the workspace table is created with the column on fresh databases
+	if m.dbClient.Migrator().HasColumn(&workspace.Workspace{}, "SecretProvider") {
+		return nil
+	}
+	err := m.dbClient.Migrator().AddColumn(&workspace.Workspace{}, "SecretProvider")
+	if err != nil {
+		go logger.Error(m.AddWorkspaceSecretProviderColumn, err)
+		return err
+	}
+	go logger.Info(m.AddWorkspaceSecretProviderColumn, "AddWorkspaceSecretProviderColumn")
+	return nil
+}
+
+func (m migration) CreateValidatorBalanceTable() error {
+	err := m.dbClient.Migrator().AutoMigrate(performance.Balance{})
+	if err != nil {
+		go logger.Error(m.CreateValidatorBalanceTable, err)
+		return err
+	}
+	go logger.Info(m.CreateValidatorBalanceTable, "CreateValidatorBalanceTable")
+	return nil
+}
+
+func (m migration) CreateAuditRecordTable() error {
+	err := m.dbClient.Migrator().AutoMigrate(audit.Record{})
+	if err != nil {
+		go logger.Error(m.CreateAuditRecordTable, err)
+		return err
+	}
+	go logger.Info(m.CreateAuditRecordTable, "CreateAuditRecordTable")
+	return nil
+}
+
+// DropTable drops the table of the given model, used by the down steps of the migrations creating tables
+func (m migration) DropTable(model interface{}) error {
+	err := m.dbClient.Migrator().DropTable(model)
+	if err != nil {
+		go logger.Error(m.DropTable, err)
+		return err
+	}
+	return nil
+}
+
+// DropColumn drops the column of the given model field, used by the down steps of the migrations adding columns
+func (m migration) DropColumn(model interface{}, field string) error {
+	err := m.dbClient.Migrator().DropColumn(model, field)
+	if err != nil {
+		go logger.Error(m.DropColumn, err)
+		return err
 	}
 	return nil
 }
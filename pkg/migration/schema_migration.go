@@ -0,0 +1,33 @@
+package migration
+
+import (
+	"gorm.io/gorm"
+	"time"
+)
+
+// SchemaMigration records an applied migration version
+type SchemaMigration struct {
+	Version   uint   `gorm:"primaryKey;autoIncrement:false"`
+	Name      string `gorm:"not null"`
+	AppliedAt time.Time
+}
+
+func (SchemaMigration) TableName() string {
+	return "schema_migrations"
+}
+
+// Definition is a versioned migration, Up and Down receive the transaction the step runs in
+type Definition struct {
+	Version uint
+	Name    string
+	Up      func(tx *gorm.DB) error
+	Down    func(tx *gorm.DB) error
+}
+
+// Status is the state of a migration definition in the database
+type Status struct {
+	Version   uint
+	Name      string
+	Applied   bool
+	AppliedAt *time.Time
+}
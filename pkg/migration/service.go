@@ -1,8 +1,21 @@
 package migration
 
 import (
+	"fmt"
+	"github.com/kotalco/core-api/core/audit"
+	"github.com/kotalco/core-api/core/endpointactivity"
+	"github.com/kotalco/core-api/core/ethereum2/performance"
+	"github.com/kotalco/core-api/core/loginattempt"
+	"github.com/kotalco/core-api/core/setting"
+	"github.com/kotalco/core-api/core/user"
+	"github.com/kotalco/core-api/core/verification"
+	"github.com/kotalco/core-api/core/workspace"
+	"github.com/kotalco/core-api/core/workspacerole"
+	"github.com/kotalco/core-api/core/workspaceuser"
 	"github.com/kotalco/core-api/pkg/logger"
 	"gorm.io/gorm"
+	"sort"
+	"time"
 )
 
 const (
@@ -12,71 +25,326 @@ const (
 	MigrateWorkspaceUserTable    = "MigrateWorkspaceUserTable"
 	MigrateSettingTable          = "MigrateSettingTable"
 	MigrateEndpointActivityTable = "MigrateEndpointActivityTable"
+	MigrateLoginAttemptTable     = "MigrateLoginAttemptTable"
+	MigratePasswordHistoryTable  = "MigratePasswordHistoryTable"
+	MigrateWorkspaceRoleTable    = "MigrateWorkspaceRoleTable"
+	AddWorkspaceSecretProvider   = "AddWorkspaceSecretProvider"
+	MigrateValidatorBalanceTable = "MigrateValidatorBalanceTable"
+	MigrateAuditRecordTable      = "MigrateAuditRecordTable"
 )
 
+// advisoryLockKey is the postgres advisory lock held while migrating, so API replicas booting together don't migrate concurrently
+const advisoryLockKey = 7016111
+
 type service struct {
+	dbClient *gorm.DB
 }
 
 type IService interface {
-	Migrations() map[string]Definition
+	Migrations() []Definition
 	Run()
+	Status() ([]Status, error)
+	Up() error
+	Down() error
+	To(version uint) error
 }
 
-var migrator IMigration
-
 func NewService(dbClient *gorm.DB) IService {
-	migrator = NewMigration(dbClient)
-
 	newService := new(service)
+	newService.dbClient = dbClient
 	return newService
-
 }
 
-func (service) Migrations() map[string]Definition {
-	return map[string]Definition{
-		MigrateUserTable: {
-			Name: MigrateUserTable,
-			Run: func() error {
-				return migrator.CreateUserTable()
+// Migrations returns the migrations ordered by version, versions are never reused or reordered once released
+// the first migrations create the tables with AutoMigrate so they're safe to apply on databases created before versioning
+func (service) Migrations() []Definition {
+	return []Definition{
+		{
+			Version: 1,
+			Name:    MigrateUserTable,
+			Up: func(tx *gorm.DB) error {
+				return NewMigration(tx).CreateUserTable()
+			},
+			Down: func(tx *gorm.DB) error {
+				return NewMigration(tx).DropTable(user.User{})
+			},
+		},
+		{
+			Version: 2,
+			Name:    MigrateVerificationTable,
+			Up: func(tx *gorm.DB) error {
+				return NewMigration(tx).CreateVerificationTable()
+			},
+			Down: func(tx *gorm.DB) error {
+				return NewMigration(tx).DropTable(verification.Verification{})
+			},
+		},
+		{
+			Version: 3,
+			Name:    MigrateWorkspaceTable,
+			Up: func(tx *gorm.DB) error {
+				return NewMigration(tx).CreateWorkspaceTable()
+			},
+			Down: func(tx *gorm.DB) error {
+				return NewMigration(tx).DropTable(workspace.Workspace{})
+			},
+		},
+		{
+			Version: 4,
+			Name:    MigrateWorkspaceUserTable,
+			Up: func(tx *gorm.DB) error {
+				return NewMigration(tx).CreateWorkspaceUserTable()
+			},
+			Down: func(tx *gorm.DB) error {
+				return NewMigration(tx).DropTable(workspaceuser.WorkspaceUser{})
+			},
+		},
+		{
+			Version: 5,
+			Name:    MigrateSettingTable,
+			Up: func(tx *gorm.DB) error {
+				return NewMigration(tx).CreateSettingTable()
+			},
+			Down: func(tx *gorm.DB) error {
+				return NewMigration(tx).DropTable(setting.Setting{})
+			},
+		},
+		{
+			Version: 6,
+			Name:    MigrateEndpointActivityTable,
+			Up: func(tx *gorm.DB) error {
+				return NewMigration(tx).CreateEndpointActivityTable()
+			},
+			Down: func(tx *gorm.DB) error {
+				return NewMigration(tx).DropTable(endpointactivity.Activity{})
 			},
 		},
-		MigrateVerificationTable: {
-			Name: MigrateVerificationTable,
-			Run: func() error {
-				return migrator.CreateVerificationTable()
+		{
+			Version: 7,
+			Name:    MigrateLoginAttemptTable,
+			Up: func(tx *gorm.DB) error {
+				return NewMigration(tx).CreateLoginAttemptTable()
+			},
+			Down: func(tx *gorm.DB) error {
+				return NewMigration(tx).DropTable(loginattempt.LoginAttempt{})
+			},
+		},
+		{
+			Version: 8,
+			Name:    MigratePasswordHistoryTable,
+			Up: func(tx *gorm.DB) error {
+				return NewMigration(tx).CreatePasswordHistoryTable()
+			},
+			Down: func(tx *gorm.DB) error {
+				return NewMigration(tx).DropTable(user.PasswordHistory{})
 			},
 		},
-		MigrateWorkspaceTable: {
-			Name: MigrateWorkspaceTable,
-			Run: func() error {
-				return migrator.CreateWorkspaceTable()
+		{
+			Version: 9,
+			Name:    MigrateWorkspaceRoleTable,
+			Up: func(tx *gorm.DB) error {
+				return NewMigration(tx).CreateWorkspaceRoleTable()
+			},
+			Down: func(tx *gorm.DB) error {
+				return NewMigration(tx).DropTable(workspacerole.WorkspaceRole{})
 			},
 		},
-		MigrateWorkspaceUserTable: {
-			Name: MigrateWorkspaceUserTable,
-			Run: func() error {
-				return migrator.CreateWorkspaceUserTable()
+		{
+			Version: 10,
+			Name:    AddWorkspaceSecretProvider,
+			Up: func(tx *gorm.DB) error {
+				return NewMigration(tx).AddWorkspaceSecretProviderColumn()
+			},
+			Down: func(tx *gorm.DB) error {
+				return NewMigration(tx).DropColumn(&workspace.Workspace{}, "SecretProvider")
 			},
 		},
-		MigrateSettingTable: {
-			Name: MigrateSettingTable,
-			Run: func() error {
-				return migrator.CreateSettingTable()
+		{
+			Version: 11,
+			Name:    MigrateValidatorBalanceTable,
+			Up: func(tx *gorm.DB) error {
+				return NewMigration(tx).CreateValidatorBalanceTable()
+			},
+			Down: func(tx *gorm.DB) error {
+				return NewMigration(tx).DropTable(performance.Balance{})
 			},
 		},
-		MigrateEndpointActivityTable: {
-			Name: MigrateEndpointActivityTable,
-			Run: func() error {
-				return migrator.CreateEndpointActivityTable()
+		{
+			Version: 12,
+			Name:    MigrateAuditRecordTable,
+			Up: func(tx *gorm.DB) error {
+				return NewMigration(tx).CreateAuditRecordTable()
+			},
+			Down: func(tx *gorm.DB) error {
+				return NewMigration(tx).DropTable(audit.Record{})
 			},
 		},
 	}
 }
 
+// Run applies the pending migrations on boot
 func (s service) Run() {
-	for _, step := range s.Migrations() {
-		if err := step.Run(); err != nil {
-			go logger.Error(step.Name, err)
+	if err := s.Up(); err != nil {
+		go logger.Error(s.Run, err)
+	}
+}
+
+// Status returns every migration with whether it has been applied
+func (s service) Status() ([]Status, error) {
+	list := make([]Status, 0)
+	err := s.withLock(func(conn *gorm.DB) error {
+		records, err := appliedMigrations(conn)
+		if err != nil {
+			return err
+		}
+		for _, definition := range s.Migrations() {
+			status := Status{Version: definition.Version, Name: definition.Name}
+			if record, ok := records[definition.Version]; ok {
+				status.Applied = true
+				status.AppliedAt = &record.AppliedAt
+			}
+			list = append(list, status)
+		}
+		return nil
+	})
+	return list, err
+}
+
+// Up applies all the pending migrations
+func (s service) Up() error {
+	migrations := s.Migrations()
+	if len(migrations) == 0 {
+		return nil
+	}
+	return s.To(migrations[len(migrations)-1].Version)
+}
+
+// Down reverts the latest applied migration
+func (s service) Down() error {
+	return s.withLock(func(conn *gorm.DB) error {
+		records, err := appliedMigrations(conn)
+		if err != nil {
+			return err
+		}
+		steps := toRevert(s.Migrations(), records, 0)
+		if len(steps) == 0 {
+			return nil
+		}
+		return revert(conn, steps[0])
+	})
+}
+
+// To migrates up or down till the given version is the latest applied one, version 0 reverts all the migrations
+func (s service) To(version uint) error {
+	if version != 0 && !s.exists(version) {
+		return fmt.Errorf("migration version %d doesn't exist", version)
+	}
+
+	return s.withLock(func(conn *gorm.DB) error {
+		records, err := appliedMigrations(conn)
+		if err != nil {
+			return err
+		}
+		for _, step := range toRevert(s.Migrations(), records, version) {
+			if err := revert(conn, step); err != nil {
+				return err
+			}
+		}
+		for _, step := range toApply(s.Migrations(), records, version) {
+			if err := apply(conn, step); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func (s service) exists(version uint) bool {
+	for _, definition := range s.Migrations() {
+		if definition.Version == version {
+			return true
+		}
+	}
+	return false
+}
+
+// withLock runs fn on a single connection holding the migrations advisory lock
+// the lock is session level so the connection has to be pinned till it's released
+func (s service) withLock(fn func(conn *gorm.DB) error) error {
+	return s.dbClient.Connection(func(conn *gorm.DB) error {
+		if err := conn.Exec("SELECT pg_advisory_lock(?)", advisoryLockKey).Error; err != nil {
+			return err
+		}
+		defer conn.Exec("SELECT pg_advisory_unlock(?)", advisoryLockKey)
+
+		if err := conn.AutoMigrate(SchemaMigration{}); err != nil {
+			return err
+		}
+		return fn(conn)
+	})
+}
+
+func appliedMigrations(conn *gorm.DB) (map[uint]SchemaMigration, error) {
+	var records []SchemaMigration
+	if err := conn.Find(&records).Error; err != nil {
+		return nil, err
+	}
+
+	applied := make(map[uint]SchemaMigration, len(records))
+	for _, record := range records {
+		applied[record.Version] = record
+	}
+	return applied, nil
+}
+
+// toApply returns the pending migrations up to the target version in ascending order
+func toApply(definitions []Definition, applied map[uint]SchemaMigration, target uint) []Definition {
+	steps := make([]Definition, 0)
+	for _, definition := range definitions {
+		if _, ok := applied[definition.Version]; !ok && definition.Version <= target {
+			steps = append(steps, definition)
+		}
+	}
+	sort.Slice(steps, func(i, j int) bool { return steps[i].Version < steps[j].Version })
+	return steps
+}
+
+// toRevert returns the applied migrations newer than the target version in descending order
+func toRevert(definitions []Definition, applied map[uint]SchemaMigration, target uint) []Definition {
+	steps := make([]Definition, 0)
+	for _, definition := range definitions {
+		if _, ok := applied[definition.Version]; ok && definition.Version > target {
+			steps = append(steps, definition)
+		}
+	}
+	sort.Slice(steps, func(i, j int) bool { return steps[i].Version > steps[j].Version })
+	return steps
+}
+
+func apply(conn *gorm.DB, definition Definition) error {
+	err := conn.Transaction(func(tx *gorm.DB) error {
+		if err := definition.Up(tx); err != nil {
+			return err
+		}
+		return tx.Create(&SchemaMigration{Version: definition.Version, Name: definition.Name, AppliedAt: time.Now().UTC()}).Error
+	})
+	if err != nil {
+		return fmt.Errorf("can't apply migration %d %s: %w", definition.Version, definition.Name, err)
+	}
+	go logger.Info("MIGRATION_UP", fmt.Sprintf("%d %s", definition.Version, definition.Name))
+	return nil
+}
+
+func revert(conn *gorm.DB, definition Definition) error {
+	err := conn.Transaction(func(tx *gorm.DB) error {
+		if err := definition.Down(tx); err != nil {
+			return err
 		}
+		return tx.Delete(&SchemaMigration{}, definition.Version).Error
+	})
+	if err != nil {
+		return fmt.Errorf("can't revert migration %d %s: %w", definition.Version, definition.Name, err)
 	}
+	go logger.Info("MIGRATION_DOWN", fmt.Sprintf("%d %s", definition.Version, definition.Name))
+	return nil
 }
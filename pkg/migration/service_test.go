@@ -0,0 +1,62 @@
+package migration
+
+import (
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+	"testing"
+)
+
+func definitions(versions ...uint) []Definition {
+	list := make([]Definition, 0)
+	for _, v := range versions {
+		list = append(list, Definition{Version: v, Up: func(tx *gorm.DB) error { return nil }, Down: func(tx *gorm.DB) error { return nil }})
+	}
+	return list
+}
+
+func versions(list []Definition) []uint {
+	result := make([]uint, 0)
+	for _, v := range list {
+		result = append(result, v.Version)
+	}
+	return result
+}
+
+func TestService_Migrations(t *testing.T) {
+	t.Run("migrations should have unique ascending versions with up and down steps", func(t *testing.T) {
+		var previous uint
+		for _, definition := range NewService(nil).Migrations() {
+			assert.Greater(t, definition.Version, previous)
+			assert.NotEmpty(t, definition.Name)
+			assert.NotNil(t, definition.Up)
+			assert.NotNil(t, definition.Down)
+			previous = definition.Version
+		}
+	})
+}
+
+func TestToApply(t *testing.T) {
+	t.Run("to apply should return the pending migrations up to the target in ascending order", func(t *testing.T) {
+		applied := map[uint]SchemaMigration{1: {Version: 1}, 3: {Version: 3}}
+		steps := toApply(definitions(4, 1, 2, 3, 5), applied, 4)
+		assert.EqualValues(t, []uint{2, 4}, versions(steps))
+	})
+	t.Run("to apply should return nothing if all migrations are applied", func(t *testing.T) {
+		applied := map[uint]SchemaMigration{1: {Version: 1}, 2: {Version: 2}}
+		steps := toApply(definitions(1, 2), applied, 2)
+		assert.Empty(t, steps)
+	})
+}
+
+func TestToRevert(t *testing.T) {
+	t.Run("to revert should return the applied migrations newer than the target in descending order", func(t *testing.T) {
+		applied := map[uint]SchemaMigration{1: {Version: 1}, 2: {Version: 2}, 4: {Version: 4}}
+		steps := toRevert(definitions(1, 2, 3, 4), applied, 1)
+		assert.EqualValues(t, []uint{4, 2}, versions(steps))
+	})
+	t.Run("to revert should return all the applied migrations for version 0", func(t *testing.T) {
+		applied := map[uint]SchemaMigration{1: {Version: 1}, 2: {Version: 2}}
+		steps := toRevert(definitions(1, 2), applied, 0)
+		assert.EqualValues(t, []uint{2, 1}, versions(steps))
+	})
+}
@@ -0,0 +1,127 @@
+package roles
+
+import "strings"
+
+// Wildcard matches any resource or action, "*" alone grants every permission
+const Wildcard = "*"
+
+// permission actions
+const (
+	Create = "create"
+	Read   = "read"
+	Update = "update"
+	Delete = "delete"
+)
+
+// permission resources, nested resources are separated by dots
+const (
+	Workspaces           = "workspaces"
+	WorkspaceMembers     = "workspaces.members"
+	WorkspaceRoles       = "workspaces.roles"
+	Endpoints            = "endpoints"
+	Secrets              = "secrets"
+	StorageClasses       = "storageclasses"
+	Snapshots            = "snapshots"
+	ChainlinkNodes       = "chainlink.nodes"
+	EthereumNodes        = "ethereum.nodes"
+	Ethereum2BeaconNodes = "ethereum2.beaconnodes"
+	Ethereum2Validators  = "ethereum2.validators"
+	FilecoinNodes        = "filecoin.nodes"
+	IPFSPeers            = "ipfs.peers"
+	IPFSClusterPeers     = "ipfs.clusterpeers"
+	NearNodes            = "near.nodes"
+	PolkadotNodes        = "polkadot.nodes"
+	BitcoinNodes         = "bitcoin.nodes"
+	StacksNodes          = "stacks.nodes"
+	AptosNodes           = "aptos.nodes"
+)
+
+// Resources lists every resource permissions can be granted on
+var Resources = []string{
+	Workspaces,
+	WorkspaceMembers,
+	WorkspaceRoles,
+	Endpoints,
+	Secrets,
+	StorageClasses,
+	Snapshots,
+	ChainlinkNodes,
+	EthereumNodes,
+	Ethereum2BeaconNodes,
+	Ethereum2Validators,
+	FilecoinNodes,
+	IPFSPeers,
+	IPFSClusterPeers,
+	NearNodes,
+	PolkadotNodes,
+	BitcoinNodes,
+	StacksNodes,
+	AptosNodes,
+}
+
+// Actions lists every action permissions can be granted for
+var Actions = []string{Create, Read, Update, Delete}
+
+// Permission builds a permission in the form resource:action e.g. ethereum.nodes:update
+func Permission(resource string, action string) string {
+	return resource + ":" + action
+}
+
+// split returns the permission resource and action, "*" is treated as "*:*"
+func split(permission string) (string, string) {
+	if permission == Wildcard {
+		return Wildcard, Wildcard
+	}
+	resource, action, found := strings.Cut(permission, ":")
+	if !found {
+		return permission, ""
+	}
+	return resource, action
+}
+
+// ValidPermission checks the permission resource and action are known, wildcards are allowed for both
+func ValidPermission(permission string) bool {
+	resource, action := split(permission)
+
+	validResource := resource == Wildcard
+	for _, v := range Resources {
+		if v == resource {
+			validResource = true
+			break
+		}
+	}
+
+	validAction := action == Wildcard
+	for _, v := range Actions {
+		if v == action {
+			validAction = true
+			break
+		}
+	}
+
+	return validResource && validAction
+}
+
+// Allowed checks if the granted permissions allow the required permission
+// the required permission may contain wildcards too, they're only allowed by wildcard grants
+func Allowed(granted []string, required string) bool {
+	requiredResource, requiredAction := split(required)
+	for _, permission := range granted {
+		resource, action := split(permission)
+		if (resource == Wildcard || resource == requiredResource) && (action == Wildcard || action == requiredAction) {
+			return true
+		}
+	}
+	return false
+}
+
+// Covers checks if the granted permissions allow every requested permission
+// used to prevent members from granting permissions they don't have
+func Covers(granted []string, requested []string) bool {
+	for _, v := range requested {
+		if !Allowed(granted, v) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,79 @@
+package roles
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestValidPermission(t *testing.T) {
+	t.Run("Valid_Permission_Should_Pass", func(t *testing.T) {
+		assert.True(t, ValidPermission("ethereum.nodes:update"))
+		assert.True(t, ValidPermission("endpoints:delete"))
+		assert.True(t, ValidPermission("secrets:*"))
+		assert.True(t, ValidPermission("*:read"))
+		assert.True(t, ValidPermission("*"))
+	})
+	t.Run("Invalid_Permission_Should_Fail", func(t *testing.T) {
+		assert.False(t, ValidPermission("ethereum.nodes"))
+		assert.False(t, ValidPermission("ethereum.nodes:restart"))
+		assert.False(t, ValidPermission("cosmos.nodes:read"))
+		assert.False(t, ValidPermission(""))
+	})
+}
+
+func TestAllowed(t *testing.T) {
+	t.Run("Exact_Permission_Should_Be_Allowed", func(t *testing.T) {
+		assert.True(t, Allowed([]string{"secrets:read"}, "secrets:read"))
+		assert.False(t, Allowed([]string{"secrets:read"}, "secrets:create"))
+	})
+	t.Run("Wildcard_Resource_Should_Allow_Any_Resource", func(t *testing.T) {
+		assert.True(t, Allowed([]string{"*:read"}, "ethereum.nodes:read"))
+		assert.False(t, Allowed([]string{"*:read"}, "ethereum.nodes:delete"))
+	})
+	t.Run("Wildcard_Action_Should_Allow_Any_Action", func(t *testing.T) {
+		assert.True(t, Allowed([]string{"endpoints:*"}, "endpoints:delete"))
+		assert.False(t, Allowed([]string{"endpoints:*"}, "secrets:delete"))
+	})
+	t.Run("Wildcard_Should_Allow_Everything", func(t *testing.T) {
+		assert.True(t, Allowed([]string{"*"}, "aptos.nodes:delete"))
+		assert.True(t, Allowed([]string{"*"}, "*"))
+	})
+	t.Run("Required_Wildcard_Should_Only_Be_Allowed_By_Wildcard", func(t *testing.T) {
+		assert.False(t, Allowed([]string{"*:read"}, "*"))
+		assert.False(t, Allowed([]string{"secrets:read"}, "secrets:*"))
+		assert.True(t, Allowed([]string{"secrets:*"}, "secrets:*"))
+	})
+}
+
+func TestCovers(t *testing.T) {
+	t.Run("Covers_Should_Pass_If_All_Permissions_Allowed", func(t *testing.T) {
+		assert.True(t, Covers([]string{"*:read", "secrets:*"}, []string{"ethereum.nodes:read", "secrets:delete"}))
+	})
+	t.Run("Covers_Should_Fail_If_Any_Permission_Not_Allowed", func(t *testing.T) {
+		assert.False(t, Covers([]string{"*:read"}, []string{"ethereum.nodes:read", "secrets:delete"}))
+	})
+}
+
+func TestBuiltInPermissions(t *testing.T) {
+	t.Run("Admin_Should_Be_Allowed_Everything", func(t *testing.T) {
+		permissions, ok := New().Permissions(Admin)
+		assert.True(t, ok)
+		assert.True(t, Allowed(permissions, Permission(EthereumNodes, Delete)))
+	})
+	t.Run("Writer_Should_Not_Delete_Nodes", func(t *testing.T) {
+		permissions, ok := New().Permissions(Writer)
+		assert.True(t, ok)
+		assert.True(t, Allowed(permissions, Permission(EthereumNodes, Update)))
+		assert.False(t, Allowed(permissions, Permission(EthereumNodes, Delete)))
+	})
+	t.Run("Reader_Should_Only_Read", func(t *testing.T) {
+		permissions, ok := New().Permissions(Reader)
+		assert.True(t, ok)
+		assert.True(t, Allowed(permissions, Permission(Secrets, Read)))
+		assert.False(t, Allowed(permissions, Permission(Secrets, Create)))
+	})
+	t.Run("Custom_Role_Should_Not_Be_Built_In", func(t *testing.T) {
+		_, ok := New().Permissions("operator")
+		assert.False(t, ok)
+	})
+}
@@ -6,6 +6,18 @@ const (
 	Writer = "writer"
 )
 
+// builtInPermissions are the permission sets of the built-in roles, workspaces can define custom roles beside them
+var builtInPermissions = map[string][]string{
+	Admin: {Wildcard},
+	Writer: {
+		Permission(Wildcard, Create),
+		Permission(Wildcard, Read),
+		Permission(Wildcard, Update),
+		Permission(Workspaces, Delete),
+	},
+	Reader: {Permission(Wildcard, Read)},
+}
+
 type Roles struct {
 }
 
@@ -19,3 +31,17 @@ func (r *Roles) Exist(role string) bool {
 	}
 	return false
 }
+
+// Permissions returns the permission set of a built-in role
+func (r *Roles) Permissions(role string) ([]string, bool) {
+	permissions, ok := builtInPermissions[role]
+	if !ok {
+		return nil, false
+	}
+	return append([]string{}, permissions...), true
+}
+
+// BuiltIn returns the built-in roles names
+func (r *Roles) BuiltIn() []string {
+	return []string{Admin, Writer, Reader}
+}
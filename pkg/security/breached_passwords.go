@@ -0,0 +1,66 @@
+package security
+
+import (
+	"bufio"
+	"crypto/sha1"
+	_ "embed"
+	"encoding/hex"
+	"strings"
+	"sync"
+)
+
+// hashPrefixLength is the sha1 prefix length used to query the breached passwords ranges, same as HIBP range api
+const hashPrefixLength = 5
+
+var (
+	//go:embed breached_passwords.txt
+	breachedPasswordsList string
+	breachedRanges        map[string][]string
+	breachedRangesOnce    sync.Once
+)
+
+type breachedPasswords struct{}
+
+type IBreachedPasswords interface {
+	Range(prefix string) []string
+	IsBreached(password string) bool
+}
+
+func NewBreachedPasswords() IBreachedPasswords {
+	newBreachedPasswords := &breachedPasswords{}
+	return newBreachedPasswords
+}
+
+// Range returns the sha1 suffixes of the breached passwords whose hashes start with the given 5 chars prefix
+// the password itself or its full hash never leaves the caller, only the prefix is used for the lookup
+func (breachedPasswords) Range(prefix string) []string {
+	breachedRangesOnce.Do(loadBreachedRanges)
+	return breachedRanges[strings.ToUpper(prefix)]
+}
+
+// IsBreached checks if the password exists in the bundled breached passwords list
+func (b breachedPasswords) IsBreached(password string) bool {
+	sum := sha1.Sum([]byte(password))
+	hash := strings.ToUpper(hex.EncodeToString(sum[:]))
+
+	for _, suffix := range b.Range(hash[:hashPrefixLength]) {
+		if suffix == hash[hashPrefixLength:] {
+			return true
+		}
+	}
+	return false
+}
+
+// loadBreachedRanges groups the bundled sha1 hashes by their prefix
+func loadBreachedRanges() {
+	breachedRanges = map[string][]string{}
+	scanner := bufio.NewScanner(strings.NewReader(breachedPasswordsList))
+	for scanner.Scan() {
+		hash := strings.ToUpper(strings.TrimSpace(scanner.Text()))
+		if len(hash) != sha1.Size*2 {
+			continue
+		}
+		prefix := hash[:hashPrefixLength]
+		breachedRanges[prefix] = append(breachedRanges[prefix], hash[hashPrefixLength:])
+	}
+}
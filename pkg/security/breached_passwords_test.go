@@ -0,0 +1,29 @@
+package security
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+var breachedPasswordsService = NewBreachedPasswords()
+
+func TestBreachedPasswords_IsBreached(t *testing.T) {
+	t.Run("Is_Breached_Should_Return_True_For_Common_Password", func(t *testing.T) {
+		assert.True(t, breachedPasswordsService.IsBreached("123456"))
+		assert.True(t, breachedPasswordsService.IsBreached("P@ssw0rd"))
+	})
+	t.Run("Is_Breached_Should_Return_False_For_Random_Password", func(t *testing.T) {
+		assert.False(t, breachedPasswordsService.IsBreached(GenerateRandomString(32)))
+	})
+}
+
+func TestBreachedPasswords_Range(t *testing.T) {
+	t.Run("Range_Should_Return_Suffixes_For_Known_Prefix", func(t *testing.T) {
+		// sha1("password") = 5BAA61E4C9B93F3F0682250B6CF8331B7EE68FD8
+		suffixes := breachedPasswordsService.Range("5baa6")
+		assert.Contains(t, suffixes, "1E4C9B93F3F0682250B6CF8331B7EE68FD8")
+	})
+	t.Run("Range_Should_Return_Empty_For_Unknown_Prefix", func(t *testing.T) {
+		assert.Empty(t, breachedPasswordsService.Range("ZZZZZ"))
+	})
+}
@@ -0,0 +1,91 @@
+package security
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/sha256"
+	"github.com/decred/dcrd/dcrec/secp256k1/v4"
+	bls12381 "github.com/kilic/bls12-381"
+	"golang.org/x/crypto/hkdf"
+	"math/big"
+)
+
+// blsKeygenSalt is the EIP-2333 HKDF_mod_r initial salt
+const blsKeygenSalt = "BLS-SIG-KEYGEN-SALT-"
+
+// GenerateRandomBytes returns n cryptographically secure random bytes
+func GenerateRandomBytes(n int) ([]byte, error) {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		return nil, err
+	}
+	return b, nil
+}
+
+// GenerateSecureString returns a cryptographically secure random string of n letters and digits
+// unlike GenerateRandomString it's safe to be used for passwords
+func GenerateSecureString(n int) (string, error) {
+	b := make([]byte, n)
+	max := big.NewInt(int64(len(letterBytes)))
+	for i := range b {
+		idx, err := rand.Int(rand.Reader, max)
+		if err != nil {
+			return "", err
+		}
+		b[i] = letterBytes[idx.Int64()]
+	}
+	return string(b), nil
+}
+
+// GenerateSecp256k1Key generates a secp256k1 key pair, returns the 32 bytes private key and the 65 bytes uncompressed public key
+func GenerateSecp256k1Key() (privateKey []byte, publicKey []byte, err error) {
+	key, err := secp256k1.GeneratePrivateKey()
+	if err != nil {
+		return nil, nil, err
+	}
+	return key.Serialize(), key.PubKey().SerializeUncompressed(), nil
+}
+
+// GenerateEd25519Key generates an ed25519 key pair
+func GenerateEd25519Key() (ed25519.PrivateKey, ed25519.PublicKey, error) {
+	publicKey, privateKey, err := ed25519.GenerateKey(rand.Reader)
+	return privateKey, publicKey, err
+}
+
+// GenerateBLSKey generates a BLS12-381 key pair from random key material as in EIP-2333 derive_master_SK
+// returns the 32 bytes big endian secret key and the 48 bytes compressed public key
+func GenerateBLSKey() (secretKey []byte, publicKey []byte, err error) {
+	ikm, err := GenerateRandomBytes(32)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	g1 := bls12381.NewG1()
+	sk, err := hkdfModR(ikm, g1.Q())
+	if err != nil {
+		return nil, nil, err
+	}
+
+	secretKey = make([]byte, 32)
+	sk.FillBytes(secretKey)
+	publicKey = g1.ToCompressed(g1.MulScalarBig(g1.New(), g1.One(), sk))
+	return secretKey, publicKey, nil
+}
+
+// hkdfModR is the EIP-2333 HKDF_mod_r with empty key info, it hashes the key material to a non zero scalar modulo the group order r
+func hkdfModR(ikm []byte, r *big.Int) (*big.Int, error) {
+	salt := []byte(blsKeygenSalt)
+	sk := new(big.Int)
+	for sk.Sign() == 0 {
+		sum := sha256.Sum256(salt)
+		salt = sum[:]
+
+		okm := make([]byte, 48)
+		prk := hkdf.Extract(sha256.New, append(ikm, 0), salt)
+		if _, err := hkdf.Expand(sha256.New, prk, []byte{0, 48}).Read(okm); err != nil {
+			return nil, err
+		}
+		sk.Mod(new(big.Int).SetBytes(okm), r)
+	}
+	return sk, nil
+}
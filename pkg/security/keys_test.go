@@ -0,0 +1,52 @@
+package security
+
+import (
+	"crypto/ed25519"
+	"encoding/hex"
+	"github.com/decred/dcrd/dcrec/secp256k1/v4"
+	bls12381 "github.com/kilic/bls12-381"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestGenerateSecureString(t *testing.T) {
+	first, err := GenerateSecureString(32)
+	assert.Nil(t, err)
+	assert.Len(t, first, 32)
+	assert.Regexp(t, "^[a-zA-Z1-9]+$", first)
+
+	second, _ := GenerateSecureString(32)
+	assert.NotEqual(t, first, second)
+}
+
+func TestGenerateSecp256k1Key(t *testing.T) {
+	privateKey, publicKey, err := GenerateSecp256k1Key()
+	assert.Nil(t, err)
+	assert.Len(t, privateKey, 32)
+	assert.Len(t, publicKey, 65)
+	assert.EqualValues(t, publicKey, secp256k1.PrivKeyFromBytes(privateKey).PubKey().SerializeUncompressed())
+}
+
+func TestGenerateEd25519Key(t *testing.T) {
+	privateKey, publicKey, err := GenerateEd25519Key()
+	assert.Nil(t, err)
+	assert.EqualValues(t, publicKey, privateKey.Public().(ed25519.PublicKey))
+}
+
+func TestGenerateBLSKey(t *testing.T) {
+	secretKey, publicKey, err := GenerateBLSKey()
+	assert.Nil(t, err)
+	assert.Len(t, secretKey, 32)
+	assert.Len(t, publicKey, 48)
+
+	_, err = bls12381.NewG1().FromCompressed(publicKey)
+	assert.Nil(t, err)
+}
+
+func TestHKDFModR(t *testing.T) {
+	// EIP-2333 test case 0 master secret key
+	seed, _ := hex.DecodeString("c55257c360c07c72029aebc1b53c05ed0362ada38ead3e3e9efa3708e53495531f09a6987599d18264c1e1c92f2cf141630c7a3c4ab7c81b2f001698e7463b04")
+	sk, err := hkdfModR(seed, bls12381.NewG1().Q())
+	assert.Nil(t, err)
+	assert.Equal(t, "6083874454709270928345386274498605044986640685124978867557563392430687146096", sk.String())
+}
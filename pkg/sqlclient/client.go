@@ -44,6 +44,6 @@ func Rollback(txHandle *gorm.DB) {
 	txHandle.Rollback()
 }
 
-func Commit(txHandle *gorm.DB) {
-	txHandle.Commit()
+func Commit(txHandle *gorm.DB) error {
+	return txHandle.Commit().Error
 }
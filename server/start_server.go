@@ -15,9 +15,9 @@ import (
 // Create channel for idle connections.
 // check if  Received an interrupt signal, shutdown.
 // Error if closing listeners, or context timeout
-// Run server.
+// Run server and the upload server.
 // Error if  Run server with reason
-func StartServerWithGracefulShutdown(a *fiber.App) {
+func StartServerWithGracefulShutdown(a *fiber.App, uploadApp *fiber.App) {
 	idleConnsClosed := make(chan struct{})
 
 	go func() {
@@ -27,9 +27,19 @@ func StartServerWithGracefulShutdown(a *fiber.App) {
 		if err := a.Shutdown(); err != nil {
 			go logger.Info("StartServerWithGracefulShutdown", fmt.Sprintf("Oops... Server is not shutting down! Reason:  %v", err))
 		}
+		if err := uploadApp.Shutdown(); err != nil {
+			go logger.Info("StartServerWithGracefulShutdown", fmt.Sprintf("Oops... Upload server is not shutting down! Reason:  %v", err))
+		}
 		close(idleConnsClosed)
 	}()
 
+	go func() {
+		uploadPort := ":" + config.Environment.UploadServerPort
+		if err := uploadApp.Listen(uploadPort); err != nil {
+			go logger.Info("StartServerWithGracefulShutdown", fmt.Sprintf("Oops... Upload server is not running! Reason: %v", err))
+		}
+	}()
+
 	port := ":" + config.Environment.ServerPort
 
 	if err := a.Listen(port); err != nil {